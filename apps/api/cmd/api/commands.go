@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"go-scraping/internal/bookmyshow"
+	"go-scraping/internal/buildinfo"
+	"go-scraping/internal/cities"
+	"go-scraping/internal/config"
+	"go-scraping/internal/fixtures"
+	"go-scraping/internal/logging"
+	"go-scraping/internal/movies"
+	"go-scraping/internal/postgres"
+)
+
+// scrape runs one scrape of a city without the server, for cron jobs and
+// debugging selectors. The movies replace the city's saved listings unless
+// -dry-run is set, in which case they are only printed. Movies new to the city
+// are queued in the outbox, so a running server tells change listeners such as
+// the Telegram bot about them.
+func scrape(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("scrape", flag.ContinueOnError)
+	cityName := flags.String("city", "", "city to scrape, by name, alias or BookMyShow slug")
+	dryRun := flags.Bool("dry-run", false, "print the movies as JSON instead of storing them")
+
+	cfg, err := config.Parse(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(*cityName) == "" {
+		return errors.New("scrape: -city is required")
+	}
+
+	registry, err := cities.NewRegistry(registryCities(cfg.Cities))
+	if err != nil {
+		return fmt.Errorf("register cities: %w", err)
+	}
+
+	city := strings.ToLower(strings.TrimSpace(*cityName))
+	if registered, ok := registry.Resolve(city); ok {
+		city = registered.Name
+	}
+
+	if *dryRun {
+		logger, err := logging.New(os.Stderr, cfg.LogFormat, cfg.LogLevel)
+		if err != nil {
+			return fmt.Errorf("configure logging: %w", err)
+		}
+
+		scraped, err := scrapeCity(ctx, cfg, city, logger)
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(scraped)
+	}
+
+	logger, pool, err := connect(ctx, cfg, os.Stderr)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	scraped, err := scrapeCity(ctx, cfg, city, logger)
+	if err != nil {
+		return err
+	}
+
+	if err := postgres.NewMovieRepository(pool).ReplaceCity(ctx, city, scraped, time.Now()); err != nil {
+		return fmt.Errorf("save movies: %w", err)
+	}
+
+	logger.InfoContext(ctx, "saved movies", "city", city, "movies", len(scraped))
+
+	return nil
+}
+
+func scrapeCity(ctx context.Context, cfg config.Config, city string, logger *slog.Logger) ([]movies.Movie, error) {
+	startedAt := time.Now()
+	logger.InfoContext(ctx, "scrape started", "city", city)
+
+	storage, err := objectStore(cfg.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	scraper := serviceScraper(cfg, bookmyshow.NewScraper(scraperOptions(cfg, scrapeArchive(cfg, storage))))
+
+	scraped, err := scraper.Scrape(ctx, city)
+	if err != nil {
+		return nil, fmt.Errorf("scrape movies: %w", err)
+	}
+
+	// An empty scrape would otherwise wipe the city's saved listings.
+	if len(scraped) == 0 {
+		return nil, fmt.Errorf("scrape movies: %w", movies.ErrScrapeEmpty)
+	}
+
+	logger.InfoContext(ctx, "scrape finished", "city", city, "duration", time.Since(startedAt), "movies", len(scraped))
+
+	return scraped, nil
+}
+
+// migrate applies the database schema and exits, so deployments can upgrade
+// the database before starting new servers.
+func migrate(ctx context.Context, args []string) error {
+	cfg, err := config.Parse(flag.NewFlagSet("migrate", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	logger, pool, err := connect(ctx, cfg, os.Stderr)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	if err := postgres.Migrate(ctx, pool); err != nil {
+		return fmt.Errorf("migrate database: %w", err)
+	}
+
+	logger.InfoContext(ctx, "database schema is up to date")
+
+	return nil
+}
+
+// seed migrates the database and saves the fixture listings for every
+// configured city, so the API has movies to serve without scraping. Cities
+// that already have movies are overwritten.
+func seed(ctx context.Context, args []string) error {
+	cfg, err := config.Parse(flag.NewFlagSet("seed", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	logger, pool, err := connect(ctx, cfg, os.Stderr)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	if err := postgres.Migrate(ctx, pool); err != nil {
+		return fmt.Errorf("migrate database: %w", err)
+	}
+
+	seedCities := fixtures.Cities()
+	for _, city := range cfg.Cities {
+		seedCities = append(seedCities, city.Name)
+	}
+	seedCities = append(seedCities, cfg.PreloadCities...)
+	slices.Sort(seedCities)
+
+	repo := postgres.NewMovieRepository(pool)
+	for _, city := range slices.Compact(seedCities) {
+		list := fixtures.Movies(city)
+		if err := repo.ReplaceCity(ctx, city, list, time.Now()); err != nil {
+			return fmt.Errorf("seed %s: %w", city, err)
+		}
+
+		logger.InfoContext(ctx, "seeded movies", "city", city, "movies", len(list))
+	}
+
+	return nil
+}
+
+// version prints the build details reported by GET /version.
+func version(_ context.Context, args []string) error {
+	if err := flag.NewFlagSet("version", flag.ContinueOnError).Parse(args); err != nil {
+		return err
+	}
+
+	info := buildinfo.Get()
+
+	fmt.Printf("version:  %s\n", info.Version)
+	if info.Commit != "" {
+		modified := ""
+		if info.Modified {
+			modified = " (modified)"
+		}
+		fmt.Printf("commit:   %s%s\n", info.Commit, modified)
+	}
+	if info.Date != "" {
+		fmt.Printf("built:    %s\n", info.Date)
+	}
+	fmt.Printf("go:       %s\n", info.GoVersion)
+
+	return nil
+}
+
+// export writes the search log to stdout, oldest search first, in the same
+// formats as the admin export endpoint.
+func export(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	city := flags.String("city", "", "only export searches in this city")
+	days := flags.Int("days", 7, "export searches from the last this many days")
+	format := flags.String("format", "csv", `output format, "csv" or "ndjson"`)
+
+	cfg, err := config.Parse(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if *format != "csv" && *format != "ndjson" {
+		return fmt.Errorf(`export: -format must be "csv" or "ndjson", got %q`, *format)
+	}
+
+	if *days < 1 {
+		return fmt.Errorf("export: -days must be positive, got %d", *days)
+	}
+
+	_, pool, err := connect(ctx, cfg, os.Stderr)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	since := time.Now().AddDate(0, 0, -*days)
+	searchLog := postgres.NewSearchLog(pool)
+
+	if *format == "ndjson" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+
+		err := searchLog.ExportSearches(ctx, *city, since, func(event movies.SearchEvent) error {
+			return encoder.Encode(event)
+		})
+		if err != nil {
+			return fmt.Errorf("export searches: %w", err)
+		}
+
+		return nil
+	}
+
+	writer := csv.NewWriter(os.Stdout)
+	if err := writer.Write(movies.SearchEventColumns); err != nil {
+		return err
+	}
+
+	err = searchLog.ExportSearches(ctx, *city, since, func(event movies.SearchEvent) error {
+		return writer.Write(event.Record())
+	})
+	if err != nil {
+		return fmt.Errorf("export searches: %w", err)
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
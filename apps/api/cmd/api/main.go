@@ -2,97 +2,247 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
-	"log"
-	"net"
-	"net/http"
+	"io"
+	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
-	"time"
 
-	"go-scraping/internal/bookmyshow"
+	"go-scraping/internal/alerts"
+	"go-scraping/internal/announce"
 	"go-scraping/internal/config"
+	"go-scraping/internal/digest"
+	"go-scraping/internal/logging"
 	"go-scraping/internal/movies"
+	"go-scraping/internal/outbox"
 	"go-scraping/internal/postgres"
-	"go-scraping/internal/web"
+	"go-scraping/internal/reminders"
+	"go-scraping/internal/social"
+	"go-scraping/internal/telegram"
+	"go-scraping/internal/webpush"
+
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func main() {
-	logger := log.New(os.Stdout, "", log.LstdFlags)
+const commandUsage = `Usage: api [command] [flags]
+
+Commands:
+  serve    run the API server (the default)
+  scrape   scrape one city and store or print its movies
+  migrate  create or upgrade the database schema
+  seed     save fixture movies for local development
+  version  print the build's version, commit and date
+  export   write the search log as CSV or NDJSON
+
+Run "api <command> -h" for the flags of a command.
+`
+
+// commands maps each subcommand to the function that runs it with the
+// arguments following its name.
+var commands = map[string]func(ctx context.Context, args []string) error{
+	"serve":   serve,
+	"scrape":  scrape,
+	"migrate": migrate,
+	"seed":    seed,
+	"version": version,
+	"export":  export,
+}
 
-	if err := run(logger); err != nil {
-		logger.Fatal(err)
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := run(ctx, os.Args[1:])
+	stop()
+
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(2)
+	case err != nil:
+		slog.Error("api exited", "error", err)
+		os.Exit(1)
 	}
 }
 
-func run(logger *log.Logger) error {
-	cfg := config.Load()
+// run dispatches to the command named by the first argument. Without one,
+// or when the first argument is a flag, the server is started, so existing
+// deployments that pass only flags keep working.
+func run(ctx context.Context, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return serve(ctx, args)
+	}
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
+	if args[0] == "help" {
+		fmt.Print(commandUsage)
+		return nil
+	}
 
-	pool, err := postgres.NewPool(ctx, cfg)
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprint(os.Stderr, commandUsage)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	return command(ctx, args[1:])
+}
+
+func serve(ctx context.Context, args []string) error {
+	cfg, err := config.Parse(flag.NewFlagSet("serve", flag.ContinueOnError), args)
 	if err != nil {
-		return fmt.Errorf("connect to database: %w", err)
+		return err
+	}
+
+	logger, pool, err := connect(ctx, cfg, os.Stdout)
+	if err != nil {
+		return err
 	}
 	defer pool.Close()
 
-	logger.Println("Connected to database...")
+	if err := postgres.Migrate(ctx, pool); err != nil {
+		return fmt.Errorf("migrate database: %w", err)
+	}
 
-	repo := postgres.NewMovieRepository(pool)
-	scraper := bookmyshow.NewScraper(cfg.ScrapeTimeout)
-	service := movies.NewMovieService(repo, scraper, cfg.CacheTTL, logger)
+	s, err := newServer(ctx, cfg, args, pool, logger)
+	if err != nil {
+		return err
+	}
 
-	mux := http.NewServeMux()
-	web.RegisterMovieRoutes(mux, service, cfg.DefaultCity, logger)
+	return s.Run(ctx)
+}
 
-	server := &http.Server{
-		Addr: cfg.ServerAddr,
-		Handler: web.Chain(
-			mux,
-			web.CORSMiddleware(),
-			web.LoggingMiddleware(logger),
-			web.RecoverMiddleware(logger),
-		),
+// connect sets up logging to w and opens the database pool.
+func connect(ctx context.Context, cfg config.Config, w io.Writer) (*slog.Logger, *pgxpool.Pool, error) {
+	logger, err := logging.New(w, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		return nil, nil, fmt.Errorf("configure logging: %w", err)
 	}
+	slog.SetDefault(logger)
 
-	listener, err := net.Listen("tcp", cfg.ServerAddr)
+	pool, err := postgres.NewPool(ctx, cfg)
 	if err != nil {
-		return err
+		return nil, nil, fmt.Errorf("connect to database: %w", err)
 	}
 
-	go func() {
-		if err := service.Preload(ctx, cfg.PreloadCities); err != nil && !errors.Is(err, context.Canceled) {
-			logger.Printf("Initial movie preload completed with errors: %v", err)
-		}
-	}()
+	logger.Info("connected to database", "host", cfg.DBHost, "port", cfg.DBPort)
+
+	return logger, pool, nil
+}
+
+// alertNotifier combines every configured alert destination, returning nil
+// when none is configured.
+func alertNotifier(cfg config.AlertConfig) alerts.Notifier {
+	var notifiers alerts.MultiNotifier
+
+	if cfg.WebhookURL != "" {
+		notifiers = append(notifiers, alerts.NewWebhookNotifier(cfg.WebhookURL))
+	}
+
+	if cfg.SlackWebhookURL != "" {
+		notifiers = append(notifiers, alerts.NewSlackNotifier(cfg.SlackWebhookURL))
+	}
 
-	serverErr := make(chan error, 1)
-	go func() {
-		logger.Printf("Server starting on %s...", cfg.ServerAddr)
+	if cfg.SMTPAddr != "" && cfg.EmailFrom != "" && len(cfg.EmailTo) > 0 {
+		notifiers = append(notifiers, alerts.NewEmailNotifier(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.EmailFrom, cfg.EmailTo))
+	}
 
-		err := server.Serve(listener)
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			serverErr <- err
-			return
+	if len(notifiers) == 0 {
+		return nil
+	}
+
+	return notifiers
+}
+
+func announcementNotifiers(cfg map[string]config.AnnouncementConfig) map[string][]announce.Notifier {
+	notifiers := make(map[string][]announce.Notifier)
+	for city, channels := range cfg {
+		city = strings.ToLower(strings.TrimSpace(city))
+
+		if channels.DiscordWebhookURL != "" {
+			notifiers[city] = append(notifiers[city], announce.NewDiscordNotifier(channels.DiscordWebhookURL))
 		}
 
-		serverErr <- nil
-	}()
+		if channels.SlackWebhookURL != "" {
+			notifiers[city] = append(notifiers[city], announce.NewSlackNotifier(channels.SlackWebhookURL))
+		}
+	}
 
-	select {
-	case err := <-serverErr:
-		return err
-	case <-ctx.Done():
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+	return notifiers
+}
+
+// changeDestination delivers the movies_added messages of the outbox to
+// listener. A message is acknowledged only once the listener succeeds, so one
+// that cannot be decoded or that the listener fails to send is retried.
+func changeDestination(name string, listener movies.ChangeListener) outbox.Destination {
+	return outbox.Destination{
+		Name: name,
+		Deliver: func(ctx context.Context, payload []byte) error {
+			var added movies.AddedMovies
+			if err := json.Unmarshal(payload, &added); err != nil {
+				return fmt.Errorf("decode added movies: %w", err)
+			}
+
+			return listener.MoviesAdded(ctx, added.City, added.Movies)
+		},
+	}
+}
+
+// reminderDeliverers returns a deliverer for each channel whose sender is
+// configured.
+func reminderDeliverers(mailer *digest.SMTPMailer, pushNotifier *webpush.Notifier, bot *telegram.Bot) map[reminders.Channel]reminders.Deliverer {
+	deliverers := make(map[reminders.Channel]reminders.Deliverer)
+
+	if mailer != nil {
+		deliverers[reminders.ChannelEmail] = reminders.NewEmailDeliverer(mailer)
+	}
 
-		if err := server.Shutdown(shutdownCtx); err != nil {
-			return fmt.Errorf("shutdown server: %w", err)
+	if pushNotifier != nil {
+		deliverers[reminders.ChannelPush] = reminders.NewPushDeliverer(pushNotifier)
+	}
+
+	if bot != nil {
+		deliverers[reminders.ChannelTelegram] = reminders.NewTelegramDeliverer(bot)
+	}
+
+	return deliverers
+}
+
+func socialAccounts(cfg config.SocialConfig) []social.Account {
+	var accounts []social.Account
+
+	if cfg.Mastodon.AccessToken != "" {
+		accounts = append(accounts, social.NewMastodon(cfg.Mastodon.Server, cfg.Mastodon.AccessToken))
+	}
+
+	if cfg.X.AccessToken != "" {
+		accounts = append(accounts, social.NewX(social.XCredentials{
+			ConsumerKey:       cfg.X.ConsumerKey,
+			ConsumerSecret:    cfg.X.ConsumerSecret,
+			AccessToken:       cfg.X.AccessToken,
+			AccessTokenSecret: cfg.X.AccessTokenSecret,
+		}))
+	}
+
+	return accounts
+}
+
+// socialTemplates returns the post template of each enabled city.
+func socialTemplates(cfg config.SocialConfig) map[string]string {
+	templates := make(map[string]string)
+	for city, cityCfg := range cfg.Cities {
+		if !cityCfg.Enabled {
+			continue
+		}
+
+		template := cfg.Template
+		if cityCfg.Template != "" {
+			template = cityCfg.Template
 		}
 
-		return <-serverErr
+		templates[strings.ToLower(strings.TrimSpace(city))] = template
 	}
+
+	return templates
 }
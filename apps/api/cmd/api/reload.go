@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go-scraping/internal/alerts"
+	"go-scraping/internal/backup"
+	"go-scraping/internal/bookmyshow"
+	"go-scraping/internal/cities"
+	"go-scraping/internal/config"
+	"go-scraping/internal/digest"
+	"go-scraping/internal/fixtures"
+	"go-scraping/internal/flags"
+	"go-scraping/internal/jobs"
+	"go-scraping/internal/movies"
+	"go-scraping/internal/objectstore"
+	"go-scraping/internal/web"
+)
+
+// reloader re-reads the configuration on SIGHUP or from the admin endpoint and
+// applies the settings that can change without a restart: the preload cities,
+// the city registry, job schedules, scraper options, CORS origins, and feature
+// flags. Storage settings take effect only after a restart. The server keeps
+// serving throughout, so no in-flight request is dropped.
+//
+// It also schedules the cities onboarded at runtime, and registers those
+// onboarded on other replicas when it reloads.
+type reloader struct {
+	args      []string
+	service   movies.Service
+	scheduler *jobs.Scheduler
+	scraper   *bookmyshow.Scraper
+	archive   objectstore.Store
+	cities    *cities.Registry
+	onboarded cities.Store
+	cors      *web.CORSOrigins
+	monitor   *alerts.Monitor
+	digest    *digest.Digest
+	backups   *backup.Backup
+	flags     *flags.Set
+	logger    *slog.Logger
+
+	mu  sync.Mutex
+	cfg config.Config
+}
+
+func (r *reloader) Reload(ctx context.Context) error {
+	next, err := config.Load(r.args)
+	if err != nil {
+		return err
+	}
+
+	onboarded, err := r.onboarded.ListCities(ctx)
+	if err != nil {
+		return fmt.Errorf("load onboarded cities: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := preloadCities(r.cfg, r.cities)
+
+	if err := r.cities.Set(registryCities(next.Cities)); err != nil {
+		return fmt.Errorf("register cities: %w", err)
+	}
+
+	if err := r.cities.Add(onboarded...); err != nil {
+		return fmt.Errorf("register onboarded cities: %w", err)
+	}
+
+	preload := preloadCities(next, r.cities)
+	for _, city := range current {
+		if !slices.Contains(preload, city) {
+			_ = r.scheduler.Remove(refreshJobName(city))
+		}
+	}
+
+	registerJobs(r.scheduler, r.service, r.monitor, r.digest, r.backups, next, preload)
+
+	if r.monitor != nil {
+		r.monitor.SetCities(preload)
+	}
+
+	r.scraper.SetOptions(scraperOptions(next, r.archive))
+	r.cors.Set(next.CORSOrigins)
+	r.flags.SetDefaults(configFlags(next.Flags))
+
+	if restartRequired(r.cfg, next) {
+		r.logger.WarnContext(ctx, "some changed settings take effect only after a restart")
+	}
+
+	r.cfg = next
+	r.logger.InfoContext(ctx, "reloaded config", "cities", preload)
+
+	return nil
+}
+
+// ScheduleCity refreshes a city onboarded at runtime like the preload cities,
+// queueing its first scrape right away when this replica is the scheduler
+// leader.
+func (r *reloader) ScheduleCity(ctx context.Context, city cities.City) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cfg.ScrapingDisabled {
+		return false, nil
+	}
+
+	r.scheduler.Register(refreshJob(r.service, r.cfg, city.Name))
+
+	if r.monitor != nil {
+		r.monitor.SetCities(preloadCities(r.cfg, r.cities))
+	}
+
+	if err := r.scheduler.Trigger(refreshJobName(city.Name)); err != nil {
+		if errors.Is(err, jobs.ErrNotLeader) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// preloadCities returns the cities kept fresh in the background: the preload
+// cities in cfg and every city onboarded at runtime.
+func preloadCities(cfg config.Config, registry *cities.Registry) []string {
+	preload := slices.Clone(cfg.PreloadCities)
+	for _, city := range registry.Added() {
+		if !slices.Contains(preload, city.Name) {
+			preload = append(preload, city.Name)
+		}
+	}
+
+	return preload
+}
+
+// registerJobs registers the background jobs for cfg, with a refresh job for
+// each of the preload cities, replacing the definitions of jobs that are
+// already registered.
+func registerJobs(scheduler *jobs.Scheduler, service movies.Service, monitor *alerts.Monitor, emailDigest *digest.Digest, backups *backup.Backup, cfg config.Config, preload []string) {
+	// Read-only replicas never scrape, so they have nothing to refresh.
+	if cfg.ScrapingDisabled {
+		preload = nil
+	}
+
+	for _, city := range preload {
+		scheduler.Register(refreshJob(service, cfg, city))
+	}
+
+	scheduler.Register(jobs.Job{
+		Name:        "cleanup",
+		Interval:    cfg.CleanupInterval,
+		MaxFailures: cfg.JobMaxFailures,
+		Run: func(ctx context.Context) error {
+			return service.Cleanup(ctx, time.Now().Add(-cfg.DataRetention))
+		},
+	})
+
+	if monitor != nil {
+		scheduler.Register(jobs.Job{
+			Name:     "alerts",
+			Interval: cfg.Alerts.CheckInterval,
+			Run:      monitor.Check,
+		})
+	}
+
+	// The digest keeps the interval it was created with, since each digest
+	// covers exactly one interval.
+	if emailDigest != nil {
+		scheduler.Register(jobs.Job{
+			Name:        "digest",
+			Interval:    emailDigest.Interval(),
+			MaxFailures: cfg.JobMaxFailures,
+			Run:         emailDigest.Send,
+		})
+	}
+
+	// Backups likewise keep the interval and storage they were created with.
+	if backups != nil {
+		scheduler.Register(jobs.Job{
+			Name:        "backup",
+			Interval:    backups.Interval(),
+			MaxFailures: cfg.JobMaxFailures,
+			Run:         backups.Run,
+		})
+	}
+}
+
+// refreshJob scrapes city every refresh interval, and as soon as jobs start.
+func refreshJob(service movies.Service, cfg config.Config, city string) jobs.Job {
+	return jobs.Job{
+		Name:        refreshJobName(city),
+		Interval:    cfg.RefreshInterval,
+		Jitter:      cfg.RefreshJitter,
+		MaxFailures: cfg.JobMaxFailures,
+		RunOnStart:  true,
+		Run: func(ctx context.Context) error {
+			return service.Preload(ctx, []string{city})
+		},
+	}
+}
+
+func refreshJobName(city string) string {
+	return "refresh:" + city
+}
+
+// serviceScraper returns the scraper the movie service uses: scraper itself,
+// the fixture listings when FAKE_SCRAPER is set, generated listings when
+// SYNTHETIC_MOVIES is set, or the recordings in SCRAPE_REPLAY_DIR.
+func serviceScraper(cfg config.Config, scraper *bookmyshow.Scraper) movies.Scraper {
+	if cfg.FakeScraper {
+		return fixtures.NewScraper()
+	}
+
+	if cfg.SyntheticMovies > 0 {
+		return fixtures.NewSyntheticScraper(cfg.SyntheticMovies)
+	}
+
+	if cfg.ReplayDir != "" {
+		return bookmyshow.NewReplayScraper(cfg.ReplayDir)
+	}
+
+	return scraper
+}
+
+// scraperOptions returns the scraper options for cfg, with each city that
+// names a region scraped from that region's site and every scrape kept in
+// archive, unless it is nil.
+func scraperOptions(cfg config.Config, archive objectstore.Store) bookmyshow.Options {
+	opts := bookmyshow.Options{
+		Timeout:      cfg.Scraper.Timeout,
+		URLTemplate:  cfg.Scraper.URLTemplate,
+		LinkSelector: cfg.Scraper.LinkSelector,
+		SettleDelay:  cfg.Scraper.SettleDelay,
+		RecordDir:    cfg.Scraper.RecordDir,
+		Mirrors:      cfg.Scraper.Mirrors,
+		Archive:      archive,
+		CityRegions:  make(map[string]bookmyshow.Region),
+	}
+
+	for _, city := range cfg.Cities {
+		region, ok := cfg.Scraper.Regions[city.Region]
+		if !ok {
+			continue
+		}
+
+		opts.CityRegions[strings.ToLower(strings.TrimSpace(city.Name))] = bookmyshow.Region{
+			URLTemplate:  region.URLTemplate,
+			LinkSelector: region.LinkSelector,
+			Mirrors:      region.Mirrors,
+		}
+	}
+
+	return opts
+}
+
+// objectStore returns the store cfg selects, or nil when storage is disabled.
+func objectStore(cfg config.StorageConfig) (objectstore.Store, error) {
+	if cfg.Backend == "" {
+		return nil, nil
+	}
+
+	store, err := objectstore.New(objectstore.Config{
+		Backend:         cfg.Backend,
+		Bucket:          cfg.Bucket,
+		Endpoint:        cfg.Endpoint,
+		Region:          cfg.Region,
+		AccessKeyID:     cfg.AccessKeyID,
+		SecretAccessKey: cfg.SecretAccessKey,
+		CredentialsFile: cfg.CredentialsFile,
+		Dir:             cfg.Dir,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("configure storage: %w", err)
+	}
+
+	return store, nil
+}
+
+// scrapeArchive returns where scrapes are archived, or nil when they are not.
+func scrapeArchive(cfg config.Config, storage objectstore.Store) objectstore.Store {
+	if !cfg.Storage.ArchiveScrapes {
+		return nil
+	}
+
+	return storage
+}
+
+func registryCities(cfg []config.CityConfig) []cities.City {
+	registered := make([]cities.City, 0, len(cfg))
+	for _, city := range cfg {
+		registered = append(registered, cities.City{
+			Name:        city.Name,
+			DisplayName: city.DisplayName,
+			Timezone:    city.Timezone,
+			Aliases:     city.Aliases,
+		})
+	}
+
+	return registered
+}
+
+// configFlags returns the feature flags defined in the config file.
+func configFlags(cfg map[string]config.FlagConfig) []flags.Flag {
+	defined := make([]flags.Flag, 0, len(cfg))
+	for name, flag := range cfg {
+		cities := make([]string, 0, len(flag.Cities))
+		for _, city := range flag.Cities {
+			cities = append(cities, strings.ToLower(strings.TrimSpace(city)))
+		}
+
+		defined = append(defined, flags.Flag{Name: name, Enabled: flag.Enabled, Cities: cities, Percent: flag.Percent})
+	}
+
+	return defined
+}
+
+// restartRequired reports whether next changes any setting that a reload
+// does not apply.
+func restartRequired(current, next config.Config) bool {
+	next.PreloadCities = current.PreloadCities
+	next.Cities = current.Cities
+	next.RefreshInterval = current.RefreshInterval
+	next.RefreshJitter = current.RefreshJitter
+	next.CleanupInterval = current.CleanupInterval
+	next.DataRetention = current.DataRetention
+	next.JobMaxFailures = current.JobMaxFailures
+	next.Scraper = current.Scraper
+	next.CORSOrigins = current.CORSOrigins
+	next.Flags = current.Flags
+	next.Alerts.CheckInterval = current.Alerts.CheckInterval
+
+	return !reflect.DeepEqual(current, next)
+}
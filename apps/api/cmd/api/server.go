@@ -0,0 +1,470 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"go-scraping/internal/alerts"
+	"go-scraping/internal/announce"
+	"go-scraping/internal/backup"
+	"go-scraping/internal/bookmyshow"
+	"go-scraping/internal/browser"
+	"go-scraping/internal/buildinfo"
+	"go-scraping/internal/cities"
+	"go-scraping/internal/config"
+	"go-scraping/internal/digest"
+	"go-scraping/internal/flags"
+	"go-scraping/internal/frontend"
+	"go-scraping/internal/jobs"
+	"go-scraping/internal/movies"
+	"go-scraping/internal/objectstore"
+	"go-scraping/internal/omdb"
+	"go-scraping/internal/outbox"
+	"go-scraping/internal/postgres"
+	"go-scraping/internal/ratelimit"
+	"go-scraping/internal/reminders"
+	"go-scraping/internal/social"
+	"go-scraping/internal/telegram"
+	"go-scraping/internal/tmdb"
+	"go-scraping/internal/usage"
+	"go-scraping/internal/users"
+	"go-scraping/internal/watchlist"
+	"go-scraping/internal/web"
+	"go-scraping/internal/webpush"
+)
+
+// server holds the API's long-lived dependencies. newServer wires them from
+// the config, and Run serves requests and runs background work until the
+// context is cancelled.
+type server struct {
+	cfg       config.Config
+	logger    *slog.Logger
+	pool      *pgxpool.Pool
+	service   movies.Service
+	scheduler *jobs.Scheduler
+	reloader  *reloader
+	http      *http.Server
+
+	// workers deliver the outbox's notifications and run the Telegram bot's
+	// polling and other background loops.
+	workers []worker
+}
+
+type worker interface {
+	Run(ctx context.Context)
+}
+
+// newServer wires the API for cfg, which was loaded from args; the same args
+// are read again when the configuration is reloaded.
+func newServer(ctx context.Context, cfg config.Config, args []string, pool *pgxpool.Pool, logger *slog.Logger) (*server, error) {
+	storage, err := objectStore(cfg.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := scrapeArchive(cfg, storage)
+
+	repo := postgres.NewMovieRepository(pool)
+	scraper := bookmyshow.NewScraper(scraperOptions(cfg, archive))
+	featureFlags := flags.New(configFlags(cfg.Flags), postgres.NewFlagStore(pool), logger)
+	serviceOpts := movies.ServiceOptions{
+		CacheTTL:       cfg.CacheTTL,
+		StalePolicy:    movies.StalePolicy(cfg.StalePolicy),
+		SearchMinScore: cfg.SearchMinScore,
+		SearchBackend:  cfg.SearchBackend,
+		SearchLog:      postgres.NewSearchLog(pool),
+		RequestLog:     postgres.NewRequestLog(pool),
+		Aliases:        postgres.NewAliasStore(pool),
+		TitleVariants:  postgres.NewTitleVariantStore(pool),
+		CitySettings:   postgres.NewCitySettingsStore(pool),
+		Flags:          featureFlags,
+
+		MaxConcurrentScrapes: cfg.MaxConcurrentScrapes,
+		ScrapeQueueTimeout:   cfg.ScrapeQueueTimeout,
+		ScrapingDisabled:     cfg.ScrapingDisabled,
+	}
+	if checks := cfg.ScrapeChecks; checks.Enabled {
+		serviceOpts.Quarantine = postgres.NewQuarantineStore(pool)
+		serviceOpts.Quality = movies.QualityOptions{
+			MaxCountDrop:  checks.MaxCountDrop,
+			MaxDuplicates: checks.MaxDuplicates,
+			LinkDomains:   checks.LinkDomains,
+		}
+	}
+	if cfg.Ratings.OMDbAPIKey != "" {
+		serviceOpts.Ratings = omdb.NewClient(cfg.Ratings.OMDbAPIKey)
+		serviceOpts.RatingsStore = postgres.NewRatingsStore(pool)
+		serviceOpts.RatingsTTL = cfg.Ratings.TTL
+	}
+	if cfg.Streaming.TMDBAPIKey != "" {
+		client := tmdb.NewClient(cfg.Streaming.TMDBAPIKey, strings.ToUpper(cfg.Streaming.Region))
+		serviceOpts.Streaming = client
+		serviceOpts.StreamingTTL = cfg.Streaming.TTL
+		serviceOpts.Trailers = client
+	}
+	listings := serviceScraper(cfg, scraper)
+	service := movies.NewMovieService(repo, listings, serviceOpts, logger)
+
+	mux := http.NewServeMux()
+	registry, err := cities.NewRegistry(registryCities(cfg.Cities))
+	if err != nil {
+		return nil, fmt.Errorf("register cities: %w", err)
+	}
+
+	cityStore := postgres.NewCityStore(pool)
+	onboarded, err := cityStore.ListCities(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load onboarded cities: %w", err)
+	}
+	if err := registry.Add(onboarded...); err != nil {
+		return nil, fmt.Errorf("register onboarded cities: %w", err)
+	}
+
+	var (
+		userAccounts *users.Service
+		preferences  web.PreferenceSource
+		usageTracker *usage.Tracker
+	)
+	if cfg.Auth.JWTSecret != "" {
+		userAccounts, err = users.NewService(postgres.NewUserStore(pool), users.Options{
+			Secret:     cfg.Auth.JWTSecret,
+			AccessTTL:  cfg.Auth.AccessTTL,
+			RefreshTTL: cfg.Auth.RefreshTTL,
+		}, logger)
+		if err != nil {
+			return nil, fmt.Errorf("configure user accounts: %w", err)
+		}
+
+		preferences = userAccounts
+	}
+
+	web.RegisterMovieRoutes(mux, service, registry, preferences, cfg.DefaultCity, logger)
+	web.RegisterTriggerRoutes(mux, service, registry, cfg.DefaultCity, logger)
+	web.RegisterChangeRoutes(mux, service, registry, cfg.DefaultCity, logger)
+	web.RegisterHistoryRoutes(mux, service, registry, cfg.DefaultCity, logger)
+	web.RegisterRunsRoutes(mux, service, registry, cfg.DefaultCity, logger)
+	web.RegisterPopularRoutes(mux, service, registry, cfg.DefaultCity, logger)
+	web.RegisterEventRoutes(mux, service, registry, logger)
+	web.RegisterTrendingRoutes(mux, service, registry, cfg.DefaultCity, logger)
+	web.RegisterRandomRoutes(mux, service, registry, cfg.DefaultCity, logger)
+	web.RegisterAvailabilityRoutes(mux, service, registry, logger)
+	web.RegisterRedirectRoutes(mux, service, registry, cfg.DefaultCity, logger)
+	web.RegisterQRRoutes(mux, service, registry, cfg.DefaultCity, cfg.PublicURL, logger)
+	web.RegisterTrailerRoutes(mux, service, registry, cfg.DefaultCity, logger)
+	web.RegisterWidgetRoutes(mux, service, registry, cfg.DefaultCity, logger)
+	if cfg.PreviewImages {
+		var previews objectstore.Store
+		if cfg.Storage.ArchivePreviews {
+			previews = storage
+		}
+		web.RegisterPreviewRoutes(mux, service, browser.NewRenderer(), registry, cfg.DefaultCity, previews, logger)
+	}
+	if cfg.WebUI {
+		web.RegisterWebUIRoutes(mux, service, registry, cfg.DefaultCity, logger)
+	}
+	if cfg.EmbeddedFrontend {
+		files, err := frontend.Files()
+		if err != nil {
+			return nil, fmt.Errorf("serve frontend: %w", err)
+		}
+
+		web.RegisterFrontendRoutes(mux, files)
+	}
+	web.RegisterAdminRoutes(mux, service, logger)
+	web.RegisterFlagRoutes(mux, featureFlags, registry, logger)
+	web.RegisterSourceRoutes(mux, scraper)
+
+	var (
+		monitor       *alerts.Monitor
+		panicReporter web.PanicReporter
+	)
+	if notifier := alertNotifier(cfg.Alerts); notifier != nil {
+		monitor = alerts.NewMonitor(service, notifier, alerts.MonitorOptions{
+			Cities:           preloadCities(cfg, registry),
+			MaxFailureStreak: cfg.Alerts.FailureStreak,
+			MaxDataAge:       cfg.Alerts.MaxDataAge,
+		}, logger)
+		panicReporter = alerts.NewPanicReporter(notifier, logger)
+	}
+
+	// The dispatcher runs even without destinations, so that the messages
+	// saved scrapes queue are cleared instead of piling up. Movies a scrape
+	// added reach the digest, push and Telegram through it, so a crash right
+	// after saving the scrape delays their notifications instead of losing
+	// them.
+	dispatcher := outbox.NewDispatcher(postgres.NewOutboxStore(pool), logger)
+
+	var (
+		emailDigest *digest.Digest
+		mailer      *digest.SMTPMailer
+	)
+	if cfg.Digest.SMTPAddr != "" && cfg.Digest.From != "" {
+		mailer = digest.NewSMTPMailer(cfg.Digest.SMTPAddr, cfg.Digest.SMTPUsername, cfg.Digest.SMTPPassword, cfg.Digest.From)
+		emailDigest = digest.New(postgres.NewDigestStore(pool), mailer, registry, digest.Options{
+			Interval:  cfg.Digest.Interval,
+			PublicURL: cfg.Digest.PublicURL,
+		}, logger)
+		dispatcher.Subscribe(movies.TopicMoviesAdded, changeDestination("digest", emailDigest))
+		web.RegisterDigestRoutes(mux, emailDigest, logger)
+	}
+
+	var pushNotifier *webpush.Notifier
+	if cfg.Push.VAPIDPrivateKey != "" {
+		vapid, err := webpush.ParseVAPID(cfg.Push.VAPIDPublicKey, cfg.Push.VAPIDPrivateKey, cfg.Push.Subject)
+		if err != nil {
+			return nil, fmt.Errorf("configure push notifications: %w", err)
+		}
+
+		pushNotifier = webpush.NewNotifier(vapid, postgres.NewPushSubscriptions(pool), registry, logger)
+		dispatcher.Subscribe(movies.TopicMoviesAdded, changeDestination("push", pushNotifier))
+		web.RegisterPushRoutes(mux, pushNotifier, logger)
+	}
+
+	if userAccounts != nil {
+		web.RegisterAuthRoutes(mux, userAccounts, logger)
+		web.RegisterPreferenceRoutes(mux, userAccounts, registry, logger)
+
+		if google := cfg.Auth.Google; google.ClientID != "" {
+			provider := users.NewGoogle(google.ClientID, google.ClientSecret, google.RedirectURL)
+			web.RegisterGoogleAuthRoutes(mux, provider, userAccounts, cfg.Auth.FrontendURL, logger)
+		}
+
+		watchlists := watchlist.New(postgres.NewWatchlistStore(pool), service, logger)
+		web.RegisterWatchlistRoutes(mux, watchlists, userAccounts, registry, cfg.DefaultCity, logger)
+
+		usageTracker = usage.New(postgres.NewUsageStore(pool), logger)
+		web.RegisterUsageRoutes(mux, usageTracker, userAccounts, logger)
+	}
+
+	var publisher *social.Publisher
+	if accounts := socialAccounts(cfg.Social); len(accounts) > 0 {
+		templates, err := social.ParseTemplates(socialTemplates(cfg.Social))
+		if err != nil {
+			return nil, fmt.Errorf("configure social posts: %w", err)
+		}
+
+		publisher = social.NewPublisher(accounts, templates, registry, logger)
+		service.AddRefreshListener(publisher)
+	}
+
+	var (
+		backups        *backup.Backup
+		backupReporter web.BackupReporter
+	)
+	if b := cfg.Backup; b.Bucket != "" || b.Enabled {
+		// A bucket of the backups' own takes precedence over the shared
+		// storage, which backups used before it existed.
+		var store backup.Store = storage
+		if b.Bucket != "" {
+			store = objectstore.NewS3(b.Endpoint, b.Region, b.Bucket, b.AccessKeyID, b.SecretAccessKey)
+		}
+
+		backups = backup.New(
+			backup.NewPGDump(b.PGDumpPath, cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword),
+			store,
+			backup.Options{Interval: b.Interval, Retention: b.Retention, Prefix: b.Prefix},
+			logger,
+		)
+		backupReporter = backups
+	}
+
+	scheduler := jobs.NewScheduler(logger)
+	registerJobs(scheduler, service, monitor, emailDigest, backups, cfg, preloadCities(cfg, registry))
+
+	reminderStore := postgres.NewReminderStore(pool)
+
+	var bot *telegram.Bot
+	if cfg.Telegram.BotToken != "" {
+		botOpts := telegram.Options{Leader: scheduler.Leader}
+		if userAccounts != nil {
+			botOpts.Linker = reminders.NewTelegramLinker(reminderStore, userAccounts)
+		}
+
+		subscriptions := postgres.NewTelegramSubscriptions(pool)
+		bot = telegram.NewBot(cfg.Telegram.BotToken, service, registry, subscriptions, botOpts, logger)
+		dispatcher.Subscribe(movies.TopicMoviesAdded, changeDestination("telegram", bot))
+	}
+
+	var releaseReminders *reminders.Reminders
+	if userAccounts != nil {
+		releaseReminders = reminders.New(reminderStore, reminderDeliverers(mailer, pushNotifier, bot), registry, logger)
+		service.AddRefreshListener(releaseReminders)
+		web.RegisterReminderRoutes(mux, releaseReminders, userAccounts, logger)
+	}
+
+	if notifiers := announcementNotifiers(cfg.Announcements); len(notifiers) > 0 {
+		dispatcher.Subscribe(movies.TopicMoviesAdded, announce.NewAnnouncer(notifiers, registry).Destinations()...)
+	}
+
+	cors := web.NewCORSOrigins(cfg.CORSOrigins)
+	reloader := &reloader{
+		args:      args,
+		service:   service,
+		scheduler: scheduler,
+		scraper:   scraper,
+		archive:   archive,
+		cities:    registry,
+		onboarded: cityStore,
+		cors:      cors,
+		monitor:   monitor,
+		digest:    emailDigest,
+		backups:   backups,
+		flags:     featureFlags,
+		logger:    logger,
+		cfg:       cfg,
+	}
+
+	web.RegisterHealthRoutes(mux)
+	web.RegisterVersionRoutes(mux)
+	web.RegisterJobRoutes(mux, scheduler, logger)
+	web.RegisterDashboardRoutes(mux, service, scheduler, registry, logger)
+	web.RegisterMetricsRoutes(mux, service, backupReporter, logger)
+	web.RegisterConfigRoutes(mux, reloader, logger)
+
+	// Read-only replicas never scrape, so they cannot check or refresh a new
+	// city; they register the cities onboarded elsewhere when they reload.
+	if !cfg.ScrapingDisabled {
+		web.RegisterCityRoutes(mux, cities.NewOnboarding(registry, cityStore, service, reloader, logger), logger)
+	}
+
+	middlewares := []web.Middleware{
+		web.CORSMiddleware(cors),
+		web.RequestIDMiddleware(),
+		web.LoggingMiddleware(logger),
+		web.RecoverMiddleware(logger, panicReporter),
+	}
+	if limits := cfg.RateLimit; limits.Requests > 0 {
+		var store ratelimit.Store = ratelimit.NewMemoryStore()
+		if limits.RedisURL != "" {
+			redisStore, err := ratelimit.NewRedisStore(limits.RedisURL)
+			if err != nil {
+				return nil, fmt.Errorf("configure rate limiting: %w", err)
+			}
+			store = ratelimit.NewFallbackStore(redisStore, store, logger)
+		}
+
+		limiter := ratelimit.New(store, ratelimit.Options{Requests: limits.Requests, Window: limits.Window}, logger)
+		middlewares = append(middlewares, web.RateLimitMiddleware(limiter, preferences, limits.TrustForwardedFor))
+	}
+	middlewares = append(middlewares, web.AdminMiddleware(cfg.AdminToken))
+	middlewares = append(middlewares, web.IdempotencyMiddleware(postgres.NewIdempotencyKeys(pool, cfg.IdempotencyTTL), logger))
+	if usageTracker != nil {
+		middlewares = append(middlewares, web.UsageMiddleware(userAccounts, usageTracker))
+	}
+	middlewares = append(middlewares, web.TrafficMiddleware(service))
+
+	httpServer := &http.Server{
+		Addr:     cfg.ServerAddr,
+		Handler:  web.Chain(mux, middlewares...),
+		ErrorLog: slog.NewLogLogger(logger.Handler(), slog.LevelError),
+	}
+
+	s := &server{
+		cfg:       cfg,
+		logger:    logger,
+		pool:      pool,
+		service:   service,
+		scheduler: scheduler,
+		reloader:  reloader,
+		http:      httpServer,
+		workers:   []worker{dispatcher},
+	}
+
+	// Replicas that start Chrome clean up after it.
+	if !cfg.ScrapingDisabled || cfg.PreviewImages {
+		s.workers = append(s.workers, browser.NewReaper(logger))
+	}
+
+	// Checked one by one, since a nil pointer stored in the interface would
+	// not compare equal to nil.
+	if bot != nil {
+		s.workers = append(s.workers, bot)
+	}
+	if publisher != nil {
+		s.workers = append(s.workers, publisher)
+	}
+	if releaseReminders != nil {
+		s.workers = append(s.workers, releaseReminders)
+	}
+
+	return s, nil
+}
+
+// Run serves requests and runs the background jobs and workers until ctx is
+// cancelled, then shuts everything down.
+func (s *server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.cfg.ServerAddr)
+	if err != nil {
+		return err
+	}
+
+	// The server starts before the jobs so that it answers health checks and
+	// reads while the refresh jobs preload cities in the background.
+	serverErr := make(chan error, 1)
+	go func() {
+		s.logger.Info("server starting", "addr", s.cfg.ServerAddr, "version", buildinfo.Version)
+
+		err := s.http.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+			return
+		}
+
+		serverErr <- nil
+	}()
+
+	s.scheduler.StartElected(ctx, postgres.NewLeaderElector(s.pool, s.logger))
+	defer s.scheduler.Wait()
+
+	for _, worker := range s.workers {
+		go worker.Run(ctx)
+	}
+
+	reloadSignals := make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+	defer signal.Stop(reloadSignals)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-reloadSignals:
+				if err := s.reloader.Reload(ctx); err != nil {
+					s.logger.ErrorContext(ctx, "failed to reload config", "error", err)
+				}
+			}
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// Scrapes are cancelled first so requests waiting on them fail fast
+		// instead of holding up the server shutdown.
+		if err := s.service.Shutdown(shutdownCtx); err != nil {
+			s.logger.Error("failed to shut down service", "error", err)
+		}
+
+		if err := s.http.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("shutdown server: %w", err)
+		}
+
+		return <-serverErr
+	}
+}
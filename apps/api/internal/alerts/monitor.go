@@ -0,0 +1,201 @@
+// Package alerts watches per-city scrape health and notifies operators when a
+// city keeps failing to refresh or its data grows too old, so stale listings
+// do not go unnoticed for days.
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"go-scraping/internal/movies"
+)
+
+const (
+	KindFailureStreak = "failure_streak"
+	KindStaleData     = "stale_data"
+)
+
+type Alert struct {
+	Kind          string    `json:"kind"`
+	City          string    `json:"city"`
+	Resolved      bool      `json:"resolved"`
+	Message       string    `json:"message"`
+	FailureStreak int       `json:"failure_streak,omitempty"`
+	AgeSeconds    float64   `json:"age_seconds,omitempty"`
+	FiredAt       time.Time `json:"fired_at"`
+
+	// RequestID and Stack are set on panic alerts.
+	RequestID string `json:"request_id,omitempty"`
+	Stack     string `json:"stack,omitempty"`
+}
+
+// Summary is a one-line description of the alert for chat and email subjects.
+func (a Alert) Summary() string {
+	status := "FIRING"
+	if a.Resolved {
+		status = "RESOLVED"
+	}
+
+	if a.City == "" {
+		return fmt.Sprintf("[%s] %s", status, a.Message)
+	}
+
+	return fmt.Sprintf("[%s] %s: %s", status, a.City, a.Message)
+}
+
+type statsProvider interface {
+	Stats(ctx context.Context) (movies.CacheStats, error)
+}
+
+type MonitorOptions struct {
+	// Cities are the cities expected to stay fresh.
+	Cities []string
+
+	// MaxFailureStreak fires an alert once a city has failed this many
+	// scrapes in a row. Zero disables the check.
+	MaxFailureStreak int
+
+	// MaxDataAge fires an alert once a city's last successful scrape is older
+	// than this. Zero disables the check.
+	MaxDataAge time.Duration
+}
+
+// Monitor fires an alert when a condition starts and a resolved alert when it
+// clears, rather than on every check.
+type Monitor struct {
+	stats    statsProvider
+	notifier Notifier
+	opts     MonitorOptions
+	logger   *slog.Logger
+
+	mu     sync.Mutex
+	cities []string
+	firing map[string]bool
+}
+
+func NewMonitor(stats statsProvider, notifier Notifier, opts MonitorOptions, logger *slog.Logger) *Monitor {
+	return &Monitor{
+		stats:    stats,
+		notifier: notifier,
+		opts:     opts,
+		logger:   logger,
+		cities:   opts.Cities,
+		firing:   make(map[string]bool),
+	}
+}
+
+// SetCities replaces the cities checked from the next check on. Alerts still
+// firing for a dropped city are forgotten without a resolved alert.
+func (m *Monitor) SetCities(cities []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cities = cities
+
+	kept := make(map[string]bool, len(cities))
+	for _, city := range cities {
+		kept[city] = true
+	}
+
+	for key := range m.firing {
+		if _, city, _ := strings.Cut(key, ":"); !kept[city] {
+			delete(m.firing, key)
+		}
+	}
+}
+
+// Check evaluates every city once and sends alerts for conditions that
+// started or cleared since the previous check.
+func (m *Monitor) Check(ctx context.Context) error {
+	stats, err := m.stats.Stats(ctx)
+	if err != nil {
+		return fmt.Errorf("load cache stats: %w", err)
+	}
+
+	byCity := make(map[string]movies.CityCacheStats, len(stats.Cities))
+	for _, city := range stats.Cities {
+		byCity[city.City] = city
+	}
+
+	now := time.Now()
+
+	m.mu.Lock()
+	cities := m.cities
+	m.mu.Unlock()
+
+	var alerts []Alert
+	for _, city := range cities {
+		entry := byCity[city]
+
+		if m.opts.MaxFailureStreak > 0 {
+			failing := entry.FailureStreak >= m.opts.MaxFailureStreak
+			message := fmt.Sprintf("%d consecutive scrape failures, last error: %s", entry.FailureStreak, entry.LastError)
+			if !failing {
+				message = "scrapes are succeeding again"
+			}
+
+			alerts = m.transition(alerts, Alert{
+				Kind:          KindFailureStreak,
+				City:          city,
+				Message:       message,
+				FailureStreak: entry.FailureStreak,
+				FiredAt:       now,
+			}, failing)
+		}
+
+		if m.opts.MaxDataAge > 0 && entry.ScrapedAt != nil {
+			age := time.Duration(entry.AgeSeconds * float64(time.Second))
+			stale := age > m.opts.MaxDataAge
+			message := fmt.Sprintf("data is %s old, older than %s", age.Round(time.Minute), m.opts.MaxDataAge)
+			if !stale {
+				message = "data is fresh again"
+			}
+
+			alerts = m.transition(alerts, Alert{
+				Kind:       KindStaleData,
+				City:       city,
+				Message:    message,
+				AgeSeconds: entry.AgeSeconds,
+				FiredAt:    now,
+			}, stale)
+		}
+	}
+
+	var notifyErrs []error
+	for _, alert := range alerts {
+		m.logger.WarnContext(ctx, "sending alert", "kind", alert.Kind, "city", alert.City, "resolved", alert.Resolved, "message", alert.Message)
+
+		if err := m.notifier.Notify(ctx, alert); err != nil {
+			notifyErrs = append(notifyErrs, fmt.Errorf("notify %s alert for %s: %w", alert.Kind, alert.City, err))
+			continue
+		}
+
+		// The state only changes once the alert is delivered, so a failed
+		// notification is retried on the next check.
+		m.mu.Lock()
+		m.firing[alert.Kind+":"+alert.City] = !alert.Resolved
+		m.mu.Unlock()
+	}
+
+	return errors.Join(notifyErrs...)
+}
+
+// transition appends alert to alerts if the condition changed state since the
+// last delivered alert.
+func (m *Monitor) transition(alerts []Alert, alert Alert, active bool) []Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.firing[alert.Kind+":"+alert.City] == active {
+		return alerts
+	}
+
+	alert.Resolved = !active
+
+	return append(alerts, alert)
+}
@@ -0,0 +1,143 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"go-scraping/internal/movies"
+)
+
+type fakeStats struct {
+	mu    sync.Mutex
+	stats movies.CacheStats
+}
+
+func (f *fakeStats) Stats(_ context.Context) (movies.CacheStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.stats, nil
+}
+
+func (f *fakeStats) set(cities ...movies.CityCacheStats) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.stats = movies.CacheStats{Cities: cities}
+}
+
+type fakeNotifier struct {
+	alerts []Alert
+	err    error
+}
+
+func (f *fakeNotifier) Notify(_ context.Context, alert Alert) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	f.alerts = append(f.alerts, alert)
+	return nil
+}
+
+func testMonitor(stats statsProvider, notifier Notifier) *Monitor {
+	return NewMonitor(stats, notifier, MonitorOptions{
+		Cities:           []string{"cuttack"},
+		MaxFailureStreak: 3,
+		MaxDataAge:       48 * time.Hour,
+	}, slog.New(slog.DiscardHandler))
+}
+
+func TestMonitorFiresOnceAndResolvesFailureStreaks(t *testing.T) {
+	t.Parallel()
+
+	stats := &fakeStats{}
+	notifier := &fakeNotifier{}
+	monitor := testMonitor(stats, notifier)
+
+	stats.set(movies.CityCacheStats{City: "cuttack", FailureStreak: 3, LastError: "blocked"})
+	for range 2 {
+		if err := monitor.Check(context.Background()); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+	}
+
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Kind != KindFailureStreak || notifier.alerts[0].Resolved {
+		t.Fatalf("alerts = %+v, want one firing failure streak alert", notifier.alerts)
+	}
+
+	stats.set(movies.CityCacheStats{City: "cuttack"})
+	if err := monitor.Check(context.Background()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if len(notifier.alerts) != 2 || !notifier.alerts[1].Resolved {
+		t.Fatalf("alerts = %+v, want a resolved alert after recovery", notifier.alerts)
+	}
+}
+
+func TestMonitorFiresOnStaleData(t *testing.T) {
+	t.Parallel()
+
+	scrapedAt := time.Now().Add(-72 * time.Hour)
+	stats := &fakeStats{}
+	stats.set(movies.CityCacheStats{City: "cuttack", ScrapedAt: &scrapedAt, AgeSeconds: (72 * time.Hour).Seconds()})
+
+	notifier := &fakeNotifier{}
+	if err := testMonitor(stats, notifier).Check(context.Background()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Kind != KindStaleData {
+		t.Fatalf("alerts = %+v, want one stale data alert", notifier.alerts)
+	}
+}
+
+func TestMonitorRetriesUndeliveredAlerts(t *testing.T) {
+	t.Parallel()
+
+	stats := &fakeStats{}
+	stats.set(movies.CityCacheStats{City: "cuttack", FailureStreak: 5})
+
+	notifier := &fakeNotifier{err: errors.New("webhook down")}
+	monitor := testMonitor(stats, notifier)
+
+	if err := monitor.Check(context.Background()); err == nil {
+		t.Fatal("Check() error = nil, want notify error")
+	}
+
+	notifier.err = nil
+	if err := monitor.Check(context.Background()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if len(notifier.alerts) != 1 {
+		t.Fatalf("alerts = %+v, want the undelivered alert retried", notifier.alerts)
+	}
+}
+
+func TestMonitorSetCitiesChangesCheckedCities(t *testing.T) {
+	t.Parallel()
+
+	stats := &fakeStats{}
+	notifier := &fakeNotifier{}
+	monitor := testMonitor(stats, notifier)
+
+	stats.set(
+		movies.CityCacheStats{City: "cuttack", FailureStreak: 3},
+		movies.CityCacheStats{City: "puri", FailureStreak: 3},
+	)
+	monitor.SetCities([]string{"puri"})
+
+	if err := monitor.Check(context.Background()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if len(notifier.alerts) != 1 || notifier.alerts[0].City != "puri" {
+		t.Fatalf("alerts = %+v, want one alert for puri", notifier.alerts)
+	}
+}
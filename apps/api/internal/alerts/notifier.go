@@ -0,0 +1,121 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+const notifyTimeout = 10 * time.Second
+
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// WebhookNotifier posts each alert as JSON to an arbitrary URL.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: notifyTimeout}}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	return postJSON(ctx, n.client, n.url, alert)
+}
+
+// SlackNotifier posts each alert's summary to a Slack incoming webhook.
+type SlackNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewSlackNotifier(url string) *SlackNotifier {
+	return &SlackNotifier{url: url, client: &http.Client{Timeout: notifyTimeout}}
+}
+
+func (n *SlackNotifier) Notify(ctx context.Context, alert Alert) error {
+	return postJSON(ctx, n.client, n.url, map[string]string{"text": alert.Summary()})
+}
+
+// EmailNotifier sends each alert as a plain-text email over SMTP.
+type EmailNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+}
+
+// NewEmailNotifier sends through the SMTP server at addr (host:port),
+// authenticating with PLAIN auth when username is set.
+func NewEmailNotifier(addr, username, password, from string, to []string) *EmailNotifier {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := strings.Cut(addr, ":")
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &EmailNotifier{addr: addr, auth: auth, from: from, to: to}
+}
+
+func (n *EmailNotifier) Notify(_ context.Context, alert Alert) error {
+	body := alert.Message
+	if alert.RequestID != "" {
+		body += "\n\nRequest ID: " + alert.RequestID
+	}
+	if alert.Stack != "" {
+		body += "\n\n" + alert.Stack
+	}
+
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n",
+		n.from, strings.Join(n.to, ", "), alert.Summary(), strings.ReplaceAll(body, "\n", "\r\n"))
+
+	return smtp.SendMail(n.addr, n.auth, n.from, n.to, []byte(message))
+}
+
+// MultiNotifier sends every alert to all of its notifiers.
+type MultiNotifier []Notifier
+
+func (m MultiNotifier) Notify(ctx context.Context, alert Alert) error {
+	var notifyErrs []error
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, alert); err != nil {
+			notifyErrs = append(notifyErrs, err)
+		}
+	}
+
+	return errors.Join(notifyErrs...)
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("send alert: unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSlackNotifierPostsSummary(t *testing.T) {
+	t.Parallel()
+
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	alert := Alert{Kind: KindStaleData, City: "cuttack", Message: "data is 72h0m0s old"}
+	if err := NewSlackNotifier(server.URL).Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if payload["text"] != "[FIRING] cuttack: data is 72h0m0s old" {
+		t.Fatalf("text = %q, want firing summary", payload["text"])
+	}
+}
+
+func TestWebhookNotifierReportsErrorStatus(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier(server.URL).Notify(context.Background(), Alert{City: "cuttack"}); err == nil {
+		t.Fatal("Notify() error = nil, want status error")
+	}
+}
@@ -0,0 +1,73 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const KindPanic = "panic"
+
+// panicCooldown is how long the same panic at the same path is not reported
+// again, so a handler that panics on every request does not flood the
+// notifiers.
+const panicCooldown = 10 * time.Minute
+
+// Panic describes a panic recovered while serving a request.
+type Panic struct {
+	RequestID string
+	Method    string
+	Path      string
+	Value     string
+	Stack     string
+}
+
+// PanicReporter sends recovered panics to the alert notifiers, acting as the
+// service's error tracker.
+type PanicReporter struct {
+	notifier Notifier
+	logger   *slog.Logger
+
+	mu       sync.Mutex
+	reported map[string]time.Time
+}
+
+func NewPanicReporter(notifier Notifier, logger *slog.Logger) *PanicReporter {
+	return &PanicReporter{
+		notifier: notifier,
+		logger:   logger,
+		reported: make(map[string]time.Time),
+	}
+}
+
+func (r *PanicReporter) Report(ctx context.Context, p Panic) {
+	now := time.Now()
+	key := p.Path + "\x00" + p.Value
+
+	r.mu.Lock()
+	if last, ok := r.reported[key]; ok && now.Sub(last) < panicCooldown {
+		r.mu.Unlock()
+		return
+	}
+	r.reported[key] = now
+	for reportedKey, at := range r.reported {
+		if now.Sub(at) >= panicCooldown {
+			delete(r.reported, reportedKey)
+		}
+	}
+	r.mu.Unlock()
+
+	alert := Alert{
+		Kind:      KindPanic,
+		Message:   fmt.Sprintf("panic serving %s %s: %s", p.Method, p.Path, p.Value),
+		RequestID: p.RequestID,
+		Stack:     p.Stack,
+		FiredAt:   now,
+	}
+
+	if err := r.notifier.Notify(ctx, alert); err != nil {
+		r.logger.ErrorContext(ctx, "failed to report panic", "error", err)
+	}
+}
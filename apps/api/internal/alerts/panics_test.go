@@ -0,0 +1,32 @@
+package alerts
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestPanicReporterSkipsRepeatedPanics(t *testing.T) {
+	t.Parallel()
+
+	notifier := &fakeNotifier{}
+	reporter := NewPanicReporter(notifier, slog.New(slog.DiscardHandler))
+
+	boom := Panic{RequestID: "req-1", Method: "GET", Path: "/movies", Value: "boom", Stack: "goroutine 1"}
+	reporter.Report(context.Background(), boom)
+	reporter.Report(context.Background(), boom)
+	reporter.Report(context.Background(), Panic{Method: "GET", Path: "/suggest", Value: "boom"})
+
+	if len(notifier.alerts) != 2 {
+		t.Fatalf("sent %d alerts, want 2", len(notifier.alerts))
+	}
+
+	alert := notifier.alerts[0]
+	if alert.Kind != KindPanic || alert.RequestID != "req-1" || alert.Stack != "goroutine 1" {
+		t.Fatalf("alert = %+v, want panic alert with request ID and stack", alert)
+	}
+
+	if got, want := alert.Summary(), "[FIRING] panic serving GET /movies: boom"; got != want {
+		t.Fatalf("Summary() = %q, want %q", got, want)
+	}
+}
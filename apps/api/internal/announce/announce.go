@@ -0,0 +1,116 @@
+// Package announce posts "New this week" messages to chat channels when a
+// scrape finds new movies in a city.
+package announce
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+	"go-scraping/internal/outbox"
+)
+
+const notifyTimeout = 10 * time.Second
+
+// Announcement lists the movies a scrape found for the first time in a city.
+type Announcement struct {
+	City        string
+	DisplayName string
+	Movies      []movies.Movie
+}
+
+func (a Announcement) Title() string {
+	return "New this week in " + a.DisplayName
+}
+
+// Notifier posts an announcement to one channel.
+type Notifier interface {
+	Announce(ctx context.Context, announcement Announcement) error
+}
+
+type cityResolver interface {
+	Resolve(name string) (cities.City, bool)
+}
+
+// Announcer posts new movies to the notifiers configured for their city. The
+// movies come from the outbox, so an announcement survives a crash and a
+// failed post is retried.
+type Announcer struct {
+	notifiers map[string][]Notifier
+	cities    cityResolver
+}
+
+// NewAnnouncer posts announcements for each city to notifiers[city]. Cities
+// without notifiers are not announced.
+func NewAnnouncer(notifiers map[string][]Notifier, cities cityResolver) *Announcer {
+	return &Announcer{notifiers: notifiers, cities: cities}
+}
+
+// Destinations returns an outbox destination for each notifier, for the
+// movies.TopicMoviesAdded topic. They are named after the city and the
+// notifier's position in its list, so a retry skips the notifiers that
+// already posted.
+func (a *Announcer) Destinations() []outbox.Destination {
+	cityNames := make([]string, 0, len(a.notifiers))
+	for city := range a.notifiers {
+		cityNames = append(cityNames, city)
+	}
+	sort.Strings(cityNames)
+
+	var destinations []outbox.Destination
+	for _, city := range cityNames {
+		for i, notifier := range a.notifiers[city] {
+			destinations = append(destinations, outbox.Destination{
+				Name: fmt.Sprintf("announce:%s:%d", city, i),
+				Key:  city,
+				Deliver: func(ctx context.Context, payload []byte) error {
+					return a.announce(ctx, notifier, payload)
+				},
+			})
+		}
+	}
+
+	return destinations
+}
+
+func (a *Announcer) announce(ctx context.Context, notifier Notifier, payload []byte) error {
+	var added movies.AddedMovies
+	if err := json.Unmarshal(payload, &added); err != nil {
+		return fmt.Errorf("decode added movies: %w", err)
+	}
+
+	resolved, _ := a.cities.Resolve(added.City)
+
+	return notifier.Announce(ctx, Announcement{City: added.City, DisplayName: resolved.DisplayName, Movies: added.Movies})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode announcement: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build announcement request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send announcement: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("send announcement: unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
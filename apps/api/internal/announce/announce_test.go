@@ -0,0 +1,139 @@
+package announce
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+func captureServer(t *testing.T) (*httptest.Server, func() map[string]string) {
+	t.Helper()
+
+	var (
+		mu      sync.Mutex
+		payload map[string]string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() map[string]string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return payload
+	}
+}
+
+var testAnnouncement = Announcement{
+	City:        "bhubaneswar",
+	DisplayName: "Bhubaneswar",
+	Movies: []movies.Movie{
+		{Title: "Tom & Jerry", Href: "https://in.bookmyshow.com/tom-and-jerry"},
+		{Title: "F1"},
+	},
+}
+
+func TestDiscordNotifierPostsMarkdownList(t *testing.T) {
+	t.Parallel()
+
+	server, payload := captureServer(t)
+
+	if err := NewDiscordNotifier(server.URL).Announce(context.Background(), testAnnouncement); err != nil {
+		t.Fatalf("Announce() error = %v", err)
+	}
+
+	want := "**New this week in Bhubaneswar**\n- [Tom & Jerry](<https://in.bookmyshow.com/tom-and-jerry>)\n- F1"
+	if got := payload()["content"]; got != want {
+		t.Fatalf("content = %q, want %q", got, want)
+	}
+}
+
+func TestSlackNotifierPostsEscapedLinks(t *testing.T) {
+	t.Parallel()
+
+	server, payload := captureServer(t)
+
+	if err := NewSlackNotifier(server.URL).Announce(context.Background(), testAnnouncement); err != nil {
+		t.Fatalf("Announce() error = %v", err)
+	}
+
+	want := "*New this week in Bhubaneswar*\n\u2022 <https://in.bookmyshow.com/tom-and-jerry|Tom &amp; Jerry>\n\u2022 F1"
+	if got := payload()["text"]; got != want {
+		t.Fatalf("text = %q, want %q", got, want)
+	}
+}
+
+type fakeNotifier struct {
+	mu            sync.Mutex
+	announcements []Announcement
+}
+
+func (f *fakeNotifier) Announce(_ context.Context, announcement Announcement) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.announcements = append(f.announcements, announcement)
+	return nil
+}
+
+func TestAnnouncerPostsOnlyToTheCitysNotifiers(t *testing.T) {
+	t.Parallel()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar"}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	notifier := &fakeNotifier{}
+	announcer := NewAnnouncer(map[string][]Notifier{"bhubaneswar": {notifier}}, registry)
+
+	destinations := announcer.Destinations()
+	if len(destinations) != 1 || destinations[0].Key != "bhubaneswar" || destinations[0].Name != "announce:bhubaneswar:0" {
+		t.Fatalf("destinations = %+v, want one keyed to bhubaneswar", destinations)
+	}
+
+	payload, err := json.Marshal(movies.AddedMovies{City: "bhubaneswar", Movies: []movies.Movie{{Title: "F1"}}})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	if err := destinations[0].Deliver(context.Background(), payload); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+
+	if len(notifier.announcements) != 1 || notifier.announcements[0].DisplayName != "Bhubaneswar" || notifier.announcements[0].Movies[0].Title != "F1" {
+		t.Fatalf("announcements = %+v, want F1 for Bhubaneswar", notifier.announcements)
+	}
+}
+
+func TestTruncateLinesNotesDroppedLines(t *testing.T) {
+	t.Parallel()
+
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = strings.Repeat("x", 50)
+	}
+
+	text := truncateLines("header", lines, maxDiscordLength)
+	if len(text) > maxDiscordLength || !strings.HasSuffix(text, "more") {
+		t.Fatalf("len(text) = %d, want at most %d ending with a dropped count", len(text), maxDiscordLength)
+	}
+
+	if got := truncateLines("header", lines[:2], maxDiscordLength); strings.Contains(got, "more") {
+		t.Fatalf("truncateLines() = %q, want every line", got)
+	}
+}
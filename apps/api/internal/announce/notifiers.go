@@ -0,0 +1,91 @@
+package announce
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxDiscordLength is Discord's limit on the length of a message.
+const maxDiscordLength = 2000
+
+// DiscordNotifier posts announcements to a Discord channel webhook.
+type DiscordNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewDiscordNotifier(url string) *DiscordNotifier {
+	return &DiscordNotifier{url: url, client: &http.Client{Timeout: notifyTimeout}}
+}
+
+func (n *DiscordNotifier) Announce(ctx context.Context, announcement Announcement) error {
+	lines := make([]string, 0, len(announcement.Movies))
+	for _, movie := range announcement.Movies {
+		if movie.Href == "" {
+			lines = append(lines, "- "+movie.Title)
+			continue
+		}
+
+		// Angle brackets stop Discord from unfurling a preview for every link.
+		lines = append(lines, fmt.Sprintf("- [%s](<%s>)", movie.Title, movie.Href))
+	}
+
+	content := truncateLines("**"+announcement.Title()+"**", lines, maxDiscordLength)
+
+	return postJSON(ctx, n.client, n.url, map[string]string{"content": content})
+}
+
+// SlackNotifier posts announcements to a Slack incoming webhook.
+type SlackNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewSlackNotifier(url string) *SlackNotifier {
+	return &SlackNotifier{url: url, client: &http.Client{Timeout: notifyTimeout}}
+}
+
+func (n *SlackNotifier) Announce(ctx context.Context, announcement Announcement) error {
+	var text strings.Builder
+	text.WriteString("*" + slackEscape(announcement.Title()) + "*")
+
+	for _, movie := range announcement.Movies {
+		if movie.Href == "" {
+			fmt.Fprintf(&text, "\n\u2022 %s", slackEscape(movie.Title))
+			continue
+		}
+
+		fmt.Fprintf(&text, "\n\u2022 <%s|%s>", movie.Href, slackEscape(movie.Title))
+	}
+
+	return postJSON(ctx, n.client, n.url, map[string]string{"text": text.String()})
+}
+
+// slackEscape escapes the characters Slack treats as markup.
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// truncateLines joins header and lines with newlines, dropping trailing lines
+// that would exceed limit and noting how many were left out.
+func truncateLines(header string, lines []string, limit int) string {
+	text := header
+	for i, line := range lines {
+		next := text + "\n" + line
+
+		var more string
+		if remaining := len(lines) - i - 1; remaining > 0 {
+			more = fmt.Sprintf("\n\u2026and %d more", remaining)
+		}
+
+		if len(next)+len(more) > limit {
+			return text + fmt.Sprintf("\n\u2026and %d more", len(lines)-i)
+		}
+
+		text = next
+	}
+
+	return text
+}
@@ -0,0 +1,164 @@
+// Package backup periodically dumps the database and uploads the dumps to
+// object storage, deleting the ones that are older than the retention.
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go-scraping/internal/objectstore"
+)
+
+// Dumper writes a dump of the database to w.
+type Dumper interface {
+	Dump(ctx context.Context, w io.Writer) error
+}
+
+// Store keeps the uploaded dumps.
+type Store interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64) error
+	List(ctx context.Context, prefix string) ([]objectstore.Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type Options struct {
+	// Interval is how often Run should run.
+	Interval time.Duration
+
+	// Retention is how long dumps are kept. Zero keeps every dump.
+	Retention time.Duration
+
+	// Prefix is prepended to the key of every dump, and only dumps under it
+	// are pruned.
+	Prefix string
+}
+
+// Status is the outcome of the most recent backups.
+type Status struct {
+	LastAttemptAt time.Time `json:"last_attempt_at"`
+	LastSuccessAt time.Time `json:"last_success_at"`
+	LastError     string    `json:"last_error,omitempty"`
+	LastKey       string    `json:"last_key,omitempty"`
+	LastSizeBytes int64     `json:"last_size_bytes"`
+}
+
+type Backup struct {
+	dumper Dumper
+	store  Store
+	opts   Options
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu     sync.Mutex
+	status Status
+}
+
+func New(dumper Dumper, store Store, opts Options, logger *slog.Logger) *Backup {
+	return &Backup{dumper: dumper, store: store, opts: opts, logger: logger, now: time.Now}
+}
+
+// Interval is how often Run should run.
+func (b *Backup) Interval() time.Duration {
+	return b.opts.Interval
+}
+
+// Status returns the outcome of the most recent backups on this replica.
+func (b *Backup) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.status
+}
+
+// Run dumps the database, uploads the dump, and deletes the dumps that are
+// older than the retention. A failure to prune does not fail the backup.
+func (b *Backup) Run(ctx context.Context) error {
+	startedAt := b.now().UTC()
+
+	key, size, err := b.upload(ctx, startedAt)
+
+	b.mu.Lock()
+	b.status.LastAttemptAt = startedAt
+	if err != nil {
+		b.status.LastError = err.Error()
+	} else {
+		b.status.LastSuccessAt = startedAt
+		b.status.LastError = ""
+		b.status.LastKey = key
+		b.status.LastSizeBytes = size
+	}
+	b.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	b.logger.InfoContext(ctx, "backed up database", "key", key, "bytes", size)
+
+	if err := b.prune(ctx, startedAt); err != nil {
+		b.logger.WarnContext(ctx, "failed to delete old backups", "error", err)
+	}
+
+	return nil
+}
+
+// upload dumps the database to a temporary file first, since the store needs
+// the size of the dump up front.
+func (b *Backup) upload(ctx context.Context, startedAt time.Time) (string, int64, error) {
+	file, err := os.CreateTemp("", "backup-*.dump")
+	if err != nil {
+		return "", 0, fmt.Errorf("create dump file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if err := b.dumper.Dump(ctx, file); err != nil {
+		return "", 0, err
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", 0, fmt.Errorf("size dump file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", 0, fmt.Errorf("rewind dump file: %w", err)
+	}
+
+	key := b.opts.Prefix + startedAt.Format("20060102T150405Z") + ".dump"
+	if err := b.store.Put(ctx, key, file, size); err != nil {
+		return "", 0, fmt.Errorf("upload %s: %w", key, err)
+	}
+
+	return key, size, nil
+}
+
+func (b *Backup) prune(ctx context.Context, now time.Time) error {
+	if b.opts.Retention <= 0 {
+		return nil
+	}
+
+	objects, err := b.store.List(ctx, b.opts.Prefix)
+	if err != nil {
+		return fmt.Errorf("list backups: %w", err)
+	}
+
+	cutoff := now.Add(-b.opts.Retention)
+	for _, object := range objects {
+		if !strings.HasSuffix(object.Key, ".dump") || !object.LastModified.Before(cutoff) {
+			continue
+		}
+
+		if err := b.store.Delete(ctx, object.Key); err != nil {
+			return fmt.Errorf("delete %s: %w", object.Key, err)
+		}
+		b.logger.InfoContext(ctx, "deleted old backup", "key", object.Key)
+	}
+
+	return nil
+}
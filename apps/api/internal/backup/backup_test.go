@@ -0,0 +1,139 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"go-scraping/internal/objectstore"
+)
+
+type fakeDumper struct {
+	dump string
+	err  error
+}
+
+func (f *fakeDumper) Dump(_ context.Context, w io.Writer) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	_, err := io.WriteString(w, f.dump)
+	return err
+}
+
+type fakeStore struct {
+	objects map[string]objectstore.Object
+	bodies  map[string]string
+	deleted []string
+}
+
+func (f *fakeStore) Put(_ context.Context, key string, body io.Reader, size int64) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) != size {
+		return errors.New("size does not match body")
+	}
+
+	f.bodies[key] = string(data)
+	f.objects[key] = objectstore.Object{Key: key, LastModified: time.Date(2025, 6, 10, 3, 0, 0, 0, time.UTC), Size: size}
+	return nil
+}
+
+func (f *fakeStore) List(_ context.Context, prefix string) ([]objectstore.Object, error) {
+	var objects []objectstore.Object
+	for key, object := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, object)
+		}
+	}
+
+	return objects, nil
+}
+
+func (f *fakeStore) Delete(_ context.Context, key string) error {
+	delete(f.objects, key)
+	f.deleted = append(f.deleted, key)
+	return nil
+}
+
+func testBackup(dumper Dumper, retention time.Duration) (*Backup, *fakeStore) {
+	store := &fakeStore{objects: make(map[string]objectstore.Object), bodies: make(map[string]string)}
+	backup := New(dumper, store, Options{Interval: 24 * time.Hour, Retention: retention, Prefix: "backups/"}, slog.New(slog.DiscardHandler))
+	backup.now = func() time.Time { return time.Date(2025, 6, 10, 3, 0, 0, 0, time.UTC) }
+
+	return backup, store
+}
+
+func TestRunUploadsDumpAndPrunesOldBackups(t *testing.T) {
+	t.Parallel()
+
+	backup, store := testBackup(&fakeDumper{dump: "PGDMP"}, 7*24*time.Hour)
+	store.objects["backups/20250601T030000Z.dump"] = objectstore.Object{Key: "backups/20250601T030000Z.dump", LastModified: time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)}
+	store.objects["backups/20250605T030000Z.dump"] = objectstore.Object{Key: "backups/20250605T030000Z.dump", LastModified: time.Date(2025, 6, 5, 3, 0, 0, 0, time.UTC)}
+	store.objects["backups/notes.txt"] = objectstore.Object{Key: "backups/notes.txt", LastModified: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	if err := backup.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if got := store.bodies["backups/20250610T030000Z.dump"]; got != "PGDMP" {
+		t.Fatalf("uploaded dump = %q, want PGDMP", got)
+	}
+
+	if len(store.deleted) != 1 || store.deleted[0] != "backups/20250601T030000Z.dump" {
+		t.Fatalf("deleted = %v, want only the dump older than the retention", store.deleted)
+	}
+
+	status := backup.Status()
+	if status.LastKey != "backups/20250610T030000Z.dump" || status.LastSizeBytes != 5 || status.LastSuccessAt.IsZero() || status.LastError != "" {
+		t.Fatalf("Status() = %+v, want a successful backup of 5 bytes", status)
+	}
+}
+
+func TestRunKeepsEveryBackupWithoutRetention(t *testing.T) {
+	t.Parallel()
+
+	backup, store := testBackup(&fakeDumper{dump: "PGDMP"}, 0)
+	store.objects["backups/20200101T030000Z.dump"] = objectstore.Object{Key: "backups/20200101T030000Z.dump", LastModified: time.Date(2020, 1, 1, 3, 0, 0, 0, time.UTC)}
+
+	if err := backup.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(store.deleted) != 0 {
+		t.Fatalf("deleted = %v, want none", store.deleted)
+	}
+}
+
+func TestRunRecordsFailureAndKeepsLastSuccess(t *testing.T) {
+	t.Parallel()
+
+	dumper := &fakeDumper{dump: "PGDMP"}
+	backup, store := testBackup(dumper, 0)
+
+	if err := backup.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	succeededAt := backup.Status().LastSuccessAt
+
+	dumper.err = errors.New("pg_dump: connection refused")
+	if err := backup.Run(context.Background()); err == nil {
+		t.Fatal("Run() error = nil, want the dump error")
+	}
+
+	status := backup.Status()
+	if status.LastError != "pg_dump: connection refused" || !status.LastSuccessAt.Equal(succeededAt) {
+		t.Fatalf("Status() = %+v, want the error and the earlier success", status)
+	}
+
+	if len(store.objects) != 1 {
+		t.Fatalf("stored %d objects, want only the successful dump", len(store.objects))
+	}
+}
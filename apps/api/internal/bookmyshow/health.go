@@ -0,0 +1,109 @@
+package bookmyshow
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// sourceRetryAfter is how long a source that failed is tried only after the
+// others.
+const sourceRetryAfter = 5 * time.Minute
+
+// SourceStatus is the health of one URL template scrapes are loaded from, as
+// of the last scrape that used it.
+type SourceStatus struct {
+	URLTemplate string     `json:"url_template"`
+	Healthy     bool       `json:"healthy"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastFailure *time.Time `json:"last_failure,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// sourceHealth checks sources passively, by the outcome of real scrapes, so
+// that a failing site costs at most one attempt every sourceRetryAfter.
+type sourceHealth struct {
+	mu      sync.Mutex
+	sources map[string]SourceStatus
+}
+
+// order returns urlTemplates with those that failed within sourceRetryAfter
+// moved to the end, keeping the configured order otherwise.
+func (h *sourceHealth) order(urlTemplates []string) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ordered := slices.Clone(urlTemplates)
+	slices.SortStableFunc(ordered, func(a, b string) int {
+		return boolOrder(h.backingOff(a), h.backingOff(b))
+	})
+
+	return ordered
+}
+
+func (h *sourceHealth) backingOff(urlTemplate string) bool {
+	status, ok := h.sources[urlTemplate]
+	return ok && !status.Healthy && time.Since(*status.LastFailure) < sourceRetryAfter
+}
+
+func (h *sourceHealth) succeeded(urlTemplate string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	status := h.sources[urlTemplate]
+	status.Healthy = true
+	status.LastSuccess = &now
+	h.set(urlTemplate, status)
+}
+
+func (h *sourceHealth) failed(urlTemplate string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	status := h.sources[urlTemplate]
+	status.Healthy = false
+	status.LastFailure = &now
+	status.LastError = err.Error()
+	h.set(urlTemplate, status)
+}
+
+func (h *sourceHealth) set(urlTemplate string, status SourceStatus) {
+	if h.sources == nil {
+		h.sources = make(map[string]SourceStatus)
+	}
+
+	status.URLTemplate = urlTemplate
+	h.sources[urlTemplate] = status
+}
+
+// statuses returns the status of each of urlTemplates. Sources that were
+// never scraped from are reported healthy.
+func (h *sourceHealth) statuses(urlTemplates []string) []SourceStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	result := make([]SourceStatus, 0, len(urlTemplates))
+	for _, urlTemplate := range urlTemplates {
+		status, ok := h.sources[urlTemplate]
+		if !ok {
+			status = SourceStatus{URLTemplate: urlTemplate, Healthy: true}
+		}
+
+		result = append(result, status)
+	}
+
+	return result
+}
+
+func boolOrder(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,32 @@
+package bookmyshow
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSourceHealthTriesFailedSourcesLast(t *testing.T) {
+	t.Parallel()
+
+	var health sourceHealth
+	sources := []string{"primary/%s", "mirror/%s", "backup/%s"}
+
+	health.failed("primary/%s", errors.New("navigation timeout"))
+	health.succeeded("mirror/%s")
+
+	if got, want := health.order(sources), []string{"mirror/%s", "backup/%s", "primary/%s"}; !slices.Equal(got, want) {
+		t.Fatalf("order() = %q, want %q", got, want)
+	}
+
+	statuses := health.statuses(sources)
+	if statuses[0].Healthy || statuses[0].LastError != "navigation timeout" || !statuses[1].Healthy || statuses[1].LastSuccess == nil || !statuses[2].Healthy {
+		t.Fatalf("statuses() = %+v, want primary failed, mirror succeeded and backup untried", statuses)
+	}
+
+	health.succeeded("primary/%s")
+
+	if got := health.order(sources); !slices.Equal(got, sources) {
+		t.Fatalf("order() after recovery = %q, want %q", got, sources)
+	}
+}
@@ -0,0 +1,71 @@
+package bookmyshow
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	parenthesizedYear = regexp.MustCompile(`\((\d{4})\)`)
+	bareYear          = regexp.MustCompile(`\b(\d{4})\b`)
+
+	// buzzCount matches the interest and vote counts shown on movie cards,
+	// such as "450K are interested", "45.3K Votes" or "1.2M+ likes".
+	buzzCount = regexp.MustCompile(`(?i)\b(\d+(?:\.\d+)?)\s*([KM])?\+?\s*(?:are interested|interested|votes|likes)\b`)
+)
+
+// buzz returns the largest interest or vote count in a movie card's text, or
+// zero when the card shows none.
+func buzz(details string) int {
+	var most int
+	for _, match := range buzzCount.FindAllStringSubmatch(details, -1) {
+		count, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			continue
+		}
+
+		switch strings.ToUpper(match[2]) {
+		case "K":
+			count *= 1e3
+		case "M":
+			count *= 1e6
+		}
+
+		most = max(most, int(math.Round(count)))
+	}
+
+	return most
+}
+
+// releaseYear extracts the release year for a movie card, preferring a year
+// in parentheses in the title ("Interstellar (2014)") and falling back to a
+// year anywhere in the rest of the card's text. Four-digit numbers that cannot
+// be a release year, such as the "2898" in "Kalki 2898 AD", are ignored.
+func releaseYear(title, details string, now time.Time) int {
+	if match := parenthesizedYear.FindStringSubmatch(title); match != nil {
+		if year := plausibleYear(match[1], now); year != 0 {
+			return year
+		}
+	}
+
+	details = strings.Replace(details, title, "", 1)
+	for _, match := range bareYear.FindAllStringSubmatch(details, -1) {
+		if year := plausibleYear(match[1], now); year != 0 {
+			return year
+		}
+	}
+
+	return 0
+}
+
+func plausibleYear(value string, now time.Time) int {
+	year, err := strconv.Atoi(value)
+	if err != nil || year < 1900 || year > now.Year()+1 {
+		return 0
+	}
+
+	return year
+}
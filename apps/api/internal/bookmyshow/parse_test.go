@@ -0,0 +1,47 @@
+package bookmyshow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReleaseYear(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		title   string
+		details string
+		want    int
+	}{
+		{"Interstellar (2014)", "Interstellar (2014)\nUA\nEnglish", 2014},
+		{"Interstellar", "Interstellar\nRe-release\nReleased 2014", 2014},
+		{"Kalki 2898 AD", "Kalki 2898 AD\nUA\nHindi, Telugu", 0},
+		{"Blade Runner 2049", "Blade Runner 2049", 0},
+		{"Ballerina", "", 0},
+	}
+
+	for _, tt := range tests {
+		if got := releaseYear(tt.title, tt.details, now); got != tt.want {
+			t.Fatalf("releaseYear(%q, %q) = %d, want %d", tt.title, tt.details, got, tt.want)
+		}
+	}
+}
+
+func TestBuzz(t *testing.T) {
+	t.Parallel()
+
+	for details, want := range map[string]int{
+		"War 2\nUA16+\nHindi\n450K are interested": 450000,
+		"Saiyaara\n8.9/10 45.3K Votes":             45300,
+		"Coolie\n1.2M+ likes\n12K votes":           1200000,
+		"Daskalia\n312 interested":                 312,
+		"Kalki 2898 AD\nUA\nHindi, Telugu":         0,
+		"":                                         0,
+	} {
+		if got := buzz(details); got != want {
+			t.Fatalf("buzz(%q) = %d, want %d", details, got, want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package bookmyshow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io/fs"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"go-scraping/internal/movies"
+	"go-scraping/internal/objectstore"
+)
+
+// Recording is what one scrape saw on the listings page, before parsing.
+type Recording struct {
+	City       string    `json:"city"`
+	URL        string    `json:"url"`
+	Title      string    `json:"title"`
+	Links      []Link    `json:"links"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// Link is a movie card matched by the link selector, as read from the page.
+type Link struct {
+	Text    string `json:"text"`
+	Href    string `json:"href"`
+	Details string `json:"details"`
+}
+
+var (
+	scriptElement = regexp.MustCompile(`(?is)<script\b.*?</script\s*>`)
+	headElement   = regexp.MustCompile(`(?i)<head\b[^>]*>`)
+)
+
+// recordingPath returns the path of the city's recording in dir with the
+// given extension. The city is escaped so it cannot name another directory.
+func recordingPath(dir, city, ext string) string {
+	return filepath.Join(dir, neturl.PathEscape(city)+ext)
+}
+
+// files returns the rendered page and the recording's JSON. Scripts are
+// removed from the page so it shows the rendered listings when opened again,
+// and a base URL is added so its relative links still point at BookMyShow.
+func (r Recording) files(page string) ([]byte, []byte, error) {
+	page = scriptElement.ReplaceAllString(page, "")
+	if location := headElement.FindStringIndex(page); location != nil {
+		base := fmt.Sprintf(`<base href="%s">`, html.EscapeString(r.URL))
+		page = page[:location[1]] + base + page[location[1]:]
+	}
+
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return []byte(page), append(content, '\n'), nil
+}
+
+// save writes the recording to <city>.json in dir and the rendered page to
+// <city>.html. Loading the page through SCRAPE_URL_TEMPLATE reruns the link
+// selector against the recorded markup.
+func (r Recording) save(dir, page string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	htmlFile, jsonFile, err := r.files(page)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(recordingPath(dir, r.City, ".html"), htmlFile, 0o644); err != nil {
+		return err
+	}
+
+	return os.WriteFile(recordingPath(dir, r.City, ".json"), jsonFile, 0o644)
+}
+
+// archive keeps the recording in store as scrapes/<city>/<time>.json and the
+// rendered page as scrapes/<city>/<time>.html, so every scrape can be looked
+// at later, unlike the recordings in a directory, which keep only the last.
+func (r Recording) archive(ctx context.Context, store objectstore.Store, page string) error {
+	htmlFile, jsonFile, err := r.files(page)
+	if err != nil {
+		return err
+	}
+
+	key := archiveKey(r.City, r.RecordedAt)
+	if err := objectstore.PutBytes(ctx, store, key+".html", htmlFile); err != nil {
+		return err
+	}
+
+	return objectstore.PutBytes(ctx, store, key+".json", jsonFile)
+}
+
+// archiveKey returns the key, without extension, of the city's scrape at
+// recordedAt.
+func archiveKey(city string, recordedAt time.Time) string {
+	return "scrapes/" + neturl.PathEscape(city) + "/" + recordedAt.UTC().Format("20060102T150405Z")
+}
+
+// LoadRecording reads the city's recording from dir.
+func LoadRecording(dir, city string) (Recording, error) {
+	content, err := os.ReadFile(recordingPath(dir, city, ".json"))
+	if err != nil {
+		return Recording{}, err
+	}
+
+	var recording Recording
+	if err := json.Unmarshal(content, &recording); err != nil {
+		return Recording{}, fmt.Errorf("parse recording for %s: %w", city, err)
+	}
+
+	return recording, nil
+}
+
+// ReplayScraper parses recorded scrapes instead of loading BookMyShow, so
+// parsing can be tested and developed deterministically and without Chrome.
+// Cities without a recording fail as if they were unknown.
+type ReplayScraper struct {
+	dir string
+}
+
+var _ movies.Scraper = (*ReplayScraper)(nil)
+
+func NewReplayScraper(dir string) *ReplayScraper {
+	return &ReplayScraper{dir: dir}
+}
+
+func (s *ReplayScraper) Scrape(ctx context.Context, city string) ([]movies.Movie, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	recording, err := LoadRecording(s.dir, city)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: no recording for %s", movies.ErrCityUnknown, city)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRecording(recording)
+}
@@ -0,0 +1,117 @@
+package bookmyshow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go-scraping/internal/movies"
+	"go-scraping/internal/objectstore"
+)
+
+func TestReplayScraperParsesRecordings(t *testing.T) {
+	t.Parallel()
+
+	scraper := NewReplayScraper(filepath.Join("testdata", "recordings"))
+
+	got, err := scraper.Scrape(context.Background(), "cuttack")
+	if err != nil {
+		t.Fatalf("Scrape() error = %v", err)
+	}
+
+	want := []movies.Movie{
+		{Title: "Superman", Href: "https://in.bookmyshow.com/movies/cuttack/superman/ET00414210"},
+		{Title: "Interstellar (2014)", Href: "https://in.bookmyshow.com/movies/cuttack/interstellar/ET00020354", Year: 2014},
+		{Title: "Bou Buttu Bhuta", Href: "https://in.bookmyshow.com/movies/cuttack/bou-buttu-bhuta/ET00438120", Year: 2025},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Scrape() = %+v, want %+v", got, want)
+	}
+
+	if _, err := scraper.Scrape(context.Background(), "mumbai"); !errors.Is(err, movies.ErrScrapeBlocked) {
+		t.Fatalf("Scrape(mumbai) error = %v, want ErrScrapeBlocked", err)
+	}
+
+	if _, err := scraper.Scrape(context.Background(), "puri"); !errors.Is(err, movies.ErrCityUnknown) {
+		t.Fatalf("Scrape(puri) error = %v, want ErrCityUnknown", err)
+	}
+}
+
+func TestRecordingSaveRoundTrips(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	recording := Recording{
+		City:       "cuttack",
+		URL:        "https://in.bookmyshow.com/explore/movies-cuttack",
+		Title:      "Movies in Cuttack",
+		Links:      []Link{{Text: "Superman", Href: "https://in.bookmyshow.com/movies/cuttack/superman/ET00414210"}},
+		RecordedAt: time.Date(2025, 7, 14, 10, 0, 0, 0, time.UTC),
+	}
+
+	page := `<html><head lang="en"><script>render()</script></head><body><a href="/movies/cuttack/superman/ET00414210">Superman</a></body></html>`
+	if err := recording.save(dir, page); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	loaded, err := LoadRecording(dir, "cuttack")
+	if err != nil || !reflect.DeepEqual(loaded, recording) {
+		t.Fatalf("LoadRecording() = %+v, %v, want %+v", loaded, err, recording)
+	}
+
+	saved, err := os.ReadFile(filepath.Join(dir, "cuttack.html"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	if strings.Contains(string(saved), "<script") || !strings.Contains(string(saved), `<head lang="en"><base href="https://in.bookmyshow.com/explore/movies-cuttack">`) {
+		t.Fatalf("saved page = %s, want scripts removed and a base URL added", saved)
+	}
+}
+
+func TestRecordingArchiveKeepsEveryScrape(t *testing.T) {
+	t.Parallel()
+
+	store := objectstore.NewLocal(t.TempDir())
+	ctx := context.Background()
+
+	for _, recordedAt := range []time.Time{
+		time.Date(2025, 7, 14, 10, 0, 0, 0, time.UTC),
+		time.Date(2025, 7, 14, 16, 0, 0, 0, time.UTC),
+	} {
+		recording := Recording{City: "new delhi", URL: "https://in.bookmyshow.com/explore/movies-new-delhi", RecordedAt: recordedAt}
+		if err := recording.archive(ctx, store, "<html><head></head></html>"); err != nil {
+			t.Fatalf("archive() error = %v", err)
+		}
+	}
+
+	objects, err := store.List(ctx, "scrapes/new%20delhi/")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	var keys []string
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+
+	want := []string{
+		"scrapes/new%20delhi/20250714T100000Z.html",
+		"scrapes/new%20delhi/20250714T100000Z.json",
+		"scrapes/new%20delhi/20250714T160000Z.html",
+		"scrapes/new%20delhi/20250714T160000Z.json",
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("archived keys = %v, want %v", keys, want)
+	}
+
+	page, err := objectstore.GetBytes(ctx, store, want[0])
+	if err != nil || !strings.Contains(string(page), `<base href="https://in.bookmyshow.com/explore/movies-new-delhi">`) {
+		t.Fatalf("archived page = %s, %v, want the cleaned up page", page, err)
+	}
+}
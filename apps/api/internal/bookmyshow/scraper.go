@@ -2,54 +2,210 @@ package bookmyshow
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	neturl "net/url"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"go-scraping/internal/browser"
 	"go-scraping/internal/movies"
+	"go-scraping/internal/objectstore"
 
 	"github.com/chromedp/chromedp"
 )
 
 const userAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
 
+// Options controls where listings are scraped from. URLTemplate and
+// LinkSelector take the city as their only %s verb; empty fields fall back to
+// the BookMyShow defaults. The city is path-escaped in the URL and escaped for
+// a quoted CSS string in the selector.
+type Options struct {
+	Timeout      time.Duration
+	URLTemplate  string
+	LinkSelector string
+
+	// SettleDelay is how long to wait after the page loads for the listings
+	// to render.
+	SettleDelay time.Duration
+
+	// RecordDir, when set, receives a recording of every scrape: the
+	// rendered page and the links found on it. See ReplayScraper.
+	RecordDir string
+
+	// Archive, when set, keeps every scrape's recording and rendered page,
+	// under scrapes/<city>/. Like recording, a scrape fails when it cannot
+	// be archived, so the archive has no silent gaps.
+	Archive objectstore.Store
+
+	// Mirrors are alternate URL templates, such as regional subdomains,
+	// tried in order when scraping URLTemplate fails.
+	Mirrors []string
+
+	// CityRegions scrapes the listed cities from another market's site.
+	// Other cities use URLTemplate and LinkSelector.
+	CityRegions map[string]Region
+}
+
+// Region holds the templates of a market other than BookMyShow India, such as
+// another country's domain. Empty fields fall back to Options'.
+type Region struct {
+	URLTemplate  string
+	LinkSelector string
+	Mirrors      []string
+}
+
+// templates returns the URL templates to scrape the city from, primary first,
+// and its link selector template.
+func (opts Options) templates(city string) ([]string, string) {
+	urlTemplate, mirrors, linkSelector := opts.URLTemplate, opts.Mirrors, opts.LinkSelector
+
+	if region, ok := opts.CityRegions[city]; ok {
+		if region.URLTemplate != "" {
+			urlTemplate, mirrors = region.URLTemplate, region.Mirrors
+		}
+		if region.LinkSelector != "" {
+			linkSelector = region.LinkSelector
+		}
+	}
+
+	return append([]string{urlTemplate}, mirrors...), linkSelector
+}
+
 type Scraper struct {
-	timeout time.Duration
+	mu   sync.RWMutex
+	opts Options
+
+	health sourceHealth
 }
 
 var _ movies.Scraper = (*Scraper)(nil)
 
-func NewScraper(timeout time.Duration) *Scraper {
-	return &Scraper{timeout: timeout}
+func NewScraper(opts Options) *Scraper {
+	return &Scraper{opts: opts.withDefaults()}
+}
+
+// SetOptions replaces the options used by scrapes that start afterwards.
+// Scrapes already in progress keep the options they started with.
+func (s *Scraper) SetOptions(opts Options) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.opts = opts.withDefaults()
+}
+
+func (opts Options) withDefaults() Options {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 60 * time.Second
+	}
+
+	if opts.SettleDelay <= 0 {
+		opts.SettleDelay = 5 * time.Second
+	}
+
+	if opts.URLTemplate == "" {
+		opts.URLTemplate = "https://in.bookmyshow.com/explore/movies-%s"
+	}
+
+	if opts.LinkSelector == "" {
+		opts.LinkSelector = `a[href*="/movies/%s/"]`
+	}
+
+	return opts
 }
 
+// Scrape loads the city's listings from the first of its sources that
+// succeeds, trying sources that failed recently last.
 func (s *Scraper) Scrape(ctx context.Context, city string) ([]movies.Movie, error) {
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.UserAgent(userAgent),
-		chromedp.Flag("headless", true),
-		chromedp.Flag("disable-gpu", true),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.Flag("disable-dev-shm-usage", true),
-	)
-
-	allocCtx, cancel := chromedp.NewExecAllocator(ctx, opts...)
-	defer cancel()
+	s.mu.RLock()
+	opts := s.opts
+	s.mu.RUnlock()
 
-	browserCtx, cancel := chromedp.NewContext(allocCtx)
-	defer cancel()
+	urlTemplates, linkSelector := opts.templates(city)
+
+	var errs []error
+	for _, urlTemplate := range s.health.order(urlTemplates) {
+		list, err := s.scrapeSource(ctx, opts, city, urlTemplate, linkSelector)
+		if err == nil {
+			s.health.succeeded(urlTemplate)
+			return list, nil
+		}
+
+		// A cancelled scrape says nothing about the source.
+		if ctx.Err() != nil {
+			return nil, err
+		}
+
+		s.health.failed(urlTemplate, err)
+		errs = append(errs, err)
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// Sources reports the health of every configured source, in the order they
+// are configured.
+func (s *Scraper) Sources() []SourceStatus {
+	s.mu.RLock()
+	opts := s.opts
+	s.mu.RUnlock()
+
+	var urlTemplates []string
+	add := func(templates ...string) {
+		for _, urlTemplate := range templates {
+			if urlTemplate != "" && !slices.Contains(urlTemplates, urlTemplate) {
+				urlTemplates = append(urlTemplates, urlTemplate)
+			}
+		}
+	}
+
+	add(opts.URLTemplate)
+	add(opts.Mirrors...)
 
-	browserCtx, cancel = context.WithTimeout(browserCtx, s.timeout)
+	regions := slices.Sorted(maps.Keys(opts.CityRegions))
+	for _, city := range regions {
+		add(opts.CityRegions[city].URLTemplate)
+		add(opts.CityRegions[city].Mirrors...)
+	}
+
+	return s.health.statuses(urlTemplates)
+}
+
+func (s *Scraper) scrapeSource(ctx context.Context, opts Options, city, urlTemplate, linkSelector string) ([]movies.Movie, error) {
+	// The timeout covers the browser itself, so a Chrome that is still
+	// running when it expires is killed.
+	scrapeCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
 	defer cancel()
 
-	url := fmt.Sprintf("https://in.bookmyshow.com/explore/movies-%s", city)
-	selector := fmt.Sprintf("a[href*=\"/movies/%s/\"]", city)
+	browserCtx, closeBrowser, err := browser.New(scrapeCtx, chromedp.UserAgent(userAgent))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBrowser()
+
+	url := fmt.Sprintf(urlTemplate, neturl.PathEscape(city))
+	selector := fmt.Sprintf(linkSelector, escapeCSSString(city))
 
-	var links []map[string]string
-	err := chromedp.Run(browserCtx,
+	// The selector is passed as a JSON string literal so that no city can
+	// end the string and change the script.
+	selectorJSON, err := json.Marshal(selector)
+	if err != nil {
+		return nil, fmt.Errorf("encode link selector: %w", err)
+	}
+
+	recording := Recording{City: city, URL: url}
+	actions := []chromedp.Action{
 		chromedp.Navigate(url),
 		chromedp.WaitVisible("body", chromedp.ByQuery),
-		chromedp.Sleep(5*time.Second),
+		chromedp.Sleep(opts.SettleDelay),
+		chromedp.Title(&recording.Title),
 		chromedp.Evaluate(fmt.Sprintf(`
-			Array.from(document.querySelectorAll('%s')).map(link => {
+			Array.from(document.querySelectorAll(%s)).map(link => {
 				const h3Element = link.querySelector('h3');
 
 				let title = '';
@@ -61,27 +217,97 @@ func (s *Scraper) Scrape(ctx context.Context, city string) ([]movies.Movie, erro
 
 				return {
 					text: title,
-					href: link.href
+					href: link.href,
+					details: link.innerText || ''
 				};
 			});
-		`, selector), &links),
-	)
-	if err != nil {
+		`, selectorJSON), &recording.Links),
+	}
+
+	var page string
+	if opts.RecordDir != "" || opts.Archive != nil {
+		actions = append(actions, chromedp.OuterHTML("html", &page, chromedp.ByQuery))
+	}
+
+	if err := chromedp.Run(browserCtx, actions...); err != nil {
+		// A killed Chrome can surface as a lost connection rather than the
+		// timeout that killed it.
+		if scrapeCtx.Err() != nil {
+			return nil, scrapeCtx.Err()
+		}
+
 		return nil, err
 	}
 
-	result := make([]movies.Movie, 0, len(links))
-	for _, link := range links {
-		href := link["href"]
-		if href == "" {
+	recording.RecordedAt = time.Now()
+
+	if opts.RecordDir != "" {
+		if err := recording.save(opts.RecordDir, page); err != nil {
+			return nil, fmt.Errorf("record scrape: %w", err)
+		}
+	}
+
+	if opts.Archive != nil {
+		if err := recording.archive(ctx, opts.Archive, page); err != nil {
+			return nil, fmt.Errorf("archive scrape: %w", err)
+		}
+	}
+
+	return parseRecording(recording)
+}
+
+// parseRecording turns the links found on a listings page into movies. Years
+// are judged against the time of the recording, so replays are
+// deterministic.
+func parseRecording(recording Recording) ([]movies.Movie, error) {
+	if len(recording.Links) == 0 && blockedPage(recording.Title) {
+		return nil, fmt.Errorf("%w: page title %q", movies.ErrScrapeBlocked, recording.Title)
+	}
+
+	result := make([]movies.Movie, 0, len(recording.Links))
+	for _, link := range recording.Links {
+		if link.Href == "" {
 			continue
 		}
 
+		title := movies.NormalizeQuery(link.Text)
+
 		result = append(result, movies.Movie{
-			Title: movies.NormalizeQuery(link["text"]),
-			Href:  href,
+			Title: title,
+			Href:  link.Href,
+			Year:  releaseYear(title, movies.NormalizeQuery(link.Details), recording.RecordedAt),
+			Buzz:  buzz(link.Details),
 		})
 	}
 
 	return result, nil
 }
+
+// blockedTitles are the page titles of bot checks and access-denied pages
+// served in place of the listings.
+var blockedTitles = []string{"access denied", "attention required", "just a moment", "forbidden"}
+
+func blockedPage(title string) bool {
+	title = strings.ToLower(title)
+
+	return slices.ContainsFunc(blockedTitles, func(blocked string) bool {
+		return strings.Contains(title, blocked)
+	})
+}
+
+// escapeCSSString escapes value for use inside a quoted CSS string, such as
+// the attribute value in the default link selector. Characters other than
+// ASCII letters, digits, hyphens and underscores become hex escapes.
+func escapeCSSString(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			fmt.Fprintf(&b, "\\%x ", r)
+		}
+	}
+
+	return b.String()
+}
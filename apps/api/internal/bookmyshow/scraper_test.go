@@ -0,0 +1,70 @@
+package bookmyshow
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestEscapeCSSString(t *testing.T) {
+	t.Parallel()
+
+	for value, want := range map[string]string{
+		"navi-mumbai":  "navi-mumbai",
+		`puri"]`:       `puri\22 \5d `,
+		"a'b\\c":       `a\27 b\5c c`,
+		"bh\u00fcbane": `bh\fc bane`,
+	} {
+		if got := escapeCSSString(value); got != want {
+			t.Fatalf("escapeCSSString(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestBlockedPage(t *testing.T) {
+	t.Parallel()
+
+	for title, want := range map[string]bool{
+		"Access Denied":                    true,
+		"Just a moment...":                 true,
+		"Attention Required! | Cloudflare": true,
+		"Movies in Cuttack | BookMyShow":   false,
+		"":                                 false,
+	} {
+		if got := blockedPage(title); got != want {
+			t.Fatalf("blockedPage(%q) = %v, want %v", title, got, want)
+		}
+	}
+}
+
+func TestOptionsTemplatesUseCityRegion(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{
+		Mirrors: []string{"https://mirror.example.com/movies-%s"},
+		CityRegions: map[string]Region{
+			"colombo": {URLTemplate: "https://lk.bookmyshow.com/explore/movies-%s"},
+		},
+	}.withDefaults()
+
+	urlTemplates, linkSelector := opts.templates("colombo")
+	if !slices.Equal(urlTemplates, []string{"https://lk.bookmyshow.com/explore/movies-%s"}) || linkSelector != opts.LinkSelector {
+		t.Fatalf("templates(colombo) = %q, %q, want the region's URL and the default selector", urlTemplates, linkSelector)
+	}
+
+	if urlTemplates, _ := opts.templates("cuttack"); !slices.Equal(urlTemplates, []string{opts.URLTemplate, "https://mirror.example.com/movies-%s"}) {
+		t.Fatalf("templates(cuttack) URLs = %q, want the default and its mirror", urlTemplates)
+	}
+}
+
+func TestOptionsWithDefaultsWaitsForListingsToSettle(t *testing.T) {
+	t.Parallel()
+
+	if got := (Options{}).withDefaults().SettleDelay; got != 5*time.Second {
+		t.Fatalf("default SettleDelay = %s, want 5s", got)
+	}
+
+	if got := (Options{SettleDelay: time.Second}).withDefaults().SettleDelay; got != time.Second {
+		t.Fatalf("SettleDelay = %s, want the configured 1s", got)
+	}
+}
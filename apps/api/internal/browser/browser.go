@@ -0,0 +1,131 @@
+// Package browser runs headless Chrome for scraping and rendering, making sure
+// no Chrome process outlives the work it was started for.
+package browser
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// closeTimeout is how long Chrome gets to close before it is killed.
+const closeTimeout = 5 * time.Second
+
+// New starts a headless Chrome, with opts added to the default allocator
+// options, and returns a context for running actions in it. Calling the
+// returned function closes Chrome and kills any helpers it left behind. When
+// ctx is done first, Chrome and its helpers are killed right away rather
+// than waited for.
+func New(ctx context.Context, opts ...chromedp.ExecAllocatorOption) (context.Context, context.CancelFunc, error) {
+	var browserCmd *exec.Cmd
+
+	allocOpts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("headless", true),
+		chromedp.Flag("disable-gpu", true),
+		chromedp.Flag("no-sandbox", true),
+		chromedp.Flag("disable-dev-shm-usage", true),
+		chromedp.ModifyCmdFunc(func(cmd *exec.Cmd) {
+			configureBrowserCmd(cmd)
+			browserCmd = cmd
+		}),
+	)
+	allocOpts = append(allocOpts, opts...)
+
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(ctx, allocOpts...)
+	browserCtx, cancelBrowser := chromedp.NewContext(allocCtx)
+
+	// Chrome is started now rather than by the first action, so that its
+	// process is tracked from the start. The command is only touched by
+	// this goroutine, which started it.
+	err := chromedp.Run(browserCtx)
+
+	pid := 0
+	if browserCmd != nil && browserCmd.Process != nil {
+		pid = browserCmd.Process.Pid
+		processes.started(pid)
+	}
+
+	stopKill := context.AfterFunc(ctx, func() { killGroup(pid) })
+
+	closeBrowser := func() {
+		stopKill()
+
+		// Closing waits for Chrome to exit, so a Chrome that hangs instead
+		// is killed.
+		timer := time.AfterFunc(closeTimeout, func() { killGroup(pid) })
+		cancelBrowser()
+
+		// Cancelling the allocator waits for Chrome to exit; its helpers are
+		// then killed so a cancelled run never leaves processes behind.
+		cancelAlloc()
+		timer.Stop()
+		killGroup(pid)
+		processes.closed(pid)
+	}
+
+	if err != nil {
+		closeBrowser()
+		return nil, nil, fmt.Errorf("start chrome: %w", err)
+	}
+
+	return browserCtx, closeBrowser, nil
+}
+
+const (
+	maxConcurrentRenders = 2
+	renderTimeout        = 30 * time.Second
+)
+
+// Renderer renders HTML documents to images, each in its own Chrome, running
+// at most maxConcurrentRenders at a time.
+type Renderer struct {
+	slots chan struct{}
+}
+
+func NewRenderer() *Renderer {
+	return &Renderer{slots: make(chan struct{}, maxConcurrentRenders)}
+}
+
+// RenderPNG renders an HTML document in a viewport of the given size and
+// returns a PNG screenshot of it. The document is loaded from a data URL, so
+// it should be self-contained.
+func (r *Renderer) RenderPNG(ctx context.Context, document string, width, height int) ([]byte, error) {
+	select {
+	case r.slots <- struct{}{}:
+		defer func() { <-r.slots }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	ctx, cancelTimeout := context.WithTimeout(ctx, renderTimeout)
+	defer cancelTimeout()
+
+	browserCtx, cancel, err := New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var screenshot []byte
+	err = chromedp.Run(browserCtx,
+		chromedp.EmulateViewport(int64(width), int64(height)),
+		chromedp.Navigate("data:text/html;charset=utf-8;base64,"+base64.StdEncoding.EncodeToString([]byte(document))),
+		chromedp.WaitReady("body", chromedp.ByQuery),
+		chromedp.CaptureScreenshot(&screenshot),
+	)
+	if err != nil {
+		// Chrome is killed when the render times out, which can surface as
+		// a lost connection rather than the timeout.
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		return nil, err
+	}
+
+	return screenshot, nil
+}
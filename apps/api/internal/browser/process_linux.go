@@ -0,0 +1,101 @@
+//go:build linux
+
+package browser
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// configureBrowserCmd kills Chrome if the API process dies and starts it in
+// its own process group, so its renderer and GPU helpers can be killed along
+// with it.
+func configureBrowserCmd(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = new(syscall.SysProcAttr)
+	}
+
+	cmd.SysProcAttr.Pdeathsig = syscall.SIGKILL
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// killGroup kills every process in the group of the Chrome started as pid.
+func killGroup(pid int) {
+	if pid <= 0 {
+		return
+	}
+
+	_ = syscall.Kill(-pid, syscall.SIGKILL)
+}
+
+func killProcess(pid int) {
+	_ = syscall.Kill(pid, syscall.SIGKILL)
+}
+
+// reapZombie collects the exit status of a child that has exited, without
+// waiting for one that has not.
+func reapZombie(pid int) bool {
+	reaped, err := syscall.Wait4(pid, nil, syscall.WNOHANG, nil)
+	return err == nil && reaped == pid
+}
+
+// listProcesses reads every process visible in /proc. Processes that exit
+// while they are read are skipped.
+func listProcesses() ([]process, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []process
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+
+		stat, err := os.ReadFile("/proc/" + entry.Name() + "/stat")
+		if err != nil {
+			continue
+		}
+
+		p, err := parseStat(stat)
+		if err != nil {
+			continue
+		}
+
+		// Zombies have no command line left.
+		if cmdline, err := os.ReadFile("/proc/" + entry.Name() + "/cmdline"); err == nil {
+			p.cmdline = string(bytes.ReplaceAll(bytes.TrimRight(cmdline, "\x00"), []byte{0}, []byte{' '}))
+		}
+
+		processes = append(processes, p)
+	}
+
+	return processes, nil
+}
+
+// parseStat parses the fields of /proc/<pid>/stat that the reaper needs. The
+// command name is in parentheses and may itself contain spaces and
+// parentheses, so the fields after it are found from the last ')'.
+func parseStat(stat []byte) (process, error) {
+	open, end := bytes.IndexByte(stat, '('), bytes.LastIndexByte(stat, ')')
+	if open < 0 || end < open {
+		return process{}, fmt.Errorf("malformed stat %q", stat)
+	}
+
+	var p process
+	if _, err := fmt.Sscan(string(stat[:open]), &p.pid); err != nil {
+		return process{}, fmt.Errorf("malformed stat %q: %w", stat, err)
+	}
+	p.comm = string(stat[open+1 : end])
+
+	if _, err := fmt.Sscanf(string(stat[end+1:]), " %c %d %d", &p.state, &p.ppid, &p.pgrp); err != nil {
+		return process{}, fmt.Errorf("malformed stat %q: %w", stat, err)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,63 @@
+//go:build linux
+
+package browser
+
+import (
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestParseStat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		stat string
+		want process
+	}{
+		{
+			"4242 (chrome) S 4200 4200 4200 0 -1 4194560 1234 0 0 0",
+			process{pid: 4242, ppid: 4200, pgrp: 4200, state: 'S', comm: "chrome"},
+		},
+		{
+			"77 (Web Content (x)) Z 1 70 70 0 -1",
+			process{pid: 77, ppid: 1, pgrp: 70, state: 'Z', comm: "Web Content (x)"},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := parseStat([]byte(tt.stat))
+		if err != nil {
+			t.Fatalf("parseStat(%q) error = %v", tt.stat, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("parseStat(%q) = %+v, want %+v", tt.stat, got, tt.want)
+		}
+	}
+
+	if _, err := parseStat([]byte("4242 chrome S 1 1")); err == nil {
+		t.Error("parseStat() without a command name error = nil, want error")
+	}
+}
+
+func TestListProcessesIncludesSelf(t *testing.T) {
+	t.Parallel()
+
+	list, err := listProcesses()
+	if err != nil {
+		t.Fatalf("listProcesses() error = %v", err)
+	}
+
+	self := os.Getpid()
+	for _, p := range list {
+		if p.pid == self {
+			if p.ppid != os.Getppid() || p.cmdline == "" {
+				t.Fatalf("listProcesses() self = %+v, want parent %d and a command line", p, os.Getppid())
+			}
+			return
+		}
+	}
+
+	t.Fatalf("listProcesses() has no process %s", strconv.Itoa(self))
+}
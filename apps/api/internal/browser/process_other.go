@@ -0,0 +1,36 @@
+//go:build !linux
+
+package browser
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+func configureBrowserCmd(*exec.Cmd) {}
+
+// killGroup kills the Chrome started as pid. Without process groups, its
+// helpers are left to exit on their own.
+func killGroup(pid int) {
+	if pid <= 0 {
+		return
+	}
+
+	killProcess(pid)
+}
+
+func killProcess(pid int) {
+	if process, err := os.FindProcess(pid); err == nil {
+		_ = process.Kill()
+	}
+}
+
+func reapZombie(int) bool {
+	return false
+}
+
+// listProcesses is only supported on Linux, so elsewhere nothing is reaped.
+func listProcesses() ([]process, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,210 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reapInterval is how often the reaper looks for Chrome processes left
+// behind.
+const reapInterval = time.Minute
+
+// process is a process as read from the operating system.
+type process struct {
+	pid, ppid, pgrp int
+	state           rune
+	comm            string
+	cmdline         string
+}
+
+func (p process) zombie() bool {
+	return p.state == 'Z'
+}
+
+// chrome reports whether the process looks like Chrome or one of its
+// helpers. Zombies keep only their command name, truncated by the kernel.
+func (p process) chrome() bool {
+	comm := strings.ToLower(p.comm)
+
+	return strings.Contains(comm, "chrom") || strings.Contains(comm, "headless")
+}
+
+// headless reports whether the process is a headless Chrome or one of the
+// helpers, such as the zygote, that Chrome starts.
+func (p process) headless() bool {
+	return p.chrome() && (strings.Contains(p.cmdline, "--headless") || strings.Contains(p.cmdline, "--type="))
+}
+
+// tracker records the process groups of the Chrome instances New starts. Each
+// Chrome leads its own group, so the group ID is the browser's PID.
+type tracker struct {
+	mu      sync.Mutex
+	running map[int]bool
+
+	// closing holds the groups of closed browsers until the reaper finds
+	// none of their processes left.
+	closing map[int]bool
+}
+
+var processes = &tracker{running: make(map[int]bool), closing: make(map[int]bool)}
+
+func (t *tracker) started(pid int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// The PID of a closed browser may be reused by a new one.
+	delete(t.closing, pid)
+	t.running[pid] = true
+}
+
+func (t *tracker) closed(pid int) {
+	if pid <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.running, pid)
+	t.closing[pid] = true
+}
+
+// snapshot returns copies of the running and closing groups.
+func (t *tracker) snapshot() (running, closing map[int]bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	running = make(map[int]bool, len(t.running))
+	for pid := range t.running {
+		running[pid] = true
+	}
+
+	closing = make(map[int]bool, len(t.closing))
+	for pid := range t.closing {
+		closing[pid] = true
+	}
+
+	return running, closing
+}
+
+// forget stops watching a closed browser's group.
+func (t *tracker) forget(pid int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.closing, pid)
+}
+
+// Reaper kills the Chrome processes that outlive their browser and collects
+// the ones left as zombies, which a long-running server otherwise gathers
+// until it runs out of memory or process IDs. Helpers of a killed Chrome are
+// reparented to init, which is the API itself when it runs as a container's
+// first process.
+type Reaper struct {
+	logger *slog.Logger
+}
+
+func NewReaper(logger *slog.Logger) *Reaper {
+	return &Reaper{logger: logger}
+}
+
+// Run reaps every reapInterval until ctx is done.
+func (r *Reaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(reapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Reap(ctx)
+		}
+	}
+}
+
+// Reap kills what is left of closed browsers and any orphaned headless
+// Chrome, and collects the Chrome zombies that are this process's children.
+// Processes of browsers still open are left alone.
+func (r *Reaper) Reap(ctx context.Context) {
+	list, err := listProcesses()
+	if err != nil {
+		if !errors.Is(err, errors.ErrUnsupported) {
+			r.logger.WarnContext(ctx, "failed to list processes", "error", err)
+		}
+		return
+	}
+
+	running, closing := processes.snapshot()
+	sweep := planSweep(list, os.Getpid(), running, closing)
+
+	for _, pid := range sweep.kill {
+		killProcess(pid)
+	}
+
+	reaped := 0
+	for _, pid := range sweep.collect {
+		if reapZombie(pid) {
+			reaped++
+		}
+	}
+
+	for _, pgid := range sweep.gone {
+		processes.forget(pgid)
+	}
+
+	if len(sweep.kill) > 0 || reaped > 0 {
+		r.logger.InfoContext(ctx, "reaped leftover chrome processes", "killed", len(sweep.kill), "reaped", reaped)
+	}
+}
+
+// sweep is what a pass of the reaper does: the processes to kill, the zombies
+// to collect, and the groups of closed browsers that are gone.
+type sweep struct {
+	kill, collect, gone []int
+}
+
+// planSweep decides what to do with the processes listed, as seen by the
+// process self, given the groups of running and closed browsers.
+func planSweep(list []process, self int, running, closing map[int]bool) sweep {
+	var s sweep
+	left := make(map[int]bool)
+
+	for _, p := range list {
+		switch {
+		case p.zombie():
+			// Each browser leads its group and is collected by its
+			// command; zombies of other parents are theirs to collect.
+			if p.ppid == self && p.pid != p.pgrp && p.chrome() {
+				s.collect = append(s.collect, p.pid)
+			}
+		case running[p.pgrp]:
+		case closing[p.pgrp], orphaned(p):
+			s.kill = append(s.kill, p.pid)
+			left[p.pgrp] = true
+		}
+	}
+
+	// A group is watched until a pass finds nothing of it to kill.
+	for pgid := range closing {
+		if !left[pgid] {
+			s.gone = append(s.gone, pgid)
+		}
+	}
+	slices.Sort(s.gone)
+
+	return s
+}
+
+// orphaned reports whether the process is a helper of a headless Chrome that
+// exited, leaving it to init. Browsers themselves lead their groups and are
+// killed with the API when it dies, so they are never orphaned.
+func orphaned(p process) bool {
+	return p.ppid == 1 && p.pid != p.pgrp && p.headless()
+}
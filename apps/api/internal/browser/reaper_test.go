@@ -0,0 +1,57 @@
+package browser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanSweep(t *testing.T) {
+	t.Parallel()
+
+	const self = 100
+
+	list := []process{
+		// A running browser and its zygote are left alone.
+		{pid: 200, ppid: self, pgrp: 200, state: 'S', comm: "chrome", cmdline: "chrome --headless"},
+		{pid: 201, ppid: 200, pgrp: 200, state: 'S', comm: "chrome", cmdline: "chrome --type=zygote"},
+
+		// A renderer of a closed browser is killed.
+		{pid: 301, ppid: 1, pgrp: 300, state: 'S', comm: "chrome", cmdline: "chrome --type=renderer"},
+
+		// A zygote orphaned by an earlier process is killed, but a
+		// desktop Chrome and other programs are not.
+		{pid: 401, ppid: 1, pgrp: 400, state: 'S', comm: "chrome", cmdline: "chrome --type=zygote --headless"},
+		{pid: 501, ppid: 1, pgrp: 501, state: 'S', comm: "chrome", cmdline: "chrome --restore-last-session"},
+		{pid: 502, ppid: 1, pgrp: 502, state: 'S', comm: "postgres", cmdline: "postgres --type=main"},
+
+		// Chrome zombies left to this process are collected, but not a
+		// browser's own process or another parent's zombies.
+		{pid: 601, ppid: self, pgrp: 300, state: 'Z', comm: "chrome"},
+		{pid: 602, ppid: self, pgrp: 602, state: 'Z', comm: "chrome"},
+		{pid: 603, ppid: 1, pgrp: 300, state: 'Z', comm: "chrome"},
+		{pid: 604, ppid: self, pgrp: self, state: 'Z', comm: "pg_dump"},
+	}
+
+	running := map[int]bool{200: true}
+	closing := map[int]bool{300: true, 700: true}
+
+	got := planSweep(list, self, running, closing)
+	want := sweep{kill: []int{301, 401}, collect: []int{601}, gone: []int{700}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("planSweep() = %+v, want %+v", got, want)
+	}
+}
+
+func TestTrackerForgetsReusedPIDs(t *testing.T) {
+	t.Parallel()
+
+	tracker := &tracker{running: make(map[int]bool), closing: make(map[int]bool)}
+	tracker.started(200)
+	tracker.closed(200)
+	tracker.started(200)
+
+	running, closing := tracker.snapshot()
+	if !running[200] || closing[200] {
+		t.Fatalf("snapshot() = %v, %v, want 200 running again", running, closing)
+	}
+}
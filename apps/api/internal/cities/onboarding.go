@@ -0,0 +1,117 @@
+package cities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"go-scraping/internal/movies"
+)
+
+// ErrNotListed reports a city that BookMyShow lists no movies for, which is
+// usually a misspelt slug.
+var ErrNotListed = errors.New("city has no movies listed")
+
+// Store keeps the cities onboarded at runtime, so they are registered again
+// after a restart.
+type Store interface {
+	ListCities(ctx context.Context) ([]City, error)
+
+	// AddCity saves a city, failing with ErrNameUsed if one of that name
+	// was already saved.
+	AddCity(ctx context.Context, city City) error
+}
+
+// Loader loads a city's movies, scraping them when none are cached. The movie
+// service is one, so the test scrape is paused, queued, tracked, and checked
+// like any other, and its result is kept as the city's first listings.
+type Loader interface {
+	Load(ctx context.Context, city string) ([]movies.Movie, bool, error)
+}
+
+// Scheduler starts refreshing an onboarded city. It reports whether the
+// city's first scrape was queued, which only the scheduler leader can do.
+type Scheduler interface {
+	ScheduleCity(ctx context.Context, city City) (bool, error)
+}
+
+// Onboarded describes a city that was just onboarded.
+type Onboarded struct {
+	City City
+
+	// Movies counts the movies the test load found.
+	Movies int
+
+	// Queued reports whether the city's first scrape was queued. When it
+	// was not, the scheduler leader scrapes the city once it reloads.
+	Queued bool
+}
+
+// Onboarding adds cities while the server runs, so that covering a new city
+// needs no change to the config file.
+type Onboarding struct {
+	registry  *Registry
+	store     Store
+	loader    Loader
+	scheduler Scheduler
+	logger    *slog.Logger
+
+	// mu serializes onboarding, so a city is never test loaded and saved
+	// twice at once.
+	mu sync.Mutex
+}
+
+func NewOnboarding(registry *Registry, store Store, loader Loader, scheduler Scheduler, logger *slog.Logger) *Onboarding {
+	return &Onboarding{
+		registry:  registry,
+		store:     store,
+		loader:    loader,
+		scheduler: scheduler,
+		logger:    logger,
+	}
+}
+
+// Onboard registers the city and loads its movies once to check that
+// BookMyShow lists movies for it, then saves it and schedules its refreshes.
+// The loaded movies are cached, so the first refresh does not scrape again.
+func (o *Onboarding) Onboard(ctx context.Context, city City) (Onboarded, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	city.Name = normalize(city.Name)
+	if _, ok := o.registry.Resolve(city.Name); ok {
+		return Onboarded{}, fmt.Errorf("city %s: name is %w", city.Name, ErrNameUsed)
+	}
+
+	if err := o.registry.Add(city); err != nil {
+		return Onboarded{}, err
+	}
+
+	listed, _, err := o.loader.Load(ctx, city.Name)
+	if err != nil && !errors.Is(err, movies.ErrScrapeEmpty) {
+		o.registry.remove(city.Name)
+		return Onboarded{}, fmt.Errorf("test load: %w", err)
+	}
+	if len(listed) == 0 {
+		o.registry.remove(city.Name)
+		return Onboarded{}, fmt.Errorf("city %s: %w", city.Name, ErrNotListed)
+	}
+
+	// The registered city has its display name and timezone defaulted.
+	city, _ = o.registry.Resolve(city.Name)
+	if err := o.store.AddCity(ctx, city); err != nil {
+		o.registry.remove(city.Name)
+		return Onboarded{}, fmt.Errorf("save city: %w", err)
+	}
+
+	queued, err := o.scheduler.ScheduleCity(ctx, city)
+	if err != nil {
+		return Onboarded{}, fmt.Errorf("schedule city: %w", err)
+	}
+
+	o.logger.InfoContext(ctx, "onboarded city", "city", city.Name, "movies", len(listed), "queued", queued)
+
+	return Onboarded{City: city, Movies: len(listed), Queued: queued}, nil
+}
@@ -0,0 +1,155 @@
+package cities
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"go-scraping/internal/movies"
+)
+
+type fakeLoader struct {
+	movies []movies.Movie
+	err    error
+	calls  int
+}
+
+func (l *fakeLoader) Load(ctx context.Context, city string) ([]movies.Movie, bool, error) {
+	l.calls++
+	return l.movies, false, l.err
+}
+
+type fakeStore struct {
+	cities []City
+	err    error
+}
+
+func (s *fakeStore) ListCities(ctx context.Context) ([]City, error) {
+	return s.cities, nil
+}
+
+func (s *fakeStore) AddCity(ctx context.Context, city City) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.cities = append(s.cities, city)
+	return nil
+}
+
+type fakeScheduler struct {
+	scheduled []string
+}
+
+func (s *fakeScheduler) ScheduleCity(ctx context.Context, city City) (bool, error) {
+	s.scheduled = append(s.scheduled, city.Name)
+	return true, nil
+}
+
+func newTestOnboarding(t *testing.T, loader *fakeLoader, store *fakeStore) (*Onboarding, *Registry, *fakeScheduler) {
+	t.Helper()
+
+	registry, err := NewRegistry(testCities())
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	scheduler := &fakeScheduler{}
+	onboarding := NewOnboarding(registry, store, loader, scheduler, slog.New(slog.DiscardHandler))
+
+	return onboarding, registry, scheduler
+}
+
+func TestOnboardRegistersSavesAndSchedulesCity(t *testing.T) {
+	t.Parallel()
+
+	loader := &fakeLoader{movies: []movies.Movie{{Title: "Kalki"}, {Title: "Stree 2"}}}
+	store := &fakeStore{}
+	onboarding, registry, scheduler := newTestOnboarding(t, loader, store)
+
+	onboarded, err := onboarding.Onboard(context.Background(), City{Name: " Puri ", Timezone: "Asia/Kolkata"})
+	if err != nil {
+		t.Fatalf("Onboard() error = %v", err)
+	}
+
+	if onboarded.City.Name != "puri" || onboarded.City.DisplayName != "puri" || onboarded.Movies != 2 || !onboarded.Queued {
+		t.Fatalf("Onboard() = %+v, want puri with 2 movies, queued", onboarded)
+	}
+
+	if _, ok := registry.Resolve("puri"); !ok {
+		t.Fatal("Resolve(puri) ok = false, want the city registered")
+	}
+
+	if loader.calls != 1 {
+		t.Fatalf("loads = %d, want 1", loader.calls)
+	}
+
+	if len(store.cities) != 1 || store.cities[0].Name != "puri" {
+		t.Fatalf("saved cities = %+v, want puri", store.cities)
+	}
+
+	if len(scheduler.scheduled) != 1 || scheduler.scheduled[0] != "puri" {
+		t.Fatalf("scheduled = %v, want puri", scheduler.scheduled)
+	}
+}
+
+func TestOnboardRejectsCities(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		city   City
+		loader *fakeLoader
+		want   error
+		loaded bool
+	}{
+		{"registered name", City{Name: "Mumbai"}, &fakeLoader{}, ErrNameUsed, false},
+		{"registered alias", City{Name: "bbsr"}, &fakeLoader{}, ErrNameUsed, false},
+		{"used alias", City{Name: "puri", Aliases: []string{"bombay"}}, &fakeLoader{movies: []movies.Movie{{Title: "Kalki"}}}, ErrNameUsed, false},
+		{"no movies listed", City{Name: "purii"}, &fakeLoader{err: movies.ErrScrapeEmpty}, ErrNotListed, true},
+		{"blocked scrape", City{Name: "puri"}, &fakeLoader{err: movies.ErrScrapeBlocked}, movies.ErrScrapeBlocked, true},
+		{"scraping paused", City{Name: "puri"}, &fakeLoader{err: movies.ErrScrapingPaused}, movies.ErrScrapingPaused, true},
+		{"quarantined scrape", City{Name: "puri"}, &fakeLoader{err: movies.ErrScrapeQuarantined}, movies.ErrScrapeQuarantined, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := &fakeStore{}
+			onboarding, registry, scheduler := newTestOnboarding(t, tt.loader, store)
+
+			if _, err := onboarding.Onboard(context.Background(), tt.city); !errors.Is(err, tt.want) {
+				t.Fatalf("Onboard() error = %v, want %v", err, tt.want)
+			}
+
+			if loaded := tt.loader.calls > 0; loaded != tt.loaded {
+				t.Errorf("loaded = %v, want %v", loaded, tt.loaded)
+			}
+
+			if len(registry.Added()) != 0 || len(store.cities) != 0 || len(scheduler.scheduled) != 0 {
+				t.Fatalf("added %v, saved %v, scheduled %v, want nothing", registry.Added(), store.cities, scheduler.scheduled)
+			}
+		})
+	}
+}
+
+func TestOnboardUnregistersCityWhenSaveFails(t *testing.T) {
+	t.Parallel()
+
+	loader := &fakeLoader{movies: []movies.Movie{{Title: "Kalki"}}}
+	onboarding, registry, scheduler := newTestOnboarding(t, loader, &fakeStore{err: errors.New("connection refused")})
+
+	if _, err := onboarding.Onboard(context.Background(), City{Name: "puri"}); err == nil {
+		t.Fatal("Onboard() error = nil, want save error")
+	}
+
+	if _, ok := registry.Resolve("puri"); ok {
+		t.Fatal("Resolve(puri) ok = true, want the city unregistered again")
+	}
+
+	if len(scheduler.scheduled) != 0 {
+		t.Fatalf("scheduled = %v, want nothing", scheduler.scheduled)
+	}
+}
@@ -0,0 +1,218 @@
+// Package cities resolves the city names clients send, including aliases such
+// as "bbsr", to the BookMyShow city slugs that movies are scraped and cached
+// under.
+package cities
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	// Timezones are validated against the embedded database so hosts and
+	// containers without zoneinfo files behave the same.
+	_ "time/tzdata"
+)
+
+// ErrNameUsed reports a city name or alias that another city already uses.
+var ErrNameUsed = errors.New("already used")
+
+type City struct {
+	// Name is the BookMyShow city slug, such as "bhubaneswar".
+	Name        string   `json:"name"`
+	DisplayName string   `json:"display_name"`
+	Timezone    string   `json:"timezone"`
+	Aliases     []string `json:"aliases,omitempty"`
+}
+
+// locations caches loaded timezones by name, since loading one reads and
+// parses its zoneinfo.
+var locations sync.Map
+
+// Location returns the city's timezone, or UTC for a city without a valid
+// one. Times shown to clients are in the city's timezone, so that a date
+// means the same day to everyone looking at the city.
+func (c City) Location() *time.Location {
+	if c.Timezone == "" {
+		return time.UTC
+	}
+
+	if location, ok := locations.Load(c.Timezone); ok {
+		return location.(*time.Location)
+	}
+
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	locations.Store(c.Timezone, location)
+
+	return location
+}
+
+// Registry maps city names and aliases to cities. It holds the cities from the
+// config file, which can be replaced while the server is running, and the
+// cities onboarded at runtime, which are kept across replacements.
+type Registry struct {
+	mu         sync.RWMutex
+	configured []City
+	added      []City
+	cities     []City
+	onboarded  []City
+	lookup     map[string]City
+}
+
+func NewRegistry(cities []City) (*Registry, error) {
+	registry := &Registry{}
+	if err := registry.Set(cities); err != nil {
+		return nil, err
+	}
+
+	return registry, nil
+}
+
+// Set replaces the cities from the config file. The registry is left unchanged
+// if any city is invalid or a name or alias is used twice. An onboarded city
+// that the config file now names is replaced by the configured one.
+func (r *Registry) Set(cities []City) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.build(cities, r.added)
+}
+
+// Add registers cities onboarded at runtime, skipping any that were added
+// already. The registry is left unchanged if any city is invalid or uses a
+// name or alias that is already used.
+func (r *Registry) Add(cities ...City) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	added := slices.Clone(r.added)
+	for _, city := range cities {
+		if !slices.ContainsFunc(added, func(a City) bool { return normalize(a.Name) == normalize(city.Name) }) {
+			added = append(added, city)
+		}
+	}
+
+	return r.build(r.configured, added)
+}
+
+// Added returns the onboarded cities that the config file does not name,
+// sorted by name.
+func (r *Registry) Added() []City {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.onboarded)
+}
+
+// remove drops an onboarded city, undoing Add.
+func (r *Registry) remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	added := slices.DeleteFunc(slices.Clone(r.added), func(city City) bool {
+		return normalize(city.Name) == name
+	})
+
+	// Dropping a city cannot make the rest invalid.
+	_ = r.build(r.configured, added)
+}
+
+// build registers the configured and added cities in place of the current
+// ones. The caller must hold r.mu.
+func (r *Registry) build(configured, added []City) error {
+	names := make(map[string]bool, len(configured))
+	for _, city := range configured {
+		names[normalize(city.Name)] = true
+	}
+
+	all := slices.Clone(configured)
+	for _, city := range added {
+		if !names[normalize(city.Name)] {
+			all = append(all, city)
+		}
+	}
+
+	lookup := make(map[string]City, len(all))
+	registered := make([]City, 0, len(all))
+	var onboarded []City
+
+	for i, city := range all {
+		city.Name = normalize(city.Name)
+		if city.Name == "" {
+			return fmt.Errorf("city name must not be empty")
+		}
+
+		if city.DisplayName == "" {
+			city.DisplayName = city.Name
+		}
+
+		if city.Timezone == "" {
+			city.Timezone = "UTC"
+		}
+
+		if _, err := time.LoadLocation(city.Timezone); err != nil {
+			return fmt.Errorf("city %s: unknown timezone %q", city.Name, city.Timezone)
+		}
+
+		for _, key := range append([]string{city.Name}, city.Aliases...) {
+			key = normalize(key)
+			if existing, ok := lookup[key]; ok {
+				return fmt.Errorf("city %s: name or alias %q is %w by %s", city.Name, key, ErrNameUsed, existing.Name)
+			}
+
+			lookup[key] = city
+		}
+
+		registered = append(registered, city)
+		if i >= len(configured) {
+			onboarded = append(onboarded, city)
+		}
+	}
+
+	byName := func(a, b City) int {
+		return strings.Compare(a.Name, b.Name)
+	}
+	slices.SortFunc(registered, byName)
+	slices.SortFunc(onboarded, byName)
+
+	r.configured = configured
+	r.added = added
+	r.cities = registered
+	r.onboarded = onboarded
+	r.lookup = lookup
+
+	return nil
+}
+
+// Resolve returns the city registered under name or one of its aliases,
+// ignoring case. Unregistered names resolve to a city of that name so they
+// can still be scraped; ok reports whether the name was registered.
+func (r *Registry) Resolve(name string) (city City, ok bool) {
+	name = normalize(name)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if city, ok := r.lookup[name]; ok {
+		return city, true
+	}
+
+	return City{Name: name, DisplayName: name}, false
+}
+
+// List returns the registered cities sorted by name.
+func (r *Registry) List() []City {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.cities)
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,123 @@
+package cities
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testCities() []City {
+	return []City{
+		{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Timezone: "Asia/Kolkata", Aliases: []string{"bbsr"}},
+		{Name: "mumbai", DisplayName: "Mumbai", Timezone: "Asia/Kolkata", Aliases: []string{"bombay"}},
+	}
+}
+
+func TestRegistryResolvesNamesAndAliases(t *testing.T) {
+	t.Parallel()
+
+	registry, err := NewRegistry(testCities())
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	for input, want := range map[string]string{
+		"bhubaneswar": "bhubaneswar",
+		" BBSR ":      "bhubaneswar",
+		"Bombay":      "mumbai",
+	} {
+		city, ok := registry.Resolve(input)
+		if !ok || city.Name != want {
+			t.Fatalf("Resolve(%q) = %+v, %v, want %s", input, city, ok, want)
+		}
+	}
+
+	city, ok := registry.Resolve("Puri")
+	if ok || city.Name != "puri" {
+		t.Fatalf("Resolve(%q) = %+v, %v, want unregistered puri", "Puri", city, ok)
+	}
+}
+
+func TestRegistryRejectsInvalidCities(t *testing.T) {
+	t.Parallel()
+
+	duplicate := append(testCities(), City{Name: "bombay", Timezone: "Asia/Kolkata"})
+	if _, err := NewRegistry(duplicate); err == nil || !strings.Contains(err.Error(), `"bombay"`) {
+		t.Fatalf("NewRegistry() error = %v, want duplicate alias error", err)
+	}
+
+	registry, err := NewRegistry(testCities())
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	if err := registry.Set([]City{{Name: "puri", Timezone: "Asia/Bhubaneswar"}}); err == nil {
+		t.Fatal("Set() error = nil, want unknown timezone error")
+	}
+
+	if got := len(registry.List()); got != 2 {
+		t.Fatalf("len(List()) = %d, want registry unchanged with 2 cities", got)
+	}
+}
+
+func TestRegistryKeepsAddedCitiesAcrossSet(t *testing.T) {
+	t.Parallel()
+
+	registry, err := NewRegistry(testCities())
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	if err := registry.Add(City{Name: "Puri", Timezone: "Asia/Kolkata", Aliases: []string{"jagannath-puri"}}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	// Cities saved by other replicas are added again on reload.
+	if err := registry.Add(City{Name: "puri"}, City{Name: "cuttack"}); err != nil {
+		t.Fatalf("Add() with an added city error = %v", err)
+	}
+
+	if err := registry.Add(City{Name: "angul", Aliases: []string{"bbsr"}}); !errors.Is(err, ErrNameUsed) {
+		t.Fatalf("Add() with a used alias error = %v, want ErrNameUsed", err)
+	}
+
+	if err := registry.Set(testCities()[:1]); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	if city, ok := registry.Resolve("jagannath-puri"); !ok || city.Name != "puri" {
+		t.Fatalf("Resolve(jagannath-puri) = %+v, %v, want puri kept after Set", city, ok)
+	}
+
+	if added := registry.Added(); len(added) != 2 || added[0].Name != "cuttack" || added[1].Name != "puri" {
+		t.Fatalf("Added() = %+v, want cuttack and puri", added)
+	}
+
+	// Once the config file names an onboarded city, its entry wins.
+	if err := registry.Set(append(testCities(), City{Name: "puri", DisplayName: "Puri"})); err != nil {
+		t.Fatalf("Set() naming the added city error = %v", err)
+	}
+
+	if city, _ := registry.Resolve("puri"); city.DisplayName != "Puri" || city.Timezone != "UTC" {
+		t.Fatalf("Resolve(puri) = %+v, want the configured city", city)
+	}
+
+	if added := registry.Added(); len(added) != 1 || added[0].Name != "cuttack" {
+		t.Fatalf("Added() = %+v, want only cuttack once puri is configured", added)
+	}
+}
+
+func TestCityLocationFallsBackToUTC(t *testing.T) {
+	t.Parallel()
+
+	for timezone, want := range map[string]string{
+		"Asia/Kolkata":     "Asia/Kolkata",
+		"":                 "UTC",
+		"Asia/Bhubaneswar": "UTC",
+	} {
+		city := City{Name: "puri", Timezone: timezone}
+		if got := city.Location().String(); got != want {
+			t.Errorf("Location() with timezone %q = %s, want %s", timezone, got, want)
+		}
+	}
+}
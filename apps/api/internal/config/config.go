@@ -1,45 +1,1000 @@
+// Package config loads the API's settings from built-in defaults, an optional
+// YAML file, environment variables, and command-line flags, in increasing
+// order of precedence, and validates the result before the server starts.
 package config
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
 	"os"
+	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	DBHost        string
-	DBPort        string
-	DBUser        string
-	DBPassword    string
-	ServerAddr    string
-	CacheTTL      time.Duration
-	ScrapeTimeout time.Duration
-	DefaultCity   string
-	PreloadCities []string
-}
-
-func Load() Config {
+	DBHost     string `yaml:"db_host"`
+	DBPort     string `yaml:"db_port"`
+	DBUser     string `yaml:"db_user"`
+	DBPassword string `yaml:"db_password"`
+
+	ServerAddr     string        `yaml:"server_addr"`
+	CacheTTL       time.Duration `yaml:"cache_ttl"`
+	StalePolicy    string        `yaml:"stale_policy"`
+	DefaultCity    string        `yaml:"default_city"`
+	PreloadCities  []string      `yaml:"preload_cities"`
+	Cities         []CityConfig  `yaml:"cities"`
+	SearchMinScore int           `yaml:"search_min_score"`
+	SearchBackend  string        `yaml:"search_backend"`
+
+	// CORSOrigins are the origins allowed to call the API from a browser;
+	// "*" allows any origin.
+	CORSOrigins []string `yaml:"cors_origins"`
+
+	// AdminToken must be presented to reach /admin and everything under it.
+	// Admin routes are refused without one.
+	AdminToken string `yaml:"admin_token"`
+
+	// RefreshInterval is how often the preload cities are checked for stale
+	// caches, and DataRetention how long scraped movies and search events are
+	// kept before the cleanup job deletes them.
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval"`
+	DataRetention   time.Duration `yaml:"data_retention"`
+
+	// RefreshJitter spreads each city's refresh by up to this long, and
+	// MaxConcurrentScrapes caps how many Chrome instances scrape at once.
+	// Scrapes queued behind that cap fail after ScrapeQueueTimeout.
+	RefreshJitter        time.Duration `yaml:"refresh_jitter"`
+	MaxConcurrentScrapes int           `yaml:"max_concurrent_scrapes"`
+	ScrapeQueueTimeout   time.Duration `yaml:"scrape_queue_timeout"`
+
+	// ScrapingDisabled runs a read-only replica that serves movies already
+	// in the database and never starts Chrome.
+	ScrapingDisabled bool `yaml:"scraping_disabled"`
+
+	// FakeScraper serves canned fixture listings instead of scraping
+	// BookMyShow, for local development without Chrome or network access.
+	FakeScraper bool `yaml:"fake_scraper"`
+
+	// ReplayDir, when set, serves scrapes from the recordings in this
+	// directory instead of loading BookMyShow. See Scraper.RecordDir.
+	ReplayDir string `yaml:"replay_dir"`
+
+	// SyntheticMovies, when positive, serves this many generated movies in
+	// every city instead of scraping BookMyShow. The movies are seeded by
+	// city, so each scrape returns the same ones, for staging and load tests.
+	SyntheticMovies int `yaml:"synthetic_movies"`
+
+	// PreviewImages serves social share images rendered in Chrome.
+	PreviewImages bool `yaml:"preview_images"`
+
+	// WebUI serves the server-rendered listings pages under /web.
+	WebUI bool `yaml:"web_ui"`
+
+	// EmbeddedFrontend serves the frontend embedded in the binary from /, so
+	// a small deployment is a single container. API routes take precedence.
+	EmbeddedFrontend bool `yaml:"embedded_frontend"`
+
+	// PublicURL is the API's externally reachable base URL, used in links
+	// the API hands out, such as QR codes. Links are based on the request's
+	// host when it is empty.
+	PublicURL string `yaml:"public_url"`
+
+	// JobMaxFailures is how many consecutive failures move a background job
+	// to the dead-letter state.
+	JobMaxFailures int `yaml:"job_max_failures"`
+
+	// IdempotencyTTL is how long the response to an admin POST made with an
+	// Idempotency-Key header is replayed to retries.
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl"`
+
+	// LogFormat is "text" or "json"; LogLevel is the minimum level logged.
+	LogFormat string `yaml:"log_format"`
+	LogLevel  string `yaml:"log_level"`
+
+	Scraper   ScraperConfig   `yaml:"scraper"`
+	Ratings   RatingsConfig   `yaml:"ratings"`
+	Streaming StreamingConfig `yaml:"streaming"`
+	Telegram  TelegramConfig  `yaml:"telegram"`
+	Digest    DigestConfig    `yaml:"digest"`
+	Push      PushConfig      `yaml:"push"`
+	Social    SocialConfig    `yaml:"social"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Alerts    AlertConfig     `yaml:"alerts"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Backup    BackupConfig    `yaml:"backup"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+
+	ScrapeChecks ScrapeChecksConfig `yaml:"scrape_checks"`
+
+	// Announcements maps a city name to the channels that are told about its
+	// new movies.
+	Announcements map[string]AnnouncementConfig `yaml:"announcements"`
+
+	// Flags maps a feature flag's name to where it is on. Admins can
+	// override flags at runtime.
+	Flags map[string]FlagConfig `yaml:"flags"`
+}
+
+// FlagConfig turns a feature flag on for some cities or a share of traffic.
+type FlagConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Cities limits the flag to the listed cities. Empty means every city.
+	Cities []string `yaml:"cities"`
+
+	// Percent limits the flag to a share of traffic, from 1 to 100. Zero
+	// means all of it.
+	Percent int `yaml:"percent"`
+}
+
+// AnnouncementConfig lists where a city's new movies are announced.
+type AnnouncementConfig struct {
+	DiscordWebhookURL string `yaml:"discord_webhook_url"`
+	SlackWebhookURL   string `yaml:"slack_webhook_url"`
+}
+
+// TelegramConfig enables the Telegram bot. The bot is disabled without a
+// token.
+type TelegramConfig struct {
+	BotToken string `yaml:"bot_token"`
+}
+
+// DigestConfig enables the email digest of newly added movies, sent every
+// Interval through the SMTP server at SMTPAddr. The digest is disabled
+// without an SMTP server and sender. PublicURL is the API's externally
+// reachable base URL, used in unsubscribe links.
+type DigestConfig struct {
+	SMTPAddr     string        `yaml:"smtp_addr"`
+	SMTPUsername string        `yaml:"smtp_username"`
+	SMTPPassword string        `yaml:"smtp_password"`
+	From         string        `yaml:"from"`
+	PublicURL    string        `yaml:"public_url"`
+	Interval     time.Duration `yaml:"interval"`
+}
+
+// PushConfig enables browser push notifications, signed with the VAPID key
+// pair. Push is disabled without a private key. Subject is a mailto: or
+// https: URL push services can use to reach the operator.
+type PushConfig struct {
+	VAPIDPublicKey  string `yaml:"vapid_public_key"`
+	VAPIDPrivateKey string `yaml:"vapid_private_key"`
+	Subject         string `yaml:"subject"`
+}
+
+// AuthConfig enables user accounts. Access and refresh tokens are signed with
+// JWTSecret, which must be at least 32 bytes; accounts are disabled without it.
+type AuthConfig struct {
+	JWTSecret  string        `yaml:"jwt_secret"`
+	AccessTTL  time.Duration `yaml:"access_ttl"`
+	RefreshTTL time.Duration `yaml:"refresh_ttl"`
+
+	// FrontendURL receives the tokens of OAuth sign-ins in its fragment.
+	// Without it, the OAuth callback responds with the tokens as JSON.
+	FrontendURL string       `yaml:"frontend_url"`
+	Google      GoogleConfig `yaml:"google"`
+}
+
+// GoogleConfig enables signing in with Google. RedirectURL is this server's
+// /auth/google/callback, which must be an authorized redirect URI of the
+// OAuth client.
+type GoogleConfig struct {
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	RedirectURL  string `yaml:"redirect_url"`
+}
+
+// SocialConfig posts the listings of each enabled city to the configured
+// accounts after every scrape of the city. Template is a text/template with
+// .City, .Titles, and .Count, which a city's own template overrides.
+type SocialConfig struct {
+	Template string                      `yaml:"template"`
+	Cities   map[string]SocialCityConfig `yaml:"cities"`
+	Mastodon MastodonConfig              `yaml:"mastodon"`
+	X        XConfig                     `yaml:"x"`
+}
+
+type SocialCityConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Template string `yaml:"template"`
+}
+
+// MastodonConfig enables posting to Mastodon. It is disabled without an
+// access token.
+type MastodonConfig struct {
+	Server      string `yaml:"server"`
+	AccessToken string `yaml:"access_token"`
+}
+
+// XConfig enables posting to X. It is disabled without an access token.
+type XConfig struct {
+	ConsumerKey       string `yaml:"consumer_key"`
+	ConsumerSecret    string `yaml:"consumer_secret"`
+	AccessToken       string `yaml:"access_token"`
+	AccessTokenSecret string `yaml:"access_token_secret"`
+}
+
+// RatingsConfig enables critic scores on search matches, looked up from OMDb
+// and cached for TTL. Ratings are disabled without an API key.
+type RatingsConfig struct {
+	OMDbAPIKey string        `yaml:"omdb_api_key"`
+	TTL        time.Duration `yaml:"ttl"`
+}
+
+// CityConfig registers a city's display name, timezone, and the aliases that
+// resolve to it, such as "bbsr" for "bhubaneswar". Region names one of
+// scraper.regions to scrape the city from instead of the default site.
+type CityConfig struct {
+	Name        string   `yaml:"name"`
+	DisplayName string   `yaml:"display_name"`
+	Timezone    string   `yaml:"timezone"`
+	Aliases     []string `yaml:"aliases"`
+	Region      string   `yaml:"region"`
+}
+
+// ScraperConfig describes where and how BookMyShow listings are scraped. The
+// URL and selector templates take the city as their only %s verb.
+type ScraperConfig struct {
+	Timeout      time.Duration `yaml:"timeout"`
+	URLTemplate  string        `yaml:"url_template"`
+	LinkSelector string        `yaml:"link_selector"`
+
+	// SettleDelay is how long to wait after the page loads for the listings
+	// to render.
+	SettleDelay time.Duration `yaml:"settle_delay"`
+
+	// RecordDir, when set, receives the rendered page and the links found on
+	// it for every scrape, for replaying later.
+	RecordDir string `yaml:"record_dir"`
+
+	// Mirrors are alternate URL templates tried in order when scraping
+	// URLTemplate fails.
+	Mirrors []string `yaml:"mirrors"`
+
+	// Regions holds the templates of other markets' sites, such as another
+	// country's BookMyShow domain, by region name.
+	Regions map[string]RegionConfig `yaml:"regions"`
+}
+
+// RegionConfig overrides the scraper's templates for the cities in a region.
+// Empty fields fall back to the scraper's. Mirrors replace the scraper's
+// only when URLTemplate is set.
+type RegionConfig struct {
+	URLTemplate  string   `yaml:"url_template"`
+	LinkSelector string   `yaml:"link_selector"`
+	Mirrors      []string `yaml:"mirrors"`
+}
+
+// StreamingConfig enables marking search matches that can already be streamed
+// in Region, looked up from TMDB and cached for TTL. Streaming lookups are
+// disabled without an API key.
+type StreamingConfig struct {
+	TMDBAPIKey string        `yaml:"tmdb_api_key"`
+	Region     string        `yaml:"region"`
+	TTL        time.Duration `yaml:"ttl"`
+}
+
+// StorageConfig selects where files are kept: Backend "s3" keeps them in
+// Bucket on S3 or an S3-compatible service at Endpoint, which defaults to AWS
+// in Region; "gcs" in Bucket on Google Cloud Storage, signing in with the
+// service account key in CredentialsFile or the instance's service account;
+// and "local" in the directory Dir. Storage is disabled when Backend is empty.
+type StorageConfig struct {
+	Backend         string `yaml:"backend"`
+	Bucket          string `yaml:"bucket"`
+	Endpoint        string `yaml:"endpoint"`
+	Region          string `yaml:"region"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	CredentialsFile string `yaml:"credentials_file"`
+	Dir             string `yaml:"dir"`
+
+	// ArchiveScrapes keeps the rendered page and recording of every scrape,
+	// and ArchivePreviews the rendered share images.
+	ArchiveScrapes  bool `yaml:"archive_scrapes"`
+	ArchivePreviews bool `yaml:"archive_previews"`
+}
+
+// BackupConfig enables database backups with pg_dump every Interval, uploaded
+// under Prefix to Bucket on S3 or an S3-compatible service at Endpoint, which
+// defaults to AWS in Region. Without a bucket of their own, backups go to the
+// shared storage when Enabled is set. Backups older than Retention are
+// deleted after each upload; zero keeps them all.
+type BackupConfig struct {
+	Enabled         bool          `yaml:"enabled"`
+	Endpoint        string        `yaml:"endpoint"`
+	Region          string        `yaml:"region"`
+	Bucket          string        `yaml:"bucket"`
+	Prefix          string        `yaml:"prefix"`
+	AccessKeyID     string        `yaml:"access_key_id"`
+	SecretAccessKey string        `yaml:"secret_access_key"`
+	Interval        time.Duration `yaml:"interval"`
+	Retention       time.Duration `yaml:"retention"`
+	PGDumpPath      string        `yaml:"pg_dump_path"`
+}
+
+// RateLimitConfig limits each client to Requests in every sliding Window,
+// counted per user for requests with an access token and per IP otherwise.
+// Replicas share the counts in Redis at RedisURL, or each counts on its own
+// without one. Rate limiting is disabled when Requests is zero.
+type RateLimitConfig struct {
+	Requests          int           `yaml:"requests"`
+	Window            time.Duration `yaml:"window"`
+	RedisURL          string        `yaml:"redis_url"`
+	TrustForwardedFor bool          `yaml:"trust_forwarded_for"`
+}
+
+// ScrapeChecksConfig holds back scrapes that look wrong for an admin to
+// review: ones with MaxCountDrop percent fewer movies than the city's previous
+// listings, with more than MaxDuplicates percent repeated titles, or with
+// links outside LinkDomains.
+type ScrapeChecksConfig struct {
+	Enabled       bool     `yaml:"enabled"`
+	MaxCountDrop  int      `yaml:"max_count_drop"`
+	MaxDuplicates int      `yaml:"max_duplicates"`
+	LinkDomains   []string `yaml:"link_domains"`
+}
+
+// AlertConfig controls when city health alerts fire and where they are sent.
+// Alerting is disabled when no destination is configured.
+type AlertConfig struct {
+	FailureStreak int           `yaml:"failure_streak"`
+	MaxDataAge    time.Duration `yaml:"max_data_age"`
+	CheckInterval time.Duration `yaml:"check_interval"`
+
+	WebhookURL      string `yaml:"webhook_url"`
+	SlackWebhookURL string `yaml:"slack_webhook_url"`
+
+	SMTPAddr     string   `yaml:"smtp_addr"`
+	SMTPUsername string   `yaml:"smtp_username"`
+	SMTPPassword string   `yaml:"smtp_password"`
+	EmailFrom    string   `yaml:"email_from"`
+	EmailTo      []string `yaml:"email_to"`
+}
+
+// Defaults returns the configuration used when nothing overrides it.
+func Defaults() Config {
 	return Config{
-		DBHost:        getEnv("DB_HOST", "localhost"),
-		DBPort:        getEnv("DB_PORT", "5432"),
-		DBUser:        getEnv("DB_USER", "postgres"),
-		DBPassword:    getEnv("DB_PASSWORD", "password"),
+		DBHost:     "localhost",
+		DBPort:     "5432",
+		DBUser:     "postgres",
+		DBPassword: "password",
+
 		ServerAddr:    ":8080",
 		CacheTTL:      24 * time.Hour,
-		ScrapeTimeout: 60 * time.Second,
+		StalePolicy:   "block",
 		DefaultCity:   "cuttack",
 		PreloadCities: []string{"cuttack", "bhubaneswar"},
+		Cities: []CityConfig{
+			{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Timezone: "Asia/Kolkata", Aliases: []string{"bbsr"}},
+			{Name: "cuttack", DisplayName: "Cuttack", Timezone: "Asia/Kolkata", Aliases: []string{"ctc"}},
+			{Name: "mumbai", DisplayName: "Mumbai", Timezone: "Asia/Kolkata", Aliases: []string{"bombay"}},
+		},
+		SearchMinScore: 50,
+		SearchBackend:  "fuzzy",
+		CORSOrigins:    []string{"*"},
+
+		RefreshInterval: time.Hour,
+		CleanupInterval: 24 * time.Hour,
+		DataRetention:   30 * 24 * time.Hour,
+
+		RefreshJitter:        5 * time.Minute,
+		MaxConcurrentScrapes: 2,
+		ScrapeQueueTimeout:   time.Minute,
+
+		PreviewImages:  true,
+		WebUI:          true,
+		JobMaxFailures: 5,
+		IdempotencyTTL: time.Hour,
+
+		LogFormat: "text",
+		LogLevel:  "info",
+
+		Scraper: ScraperConfig{
+			Timeout:      60 * time.Second,
+			URLTemplate:  "https://in.bookmyshow.com/explore/movies-%s",
+			LinkSelector: `a[href*="/movies/%s/"]`,
+			SettleDelay:  5 * time.Second,
+		},
+
+		Ratings: RatingsConfig{
+			TTL: 7 * 24 * time.Hour,
+		},
+
+		Streaming: StreamingConfig{
+			Region: "IN",
+			TTL:    24 * time.Hour,
+		},
+
+		Digest: DigestConfig{
+			PublicURL: "http://localhost:8080",
+			Interval:  7 * 24 * time.Hour,
+		},
+
+		Social: SocialConfig{
+			Template: "Now showing in {{.City}}: {{.Titles}}",
+		},
+
+		Auth: AuthConfig{
+			AccessTTL:  15 * time.Minute,
+			RefreshTTL: 30 * 24 * time.Hour,
+		},
+
+		Alerts: AlertConfig{
+			FailureStreak: 3,
+			MaxDataAge:    48 * time.Hour,
+			CheckInterval: 5 * time.Minute,
+		},
+
+		Storage: StorageConfig{
+			Region: "us-east-1",
+		},
+
+		Backup: BackupConfig{
+			Region:     "us-east-1",
+			Prefix:     "backups/",
+			Interval:   24 * time.Hour,
+			Retention:  30 * 24 * time.Hour,
+			PGDumpPath: "pg_dump",
+		},
+
+		RateLimit: RateLimitConfig{
+			Window: time.Minute,
+		},
+
+		ScrapeChecks: ScrapeChecksConfig{
+			Enabled:       true,
+			MaxCountDrop:  50,
+			MaxDuplicates: 20,
+			LinkDomains:   []string{"bookmyshow.com"},
+		},
 	}
 }
 
-func (c Config) ConnectionString() string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%s", c.DBUser, c.DBPassword, c.DBHost, c.DBPort)
+// Load builds the configuration from the defaults, the YAML file named by the
+// -config flag or CONFIG_FILE, environment variables, and then the flags in
+// args, and validates it.
+func Load(args []string) (Config, error) {
+	return Parse(flag.NewFlagSet("api", flag.ContinueOnError), args)
+}
+
+// Parse is Load with the configuration flags added to flags, so a command
+// can accept its own flags alongside them.
+func Parse(flags *flag.FlagSet, args []string) (Config, error) {
+	cfg := Defaults()
+
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML config file")
+	addr := flags.String("addr", "", "address to listen on")
+	defaultCity := flags.String("default-city", "", "city served when a request names none")
+	cities := flags.String("cities", "", "comma-separated cities to preload and refresh")
+	searchBackend := flags.String("search-backend", "", `search backend, "fuzzy" or "index"`)
+	logFormat := flags.String("log-format", "", `log format, "text" or "json"`)
+	logLevel := flags.String("log-level", "", "minimum log level")
+
+	if err := flags.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return Config{}, err
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "addr":
+			cfg.ServerAddr = *addr
+		case "default-city":
+			cfg.DefaultCity = *defaultCity
+		case "cities":
+			cfg.PreloadCities = splitList(*cities)
+		case "search-backend":
+			cfg.SearchBackend = *searchBackend
+		case "log-format":
+			cfg.LogFormat = *logFormat
+		case "log-level":
+			cfg.LogLevel = *logLevel
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
 }
 
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+func (c *Config) loadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	env := newEnvReader()
+
+	env.string("DB_HOST", &c.DBHost)
+	env.string("DB_PORT", &c.DBPort)
+	env.string("DB_USER", &c.DBUser)
+	env.string("DB_PASSWORD", &c.DBPassword)
+
+	env.string("SERVER_ADDR", &c.ServerAddr)
+	env.duration("CACHE_TTL", &c.CacheTTL)
+	env.string("STALE_POLICY", &c.StalePolicy)
+	env.string("DEFAULT_CITY", &c.DefaultCity)
+	env.list("PRELOAD_CITIES", &c.PreloadCities)
+	env.int("SEARCH_MIN_SCORE", &c.SearchMinScore)
+	env.string("SEARCH_BACKEND", &c.SearchBackend)
+	env.list("CORS_ORIGINS", &c.CORSOrigins)
+	env.string("ADMIN_TOKEN", &c.AdminToken)
+
+	env.duration("REFRESH_INTERVAL", &c.RefreshInterval)
+	env.duration("CLEANUP_INTERVAL", &c.CleanupInterval)
+	env.duration("DATA_RETENTION", &c.DataRetention)
+	env.duration("REFRESH_JITTER", &c.RefreshJitter)
+	env.int("MAX_CONCURRENT_SCRAPES", &c.MaxConcurrentScrapes)
+	env.duration("SCRAPE_QUEUE_TIMEOUT", &c.ScrapeQueueTimeout)
+	env.bool("SCRAPING_DISABLED", &c.ScrapingDisabled)
+	env.bool("FAKE_SCRAPER", &c.FakeScraper)
+	env.string("SCRAPE_REPLAY_DIR", &c.ReplayDir)
+	env.int("SYNTHETIC_MOVIES", &c.SyntheticMovies)
+	env.bool("PREVIEW_IMAGES", &c.PreviewImages)
+	env.bool("WEB_UI", &c.WebUI)
+	env.bool("EMBEDDED_FRONTEND", &c.EmbeddedFrontend)
+	env.string("PUBLIC_URL", &c.PublicURL)
+	env.int("JOB_MAX_FAILURES", &c.JobMaxFailures)
+	env.duration("IDEMPOTENCY_TTL", &c.IdempotencyTTL)
+
+	env.string("LOG_FORMAT", &c.LogFormat)
+	env.string("LOG_LEVEL", &c.LogLevel)
+
+	env.duration("SCRAPE_TIMEOUT", &c.Scraper.Timeout)
+	env.string("SCRAPE_URL_TEMPLATE", &c.Scraper.URLTemplate)
+	env.string("SCRAPE_LINK_SELECTOR", &c.Scraper.LinkSelector)
+	env.list("SCRAPE_MIRRORS", &c.Scraper.Mirrors)
+	env.duration("SCRAPE_SETTLE_DELAY", &c.Scraper.SettleDelay)
+	env.string("SCRAPE_RECORD_DIR", &c.Scraper.RecordDir)
+
+	env.string("OMDB_API_KEY", &c.Ratings.OMDbAPIKey)
+	env.duration("RATINGS_TTL", &c.Ratings.TTL)
+
+	env.string("TMDB_API_KEY", &c.Streaming.TMDBAPIKey)
+	env.string("STREAMING_REGION", &c.Streaming.Region)
+	env.duration("STREAMING_TTL", &c.Streaming.TTL)
+
+	env.string("TELEGRAM_BOT_TOKEN", &c.Telegram.BotToken)
+
+	env.string("DIGEST_SMTP_ADDR", &c.Digest.SMTPAddr)
+	env.string("DIGEST_SMTP_USERNAME", &c.Digest.SMTPUsername)
+	env.string("DIGEST_SMTP_PASSWORD", &c.Digest.SMTPPassword)
+	env.string("DIGEST_FROM", &c.Digest.From)
+	env.string("DIGEST_PUBLIC_URL", &c.Digest.PublicURL)
+	env.duration("DIGEST_INTERVAL", &c.Digest.Interval)
+
+	env.string("VAPID_PUBLIC_KEY", &c.Push.VAPIDPublicKey)
+	env.string("VAPID_PRIVATE_KEY", &c.Push.VAPIDPrivateKey)
+	env.string("VAPID_SUBJECT", &c.Push.Subject)
+
+	env.string("SOCIAL_TEMPLATE", &c.Social.Template)
+	env.string("SOCIAL_MASTODON_SERVER", &c.Social.Mastodon.Server)
+	env.string("SOCIAL_MASTODON_ACCESS_TOKEN", &c.Social.Mastodon.AccessToken)
+	env.string("SOCIAL_X_CONSUMER_KEY", &c.Social.X.ConsumerKey)
+	env.string("SOCIAL_X_CONSUMER_SECRET", &c.Social.X.ConsumerSecret)
+	env.string("SOCIAL_X_ACCESS_TOKEN", &c.Social.X.AccessToken)
+	env.string("SOCIAL_X_ACCESS_TOKEN_SECRET", &c.Social.X.AccessTokenSecret)
+
+	env.string("JWT_SECRET", &c.Auth.JWTSecret)
+	env.duration("AUTH_ACCESS_TTL", &c.Auth.AccessTTL)
+	env.duration("AUTH_REFRESH_TTL", &c.Auth.RefreshTTL)
+	env.string("AUTH_FRONTEND_URL", &c.Auth.FrontendURL)
+	env.string("GOOGLE_CLIENT_ID", &c.Auth.Google.ClientID)
+	env.string("GOOGLE_CLIENT_SECRET", &c.Auth.Google.ClientSecret)
+	env.string("GOOGLE_REDIRECT_URL", &c.Auth.Google.RedirectURL)
+
+	env.int("ALERT_FAILURE_STREAK", &c.Alerts.FailureStreak)
+	env.duration("ALERT_MAX_DATA_AGE", &c.Alerts.MaxDataAge)
+	env.duration("ALERT_CHECK_INTERVAL", &c.Alerts.CheckInterval)
+	env.string("ALERT_WEBHOOK_URL", &c.Alerts.WebhookURL)
+	env.string("ALERT_SLACK_WEBHOOK_URL", &c.Alerts.SlackWebhookURL)
+	env.string("ALERT_SMTP_ADDR", &c.Alerts.SMTPAddr)
+	env.string("ALERT_SMTP_USERNAME", &c.Alerts.SMTPUsername)
+	env.string("ALERT_SMTP_PASSWORD", &c.Alerts.SMTPPassword)
+	env.string("ALERT_EMAIL_FROM", &c.Alerts.EmailFrom)
+	env.list("ALERT_EMAIL_TO", &c.Alerts.EmailTo)
+
+	env.string("STORAGE_BACKEND", &c.Storage.Backend)
+	env.string("STORAGE_BUCKET", &c.Storage.Bucket)
+	env.string("STORAGE_ENDPOINT", &c.Storage.Endpoint)
+	env.string("STORAGE_REGION", &c.Storage.Region)
+	env.string("STORAGE_ACCESS_KEY_ID", &c.Storage.AccessKeyID)
+	env.string("STORAGE_SECRET_ACCESS_KEY", &c.Storage.SecretAccessKey)
+	env.string("STORAGE_GCS_CREDENTIALS_FILE", &c.Storage.CredentialsFile)
+	env.string("STORAGE_DIR", &c.Storage.Dir)
+	env.bool("STORAGE_ARCHIVE_SCRAPES", &c.Storage.ArchiveScrapes)
+	env.bool("STORAGE_ARCHIVE_PREVIEWS", &c.Storage.ArchivePreviews)
+
+	env.bool("BACKUP_ENABLED", &c.Backup.Enabled)
+	env.string("BACKUP_S3_ENDPOINT", &c.Backup.Endpoint)
+	env.string("BACKUP_S3_REGION", &c.Backup.Region)
+	env.string("BACKUP_S3_BUCKET", &c.Backup.Bucket)
+	env.string("BACKUP_S3_PREFIX", &c.Backup.Prefix)
+	env.string("BACKUP_S3_ACCESS_KEY_ID", &c.Backup.AccessKeyID)
+	env.string("BACKUP_S3_SECRET_ACCESS_KEY", &c.Backup.SecretAccessKey)
+	env.duration("BACKUP_INTERVAL", &c.Backup.Interval)
+	env.duration("BACKUP_RETENTION", &c.Backup.Retention)
+	env.string("BACKUP_PG_DUMP_PATH", &c.Backup.PGDumpPath)
+
+	env.int("RATE_LIMIT_REQUESTS", &c.RateLimit.Requests)
+	env.duration("RATE_LIMIT_WINDOW", &c.RateLimit.Window)
+	env.string("RATE_LIMIT_REDIS_URL", &c.RateLimit.RedisURL)
+	env.bool("RATE_LIMIT_TRUST_FORWARDED_FOR", &c.RateLimit.TrustForwardedFor)
+
+	env.bool("SCRAPE_CHECKS_ENABLED", &c.ScrapeChecks.Enabled)
+	env.int("SCRAPE_CHECKS_MAX_COUNT_DROP", &c.ScrapeChecks.MaxCountDrop)
+	env.int("SCRAPE_CHECKS_MAX_DUPLICATES", &c.ScrapeChecks.MaxDuplicates)
+	env.list("SCRAPE_CHECKS_LINK_DOMAINS", &c.ScrapeChecks.LinkDomains)
+
+	return errors.Join(env.errs...)
+}
+
+// Validate reports every invalid setting at once.
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.ServerAddr == "" {
+		invalid("server_addr must not be empty")
+	}
+
+	if c.CacheTTL <= 0 {
+		invalid("cache_ttl must be positive, got %s", c.CacheTTL)
+	}
+
+	switch c.StalePolicy {
+	case "block", "serve_stale", "serve_stale_and_refresh":
+	default:
+		invalid(`stale_policy must be "block", "serve_stale" or "serve_stale_and_refresh", got %q`, c.StalePolicy)
+	}
+
+	if c.DefaultCity == "" {
+		invalid("default_city must not be empty")
+	}
+
+	for _, city := range c.PreloadCities {
+		if strings.TrimSpace(city) == "" {
+			invalid("preload_cities must not contain empty names")
+			break
+		}
+	}
+
+	if c.SearchBackend != "fuzzy" && c.SearchBackend != "index" {
+		invalid(`search_backend must be "fuzzy" or "index", got %q`, c.SearchBackend)
+	}
+
+	for _, city := range c.Cities {
+		if strings.TrimSpace(city.Name) == "" {
+			invalid("cities must each have a name")
+			break
+		}
+	}
+
+	for city, announcement := range c.Announcements {
+		if announcement.DiscordWebhookURL == "" && announcement.SlackWebhookURL == "" {
+			invalid("announcements.%s must set discord_webhook_url or slack_webhook_url", city)
+		}
+	}
+
+	for name, flag := range c.Flags {
+		if !isFlagName(name) {
+			invalid("flags.%s must be named with lowercase letters, digits and underscores", name)
+		}
+		if flag.Percent < 0 || flag.Percent > 100 {
+			invalid("flags.%s.percent must be between 0 and 100, got %d", name, flag.Percent)
+		}
+	}
+
+	for _, origin := range c.CORSOrigins {
+		if strings.TrimSpace(origin) == "" {
+			invalid("cors_origins must not contain empty origins")
+			break
+		}
+	}
+
+	if c.AdminToken != "" && len(c.AdminToken) < 32 {
+		invalid("admin_token must be at least 32 bytes, got %d", len(c.AdminToken))
+	}
+
+	if c.RefreshInterval <= 0 || c.CleanupInterval <= 0 || c.DataRetention <= 0 {
+		invalid("refresh_interval, cleanup_interval, and data_retention must be positive")
+	}
+
+	if c.IdempotencyTTL < time.Second {
+		invalid("idempotency_ttl must be at least 1s, got %s", c.IdempotencyTTL)
+	}
+
+	if c.RefreshJitter < 0 {
+		invalid("refresh_jitter must not be negative, got %s", c.RefreshJitter)
+	}
+
+	if c.MaxConcurrentScrapes < 0 || c.JobMaxFailures < 0 {
+		invalid("max_concurrent_scrapes and job_max_failures must not be negative")
+	}
+
+	if c.ScrapeQueueTimeout < 0 {
+		invalid("scrape_queue_timeout must not be negative, got %s", c.ScrapeQueueTimeout)
+	}
+
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		invalid(`log_format must be "text" or "json", got %q`, c.LogFormat)
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		invalid("log_level must be debug, info, warn, or error, got %q", c.LogLevel)
+	}
+
+	if c.SyntheticMovies < 0 || c.SyntheticMovies > 10000 {
+		invalid("synthetic_movies must be between 0 and 10000, got %d", c.SyntheticMovies)
+	}
+
+	if c.FakeScraper && c.ReplayDir != "" {
+		invalid("fake_scraper and replay_dir cannot both be set")
+	}
+
+	if c.SyntheticMovies > 0 && (c.FakeScraper || c.ReplayDir != "") {
+		invalid("synthetic_movies cannot be set with fake_scraper or replay_dir")
+	}
+
+	if c.PublicURL != "" && !isHTTPURL(c.PublicURL) {
+		invalid("public_url must be an http or https URL, got %q", c.PublicURL)
+	}
+
+	if c.Scraper.Timeout <= 0 {
+		invalid("scraper.timeout must be positive, got %s", c.Scraper.Timeout)
+	}
+
+	if strings.Count(c.Scraper.URLTemplate, "%s") != 1 || strings.Count(c.Scraper.LinkSelector, "%s") != 1 {
+		invalid("scraper.url_template and scraper.link_selector must each contain exactly one %%s for the city")
+	}
+
+	for _, mirror := range c.Scraper.Mirrors {
+		if strings.Count(mirror, "%s") != 1 {
+			invalid("scraper.mirrors must each contain exactly one %%s for the city, got %q", mirror)
+		}
+	}
+
+	for name, region := range c.Scraper.Regions {
+		if (region.URLTemplate != "" && strings.Count(region.URLTemplate, "%s") != 1) || (region.LinkSelector != "" && strings.Count(region.LinkSelector, "%s") != 1) {
+			invalid("scraper.regions.%s templates must each contain exactly one %%s for the city", name)
+		}
+
+		for _, mirror := range region.Mirrors {
+			if strings.Count(mirror, "%s") != 1 {
+				invalid("scraper.regions.%s.mirrors must each contain exactly one %%s for the city, got %q", name, mirror)
+			}
+		}
+	}
+
+	for _, city := range c.Cities {
+		if _, ok := c.Scraper.Regions[city.Region]; city.Region != "" && !ok {
+			invalid("cities: %s has region %q, which is not in scraper.regions", city.Name, city.Region)
+		}
+	}
+
+	if c.Ratings.TTL <= 0 {
+		invalid("ratings.ttl must be positive, got %s", c.Ratings.TTL)
+	}
+
+	if len(c.Streaming.Region) != 2 {
+		invalid("streaming.region must be a two-letter country code, got %q", c.Streaming.Region)
+	}
+
+	if c.Streaming.TTL <= 0 {
+		invalid("streaming.ttl must be positive, got %s", c.Streaming.TTL)
+	}
+
+	if c.Digest.Interval <= 0 {
+		invalid("digest.interval must be positive, got %s", c.Digest.Interval)
+	}
+
+	if c.Digest.SMTPAddr != "" && c.Digest.PublicURL == "" {
+		invalid("digest.public_url must be set when the digest is enabled")
+	}
+
+	if c.Push.VAPIDPrivateKey != "" {
+		if c.Push.VAPIDPublicKey == "" {
+			invalid("push.vapid_public_key must be set with push.vapid_private_key")
+		}
+
+		if !strings.HasPrefix(c.Push.Subject, "mailto:") && !strings.HasPrefix(c.Push.Subject, "https://") {
+			invalid("push.subject must be a mailto: or https: URL, got %q", c.Push.Subject)
+		}
+	}
+
+	if c.Auth.JWTSecret != "" && len(c.Auth.JWTSecret) < 32 {
+		invalid("auth.jwt_secret must be at least 32 bytes, got %d", len(c.Auth.JWTSecret))
+	}
+
+	if c.Auth.AccessTTL <= 0 {
+		invalid("auth.access_ttl must be positive, got %s", c.Auth.AccessTTL)
+	}
+
+	if c.Auth.RefreshTTL <= c.Auth.AccessTTL {
+		invalid("auth.refresh_ttl must be longer than auth.access_ttl, got %s", c.Auth.RefreshTTL)
+	}
+
+	if google := c.Auth.Google; google.ClientID != "" {
+		if c.Auth.JWTSecret == "" {
+			invalid("auth.jwt_secret must be set to sign in with Google")
+		}
+
+		if google.ClientSecret == "" {
+			invalid("auth.google.client_secret must be set with auth.google.client_id")
+		}
+
+		if !isHTTPURL(google.RedirectURL) {
+			invalid("auth.google.redirect_url must be an http or https URL, got %q", google.RedirectURL)
+		}
+	}
+
+	if c.Auth.FrontendURL != "" && !isHTTPURL(c.Auth.FrontendURL) {
+		invalid("auth.frontend_url must be an http or https URL, got %q", c.Auth.FrontendURL)
+	}
+
+	if c.Social.Mastodon.AccessToken != "" && !strings.HasPrefix(c.Social.Mastodon.Server, "https://") {
+		invalid("social.mastodon.server must be an https URL, got %q", c.Social.Mastodon.Server)
+	}
+
+	if x := c.Social.X; x.AccessToken != "" && (x.ConsumerKey == "" || x.ConsumerSecret == "" || x.AccessTokenSecret == "") {
+		invalid("social.x needs consumer_key, consumer_secret, access_token, and access_token_secret")
+	}
+
+	if c.Alerts.CheckInterval <= 0 {
+		invalid("alerts.check_interval must be positive, got %s", c.Alerts.CheckInterval)
+	}
+
+	switch s := c.Storage; s.Backend {
+	case "":
+		if s.ArchiveScrapes || s.ArchivePreviews || (c.Backup.Enabled && c.Backup.Bucket == "") {
+			invalid("storage.backend must be set to archive scrapes or previews, or to enable backups without backup.bucket")
+		}
+	case "s3":
+		if s.Bucket == "" || s.AccessKeyID == "" || s.SecretAccessKey == "" {
+			invalid("storage.bucket, storage.access_key_id, and storage.secret_access_key must be set for the s3 backend")
+		}
+
+		if s.Endpoint != "" && !isHTTPURL(s.Endpoint) {
+			invalid("storage.endpoint must be an http or https URL, got %q", s.Endpoint)
+		}
+
+		if s.Endpoint == "" && s.Region == "" {
+			invalid("storage.region must be set when storage.endpoint is not")
+		}
+	case "gcs":
+		if s.Bucket == "" {
+			invalid("storage.bucket must be set for the gcs backend")
+		}
+
+		if s.Endpoint != "" && !isHTTPURL(s.Endpoint) {
+			invalid("storage.endpoint must be an http or https URL, got %q", s.Endpoint)
+		}
+	case "local":
+		if s.Dir == "" {
+			invalid("storage.dir must be set for the local backend")
+		}
+	default:
+		invalid(`storage.backend must be "s3", "gcs", or "local", got %q`, s.Backend)
+	}
+
+	if c.Backup.Interval <= 0 {
+		invalid("backup.interval must be positive, got %s", c.Backup.Interval)
+	}
+
+	if c.Backup.Retention < 0 {
+		invalid("backup.retention must not be negative, got %s", c.Backup.Retention)
+	}
+
+	if c.Backup.Bucket != "" {
+		if c.Backup.AccessKeyID == "" || c.Backup.SecretAccessKey == "" {
+			invalid("backup.access_key_id and backup.secret_access_key must be set with backup.bucket")
+		}
+
+		if c.Backup.Endpoint != "" && !isHTTPURL(c.Backup.Endpoint) {
+			invalid("backup.endpoint must be an http or https URL, got %q", c.Backup.Endpoint)
+		}
+
+		if c.Backup.Endpoint == "" && c.Backup.Region == "" {
+			invalid("backup.region must be set when backup.endpoint is not")
+		}
+	}
+
+	if c.RateLimit.Requests < 0 {
+		invalid("rate_limit.requests must not be negative, got %d", c.RateLimit.Requests)
+	}
+
+	if c.RateLimit.Window < time.Second {
+		invalid("rate_limit.window must be at least 1s, got %s", c.RateLimit.Window)
+	}
+
+	if c.RateLimit.RedisURL != "" {
+		if parsed, err := url.Parse(c.RateLimit.RedisURL); err != nil || parsed.Scheme != "redis" || parsed.Host == "" {
+			invalid("rate_limit.redis_url must be a redis:// URL")
+		}
+	}
+
+	if checks := c.ScrapeChecks; checks.Enabled {
+		if checks.MaxCountDrop < 0 || checks.MaxCountDrop > 100 || checks.MaxDuplicates < 0 || checks.MaxDuplicates > 100 {
+			invalid("scrape_checks.max_count_drop and scrape_checks.max_duplicates must be percentages between 0 and 100")
+		}
+
+		if len(checks.LinkDomains) == 0 {
+			invalid("scrape_checks.link_domains must not be empty")
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// isHTTPURL reports whether value is an absolute http or https URL.
+func isHTTPURL(value string) bool {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+func isFlagName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ConnectionString returns the database URL, with the user and password
+// escaped so secrets read from files may contain any character.
+func (c Config) ConnectionString() string {
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(c.DBUser, c.DBPassword),
+		Host:   net.JoinHostPort(c.DBHost, c.DBPort),
 	}
 
-	return defaultValue
+	return dsn.String()
 }
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"flag"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadAppliesFileThenEnvThenFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	file := `
+server_addr: ":9000"
+default_city: bhubaneswar
+cache_ttl: 12h
+preload_cities: [bhubaneswar, puri]
+scraper:
+  timeout: 90s
+`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	t.Setenv("CACHE_TTL", "6h")
+	t.Setenv("DEFAULT_CITY", "puri")
+	t.Setenv("SCRAPING_DISABLED", "true")
+
+	cfg, err := Load([]string{"-config", path, "-default-city", "cuttack"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.ServerAddr != ":9000" {
+		t.Fatalf("ServerAddr = %q, want %q", cfg.ServerAddr, ":9000")
+	}
+
+	if cfg.CacheTTL != 6*time.Hour {
+		t.Fatalf("CacheTTL = %s, want %s", cfg.CacheTTL, 6*time.Hour)
+	}
+
+	if cfg.DefaultCity != "cuttack" {
+		t.Fatalf("DefaultCity = %q, want %q", cfg.DefaultCity, "cuttack")
+	}
+
+	if want := []string{"bhubaneswar", "puri"}; !reflect.DeepEqual(cfg.PreloadCities, want) {
+		t.Fatalf("PreloadCities = %v, want %v", cfg.PreloadCities, want)
+	}
+
+	if !cfg.ScrapingDisabled {
+		t.Fatal("ScrapingDisabled = false, want true from SCRAPING_DISABLED")
+	}
+
+	if cfg.Scraper.Timeout != 90*time.Second || cfg.Scraper.URLTemplate != Defaults().Scraper.URLTemplate {
+		t.Fatalf("Scraper = %+v, want file timeout with default URL template", cfg.Scraper)
+	}
+}
+
+func TestLoadRejectsUnknownFileKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("cache_tll: 1h\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := Load([]string{"-config", path}); err == nil || !strings.Contains(err.Error(), "cache_tll") {
+		t.Fatalf("Load() error = %v, want unknown field cache_tll", err)
+	}
+}
+
+func TestLoadRejectsMalformedEnv(t *testing.T) {
+	t.Setenv("REFRESH_INTERVAL", "hourly")
+
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), "REFRESH_INTERVAL") {
+		t.Fatalf("Load() error = %v, want REFRESH_INTERVAL error", err)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	t.Parallel()
+
+	cfg := Defaults()
+	cfg.CacheTTL = 0
+	cfg.StalePolicy = "stale"
+	cfg.SearchBackend = "elastic"
+	cfg.AdminToken = "admin"
+	cfg.Scraper.URLTemplate = "https://in.bookmyshow.com/explore/movies"
+	cfg.Cities = append(cfg.Cities, CityConfig{Name: "colombo", Region: "lk"})
+	cfg.Storage.Backend = "local"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil, want error")
+	}
+
+	for _, want := range []string{"cache_ttl", "stale_policy", "admin_token", "search_backend", "scraper.url_template", `region "lk"`, "storage.dir"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("Validate() error = %v, want mention of %s", err, want)
+		}
+	}
+}
+
+func TestDefaultsAreValid(t *testing.T) {
+	t.Parallel()
+
+	if err := Defaults().Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+}
+
+func TestLoadReadsSecretsFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "alert_smtp_password"), []byte("smtp-pass\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	t.Setenv("DB_PASSWORD_FILE", passwordFile)
+	t.Setenv("SECRETS_DIR", dir)
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.DBPassword != "s3cret" {
+		t.Fatalf("DBPassword = %q, want %q", cfg.DBPassword, "s3cret")
+	}
+
+	if cfg.Alerts.SMTPPassword != "smtp-pass" {
+		t.Fatalf("Alerts.SMTPPassword = %q, want %q", cfg.Alerts.SMTPPassword, "smtp-pass")
+	}
+}
+
+func TestConnectionStringEscapesCredentials(t *testing.T) {
+	t.Parallel()
+
+	cfg := Defaults()
+	cfg.DBUser = "api user"
+	cfg.DBPassword = "p@ss:w/rd%"
+	cfg.DBHost = "::1"
+
+	dsn, err := url.Parse(cfg.ConnectionString())
+	if err != nil {
+		t.Fatalf("url.Parse(%q) error = %v", cfg.ConnectionString(), err)
+	}
+
+	password, _ := dsn.User.Password()
+	if dsn.User.Username() != cfg.DBUser || password != cfg.DBPassword {
+		t.Fatalf("credentials = %q:%q, want %q:%q", dsn.User.Username(), password, cfg.DBUser, cfg.DBPassword)
+	}
+
+	if dsn.Scheme != "postgres" || dsn.Host != "[::1]:5432" {
+		t.Fatalf("connection string = %q, want postgres://...@[::1]:5432", cfg.ConnectionString())
+	}
+}
+
+func TestLoadRejectsValueAndFileTogether(t *testing.T) {
+	t.Setenv("SECRETS_DIR", "")
+	t.Setenv("DB_PASSWORD", "plain")
+	t.Setenv("DB_PASSWORD_FILE", filepath.Join(t.TempDir(), "password"))
+
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), "DB_PASSWORD_FILE") {
+		t.Fatalf("Load() error = %v, want DB_PASSWORD and DB_PASSWORD_FILE conflict", err)
+	}
+}
+
+func TestParseAcceptsCommandFlags(t *testing.T) {
+	t.Parallel()
+
+	flags := flag.NewFlagSet("scrape", flag.ContinueOnError)
+	city := flags.String("city", "", "city to scrape")
+
+	cfg, err := Parse(flags, []string{"-city", "puri", "-log-level", "debug"})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if *city != "puri" || cfg.LogLevel != "debug" {
+		t.Fatalf("Parse() city = %q, LogLevel = %q, want puri and debug", *city, cfg.LogLevel)
+	}
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSecretsDir is where Docker and Kubernetes mount secrets by default.
+const defaultSecretsDir = "/run/secrets"
+
+// secretKeys are also read from the secrets directory, from a file named after
+// the lowercased key, such as /run/secrets/db_password.
+var secretKeys = []string{
+	"DB_USER",
+	"DB_PASSWORD",
+	"OMDB_API_KEY",
+	"TMDB_API_KEY",
+	"TELEGRAM_BOT_TOKEN",
+	"DIGEST_SMTP_USERNAME",
+	"DIGEST_SMTP_PASSWORD",
+	"VAPID_PRIVATE_KEY",
+	"SOCIAL_MASTODON_ACCESS_TOKEN",
+	"SOCIAL_X_CONSUMER_SECRET",
+	"SOCIAL_X_ACCESS_TOKEN",
+	"SOCIAL_X_ACCESS_TOKEN_SECRET",
+	"JWT_SECRET",
+	"GOOGLE_CLIENT_SECRET",
+	"ADMIN_TOKEN",
+	"ALERT_WEBHOOK_URL",
+	"ALERT_SLACK_WEBHOOK_URL",
+	"ALERT_SMTP_USERNAME",
+	"ALERT_SMTP_PASSWORD",
+	"STORAGE_SECRET_ACCESS_KEY",
+	"BACKUP_S3_SECRET_ACCESS_KEY",
+	"RATE_LIMIT_REDIS_URL",
+}
+
+// envReader overrides settings from environment variables that are set,
+// collecting parse errors instead of silently keeping the previous value.
+// Every key can instead be read from the file named by KEY_FILE, and secret
+// keys from the secrets directory, so credentials need not be passed as plain
+// environment variables.
+type envReader struct {
+	secretsDir string
+	errs       []error
+}
+
+func newEnvReader() *envReader {
+	secretsDir := defaultSecretsDir
+	if dir, exists := os.LookupEnv("SECRETS_DIR"); exists {
+		secretsDir = dir
+	}
+
+	return &envReader{secretsDir: secretsDir}
+}
+
+// lookup returns the value of key from the environment, the file named by
+// KEY_FILE, or the secrets directory, in that order.
+func (e *envReader) lookup(key string) (string, bool) {
+	value, exists := os.LookupEnv(key)
+	path, fromFile := os.LookupEnv(key + "_FILE")
+
+	switch {
+	case exists && fromFile:
+		e.errs = append(e.errs, fmt.Errorf("set only one of %s and %s_FILE", key, key))
+		return "", false
+	case exists:
+		return value, true
+	case fromFile:
+		return e.readFile(key+"_FILE", path)
+	}
+
+	if e.secretsDir == "" || !slices.Contains(secretKeys, key) {
+		return "", false
+	}
+
+	path = filepath.Join(e.secretsDir, strings.ToLower(key))
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return "", false
+	}
+
+	return e.readFile(key, path)
+}
+
+func (e *envReader) readFile(key, path string) (string, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("read %s: %w", key, err))
+		return "", false
+	}
+
+	// Secret files usually end with a newline that is not part of the value.
+	return strings.TrimRight(string(content), "\r\n"), true
+}
+
+func (e *envReader) string(key string, dst *string) {
+	if value, exists := e.lookup(key); exists {
+		*dst = value
+	}
+}
+
+func (e *envReader) int(key string, dst *int) {
+	value, exists := e.lookup(key)
+	if !exists {
+		return
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s must be an integer, got %q", key, value))
+		return
+	}
+
+	*dst = parsed
+}
+
+func (e *envReader) bool(key string, dst *bool) {
+	value, exists := e.lookup(key)
+	if !exists {
+		return
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s must be true or false, got %q", key, value))
+		return
+	}
+
+	*dst = parsed
+}
+
+func (e *envReader) duration(key string, dst *time.Duration) {
+	value, exists := e.lookup(key)
+	if !exists {
+		return
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s must be a duration such as 30s or 24h, got %q", key, value))
+		return
+	}
+
+	*dst = parsed
+}
+
+func (e *envReader) list(key string, dst *[]string) {
+	if value, exists := e.lookup(key); exists {
+		*dst = splitList(value)
+	}
+}
+
+// splitList splits a comma-separated value, dropping empty entries.
+func splitList(value string) []string {
+	var values []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			values = append(values, entry)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,224 @@
+// Package digest emails subscribers a periodic summary of the movies that
+// were added in their cities.
+package digest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+var ErrInvalidEmail = errors.New("invalid email address")
+
+// Subscription asks for a city's digest to be sent to an email address. Token
+// unsubscribes it without requiring a login.
+type Subscription struct {
+	Email string
+	City  string
+	Token string
+}
+
+// Store keeps digest subscriptions and the movies added since the last
+// digest.
+type Store interface {
+	Subscribe(ctx context.Context, subscription Subscription) error
+	Unsubscribe(ctx context.Context, token string) (bool, error)
+	Subscriptions(ctx context.Context) ([]Subscription, error)
+
+	RecordAdditions(ctx context.Context, city string, added []movies.Movie, addedAt time.Time) error
+	Additions(ctx context.Context, city string, since time.Time) ([]movies.Movie, error)
+	DeleteAdditionsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Mailer delivers a plain-text email.
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+type cityResolver interface {
+	Resolve(name string) (cities.City, bool)
+}
+
+type Options struct {
+	// Interval is how often digests are sent and how far back each one
+	// looks for added movies.
+	Interval time.Duration
+
+	// PublicURL is the API's externally reachable base URL, used to build
+	// unsubscribe links.
+	PublicURL string
+}
+
+type Digest struct {
+	store  Store
+	mailer Mailer
+	cities cityResolver
+	opts   Options
+	logger *slog.Logger
+}
+
+var _ movies.ChangeListener = (*Digest)(nil)
+
+func New(store Store, mailer Mailer, cities cityResolver, opts Options, logger *slog.Logger) *Digest {
+	return &Digest{store: store, mailer: mailer, cities: cities, opts: opts, logger: logger}
+}
+
+// Interval is how often Send should run.
+func (d *Digest) Interval() time.Duration {
+	return d.opts.Interval
+}
+
+// Subscribe signs email up for the city's digest. Subscribing again to the
+// same city keeps the existing subscription.
+func (d *Digest) Subscribe(ctx context.Context, email, city string) error {
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidEmail, email)
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return fmt.Errorf("generate unsubscribe token: %w", err)
+	}
+
+	resolved, _ := d.cities.Resolve(city)
+
+	return d.store.Subscribe(ctx, Subscription{
+		Email: strings.ToLower(address.Address),
+		City:  resolved.Name,
+		Token: token,
+	})
+}
+
+func (d *Digest) Unsubscribe(ctx context.Context, token string) (bool, error) {
+	return d.store.Unsubscribe(ctx, token)
+}
+
+// MoviesAdded records the movies for the next digest.
+func (d *Digest) MoviesAdded(ctx context.Context, city string, added []movies.Movie) error {
+	if err := d.store.RecordAdditions(ctx, city, added, time.Now()); err != nil {
+		return fmt.Errorf("record added movies: %w", err)
+	}
+
+	return nil
+}
+
+// Send emails each subscriber the movies added in their cities during the
+// last interval, skipping subscribers whose cities had none, and then forgets
+// the movies it covered.
+func (d *Digest) Send(ctx context.Context) error {
+	subscriptions, err := d.store.Subscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("load digest subscriptions: %w", err)
+	}
+
+	since := time.Now().Add(-d.opts.Interval)
+	additions := make(map[string][]movies.Movie)
+	byEmail := make(map[string][]Subscription)
+
+	for _, subscription := range subscriptions {
+		if _, loaded := additions[subscription.City]; !loaded {
+			added, err := d.store.Additions(ctx, subscription.City, since)
+			if err != nil {
+				return fmt.Errorf("load added movies: %w", err)
+			}
+
+			additions[subscription.City] = added
+		}
+
+		byEmail[subscription.Email] = append(byEmail[subscription.Email], subscription)
+	}
+
+	emails := make([]string, 0, len(byEmail))
+	for email := range byEmail {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+
+	var sendErrs []error
+	sent := 0
+
+	for _, email := range emails {
+		subject, body, ok := d.compose(byEmail[email], additions)
+		if !ok {
+			continue
+		}
+
+		if err := d.mailer.Send(ctx, email, subject, body); err != nil {
+			sendErrs = append(sendErrs, fmt.Errorf("send digest to %s: %w", email, err))
+			continue
+		}
+
+		sent++
+	}
+
+	d.logger.InfoContext(ctx, "sent digests", "sent", sent, "failed", len(sendErrs))
+
+	if _, err := d.store.DeleteAdditionsBefore(ctx, since); err != nil {
+		sendErrs = append(sendErrs, fmt.Errorf("delete old added movies: %w", err))
+	}
+
+	return errors.Join(sendErrs...)
+}
+
+// compose builds one subscriber's digest with a section per city that had
+// additions, reporting false when there is nothing to send.
+func (d *Digest) compose(subscriptions []Subscription, additions map[string][]movies.Movie) (string, string, bool) {
+	var (
+		body       strings.Builder
+		cityNames  []string
+		hasContent bool
+	)
+
+	for _, subscription := range subscriptions {
+		added := additions[subscription.City]
+		if len(added) == 0 {
+			continue
+		}
+
+		city, _ := d.cities.Resolve(subscription.City)
+		cityNames = append(cityNames, city.DisplayName)
+
+		if hasContent {
+			body.WriteString("\r\n")
+		}
+		hasContent = true
+
+		fmt.Fprintf(&body, "New in %s:\r\n", city.DisplayName)
+		for _, movie := range added {
+			fmt.Fprintf(&body, "- %s %s\r\n", movie.Title, movie.Href)
+		}
+
+		fmt.Fprintf(&body, "\r\nUnsubscribe from %s: %s\r\n", city.DisplayName, d.unsubscribeURL(subscription.Token))
+	}
+
+	if !hasContent {
+		return "", "", false
+	}
+
+	return "New movies this week in " + strings.Join(cityNames, ", "), body.String(), true
+}
+
+func (d *Digest) unsubscribeURL(token string) string {
+	return strings.TrimRight(d.opts.PublicURL, "/") + "/digest/unsubscribe?token=" + url.QueryEscape(token)
+}
+
+func newToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,162 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeStore struct {
+	mu            sync.Mutex
+	subscriptions []Subscription
+	additions     map[string][]movies.Movie
+	deletedBefore time.Time
+}
+
+func (f *fakeStore) Subscribe(_ context.Context, subscription Subscription) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.subscriptions = append(f.subscriptions, subscription)
+	return nil
+}
+
+func (f *fakeStore) Unsubscribe(_ context.Context, _ string) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeStore) Subscriptions(_ context.Context) ([]Subscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.subscriptions, nil
+}
+
+func (f *fakeStore) RecordAdditions(_ context.Context, city string, added []movies.Movie, _ time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.additions[city] = append(f.additions[city], added...)
+	return nil
+}
+
+func (f *fakeStore) Additions(_ context.Context, city string, _ time.Time) ([]movies.Movie, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.additions[city], nil
+}
+
+func (f *fakeStore) DeleteAdditionsBefore(_ context.Context, before time.Time) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.deletedBefore = before
+	return 0, nil
+}
+
+type sentEmail struct {
+	to, subject, body string
+}
+
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []sentEmail
+}
+
+func (f *fakeMailer) Send(_ context.Context, to, subject, body string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.sent = append(f.sent, sentEmail{to: to, subject: subject, body: body})
+	return nil
+}
+
+func testDigest(t *testing.T) (*Digest, *fakeStore, *fakeMailer) {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{
+		{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Aliases: []string{"bbsr"}},
+		{Name: "cuttack", DisplayName: "Cuttack"},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	store := &fakeStore{additions: map[string][]movies.Movie{}}
+	mailer := &fakeMailer{}
+	digest := New(store, mailer, registry, Options{
+		Interval:  7 * 24 * time.Hour,
+		PublicURL: "https://api.example.com/",
+	}, slog.New(slog.DiscardHandler))
+
+	return digest, store, mailer
+}
+
+func TestSubscribeNormalizesEmailAndCity(t *testing.T) {
+	t.Parallel()
+
+	digest, store, _ := testDigest(t)
+
+	if err := digest.Subscribe(context.Background(), "Reader <Reader@Example.com>", "BBSR"); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	subscription := store.subscriptions[0]
+	if subscription.Email != "reader@example.com" || subscription.City != "bhubaneswar" || len(subscription.Token) != 32 {
+		t.Fatalf("subscription = %+v, want normalized email, canonical city, and a token", subscription)
+	}
+
+	if err := digest.Subscribe(context.Background(), "not an email", "cuttack"); !errors.Is(err, ErrInvalidEmail) {
+		t.Fatalf("Subscribe() error = %v, want ErrInvalidEmail", err)
+	}
+}
+
+func TestSendEmailsEachSubscriberTheirCities(t *testing.T) {
+	t.Parallel()
+
+	digest, store, mailer := testDigest(t)
+	store.subscriptions = []Subscription{
+		{Email: "a@example.com", City: "bhubaneswar", Token: "token-a-bbsr"},
+		{Email: "a@example.com", City: "cuttack", Token: "token-a-ctc"},
+		{Email: "b@example.com", City: "cuttack", Token: "token-b-ctc"},
+	}
+
+	if err := digest.MoviesAdded(context.Background(), "bhubaneswar", []movies.Movie{{Title: "F1", Href: "https://in.bookmyshow.com/f1"}}); err != nil {
+		t.Fatalf("MoviesAdded() error = %v", err)
+	}
+
+	if err := digest.Send(context.Background()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if len(mailer.sent) != 1 {
+		t.Fatalf("sent %d emails, want 1 to the only subscriber with new movies", len(mailer.sent))
+	}
+
+	email := mailer.sent[0]
+	if email.to != "a@example.com" || email.subject != "New movies this week in Bhubaneswar" {
+		t.Fatalf("email = %+v, want Bhubaneswar digest to a@example.com", email)
+	}
+
+	for _, want := range []string{"- F1 https://in.bookmyshow.com/f1", "https://api.example.com/digest/unsubscribe?token=token-a-bbsr"} {
+		if !strings.Contains(email.body, want) {
+			t.Fatalf("body = %q, want %q", email.body, want)
+		}
+	}
+
+	if strings.Contains(email.body, "Cuttack") {
+		t.Fatalf("body = %q, want no section for Cuttack without new movies", email.body)
+	}
+
+	if store.deletedBefore.IsZero() {
+		t.Fatal("DeleteAdditionsBefore() not called, want covered movies forgotten")
+	}
+}
@@ -0,0 +1,36 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPMailer sends email through an SMTP server.
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer sends through the SMTP server at addr (host:port),
+// authenticating with PLAIN auth when username is set.
+func NewSMTPMailer(addr, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := strings.Cut(addr, ":")
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{addr: addr, auth: auth, from: from}
+}
+
+func (m *SMTPMailer) Send(_ context.Context, to, subject, body string) error {
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		m.from, to, mime.QEncoding.Encode("UTF-8", subject), time.Now().Format(time.RFC1123Z), body)
+
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(message))
+}
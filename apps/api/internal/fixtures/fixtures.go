@@ -0,0 +1,96 @@
+// Package fixtures provides canned movie listings for local development, so
+// the API can run without Chrome or network access to BookMyShow.
+package fixtures
+
+import (
+	"context"
+	"slices"
+
+	"go-scraping/internal/movies"
+)
+
+type listing struct {
+	title     string
+	slug      string
+	code      string
+	year      int
+	genres    []string
+	languages []string
+	cast      []string
+}
+
+// nationwide releases are listed in every city.
+var nationwide = []listing{
+	{"Superman", "superman", "ET00414210", 2025, []string{"Action", "Adventure", "Sci-Fi"}, []string{"English", "Hindi"}, []string{"David Corenswet", "Rachel Brosnahan", "Nicholas Hoult"}},
+	{"F1: The Movie", "f1-the-movie", "ET00403839", 2025, []string{"Action", "Drama", "Sports"}, []string{"English", "Hindi"}, []string{"Brad Pitt", "Damson Idris", "Kerry Condon"}},
+	{"Jurassic World Rebirth", "jurassic-world-rebirth", "ET00420516", 2025, []string{"Action", "Adventure", "Thriller"}, []string{"English", "Hindi", "Tamil", "Telugu"}, []string{"Scarlett Johansson", "Mahershala Ali", "Jonathan Bailey"}},
+	{"Saiyaara", "saiyaara", "ET00428173", 2025, []string{"Drama", "Romantic"}, []string{"Hindi"}, []string{"Ahaan Panday", "Aneet Padda"}},
+	{"Mission: Impossible \u2013 The Final Reckoning", "mission-impossible-the-final-reckoning", "ET00347643", 2025, []string{"Action", "Thriller"}, []string{"English", "Hindi"}, []string{"Tom Cruise", "Hayley Atwell", "Simon Pegg"}},
+	{"How to Train Your Dragon", "how-to-train-your-dragon", "ET00396731", 2025, []string{"Adventure", "Family", "Fantasy"}, []string{"English", "Hindi"}, []string{"Mason Thames", "Nico Parker", "Gerard Butler"}},
+	{"Interstellar (2014)", "interstellar", "ET00020354", 2014, []string{"Drama", "Sci-Fi"}, []string{"English"}, []string{"Matthew McConaughey", "Anne Hathaway", "Jessica Chastain"}},
+}
+
+// regional releases are listed only in the cities they are keyed by.
+var regional = map[string][]listing{
+	"cuttack": {
+		{"Bou Buttu Bhuta", "bou-buttu-bhuta", "ET00438120", 2025, []string{"Comedy", "Horror"}, []string{"Odia"}, []string{"Babushan Mohanty", "Aparajita Mohanty"}},
+		{"Dui Dosti", "dui-dosti", "ET00438344", 2025, []string{"Drama"}, []string{"Odia"}, []string{"Swaraj Barik", "Sambhabana Mohanty"}},
+	},
+	"bhubaneswar": {
+		{"Bou Buttu Bhuta", "bou-buttu-bhuta", "ET00438120", 2025, []string{"Comedy", "Horror"}, []string{"Odia"}, []string{"Babushan Mohanty", "Aparajita Mohanty"}},
+		{"Kalki 2898 AD", "kalki-2898-ad", "ET00352941", 2024, []string{"Action", "Sci-Fi"}, []string{"Telugu", "Hindi"}, []string{"Prabhas", "Amitabh Bachchan", "Deepika Padukone"}},
+	},
+	"mumbai": {
+		{"Jarann", "jarann", "ET00437601", 2025, []string{"Horror", "Thriller"}, []string{"Marathi"}, []string{"Amruta Subhash", "Anita Date-Kelkar"}},
+		{"Gulkand", "gulkand", "ET00436025", 2025, []string{"Comedy", "Family"}, []string{"Marathi"}, []string{"Sai Tamhankar", "Sameer Choughule"}},
+	},
+}
+
+// Cities returns the cities with regional fixtures, which are the ones worth
+// seeding. Any other city is listed with the nationwide releases only.
+func Cities() []string {
+	cities := make([]string, 0, len(regional))
+	for city := range regional {
+		cities = append(cities, city)
+	}
+	slices.Sort(cities)
+
+	return cities
+}
+
+// Movies returns the fixture listings for city, linked the way the
+// BookMyShow scraper links them.
+func Movies(city string) []movies.Movie {
+	listings := append(slices.Clone(nationwide), regional[city]...)
+
+	result := make([]movies.Movie, 0, len(listings))
+	for _, l := range listings {
+		result = append(result, movies.Movie{
+			Title:     l.title,
+			Href:      "https://in.bookmyshow.com/movies/" + city + "/" + l.slug + "/" + l.code,
+			Year:      l.year,
+			Genres:    slices.Clone(l.genres),
+			Languages: slices.Clone(l.languages),
+			Cast:      slices.Clone(l.cast),
+		})
+	}
+
+	return result
+}
+
+// Scraper serves the fixture listings in place of the BookMyShow scraper.
+type Scraper struct{}
+
+var _ movies.Scraper = (*Scraper)(nil)
+
+func NewScraper() *Scraper {
+	return &Scraper{}
+}
+
+func (s *Scraper) Scrape(ctx context.Context, city string) ([]movies.Movie, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return Movies(city), nil
+}
@@ -0,0 +1,60 @@
+package fixtures
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMoviesAddRegionalReleases(t *testing.T) {
+	t.Parallel()
+
+	puri := Movies("puri")
+	if len(puri) != len(nationwide) {
+		t.Fatalf("Movies(puri) returned %d movies, want the %d nationwide releases", len(puri), len(nationwide))
+	}
+
+	for _, city := range Cities() {
+		list, err := NewScraper().Scrape(context.Background(), city)
+		if err != nil {
+			t.Fatalf("Scrape(%q) error = %v", city, err)
+		}
+
+		if len(list) != len(nationwide)+len(regional[city]) {
+			t.Fatalf("Scrape(%q) returned %d movies, want %d", city, len(list), len(nationwide)+len(regional[city]))
+		}
+
+		seen := make(map[string]bool)
+		for _, movie := range list {
+			if !strings.Contains(movie.Href, "/movies/"+city+"/") || seen[movie.Href] {
+				t.Fatalf("Scrape(%q) href = %q, want a unique link for the city", city, movie.Href)
+			}
+			seen[movie.Href] = true
+		}
+	}
+}
+
+func TestSyntheticIsDeterministicPerCity(t *testing.T) {
+	t.Parallel()
+
+	first := Synthetic("puri", 200)
+	if !reflect.DeepEqual(first, Synthetic("puri", 200)) {
+		t.Fatal("Synthetic(puri) differs between calls, want the same movies")
+	}
+
+	if reflect.DeepEqual(first, Synthetic("cuttack", 200)) {
+		t.Fatal("Synthetic(cuttack) = Synthetic(puri), want movies seeded by city")
+	}
+
+	seen := make(map[string]bool)
+	for _, movie := range first {
+		if movie.Title == "" || len(movie.Genres) == 0 || len(movie.Languages) == 0 || !strings.Contains(movie.Href, "/movies/puri/") {
+			t.Fatalf("movie = %+v, want a complete listing in Puri", movie)
+		}
+		if seen[movie.Href] || seen[movie.Title] {
+			t.Fatalf("movie = %+v, want unique titles and links", movie)
+		}
+		seen[movie.Href], seen[movie.Title] = true, true
+	}
+}
@@ -0,0 +1,97 @@
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"strings"
+
+	"go-scraping/internal/movies"
+)
+
+var (
+	titleOpenings = []string{"The", "Return of the", "Beyond the", "Last", "Midnight", "Secret of the", "Rise of the", "Chasing the"}
+	titleWords    = []string{"Monsoon", "Tiger", "Horizon", "Kingdom", "Echo", "River", "Shadow", "Lantern", "Storm", "Temple", "Orbit", "Harbour", "Mirage", "Falcon", "Garden", "Signal"}
+	genreNames    = []string{"Action", "Adventure", "Comedy", "Drama", "Family", "Fantasy", "Horror", "Romantic", "Sci-Fi", "Thriller"}
+	languageNames = []string{"English", "Hindi", "Odia", "Tamil", "Telugu", "Marathi", "Bengali", "Kannada"}
+	firstNames    = []string{"Aarav", "Diya", "Kabir", "Meera", "Rohan", "Sara", "Vikram", "Anaya", "Ishaan", "Tara"}
+	lastNames     = []string{"Mohanty", "Sharma", "Iyer", "Reddy", "Patil", "Das", "Kapoor", "Nair", "Sen", "Rao"}
+)
+
+// Synthetic returns count generated movies for city. The same city and count
+// always give the same movies, and different cities mostly different ones, so
+// load tests get realistic variety without scraping anything.
+func Synthetic(city string, count int) []movies.Movie {
+	hash := fnv.New64a()
+	hash.Write([]byte(city))
+	rng := rand.New(rand.NewPCG(hash.Sum64(), 0))
+
+	result := make([]movies.Movie, 0, count)
+	for i := range count {
+		title := titleOpenings[rng.IntN(len(titleOpenings))] + " " + titleWords[rng.IntN(len(titleWords))]
+		if rng.IntN(3) == 0 {
+			title += " " + titleWords[rng.IntN(len(titleWords))]
+		}
+		// The number keeps titles distinct, which searches and links rely on.
+		title = fmt.Sprintf("%s %d", title, i+1)
+
+		slug := strings.ToLower(strings.ReplaceAll(title, " ", "-"))
+		code := fmt.Sprintf("ET9%07d", hash.Sum64()%1000000+uint64(i))
+
+		cast := make([]string, 0, 3)
+		for range 2 + rng.IntN(2) {
+			cast = append(cast, firstNames[rng.IntN(len(firstNames))]+" "+lastNames[rng.IntN(len(lastNames))])
+		}
+
+		result = append(result, movies.Movie{
+			Title:     title,
+			Href:      "https://in.bookmyshow.com/movies/" + city + "/" + slug + "/" + code,
+			Year:      2015 + rng.IntN(11),
+			Genres:    pick(rng, genreNames, 1+rng.IntN(3)),
+			Languages: pick(rng, languageNames, 1+rng.IntN(2)),
+			Cast:      cast,
+			Buzz:      rng.IntN(50000),
+		})
+	}
+
+	return result
+}
+
+// pick returns n distinct values, in the order they appear in values.
+func pick(rng *rand.Rand, values []string, n int) []string {
+	chosen := make([]bool, len(values))
+	for _, i := range rng.Perm(len(values))[:n] {
+		chosen[i] = true
+	}
+
+	picked := make([]string, 0, n)
+	for i, value := range values {
+		if chosen[i] {
+			picked = append(picked, value)
+		}
+	}
+
+	return picked
+}
+
+// SyntheticScraper serves generated listings in place of the BookMyShow
+// scraper, for staging environments and load tests.
+type SyntheticScraper struct {
+	count int
+}
+
+var _ movies.Scraper = (*SyntheticScraper)(nil)
+
+// NewSyntheticScraper lists count generated movies in every city.
+func NewSyntheticScraper(count int) *SyntheticScraper {
+	return &SyntheticScraper{count: count}
+}
+
+func (s *SyntheticScraper) Scrape(ctx context.Context, city string) ([]movies.Movie, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return Synthetic(city, s.count), nil
+}
@@ -0,0 +1,240 @@
+// Package flags turns features on for some cities or a share of traffic, so
+// risky changes can be rolled out gradually. Flags are defined in the config
+// file and can be overridden at runtime by admins, with overrides stored in
+// the database and shared by every replica.
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// refreshInterval is how often overrides are reloaded, so that a change made
+// on one replica reaches the others.
+const refreshInterval = 30 * time.Second
+
+var (
+	ErrInvalidFlag       = errors.New("invalid flag")
+	ErrOverridesDisabled = errors.New("flag overrides are disabled")
+)
+
+var namePattern = regexp.MustCompile(`^[a-z0-9_]{1,64}$`)
+
+// Flag decides whether a feature is on.
+type Flag struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+
+	// Cities limits the flag to the listed cities. Empty means every city.
+	Cities []string `json:"cities,omitempty"`
+
+	// Percent limits the flag to a share of traffic, from 1 to 100. Zero
+	// means all of it.
+	Percent int `json:"percent,omitempty"`
+
+	// Overridden reports whether the flag was set at runtime rather than in
+	// the config file.
+	Overridden bool      `json:"overridden"`
+	UpdatedAt  time.Time `json:"updated_at,omitzero"`
+}
+
+// on reports whether the flag is on for the city and the unit of traffic.
+func (f Flag) on(city, unit string) bool {
+	if !f.Enabled {
+		return false
+	}
+
+	if len(f.Cities) > 0 && !slices.Contains(f.Cities, city) {
+		return false
+	}
+
+	if f.Percent <= 0 || f.Percent >= 100 {
+		return true
+	}
+
+	return bucket(f.Name, unit) < f.Percent
+}
+
+// bucket places unit in one of 100 buckets, the same one every time for a
+// flag, so the same unit keeps getting the same answer while the share grows.
+// An empty unit gets a random bucket.
+func bucket(name, unit string) int {
+	if unit == "" {
+		return rand.IntN(100)
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(name + "\x00" + unit))
+
+	return int(hash.Sum32() % 100)
+}
+
+// Validate reports whether the flag can be saved.
+func (f Flag) Validate() error {
+	if !namePattern.MatchString(f.Name) {
+		return fmt.Errorf("%w: name must be 1 to 64 lowercase letters, digits and underscores", ErrInvalidFlag)
+	}
+
+	if f.Percent < 0 || f.Percent > 100 {
+		return fmt.Errorf("%w: percent must be between 0 and 100", ErrInvalidFlag)
+	}
+
+	return nil
+}
+
+// Store keeps the flags overridden at runtime.
+type Store interface {
+	ListFlags(ctx context.Context) ([]Flag, error)
+	UpsertFlag(ctx context.Context, flag Flag) error
+	DeleteFlag(ctx context.Context, name string) (bool, error)
+}
+
+// Set is the flags in effect: the configured ones, replaced by any override
+// with the same name.
+type Set struct {
+	store  Store
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	defaults  map[string]Flag
+	overrides map[string]Flag
+	loadedAt  time.Time
+}
+
+// New returns the flags defined by defaults. Overrides are disabled when
+// store is nil.
+func New(defaults []Flag, store Store, logger *slog.Logger) *Set {
+	s := &Set{store: store, logger: logger}
+	s.SetDefaults(defaults)
+
+	return s
+}
+
+// SetDefaults replaces the configured flags, such as after the config file is
+// reloaded.
+func (s *Set) SetDefaults(defaults []Flag) {
+	byName := make(map[string]Flag, len(defaults))
+	for _, flag := range defaults {
+		byName[flag.Name] = flag
+	}
+
+	s.mu.Lock()
+	s.defaults = byName
+	s.mu.Unlock()
+}
+
+// Enabled reports whether the named flag is on for the city and unit, the
+// part of the traffic a percentage rollout is decided by, such as a search
+// query. Flags that are not defined are fallback.
+func (s *Set) Enabled(ctx context.Context, name, city, unit string, fallback bool) bool {
+	s.mu.Lock()
+	s.refresh(ctx)
+	flag, ok := s.overrides[name]
+	if !ok {
+		flag, ok = s.defaults[name]
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return fallback
+	}
+
+	return flag.on(city, unit)
+}
+
+// List returns the flags in effect, by name.
+func (s *Set) List(ctx context.Context) []Flag {
+	s.mu.Lock()
+	s.refresh(ctx)
+	byName := make(map[string]Flag, len(s.defaults)+len(s.overrides))
+	for name, flag := range s.defaults {
+		byName[name] = flag
+	}
+	for name, flag := range s.overrides {
+		byName[name] = flag
+	}
+	s.mu.Unlock()
+
+	list := make([]Flag, 0, len(byName))
+	for _, flag := range byName {
+		list = append(list, flag)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list
+}
+
+// Override replaces the flag at runtime on every replica, taking effect
+// immediately on this one and within refreshInterval on others.
+func (s *Set) Override(ctx context.Context, flag Flag) (Flag, error) {
+	if s.store == nil {
+		return Flag{}, ErrOverridesDisabled
+	}
+
+	if err := flag.Validate(); err != nil {
+		return Flag{}, err
+	}
+
+	flag.Overridden = true
+	flag.UpdatedAt = time.Now()
+	if err := s.store.UpsertFlag(ctx, flag); err != nil {
+		return Flag{}, err
+	}
+
+	s.invalidate()
+
+	return flag, nil
+}
+
+// Reset removes the flag's override, reporting whether it had one, so the
+// configured flag applies again.
+func (s *Set) Reset(ctx context.Context, name string) (bool, error) {
+	if s.store == nil {
+		return false, ErrOverridesDisabled
+	}
+
+	removed, err := s.store.DeleteFlag(ctx, name)
+	if err != nil {
+		return false, err
+	}
+
+	s.invalidate()
+
+	return removed, nil
+}
+
+func (s *Set) invalidate() {
+	s.mu.Lock()
+	s.overrides = nil
+	s.mu.Unlock()
+}
+
+// refresh reloads the overrides once they are older than refreshInterval. A
+// failed reload keeps serving the previous ones. s.mu must be held.
+func (s *Set) refresh(ctx context.Context) {
+	if s.store == nil || (s.overrides != nil && time.Since(s.loadedAt) < refreshInterval) {
+		return
+	}
+
+	list, err := s.store.ListFlags(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to load flag overrides", "error", err)
+		return
+	}
+
+	s.overrides = make(map[string]Flag, len(list))
+	for _, flag := range list {
+		flag.Overridden = true
+		s.overrides[flag.Name] = flag
+	}
+	s.loadedAt = time.Now()
+}
@@ -0,0 +1,117 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+)
+
+type fakeStore struct {
+	flags map[string]Flag
+}
+
+func (f *fakeStore) ListFlags(_ context.Context) ([]Flag, error) {
+	list := make([]Flag, 0, len(f.flags))
+	for _, flag := range f.flags {
+		list = append(list, flag)
+	}
+
+	return list, nil
+}
+
+func (f *fakeStore) UpsertFlag(_ context.Context, flag Flag) error {
+	f.flags[flag.Name] = flag
+	return nil
+}
+
+func (f *fakeStore) DeleteFlag(_ context.Context, name string) (bool, error) {
+	_, ok := f.flags[name]
+	delete(f.flags, name)
+
+	return ok, nil
+}
+
+func TestEnabledLimitsFlagsToCities(t *testing.T) {
+	t.Parallel()
+
+	set := New([]Flag{{Name: "search_index", Enabled: true, Cities: []string{"cuttack"}}}, nil, slog.New(slog.DiscardHandler))
+	ctx := context.Background()
+
+	if !set.Enabled(ctx, "search_index", "cuttack", "", false) {
+		t.Fatal("Enabled(cuttack) = false, want true for a listed city")
+	}
+	if set.Enabled(ctx, "search_index", "puri", "", true) {
+		t.Fatal("Enabled(puri) = true, want false for a city not listed")
+	}
+	if !set.Enabled(ctx, "undefined", "puri", "", true) {
+		t.Fatal("Enabled(undefined) = false, want the fallback")
+	}
+}
+
+func TestEnabledRollsOutToShareOfUnits(t *testing.T) {
+	t.Parallel()
+
+	set := New([]Flag{{Name: "search_index", Enabled: true, Percent: 25}}, nil, slog.New(slog.DiscardHandler))
+	ctx := context.Background()
+
+	on := 0
+	for i := range 1000 {
+		unit := fmt.Sprintf("query-%d", i)
+		enabled := set.Enabled(ctx, "search_index", "cuttack", unit, false)
+		if enabled != set.Enabled(ctx, "search_index", "cuttack", unit, false) {
+			t.Fatalf("Enabled(%s) changed between calls, want the same answer for a unit", unit)
+		}
+		if enabled {
+			on++
+		}
+	}
+
+	if on < 200 || on > 300 {
+		t.Fatalf("flag on for %d of 1000 units, want about 250", on)
+	}
+}
+
+func TestOverrideReplacesConfiguredFlagUntilReset(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeStore{flags: make(map[string]Flag)}
+	set := New([]Flag{{Name: "live_scraping", Enabled: true}}, store, slog.New(slog.DiscardHandler))
+	ctx := context.Background()
+
+	if _, err := set.Override(ctx, Flag{Name: "live_scraping", Enabled: false}); err != nil {
+		t.Fatalf("Override() error = %v", err)
+	}
+
+	if set.Enabled(ctx, "live_scraping", "cuttack", "", true) {
+		t.Fatal("Enabled() = true, want the override to turn the flag off")
+	}
+	if list := set.List(ctx); len(list) != 1 || !list[0].Overridden {
+		t.Fatalf("List() = %+v, want the overridden flag", list)
+	}
+
+	if removed, err := set.Reset(ctx, "live_scraping"); err != nil || !removed {
+		t.Fatalf("Reset() = %v, %v, want true", removed, err)
+	}
+
+	if !set.Enabled(ctx, "live_scraping", "cuttack", "", false) {
+		t.Fatal("Enabled() = false, want the configured flag after reset")
+	}
+}
+
+func TestOverrideRejectsInvalidFlags(t *testing.T) {
+	t.Parallel()
+
+	set := New(nil, &fakeStore{flags: make(map[string]Flag)}, slog.New(slog.DiscardHandler))
+
+	for _, flag := range []Flag{{Name: "Search Index"}, {Name: "search_index", Percent: 150}} {
+		if _, err := set.Override(context.Background(), flag); !errors.Is(err, ErrInvalidFlag) {
+			t.Fatalf("Override(%+v) error = %v, want ErrInvalidFlag", flag, err)
+		}
+	}
+
+	if _, err := New(nil, nil, slog.New(slog.DiscardHandler)).Override(context.Background(), Flag{Name: "search_index"}); !errors.Is(err, ErrOverridesDisabled) {
+		t.Fatalf("Override() error = %v, want ErrOverridesDisabled without a store", err)
+	}
+}
@@ -0,0 +1,30 @@
+// Package frontend holds the built frontend for servers that serve it
+// themselves. Copy the frontend's build output into dist before building the
+// server; a binary built without it has only the placeholder file.
+package frontend
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+)
+
+//go:embed all:dist
+var dist embed.FS
+
+// ErrNotBuilt reports that the binary was built without the frontend.
+var ErrNotBuilt = errors.New("the server was built without the frontend: copy its build output into internal/frontend/dist and rebuild")
+
+// Files returns the embedded frontend, rooted at its index.html.
+func Files() (fs.FS, error) {
+	files, err := fs.Sub(dist, "dist")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := fs.Stat(files, "index.html"); err != nil {
+		return nil, ErrNotBuilt
+	}
+
+	return files, nil
+}
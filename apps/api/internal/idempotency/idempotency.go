@@ -0,0 +1,129 @@
+// Package idempotency remembers the responses to requests made with an
+// idempotency key, so that a retried request is answered with the first
+// response instead of repeating its effects.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInProgress means a request with the key has not finished yet.
+	ErrInProgress = errors.New("idempotency: a request with this key is in progress")
+
+	// ErrKeyReused means the key was first used for a different request.
+	ErrKeyReused = errors.New("idempotency: key was used for a different request")
+)
+
+// Response is a saved response to a request.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Store remembers requests by key until they expire, across every replica
+// that shares it.
+type Store interface {
+	// Begin claims key for the request identified by fingerprint. It
+	// returns the saved response when the request already finished, and nil
+	// when the caller should serve it and then Complete or Abandon the key.
+	// A claim that is neither completed nor abandoned expires like a saved
+	// response, so a replica that dies while serving a request does not hold
+	// its key forever.
+	Begin(ctx context.Context, key, fingerprint string) (*Response, error)
+
+	// Complete saves the response to the request that claimed key.
+	Complete(ctx context.Context, key string, response Response) error
+
+	// Abandon releases key without saving a response, so that the request
+	// can be retried.
+	Abandon(ctx context.Context, key string) error
+}
+
+type entry struct {
+	fingerprint string
+	response    *Response
+	expiresAt   time.Time
+}
+
+// Cache is a Store that keeps the responses to requests in this process for
+// TTL after they finish. Each process remembers only the requests it served,
+// so it suits a single replica.
+type Cache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	sweepAt time.Time
+}
+
+var _ Store = (*Cache)(nil)
+
+func New(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*entry),
+	}
+}
+
+func (c *Cache) Begin(_ context.Context, key, fingerprint string) (*Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if now.After(c.sweepAt) {
+		for k, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		c.sweepAt = now.Add(c.ttl)
+	}
+
+	e, ok := c.entries[key]
+	if ok && now.After(e.expiresAt) {
+		ok = false
+	}
+
+	switch {
+	case !ok:
+		c.entries[key] = &entry{fingerprint: fingerprint, expiresAt: now.Add(c.ttl)}
+		return nil, nil
+	case e.fingerprint != fingerprint:
+		return nil, ErrKeyReused
+	case e.response == nil:
+		return nil, ErrInProgress
+	default:
+		return e.response, nil
+	}
+}
+
+func (c *Cache) Complete(_ context.Context, key string, response Response) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		e.response = &response
+		e.expiresAt = c.now().Add(c.ttl)
+	}
+
+	return nil
+}
+
+func (c *Cache) Abandon(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok && e.response == nil {
+		delete(c.entries, key)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func testCache(now *time.Time) *Cache {
+	cache := New(time.Hour)
+	cache.now = func() time.Time { return *now }
+
+	return cache
+}
+
+func TestCacheReplaysCompletedRequests(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cache := testCache(&now)
+
+	if saved, err := cache.Begin(context.Background(), "retry-1", "POST /admin/jobs/cleanup/run"); saved != nil || err != nil {
+		t.Fatalf("first Begin() = %v, %v, want a new request", saved, err)
+	}
+
+	if _, err := cache.Begin(context.Background(), "retry-1", "POST /admin/jobs/cleanup/run"); !errors.Is(err, ErrInProgress) {
+		t.Fatalf("Begin() during the request error = %v, want ErrInProgress", err)
+	}
+
+	_ = cache.Complete(context.Background(), "retry-1", Response{Status: http.StatusAccepted, Body: []byte(`{"ok":true}`)})
+
+	saved, err := cache.Begin(context.Background(), "retry-1", "POST /admin/jobs/cleanup/run")
+	if err != nil || saved == nil || saved.Status != http.StatusAccepted || string(saved.Body) != `{"ok":true}` {
+		t.Fatalf("Begin() after the request = %+v, %v, want the saved response", saved, err)
+	}
+
+	if _, err := cache.Begin(context.Background(), "retry-1", "POST /admin/scraping/pause"); !errors.Is(err, ErrKeyReused) {
+		t.Fatalf("Begin() for another request error = %v, want ErrKeyReused", err)
+	}
+}
+
+func TestCacheForgetsExpiredAndAbandonedRequests(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cache := testCache(&now)
+
+	_, _ = cache.Begin(context.Background(), "retry-1", "a")
+	_ = cache.Abandon(context.Background(), "retry-1")
+	if saved, err := cache.Begin(context.Background(), "retry-1", "b"); saved != nil || err != nil {
+		t.Fatalf("Begin() after Abandon = %v, %v, want a new request", saved, err)
+	}
+
+	_ = cache.Complete(context.Background(), "retry-1", Response{Status: http.StatusOK})
+	now = now.Add(time.Hour + time.Second)
+
+	if saved, err := cache.Begin(context.Background(), "retry-1", "c"); saved != nil || err != nil {
+		t.Fatalf("Begin() after expiry = %v, %v, want a new request", saved, err)
+	}
+
+	now = now.Add(time.Hour + time.Second)
+	if saved, err := cache.Begin(context.Background(), "retry-1", "d"); saved != nil || err != nil {
+		t.Fatalf("Begin() after an unfinished request expired = %v, %v, want a new request", saved, err)
+	}
+}
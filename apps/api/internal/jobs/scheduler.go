@@ -0,0 +1,410 @@
+// Package jobs runs the service's background work — preloading, scheduled
+// refreshes, and cleanup — on fixed intervals, and records the outcome of
+// every run so operators can inspect and trigger jobs at runtime.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrUnknownJob = errors.New("unknown job")
+	ErrNotLeader  = errors.New("this replica is not the scheduler leader")
+)
+
+// Elector decides which replica runs jobs when several share a database.
+type Elector interface {
+	// Campaign blocks until this replica becomes leader or ctx is done. The
+	// returned context is cancelled when leadership is lost.
+	Campaign(ctx context.Context) (context.Context, error)
+}
+
+type Job struct {
+	Name string
+
+	// Interval between runs. Jobs with a zero interval only run on start (if
+	// RunOnStart is set) or when triggered manually.
+	Interval time.Duration
+
+	// Jitter delays every run, including the first, by a random duration up
+	// to this long so jobs sharing an interval do not all fire at once.
+	Jitter time.Duration
+
+	// MaxFailures moves the job to the dead-letter state after this many
+	// consecutive failed runs. Dead-lettered jobs are no longer scheduled
+	// until triggered manually and a run succeeds. Zero retries forever.
+	MaxFailures int
+
+	RunOnStart bool
+	Run        func(ctx context.Context) error
+}
+
+type Status struct {
+	Name         string     `json:"name"`
+	Interval     string     `json:"interval,omitempty"`
+	Running      bool       `json:"running"`
+	Runs         int64      `json:"runs"`
+	Failures     int64      `json:"failures"`
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"`
+	LastDuration string     `json:"last_duration,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	NextRunAt    *time.Time `json:"next_run_at,omitempty"`
+
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DeadLettered        bool       `json:"dead_lettered"`
+	DeadLetteredAt      *time.Time `json:"dead_lettered_at,omitempty"`
+}
+
+type jobState struct {
+	job        Job
+	trigger    chan struct{}
+	reschedule chan struct{}
+	removed    chan struct{}
+	status     Status
+}
+
+type Scheduler struct {
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	jobs   map[string]*jobState
+	wg     sync.WaitGroup
+	leader atomic.Bool
+
+	// runCtx and loops are set while jobs are running so jobs registered at
+	// runtime can be started alongside the others.
+	runCtx context.Context
+	loops  *sync.WaitGroup
+}
+
+func NewScheduler(logger *slog.Logger) *Scheduler {
+	return &Scheduler{
+		logger: logger,
+		jobs:   make(map[string]*jobState),
+	}
+}
+
+// Register adds a job, starting it immediately if jobs are already running.
+// Registering a job under an existing name replaces its definition but keeps
+// its history; if the interval or jitter changed, the next run is rescheduled
+// once any in-flight run completes.
+func (s *Scheduler) Register(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	interval := ""
+	if job.Interval > 0 {
+		interval = job.Interval.String()
+	}
+
+	if state, ok := s.jobs[job.Name]; ok {
+		changed := state.job.Interval != job.Interval || state.job.Jitter != job.Jitter
+		state.job = job
+		state.status.Interval = interval
+
+		if changed {
+			select {
+			case state.reschedule <- struct{}{}:
+			default:
+			}
+		}
+
+		return
+	}
+
+	state := &jobState{
+		job:        job,
+		trigger:    make(chan struct{}, 1),
+		reschedule: make(chan struct{}, 1),
+		removed:    make(chan struct{}),
+		status:     Status{Name: job.Name, Interval: interval},
+	}
+	s.jobs[job.Name] = state
+
+	if s.runCtx != nil {
+		s.startLoop(state)
+	}
+}
+
+// Remove unregisters the named job. A run already in progress completes, but
+// the job is not run again.
+func (s *Scheduler) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.jobs[name]
+	if !ok {
+		return ErrUnknownJob
+	}
+
+	delete(s.jobs, name)
+	close(state.removed)
+
+	return nil
+}
+
+// Start runs every registered job in its own goroutine until ctx is done. Runs
+// of the same job never overlap.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.leader.Store(true)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.runJobs(ctx)
+	}()
+}
+
+// StartElected runs jobs only while this replica holds leadership, campaigning
+// again whenever leadership is lost, until ctx is done.
+func (s *Scheduler) StartElected(ctx context.Context, elector Elector) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			leaderCtx, err := elector.Campaign(ctx)
+			if err != nil {
+				return
+			}
+
+			s.logger.InfoContext(ctx, "became scheduler leader, starting jobs")
+			s.leader.Store(true)
+			s.runJobs(leaderCtx)
+			s.leader.Store(false)
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			s.logger.WarnContext(ctx, "lost scheduler leadership, campaigning again")
+		}
+	}()
+}
+
+// Wait blocks until every job loop started by Start or StartElected has
+// returned.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// Leader reports whether this replica is currently running jobs.
+func (s *Scheduler) Leader() bool {
+	return s.leader.Load()
+}
+
+func (s *Scheduler) runJobs(ctx context.Context) {
+	var loops sync.WaitGroup
+
+	s.mu.Lock()
+	s.runCtx = ctx
+	s.loops = &loops
+	for _, state := range s.jobs {
+		s.startLoop(state)
+	}
+	s.mu.Unlock()
+
+	<-ctx.Done()
+
+	s.mu.Lock()
+	s.runCtx = nil
+	s.loops = nil
+	s.mu.Unlock()
+
+	loops.Wait()
+}
+
+// startLoop runs the job's loop until jobs stop or the job is removed. The
+// caller must hold s.mu while jobs are running.
+func (s *Scheduler) startLoop(state *jobState) {
+	ctx, loops := s.runCtx, s.loops
+
+	loops.Add(1)
+	go func() {
+		defer loops.Done()
+		s.loop(ctx, state)
+	}()
+}
+
+// Trigger queues an immediate run of the named job. A run already queued is
+// not duplicated.
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	state, ok := s.jobs[name]
+	s.mu.Unlock()
+
+	if !ok {
+		return ErrUnknownJob
+	}
+
+	if !s.leader.Load() {
+		return ErrNotLeader
+	}
+
+	select {
+	case state.trigger <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (s *Scheduler) Statuses() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]Status, 0, len(s.jobs))
+	for _, state := range s.jobs {
+		statuses = append(statuses, state.status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
+func (s *Scheduler) loop(ctx context.Context, state *jobState) {
+	timer := time.NewTimer(0)
+	timer.Stop()
+	defer timer.Stop()
+
+	var due <-chan time.Time
+	schedule := func(delay time.Duration) {
+		timer.Reset(delay)
+		due = timer.C
+		s.setNextRun(state, time.Now().Add(delay))
+	}
+
+	job, _ := s.definition(state)
+	switch {
+	case job.RunOnStart:
+		schedule(jitter(job.Jitter))
+	case job.Interval > 0:
+		schedule(job.Interval + jitter(job.Jitter))
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.setNextRun(state, time.Time{})
+			return
+		case <-state.removed:
+			return
+		case <-state.reschedule:
+			timer.Stop()
+			due = nil
+			s.setNextRun(state, time.Time{})
+
+			job, deadLettered := s.definition(state)
+			if job.Interval > 0 && !deadLettered {
+				schedule(job.Interval + jitter(job.Jitter))
+			}
+
+			continue
+		case <-due:
+		case <-state.trigger:
+			timer.Stop()
+		}
+
+		due = nil
+		s.setNextRun(state, time.Time{})
+
+		job, _ := s.definition(state)
+		if deadLettered := s.runOnce(ctx, state, job); deadLettered {
+			continue
+		}
+
+		if job.Interval > 0 {
+			schedule(job.Interval + jitter(job.Jitter))
+		}
+	}
+}
+
+// definition returns the job's current definition, which Register may replace
+// at runtime, and whether it is dead-lettered.
+func (s *Scheduler) definition(state *jobState) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return state.job, state.status.DeadLettered
+}
+
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+
+	return rand.N(max)
+}
+
+// runOnce runs the job and records the outcome, reporting whether the job is
+// now dead-lettered.
+func (s *Scheduler) runOnce(ctx context.Context, state *jobState, job Job) bool {
+	startedAt := time.Now()
+
+	s.mu.Lock()
+	state.status.Running = true
+	state.status.LastRunAt = &startedAt
+	s.mu.Unlock()
+
+	err := job.Run(ctx)
+	duration := time.Since(startedAt)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state.status.Running = false
+	state.status.Runs++
+	state.status.LastDuration = duration.Round(time.Millisecond).String()
+	state.status.LastError = ""
+
+	if err != nil {
+		state.status.Failures++
+		state.status.ConsecutiveFailures++
+		state.status.LastError = err.Error()
+		s.logger.ErrorContext(ctx, "job failed", "job", job.Name, "duration", duration, "error", err)
+
+		if maxFailures := job.MaxFailures; maxFailures > 0 && state.status.ConsecutiveFailures >= maxFailures && !state.status.DeadLettered {
+			deadLetteredAt := time.Now()
+			state.status.DeadLettered = true
+			state.status.DeadLetteredAt = &deadLetteredAt
+			s.logger.ErrorContext(ctx, "job moved to dead letter", "job", job.Name, "consecutive_failures", state.status.ConsecutiveFailures)
+		}
+
+		return state.status.DeadLettered
+	}
+
+	if state.status.DeadLettered {
+		s.logger.InfoContext(ctx, "job recovered from dead letter", "job", job.Name)
+	}
+
+	state.status.ConsecutiveFailures = 0
+	state.status.DeadLettered = false
+	state.status.DeadLetteredAt = nil
+
+	s.logger.InfoContext(ctx, "job completed", "job", job.Name, "duration", duration)
+
+	return false
+}
+
+// setNextRun records when the job will next run, clearing it for a zero time.
+func (s *Scheduler) setNextRun(state *jobState, next time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if next.IsZero() {
+		state.status.NextRunAt = nil
+		return
+	}
+
+	state.status.NextRunAt = &next
+}
@@ -0,0 +1,323 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+func waitForRuns(t *testing.T, scheduler *Scheduler, name string, runs int64) Status {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, status := range scheduler.Statuses() {
+			if status.Name == name && status.Runs >= runs && !status.Running {
+				return status
+			}
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatalf("job %s did not reach %d runs", name, runs)
+	return Status{}
+}
+
+func TestSchedulerRunsOnStartAndRecordsErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scheduler := NewScheduler(testLogger())
+	scheduler.Register(Job{
+		Name:       "preload",
+		RunOnStart: true,
+		Run: func(context.Context) error {
+			return errors.New("scrape failed")
+		},
+	})
+	scheduler.Start(ctx)
+
+	status := waitForRuns(t, scheduler, "preload", 1)
+	if status.Failures != 1 || status.LastError != "scrape failed" {
+		t.Fatalf("status = %+v, want one failure with last error", status)
+	}
+
+	if status.LastRunAt == nil || status.NextRunAt != nil {
+		t.Fatalf("status = %+v, want last run set and no next run", status)
+	}
+
+	cancel()
+	scheduler.Wait()
+}
+
+func TestSchedulerTriggerRunsJob(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := make(chan struct{}, 1)
+	scheduler := NewScheduler(testLogger())
+	scheduler.Register(Job{
+		Name:     "cleanup",
+		Interval: time.Hour,
+		Run: func(context.Context) error {
+			runs <- struct{}{}
+			return nil
+		},
+	})
+	scheduler.Start(ctx)
+
+	if err := scheduler.Trigger("cleanup"); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+
+	select {
+	case <-runs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("triggered job did not run")
+	}
+
+	status := waitForRuns(t, scheduler, "cleanup", 1)
+	if status.NextRunAt == nil || status.Interval != "1h0m0s" {
+		t.Fatalf("status = %+v, want next run scheduled hourly", status)
+	}
+
+	if err := scheduler.Trigger("missing"); !errors.Is(err, ErrUnknownJob) {
+		t.Fatalf("Trigger() error = %v, want ErrUnknownJob", err)
+	}
+}
+
+func TestJitterStaysWithinBound(t *testing.T) {
+	t.Parallel()
+
+	if got := jitter(0); got != 0 {
+		t.Fatalf("jitter(0) = %v, want 0", got)
+	}
+
+	for range 100 {
+		if got := jitter(time.Minute); got < 0 || got >= time.Minute {
+			t.Fatalf("jitter(1m) = %v, want within [0, 1m)", got)
+		}
+	}
+}
+
+func TestSchedulerSchedulesFirstRunWithinJitter(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scheduler := NewScheduler(testLogger())
+	scheduler.Register(Job{
+		Name:       "refresh:cuttack",
+		Interval:   time.Hour,
+		Jitter:     time.Hour,
+		RunOnStart: true,
+		Run:        func(context.Context) error { return nil },
+	})
+
+	before := time.Now()
+	scheduler.Start(ctx)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		status := scheduler.Statuses()[0]
+		if status.NextRunAt == nil || status.Runs > 0 {
+			time.Sleep(5 * time.Millisecond)
+			continue
+		}
+
+		if status.NextRunAt.After(before.Add(time.Hour + time.Second)) {
+			t.Fatalf("first run at %v, want within an hour of %v", status.NextRunAt, before)
+		}
+
+		return
+	}
+
+	t.Fatal("job was never scheduled")
+}
+
+func TestSchedulerDeadLettersRepeatedlyFailingJobs(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var failing atomic.Bool
+	failing.Store(true)
+
+	scheduler := NewScheduler(testLogger())
+	scheduler.Register(Job{
+		Name:        "refresh:cuttack",
+		Interval:    time.Millisecond,
+		MaxFailures: 3,
+		RunOnStart:  true,
+		Run: func(context.Context) error {
+			if failing.Load() {
+				return errors.New("blocked")
+			}
+
+			return nil
+		},
+	})
+	scheduler.Start(ctx)
+
+	waitForRuns(t, scheduler, "refresh:cuttack", 3)
+	time.Sleep(20 * time.Millisecond)
+
+	status := scheduler.Statuses()[0]
+	if !status.DeadLettered || status.Runs != 3 || status.LastError != "blocked" || status.NextRunAt != nil {
+		t.Fatalf("status = %+v, want dead-lettered after 3 runs with no next run", status)
+	}
+
+	failing.Store(false)
+
+	if err := scheduler.Trigger("refresh:cuttack"); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+
+	status = waitForRuns(t, scheduler, "refresh:cuttack", 4)
+	if status.DeadLettered || status.ConsecutiveFailures != 0 {
+		t.Fatalf("status = %+v, want recovered after a successful manual run", status)
+	}
+}
+
+type fakeElector struct {
+	campaigns chan context.CancelFunc
+}
+
+func (f *fakeElector) Campaign(ctx context.Context) (context.Context, error) {
+	leaderCtx, cancel := context.WithCancel(ctx)
+
+	select {
+	case f.campaigns <- cancel:
+		return leaderCtx, nil
+	case <-ctx.Done():
+		cancel()
+		return nil, ctx.Err()
+	}
+}
+
+func TestSchedulerRunsJobsOnlyWhileLeader(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := make(chan struct{}, 2)
+	scheduler := NewScheduler(testLogger())
+	scheduler.Register(Job{
+		Name:       "refresh:cuttack",
+		RunOnStart: true,
+		Run: func(context.Context) error {
+			runs <- struct{}{}
+			return nil
+		},
+	})
+
+	elector := &fakeElector{campaigns: make(chan context.CancelFunc)}
+	scheduler.StartElected(ctx, elector)
+
+	if err := scheduler.Trigger("refresh:cuttack"); !errors.Is(err, ErrNotLeader) {
+		t.Fatalf("Trigger() before election error = %v, want ErrNotLeader", err)
+	}
+
+	for term := 1; term <= 2; term++ {
+		loseLeadership := <-elector.campaigns
+
+		select {
+		case <-runs:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("term %d: job did not run after becoming leader", term)
+		}
+
+		if !scheduler.Leader() {
+			t.Fatalf("term %d: Leader() = false, want true", term)
+		}
+
+		loseLeadership()
+	}
+
+	cancel()
+	scheduler.Wait()
+
+	if scheduler.Leader() {
+		t.Fatal("Leader() = true after shutdown, want false")
+	}
+}
+
+func TestSchedulerRegistersAndRemovesJobsWhileRunning(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scheduler := NewScheduler(testLogger())
+	scheduler.Start(ctx)
+
+	var runs atomic.Int64
+	scheduler.Register(Job{
+		Name:       "refresh:puri",
+		Interval:   time.Hour,
+		RunOnStart: true,
+		Run: func(context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	})
+
+	waitForRuns(t, scheduler, "refresh:puri", 1)
+
+	scheduler.Register(Job{
+		Name:     "refresh:puri",
+		Interval: 2 * time.Hour,
+		Run: func(context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		status := scheduler.Statuses()[0]
+		if status.Interval == "2h0m0s" && status.Runs == 1 && status.NextRunAt != nil && time.Until(*status.NextRunAt) > time.Hour {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("status = %+v, want history kept and next run rescheduled at the new interval", status)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := scheduler.Remove("refresh:puri"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	if statuses := scheduler.Statuses(); len(statuses) != 0 {
+		t.Fatalf("Statuses() = %+v, want none", statuses)
+	}
+
+	if err := scheduler.Remove("refresh:puri"); !errors.Is(err, ErrUnknownJob) {
+		t.Fatalf("Remove() error = %v, want ErrUnknownJob", err)
+	}
+
+	cancel()
+	scheduler.Wait()
+
+	if got := runs.Load(); got != 1 {
+		t.Fatalf("runs = %d, want 1", got)
+	}
+}
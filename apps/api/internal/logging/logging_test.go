@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestNewJSONLoggerAddsContextIDs(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger, err := New(&buf, FormatJSON, "info")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := WithScrapeRunID(WithRequestID(context.Background(), "req-1"), "run-1")
+	logger.InfoContext(ctx, "scraped movies", "city", "cuttack")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if record["city"] != "cuttack" || record["request_id"] != "req-1" || record["scrape_run_id"] != "run-1" {
+		t.Fatalf("record = %v, want city, request_id, and scrape_run_id", record)
+	}
+}
+
+func TestNewFiltersBelowLevel(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger, err := New(&buf, FormatText, "warn")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	logger.Info("dropped")
+	if buf.Len() != 0 {
+		t.Fatalf("output = %q, want info records dropped at warn level", buf.String())
+	}
+
+	logger.Warn("kept")
+	if buf.Len() == 0 {
+		t.Fatal("output is empty, want warn record")
+	}
+}
+
+func TestNewRejectsUnknownSettings(t *testing.T) {
+	t.Parallel()
+
+	if _, err := New(&bytes.Buffer{}, "xml", "info"); err == nil {
+		t.Fatal("New() error = nil, want unknown format error")
+	}
+
+	if _, err := New(&bytes.Buffer{}, FormatText, "verbose"); err == nil {
+		t.Fatal("New() error = nil, want unknown level error")
+	}
+}
@@ -0,0 +1,120 @@
+package movies
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// aliasRefreshInterval bounds how long an alias added through another replica
+// takes to be picked up by this one.
+const aliasRefreshInterval = time.Minute
+
+// AliasKey is the form alias lookups are keyed by, so "HTTYD", "httyd " and
+// "H.T.T.Y.D" style variants typed by users resolve the same way.
+func AliasKey(alias string) string {
+	return strings.ToLower(foldForMatch(alias))
+}
+
+type aliasCache struct {
+	mu       sync.Mutex
+	loadedAt time.Time
+	byKey    map[string]string
+}
+
+func (s *MovieService) resolveAlias(ctx context.Context, query string) string {
+	if s.aliases == nil {
+		return query
+	}
+
+	byKey, err := s.aliasMap(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to load title aliases", "error", err)
+	}
+
+	if canonical, ok := byKey[AliasKey(query)]; ok {
+		return canonical
+	}
+
+	return query
+}
+
+// aliasMap returns the cached alias lookup table, reloading it from the store
+// once it is older than aliasRefreshInterval. A failed reload keeps serving
+// the previous table.
+func (s *MovieService) aliasMap(ctx context.Context) (map[string]string, error) {
+	s.aliasCache.mu.Lock()
+	defer s.aliasCache.mu.Unlock()
+
+	if s.aliasCache.byKey != nil && time.Since(s.aliasCache.loadedAt) < aliasRefreshInterval {
+		return s.aliasCache.byKey, nil
+	}
+
+	aliases, err := s.aliases.ListAliases(ctx)
+	if err != nil {
+		return s.aliasCache.byKey, err
+	}
+
+	byKey := make(map[string]string, len(aliases))
+	for _, alias := range aliases {
+		byKey[alias.Alias] = alias.Canonical
+	}
+
+	s.aliasCache.byKey = byKey
+	s.aliasCache.loadedAt = time.Now()
+
+	return byKey, nil
+}
+
+func (s *MovieService) invalidateAliases() {
+	s.aliasCache.mu.Lock()
+	defer s.aliasCache.mu.Unlock()
+
+	s.aliasCache.byKey = nil
+}
+
+func (s *MovieService) ListAliases(ctx context.Context) ([]Alias, error) {
+	if s.aliases == nil {
+		return nil, ErrAliasesDisabled
+	}
+
+	return s.aliases.ListAliases(ctx)
+}
+
+func (s *MovieService) AddAlias(ctx context.Context, alias, canonical string) (Alias, error) {
+	if s.aliases == nil {
+		return Alias{}, ErrAliasesDisabled
+	}
+
+	entry := Alias{
+		Alias:     AliasKey(alias),
+		Canonical: NormalizeQuery(canonical),
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.aliases.UpsertAlias(ctx, entry); err != nil {
+		return Alias{}, err
+	}
+
+	s.invalidateAliases()
+	s.memo.reset()
+
+	return entry, nil
+}
+
+func (s *MovieService) RemoveAlias(ctx context.Context, alias string) (bool, error) {
+	if s.aliases == nil {
+		return false, ErrAliasesDisabled
+	}
+
+	removed, err := s.aliases.DeleteAlias(ctx, AliasKey(alias))
+	if err != nil {
+		return false, err
+	}
+
+	s.invalidateAliases()
+	s.memo.reset()
+
+	return removed, nil
+}
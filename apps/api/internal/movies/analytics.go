@@ -0,0 +1,137 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const searchLogTimeout = 5 * time.Second
+
+// recordSearch writes the search event in the background so a slow or
+// unavailable analytics table never delays the search response.
+func (s *MovieService) recordSearch(ctx context.Context, event SearchEvent) {
+	if s.searchLog == nil {
+		return
+	}
+
+	go func() {
+		recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), searchLogTimeout)
+		defer cancel()
+
+		if err := s.searchLog.RecordSearch(recordCtx, event); err != nil {
+			s.logger.ErrorContext(recordCtx, "failed to record search", "city", event.City, "error", err)
+		}
+	}()
+}
+
+func (s *MovieService) SearchSummary(ctx context.Context, city string, since time.Time, limit int) (SearchSummary, error) {
+	if s.searchLog == nil {
+		return SearchSummary{}, ErrSearchLogDisabled
+	}
+
+	return s.searchLog.SummarizeSearches(ctx, city, since, limit)
+}
+
+// RecordClick records a click on the movie with the given ID in the city's
+// listings. Like searches, clicks are written in the background.
+func (s *MovieService) RecordClick(ctx context.Context, city, id string) error {
+	if s.searchLog == nil {
+		return ErrSearchLogDisabled
+	}
+
+	movie, err := s.FindMovie(ctx, city, id)
+	if err != nil {
+		return err
+	}
+
+	s.recordClick(ctx, city, movie)
+
+	return nil
+}
+
+// FollowLink returns the movie with the given ID in the city's listings,
+// recording a click on it when search analytics are enabled.
+func (s *MovieService) FollowLink(ctx context.Context, city, id string) (Movie, error) {
+	movie, err := s.FindMovie(ctx, city, id)
+	if err != nil {
+		return Movie{}, err
+	}
+
+	s.recordClick(ctx, city, movie)
+
+	return movie, nil
+}
+
+// FindMovie returns the movie with the given ID in the city's listings.
+func (s *MovieService) FindMovie(ctx context.Context, city, id string) (Movie, error) {
+	id, ok := ParseID(id)
+	if !ok {
+		return Movie{}, ErrMovieNotListed
+	}
+
+	return s.findListed(ctx, city, func(movie Movie) bool { return movie.ID() == id })
+}
+
+// findListed returns the first of the city's movies that match reports true
+// for, or ErrMovieNotListed.
+func (s *MovieService) findListed(ctx context.Context, city string, match func(Movie) bool) (Movie, error) {
+	listed, _, err := s.Load(ctx, city)
+	if err != nil {
+		return Movie{}, err
+	}
+
+	index := slices.IndexFunc(listed, match)
+	if index < 0 {
+		return Movie{}, ErrMovieNotListed
+	}
+
+	return listed[index], nil
+}
+
+func (s *MovieService) recordClick(ctx context.Context, city string, movie Movie) {
+	if s.searchLog == nil {
+		return
+	}
+
+	event := ClickEvent{City: city, Title: movie.Title, Href: movie.Href, ClickedAt: time.Now()}
+
+	go func() {
+		recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), searchLogTimeout)
+		defer cancel()
+
+		if err := s.searchLog.RecordClick(recordCtx, event); err != nil {
+			s.logger.ErrorContext(recordCtx, "failed to record click", "city", city, "error", err)
+		}
+	}()
+}
+
+func (s *MovieService) Trending(ctx context.Context, city string, since time.Time, limit int) (Trending, error) {
+	if s.searchLog == nil {
+		return Trending{}, ErrSearchLogDisabled
+	}
+
+	trending, err := s.searchLog.Trending(ctx, city, since, limit)
+	if err != nil {
+		return Trending{}, fmt.Errorf("query trending titles: %w", err)
+	}
+
+	return trending, nil
+}
+
+func topTitle(result []Movie) string {
+	if len(result) == 0 {
+		return ""
+	}
+
+	return result[0].Title
+}
+
+func (s *MovieService) ExportSearches(ctx context.Context, city string, since time.Time, fn func(SearchEvent) error) error {
+	if s.searchLog == nil {
+		return ErrSearchLogDisabled
+	}
+
+	return s.searchLog.ExportSearches(ctx, city, since, fn)
+}
@@ -0,0 +1,87 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Availability finds the movie best matching title across every city with
+// saved listings, aliases included, and lists the cities showing it. A city
+// shows the movie when it lists the same ID, or the same title once folded
+// for matching. It returns ErrTitleNotShowing when no city's listings match.
+func (s *MovieService) Availability(ctx context.Context, title string) (Availability, error) {
+	listings, err := s.enabledListings(ctx)
+	if err != nil {
+		return Availability{}, err
+	}
+
+	// Each title is searched once however many cities show it.
+	seen := make(map[string]bool)
+	var candidates []Movie
+	for _, listing := range listings {
+		key := AliasKey(listing.Movie.Title)
+		if !seen[key] {
+			seen[key] = true
+			candidates = append(candidates, listing.Movie)
+		}
+	}
+
+	query := s.resolveAlias(ctx, NormalizeQuery(title))
+	matches := FuzzySearch(candidates, query, SearchOptions{MinScore: s.minScore})
+	if len(matches) == 0 {
+		return Availability{}, ErrTitleNotShowing
+	}
+
+	id, key := matches[0].ID(), AliasKey(matches[0].Title)
+	availability := Availability{ID: id, Title: matches[0].Title}
+	for _, listing := range listings {
+		if listing.Movie.ID() == id || AliasKey(listing.Movie.Title) == key {
+			availability.Cities = append(availability.Cities, listing)
+		}
+	}
+
+	return availability, nil
+}
+
+// MovieAvailability lists the cities showing the movie with the given ID. It
+// returns ErrTitleNotShowing when no city lists it.
+func (s *MovieService) MovieAvailability(ctx context.Context, id string) (Availability, error) {
+	id, ok := ParseID(id)
+	if !ok {
+		return Availability{}, ErrTitleNotShowing
+	}
+
+	listings, err := s.enabledListings(ctx)
+	if err != nil {
+		return Availability{}, err
+	}
+
+	availability := Availability{ID: id}
+	for _, listing := range listings {
+		if listing.Movie.ID() == id {
+			availability.Cities = append(availability.Cities, listing)
+		}
+	}
+
+	if len(availability.Cities) == 0 {
+		return Availability{}, ErrTitleNotShowing
+	}
+
+	availability.Title = availability.Cities[0].Movie.Title
+
+	return availability, nil
+}
+
+// enabledListings returns the saved listings of every city that is not
+// disabled.
+func (s *MovieService) enabledListings(ctx context.Context) ([]Listing, error) {
+	saved, err := s.repo.ListListings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("query listings: %w", err)
+	}
+
+	return slices.DeleteFunc(saved, func(listing Listing) bool {
+		return s.citySettings(ctx, listing.City).Disabled
+	}), nil
+}
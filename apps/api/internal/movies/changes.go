@@ -0,0 +1,59 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AddRefreshListener registers a listener for every saved scrape.
+func (s *MovieService) AddRefreshListener(listener RefreshListener) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+
+	s.refreshListeners = append(s.refreshListeners, listener)
+}
+
+// saveScrape replaces the city's saved movies with a fresh scrape and tells
+// refresh listeners about it. The repository queues the movies that were not
+// in the previous listings for change listeners, through the outbox. Failing
+// to save is logged as well as returned, since loads serve the
+// scrape regardless.
+func (s *MovieService) saveScrape(ctx context.Context, city string, scraped []Movie) error {
+	s.listenersMu.RLock()
+	refreshListeners := s.refreshListeners
+	s.listenersMu.RUnlock()
+
+	if err := s.repo.ReplaceCity(ctx, city, scraped, time.Now()); err != nil {
+		s.logger.ErrorContext(ctx, "failed to save movies", "city", city, "error", err)
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "saved movies", "city", city, "movies", len(scraped))
+
+	for _, listener := range refreshListeners {
+		listener.MoviesRefreshed(ctx, city, scraped)
+	}
+
+	return nil
+}
+
+// ListNewMovies returns up to limit movies first seen in the city after since,
+// newest first.
+func (s *MovieService) ListNewMovies(ctx context.Context, city string, since time.Time, limit int) ([]Sighting, error) {
+	sightings, err := s.repo.ListSightings(ctx, city, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query new movies: %w", err)
+	}
+
+	return sightings, nil
+}
+
+func (s *MovieService) ListChanges(ctx context.Context, city string, since time.Time) (Changes, error) {
+	changes, err := s.repo.ListChanges(ctx, city, since)
+	if err != nil {
+		return Changes{}, fmt.Errorf("query movie changes: %w", err)
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,95 @@
+package movies
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type citySettingsCache struct {
+	mu       sync.Mutex
+	loadedAt time.Time
+	byCity   map[string]CitySettings
+}
+
+// citySettings returns the settings saved for the city, or the defaults when
+// there are none. Settings are reloaded like aliases, and a failed reload
+// keeps serving the previous ones.
+func (s *MovieService) citySettings(ctx context.Context, city string) CitySettings {
+	if s.settings == nil {
+		return CitySettings{City: city}
+	}
+
+	byCity, err := s.citySettingsMap(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to load city settings", "error", err)
+	}
+
+	settings, ok := byCity[city]
+	if !ok {
+		settings.City = city
+	}
+
+	return settings
+}
+
+// cacheTTLFor returns how long the city's scraped movies are served before
+// the city is scraped again.
+func (s *MovieService) cacheTTLFor(ctx context.Context, city string) time.Duration {
+	if ttl := s.citySettings(ctx, city).CacheTTL; ttl > 0 {
+		return ttl
+	}
+
+	return s.cacheTTL
+}
+
+func (s *MovieService) citySettingsMap(ctx context.Context) (map[string]CitySettings, error) {
+	s.settingsCache.mu.Lock()
+	defer s.settingsCache.mu.Unlock()
+
+	if s.settingsCache.byCity != nil && time.Since(s.settingsCache.loadedAt) < aliasRefreshInterval {
+		return s.settingsCache.byCity, nil
+	}
+
+	list, err := s.settings.ListCitySettings(ctx)
+	if err != nil {
+		return s.settingsCache.byCity, err
+	}
+
+	byCity := make(map[string]CitySettings, len(list))
+	for _, settings := range list {
+		byCity[settings.City] = settings
+	}
+
+	s.settingsCache.byCity = byCity
+	s.settingsCache.loadedAt = time.Now()
+
+	return byCity, nil
+}
+
+func (s *MovieService) ListCitySettings(ctx context.Context) ([]CitySettings, error) {
+	if s.settings == nil {
+		return nil, ErrCitySettingsDisabled
+	}
+
+	return s.settings.ListCitySettings(ctx)
+}
+
+// UpdateCitySettings replaces the city's settings. They take effect
+// immediately on this replica and within aliasRefreshInterval on others.
+func (s *MovieService) UpdateCitySettings(ctx context.Context, settings CitySettings) (CitySettings, error) {
+	if s.settings == nil {
+		return CitySettings{}, ErrCitySettingsDisabled
+	}
+
+	settings.UpdatedAt = time.Now()
+	if err := s.settings.UpsertCitySettings(ctx, settings); err != nil {
+		return CitySettings{}, err
+	}
+
+	s.settingsCache.mu.Lock()
+	s.settingsCache.byCity = nil
+	s.settingsCache.mu.Unlock()
+
+	return settings, nil
+}
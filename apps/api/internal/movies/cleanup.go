@@ -0,0 +1,43 @@
+package movies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Cleanup deletes movies from cities not scraped since before and, when
+// analytics are enabled, searches, clicks and requests older than before.
+// Daily click counts are kept from the UTC day of before onwards.
+func (s *MovieService) Cleanup(ctx context.Context, before time.Time) error {
+	var cleanupErrs []error
+
+	deletedMovies, err := s.repo.DeleteScrapedBefore(ctx, before)
+	if err != nil {
+		cleanupErrs = append(cleanupErrs, fmt.Errorf("delete stale movies: %w", err))
+	} else if deletedMovies > 0 {
+		s.memo.reset()
+		s.logger.InfoContext(ctx, "deleted stale movies", "movies", deletedMovies, "before", before)
+	}
+
+	if s.searchLog != nil {
+		deletedSearches, err := s.searchLog.DeleteSearchesBefore(ctx, before)
+		if err != nil {
+			cleanupErrs = append(cleanupErrs, fmt.Errorf("delete old searches: %w", err))
+		} else if deletedSearches > 0 {
+			s.logger.InfoContext(ctx, "deleted old search events", "searches", deletedSearches, "before", before)
+		}
+	}
+
+	if s.requestLog != nil {
+		deletedRequests, err := s.requestLog.DeleteRequestsBefore(ctx, before)
+		if err != nil {
+			cleanupErrs = append(cleanupErrs, fmt.Errorf("delete old requests: %w", err))
+		} else if deletedRequests > 0 {
+			s.logger.InfoContext(ctx, "deleted old request events", "requests", deletedRequests, "before", before)
+		}
+	}
+
+	return errors.Join(cleanupErrs...)
+}
@@ -0,0 +1,123 @@
+package movies
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// autoFuzziness allows no typos in very short queries, where a single edit
+// already changes most of the word, and up to two edits in longer ones.
+func autoFuzziness(query string) int {
+	switch length := utf8.RuneCountInString(query); {
+	case length <= 3:
+		return 0
+	case length <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// titleDistance returns the smallest edit distance between query and either
+// the whole title or any run of consecutive title words with the same word
+// count as the query, so "oppenhiemer" still lines up with "oppenheimer (imax)".
+func titleDistance(title, query string) int {
+	best := levenshtein(title, query)
+
+	titleWords := strings.Fields(title)
+	queryWords := len(strings.Fields(query))
+	if queryWords == 0 || queryWords > len(titleWords) {
+		return best
+	}
+
+	for start := 0; start+queryWords <= len(titleWords); start++ {
+		window := strings.Join(titleWords[start:start+queryWords], " ")
+		if distance := levenshtein(window, query); distance < best {
+			best = distance
+		}
+	}
+
+	return best
+}
+
+func levenshtein(a, b string) int {
+	// Titles and queries are short, so the runes and rows usually fit in
+	// these stack buffers and the search hot path does not allocate.
+	var (
+		sourceBuf, targetBuf [64]rune
+		rowBuf               [2 * 65]int
+	)
+
+	source := appendRunes(sourceBuf[:0], a)
+	target := appendRunes(targetBuf[:0], b)
+
+	if len(source) == 0 {
+		return len(target)
+	}
+
+	if len(target) == 0 {
+		return len(source)
+	}
+
+	rows := rowBuf[:0]
+	if len(target)+1 > len(rowBuf)/2 {
+		rows = make([]int, 0, 2*(len(target)+1))
+	}
+	rows = rows[:2*(len(target)+1)]
+	previous, current := rows[:len(target)+1], rows[len(target)+1:]
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(
+				previous[j]+1,
+				current[j-1]+1,
+				previous[j-1]+cost,
+			)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(target)]
+}
+
+func appendRunes(dst []rune, s string) []rune {
+	for _, r := range s {
+		dst = append(dst, r)
+	}
+
+	return dst
+}
+
+// closestTitle returns the title nearest to query by edit distance, or "" when
+// even the nearest one differs in more than half of the query's characters.
+func closestTitle(list []Movie, query string) string {
+	folded := strings.ToLower(foldForMatch(query))
+	if folded == "" {
+		return ""
+	}
+
+	best := ""
+	bestDistance := utf8.RuneCountInString(folded)/2 + 1
+
+	for _, movie := range list {
+		distance := titleDistance(strings.ToLower(foldForMatch(movie.Title)), folded)
+		if distance < bestDistance {
+			best = movie.Title
+			bestDistance = distance
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,149 @@
+package movies
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// editionLanguages are the languages BookMyShow names in a title when it
+// lists a film once per language, as in "Kalki 2898 AD (Telugu)".
+var editionLanguages = []string{
+	"Assamese", "Bengali", "Bhojpuri", "English", "Gujarati", "Hindi", "Kannada", "Konkani",
+	"Malayalam", "Marathi", "Odia", "Punjabi", "Tamil", "Telugu", "Tulu", "Urdu",
+	"Chinese", "French", "Japanese", "Korean", "Spanish",
+}
+
+// editionSuffix matches a language named in parentheses or brackets at the
+// end of a title.
+var editionSuffix = regexp.MustCompile(`^(.+?)\s*[(\[]\s*([A-Za-z]+)\s*[)\]]$`)
+
+// splitEdition returns a title without the language it names at its end, and
+// that language, or ok false when it names none.
+func splitEdition(title string) (base, language string, ok bool) {
+	match := editionSuffix.FindStringSubmatch(title)
+	if match == nil {
+		return title, "", false
+	}
+
+	i := slices.IndexFunc(editionLanguages, func(name string) bool { return strings.EqualFold(name, match[2]) })
+	if i < 0 {
+		return title, "", false
+	}
+
+	return match[1], editionLanguages[i], true
+}
+
+// GroupEditions merges the cards of a film listed once per language into one
+// movie at the position of its first card. The merged movie is titled without
+// the language, shows in every edition's languages, and links to each
+// edition. A card without a language in its title joins the editions of the
+// same title and becomes the merged movie's link; cards with the same title
+// and no editions are left alone, since they may be different films.
+func GroupEditions(list []Movie) []Movie {
+	type edition struct {
+		base     string
+		key      string
+		language string
+	}
+
+	editions := make([]edition, len(list))
+	hasEditions := make(map[string]bool)
+	for i, movie := range list {
+		base, language, ok := splitEdition(movie.Title)
+		key := strings.ToLower(foldForMatch(base))
+		editions[i] = edition{base: base, key: key, language: language}
+		if ok {
+			hasEditions[key] = true
+		}
+	}
+
+	grouped := make([]Movie, 0, len(list))
+	positions := make(map[string]int)
+	for i, movie := range list {
+		e := editions[i]
+		if !hasEditions[e.key] {
+			grouped = append(grouped, movie)
+			continue
+		}
+
+		position, seen := positions[e.key]
+		if !seen {
+			positions[e.key] = len(grouped)
+			grouped = append(grouped, editionOf(movie, e.base))
+			position = len(grouped) - 1
+		} else {
+			mergeEdition(&grouped[position], movie)
+		}
+
+		merged := &grouped[position]
+		language := e.language
+		if language == "" {
+			// The card without a language is the film's own listing.
+			merged.Href = movie.Href
+			if len(movie.Languages) == 1 {
+				language = movie.Languages[0]
+			}
+		}
+
+		if language != "" {
+			merged.Editions = append(merged.Editions, Edition{Language: language, Href: movie.Href})
+			if !slices.ContainsFunc(merged.Languages, func(l string) bool { return strings.EqualFold(l, language) }) {
+				merged.Languages = append(merged.Languages, language)
+			}
+		}
+	}
+
+	return grouped
+}
+
+// editionOf starts a merged movie from the first card of a film's editions.
+func editionOf(movie Movie, base string) Movie {
+	merged := movie
+	merged.Title = base
+	merged.Genres = slices.Clone(movie.Genres)
+	merged.Languages = slices.Clone(movie.Languages)
+	merged.Cast = slices.Clone(movie.Cast)
+	merged.Editions = nil
+
+	// Highlights past the base title covered the dropped language.
+	length := utf8.RuneCountInString(base)
+	merged.Highlights = slices.DeleteFunc(slices.Clone(movie.Highlights), func(h Highlight) bool { return h.End > length })
+
+	return merged
+}
+
+// mergeEdition adds another card of the same film to merged.
+func mergeEdition(merged *Movie, movie Movie) {
+	if merged.Year == 0 {
+		merged.Year = movie.Year
+	}
+
+	merged.Genres = appendMissing(merged.Genres, movie.Genres)
+	merged.Languages = appendMissing(merged.Languages, movie.Languages)
+	merged.Cast = appendMissing(merged.Cast, movie.Cast)
+	merged.Score = max(merged.Score, movie.Score)
+	merged.Buzz += movie.Buzz
+
+	if merged.Ratings == nil {
+		merged.Ratings = movie.Ratings
+		merged.LetterboxdURL = movie.LetterboxdURL
+		merged.IMDbURL = movie.IMDbURL
+	}
+
+	if merged.Streaming == nil {
+		merged.Streaming = movie.Streaming
+	}
+}
+
+// appendMissing appends the values not already in list, ignoring case.
+func appendMissing(list, values []string) []string {
+	for _, value := range values {
+		if !slices.ContainsFunc(list, func(v string) bool { return strings.EqualFold(v, value) }) {
+			list = append(list, value)
+		}
+	}
+
+	return list
+}
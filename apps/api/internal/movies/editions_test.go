@@ -0,0 +1,49 @@
+package movies
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupEditionsMergesLanguageCards(t *testing.T) {
+	t.Parallel()
+
+	list := []Movie{
+		{Title: "Kalki 2898 AD (Telugu)", Href: "/kalki-te", Year: 2024, Genres: []string{"Action"}, Buzz: 300, Highlights: []Highlight{{Start: 0, End: 5}, {Start: 15, End: 21}}},
+		{Title: "Thug Life", Href: "/thug-life"},
+		{Title: "Kalki 2898 AD [hindi]", Href: "/kalki-hi", Genres: []string{"Action", "Sci-Fi"}, Languages: []string{"Hindi"}, Buzz: 200},
+		{Title: "Kalki 2898 AD", Href: "/kalki", Languages: []string{"Tamil"}},
+		{Title: "Superman", Href: "/superman-1"},
+		{Title: "Superman", Href: "/superman-2"},
+		{Title: "Pokemon (The Movie)", Href: "/pokemon"},
+	}
+
+	want := []Movie{
+		{
+			Title:      "Kalki 2898 AD",
+			Href:       "/kalki",
+			Year:       2024,
+			Genres:     []string{"Action", "Sci-Fi"},
+			Languages:  []string{"Telugu", "Hindi", "Tamil"},
+			Buzz:       500,
+			Highlights: []Highlight{{Start: 0, End: 5}},
+			Editions: []Edition{
+				{Language: "Telugu", Href: "/kalki-te"},
+				{Language: "Hindi", Href: "/kalki-hi"},
+				{Language: "Tamil", Href: "/kalki"},
+			},
+		},
+		{Title: "Thug Life", Href: "/thug-life"},
+		{Title: "Superman", Href: "/superman-1"},
+		{Title: "Superman", Href: "/superman-2"},
+		{Title: "Pokemon (The Movie)", Href: "/pokemon"},
+	}
+
+	if got := GroupEditions(list); !reflect.DeepEqual(got, want) {
+		t.Fatalf("GroupEditions() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if list[0].Title != "Kalki 2898 AD (Telugu)" || len(list[0].Highlights) != 2 {
+		t.Fatalf("GroupEditions() changed its input: %+v", list[0])
+	}
+}
@@ -0,0 +1,33 @@
+package movies
+
+import (
+	"context"
+	"slices"
+)
+
+// maxEnrichedMatches bounds how many search matches get ratings and streaming
+// availability so a broad query cannot trigger a burst of external lookups.
+const maxEnrichedMatches = 5
+
+// enrich returns a copy of matches with ratings and streaming availability set
+// on the top matches, for whichever lookups are enabled.
+func (s *MovieService) enrich(ctx context.Context, matches []Movie) []Movie {
+	if (s.ratings == nil && s.streaming == nil) || len(matches) == 0 {
+		return matches
+	}
+
+	// Matches may be shared with the search memo, so they are copied rather
+	// than modified in place.
+	enriched := slices.Clone(matches)
+	for i := range enriched[:min(len(enriched), maxEnrichedMatches)] {
+		if s.ratings != nil {
+			s.addRatings(ctx, &enriched[i])
+		}
+
+		if s.streaming != nil {
+			s.addStreaming(ctx, &enriched[i])
+		}
+	}
+
+	return enriched
+}
@@ -0,0 +1,63 @@
+package movies
+
+import "errors"
+
+// Errors the service reports for conditions clients can act on. The API maps
+// each to its own status and error code; any other error is an internal
+// failure whose details are only logged.
+var (
+	// ErrCityUnknown is returned for city names that are neither registered
+	// nor valid BookMyShow city slugs.
+	ErrCityUnknown = errors.New("unknown city")
+
+	// ErrScrapeBlocked is returned when the listings site serves a bot check
+	// or access-denied page instead of listings.
+	ErrScrapeBlocked = errors.New("scrape was blocked by the listings site")
+
+	// ErrScrapeQueueFull is returned when every Chrome slot stayed busy for
+	// the whole queue timeout.
+	ErrScrapeQueueFull = errors.New("timed out waiting for a free scrape slot")
+
+	ErrScrapeEmpty        = errors.New("scrape returned no movies")
+	ErrScrapingPaused     = errors.New("scraping is paused and no cached movies are available")
+	ErrScrapingDisabled   = errors.New("scraping is disabled and no cached movies are available")
+	ErrShuttingDown       = errors.New("service is shutting down")
+	ErrSearchLogDisabled  = errors.New("search analytics are disabled")
+	ErrTrafficLogDisabled = errors.New("traffic analytics are disabled")
+	ErrAliasesDisabled    = errors.New("title aliases are disabled")
+
+	ErrTitleVariantsDisabled = errors.New("title variants are disabled")
+	ErrCitySettingsDisabled  = errors.New("city settings are disabled")
+
+	// ErrCityDisabled is returned for cities an admin has turned off.
+	ErrCityDisabled = errors.New("city is disabled")
+
+	// ErrLanguageUnknown is returned for language tags that are not valid
+	// BCP 47.
+	ErrLanguageUnknown = errors.New("unknown language")
+
+	// ErrMovieNotListed is returned for a link that is not in the city's
+	// current listings.
+	ErrMovieNotListed = errors.New("movie is not listed in the city")
+
+	// ErrMovieNeverListed is returned for a movie that no scrape of the city
+	// ever found.
+	ErrMovieNeverListed = errors.New("movie was never listed in the city")
+
+	// ErrNoMatchingMovies is returned when none of the city's movies match a
+	// filter.
+	ErrNoMatchingMovies = errors.New("no movies match the filter")
+
+	// ErrTitleNotShowing is returned when a title is not in any city's
+	// listings.
+	ErrTitleNotShowing = errors.New("title is not showing in any city")
+
+	// ErrScrapeQuarantined is returned for a city whose only scrape failed
+	// the quality checks and awaits review.
+	ErrScrapeQuarantined = errors.New("scrape is quarantined for review")
+
+	ErrQuarantineDisabled = errors.New("scrape quarantine is disabled")
+
+	ErrTrailersDisabled = errors.New("trailer lookups are disabled")
+	ErrTrailerNotFound  = errors.New("no trailer was found for the movie")
+)
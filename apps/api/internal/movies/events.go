@@ -0,0 +1,139 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Listing event types.
+const (
+	EventMovieAdded      = "movie_added"
+	EventMovieRemoved    = "movie_removed"
+	EventMetadataChanged = "metadata_changed"
+)
+
+// TopicMoviesAdded is the outbox topic for the movies a scrape found that
+// were not in the city's previous listings. Its key is the city and its
+// payload an AddedMovies. A city's first scrape adds none, so a new
+// deployment does not announce every movie at once.
+const TopicMoviesAdded = "movies_added"
+
+// AddedMovies is the payload of a TopicMoviesAdded message.
+type AddedMovies struct {
+	City   string  `json:"city"`
+	Movies []Movie `json:"movies"`
+}
+
+// ListingEvent is a change a scrape observed in a city's listings. Events are
+// only ever appended, so a city's listings at any point are the projection of
+// its events up to then.
+type ListingEvent struct {
+	// ID orders events across cities and is the cursor for reading them.
+	ID   int64
+	City string
+	Type string
+
+	// Movie is the movie as scraped: after the change for added and changed
+	// movies, and as last listed for removed ones.
+	Movie Movie
+
+	// Changed names the fields of a metadata_changed event that differ from
+	// the previous listing.
+	Changed    []string
+	OccurredAt time.Time
+}
+
+// DiffListings returns the events that turn a city's previous listings into
+// current, with movies matched by link. Buzz and re-release flags are left
+// out, since they change on nearly every scrape.
+func DiffListings(city string, previous, current []Movie, at time.Time) []ListingEvent {
+	before := make(map[string]Movie, len(previous))
+	for _, movie := range previous {
+		before[movie.Href] = movie
+	}
+
+	var events []ListingEvent
+	listed := make(map[string]bool, len(current))
+	for _, movie := range current {
+		listed[movie.Href] = true
+
+		old, ok := before[movie.Href]
+		if !ok {
+			events = append(events, ListingEvent{City: city, Type: EventMovieAdded, Movie: movie, OccurredAt: at})
+			continue
+		}
+
+		if changed := changedFields(old, movie); len(changed) > 0 {
+			events = append(events, ListingEvent{City: city, Type: EventMetadataChanged, Movie: movie, Changed: changed, OccurredAt: at})
+		}
+	}
+
+	for _, movie := range previous {
+		if !listed[movie.Href] {
+			events = append(events, ListingEvent{City: city, Type: EventMovieRemoved, Movie: movie, OccurredAt: at})
+		}
+	}
+
+	return events
+}
+
+func changedFields(old, movie Movie) []string {
+	var changed []string
+	if old.Title != movie.Title {
+		changed = append(changed, "title")
+	}
+	if old.Year != movie.Year {
+		changed = append(changed, "year")
+	}
+	if !slices.Equal(old.Genres, movie.Genres) {
+		changed = append(changed, "genres")
+	}
+	if !slices.Equal(old.Languages, movie.Languages) {
+		changed = append(changed, "languages")
+	}
+	if !slices.Equal(old.Cast, movie.Cast) {
+		changed = append(changed, "cast")
+	}
+
+	return changed
+}
+
+// ProjectListings replays events, oldest first, into each city's listings as
+// of the last event, keeping movies in the order they were first added.
+func ProjectListings(events []ListingEvent) map[string][]Movie {
+	listings := make(map[string][]Movie)
+	for _, event := range events {
+		list := listings[event.City]
+		index := slices.IndexFunc(list, func(movie Movie) bool { return movie.Href == event.Movie.Href })
+
+		switch event.Type {
+		case EventMovieAdded, EventMetadataChanged:
+			if index >= 0 {
+				list[index] = event.Movie
+			} else {
+				list = append(list, event.Movie)
+			}
+		case EventMovieRemoved:
+			if index >= 0 {
+				list = slices.Delete(list, index, index+1)
+			}
+		}
+
+		listings[event.City] = list
+	}
+
+	return listings
+}
+
+// ListEvents returns up to limit of the city's listing events after the event
+// with ID after, oldest first. An empty city lists every city's events.
+func (s *MovieService) ListEvents(ctx context.Context, city string, after int64, limit int) ([]ListingEvent, error) {
+	events, err := s.repo.ListEvents(ctx, city, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query listing events: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,62 @@
+package movies
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffListingsReportsAddedRemovedAndChangedMovies(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	previous := []Movie{
+		{Title: "F1", Href: "/f1", Year: 2025, Languages: []string{"English"}, Buzz: 100},
+		{Title: "Jaws", Href: "/jaws", Year: 1975},
+		{Title: "Sitaare", Href: "/sitaare", Year: 2025},
+	}
+	current := []Movie{
+		{Title: "F1: The Movie", Href: "/f1", Year: 2025, Languages: []string{"English", "Hindi"}, Buzz: 250},
+		{Title: "Sitaare", Href: "/sitaare", Year: 2025, Buzz: 40},
+		{Title: "Superman", Href: "/superman", Year: 2025},
+	}
+
+	events := DiffListings("cuttack", previous, current, at)
+
+	var got []string
+	for _, event := range events {
+		got = append(got, event.Type+" "+event.Movie.Href)
+	}
+	want := []string{"metadata_changed /f1", "movie_added /superman", "movie_removed /jaws"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("DiffListings() = %v, want %v", got, want)
+	}
+
+	if changed := events[0].Changed; !reflect.DeepEqual(changed, []string{"title", "languages"}) {
+		t.Fatalf("Changed = %v, want title and languages without buzz", changed)
+	}
+}
+
+func TestProjectListingsRebuildsListingsFromEvents(t *testing.T) {
+	t.Parallel()
+
+	first := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	scrapes := [][]Movie{
+		{{Title: "F1", Href: "/f1"}, {Title: "Jaws", Href: "/jaws"}},
+		{{Title: "F1: The Movie", Href: "/f1"}, {Title: "Superman", Href: "/superman"}},
+	}
+
+	var (
+		events   []ListingEvent
+		previous []Movie
+	)
+	for i, scraped := range scrapes {
+		events = append(events, DiffListings("cuttack", previous, scraped, first.Add(time.Duration(i)*time.Hour))...)
+		previous = scraped
+	}
+
+	listings := ProjectListings(events)
+	if got := listings["cuttack"]; !reflect.DeepEqual(got, scrapes[1]) {
+		t.Fatalf("ProjectListings() = %+v, want the latest scrape %+v", got, scrapes[1])
+	}
+}
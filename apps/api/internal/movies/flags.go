@@ -0,0 +1,30 @@
+package movies
+
+import "context"
+
+// Feature flags the service consults through ServiceOptions.Flags.
+const (
+	// FlagLiveScraping scrapes cities again once their movies expire, which
+	// is the default. Cities it is off for are served their saved movies, of
+	// any age, as on a read-only replica. The percentage is of cities.
+	FlagLiveScraping = "live_scraping"
+
+	// FlagSearchIndex matches searches with SearchBackendIndex rather than
+	// the configured backend. The percentage is of distinct queries, so a
+	// query is always matched the same way.
+	FlagSearchIndex = "search_index"
+)
+
+func (s *MovieService) flagEnabled(ctx context.Context, name, city, unit string, fallback bool) bool {
+	if s.flags == nil {
+		return fallback
+	}
+
+	return s.flags.Enabled(ctx, name, city, unit, fallback)
+}
+
+// liveScraping reports whether the city is scraped once its movies expire,
+// rather than served the movies last saved.
+func (s *MovieService) liveScraping(ctx context.Context, city string) bool {
+	return !s.ScrapingPaused() && s.flagEnabled(ctx, FlagLiveScraping, city, city, true)
+}
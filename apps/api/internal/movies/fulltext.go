@@ -0,0 +1,326 @@
+package movies
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/char/asciifolding"
+	"github.com/blevesearch/bleve/v2/analysis/lang/en"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/index/scorch"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+	index "github.com/blevesearch/bleve_index_api"
+)
+
+// Search backends selectable through ServiceOptions.SearchBackend.
+const (
+	SearchBackendFuzzy = "fuzzy"
+	SearchBackendIndex = "index"
+)
+
+// movieAnalyzer folds accents, drops English stop words and possessives, and
+// stems what is left, so "Pokémon's" and "pokemon" index as the same term.
+const movieAnalyzer = "movie"
+
+// maxFacetTerms bounds the values counted per facet.
+const maxFacetTerms = 100
+
+var facetFields = []string{FieldGenres, FieldLanguages}
+
+// facetField names the unanalyzed copy of a faceted field.
+func facetField(field string) string {
+	return field + "_facet"
+}
+
+type cityTextIndex struct {
+	snapshot uint64
+	movies   []Movie
+	index    bleve.Index
+	analyzer analysis.Analyzer
+
+	// searches counts the searches using the index. A replaced index is
+	// closed once the last of them finishes.
+	searches int
+	replaced bool
+}
+
+// textIndexes keeps one in-memory Bleve index per city, rebuilt whenever the
+// city's movie list changes so it never drifts from the database.
+type textIndexes struct {
+	mu     sync.Mutex
+	cities map[string]*cityTextIndex
+}
+
+func newTextIndexes() *textIndexes {
+	return &textIndexes{cities: make(map[string]*cityTextIndex)}
+}
+
+func newIndexMapping() (*mapping.IndexMappingImpl, error) {
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.ScoringModel = index.BM25Scoring
+
+	err := indexMapping.AddCustomAnalyzer(movieAnalyzer, map[string]any{
+		"type":          custom.Name,
+		"char_filters":  []string{asciifolding.Name},
+		"tokenizer":     unicode.Name,
+		"token_filters": []string{en.PossessiveName, lowercase.Name, en.StopName, en.SnowballStemmerName},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	document := bleve.NewDocumentStaticMapping()
+	for field := range fieldWeights {
+		text := bleve.NewTextFieldMapping()
+		text.Analyzer = movieAnalyzer
+		text.Store = false
+		text.IncludeTermVectors = false
+		document.AddFieldMappingsAt(field, text)
+	}
+
+	for _, field := range facetFields {
+		facet := bleve.NewKeywordFieldMapping()
+		facet.Analyzer = keyword.Name
+		facet.Store = false
+		document.AddFieldMappingsAt(facetField(field), facet)
+	}
+
+	indexMapping.DefaultMapping = document
+
+	return indexMapping, nil
+}
+
+// search matches query against the index of the city's movies, building the
+// index first if the movies changed.
+func (t *textIndexes) search(city string, list []Movie, query string, opts SearchOptions) ([]Movie, map[string]map[string]int, error) {
+	entry, err := t.acquire(city, list)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer t.release(entry)
+
+	return entry.search(query, opts)
+}
+
+// acquire returns the city's index for list, building it outside the lock so
+// searches in other cities are not held up. When searches race to build the
+// same index, the first to finish is kept and the others are closed.
+func (t *textIndexes) acquire(city string, list []Movie) (*cityTextIndex, error) {
+	snapshot := snapshotKey(list)
+
+	t.mu.Lock()
+	if entry, ok := t.cities[city]; ok && entry.snapshot == snapshot {
+		entry.searches++
+		t.mu.Unlock()
+		return entry, nil
+	}
+	t.mu.Unlock()
+
+	built, err := buildTextIndex(snapshot, list)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.cities[city]
+	if ok && entry.snapshot == snapshot {
+		built.index.Close()
+	} else {
+		if ok {
+			entry.replaced = true
+			t.closeUnused(entry)
+		}
+
+		entry = built
+		t.cities[city] = entry
+	}
+
+	entry.searches++
+
+	return entry, nil
+}
+
+func (t *textIndexes) release(entry *cityTextIndex) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry.searches--
+	t.closeUnused(entry)
+}
+
+func (t *textIndexes) closeUnused(entry *cityTextIndex) {
+	if entry.replaced && entry.searches == 0 {
+		entry.index.Close()
+	}
+}
+
+func buildTextIndex(snapshot uint64, list []Movie) (*cityTextIndex, error) {
+	indexMapping, err := newIndexMapping()
+	if err != nil {
+		return nil, fmt.Errorf("map search index: %w", err)
+	}
+
+	// Scorch keeps the field lengths BM25 needs; an empty path keeps the
+	// index in memory.
+	idx, err := bleve.NewUsing("", indexMapping, scorch.Name, scorch.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create search index: %w", err)
+	}
+
+	batch := idx.NewBatch()
+	for i, movie := range list {
+		err := batch.Index(strconv.Itoa(i), map[string]any{
+			FieldTitle:                 movie.Title,
+			FieldCast:                  movie.Cast,
+			FieldGenres:                movie.Genres,
+			FieldLanguages:             movie.Languages,
+			facetField(FieldGenres):    movie.Genres,
+			facetField(FieldLanguages): movie.Languages,
+		})
+		if err != nil {
+			idx.Close()
+			return nil, fmt.Errorf("index %q: %w", movie.Title, err)
+		}
+	}
+
+	if err := idx.Batch(batch); err != nil {
+		idx.Close()
+		return nil, fmt.Errorf("build search index: %w", err)
+	}
+
+	return &cityTextIndex{
+		snapshot: snapshot,
+		movies:   list,
+		index:    idx,
+		analyzer: indexMapping.AnalyzerNamed(movieAnalyzer),
+	}, nil
+}
+
+// search ranks movies by BM25 over the weighted fields, returning them with
+// Score set to a percentage of the best match's, plus genre and language facet
+// counts over the matches. The last query term also matches indexed terms it is a
+// prefix of, so partially typed queries still find results. Only movies
+// matching more than half of the query's terms are ranked, since scaling to
+// the best match would otherwise give a movie matching one word of a longer
+// query a full score.
+func (e *cityTextIndex) search(text string, opts SearchOptions) ([]Movie, map[string]map[string]int, error) {
+	fields := opts.Fields
+	if len(fields) == 0 {
+		fields = []string{FieldTitle}
+	}
+
+	tokens := e.analyzer.Analyze([]byte(text))
+	if len(tokens) == 0 {
+		return []Movie{}, map[string]map[string]int{}, nil
+	}
+
+	// Each term matches in any field, and the terms' clauses are
+	// combined so that Min counts matched terms rather than fields.
+	terms := make([]query.Query, 0, len(tokens))
+	for i, token := range tokens {
+		var clauses []query.Query
+		for _, field := range fields {
+			weight, ok := fieldWeights[field]
+			if !ok {
+				continue
+			}
+
+			boost := float64(weight) / 100
+			if i == len(tokens)-1 {
+				prefix := bleve.NewPrefixQuery(string(token.Term))
+				prefix.SetField(field)
+				prefix.SetBoost(boost)
+				clauses = append(clauses, prefix)
+				continue
+			}
+
+			term := bleve.NewTermQuery(string(token.Term))
+			term.SetField(field)
+			term.SetBoost(boost)
+			clauses = append(clauses, term)
+		}
+
+		terms = append(terms, bleve.NewDisjunctionQuery(clauses...))
+	}
+
+	matched := bleve.NewDisjunctionQuery(terms...)
+	matched.SetMin(float64(minMatchedTerms(len(terms))))
+
+	request := bleve.NewSearchRequestOptions(matched, len(e.movies), 0, false)
+	for _, field := range facetFields {
+		request.AddFacet(field, bleve.NewFacetRequest(facetField(field), maxFacetTerms))
+	}
+
+	found, err := e.index.Search(request)
+	if err != nil {
+		return nil, nil, fmt.Errorf("search index: %w", err)
+	}
+
+	type hit struct {
+		doc   int
+		score float64
+	}
+
+	hits := make([]hit, 0, len(found.Hits))
+	for _, match := range found.Hits {
+		doc, err := strconv.Atoi(match.ID)
+		if err != nil || doc < 0 || doc >= len(e.movies) {
+			return nil, nil, fmt.Errorf("search index returned unknown document %q", match.ID)
+		}
+
+		hits = append(hits, hit{doc: doc, score: match.Score})
+	}
+
+	slices.SortFunc(hits, func(a, b hit) int {
+		return cmp.Or(cmp.Compare(b.score, a.score), cmp.Compare(a.doc, b.doc))
+	})
+
+	result := make([]Movie, 0, len(hits))
+	for _, hit := range hits {
+		// BM25 scores depend on the query and the city's movies, so they
+		// are scaled to the best match's, like fuzzy scores to a perfect
+		// match.
+		score := int(math.Round(hit.score / hits[0].score * 100))
+		if score < opts.MinScore {
+			continue
+		}
+
+		movie := e.movies[hit.doc]
+		movie.Score = score
+		result = append(result, movie)
+	}
+
+	facets := make(map[string]map[string]int, len(facetFields))
+	for _, field := range facetFields {
+		facet, ok := found.Facets[field]
+		if !ok || facet.Terms == nil {
+			continue
+		}
+
+		counts := make(map[string]int)
+		for _, term := range facet.Terms.Terms() {
+			counts[term.Term] = term.Count
+		}
+		facets[field] = counts
+	}
+
+	return result, facets, nil
+}
+
+// minMatchedTerms is how many of a query's terms a movie must match to be
+// ranked: more than half of them.
+func minMatchedTerms(terms int) int {
+	return terms/2 + 1
+}
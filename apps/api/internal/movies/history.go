@@ -0,0 +1,65 @@
+package movies
+
+import "sync"
+
+// maxScrapeHistory is how many recent scrape runs are kept in memory.
+const maxScrapeHistory = 50
+
+type scrapeHistory struct {
+	mu      sync.Mutex
+	runs    []ScrapeRun
+	streaks map[string]failureStreak
+}
+
+type failureStreak struct {
+	count     int
+	lastError string
+}
+
+func (h *scrapeHistory) record(run ScrapeRun) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.runs = append(h.runs, run)
+	if len(h.runs) > maxScrapeHistory {
+		h.runs = h.runs[len(h.runs)-maxScrapeHistory:]
+	}
+
+	if h.streaks == nil {
+		h.streaks = make(map[string]failureStreak)
+	}
+
+	if run.Error == "" {
+		delete(h.streaks, run.City)
+		return
+	}
+
+	streak := h.streaks[run.City]
+	h.streaks[run.City] = failureStreak{count: streak.count + 1, lastError: run.Error}
+}
+
+func (h *scrapeHistory) failureStreaks() map[string]failureStreak {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	streaks := make(map[string]failureStreak, len(h.streaks))
+	for city, streak := range h.streaks {
+		streaks[city] = streak
+	}
+
+	return streaks
+}
+
+// RecentScrapes returns the scrape runs since startup, newest first, up to
+// maxScrapeHistory.
+func (s *MovieService) RecentScrapes() []ScrapeRun {
+	s.scrapeHistory.mu.Lock()
+	defer s.scrapeHistory.mu.Unlock()
+
+	runs := make([]ScrapeRun, 0, len(s.scrapeHistory.runs))
+	for i := len(s.scrapeHistory.runs) - 1; i >= 0; i-- {
+		runs = append(runs, s.scrapeHistory.runs[i])
+	}
+
+	return runs
+}
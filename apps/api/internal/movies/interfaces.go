@@ -9,13 +9,152 @@ type Repository interface {
 	ListFresh(ctx context.Context, city string, since time.Time) ([]Movie, error)
 	HasFreshScrape(ctx context.Context, city string, since time.Time) (bool, error)
 	ReplaceCity(ctx context.Context, city string, movies []Movie, scrapedAt time.Time) error
+	ListScrapes(ctx context.Context) ([]CityScrape, error)
+
+	// LastScrape returns when the city's movies were last saved, and whether
+	// they ever were.
+	LastScrape(ctx context.Context, city string) (time.Time, bool, error)
+	DeleteScrapedBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// ListSightings returns up to limit movies first seen in the city after
+	// since, newest first. A limit of zero returns them all.
+	ListSightings(ctx context.Context, city string, since time.Time, limit int) ([]Sighting, error)
+
+	// ListChanges compares the city's current listings with those it had at
+	// since.
+	ListChanges(ctx context.Context, city string, since time.Time) (Changes, error)
+
+	// ListEvents returns up to limit of the city's listing events after the
+	// event with ID after, oldest first. An empty city lists every city's
+	// events.
+	ListEvents(ctx context.Context, city string, after int64, limit int) ([]ListingEvent, error)
+
+	// ListEventsBefore returns the city's listing events that occurred before
+	// the given time, oldest first.
+	ListEventsBefore(ctx context.Context, city string, before time.Time) ([]ListingEvent, error)
+
+	// RandomMovie returns one of the city's saved movies matching the filter,
+	// chosen at random, and whether any matched.
+	RandomMovie(ctx context.Context, city string, filter MovieFilter) (Movie, bool, error)
+
+	// ListListings returns every city's saved movies, ordered by city.
+	ListListings(ctx context.Context) ([]Listing, error)
+}
+
+type SearchLog interface {
+	RecordSearch(ctx context.Context, event SearchEvent) error
+	SummarizeSearches(ctx context.Context, city string, since time.Time, limit int) (SearchSummary, error)
+
+	// ExportSearches calls fn with each search since the given time, oldest
+	// first, as it is read, stopping at the first error fn returns.
+	ExportSearches(ctx context.Context, city string, since time.Time, fn func(SearchEvent) error) error
+	RecordClick(ctx context.Context, event ClickEvent) error
+
+	// ListDailyClicks counts the city's recorded clicks per movie and UTC
+	// day, from the UTC day of since onwards.
+	ListDailyClicks(ctx context.Context, city string, since time.Time) ([]DailyClicks, error)
+	Trending(ctx context.Context, city string, since time.Time, limit int) (Trending, error)
+
+	// DeleteSearchesBefore deletes the searches and clicks recorded before
+	// the given time, returning how many searches were deleted.
+	DeleteSearchesBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type RequestLog interface {
+	RecordRequest(ctx context.Context, event RequestEvent) error
+
+	// SummarizeTraffic returns the requests since the given time per city and
+	// UTC day, newest day first and then busiest city first.
+	SummarizeTraffic(ctx context.Context, since time.Time) ([]TrafficDay, error)
+
+	// DeleteRequestsBefore deletes the requests recorded before the given
+	// time, returning how many were deleted.
+	DeleteRequestsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type AliasStore interface {
+	ListAliases(ctx context.Context) ([]Alias, error)
+	UpsertAlias(ctx context.Context, alias Alias) error
+	DeleteAlias(ctx context.Context, alias string) (bool, error)
+}
+
+type CitySettingsStore interface {
+	ListCitySettings(ctx context.Context) ([]CitySettings, error)
+	UpsertCitySettings(ctx context.Context, settings CitySettings) error
+}
+
+// QuarantineStore holds at most one quarantined scrape per city.
+type QuarantineStore interface {
+	// SaveQuarantined replaces the scrape held for the city, if any.
+	SaveQuarantined(ctx context.Context, scrape QuarantinedScrape) error
+	ListQuarantined(ctx context.Context) ([]QuarantinedScrape, error)
+	GetQuarantined(ctx context.Context, city string) (QuarantinedScrape, bool, error)
+	DeleteQuarantined(ctx context.Context, city string) (bool, error)
+}
+
+type TitleVariantStore interface {
+	ListTitleVariants(ctx context.Context) ([]TitleVariant, error)
+	UpsertTitleVariant(ctx context.Context, variant TitleVariant) error
+	DeleteTitleVariant(ctx context.Context, movieID, language string) (bool, error)
+}
+
+// RatingsProvider looks up critic scores for a title, returning nil when the
+// title is not found.
+type RatingsProvider interface {
+	LookupRatings(ctx context.Context, title string, year int) (*Ratings, error)
+}
+
+// RatingsStore caches looked-up ratings, including titles that were not
+// found, which are stored as nil ratings.
+type RatingsStore interface {
+	// GetRatings returns the ratings cached for the title since the given
+	// time, and whether any were cached.
+	GetRatings(ctx context.Context, title string, year int, since time.Time) (*Ratings, bool, error)
+	SaveRatings(ctx context.Context, title string, year int, ratings *Ratings, fetchedAt time.Time) error
+}
+
+// StreamingProvider looks up where a title can be streamed, returning nil when
+// the title is not found.
+type StreamingProvider interface {
+	LookupStreaming(ctx context.Context, title string, year int) (*Streaming, error)
+}
+
+// TrailerProvider looks up a title's trailer, returning nil when the title or
+// its trailer is not found.
+type TrailerProvider interface {
+	LookupTrailer(ctx context.Context, title string, year int) (*Trailer, error)
+}
+
+// Flags decides whether a feature flag is on for a city and a unit of
+// traffic, such as a search query. Flags that are not defined are fallback.
+type Flags interface {
+	Enabled(ctx context.Context, name, city, unit string, fallback bool) bool
+}
+
+// ChangeListener is told about movies a scrape found that were not in the
+// city's previous listings, as the outbox delivers its TopicMoviesAdded
+// messages. Returning an error makes the outbox retry the message later, so a
+// listener may be told about the same movies more than once.
+type ChangeListener interface {
+	MoviesAdded(ctx context.Context, city string, added []Movie) error
+}
+
+// RefreshListener is told about every scrape that was saved, with the city's
+// full listings. It is called synchronously and must not block.
+type RefreshListener interface {
+	MoviesRefreshed(ctx context.Context, city string, current []Movie)
 }
 
 type Scraper interface {
 	Scrape(ctx context.Context, city string) ([]Movie, error)
 }
 
+// Service is what the server and its scheduled jobs use of the movie service
+// directly. Handlers and other consumers declare the narrower interfaces they
+// need, which MovieService satisfies.
 type Service interface {
-	Load(ctx context.Context, city string) ([]Movie, bool, error)
 	Preload(ctx context.Context, cities []string) error
+	Cleanup(ctx context.Context, before time.Time) error
+	AddRefreshListener(listener RefreshListener)
+	Shutdown(ctx context.Context) error
 }
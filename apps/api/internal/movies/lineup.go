@@ -0,0 +1,113 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Run is when a movie was listed in a city.
+type Run struct {
+	// Movie is the movie as it was last listed.
+	Movie Movie
+
+	// From is when a scrape first found the movie.
+	From time.Time
+
+	// To is when a scrape last found the movie gone, and zero while it is
+	// still listed. A movie that returns, such as for a re-release, is
+	// listed again until it is next found gone.
+	To time.Time
+}
+
+// Showing reports whether the movie is still listed.
+func (r Run) Showing() bool {
+	return r.To.IsZero()
+}
+
+// ListingsOn replays one city's events, oldest first, into the movies listed
+// at any time from start until end: those listed at start and those added
+// before end, in the order they were first added. Movies removed before end
+// are kept, since they were still showing that day.
+func ListingsOn(events []ListingEvent, start, end time.Time) []Movie {
+	replayed := make([]ListingEvent, 0, len(events))
+	for _, event := range events {
+		switch {
+		case event.OccurredAt.Before(start):
+			replayed = append(replayed, event)
+		case event.OccurredAt.Before(end) && event.Type != EventMovieRemoved:
+			replayed = append(replayed, event)
+		}
+	}
+
+	var listed []Movie
+	for _, list := range ProjectListings(replayed) {
+		listed = append(listed, list...)
+	}
+
+	return listed
+}
+
+// RunOf returns when the movie with the given ID was listed, from one city's
+// events, oldest first, and whether it ever was.
+func RunOf(events []ListingEvent, id string) (Run, bool) {
+	var (
+		run   Run
+		found bool
+	)
+
+	for _, event := range events {
+		if event.Movie.ID() != id {
+			continue
+		}
+
+		switch event.Type {
+		case EventMovieAdded:
+			if !found {
+				run.From = event.OccurredAt
+				found = true
+			}
+			run.Movie = event.Movie
+			run.To = time.Time{}
+		case EventMetadataChanged:
+			run.Movie = event.Movie
+		case EventMovieRemoved:
+			if found {
+				run.To = event.OccurredAt
+			}
+		}
+	}
+
+	return run, found
+}
+
+// History returns the movies listed in the city at any time from start until
+// end.
+func (s *MovieService) History(ctx context.Context, city string, start, end time.Time) ([]Movie, error) {
+	events, err := s.repo.ListEventsBefore(ctx, city, end)
+	if err != nil {
+		return nil, fmt.Errorf("query listing events: %w", err)
+	}
+
+	return ListingsOn(events, start, end), nil
+}
+
+// MovieRun returns when the movie with the given ID was listed in the city.
+func (s *MovieService) MovieRun(ctx context.Context, city, id string) (Run, error) {
+	id, ok := ParseID(id)
+	if !ok {
+		return Run{}, ErrMovieNeverListed
+	}
+
+	events, err := s.repo.ListEventsBefore(ctx, city, time.Now())
+	if err != nil {
+		return Run{}, fmt.Errorf("query listing events: %w", err)
+	}
+
+	run, ok := RunOf(events, id)
+	if !ok {
+		return Run{}, ErrMovieNeverListed
+	}
+
+	return run, nil
+}
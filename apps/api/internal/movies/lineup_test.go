@@ -0,0 +1,87 @@
+package movies
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// lineupEvents lists F1 from 1 July, Jaws on 3 July only, and Superman from
+// 3 July, leaving on 5 July and returning on 8 July.
+func lineupEvents() []ListingEvent {
+	day := func(d, hour int) time.Time { return time.Date(2025, 7, d, hour, 0, 0, 0, time.UTC) }
+
+	return []ListingEvent{
+		{City: "cuttack", Type: EventMovieAdded, Movie: Movie{Title: "F1", Href: "/f1"}, OccurredAt: day(1, 9)},
+		{City: "cuttack", Type: EventMovieAdded, Movie: Movie{Title: "Jaws", Href: "/jaws"}, OccurredAt: day(3, 9)},
+		{City: "cuttack", Type: EventMovieAdded, Movie: Movie{Title: "Superman", Href: "https://in.bookmyshow.com/movies/cuttack/superman/ET00414210"}, OccurredAt: day(3, 9)},
+		{City: "cuttack", Type: EventMovieRemoved, Movie: Movie{Title: "Jaws", Href: "/jaws"}, OccurredAt: day(3, 21)},
+		{City: "cuttack", Type: EventMetadataChanged, Movie: Movie{Title: "F1: The Movie", Href: "/f1"}, OccurredAt: day(4, 9)},
+		{City: "cuttack", Type: EventMovieRemoved, Movie: Movie{Title: "Superman", Href: "https://in.bookmyshow.com/movies/cuttack/superman/ET00414210"}, OccurredAt: day(5, 9)},
+		{City: "cuttack", Type: EventMovieAdded, Movie: Movie{Title: "Superman", Href: "https://in.bookmyshow.com/movies/cuttack/superman/ET00414210", Rerelease: true}, OccurredAt: day(8, 9)},
+	}
+}
+
+func TestListingsOnKeepsMoviesShowingAnyTimeThatDay(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		date string
+		want []string
+	}{
+		{date: "2025-06-30", want: nil},
+		{date: "2025-07-01", want: []string{"F1"}},
+		{date: "2025-07-03", want: []string{"F1", "Jaws", "Superman"}},
+		{date: "2025-07-04", want: []string{"F1: The Movie", "Superman"}},
+		{date: "2025-07-06", want: []string{"F1: The Movie"}},
+	}
+
+	for _, tt := range tests {
+		start, _ := time.Parse(time.DateOnly, tt.date)
+
+		var titles []string
+		for _, movie := range ListingsOn(lineupEvents(), start, start.AddDate(0, 0, 1)) {
+			titles = append(titles, movie.Title)
+		}
+
+		if !reflect.DeepEqual(titles, tt.want) {
+			t.Errorf("ListingsOn(%s) = %v, want %v", tt.date, titles, tt.want)
+		}
+	}
+}
+
+func TestRunOfSpansFirstListingToLastRemoval(t *testing.T) {
+	t.Parallel()
+
+	events := lineupEvents()
+
+	run, ok := RunOf(events[:6], "ET00414210")
+	if !ok || run.Showing() || !run.From.Equal(events[2].OccurredAt) || !run.To.Equal(events[5].OccurredAt) {
+		t.Fatalf("RunOf() = %+v, %t, want listed from 3 July until 5 July", run, ok)
+	}
+
+	run, ok = RunOf(events, "ET00414210")
+	if !ok || !run.Showing() || !run.From.Equal(events[2].OccurredAt) || !run.Movie.Rerelease {
+		t.Fatalf("RunOf() = %+v, %t, want still showing since the re-release", run, ok)
+	}
+
+	if _, ok := RunOf(events, "ET00000001"); ok {
+		t.Fatal("RunOf() found a movie that was never listed")
+	}
+}
+
+func TestMovieRunReportsMoviesNeverListed(t *testing.T) {
+	t.Parallel()
+
+	service := NewMovieService(&fakeRepository{events: lineupEvents()}, &fakeScraper{}, ServiceOptions{}, testLogger())
+
+	run, err := service.MovieRun(t.Context(), "cuttack", (Movie{Href: "/f1"}).ID())
+	if err != nil || run.Movie.Title != "F1: The Movie" {
+		t.Fatalf("MovieRun() = %+v, %v, want F1 as last listed", run, err)
+	}
+
+	if _, err := service.MovieRun(t.Context(), "cuttack", "ET00000001"); !errors.Is(err, ErrMovieNeverListed) {
+		t.Fatalf("MovieRun() error = %v, want ErrMovieNeverListed", err)
+	}
+}
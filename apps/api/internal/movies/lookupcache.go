@@ -0,0 +1,59 @@
+package movies
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxCachedLookups bounds each lookup cache; expired entries are dropped once
+// it grows past this size.
+const maxCachedLookups = 1024
+
+// lookupCache keeps the results of external lookups in memory, keyed by title
+// and year, so each title is looked up at most once per TTL.
+type lookupCache[V any] struct {
+	mu      sync.Mutex
+	entries map[string]lookupEntry[V]
+}
+
+type lookupEntry[V any] struct {
+	value     V
+	fetchedAt time.Time
+}
+
+func newLookupCache[V any]() *lookupCache[V] {
+	return &lookupCache[V]{entries: make(map[string]lookupEntry[V])}
+}
+
+func (c *lookupCache[V]) get(key string, since time.Time) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || !entry.fetchedAt.After(since) {
+		var zero V
+		return zero, false
+	}
+
+	return entry.value, true
+}
+
+func (c *lookupCache[V]) set(key string, value V, fetchedAt, expiredBefore time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxCachedLookups {
+		for key, entry := range c.entries {
+			if !entry.fetchedAt.After(expiredBefore) {
+				delete(c.entries, key)
+			}
+		}
+	}
+
+	c.entries[key] = lookupEntry[V]{value: value, fetchedAt: fetchedAt}
+}
+
+func lookupKey(movie Movie) string {
+	return movie.Title + "|" + strconv.Itoa(movie.Year)
+}
@@ -0,0 +1,109 @@
+package movies
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxMemoizedQueries bounds how many distinct queries are remembered per city
+// so a stream of unique queries cannot grow the memo without limit.
+const maxMemoizedQueries = 1024
+
+type searchMemo struct {
+	mu     sync.Mutex
+	cities map[string]*citySearchMemo
+}
+
+type citySearchMemo struct {
+	snapshot uint64
+	index    *searchIndex
+	results  map[string][]Movie
+}
+
+func newSearchMemo() *searchMemo {
+	return &searchMemo{cities: make(map[string]*citySearchMemo)}
+}
+
+// search returns the fuzzy matches for query within list, reusing a previous
+// result as long as the city's movie list has not changed since it was
+// computed. Queries that miss still share the snapshot's prepared index.
+func (m *searchMemo) search(city string, list []Movie, query string, opts SearchOptions) []Movie {
+	snapshot := snapshotKey(list)
+	key := memoKey(query, opts)
+
+	m.mu.Lock()
+	entry, ok := m.cities[city]
+	if !ok || entry.snapshot != snapshot {
+		entry = &citySearchMemo{
+			snapshot: snapshot,
+			index:    newSearchIndex(list),
+			results:  make(map[string][]Movie),
+		}
+		m.cities[city] = entry
+	}
+
+	if result, ok := entry.results[key]; ok {
+		m.mu.Unlock()
+		return append([]Movie(nil), result...)
+	}
+	m.mu.Unlock()
+
+	result := entry.index.search(query, opts)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The city may have been refreshed while we were searching; only store the
+	// result if it still belongs to the current snapshot.
+	if current := m.cities[city]; current == entry {
+		if len(entry.results) >= maxMemoizedQueries {
+			entry.results = make(map[string][]Movie)
+		}
+
+		entry.results[key] = append([]Movie(nil), result...)
+	}
+
+	return result
+}
+
+func (m *searchMemo) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cities = make(map[string]*citySearchMemo)
+}
+
+func (m *searchMemo) invalidate(city string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.cities, city)
+}
+
+func memoKey(query string, opts SearchOptions) string {
+	return fmt.Sprintf("%d\x00%d\x00%s\x00%s", opts.MinScore, opts.Fuzziness, strings.Join(opts.Fields, ","), query)
+}
+
+// snapshotKey fingerprints every searchable attribute of a city's movies, so
+// any change to the list invalidates results derived from it.
+func snapshotKey(list []Movie) uint64 {
+	hash := fnv.New64a()
+	write := func(value string) {
+		_, _ = hash.Write([]byte(value))
+		_, _ = hash.Write([]byte{0})
+	}
+
+	for _, movie := range list {
+		write(movie.Title)
+		write(movie.Href)
+		write(strconv.Itoa(movie.Year))
+		write(strings.Join(movie.Genres, "\x1f"))
+		write(strings.Join(movie.Languages, "\x1f"))
+		write(strings.Join(movie.Cast, "\x1f"))
+	}
+
+	return hash.Sum64()
+}
@@ -0,0 +1,141 @@
+package movies
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// punctuationFolds maps typographic punctuation onto the plain forms users
+// type, and drops separators that carry no meaning for matching, so
+// "Mission: Impossible – Dead Reckoning" and "mission impossible dead
+// reckoning" compare equal.
+var punctuationFolds = map[rune]string{
+	'\u2018': "",
+	'\u2019': "",
+	'\u201a': "",
+	'\u201b': "",
+	'\u2032': "",
+	'\'':     "",
+	'\u201c': "",
+	'\u201d': "",
+	'\u201e': "",
+	'"':      "",
+	'\u2010': " ",
+	'\u2011': " ",
+	'\u2012': " ",
+	'\u2013': " ",
+	'\u2014': " ",
+	'\u2015': " ",
+	'\u2212': " ",
+	'-':      " ",
+	':':      " ",
+	';':      " ",
+	',':      " ",
+	'!':      " ",
+	'?':      " ",
+	'\u2026': " ",
+	'&':      " and ",
+}
+
+// NormalizeQuery cleans up text copied from web pages: HTML entities are
+// decoded, exotic whitespace becomes plain spaces, invisible characters are
+// dropped, and runs of whitespace collapse to one space.
+func NormalizeQuery(query string) string {
+	htmlDecoded := html.UnescapeString(query)
+
+	// Convert non-breaking and other Unicode spaces to ASCII spaces so fuzzy
+	// matching lines up with titles stored in the database.
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case r == '\u200b' || r == '\u200c' || r == '\u200d' || r == '\ufeff':
+			return -1
+		case unicode.IsSpace(r):
+			return ' '
+		default:
+			return r
+		}
+	}, htmlDecoded)
+
+	return strings.Join(strings.Fields(cleaned), " ")
+}
+
+// foldForMatch reduces a title or query to the form compared during search:
+// diacritics are stripped, punctuation is folded, and "&" reads as "and".
+// Case is preserved so the fuzzy matcher can still reward word boundaries.
+func foldForMatch(text string) string {
+	folded, _ := foldWithOffsets(NormalizeQuery(text))
+	return folded
+}
+
+// foldWithOffsets folds text like foldForMatch, without decoding HTML, and
+// also returns, for every byte of the folded string, the byte offset of the
+// rune in text it was produced from. The offsets let match positions found in
+// the folded form be reported against the original title.
+func foldWithOffsets(text string) (string, []int) {
+	var (
+		folded     strings.Builder
+		offsets    []int
+		pendingGap bool
+	)
+
+	emit := func(piece string, offset int) {
+		for _, r := range piece {
+			if r == ' ' {
+				pendingGap = folded.Len() > 0
+				continue
+			}
+
+			if pendingGap {
+				folded.WriteByte(' ')
+				offsets = append(offsets, offset)
+				pendingGap = false
+			}
+
+			before := folded.Len()
+			folded.WriteRune(r)
+			for range folded.Len() - before {
+				offsets = append(offsets, offset)
+			}
+		}
+	}
+
+	for offset, r := range text {
+		switch {
+		case r == '\u200b' || r == '\u200c' || r == '\u200d' || r == '\ufeff':
+			continue
+		case unicode.IsSpace(r):
+			emit(" ", offset)
+		case unicode.Is(unicode.Mn, r):
+			continue
+		default:
+			if replacement, ok := punctuationFolds[r]; ok {
+				emit(replacement, offset)
+				continue
+			}
+
+			emit(stripDiacritics(r), offset)
+		}
+	}
+
+	return folded.String(), offsets
+}
+
+func stripDiacritics(r rune) string {
+	if r < utf8.RuneSelf {
+		return string(r)
+	}
+
+	decomposed := norm.NFD.String(string(r))
+
+	return strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+
+		return r
+	}, decomposed)
+}
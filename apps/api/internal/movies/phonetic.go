@@ -0,0 +1,111 @@
+package movies
+
+import (
+	"strings"
+	"unicode"
+)
+
+// wordAliases maps alternate spellings and abbreviations that phonetic rules
+// cannot reconcile onto a single canonical word.
+var wordAliases = map[string]string{
+	"pt":      "part",
+	"chap":    "chapter",
+	"ch":      "chapter",
+	"vol":     "volume",
+	"ii":      "2",
+	"iii":     "3",
+	"iv":      "4",
+	"aur":     "or",
+	"bhaiyya": "bhaiya",
+	"mr":      "mister",
+	"dr":      "doctor",
+}
+
+// phoneticReplacements folds the spelling variations that show up when Hindi,
+// Telugu, and Odia titles are romanized by different people: doubled vowels,
+// aspirated consonants, and interchangeable letters. Order matters, since
+// vowel digraphs are folded before consonant ones.
+var phoneticReplacements = strings.NewReplacer(
+	"aa", "a",
+	"ee", "i",
+	"ii", "i",
+	"oo", "u",
+	"uu", "u",
+	"ou", "u",
+	"ai", "e",
+	"ei", "e",
+	"ph", "f",
+	"bh", "b",
+	"dh", "d",
+	"gh", "g",
+	"jh", "j",
+	"kh", "k",
+	"th", "t",
+	"sh", "s",
+	"ch", "c",
+	"ck", "k",
+	"w", "v",
+	"z", "j",
+	"q", "k",
+	"x", "ks",
+	"y", "i",
+)
+
+// wordJoiners are dropped from words before phonetic folding.
+var wordJoiners = strings.NewReplacer(".", "", "'", "")
+
+// phoneticKey reduces text to a spelling-insensitive key, word by word, so
+// "Pushpaa 2" and "Pushpa 2" or "Bhool Bhulaiyaa" and "Bhul Bhulaiya" compare
+// equal.
+func phoneticKey(text string) string {
+	// Drop dots and apostrophes inside words so "A.D." and "AD" agree.
+	text = wordJoiners.Replace(strings.ToLower(text))
+
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	keys := make([]string, 0, len(words))
+	for _, word := range words {
+		if key := phoneticWord(word); key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	return strings.Join(keys, " ")
+}
+
+func phoneticWord(word string) string {
+	if alias, ok := wordAliases[word]; ok {
+		word = alias
+	}
+
+	word = phoneticReplacements.Replace(word)
+
+	var key strings.Builder
+	var previous rune
+	for i, r := range word {
+		// A leftover 'h' after the digraph pass is almost always a silent
+		// aspiration marker, except at the start of a word.
+		if r == 'h' && i > 0 {
+			continue
+		}
+
+		if r == previous {
+			continue
+		}
+
+		key.WriteRune(r)
+		previous = r
+	}
+
+	result := key.String()
+
+	// Romanized Hindi often keeps or drops the inherent trailing vowel
+	// ("Pushp" vs "Pushpa"), so ignore it on longer words.
+	if len(result) > 3 && strings.HasSuffix(result, "a") {
+		result = strings.TrimSuffix(result, "a")
+	}
+
+	return result
+}
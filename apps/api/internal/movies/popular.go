@@ -0,0 +1,90 @@
+package movies
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// DailyClicks counts the clicks through to one movie's booking page in a
+// city on one UTC day.
+type DailyClicks struct {
+	Movie  Movie
+	Date   string
+	Clicks int64
+}
+
+// PopularMovie is a movie's clicks through to its booking page over a period,
+// in total and per UTC day, newest day first.
+type PopularMovie struct {
+	Movie  Movie
+	Clicks int64
+	Days   []DayClicks
+}
+
+type DayClicks struct {
+	Date   string
+	Clicks int64
+}
+
+// RankPopular totals each movie's daily clicks and returns the limit most
+// clicked movies, most clicked first. A movie takes the details it was last
+// clicked with, and links that changed within a day are counted together.
+func RankPopular(daily []DailyClicks, limit int) []PopularMovie {
+	// Going newest day first, a movie's first count has its latest details.
+	daily = slices.Clone(daily)
+	slices.SortStableFunc(daily, func(a, b DailyClicks) int {
+		return cmp.Compare(b.Date, a.Date)
+	})
+
+	byID := make(map[string]int)
+	popular := []PopularMovie{}
+
+	for _, day := range daily {
+		id := day.Movie.ID()
+
+		index, ok := byID[id]
+		if !ok {
+			index = len(popular)
+			byID[id] = index
+			popular = append(popular, PopularMovie{Movie: day.Movie})
+		}
+
+		movie := &popular[index]
+		movie.Clicks += day.Clicks
+
+		if last := len(movie.Days) - 1; last >= 0 && movie.Days[last].Date == day.Date {
+			movie.Days[last].Clicks += day.Clicks
+			continue
+		}
+
+		movie.Days = append(movie.Days, DayClicks{Date: day.Date, Clicks: day.Clicks})
+	}
+
+	slices.SortStableFunc(popular, func(a, b PopularMovie) int {
+		return cmp.Or(cmp.Compare(b.Clicks, a.Clicks), cmp.Compare(a.Movie.Title, b.Movie.Title))
+	})
+
+	if len(popular) > limit {
+		popular = popular[:limit]
+	}
+
+	return popular
+}
+
+// PopularMovies returns the limit movies in the city clicked through to most
+// often from the UTC day of since onwards, counted from the recorded clicks.
+func (s *MovieService) PopularMovies(ctx context.Context, city string, since time.Time, limit int) ([]PopularMovie, error) {
+	if s.searchLog == nil {
+		return nil, ErrSearchLogDisabled
+	}
+
+	daily, err := s.searchLog.ListDailyClicks(ctx, city, since)
+	if err != nil {
+		return nil, fmt.Errorf("query click counts: %w", err)
+	}
+
+	return RankPopular(daily, limit), nil
+}
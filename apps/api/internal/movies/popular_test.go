@@ -0,0 +1,36 @@
+package movies
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRankPopularTotalsDaysPerMovie(t *testing.T) {
+	t.Parallel()
+
+	f1 := Movie{Title: "F1", Href: "/cuttack/f1/ET00403839"}
+	renamed := Movie{Title: "F1: The Movie", Href: "/cuttack/f1-the-movie/ET00403839"}
+	jaws := Movie{Title: "Jaws", Href: "/cuttack/jaws/ET00000457"}
+	daskalia := Movie{Title: "Daskalia", Href: "/cuttack/daskalia"}
+
+	popular := RankPopular([]DailyClicks{
+		{Movie: f1, Date: "2025-07-01", Clicks: 2},
+		{Movie: jaws, Date: "2025-07-02", Clicks: 4},
+		{Movie: renamed, Date: "2025-07-03", Clicks: 2},
+		{Movie: f1, Date: "2025-07-03", Clicks: 1},
+		{Movie: daskalia, Date: "2025-07-03", Clicks: 1},
+	}, 2)
+
+	var titles []string
+	for _, movie := range popular {
+		titles = append(titles, movie.Movie.Title)
+	}
+	if !reflect.DeepEqual(titles, []string{"F1: The Movie", "Jaws"}) {
+		t.Fatalf("RankPopular() titles = %v, want the two most clicked, with F1's latest title", titles)
+	}
+
+	want := []DayClicks{{Date: "2025-07-03", Clicks: 3}, {Date: "2025-07-01", Clicks: 2}}
+	if popular[0].Clicks != 5 || !reflect.DeepEqual(popular[0].Days, want) {
+		t.Fatalf("F1 = %d clicks over %+v, want 5 over %+v", popular[0].Clicks, popular[0].Days, want)
+	}
+}
@@ -0,0 +1,216 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// QualityOptions are the thresholds a scrape must stay within to be
+// published. A scrape that breaks one is quarantined for an admin to review,
+// and the city keeps serving its previous listings.
+type QualityOptions struct {
+	// MaxCountDrop is the largest percentage by which a scrape may have
+	// fewer movies than the city's previous listings.
+	MaxCountDrop int
+
+	// MaxDuplicates is the largest percentage of a scrape's movies that may
+	// repeat another movie's title.
+	MaxDuplicates int
+
+	// LinkDomains are the domains, and their subdomains, that movie links
+	// may point to.
+	LinkDomains []string
+}
+
+// QuarantinedScrape is a scrape held back from publishing because it failed
+// the quality checks.
+type QuarantinedScrape struct {
+	City          string    `json:"city"`
+	Movies        []Movie   `json:"movies"`
+	PreviousCount int       `json:"previous_count"`
+	Problems      []string  `json:"problems"`
+	ScrapedAt     time.Time `json:"scraped_at"`
+}
+
+// checkScrape returns what is suspicious about scraped compared with the
+// city's previous listings.
+func checkScrape(opts QualityOptions, previous, scraped []Movie) []string {
+	var problems []string
+
+	if len(previous) > 0 {
+		drop := 100 - len(scraped)*100/len(previous)
+		if drop > opts.MaxCountDrop {
+			problems = append(problems, fmt.Sprintf("movie count dropped by %d%%, from %d to %d", drop, len(previous), len(scraped)))
+		}
+	}
+
+	seen := make(map[string]bool, len(scraped))
+	duplicates := 0
+	for _, movie := range scraped {
+		key := strings.ToLower(foldForMatch(movie.Title))
+		if seen[key] {
+			duplicates++
+		}
+		seen[key] = true
+	}
+	if duplicates*100 > opts.MaxDuplicates*len(scraped) {
+		problems = append(problems, fmt.Sprintf("%d of %d movies repeat another movie's title", duplicates, len(scraped)))
+	}
+
+	var offDomain []string
+	for _, movie := range scraped {
+		if !linkOnDomain(movie.Href, opts.LinkDomains) {
+			offDomain = append(offDomain, movie.Href)
+		}
+	}
+	if len(offDomain) > 0 {
+		problems = append(problems, fmt.Sprintf("%d links point outside %s, such as %q", len(offDomain), strings.Join(opts.LinkDomains, ", "), offDomain[0]))
+	}
+
+	return problems
+}
+
+// linkOnDomain reports whether href is an absolute link to one of domains or
+// their subdomains.
+func linkOnDomain(href string, domains []string) bool {
+	parsed, err := url.Parse(href)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return false
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	for _, domain := range domains {
+		domain = strings.ToLower(domain)
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// publishScrape saves a scrape that passed the quality checks, or quarantines
+// it and returns the city's previous listings instead. Scrapes are only
+// checked when quarantine is enabled.
+func (s *MovieService) publishScrape(ctx context.Context, city string, scraped []Movie) ([]Movie, bool, error) {
+	if s.quarantine == nil {
+		s.memo.invalidate(city)
+		_ = s.saveScrape(ctx, city, scraped)
+
+		return scraped, false, nil
+	}
+
+	previous, err := s.repo.ListFresh(ctx, city, time.Time{})
+	if err != nil {
+		return nil, false, fmt.Errorf("query previous movies: %w", err)
+	}
+
+	problems := checkScrape(s.quality, previous, scraped)
+	if len(problems) == 0 {
+		s.memo.invalidate(city)
+		_ = s.saveScrape(ctx, city, scraped)
+
+		return scraped, false, nil
+	}
+
+	s.logger.WarnContext(ctx, "scrape quarantined for review", "city", city, "movies", len(scraped), "previous", len(previous), "problems", problems)
+
+	err = s.quarantine.SaveQuarantined(ctx, QuarantinedScrape{
+		City:          city,
+		Movies:        scraped,
+		PreviousCount: len(previous),
+		Problems:      problems,
+		ScrapedAt:     time.Now(),
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("quarantine scrape: %w", err)
+	}
+
+	return quarantinedListings(previous)
+}
+
+// awaitingReview reports whether the city has a quarantined scrape younger
+// than its cache TTL. Such cities are not scraped again until it is reviewed
+// or ages out, so a broken page is not scraped on every request.
+func (s *MovieService) awaitingReview(ctx context.Context, city string) bool {
+	if s.quarantine == nil {
+		return false
+	}
+
+	held, ok, err := s.quarantine.GetQuarantined(ctx, city)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to look up quarantined scrape", "city", city, "error", err)
+		return false
+	}
+
+	return ok && time.Since(held.ScrapedAt) < s.cacheTTLFor(ctx, city)
+}
+
+// loadWhileQuarantined serves the city's previous listings while a scrape of
+// it awaits review.
+func (s *MovieService) loadWhileQuarantined(ctx context.Context, city string) ([]Movie, bool, error) {
+	previous, err := s.repo.ListFresh(ctx, city, time.Time{})
+	if err != nil {
+		return nil, false, fmt.Errorf("query cached movies: %w", err)
+	}
+
+	return quarantinedListings(previous)
+}
+
+func quarantinedListings(previous []Movie) ([]Movie, bool, error) {
+	if len(previous) == 0 {
+		return nil, false, ErrScrapeQuarantined
+	}
+
+	return previous, true, nil
+}
+
+func (s *MovieService) ListQuarantined(ctx context.Context) ([]QuarantinedScrape, error) {
+	if s.quarantine == nil {
+		return nil, ErrQuarantineDisabled
+	}
+
+	return s.quarantine.ListQuarantined(ctx)
+}
+
+// PublishQuarantined saves the city's quarantined scrape as its listings,
+// reporting false when the city has none.
+func (s *MovieService) PublishQuarantined(ctx context.Context, city string) (bool, error) {
+	if s.quarantine == nil {
+		return false, ErrQuarantineDisabled
+	}
+
+	lock := s.cityLock(city)
+	lock.Lock()
+	defer lock.Unlock()
+
+	held, ok, err := s.quarantine.GetQuarantined(ctx, city)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	s.memo.invalidate(city)
+	if err := s.saveScrape(ctx, city, held.Movies); err != nil {
+		return false, err
+	}
+	s.logger.InfoContext(ctx, "published quarantined scrape", "city", city, "movies", len(held.Movies))
+
+	if _, err := s.quarantine.DeleteQuarantined(ctx, city); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// DiscardQuarantined drops the city's quarantined scrape, so the city is
+// scraped again on its next load. It reports false when the city has none.
+func (s *MovieService) DiscardQuarantined(ctx context.Context, city string) (bool, error) {
+	if s.quarantine == nil {
+		return false, ErrQuarantineDisabled
+	}
+
+	return s.quarantine.DeleteQuarantined(ctx, city)
+}
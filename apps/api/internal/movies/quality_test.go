@@ -0,0 +1,148 @@
+package movies
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeQuarantine struct {
+	mu   sync.Mutex
+	held map[string]QuarantinedScrape
+}
+
+func (f *fakeQuarantine) SaveQuarantined(_ context.Context, scrape QuarantinedScrape) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.held == nil {
+		f.held = make(map[string]QuarantinedScrape)
+	}
+	f.held[scrape.City] = scrape
+
+	return nil
+}
+
+func (f *fakeQuarantine) ListQuarantined(_ context.Context) ([]QuarantinedScrape, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []QuarantinedScrape
+	for _, scrape := range f.held {
+		result = append(result, scrape)
+	}
+
+	return result, nil
+}
+
+func (f *fakeQuarantine) GetQuarantined(_ context.Context, city string) (QuarantinedScrape, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	scrape, ok := f.held[city]
+	return scrape, ok, nil
+}
+
+func (f *fakeQuarantine) DeleteQuarantined(_ context.Context, city string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, ok := f.held[city]
+	delete(f.held, city)
+
+	return ok, nil
+}
+
+var testQuality = QualityOptions{MaxCountDrop: 50, MaxDuplicates: 20, LinkDomains: []string{"bookmyshow.com"}}
+
+func listing(titles ...string) []Movie {
+	result := make([]Movie, 0, len(titles))
+	for i, title := range titles {
+		result = append(result, Movie{Title: title, Href: "https://in.bookmyshow.com/movies/cuttack/" + title + "/ET" + string(rune('0'+i))})
+	}
+
+	return result
+}
+
+func TestCheckScrapeFlagsSuspiciousSnapshots(t *testing.T) {
+	t.Parallel()
+
+	previous := listing("a", "b", "c", "d", "e", "f")
+	offDomain := listing("a", "b", "c", "d")
+	offDomain[2].Href = "https://bookmyshow.com.example.net/movies/c"
+	offDomain[3].Href = "/movies/cuttack/d"
+
+	tests := []struct {
+		name    string
+		scraped []Movie
+		want    []string
+	}{
+		{"healthy", listing("a", "b", "c", "g"), nil},
+		{"count drop", listing("a", "b"), []string{"movie count dropped by 67%, from 6 to 2"}},
+		{"duplicates", listing("a", "A", "b", "b", "c", "d"), []string{"2 of 6 movies repeat another movie's title"}},
+		{"off domain", offDomain, []string{`2 links point outside bookmyshow.com, such as "https://bookmyshow.com.example.net/movies/c"`}},
+	}
+
+	for _, test := range tests {
+		got := checkScrape(testQuality, previous, test.scraped)
+		if strings.Join(got, "|") != strings.Join(test.want, "|") {
+			t.Errorf("%s: checkScrape() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestMovieServiceQuarantinesSuspiciousScrapes(t *testing.T) {
+	t.Parallel()
+
+	previous := listing("a", "b", "c", "d")
+	repo := &fakeRepository{listFreshMovies: previous}
+	scraper := &fakeScraper{movies: listing("a")}
+	quarantine := &fakeQuarantine{}
+	service := NewMovieService(repo, scraper, ServiceOptions{CacheTTL: time.Hour, Quarantine: quarantine, Quality: testQuality}, testLogger())
+
+	for range 2 {
+		loaded, fromCache, err := service.Load(context.Background(), "cuttack")
+		if err != nil || !fromCache || len(loaded) != len(previous) {
+			t.Fatalf("Load() = %d movies, %v, %v, want the previous listings", len(loaded), fromCache, err)
+		}
+	}
+
+	if scraper.calls != 1 || repo.replaceCalls != 0 {
+		t.Fatalf("scraper calls = %d, saves = %d, want one scrape held for review", scraper.calls, repo.replaceCalls)
+	}
+
+	held, err := service.ListQuarantined(context.Background())
+	if err != nil || len(held) != 1 || held[0].PreviousCount != 4 || len(held[0].Problems) != 1 {
+		t.Fatalf("ListQuarantined() = %+v, %v, want the scrape with its problem", held, err)
+	}
+
+	published, err := service.PublishQuarantined(context.Background(), "cuttack")
+	if err != nil || !published || repo.replaceCalls != 1 || len(repo.replacedWith) != 1 {
+		t.Fatalf("PublishQuarantined() = %v, %v after %d saves, want the held scrape saved", published, err, repo.replaceCalls)
+	}
+
+	if published, _ := service.PublishQuarantined(context.Background(), "cuttack"); published {
+		t.Fatal("PublishQuarantined() twice = true, want false once the scrape is gone")
+	}
+}
+
+func TestMovieServiceQuarantinesFirstScrapeWithoutListings(t *testing.T) {
+	t.Parallel()
+
+	scraped := listing("a", "b")
+	scraped[1].Href = "https://example.com/b"
+
+	quarantine := &fakeQuarantine{}
+	service := NewMovieService(&fakeRepository{}, &fakeScraper{movies: scraped}, ServiceOptions{CacheTTL: time.Hour, Quarantine: quarantine, Quality: testQuality}, testLogger())
+
+	if _, _, err := service.Load(context.Background(), "cuttack"); !errors.Is(err, ErrScrapeQuarantined) {
+		t.Fatalf("Load() error = %v, want ErrScrapeQuarantined", err)
+	}
+
+	if discarded, err := service.DiscardQuarantined(context.Background(), "cuttack"); err != nil || !discarded {
+		t.Fatalf("DiscardQuarantined() = %v, %v, want true", discarded, err)
+	}
+}
@@ -0,0 +1,52 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MovieFilter narrows a city's listings to movies in any of the given
+// languages and genres, compared case-insensitively. An empty list matches
+// every movie, and like FilterLanguages, movies without language or genre
+// data match any value, since not every scraper reports them.
+type MovieFilter struct {
+	Languages []string
+	Genres    []string
+}
+
+// Matches reports whether the movie passes the filter.
+func (f MovieFilter) Matches(movie Movie) bool {
+	return matchesAny(movie.Languages, f.Languages) && matchesAny(movie.Genres, f.Genres)
+}
+
+func matchesAny(values, wanted []string) bool {
+	if len(values) == 0 || len(wanted) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(values, func(value string) bool {
+		return slices.ContainsFunc(wanted, func(want string) bool { return strings.EqualFold(value, want) })
+	})
+}
+
+// RandomMovie picks one of the city's current movies matching the filter,
+// scraping the city first if its listings are stale. It returns
+// ErrNoMatchingMovies when none match.
+func (s *MovieService) RandomMovie(ctx context.Context, city string, filter MovieFilter) (Movie, error) {
+	if _, _, err := s.Load(ctx, city); err != nil {
+		return Movie{}, err
+	}
+
+	movie, found, err := s.repo.RandomMovie(ctx, city, filter)
+	if err != nil {
+		return Movie{}, fmt.Errorf("pick random movie: %w", err)
+	}
+
+	if !found {
+		return Movie{}, ErrNoMatchingMovies
+	}
+
+	return movie, nil
+}
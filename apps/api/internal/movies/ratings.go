@@ -0,0 +1,73 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultRatingsTTL is how long looked-up ratings are reused. Critic scores
+// change slowly, and providers such as OMDb have small daily quotas.
+const defaultRatingsTTL = 7 * 24 * time.Hour
+
+// addRatings sets the movie's ratings and review links. Lookup failures are
+// logged and leave the movie without ratings.
+func (s *MovieService) addRatings(ctx context.Context, movie *Movie) {
+	ratings, err := s.lookupRatings(ctx, movie.Title, movie.Year)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to look up ratings", "title", movie.Title, "error", err)
+		return
+	}
+
+	movie.Ratings = ratings
+
+	if ratings != nil && validIMDbID(ratings.IMDbID) {
+		movie.IMDbURL = "https://www.imdb.com/title/" + ratings.IMDbID + "/"
+		// Letterboxd redirects IMDb IDs to its own film pages.
+		movie.LetterboxdURL = "https://letterboxd.com/imdb/" + ratings.IMDbID + "/"
+	}
+}
+
+// validIMDbID reports whether id looks like an IMDb title ID, such as
+// "tt7181546", so it is safe to put in a URL.
+func validIMDbID(id string) bool {
+	digits, ok := strings.CutPrefix(id, "tt")
+	if !ok || digits == "" {
+		return false
+	}
+
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *MovieService) lookupRatings(ctx context.Context, title string, year int) (*Ratings, error) {
+	if s.ratingsStore != nil {
+		ratings, cached, err := s.ratingsStore.GetRatings(ctx, title, year, time.Now().Add(-s.ratingsTTL))
+		if err != nil {
+			return nil, fmt.Errorf("query cached ratings: %w", err)
+		}
+
+		if cached {
+			return ratings, nil
+		}
+	}
+
+	ratings, err := s.ratings.LookupRatings(ctx, title, year)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.ratingsStore != nil {
+		if err := s.ratingsStore.SaveRatings(ctx, title, year, ratings, time.Now()); err != nil {
+			s.logger.WarnContext(ctx, "failed to cache ratings", "title", title, "error", err)
+		}
+	}
+
+	return ratings, nil
+}
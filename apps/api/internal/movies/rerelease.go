@@ -0,0 +1,12 @@
+package movies
+
+import "time"
+
+// markRereleases flags the movies released more than a year before now, so
+// screenings of classics can be told apart from new releases. Movies without
+// a known year are never flagged.
+func markRereleases(list []Movie, now time.Time) {
+	for i := range list {
+		list[i].Rerelease = list[i].Year > 0 && now.Year()-list[i].Year > 1
+	}
+}
@@ -0,0 +1,127 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// RunSummary describes how long movies stay listed in a city.
+type RunSummary struct {
+	// Showing holds the movies listed now, longest running first.
+	Showing []TitleRun
+
+	// CompletedRuns counts the movies no longer listed, and AverageRunDays
+	// is how many days they were listed on average.
+	CompletedRuns  int
+	AverageRunDays float64
+
+	// Weeks holds the churn of the most recent weeks, newest first.
+	Weeks []WeekChurn
+}
+
+// TitleRun is how long a movie has been listed.
+type TitleRun struct {
+	Movie       Movie
+	FirstSeenAt time.Time
+
+	// Days counts the days since the movie was first seen, starting at one,
+	// and Week is the week of its run it is in, also starting at one.
+	Days int
+	Week int
+}
+
+// WeekChurn counts the movies a city added and dropped in the seven days
+// from Start.
+type WeekChurn struct {
+	Start   time.Time
+	Added   int
+	Removed int
+}
+
+// runDays counts the days from first to last, including the first.
+func runDays(first, last time.Time) int {
+	return int(last.Sub(first)/day) + 1
+}
+
+// SummarizeRuns summarizes a city's sightings as of now over the given number
+// of weeks. A movie is listed now when the city's latest scrape, at
+// lastScrape, saw it; any other movie was removed after it was last seen.
+func SummarizeRuns(sightings []Sighting, lastScrape, now time.Time, weeks int) RunSummary {
+	summary := RunSummary{Showing: []TitleRun{}, Weeks: make([]WeekChurn, weeks)}
+	for i := range summary.Weeks {
+		summary.Weeks[i].Start = now.Add(-time.Duration(i+1) * 7 * day)
+	}
+
+	// week returns the index of the week t falls in, or -1 when it is
+	// outside them all.
+	week := func(t time.Time) int {
+		if t.After(now) {
+			return -1
+		}
+
+		index := int(now.Sub(t) / (7 * day))
+		if index >= weeks {
+			return -1
+		}
+
+		return index
+	}
+
+	var totalDays int
+	for _, sighting := range sightings {
+		if i := week(sighting.FirstSeenAt); i >= 0 {
+			summary.Weeks[i].Added++
+		}
+
+		if !lastScrape.IsZero() && !sighting.LastSeenAt.Before(lastScrape) {
+			days := runDays(sighting.FirstSeenAt, now)
+			summary.Showing = append(summary.Showing, TitleRun{
+				Movie:       sighting.Movie,
+				FirstSeenAt: sighting.FirstSeenAt,
+				Days:        days,
+				Week:        (days-1)/7 + 1,
+			})
+			continue
+		}
+
+		summary.CompletedRuns++
+		totalDays += runDays(sighting.FirstSeenAt, sighting.LastSeenAt)
+
+		if i := week(sighting.LastSeenAt); i >= 0 {
+			summary.Weeks[i].Removed++
+		}
+	}
+
+	if summary.CompletedRuns > 0 {
+		average := float64(totalDays) / float64(summary.CompletedRuns)
+		summary.AverageRunDays = math.Round(average*10) / 10
+	}
+
+	slices.SortStableFunc(summary.Showing, func(a, b TitleRun) int {
+		return a.FirstSeenAt.Compare(b.FirstSeenAt)
+	})
+
+	return summary
+}
+
+// RunSummary summarizes how long movies stay listed in the city over the
+// given number of weeks. Runs that ended are only known as far back as the
+// sightings the cleanup job keeps.
+func (s *MovieService) RunSummary(ctx context.Context, city string, weeks int) (RunSummary, error) {
+	sightings, err := s.repo.ListSightings(ctx, city, time.Time{}, 0)
+	if err != nil {
+		return RunSummary{}, fmt.Errorf("query sightings: %w", err)
+	}
+
+	lastScrape, _, err := s.repo.LastScrape(ctx, city)
+	if err != nil {
+		return RunSummary{}, fmt.Errorf("query last scrape: %w", err)
+	}
+
+	return SummarizeRuns(sightings, lastScrape, time.Now(), weeks), nil
+}
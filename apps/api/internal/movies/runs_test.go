@@ -0,0 +1,58 @@
+package movies
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSummarizeRunsCountsWeeksAndChurn(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 7, 29, 12, 0, 0, 0, time.UTC)
+	lastScrape := now.Add(-2 * time.Hour)
+	sighting := func(title string, firstDaysAgo, lastDaysAgo int) Sighting {
+		last := now.AddDate(0, 0, -lastDaysAgo).Add(-2 * time.Hour)
+		return Sighting{City: "cuttack", Movie: Movie{Title: title}, FirstSeenAt: now.AddDate(0, 0, -firstDaysAgo), LastSeenAt: last}
+	}
+
+	summary := SummarizeRuns([]Sighting{
+		sighting("Superman", 3, 0),
+		sighting("F1", 17, 0),
+		sighting("Jaws", 30, 9),
+		sighting("Sitaare", 40, 24),
+	}, lastScrape, now, 4)
+
+	var showing []string
+	for _, run := range summary.Showing {
+		showing = append(showing, run.Movie.Title)
+	}
+	if !reflect.DeepEqual(showing, []string{"F1", "Superman"}) {
+		t.Fatalf("Showing = %v, want the listed movies, longest running first", showing)
+	}
+	if f1 := summary.Showing[0]; f1.Days != 18 || f1.Week != 3 {
+		t.Fatalf("F1 run = %d days in week %d, want 18 days in week 3", f1.Days, f1.Week)
+	}
+
+	// Jaws ran 21 days and Sitaare 16.
+	if summary.CompletedRuns != 2 || summary.AverageRunDays != 18.5 {
+		t.Fatalf("completed runs = %d averaging %v days, want 2 averaging 18.5", summary.CompletedRuns, summary.AverageRunDays)
+	}
+
+	var churn [][2]int
+	for _, week := range summary.Weeks {
+		churn = append(churn, [2]int{week.Added, week.Removed})
+	}
+	if want := [][2]int{{1, 0}, {0, 1}, {1, 0}, {0, 1}}; !reflect.DeepEqual(churn, want) {
+		t.Fatalf("weekly churn = %v, want %v", churn, want)
+	}
+}
+
+func TestSummarizeRunsWithoutScrapes(t *testing.T) {
+	t.Parallel()
+
+	summary := SummarizeRuns(nil, time.Time{}, time.Now(), 2)
+	if len(summary.Showing) != 0 || summary.CompletedRuns != 0 || len(summary.Weeks) != 2 {
+		t.Fatalf("SummarizeRuns() = %+v, want no runs and two empty weeks", summary)
+	}
+}
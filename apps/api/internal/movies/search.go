@@ -1,38 +1,345 @@
 package movies
 
 import (
-	"html"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/sahilm/fuzzy"
 )
 
-func NormalizeQuery(query string) string {
-	htmlDecoded := html.UnescapeString(query)
+// Bonuses added on top of the fuzzy score so that titles containing the query
+// verbatim always outrank titles that only match scattered characters.
+const (
+	exactMatchBonus      = 300
+	prefixMatchBonus     = 200
+	wordPrefixMatchBonus = 100
+)
+
+// Titles found only by the edit-distance pass start from typoMatchScore and
+// lose typoEditPenalty per edit, keeping them below verbatim matches. Titles
+// that are spelled differently but sound the same score phoneticMatchScore.
+const (
+	typoMatchScore     = 100
+	typoEditPenalty    = 25
+	phoneticMatchScore = 90
+)
+
+// FuzzinessAuto picks the allowed edit distance from the query length.
+const FuzzinessAuto = -1
+
+// MaxFuzziness is the largest edit distance a caller may request.
+const MaxFuzziness = 3
+
+// Searchable movie fields and the weight, in percent, applied to matches in
+// each, so a title match outranks an equally good cast or genre match.
+const (
+	FieldTitle     = "title"
+	FieldCast      = "cast"
+	FieldGenres    = "genres"
+	FieldLanguages = "languages"
+)
+
+var fieldWeights = map[string]int{
+	FieldTitle:     100,
+	FieldCast:      80,
+	FieldGenres:    50,
+	FieldLanguages: 50,
+}
+
+// IsSearchField reports whether field can be passed in SearchOptions.Fields.
+func IsSearchField(field string) bool {
+	_, ok := fieldWeights[field]
+	return ok
+}
 
-	// Convert non-breaking spaces to ASCII spaces so fuzzy matching lines up
-	// with titles stored in the database.
-	cleaned := strings.ReplaceAll(htmlDecoded, "\u00a0", " ")
+type SearchOptions struct {
+	MinScore  int
+	Fuzziness int
 
-	return strings.TrimSpace(cleaned)
+	// Fields lists the movie fields to match against. Only titles are searched
+	// when it is empty.
+	Fields []string
 }
 
-func FuzzySearch(list []Movie, query string) []Movie {
-	if len(list) == 0 {
+// FilterLanguages returns the movies showing in any of languages, ignoring
+// case. Movies without language data are kept, since scrapers that don't
+// report languages would otherwise filter out everything.
+func FilterLanguages(list []Movie, languages []string) []Movie {
+	if len(languages) == 0 {
 		return list
 	}
 
-	titles := make([]string, len(list))
+	filtered := make([]Movie, 0, len(list))
+	for _, movie := range list {
+		if len(movie.Languages) == 0 || slices.ContainsFunc(movie.Languages, func(language string) bool {
+			return slices.ContainsFunc(languages, func(wanted string) bool { return strings.EqualFold(language, wanted) })
+		}) {
+			filtered = append(filtered, movie)
+		}
+	}
+
+	return filtered
+}
+
+// SortByBuzz sorts list by Buzz, most anticipated first, keeping the order of
+// movies with equal buzz.
+func SortByBuzz(list []Movie) {
+	slices.SortStableFunc(list, func(a, b Movie) int {
+		return b.Buzz - a.Buzz
+	})
+}
+
+type candidateMatch struct {
+	candidate      int
+	score          int
+	matchedIndexes []int
+}
+
+// FuzzySearch returns the movies matching query in any of opts.Fields, best
+// match first, with Score populated. Values the fuzzy matcher misses are
+// retried with an edit-distance pass bounded by opts.Fuzziness, and matches
+// scoring below opts.MinScore after field weighting are dropped.
+func FuzzySearch(list []Movie, query string, opts SearchOptions) []Movie {
+	return newSearchIndex(list).search(query, opts)
+}
+
+// searchIndex holds a movie list with its searchable fields folded, lowered
+// and phonetically keyed, so every query against the same list reuses that
+// work. Fields are prepared the first time they are searched.
+type searchIndex struct {
+	list []Movie
+
+	mu     sync.Mutex
+	fields map[string]*fieldIndex
+}
+
+// fieldIndex is one field of every movie flattened into the strings matched
+// against, with the index of the movie each came from and, for titles, the
+// folded-to-original offsets used for highlighting.
+type fieldIndex struct {
+	candidates []string
+	lowered    []string
+	phonetic   []string
+	owners     []int
+	offsets    [][]int
+}
+
+func newSearchIndex(list []Movie) *searchIndex {
+	return &searchIndex{list: list, fields: make(map[string]*fieldIndex)}
+}
+
+func (idx *searchIndex) search(query string, opts SearchOptions) []Movie {
+	if len(idx.list) == 0 {
+		return idx.list
+	}
+
+	fields := opts.Fields
+	if len(fields) == 0 {
+		fields = []string{FieldTitle}
+	}
+
+	query = foldForMatch(query)
+	lowerQuery := strings.ToLower(query)
+
+	maxEdits := opts.Fuzziness
+	if maxEdits == FuzzinessAuto {
+		maxEdits = autoFuzziness(lowerQuery)
+	}
+
+	// Editing every letter of a query turns it into any short word, so a
+	// query is allowed fewer edits than it has letters.
+	maxEdits = max(min(maxEdits, utf8.RuneCountInString(lowerQuery)-1), 0)
+
+	best := make(map[int]Movie)
+	for _, field := range fields {
+		weight, ok := fieldWeights[field]
+		if !ok {
+			continue
+		}
+
+		index := idx.field(field)
+
+		for _, match := range matchCandidates(index, query, maxEdits) {
+			score := match.score * weight / 100
+			if score < opts.MinScore {
+				continue
+			}
+
+			owner := index.owners[match.candidate]
+			if current, ok := best[owner]; ok && current.Score >= score {
+				continue
+			}
+
+			movie := idx.list[owner]
+			movie.Score = score
+			if field == FieldTitle && match.matchedIndexes != nil {
+				movie.Highlights = highlightRanges(movie.Title, index.offsets[match.candidate], match.matchedIndexes)
+			}
+
+			best[owner] = movie
+		}
+	}
+
+	result := make([]Movie, 0, len(best))
+	for owner := range idx.list {
+		if movie, ok := best[owner]; ok {
+			result = append(result, movie)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+
+	return result
+}
+
+func (idx *searchIndex) field(field string) *fieldIndex {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	index, ok := idx.fields[field]
+	if !ok {
+		index = newFieldIndex(idx.list, field)
+		idx.fields[field] = index
+	}
+
+	return index
+}
+
+func newFieldIndex(list []Movie, field string) *fieldIndex {
+	index := &fieldIndex{}
+
 	for i, movie := range list {
-		titles[i] = movie.Title
+		var values []string
+		switch field {
+		case FieldTitle:
+			values = []string{movie.Title}
+		case FieldCast:
+			values = movie.Cast
+		case FieldGenres:
+			values = movie.Genres
+		case FieldLanguages:
+			values = movie.Languages
+		}
+
+		for _, value := range values {
+			folded, offsets := foldWithOffsets(value)
+			lowered := strings.ToLower(folded)
+
+			index.candidates = append(index.candidates, folded)
+			index.lowered = append(index.lowered, lowered)
+			index.phonetic = append(index.phonetic, phoneticKey(lowered))
+			index.owners = append(index.owners, i)
+			index.offsets = append(index.offsets, offsets)
+		}
 	}
 
-	matches := fuzzy.Find(query, titles)
+	return index
+}
+
+// matchCandidates scores every candidate matching query: first with the fuzzy
+// matcher plus verbatim-match bonuses, then with the edit-distance and
+// phonetic pass for candidates the fuzzy matcher rejected.
+func matchCandidates(index *fieldIndex, query string, maxEdits int) []candidateMatch {
+	lowerQuery := strings.ToLower(query)
+	matched := make([]bool, len(index.candidates))
+
+	var result []candidateMatch
+	for _, match := range fuzzy.Find(query, index.candidates) {
+		matched[match.Index] = true
 
-	result := make([]Movie, 0, len(matches))
-	for _, match := range matches {
-		result = append(result, list[match.Index])
+		result = append(result, candidateMatch{
+			candidate:      match.Index,
+			score:          match.Score + matchBonus(index.lowered[match.Index], lowerQuery),
+			matchedIndexes: match.MatchedIndexes,
+		})
+	}
+
+	phoneticQuery := phoneticKey(lowerQuery)
+
+	for i := range index.candidates {
+		if matched[i] {
+			continue
+		}
+
+		score, ok := approximateScore(index.lowered[i], index.phonetic[i], lowerQuery, phoneticQuery, maxEdits)
+		if !ok {
+			continue
+		}
+
+		result = append(result, candidateMatch{candidate: i, score: score})
 	}
 
 	return result
 }
+
+// approximateScore scores a title the fuzzy matcher rejected, first by edit
+// distance on the raw spelling and then on the transliteration-insensitive
+// phonetic keys, so "Pushpaa" and "Kalky" still find "Pushpa" and "Kalki".
+func approximateScore(title, phoneticTitle, query, phoneticQuery string, maxEdits int) (int, bool) {
+	if maxEdits > 0 {
+		if distance := titleDistance(title, query); distance <= maxEdits {
+			return typoMatchScore - distance*typoEditPenalty, true
+		}
+	}
+
+	if phoneticQuery == "" {
+		return 0, false
+	}
+
+	distance := titleDistance(phoneticTitle, phoneticQuery)
+	switch {
+	case distance == 0:
+		return phoneticMatchScore, true
+	case distance <= maxEdits:
+		return phoneticMatchScore - distance*typoEditPenalty, true
+	default:
+		return 0, false
+	}
+}
+
+func matchBonus(title, query string) int {
+	switch {
+	case title == query:
+		return exactMatchBonus
+	case strings.HasPrefix(title, query):
+		return prefixMatchBonus
+	case strings.Contains(" "+title, " "+query):
+		return wordPrefixMatchBonus
+	default:
+		return 0
+	}
+}
+
+// highlightRanges converts byte positions matched in a folded title back to
+// ranges of characters in the original title, merging adjacent characters.
+func highlightRanges(title string, offsets []int, matchedIndexes []int) []Highlight {
+	var ranges []Highlight
+
+	lastOffset := -1
+	for _, index := range matchedIndexes {
+		if index >= len(offsets) {
+			continue
+		}
+
+		offset := offsets[index]
+		if offset == lastOffset {
+			continue
+		}
+		lastOffset = offset
+
+		position := utf8.RuneCountInString(title[:offset])
+		if n := len(ranges); n > 0 && ranges[n-1].End == position {
+			ranges[n-1].End++
+			continue
+		}
+
+		ranges = append(ranges, Highlight{Start: position, End: position + 1})
+	}
+
+	return ranges
+}
@@ -1,6 +1,9 @@
 package movies
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 func TestNormalizeQuery(t *testing.T) {
 	t.Parallel()
@@ -22,7 +25,7 @@ func TestFuzzySearchPrefersBestMatches(t *testing.T) {
 		{Title: "Ballerina (2025)", Href: "/ballerina-2025"},
 	}
 
-	got := FuzzySearch(list, "Ballerina")
+	got := FuzzySearch(list, "Ballerina", SearchOptions{})
 
 	if len(got) < 2 {
 		t.Fatalf("FuzzySearch() returned %d matches, want at least 2", len(got))
@@ -37,13 +40,329 @@ func TestFuzzySearchPrefersBestMatches(t *testing.T) {
 	}
 }
 
+func TestFilterLanguages(t *testing.T) {
+	t.Parallel()
+
+	list := []Movie{
+		{Title: "Sitaare Zameen Par", Languages: []string{"Hindi"}},
+		{Title: "Thug Life", Languages: []string{"Tamil", "Telugu"}},
+		{Title: "Ballerina"},
+	}
+
+	got := FilterLanguages(list, []string{"hindi", "English"})
+	if len(got) != 2 || got[0].Title != "Sitaare Zameen Par" || got[1].Title != "Ballerina" {
+		t.Fatalf("FilterLanguages() = %v, want Sitaare Zameen Par and Ballerina", got)
+	}
+
+	if got := FilterLanguages(list, nil); len(got) != len(list) {
+		t.Fatalf("FilterLanguages(nil) returned %d movies, want %d", len(got), len(list))
+	}
+}
+
 func TestFuzzySearchEmptyList(t *testing.T) {
 	t.Parallel()
 
 	var list []Movie
-	got := FuzzySearch(list, "anything")
+	got := FuzzySearch(list, "anything", SearchOptions{})
 
 	if len(got) != 0 {
 		t.Fatalf("FuzzySearch() returned %d items, want 0", len(got))
 	}
 }
+
+func TestSearchMemoReusesResultsForSameSnapshot(t *testing.T) {
+	t.Parallel()
+
+	memo := newSearchMemo()
+	list := []Movie{{Title: "Pushpa 2: The Rule", Href: "/pushpa"}}
+
+	first := memo.search("cuttack", list, "pushpa", SearchOptions{})
+	if len(first) != 1 {
+		t.Fatalf("search() returned %d matches, want 1", len(first))
+	}
+
+	if cached := memo.cities["cuttack"].results[memoKey("pushpa", SearchOptions{})]; len(cached) != 1 {
+		t.Fatalf("memoized result = %+v, want one movie", cached)
+	}
+
+	memo.invalidate("cuttack")
+	if _, ok := memo.cities["cuttack"]; ok {
+		t.Fatal("invalidate() left city entry in memo")
+	}
+}
+
+func TestFuzzySearchBoostsExactAndPrefixMatches(t *testing.T) {
+	t.Parallel()
+
+	list := []Movie{
+		{Title: "Kalki 2898 AD", Href: "/kalki"},
+		{Title: "Avatar: The Way of Water", Href: "/avatar-2"},
+		{Title: "Avatar", Href: "/avatar"},
+	}
+
+	got := FuzzySearch(list, "Avatar", SearchOptions{})
+
+	if len(got) != 2 {
+		t.Fatalf("FuzzySearch() returned %+v, want 2 matches", got)
+	}
+
+	if got[0].Href != "/avatar" || got[1].Href != "/avatar-2" {
+		t.Fatalf("FuzzySearch() order = %+v, want exact match before prefix match", got)
+	}
+
+	if got[0].Score <= got[1].Score {
+		t.Fatalf("scores = %d, %d, want exact match scored higher", got[0].Score, got[1].Score)
+	}
+}
+
+func TestFuzzySearchDropsMatchesBelowMinScore(t *testing.T) {
+	t.Parallel()
+
+	list := []Movie{
+		{Title: "Ballerina", Href: "/ballerina"},
+		{Title: "Interstellar", Href: "/interstellar"},
+	}
+
+	got := FuzzySearch(list, "e", SearchOptions{})
+
+	if len(got) != 0 {
+		t.Fatalf("FuzzySearch() = %+v, want scattered single-letter matches dropped", got)
+	}
+
+	got = FuzzySearch(list, "Interstellar", SearchOptions{MinScore: 50})
+
+	if len(got) != 1 || got[0].Href != "/interstellar" {
+		t.Fatalf("FuzzySearch() = %+v, want only Interstellar above threshold", got)
+	}
+}
+
+func TestFuzzySearchFindsTyposWithinFuzziness(t *testing.T) {
+	t.Parallel()
+
+	list := []Movie{
+		{Title: "Oppenheimer", Href: "/oppenheimer"},
+		{Title: "Ballerina", Href: "/ballerina"},
+	}
+
+	got := FuzzySearch(list, "Oppenhiemer", SearchOptions{Fuzziness: FuzzinessAuto})
+	if len(got) != 1 || got[0].Href != "/oppenheimer" {
+		t.Fatalf("FuzzySearch() = %+v, want Oppenheimer", got)
+	}
+
+	got = FuzzySearch(list, "Oppenhiemer", SearchOptions{Fuzziness: 0})
+	if len(got) != 0 {
+		t.Fatalf("FuzzySearch() with fuzziness 0 = %+v, want no matches", got)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"oppenhiemer", "oppenheimer", 2},
+		{"pushpa", "pushpa", 0},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Fatalf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestPhoneticKeyFoldsRomanizationVariants(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b string
+	}{
+		{"Pushpaa 2: The Rule", "Pushpa 2 The Rule"},
+		{"Bhool Bhulaiyaa 3", "Bhul Bhulaiya 3"},
+		{"Kalky 2898 AD", "Kalki 2898 A.D."},
+		{"Singham Again Pt II", "Singham Again Part 2"},
+	}
+
+	for _, tt := range tests {
+		if phoneticKey(tt.a) != phoneticKey(tt.b) {
+			t.Fatalf("phoneticKey(%q) = %q, phoneticKey(%q) = %q, want equal", tt.a, phoneticKey(tt.a), tt.b, phoneticKey(tt.b))
+		}
+	}
+}
+
+func TestFuzzySearchMatchesAlternateSpellings(t *testing.T) {
+	t.Parallel()
+
+	list := []Movie{
+		{Title: "Kalki 2898 AD", Href: "/kalki"},
+		{Title: "Devara Part 1", Href: "/devara"},
+	}
+
+	got := FuzzySearch(list, "Kalky", SearchOptions{Fuzziness: 0})
+	if len(got) != 1 || got[0].Href != "/kalki" {
+		t.Fatalf("FuzzySearch() = %+v, want Kalki 2898 AD", got)
+	}
+
+	got = FuzzySearch(list, "Devaraa Pt 1", SearchOptions{Fuzziness: 0})
+	if len(got) != 1 || got[0].Href != "/devara" {
+		t.Fatalf("FuzzySearch() = %+v, want Devara Part 1", got)
+	}
+}
+
+func TestFoldForMatchNormalizesUnicodeAndPunctuation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Amélie", "Amelie"},
+		{"Mission: Impossible – Dead Reckoning", "Mission Impossible Dead Reckoning"},
+		{"Fast & Furious", "Fast and Furious"},
+		{"Don’t Look Up", "Dont Look Up"},
+		{"“Pushpa”  2", "Pushpa 2"},
+	}
+
+	for _, tt := range tests {
+		if got := foldForMatch(tt.input); got != tt.want {
+			t.Fatalf("foldForMatch(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzySearchIgnoresPunctuationDifferences(t *testing.T) {
+	t.Parallel()
+
+	list := []Movie{
+		{Title: "Mission: Impossible – The Final Reckoning", Href: "/mi"},
+		{Title: "Fast & Furious", Href: "/ff"},
+	}
+
+	got := FuzzySearch(list, "fast and furious", SearchOptions{})
+	if len(got) == 0 || got[0].Href != "/ff" {
+		t.Fatalf("FuzzySearch() = %+v, want Fast & Furious first", got)
+	}
+
+	if got[0].Score < exactMatchBonus {
+		t.Fatalf("score = %d, want exact-match score", got[0].Score)
+	}
+
+	got = FuzzySearch(list, "Mission Impossible - The Final Reckoning", SearchOptions{})
+	if len(got) == 0 || got[0].Href != "/mi" {
+		t.Fatalf("FuzzySearch() = %+v, want Mission: Impossible first", got)
+	}
+}
+
+func TestFuzzySearchReturnsHighlightsInOriginalTitle(t *testing.T) {
+	t.Parallel()
+
+	list := []Movie{{Title: "Amélie: Le Fabuleux Destin", Href: "/amelie"}}
+
+	got := FuzzySearch(list, "amelie", SearchOptions{})
+	if len(got) != 1 {
+		t.Fatalf("FuzzySearch() = %+v, want one match", got)
+	}
+
+	want := []Highlight{{Start: 0, End: 6}}
+	if len(got[0].Highlights) != 1 || got[0].Highlights[0] != want[0] {
+		t.Fatalf("highlights = %+v, want %+v", got[0].Highlights, want)
+	}
+}
+
+func TestFoldWithOffsetsMapsBackToOriginal(t *testing.T) {
+	t.Parallel()
+
+	folded, offsets := foldWithOffsets("Fast & Furióus")
+	if folded != "Fast and Furious" {
+		t.Fatalf("folded = %q, want %q", folded, "Fast and Furious")
+	}
+
+	if len(offsets) != len(folded) {
+		t.Fatalf("len(offsets) = %d, want %d", len(offsets), len(folded))
+	}
+
+	if offsets[5] != 5 || offsets[7] != 5 {
+		t.Fatalf("offsets for \"and\" = %d..%d, want 5", offsets[5], offsets[7])
+	}
+
+	if offsets[len(folded)-1] != len("Fast & Furióu") {
+		t.Fatalf("offset of final rune = %d, want %d", offsets[len(folded)-1], len("Fast & Furióu"))
+	}
+}
+
+func TestFuzzySearchWeightsFields(t *testing.T) {
+	t.Parallel()
+
+	list := []Movie{
+		{Title: "Kalki 2898 AD", Href: "/kalki", Cast: []string{"Prabhas"}},
+		{Title: "Prabhas", Href: "/story"},
+		{Title: "Ballerina", Href: "/ballerina", Genres: []string{"Action"}},
+	}
+
+	got := FuzzySearch(list, "Prabhas", SearchOptions{Fields: []string{FieldCast, FieldTitle}})
+	if len(got) != 2 {
+		t.Fatalf("FuzzySearch() = %+v, want 2 matches", got)
+	}
+
+	if got[0].Href != "/story" || got[1].Href != "/kalki" {
+		t.Fatalf("FuzzySearch() order = %+v, want title match before equally good cast match", got)
+	}
+
+	if got[1].Highlights != nil {
+		t.Fatalf("cast match highlights = %+v, want none", got[1].Highlights)
+	}
+
+	got = FuzzySearch(list, "Prabhas", SearchOptions{})
+	if len(got) != 1 || got[0].Href != "/story" {
+		t.Fatalf("FuzzySearch() without fields = %+v, want title matches only", got)
+	}
+}
+
+func benchmarkMovies(n int) []Movie {
+	words := []string{"Kalki", "Pushpa", "Bhool", "Bhulaiyaa", "Superman", "Jurassic", "World", "Rebirth", "How", "to", "Train", "Your", "Dragon", "Sitaare", "Zameen", "Par"}
+
+	list := make([]Movie, 0, n)
+	for i := range n {
+		title := fmt.Sprintf("%s %s %s %d", words[i%len(words)], words[(i*7+3)%len(words)], words[(i*5+1)%len(words)], i)
+		list = append(list, Movie{
+			Title:     title,
+			Href:      fmt.Sprintf("/movies/%d", i),
+			Cast:      []string{words[(i*3)%len(words)] + " Kapoor", words[(i*11)%len(words)] + " Khan"},
+			Genres:    []string{"Action", "Drama"},
+			Languages: []string{"Hindi", "Telugu"},
+		})
+	}
+
+	return list
+}
+
+var benchmarkQueries = []string{"Pushpaa", "superman", "Jurasic Wrld", "bhul bhulaiya", "dragn", "kapoor"}
+
+func BenchmarkFuzzySearch(b *testing.B) {
+	list := benchmarkMovies(300)
+	opts := SearchOptions{Fuzziness: FuzzinessAuto, Fields: []string{FieldTitle, FieldCast}}
+
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		FuzzySearch(list, benchmarkQueries[i%len(benchmarkQueries)], opts)
+	}
+}
+
+// BenchmarkSearchMemoDistinctQueries measures searches that miss the result
+// memo but reuse the city's snapshot, the common case for typed queries.
+func BenchmarkSearchMemoDistinctQueries(b *testing.B) {
+	list := benchmarkMovies(300)
+	memo := newSearchMemo()
+	opts := SearchOptions{Fuzziness: FuzzinessAuto, Fields: []string{FieldTitle, FieldCast}}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			memo.search("cuttack", list, fmt.Sprintf("%s %d", benchmarkQueries[i%len(benchmarkQueries)], i), opts)
+		}
+	})
+}
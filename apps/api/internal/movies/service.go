@@ -4,76 +4,344 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"go-scraping/internal/logging"
 )
 
-type movieService struct {
-	repo     Repository
-	scraper  Scraper
-	cacheTTL time.Duration
-	logger   *log.Logger
+type ServiceOptions struct {
+	CacheTTL       time.Duration
+	SearchMinScore int
+
+	// StalePolicy decides what Load does with movies older than CacheTTL,
+	// for cities without a policy of their own. Empty means StaleBlock.
+	StalePolicy StalePolicy
+
+	// SearchBackend selects SearchBackendFuzzy (the default) or
+	// SearchBackendIndex for matching queries.
+	SearchBackend string
+
+	// SearchLog records every search for analytics. Capture is disabled when
+	// it is nil.
+	SearchLog SearchLog
+
+	// RequestLog records every API request, without anything identifying
+	// the client, for traffic reports. Capture is disabled when it is nil.
+	RequestLog RequestLog
+
+	// Aliases maps alternate titles onto canonical ones during search. Alias
+	// resolution is disabled when it is nil.
+	Aliases AliasStore
+
+	// TitleVariants holds titles in other languages for LocalizeTitles.
+	// Localization is disabled when it is nil.
+	TitleVariants TitleVariantStore
+
+	// CitySettings holds per-city overrides set by admins. Every city uses
+	// the defaults when it is nil.
+	CitySettings CitySettingsStore
+
+	// MaxConcurrentScrapes caps how many cities are scraped at once, each in
+	// its own Chrome instance. Zero means no limit. Scrapes wait at most
+	// ScrapeQueueTimeout for a free slot before failing with
+	// ErrScrapeQueueFull; zero means they wait as long as their context allows.
+	MaxConcurrentScrapes int
+	ScrapeQueueTimeout   time.Duration
+
+	// ScrapingDisabled serves only movies already in the database, of any
+	// age, and never starts the scraper, so the service can run where Chrome
+	// is not installed. Cities with nothing saved fail with
+	// ErrScrapingDisabled.
+	ScrapingDisabled bool
+
+	// Ratings adds critic scores to the top search matches, cached in
+	// RatingsStore for RatingsTTL. Ratings are disabled when it is nil.
+	Ratings      RatingsProvider
+	RatingsStore RatingsStore
+	RatingsTTL   time.Duration
+
+	// Streaming marks the top search matches that are already available on
+	// a streaming service, caching lookups in memory for StreamingTTL.
+	// Streaming lookups are disabled when it is nil.
+	Streaming    StreamingProvider
+	StreamingTTL time.Duration
+
+	// Trailers looks up the trailers of listed movies, caching lookups in
+	// memory. Trailer lookups are disabled when it is nil.
+	Trailers TrailerProvider
+
+	// Flags turns features on per city or for a share of traffic. Every
+	// flag keeps its default when it is nil.
+	Flags Flags
+
+	// Quarantine holds scrapes that fail the Quality checks for an admin to
+	// review. Scrapes are published unchecked when it is nil.
+	Quarantine QuarantineStore
+	Quality    QualityOptions
+}
+
+// MovieService loads, searches and reports on each city's movies, scraping
+// BookMyShow when the saved listings are missing or stale.
+type MovieService struct {
+	repo       Repository
+	scraper    Scraper
+	cacheTTL   time.Duration
+	stale      StalePolicy
+	minScore   int
+	backend    string
+	searchLog  SearchLog
+	requestLog RequestLog
+	aliases    AliasStore
+	variants   TitleVariantStore
+	settings   CitySettingsStore
+	flags      Flags
+	logger     *slog.Logger
+
+	quarantine QuarantineStore
+	quality    QualityOptions
+
+	ratings      RatingsProvider
+	ratingsStore RatingsStore
+	ratingsTTL   time.Duration
 
-	scrapeLocks sync.Map
+	streaming      StreamingProvider
+	streamingTTL   time.Duration
+	streamingCache *lookupCache[*Streaming]
+
+	trailers     TrailerProvider
+	trailerCache *lookupCache[*Trailer]
+
+	listenersMu      sync.RWMutex
+	refreshListeners []RefreshListener
+
+	scrapeLocks      sync.Map
+	scrapingPaused   atomic.Bool
+	scrapingDisabled bool
+	scrapeSlots      chan struct{}
+	queueTimeout     time.Duration
+
+	// shutdownCtx is cancelled by Shutdown to abort in-flight scrapes, which
+	// are tracked in scrapes so Shutdown can wait for their browsers to exit.
+	shutdownMu     sync.Mutex
+	shutdownCtx    context.Context
+	cancelShutdown context.CancelFunc
+	scrapes        sync.WaitGroup
+
+	scrapeHistory scrapeHistory
+	counters      *cacheCounters
+	memo          *searchMemo
+	prefixes      *prefixIndexes
+	popularity    *popularity
+	aliasCache    aliasCache
+	variantCache  variantCache
+	settingsCache citySettingsCache
+	textIndexes   *textIndexes
 }
 
-var errEmptyScrape = errors.New("scrape returned no movies")
+var _ Service = (*MovieService)(nil)
+
+func NewMovieService(repo Repository, scraper Scraper, opts ServiceOptions, logger *slog.Logger) *MovieService {
+	var scrapeSlots chan struct{}
+	if opts.MaxConcurrentScrapes > 0 {
+		scrapeSlots = make(chan struct{}, opts.MaxConcurrentScrapes)
+	}
+
+	ratingsTTL := opts.RatingsTTL
+	if ratingsTTL <= 0 {
+		ratingsTTL = defaultRatingsTTL
+	}
+
+	stalePolicy := opts.StalePolicy
+	if stalePolicy == "" {
+		stalePolicy = StaleBlock
+	}
+
+	streamingTTL := opts.StreamingTTL
+	if streamingTTL <= 0 {
+		streamingTTL = defaultStreamingTTL
+	}
+
+	shutdownCtx, cancelShutdown := context.WithCancel(context.Background())
+
+	return &MovieService{
+		repo:        repo,
+		scraper:     scraper,
+		cacheTTL:    opts.CacheTTL,
+		stale:       stalePolicy,
+		minScore:    opts.SearchMinScore,
+		backend:     opts.SearchBackend,
+		searchLog:   opts.SearchLog,
+		requestLog:  opts.RequestLog,
+		aliases:     opts.Aliases,
+		variants:    opts.TitleVariants,
+		settings:    opts.CitySettings,
+		flags:       opts.Flags,
+		logger:      logger,
+		quarantine:  opts.Quarantine,
+		quality:     opts.Quality,
+		counters:    newCacheCounters(),
+		memo:        newSearchMemo(),
+		prefixes:    newPrefixIndexes(),
+		popularity:  newPopularity(),
+		textIndexes: newTextIndexes(),
+		scrapeSlots: scrapeSlots,
+
+		queueTimeout:     opts.ScrapeQueueTimeout,
+		scrapingDisabled: opts.ScrapingDisabled,
+
+		shutdownCtx:    shutdownCtx,
+		cancelShutdown: cancelShutdown,
+
+		ratings:      opts.Ratings,
+		ratingsStore: opts.RatingsStore,
+		ratingsTTL:   ratingsTTL,
+
+		streaming:      opts.Streaming,
+		streamingTTL:   streamingTTL,
+		streamingCache: newLookupCache[*Streaming](),
 
-func NewMovieService(repo Repository, scraper Scraper, cacheTTL time.Duration, logger *log.Logger) Service {
-	return &movieService{
-		repo:     repo,
-		scraper:  scraper,
-		cacheTTL: cacheTTL,
-		logger:   logger,
+		trailers:     opts.Trailers,
+		trailerCache: newLookupCache[*Trailer](),
 	}
 }
 
-func (s *movieService) Load(ctx context.Context, city string) ([]Movie, bool, error) {
+// Load returns the city's movies, scraping it first when its saved movies are
+// older than the cache TTL, unless its stale policy serves them as they are.
+func (s *MovieService) Load(ctx context.Context, city string) ([]Movie, bool, error) {
+	return s.load(ctx, city, s.stalePolicyFor(ctx, city))
+}
+
+func (s *MovieService) load(ctx context.Context, city string, policy StalePolicy) ([]Movie, bool, error) {
+	if s.citySettings(ctx, city).Disabled {
+		return nil, false, ErrCityDisabled
+	}
+
 	cachedMovies, cacheValid, err := s.loadFreshCache(ctx, city)
 	if err != nil {
 		return nil, false, err
 	}
 
 	if cacheValid {
+		s.counters.recordHit(city)
 		return cachedMovies, true, nil
 	}
 
+	if policy != StaleBlock && s.liveScraping(ctx, city) {
+		staleMovies, ok, err := s.serveStale(ctx, city, policy)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if ok {
+			s.counters.recordHit(city)
+			return staleMovies, true, nil
+		}
+	}
+
 	lock := s.cityLock(city)
 	lock.Lock()
 	defer lock.Unlock()
 
-	cachedMovies, cacheValid, err = s.loadFreshCache(ctx, city)
+	return s.loadLocked(ctx, city)
+}
+
+// loadLocked loads the city's movies while holding its lock, scraping them
+// unless another load saved fresh ones while this one waited.
+func (s *MovieService) loadLocked(ctx context.Context, city string) ([]Movie, bool, error) {
+	cachedMovies, cacheValid, err := s.loadFreshCache(ctx, city)
 	if err != nil {
 		return nil, false, err
 	}
 
 	if cacheValid {
+		s.counters.recordHit(city)
 		return cachedMovies, true, nil
 	}
 
-	s.logger.Printf("No cached data for %s, scraping...", city)
+	s.counters.recordMiss(city)
 
-	scrapedMovies, err := s.scraper.Scrape(ctx, city)
+	if !s.liveScraping(ctx, city) {
+		return s.loadStaleCache(ctx, city)
+	}
+
+	if s.awaitingReview(ctx, city) {
+		return s.loadWhileQuarantined(ctx, city)
+	}
+
+	s.logger.InfoContext(ctx, "no cached movies, scraping", "city", city)
+
+	scrapedMovies, err := s.scrape(ctx, city)
 	if err != nil {
 		return nil, false, fmt.Errorf("scrape movies: %w", err)
 	}
 
 	if len(scrapedMovies) == 0 {
-		return nil, false, fmt.Errorf("scrape movies: %w", errEmptyScrape)
+		return nil, false, fmt.Errorf("scrape movies: %w", ErrScrapeEmpty)
 	}
 
-	if err := s.repo.ReplaceCity(ctx, city, scrapedMovies, time.Now()); err != nil {
-		s.logger.Printf("Failed to save movies for %s: %v", city, err)
+	return s.publishScrape(ctx, city, scrapedMovies)
+}
+
+func (s *MovieService) Search(ctx context.Context, city string, req SearchRequest) (SearchResult, error) {
+	loadedMovies, fromCache, err := s.Load(ctx, city)
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	opts := SearchOptions{
+		MinScore:  s.minScore,
+		Fuzziness: req.Fuzziness,
+		Fields:    req.Fields,
+	}
+
+	query := s.resolveAlias(ctx, NormalizeQuery(req.Query))
+
+	var (
+		result []Movie
+		facets map[string]map[string]int
+	)
+
+	if s.flagEnabled(ctx, FlagSearchIndex, city, query, s.backend == SearchBackendIndex) {
+		result, facets, err = s.textIndexes.search(city, loadedMovies, query, opts)
+		if err != nil {
+			return SearchResult{}, err
+		}
 	} else {
-		s.logger.Printf("Saved %d movies to database for city: %s", len(scrapedMovies), city)
+		result = s.memo.search(city, loadedMovies, query, opts)
+	}
+
+	if len(result) > 0 {
+		s.popularity.record(city, result[0].Title)
+	}
+
+	s.recordSearch(ctx, SearchEvent{
+		City:            city,
+		RawQuery:        req.Query,
+		NormalizedQuery: strings.ToLower(foldForMatch(req.Query)),
+		ResultCount:     len(result),
+		SearchedAt:      time.Now(),
+		TopTitle:        topTitle(result),
+	})
+
+	searchResult := SearchResult{
+		Movies:    s.enrich(ctx, result),
+		FromCache: fromCache,
+		Facets:    facets,
+	}
+
+	if len(result) == 0 {
+		searchResult.DidYouMean = closestTitle(loadedMovies, query)
 	}
 
-	return scrapedMovies, false, nil
+	return searchResult, nil
 }
 
-func (s *movieService) loadFreshCache(ctx context.Context, city string) ([]Movie, bool, error) {
-	since := time.Now().Add(-s.cacheTTL)
+func (s *MovieService) loadFreshCache(ctx context.Context, city string) ([]Movie, bool, error) {
+	since := time.Now().Add(-s.cacheTTLFor(ctx, city))
 
 	cachedMovies, err := s.repo.ListFresh(ctx, city, since)
 	if err != nil {
@@ -88,13 +356,196 @@ func (s *movieService) loadFreshCache(ctx context.Context, city string) ([]Movie
 	return cachedMovies, cacheValid, nil
 }
 
-func (s *movieService) cityLock(city string) *sync.Mutex {
+// LastModified returns when the movies Load serves for the city were scraped.
+// It reports false when there are none, the city is disabled or Load would
+// scrape them again, so conditional requests are only answered from movies
+// that will be served.
+func (s *MovieService) LastModified(ctx context.Context, city string) (time.Time, bool, error) {
+	scrapedAt, ok, err := s.repo.LastScrape(ctx, city)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("query last scrape: %w", err)
+	}
+
+	expired := time.Since(scrapedAt) >= s.cacheTTLFor(ctx, city)
+	if !ok || s.citySettings(ctx, city).Disabled || (expired && s.liveScraping(ctx, city) && s.stalePolicyFor(ctx, city) == StaleBlock) {
+		return time.Time{}, false, nil
+	}
+
+	return scrapedAt, true, nil
+}
+
+// loadStaleCache serves whatever movies were last saved for the city,
+// regardless of age, while scraping is paused or disabled, for the server or
+// by the FlagLiveScraping flag.
+func (s *MovieService) loadStaleCache(ctx context.Context, city string) ([]Movie, bool, error) {
+	staleMovies, err := s.repo.ListFresh(ctx, city, time.Time{})
+	if err != nil {
+		return nil, false, fmt.Errorf("query cached movies: %w", err)
+	}
+
+	paused := s.scrapingPaused.Load() && !s.scrapingDisabled
+
+	if len(staleMovies) == 0 {
+		if !paused {
+			return nil, false, ErrScrapingDisabled
+		}
+
+		return nil, false, ErrScrapingPaused
+	}
+
+	// Where scraping is disabled, stale movies are served on every request,
+	// so only a pause, which is meant to be temporary, is worth a warning.
+	if paused {
+		s.logger.WarnContext(ctx, "scraping paused, serving stale movies", "city", city, "movies", len(staleMovies))
+	}
+
+	NoteStale(ctx)
+
+	return staleMovies, true, nil
+}
+
+func (s *MovieService) PauseScraping() {
+	if s.scrapingPaused.CompareAndSwap(false, true) {
+		s.logger.Warn("scraping paused")
+	}
+}
+
+func (s *MovieService) ResumeScraping() {
+	if s.scrapingPaused.CompareAndSwap(true, false) {
+		s.logger.Info("scraping resumed")
+	}
+}
+
+// ScrapingPaused reports whether scraping is paused, which it always is when
+// the service was created with scraping disabled.
+func (s *MovieService) ScrapingPaused() bool {
+	return s.scrapingDisabled || s.scrapingPaused.Load()
+}
+
+// scrape runs the scraper once a scrape slot is free, aborting it if the
+// service shuts down first.
+func (s *MovieService) scrape(ctx context.Context, city string) ([]Movie, error) {
+	s.shutdownMu.Lock()
+	if s.shutdownCtx.Err() != nil {
+		s.shutdownMu.Unlock()
+		return nil, ErrShuttingDown
+	}
+	s.scrapes.Add(1)
+	s.shutdownMu.Unlock()
+
+	defer s.scrapes.Done()
+
+	runID := logging.NewID()
+	ctx = logging.WithScrapeRunID(ctx, runID)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stop := context.AfterFunc(s.shutdownCtx, cancel)
+	defer stop()
+
+	if s.scrapeSlots != nil {
+		release, err := s.acquireScrapeSlot(ctx, city)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	startedAt := time.Now()
+	s.logger.InfoContext(ctx, "scrape started", "city", city)
+
+	scrapedMovies, err := s.scraper.Scrape(ctx, city)
+
+	run := ScrapeRun{
+		ID:         runID,
+		City:       city,
+		StartedAt:  startedAt,
+		DurationMs: time.Since(startedAt).Milliseconds(),
+		MovieCount: len(scrapedMovies),
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+	s.scrapeHistory.record(run)
+
+	if err != nil {
+		s.logger.ErrorContext(ctx, "scrape failed", "city", city, "duration", time.Since(startedAt), "error", err)
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "scrape finished", "city", city, "duration", time.Since(startedAt), "movies", len(scrapedMovies))
+
+	markRereleases(scrapedMovies, startedAt)
+
+	return scrapedMovies, nil
+}
+
+// acquireScrapeSlot waits for one of the limited Chrome slots, giving up with
+// ErrScrapeQueueFull once the queue timeout passes.
+func (s *MovieService) acquireScrapeSlot(ctx context.Context, city string) (func(), error) {
+	release := func() { <-s.scrapeSlots }
+
+	select {
+	case s.scrapeSlots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	s.logger.InfoContext(ctx, "waiting for a scrape slot", "city", city, "slots", cap(s.scrapeSlots))
+
+	var timeout <-chan time.Time
+	if s.queueTimeout > 0 {
+		timer := time.NewTimer(s.queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case s.scrapeSlots <- struct{}{}:
+		return release, nil
+	case <-timeout:
+		s.logger.WarnContext(ctx, "gave up waiting for a scrape slot", "city", city, "timeout", s.queueTimeout)
+		return nil, ErrScrapeQueueFull
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Shutdown cancels in-flight scrapes, refuses new ones, and waits until every
+// cancelled scrape has returned. Cancelled scrapes save nothing, so their
+// cities are scraped again on the next load.
+func (s *MovieService) Shutdown(ctx context.Context) error {
+	s.shutdownMu.Lock()
+	s.cancelShutdown()
+	s.shutdownMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.scrapes.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("wait for scrapes to stop: %w", ctx.Err())
+	}
+}
+
+func (s *MovieService) cityLock(city string) *sync.Mutex {
 	lock, _ := s.scrapeLocks.LoadOrStore(city, &sync.Mutex{})
 	return lock.(*sync.Mutex)
 }
 
-func (s *movieService) Preload(ctx context.Context, cities []string) error {
-	s.logger.Println("Starting initial movie scraping for cities:", cities)
+func (s *MovieService) Preload(ctx context.Context, cities []string) error {
+	if s.ScrapingPaused() {
+		s.logger.WarnContext(ctx, "scraping paused, skipping preload", "cities", cities)
+		return nil
+	}
+
+	s.logger.InfoContext(ctx, "preloading movies", "cities", cities)
 
 	var preloadErrs []error
 
@@ -103,22 +554,34 @@ func (s *movieService) Preload(ctx context.Context, cities []string) error {
 			return err
 		}
 
-		loadedMovies, fromCache, err := s.Load(ctx, city)
+		if s.citySettings(ctx, city).Disabled {
+			s.logger.InfoContext(ctx, "city disabled, skipping preload", "city", city)
+			continue
+		}
+
+		if !s.liveScraping(ctx, city) {
+			s.logger.InfoContext(ctx, "live scraping off for city, skipping preload", "city", city)
+			continue
+		}
+
+		// Preloading is what keeps cities fresh, so it scrapes expired
+		// cities whatever their stale policy.
+		loadedMovies, fromCache, err := s.load(ctx, city, StaleBlock)
 		if err != nil {
-			s.logger.Printf("Failed to load movies for %s: %v", city, err)
+			s.logger.ErrorContext(ctx, "failed to preload movies", "city", city, "error", err)
 			preloadErrs = append(preloadErrs, fmt.Errorf("%s: %w", city, err))
 			continue
 		}
 
 		if fromCache {
-			s.logger.Printf("Found %d cached movies for %s (within 24 hours), skipping scrape", len(loadedMovies), city)
+			s.logger.InfoContext(ctx, "movies already cached, skipping scrape", "city", city, "movies", len(loadedMovies))
 			continue
 		}
 
-		s.logger.Printf("Successfully preloaded %d movies for %s", len(loadedMovies), city)
+		s.logger.InfoContext(ctx, "preloaded movies", "city", city, "movies", len(loadedMovies))
 	}
 
-	s.logger.Println("Initial movie scraping completed")
+	s.logger.InfoContext(ctx, "preload completed", "cities", cities)
 
 	return errors.Join(preloadErrs...)
 }
@@ -2,9 +2,11 @@ package movies
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"io"
-	"log"
+	"log/slog"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -18,11 +20,19 @@ type fakeRepository struct {
 	hasFresh        bool
 	hasFreshErr     error
 	replaceErr      error
+	scrapes         []CityScrape
 
 	replaceCalls int
 	replacedCity string
 	replacedAt   time.Time
 	replacedWith []Movie
+
+	deleteCount   int64
+	deletedBefore time.Time
+
+	sightings []Sighting
+	listings  []Listing
+	events    []ListingEvent
 }
 
 func (f *fakeRepository) ListFresh(_ context.Context, _ string, _ time.Time) ([]Movie, error) {
@@ -66,6 +76,104 @@ func (f *fakeRepository) ReplaceCity(_ context.Context, city string, list []Movi
 	return f.replaceErr
 }
 
+func (f *fakeRepository) ListScrapes(_ context.Context) ([]CityScrape, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]CityScrape(nil), f.scrapes...), nil
+}
+
+func (f *fakeRepository) LastScrape(_ context.Context, city string) (time.Time, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, scrape := range f.scrapes {
+		if scrape.City == city {
+			return scrape.ScrapedAt, true, nil
+		}
+	}
+
+	return time.Time{}, false, nil
+}
+
+func (f *fakeRepository) DeleteScrapedBefore(_ context.Context, before time.Time) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.deletedBefore = before
+	return f.deleteCount, nil
+}
+
+func (f *fakeRepository) ListEvents(_ context.Context, _ string, _ int64, _ int) ([]ListingEvent, error) {
+	return nil, nil
+}
+
+func (f *fakeRepository) ListEventsBefore(_ context.Context, _ string, before time.Time) ([]ListingEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var events []ListingEvent
+	for _, event := range f.events {
+		if event.OccurredAt.Before(before) {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func (f *fakeRepository) ListChanges(_ context.Context, city string, since time.Time) (Changes, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var changes Changes
+	for _, sighting := range f.sightings {
+		if sighting.City != city {
+			continue
+		}
+
+		if sighting.FirstSeenAt.After(since) {
+			changes.Added = append(changes.Added, sighting)
+		}
+	}
+
+	return changes, nil
+}
+
+func (f *fakeRepository) ListSightings(_ context.Context, city string, since time.Time, limit int) ([]Sighting, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []Sighting
+	for _, sighting := range f.sightings {
+		if sighting.City == city && sighting.FirstSeenAt.After(since) && (limit == 0 || len(result) < limit) {
+			result = append(result, sighting)
+		}
+	}
+
+	return result, nil
+}
+
+func (f *fakeRepository) RandomMovie(_ context.Context, _ string, filter MovieFilter) (Movie, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, movie := range f.listFreshMovies {
+		if filter.Matches(movie) {
+			return movie, true, nil
+		}
+	}
+
+	return Movie{}, false, nil
+}
+
+func (f *fakeRepository) ListListings(_ context.Context) ([]Listing, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Listing(nil), f.listings...), nil
+}
+
 type fakeScraper struct {
 	mu sync.Mutex
 
@@ -78,7 +186,7 @@ type fakeScraper struct {
 	release <-chan struct{}
 }
 
-func (f *fakeScraper) Scrape(_ context.Context, city string) ([]Movie, error) {
+func (f *fakeScraper) Scrape(ctx context.Context, city string) ([]Movie, error) {
 	f.mu.Lock()
 	f.calls++
 	f.city = city
@@ -96,7 +204,11 @@ func (f *fakeScraper) Scrape(_ context.Context, city string) ([]Movie, error) {
 	}
 
 	if release != nil {
-		<-release
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
 	}
 
 	if err != nil {
@@ -106,8 +218,8 @@ func (f *fakeScraper) Scrape(_ context.Context, city string) ([]Movie, error) {
 	return movies, nil
 }
 
-func testLogger() *log.Logger {
-	return log.New(io.Discard, "", 0)
+func testLogger() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
 }
 
 func TestMovieServiceLoadReturnsFreshCache(t *testing.T) {
@@ -118,7 +230,7 @@ func TestMovieServiceLoadReturnsFreshCache(t *testing.T) {
 		hasFresh:        true,
 	}
 	scraper := &fakeScraper{}
-	service := NewMovieService(repo, scraper, 24*time.Hour, testLogger())
+	service := NewMovieService(repo, scraper, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
 
 	got, fromCache, err := service.Load(context.Background(), "cuttack")
 	if err != nil {
@@ -149,7 +261,7 @@ func TestMovieServiceLoadScrapesAndSavesOnCacheMiss(t *testing.T) {
 	scraper := &fakeScraper{
 		movies: []Movie{{Title: "Fresh", Href: "/fresh"}},
 	}
-	service := NewMovieService(repo, scraper, 24*time.Hour, testLogger())
+	service := NewMovieService(repo, scraper, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
 
 	got, fromCache, err := service.Load(context.Background(), "bhubaneswar")
 	if err != nil {
@@ -181,12 +293,39 @@ func TestMovieServiceLoadScrapesAndSavesOnCacheMiss(t *testing.T) {
 	}
 }
 
+func TestMovieServiceLoadMarksRereleases(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	scraper := &fakeScraper{
+		movies: []Movie{
+			{Title: "Interstellar", Href: "/interstellar", Year: 2014},
+			{Title: "New", Href: "/new", Year: now.Year()},
+			{Title: "Last Year", Href: "/last-year", Year: now.Year() - 1},
+			{Title: "Unknown", Href: "/unknown"},
+		},
+	}
+	service := NewMovieService(&fakeRepository{}, scraper, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
+
+	got, _, err := service.Load(context.Background(), "bhubaneswar")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := map[string]bool{"Interstellar": true, "New": false, "Last Year": false, "Unknown": false}
+	for _, movie := range got {
+		if movie.Rerelease != want[movie.Title] {
+			t.Errorf("%s Rerelease = %v, want %v", movie.Title, movie.Rerelease, want[movie.Title])
+		}
+	}
+}
+
 func TestMovieServiceLoadReturnsScrapeError(t *testing.T) {
 	t.Parallel()
 
 	repo := &fakeRepository{}
 	scraper := &fakeScraper{err: errors.New("network down")}
-	service := NewMovieService(repo, scraper, 24*time.Hour, testLogger())
+	service := NewMovieService(repo, scraper, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
 
 	_, _, err := service.Load(context.Background(), "cuttack")
 	if err == nil {
@@ -207,11 +346,11 @@ func TestMovieServiceLoadRejectsEmptyScrape(t *testing.T) {
 
 	repo := &fakeRepository{}
 	scraper := &fakeScraper{}
-	service := NewMovieService(repo, scraper, 24*time.Hour, testLogger())
+	service := NewMovieService(repo, scraper, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
 
 	_, _, err := service.Load(context.Background(), "cuttack")
-	if !errors.Is(err, errEmptyScrape) {
-		t.Fatalf("Load() error = %v, want errEmptyScrape", err)
+	if !errors.Is(err, ErrScrapeEmpty) {
+		t.Fatalf("Load() error = %v, want ErrScrapeEmpty", err)
 	}
 
 	if repo.replaceCalls != 0 {
@@ -229,7 +368,7 @@ func TestMovieServiceLoadCoalescesConcurrentScrapes(t *testing.T) {
 		started: make(chan struct{}, 1),
 		release: release,
 	}
-	service := NewMovieService(repo, scraper, 24*time.Hour, testLogger())
+	service := NewMovieService(repo, scraper, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
 
 	type result struct {
 		movies    []Movie
@@ -288,7 +427,7 @@ func TestMovieServiceLoadReturnsMoviesWhenSaveFails(t *testing.T) {
 	scraper := &fakeScraper{
 		movies: []Movie{{Title: "Fresh", Href: "/fresh"}},
 	}
-	service := NewMovieService(repo, scraper, 24*time.Hour, testLogger())
+	service := NewMovieService(repo, scraper, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
 
 	got, fromCache, err := service.Load(context.Background(), "cuttack")
 	if err != nil {
@@ -307,3 +446,1409 @@ func TestMovieServiceLoadReturnsMoviesWhenSaveFails(t *testing.T) {
 		t.Fatalf("Load() returned %+v, want scraped movies", got)
 	}
 }
+
+func TestMovieServiceStatsCountsHitsAndMisses(t *testing.T) {
+	t.Parallel()
+
+	scrapedAt := time.Now().Add(-time.Hour)
+	repo := &fakeRepository{
+		scrapes: []CityScrape{{City: "cuttack", ScrapedAt: scrapedAt, MovieCount: 3}},
+	}
+	scraper := &fakeScraper{
+		movies: []Movie{{Title: "Fresh", Href: "/fresh"}},
+	}
+	service := NewMovieService(repo, scraper, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
+
+	for range 3 {
+		if _, _, err := service.Load(context.Background(), "cuttack"); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+	}
+
+	stats, err := service.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Fatalf("Stats() hits/misses = %d/%d, want 2/1", stats.Hits, stats.Misses)
+	}
+
+	if len(stats.Cities) != 1 {
+		t.Fatalf("Stats() cities = %+v, want one city", stats.Cities)
+	}
+
+	city := stats.Cities[0]
+	if city.City != "cuttack" || city.MovieCount != 3 || !city.Fresh {
+		t.Fatalf("Stats() city = %+v, want fresh cuttack entry with 3 movies", city)
+	}
+
+	if city.AgeSeconds < time.Hour.Seconds() {
+		t.Fatalf("Stats() age = %f, want at least one hour", city.AgeSeconds)
+	}
+}
+
+func TestMovieServiceSearchMemoizesUntilSnapshotChanges(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{
+		listFreshMovies: []Movie{
+			{Title: "Interstellar", Href: "/interstellar"},
+			{Title: "Ballerina", Href: "/ballerina"},
+		},
+		hasFresh: true,
+	}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
+
+	result, err := service.Search(context.Background(), "cuttack", SearchRequest{Query: "Interstellar"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	got := result.Movies
+
+	if len(got) != 1 || got[0].Href != "/interstellar" {
+		t.Fatalf("Search() = %+v, want Interstellar", got)
+	}
+
+	repo.mu.Lock()
+	repo.listFreshMovies = []Movie{{Title: "Interstellar", Href: "/interstellar-rerelease"}}
+	repo.mu.Unlock()
+
+	result, err = service.Search(context.Background(), "cuttack", SearchRequest{Query: "Interstellar"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	got = result.Movies
+
+	if len(got) != 1 || got[0].Href != "/interstellar-rerelease" {
+		t.Fatalf("Search() after refresh = %+v, want re-release entry", got)
+	}
+}
+
+func TestMovieServiceSuggestRanksByPopularity(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{
+		listFreshMovies: []Movie{
+			{Title: "Dragon Ball Super", Href: "/dbs"},
+			{Title: "How to Train Your Dragon", Href: "/httyd"},
+			{Title: "Devara Part 1", Href: "/devara"},
+		},
+		hasFresh: true,
+	}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
+
+	if _, err := service.Search(context.Background(), "cuttack", SearchRequest{Query: "How to Train Your Dragon"}); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	got, err := service.Suggest(context.Background(), "cuttack", "drag", 10)
+	if err != nil {
+		t.Fatalf("Suggest() error = %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("Suggest() = %+v, want 2 suggestions", got)
+	}
+
+	if got[0].Href != "/httyd" || got[0].Popularity != 1 {
+		t.Fatalf("first suggestion = %+v, want searched title first", got[0])
+	}
+
+	if got[1].Href != "/dbs" {
+		t.Fatalf("second suggestion = %+v, want Dragon Ball Super", got[1])
+	}
+
+	got, err = service.Suggest(context.Background(), "cuttack", "drag", 1)
+	if err != nil {
+		t.Fatalf("Suggest() error = %v", err)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("Suggest() with limit 1 = %+v, want 1 suggestion", got)
+	}
+}
+
+type fakeSearchLog struct {
+	events      chan SearchEvent
+	clicks      chan ClickEvent
+	deleteCount int64
+}
+
+func (f *fakeSearchLog) RecordSearch(_ context.Context, event SearchEvent) error {
+	f.events <- event
+	return nil
+}
+
+func (f *fakeSearchLog) SummarizeSearches(_ context.Context, _ string, _ time.Time, _ int) (SearchSummary, error) {
+	return SearchSummary{}, nil
+}
+
+func (f *fakeSearchLog) ExportSearches(context.Context, string, time.Time, func(SearchEvent) error) error {
+	return nil
+}
+
+func (f *fakeSearchLog) RecordClick(_ context.Context, event ClickEvent) error {
+	f.clicks <- event
+	return nil
+}
+
+func (f *fakeSearchLog) ListDailyClicks(_ context.Context, _ string, _ time.Time) ([]DailyClicks, error) {
+	return nil, nil
+}
+
+func (f *fakeSearchLog) Trending(_ context.Context, city string, since time.Time, _ int) (Trending, error) {
+	return Trending{City: city, Since: since}, nil
+}
+
+func (f *fakeSearchLog) DeleteSearchesBefore(_ context.Context, _ time.Time) (int64, error) {
+	return f.deleteCount, nil
+}
+
+func TestMovieServiceSearchRecordsEvent(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{
+		listFreshMovies: []Movie{{Title: "Ballerina", Href: "/ballerina"}},
+		hasFresh:        true,
+	}
+	searchLog := &fakeSearchLog{events: make(chan SearchEvent, 1)}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{
+		CacheTTL:  24 * time.Hour,
+		SearchLog: searchLog,
+	}, testLogger())
+
+	if _, err := service.Search(context.Background(), "cuttack", SearchRequest{Query: "  HTTYD&nbsp;2 "}); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	select {
+	case event := <-searchLog.events:
+		if event.City != "cuttack" || event.NormalizedQuery != "httyd 2" || event.ResultCount != 0 {
+			t.Fatalf("recorded event = %+v, want zero-result httyd 2 search in cuttack", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RecordSearch() was not called")
+	}
+}
+
+func TestMovieServiceRecordClickRequiresListedMovie(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{
+		listFreshMovies: []Movie{{Title: "Ballerina", Href: "/movies/cuttack/ballerina/ET00371208"}},
+		hasFresh:        true,
+	}
+	searchLog := &fakeSearchLog{clicks: make(chan ClickEvent, 1)}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{
+		CacheTTL:  24 * time.Hour,
+		SearchLog: searchLog,
+	}, testLogger())
+
+	if err := service.RecordClick(context.Background(), "cuttack", "ET00375421"); !errors.Is(err, ErrMovieNotListed) {
+		t.Fatalf("RecordClick() error = %v, want ErrMovieNotListed", err)
+	}
+
+	if err := service.RecordClick(context.Background(), "cuttack", "et00371208"); err != nil {
+		t.Fatalf("RecordClick() error = %v", err)
+	}
+
+	select {
+	case event := <-searchLog.clicks:
+		if event.City != "cuttack" || event.Title != "Ballerina" {
+			t.Fatalf("recorded click = %+v, want Ballerina in cuttack", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RecordClick() was not called on the search log")
+	}
+}
+
+func TestMovieServiceFollowLinkWorksWithoutSearchLog(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{
+		listFreshMovies: []Movie{{Title: "F1: The Movie", Href: "https://in.bookmyshow.com/movies/cuttack/f1-the-movie/ET00403839"}},
+		hasFresh:        true,
+	}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
+
+	movie, err := service.FollowLink(context.Background(), "cuttack", "ET00403839")
+	if err != nil || movie.Title != "F1: The Movie" {
+		t.Fatalf("FollowLink() = %+v, %v, want F1: The Movie", movie, err)
+	}
+
+	if _, err := service.FollowLink(context.Background(), "cuttack", "ET00000001"); !errors.Is(err, ErrMovieNotListed) {
+		t.Fatalf("FollowLink() error = %v, want ErrMovieNotListed", err)
+	}
+}
+
+func TestMovieServiceFollowLinkRecordsClicks(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{
+		listFreshMovies: []Movie{{Title: "F1: The Movie", Href: "https://in.bookmyshow.com/movies/cuttack/f1-the-movie/ET00403839"}},
+		hasFresh:        true,
+	}
+	searchLog := &fakeSearchLog{clicks: make(chan ClickEvent, 2)}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{CacheTTL: 24 * time.Hour, SearchLog: searchLog}, testLogger())
+
+	if _, err := service.FollowLink(context.Background(), "cuttack", "ET00403839"); err != nil {
+		t.Fatalf("FollowLink() error = %v", err)
+	}
+
+	select {
+	case event := <-searchLog.clicks:
+		if (Movie{Href: event.Href}).ID() != "ET00403839" {
+			t.Fatalf("recorded click = %+v, want F1", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RecordClick() was not called")
+	}
+
+	select {
+	case event := <-searchLog.clicks:
+		t.Fatalf("recorded a second click %+v, want each click recorded once", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, err := NewMovieService(repo, &fakeScraper{}, ServiceOptions{}, testLogger()).PopularMovies(context.Background(), "cuttack", time.Now(), 10); !errors.Is(err, ErrSearchLogDisabled) {
+		t.Fatalf("PopularMovies() without a search log error = %v, want ErrSearchLogDisabled", err)
+	}
+}
+
+func TestMovieIDUsesEventCode(t *testing.T) {
+	t.Parallel()
+
+	renamed := Movie{Href: "https://in.bookmyshow.com/movies/mumbai/f1/ET00403839"}
+	if got := renamed.ID(); got != "ET00403839" {
+		t.Fatalf("ID() = %q, want ET00403839", got)
+	}
+
+	other := Movie{Href: "/ballerina"}
+	if got := other.ID(); got == "" || got != (Movie{Title: "Ballerina", Href: "/ballerina"}).ID() {
+		t.Fatalf("ID() = %q, want a stable hash of the link", got)
+	}
+
+	content, err := json.Marshal(renamed)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	if !strings.Contains(string(content), `"id":"ET00403839"`) {
+		t.Fatalf("Marshal() = %s, want the id included", content)
+	}
+}
+
+func TestParseID(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{in: "ET00403839", want: "ET00403839", ok: true},
+		{in: " et00403839 ", want: "ET00403839", ok: true},
+		{in: "4F1A2B3C4D5E6F70", want: "4f1a2b3c4d5e6f70", ok: true},
+		{in: "ET", ok: false},
+		{in: "f1-the-movie", ok: false},
+		{in: "4f1a2b3c", ok: false},
+	} {
+		got, ok := ParseID(tc.in)
+		if ok != tc.ok || (ok && got != tc.want) {
+			t.Errorf("ParseID(%q) = %q, %v, want %q, %v", tc.in, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+type fakeAliasStore struct {
+	mu      sync.Mutex
+	aliases map[string]string
+}
+
+func (f *fakeAliasStore) ListAliases(_ context.Context) ([]Alias, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	result := make([]Alias, 0, len(f.aliases))
+	for alias, canonical := range f.aliases {
+		result = append(result, Alias{Alias: alias, Canonical: canonical})
+	}
+
+	return result, nil
+}
+
+func (f *fakeAliasStore) UpsertAlias(_ context.Context, alias Alias) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.aliases[alias.Alias] = alias.Canonical
+	return nil
+}
+
+func (f *fakeAliasStore) DeleteAlias(_ context.Context, alias string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, ok := f.aliases[alias]
+	delete(f.aliases, alias)
+
+	return ok, nil
+}
+
+func TestMovieServiceSearchResolvesAliases(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{
+		listFreshMovies: []Movie{
+			{Title: "Kalki 2898 AD", Href: "/kalki"},
+			{Title: "Ballerina", Href: "/ballerina"},
+		},
+		hasFresh: true,
+	}
+	aliases := &fakeAliasStore{aliases: map[string]string{}}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{
+		CacheTTL: 24 * time.Hour,
+		Aliases:  aliases,
+	}, testLogger())
+
+	result, err := service.Search(context.Background(), "cuttack", SearchRequest{Query: "Project K"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if got := result.Movies; len(got) != 0 {
+		t.Fatalf("Search() before alias = %+v, want no matches", got)
+	}
+
+	if _, err := service.AddAlias(context.Background(), "Project K", "Kalki 2898 AD"); err != nil {
+		t.Fatalf("AddAlias() error = %v", err)
+	}
+
+	result, err = service.Search(context.Background(), "cuttack", SearchRequest{Query: "project k"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if got := result.Movies; len(got) == 0 || got[0].Href != "/kalki" {
+		t.Fatalf("Search() after alias = %+v, want Kalki 2898 AD", got)
+	}
+
+	removed, err := service.RemoveAlias(context.Background(), "PROJECT K")
+	if err != nil || !removed {
+		t.Fatalf("RemoveAlias() = %v, %v, want true, nil", removed, err)
+	}
+}
+
+func TestMovieServiceSearchSuggestsClosestTitleOnZeroResults(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{
+		listFreshMovies: []Movie{
+			{Title: "Interstellar", Href: "/interstellar"},
+			{Title: "Ballerina", Href: "/ballerina"},
+		},
+		hasFresh: true,
+	}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
+
+	result, err := service.Search(context.Background(), "cuttack", SearchRequest{Query: "Balerinq", Fuzziness: 0})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if len(result.Movies) != 0 {
+		t.Fatalf("Search() movies = %+v, want none", result.Movies)
+	}
+
+	if result.DidYouMean != "Ballerina" {
+		t.Fatalf("Search() did_you_mean = %q, want %q", result.DidYouMean, "Ballerina")
+	}
+
+	result, err = service.Search(context.Background(), "cuttack", SearchRequest{Query: "Zzzzzzzz", Fuzziness: 0})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if result.DidYouMean != "" {
+		t.Fatalf("Search() did_you_mean = %q, want none for unrelated query", result.DidYouMean)
+	}
+}
+
+func TestMovieServiceSearchWithIndexBackendReturnsFacets(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{
+		listFreshMovies: []Movie{
+			{Title: "How to Train Your Dragon", Href: "/httyd", Languages: []string{"English"}},
+			{Title: "Dragons: The Nine Realms", Href: "/realms", Languages: []string{"English", "Hindi"}},
+			{Title: "Ballerina", Href: "/ballerina", Languages: []string{"English"}},
+		},
+		hasFresh: true,
+	}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{
+		CacheTTL:      24 * time.Hour,
+		SearchBackend: SearchBackendIndex,
+	}, testLogger())
+
+	result, err := service.Search(context.Background(), "cuttack", SearchRequest{Query: "dragons"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if len(result.Movies) != 2 {
+		t.Fatalf("Search() movies = %+v, want both dragon titles", result.Movies)
+	}
+
+	if got := result.Facets[FieldLanguages]; got["English"] != 2 || got["Hindi"] != 1 {
+		t.Fatalf("Search() language facets = %+v, want English: 2, Hindi: 1", got)
+	}
+}
+
+func TestMovieServiceSearchWithIndexBackendScoresAgainstTheBestMatch(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{
+		listFreshMovies: []Movie{
+			{Title: "How to Train Your Dragon", Href: "/httyd"},
+			{Title: "Dragons: The Nine Realms", Href: "/realms"},
+			{Title: "Pokémon: The First Movie", Href: "/pokemon"},
+		},
+		hasFresh: true,
+	}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{
+		CacheTTL:       24 * time.Hour,
+		SearchBackend:  SearchBackendIndex,
+		SearchMinScore: 50,
+	}, testLogger())
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "pokemon", want: []string{"Pokémon: The First Movie"}},
+		{query: "train drag", want: []string{"How to Train Your Dragon"}},
+		{query: "drag", want: []string{"How to Train Your Dragon", "Dragons: The Nine Realms"}},
+		{query: "dragon submarine", want: nil},
+	}
+
+	for _, tt := range tests {
+		result, err := service.Search(context.Background(), "cuttack", SearchRequest{Query: tt.query})
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", tt.query, err)
+		}
+
+		var titles []string
+		for _, movie := range result.Movies {
+			titles = append(titles, movie.Title)
+		}
+		if !slices.Equal(titles, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, titles, tt.want)
+		}
+		if len(result.Movies) > 0 && result.Movies[0].Score != 100 {
+			t.Errorf("Search(%q) best score = %d, want 100", tt.query, result.Movies[0].Score)
+		}
+	}
+}
+
+func TestMovieServiceCleanupDeletesStaleData(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{deleteCount: 3}
+	requestLog := &fakeRequestLog{}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{
+		CacheTTL:   24 * time.Hour,
+		SearchLog:  &fakeSearchLog{deleteCount: 5},
+		RequestLog: requestLog,
+	}, testLogger())
+
+	before := time.Now().Add(-30 * 24 * time.Hour)
+	if err := service.Cleanup(context.Background(), before); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+
+	if !repo.deletedBefore.Equal(before) {
+		t.Fatalf("DeleteScrapedBefore() cutoff = %v, want %v", repo.deletedBefore, before)
+	}
+
+	if !requestLog.deletedBefore.Equal(before) {
+		t.Fatalf("DeleteRequestsBefore() cutoff = %v, want %v", requestLog.deletedBefore, before)
+	}
+}
+
+type fakeRequestLog struct {
+	events        chan RequestEvent
+	deletedBefore time.Time
+}
+
+func (f *fakeRequestLog) RecordRequest(_ context.Context, event RequestEvent) error {
+	f.events <- event
+	return nil
+}
+
+func (f *fakeRequestLog) SummarizeTraffic(_ context.Context, _ time.Time) ([]TrafficDay, error) {
+	return []TrafficDay{{Date: "2025-07-14", City: "cuttack", Requests: 3}}, nil
+}
+
+func (f *fakeRequestLog) DeleteRequestsBefore(_ context.Context, before time.Time) (int64, error) {
+	f.deletedBefore = before
+	return 0, nil
+}
+
+func TestMovieServiceRecordsRequestsInBackground(t *testing.T) {
+	t.Parallel()
+
+	requestLog := &fakeRequestLog{events: make(chan RequestEvent, 1)}
+	service := NewMovieService(&fakeRepository{}, &fakeScraper{}, ServiceOptions{RequestLog: requestLog}, testLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	service.RecordRequest(ctx, RequestEvent{Endpoint: "GET /movies", City: "cuttack", Cache: CacheHit})
+	cancel()
+
+	select {
+	case event := <-requestLog.events:
+		if event.Endpoint != "GET /movies" || event.City != "cuttack" {
+			t.Fatalf("RecordRequest() event = %+v, want the GET /movies request", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RecordRequest() did not record the event")
+	}
+
+	summary, err := service.TrafficSummary(context.Background(), time.Now())
+	if err != nil || len(summary.Days) != 1 {
+		t.Fatalf("TrafficSummary() = %+v, %v, want one day", summary, err)
+	}
+}
+
+func TestMovieServiceTrafficSummaryDisabled(t *testing.T) {
+	t.Parallel()
+
+	service := NewMovieService(&fakeRepository{}, &fakeScraper{}, ServiceOptions{}, testLogger())
+
+	if _, err := service.TrafficSummary(context.Background(), time.Now()); !errors.Is(err, ErrTrafficLogDisabled) {
+		t.Fatalf("TrafficSummary() error = %v, want ErrTrafficLogDisabled", err)
+	}
+}
+
+func TestMovieServiceLoadServesStaleCacheWhilePaused(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{
+		listFreshMovies: []Movie{{Title: "Ballerina", Href: "/ballerina"}},
+	}
+	scraper := &fakeScraper{movies: []Movie{{Title: "Fresh", Href: "/fresh"}}}
+	service := NewMovieService(repo, scraper, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
+
+	service.PauseScraping()
+
+	loadedMovies, fromCache, err := service.Load(context.Background(), "cuttack")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if !fromCache || len(loadedMovies) != 1 || loadedMovies[0].Title != "Ballerina" {
+		t.Fatalf("Load() = %v, %v, want stale Ballerina from cache", loadedMovies, fromCache)
+	}
+
+	if scraper.calls != 0 {
+		t.Fatalf("scraper calls = %d, want 0 while paused", scraper.calls)
+	}
+
+	repo.listFreshMovies = nil
+	if _, _, err := service.Load(context.Background(), "cuttack"); !errors.Is(err, ErrScrapingPaused) {
+		t.Fatalf("Load() error = %v, want ErrScrapingPaused", err)
+	}
+
+	service.ResumeScraping()
+
+	if _, _, err := service.Load(context.Background(), "cuttack"); err != nil {
+		t.Fatalf("Load() after resume error = %v", err)
+	}
+
+	if scraper.calls != 1 {
+		t.Fatalf("scraper calls = %d, want 1 after resume", scraper.calls)
+	}
+}
+
+func TestMovieServiceLoadStalePolicies(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name       string
+		policy     StalePolicy
+		settings   []CitySettings
+		wantTitle  string
+		wantStale  bool
+		wantScrape bool
+	}{
+		{name: "block", policy: StaleBlock, wantTitle: "Fresh", wantScrape: true},
+		{name: "serve stale", policy: StaleServe, wantTitle: "Ballerina", wantStale: true},
+		{name: "serve stale and refresh", policy: StaleServeAndRefresh, wantTitle: "Ballerina", wantStale: true, wantScrape: true},
+		{
+			name:      "city override",
+			policy:    StaleBlock,
+			settings:  []CitySettings{{City: "cuttack", StalePolicy: StaleServe}},
+			wantTitle: "Ballerina",
+			wantStale: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := &fakeRepository{listFreshMovies: []Movie{{Title: "Ballerina", Href: "/ballerina"}}}
+			scraper := &fakeScraper{
+				movies:  []Movie{{Title: "Fresh", Href: "/fresh"}},
+				started: make(chan struct{}, 1),
+			}
+
+			service := NewMovieService(repo, scraper, ServiceOptions{
+				CacheTTL:     24 * time.Hour,
+				StalePolicy:  tc.policy,
+				CitySettings: &fakeCitySettingsStore{settings: tc.settings},
+			}, testLogger())
+
+			ctx, note := WithStaleNote(context.Background())
+			loadedMovies, _, err := service.Load(ctx, "cuttack")
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if len(loadedMovies) != 1 || loadedMovies[0].Title != tc.wantTitle {
+				t.Fatalf("Load() = %v, want %s", loadedMovies, tc.wantTitle)
+			}
+
+			if note.Stale != tc.wantStale {
+				t.Fatalf("stale = %v, want %v", note.Stale, tc.wantStale)
+			}
+
+			if !tc.wantScrape {
+				if scraper.calls != 0 {
+					t.Fatalf("scraper calls = %d, want 0", scraper.calls)
+				}
+				return
+			}
+
+			select {
+			case <-scraper.started:
+			case <-time.After(time.Second):
+				t.Fatal("city was not scraped")
+			}
+		})
+	}
+}
+
+func TestMovieServicePreloadScrapesStaleCities(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{listFreshMovies: []Movie{{Title: "Ballerina", Href: "/ballerina"}}}
+	scraper := &fakeScraper{movies: []Movie{{Title: "Fresh", Href: "/fresh"}}}
+	service := NewMovieService(repo, scraper, ServiceOptions{CacheTTL: 24 * time.Hour, StalePolicy: StaleServe}, testLogger())
+
+	if err := service.Preload(context.Background(), []string{"cuttack"}); err != nil {
+		t.Fatalf("Preload() error = %v", err)
+	}
+
+	if scraper.calls != 1 {
+		t.Fatalf("scraper calls = %d, want 1", scraper.calls)
+	}
+}
+
+func TestMovieServiceLoadNeverScrapesWhenDisabled(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{
+		listFreshMovies: []Movie{{Title: "Ballerina", Href: "/ballerina"}},
+	}
+	scraper := &fakeScraper{movies: []Movie{{Title: "Fresh", Href: "/fresh"}}}
+	service := NewMovieService(repo, scraper, ServiceOptions{
+		CacheTTL:         24 * time.Hour,
+		ScrapingDisabled: true,
+	}, testLogger())
+
+	service.ResumeScraping()
+
+	loadedMovies, fromCache, err := service.Load(context.Background(), "cuttack")
+	if err != nil || !fromCache || len(loadedMovies) != 1 {
+		t.Fatalf("Load() = %v, %v, %v, want stale Ballerina from cache", loadedMovies, fromCache, err)
+	}
+
+	repo.listFreshMovies = nil
+	if _, _, err := service.Load(context.Background(), "puri"); !errors.Is(err, ErrScrapingDisabled) {
+		t.Fatalf("Load() error = %v, want ErrScrapingDisabled", err)
+	}
+
+	if err := service.Preload(context.Background(), []string{"puri"}); err != nil {
+		t.Fatalf("Preload() error = %v, want nil", err)
+	}
+
+	if scraper.calls != 0 || !service.ScrapingPaused() {
+		t.Fatalf("scraper calls = %d, paused = %v, want no scrapes while disabled", scraper.calls, service.ScrapingPaused())
+	}
+}
+
+// fakeFlags turns on the flags in on for the listed cities, and leaves every
+// other flag at its fallback.
+type fakeFlags struct {
+	on map[string][]string
+}
+
+func (f fakeFlags) Enabled(_ context.Context, name, city, _ string, fallback bool) bool {
+	cities, ok := f.on[name]
+	if !ok {
+		return fallback
+	}
+
+	return slices.Contains(cities, city)
+}
+
+func TestMovieServiceFlagsControlScrapingAndSearchBackend(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{
+		listFreshMovies: []Movie{
+			{Title: "How to Train Your Dragon", Href: "/httyd", Languages: []string{"English"}},
+			{Title: "Dragons: The Nine Realms", Href: "/realms", Languages: []string{"English", "Hindi"}},
+		},
+	}
+	scraper := &fakeScraper{movies: []Movie{{Title: "Fresh", Href: "/fresh"}}}
+	service := NewMovieService(repo, scraper, ServiceOptions{
+		CacheTTL: 24 * time.Hour,
+		Flags: fakeFlags{on: map[string][]string{
+			FlagLiveScraping: {"cuttack"},
+			FlagSearchIndex:  {"puri"},
+		}},
+	}, testLogger())
+
+	loadedMovies, fromCache, err := service.Load(context.Background(), "puri")
+	if err != nil || !fromCache || len(loadedMovies) != 2 || scraper.calls != 0 {
+		t.Fatalf("Load(puri) = %d movies, %v, %v with %d scrapes, want saved movies without scraping", len(loadedMovies), fromCache, err, scraper.calls)
+	}
+
+	result, err := service.Search(context.Background(), "puri", SearchRequest{Query: "dragons"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if result.Facets == nil {
+		t.Fatal("Search() facets = nil, want the index backend's facets where search_index is on")
+	}
+
+	if _, _, err := service.Load(context.Background(), "cuttack"); err != nil || scraper.calls != 1 {
+		t.Fatalf("Load(cuttack) error = %v with %d scrapes, want one scrape where live_scraping is on", err, scraper.calls)
+	}
+}
+
+func TestMovieServiceLimitsConcurrentScrapes(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	scraper := &fakeScraper{
+		movies:  []Movie{{Title: "Ballerina", Href: "/ballerina"}},
+		started: make(chan struct{}, 2),
+		release: release,
+	}
+	service := NewMovieService(&fakeRepository{}, scraper, ServiceOptions{
+		CacheTTL:             24 * time.Hour,
+		MaxConcurrentScrapes: 1,
+	}, testLogger())
+
+	var wg sync.WaitGroup
+	for _, city := range []string{"cuttack", "bhubaneswar"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, _ = service.Load(context.Background(), city)
+		}()
+	}
+
+	<-scraper.started
+
+	select {
+	case <-scraper.started:
+		t.Fatal("second scrape started while the only slot was taken")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	wg.Wait()
+
+	if scraper.calls != 2 {
+		t.Fatalf("scraper calls = %d, want 2", scraper.calls)
+	}
+}
+
+func TestMovieServiceTimesOutQueuedScrapes(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	scraper := &fakeScraper{
+		movies:  []Movie{{Title: "Ballerina", Href: "/ballerina"}},
+		started: make(chan struct{}, 1),
+		release: release,
+	}
+	service := NewMovieService(&fakeRepository{}, scraper, ServiceOptions{
+		CacheTTL:             24 * time.Hour,
+		MaxConcurrentScrapes: 1,
+		ScrapeQueueTimeout:   20 * time.Millisecond,
+	}, testLogger())
+
+	go func() { _, _, _ = service.Load(context.Background(), "cuttack") }()
+	<-scraper.started
+
+	if _, _, err := service.Load(context.Background(), "bhubaneswar"); !errors.Is(err, ErrScrapeQueueFull) {
+		t.Fatalf("Load() error = %v, want ErrScrapeQueueFull", err)
+	}
+}
+
+func TestMovieServiceShutdownCancelsInFlightScrapes(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{}
+	scraper := &fakeScraper{
+		movies:  []Movie{{Title: "Ballerina", Href: "/ballerina"}},
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+	service := NewMovieService(repo, scraper, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
+
+	loadErr := make(chan error, 1)
+	go func() {
+		_, _, err := service.Load(context.Background(), "cuttack")
+		loadErr <- err
+	}()
+
+	<-scraper.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := service.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	if err := <-loadErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Load() error = %v, want context.Canceled", err)
+	}
+
+	if repo.replaceCalls != 0 {
+		t.Fatalf("ReplaceCity() calls = %d, want 0 for a cancelled scrape", repo.replaceCalls)
+	}
+
+	if _, _, err := service.Load(context.Background(), "bhubaneswar"); !errors.Is(err, ErrShuttingDown) {
+		t.Fatalf("Load() after shutdown error = %v, want ErrShuttingDown", err)
+	}
+}
+
+func TestMovieServiceRecordsRecentScrapes(t *testing.T) {
+	t.Parallel()
+
+	scraper := &fakeScraper{err: errors.New("blocked")}
+	service := NewMovieService(&fakeRepository{}, scraper, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
+
+	_, _, _ = service.Load(context.Background(), "cuttack")
+
+	scraper.mu.Lock()
+	scraper.err = nil
+	scraper.movies = []Movie{{Title: "Ballerina", Href: "/ballerina"}}
+	scraper.mu.Unlock()
+
+	if _, _, err := service.Load(context.Background(), "bhubaneswar"); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	runs := service.RecentScrapes()
+	if len(runs) != 2 {
+		t.Fatalf("RecentScrapes() returned %d runs, want 2", len(runs))
+	}
+
+	if runs[0].City != "bhubaneswar" || runs[0].MovieCount != 1 || runs[0].Error != "" || runs[0].ID == "" {
+		t.Fatalf("newest run = %+v, want successful bhubaneswar scrape", runs[0])
+	}
+
+	if runs[1].City != "cuttack" || runs[1].Error != "blocked" {
+		t.Fatalf("oldest run = %+v, want failed cuttack scrape", runs[1])
+	}
+
+	stats, err := service.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+
+	for _, city := range stats.Cities {
+		wantStreak := 0
+		if city.City == "cuttack" {
+			wantStreak = 1
+		}
+
+		if city.FailureStreak != wantStreak {
+			t.Fatalf("Stats() %s failure streak = %d, want %d", city.City, city.FailureStreak, wantStreak)
+		}
+	}
+}
+
+type fakeRatings struct {
+	mu      sync.Mutex
+	lookups int
+	ratings map[string]*Ratings
+}
+
+func (f *fakeRatings) LookupRatings(_ context.Context, title string, _ int) (*Ratings, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.lookups++
+	return f.ratings[title], nil
+}
+
+type fakeRatingsStore struct {
+	mu     sync.Mutex
+	cached map[string]*Ratings
+}
+
+func (f *fakeRatingsStore) GetRatings(_ context.Context, title string, _ int, _ time.Time) (*Ratings, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ratings, ok := f.cached[title]
+	return ratings, ok, nil
+}
+
+func (f *fakeRatingsStore) SaveRatings(_ context.Context, title string, _ int, ratings *Ratings, _ time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.cached[title] = ratings
+	return nil
+}
+
+func TestMovieServiceSearchAddsCachedRatings(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{
+		listFreshMovies: []Movie{{Title: "Ballerina", Href: "/ballerina"}},
+		hasFresh:        true,
+	}
+	provider := &fakeRatings{ratings: map[string]*Ratings{
+		"Ballerina": {IMDbID: "tt7181546", IMDb: 6.9, RottenTomatoes: 76},
+	}}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{
+		CacheTTL:     24 * time.Hour,
+		Ratings:      provider,
+		RatingsStore: &fakeRatingsStore{cached: map[string]*Ratings{}},
+	}, testLogger())
+
+	for range 2 {
+		result, err := service.Search(context.Background(), "cuttack", SearchRequest{Query: "ballerina"})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+
+		if got := result.Movies; len(got) != 1 || got[0].Ratings == nil || got[0].Ratings.RottenTomatoes != 76 {
+			t.Fatalf("Search() = %+v, want Ballerina with ratings", got)
+		}
+	}
+
+	if provider.lookups != 1 {
+		t.Fatalf("lookups = %d, want 1 with the second search served from cache", provider.lookups)
+	}
+
+	result, err := service.Search(context.Background(), "cuttack", SearchRequest{Query: "ballerina"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if got := result.Movies[0]; got.IMDbURL != "https://www.imdb.com/title/tt7181546/" || got.LetterboxdURL != "https://letterboxd.com/imdb/tt7181546/" {
+		t.Fatalf("links = %q, %q, want IMDb and Letterboxd links for tt7181546", got.IMDbURL, got.LetterboxdURL)
+	}
+
+	movies, _, err := service.Load(context.Background(), "cuttack")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if movies[0].Ratings != nil {
+		t.Fatalf("Load() = %+v, want unrated movies", movies)
+	}
+}
+
+func TestValidIMDbID(t *testing.T) {
+	t.Parallel()
+
+	for id, want := range map[string]bool{
+		"tt7181546":  true,
+		"tt":         false,
+		"7181546":    false,
+		"tt718/../x": false,
+	} {
+		if got := validIMDbID(id); got != want {
+			t.Fatalf("validIMDbID(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+type fakeStreaming struct {
+	mu      sync.Mutex
+	lookups int
+}
+
+func (f *fakeStreaming) LookupStreaming(_ context.Context, _ string, _ int) (*Streaming, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.lookups++
+	return &Streaming{Available: true, Providers: []string{"Netflix"}}, nil
+}
+
+func TestMovieServiceSearchMarksStreamingTitles(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{
+		listFreshMovies: []Movie{{Title: "Ballerina", Href: "/ballerina"}},
+		hasFresh:        true,
+	}
+	provider := &fakeStreaming{}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{
+		CacheTTL:  24 * time.Hour,
+		Streaming: provider,
+	}, testLogger())
+
+	for range 2 {
+		result, err := service.Search(context.Background(), "cuttack", SearchRequest{Query: "ballerina"})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+
+		if got := result.Movies; len(got) != 1 || got[0].Streaming == nil || !got[0].Streaming.Available {
+			t.Fatalf("Search() = %+v, want Ballerina marked as streaming", got)
+		}
+	}
+
+	if provider.lookups != 1 {
+		t.Fatalf("lookups = %d, want 1 with the second search served from cache", provider.lookups)
+	}
+}
+
+type fakeTrailers struct {
+	mu      sync.Mutex
+	trailer *Trailer
+	lookups int
+}
+
+func (f *fakeTrailers) LookupTrailer(_ context.Context, _ string, _ int) (*Trailer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.lookups++
+	return f.trailer, nil
+}
+
+func TestMovieServiceTrailerCachesLookups(t *testing.T) {
+	t.Parallel()
+
+	movie := Movie{Title: "F1: The Movie", Href: "https://in.bookmyshow.com/movies/cuttack/f1-the-movie/ET00403839"}
+	repo := &fakeRepository{listFreshMovies: []Movie{movie}, hasFresh: true}
+
+	for _, tc := range []struct {
+		name    string
+		trailer *Trailer
+		wantErr error
+	}{
+		{name: "found", trailer: &Trailer{Title: "Official Trailer", URL: "https://www.youtube.com/watch?v=abc123"}},
+		{name: "not found", wantErr: ErrTrailerNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider := &fakeTrailers{trailer: tc.trailer}
+			service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{CacheTTL: 24 * time.Hour, Trailers: provider}, testLogger())
+
+			for range 2 {
+				trailer, err := service.Trailer(context.Background(), "cuttack", movie.ID())
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("Trailer() error = %v, want %v", err, tc.wantErr)
+				}
+
+				if tc.trailer != nil && trailer != *tc.trailer {
+					t.Fatalf("Trailer() = %+v, want %+v", trailer, *tc.trailer)
+				}
+			}
+
+			if provider.lookups != 1 {
+				t.Fatalf("lookups = %d, want 1 with the second call served from cache", provider.lookups)
+			}
+		})
+	}
+}
+
+func TestMovieServiceTrailerDisabled(t *testing.T) {
+	t.Parallel()
+
+	service := NewMovieService(&fakeRepository{}, &fakeScraper{}, ServiceOptions{}, testLogger())
+
+	if _, err := service.Trailer(context.Background(), "cuttack", "ET00403839"); !errors.Is(err, ErrTrailersDisabled) {
+		t.Fatalf("Trailer() error = %v, want ErrTrailersDisabled", err)
+	}
+}
+
+type fakeListener struct {
+	mu        sync.Mutex
+	city      string
+	refreshed int
+}
+
+func (f *fakeListener) MoviesRefreshed(_ context.Context, city string, _ []Movie) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.city = city
+	f.refreshed++
+}
+
+func TestMovieServiceNotifiesRefreshListeners(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{}
+	scraper := &fakeScraper{movies: []Movie{{Title: "Ballerina", Href: "/ballerina"}}}
+	service := NewMovieService(repo, scraper, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
+
+	listener := &fakeListener{}
+	service.AddRefreshListener(listener)
+
+	for range 2 {
+		repo.mu.Lock()
+		repo.hasFresh = false
+		repo.mu.Unlock()
+
+		if _, _, err := service.Load(context.Background(), "cuttack"); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+	}
+
+	if listener.refreshed != 2 || listener.city != "cuttack" {
+		t.Fatalf("listener = %+v, want one refresh of cuttack per saved scrape", listener)
+	}
+}
+
+func TestSightingIDIsStablePerCityAndLink(t *testing.T) {
+	t.Parallel()
+
+	first := Sighting{City: "cuttack", Movie: Movie{Title: "F1", Href: "/f1"}, FirstSeenAt: time.Now()}
+	renamed := Sighting{City: "cuttack", Movie: Movie{Title: "F1: The Movie", Href: "/f1"}}
+	elsewhere := Sighting{City: "mumbai", Movie: Movie{Title: "F1", Href: "/f1"}}
+
+	if first.ID() != renamed.ID() {
+		t.Fatalf("ID() = %q and %q, want the same ID for the same link", first.ID(), renamed.ID())
+	}
+
+	if first.ID() == elsewhere.ID() {
+		t.Fatalf("ID() = %q for both cities, want different IDs", first.ID())
+	}
+}
+
+func TestMovieServiceRandomMovieScrapesAndFilters(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{}
+	scraper := &fakeScraper{movies: []Movie{
+		{Title: "Sitaare Zameen Par", Href: "/sitaare", Languages: []string{"Hindi"}},
+		{Title: "Daskalia", Href: "/daskalia", Languages: []string{"Odia"}},
+	}}
+	service := NewMovieService(repo, scraper, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
+
+	movie, err := service.RandomMovie(context.Background(), "cuttack", MovieFilter{Languages: []string{"odia"}})
+	if err != nil {
+		t.Fatalf("RandomMovie() error = %v", err)
+	}
+
+	if movie.Href != "/daskalia" || scraper.calls != 1 {
+		t.Fatalf("RandomMovie() = %+v after %d scrapes, want Daskalia after one", movie, scraper.calls)
+	}
+
+	if _, err := service.RandomMovie(context.Background(), "cuttack", MovieFilter{Languages: []string{"Tamil"}}); !errors.Is(err, ErrNoMatchingMovies) {
+		t.Fatalf("RandomMovie() error = %v, want %v", err, ErrNoMatchingMovies)
+	}
+}
+
+func TestMovieFilterKeepsMoviesWithoutData(t *testing.T) {
+	t.Parallel()
+
+	filter := MovieFilter{Languages: []string{"english"}, Genres: []string{"Drama"}}
+
+	tests := []struct {
+		movie Movie
+		want  bool
+	}{
+		{Movie{Title: "F1", Languages: []string{"English"}, Genres: []string{"Sports", "drama"}}, true},
+		{Movie{Title: "Unknown"}, true},
+		{Movie{Title: "Daskalia", Languages: []string{"Odia"}}, false},
+		{Movie{Title: "Jurassic World", Languages: []string{"English"}, Genres: []string{"Action"}}, false},
+	}
+
+	for _, test := range tests {
+		if got := filter.Matches(test.movie); got != test.want {
+			t.Errorf("Matches(%q) = %v, want %v", test.movie.Title, got, test.want)
+		}
+	}
+}
+
+func TestMovieServiceAvailabilityMatchesTitleAcrossCities(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{listings: []Listing{
+		{City: "bhubaneswar", Movie: Movie{Title: "F1: The Movie", Href: "/bhubaneswar/f1"}},
+		{City: "bhubaneswar", Movie: Movie{Title: "Jurassic World Rebirth", Href: "/bhubaneswar/jurassic"}},
+		{City: "cuttack", Movie: Movie{Title: "Daskalia", Href: "/cuttack/daskalia"}},
+		{City: "mumbai", Movie: Movie{Title: "F1 – The Movie", Href: "/mumbai/f1"}},
+	}}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
+
+	availability, err := service.Availability(context.Background(), "f1 the movie")
+	if err != nil {
+		t.Fatalf("Availability() error = %v", err)
+	}
+
+	if len(availability.Cities) != 2 || availability.Cities[0].City != "bhubaneswar" || availability.Cities[1].Movie.Href != "/mumbai/f1" {
+		t.Fatalf("Availability() = %+v, want F1 in bhubaneswar and mumbai", availability)
+	}
+
+	if _, err := service.Availability(context.Background(), "zzzzzz"); !errors.Is(err, ErrTitleNotShowing) {
+		t.Fatalf("Availability() error = %v, want %v", err, ErrTitleNotShowing)
+	}
+}
+
+func TestMovieServiceAvailabilityMatchesMovieIDAcrossCities(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{listings: []Listing{
+		{City: "bhubaneswar", Movie: Movie{Title: "F1: The Movie", Href: "/bhubaneswar/f1-the-movie/ET00403839"}},
+		{City: "cuttack", Movie: Movie{Title: "Daskalia", Href: "/cuttack/daskalia/ET00412345"}},
+		{City: "mumbai", Movie: Movie{Title: "F1", Href: "/mumbai/f1/ET00403839"}},
+	}}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
+
+	availability, err := service.MovieAvailability(context.Background(), "et00403839")
+	if err != nil {
+		t.Fatalf("MovieAvailability() error = %v", err)
+	}
+
+	if availability.ID != "ET00403839" || availability.Title != "F1: The Movie" || len(availability.Cities) != 2 || availability.Cities[1].City != "mumbai" {
+		t.Fatalf("MovieAvailability() = %+v, want F1 in bhubaneswar and mumbai", availability)
+	}
+
+	// Searching by title finds the city that lists it under another title.
+	availability, err = service.Availability(context.Background(), "f1 the movie")
+	if err != nil {
+		t.Fatalf("Availability() error = %v", err)
+	}
+
+	if availability.ID != "ET00403839" || len(availability.Cities) != 2 {
+		t.Fatalf("Availability() = %+v, want F1 in bhubaneswar and mumbai", availability)
+	}
+
+	if _, err := service.MovieAvailability(context.Background(), "ET00000001"); !errors.Is(err, ErrTitleNotShowing) {
+		t.Fatalf("MovieAvailability() error = %v, want %v", err, ErrTitleNotShowing)
+	}
+}
+
+func TestMovieServiceLastModified(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repo := &fakeRepository{scrapes: []CityScrape{
+		{City: "cuttack", ScrapedAt: now.Add(-time.Hour)},
+		{City: "bhubaneswar", ScrapedAt: now.Add(-48 * time.Hour)},
+	}}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{CacheTTL: 24 * time.Hour}, testLogger())
+	ctx := context.Background()
+
+	if lastModified, ok, err := service.LastModified(ctx, "cuttack"); err != nil || !ok || !lastModified.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("LastModified(cuttack) = %v, %v, %v, want the fresh scrape", lastModified, ok, err)
+	}
+
+	// Load would scrape again rather than serve stale or missing movies.
+	for _, city := range []string{"bhubaneswar", "puri"} {
+		if _, ok, err := service.LastModified(ctx, city); err != nil || ok {
+			t.Fatalf("LastModified(%s) = %v, %v, want false", city, ok, err)
+		}
+	}
+
+	service.PauseScraping()
+
+	if _, ok, err := service.LastModified(ctx, "bhubaneswar"); err != nil || !ok {
+		t.Fatalf("LastModified(bhubaneswar) while paused = %v, %v, want true", ok, err)
+	}
+}
+
+type fakeCitySettingsStore struct {
+	mu       sync.Mutex
+	settings []CitySettings
+}
+
+func (f *fakeCitySettingsStore) ListCitySettings(_ context.Context) ([]CitySettings, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]CitySettings(nil), f.settings...), nil
+}
+
+func (f *fakeCitySettingsStore) UpsertCitySettings(_ context.Context, settings CitySettings) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.settings = append(f.settings, settings)
+	return nil
+}
+
+func TestMovieServiceLoadRefusesDisabledCities(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{listFreshMovies: []Movie{{Title: "Superman", Href: "/superman"}}, hasFresh: true}
+	scraper := &fakeScraper{}
+	service := NewMovieService(repo, scraper, ServiceOptions{
+		CacheTTL:     24 * time.Hour,
+		CitySettings: &fakeCitySettingsStore{},
+	}, testLogger())
+	ctx := context.Background()
+
+	if _, err := service.UpdateCitySettings(ctx, CitySettings{City: "cuttack", Disabled: true}); err != nil {
+		t.Fatalf("UpdateCitySettings() error = %v", err)
+	}
+
+	if _, _, err := service.Load(ctx, "cuttack"); !errors.Is(err, ErrCityDisabled) {
+		t.Fatalf("Load() error = %v, want %v", err, ErrCityDisabled)
+	}
+
+	if err := service.Preload(ctx, []string{"cuttack"}); err != nil {
+		t.Fatalf("Preload() error = %v, want disabled cities skipped", err)
+	}
+
+	if _, _, err := service.Load(ctx, "bhubaneswar"); err != nil {
+		t.Fatalf("Load(bhubaneswar) error = %v", err)
+	}
+}
+
+func TestMovieServiceUsesCityCacheTTL(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeRepository{scrapes: []CityScrape{{City: "cuttack", ScrapedAt: time.Now().Add(-2 * time.Hour)}}}
+	service := NewMovieService(repo, &fakeScraper{}, ServiceOptions{
+		CacheTTL:     24 * time.Hour,
+		CitySettings: &fakeCitySettingsStore{settings: []CitySettings{{City: "cuttack", CacheTTL: time.Hour}}},
+	}, testLogger())
+
+	if _, ok, err := service.LastModified(context.Background(), "cuttack"); err != nil || ok {
+		t.Fatalf("LastModified() = %v, %v, want the scrape expired by the city's TTL", ok, err)
+	}
+
+	stats, err := service.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+
+	if len(stats.Cities) != 1 || stats.Cities[0].Fresh {
+		t.Fatalf("Stats() cities = %+v, want cuttack stale", stats.Cities)
+	}
+}
@@ -0,0 +1,112 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// StalePolicy decides what Load does with a city whose movies are older than
+// its cache TTL.
+type StalePolicy string
+
+const (
+	// StaleBlock scrapes the city before responding. It is the default.
+	StaleBlock StalePolicy = "block"
+
+	// StaleServe responds with the old movies, flagged stale, and leaves
+	// scraping the city to its refresh job.
+	StaleServe StalePolicy = "serve_stale"
+
+	// StaleServeAndRefresh responds with the old movies, flagged stale, and
+	// scrapes the city in the background.
+	StaleServeAndRefresh StalePolicy = "serve_stale_and_refresh"
+)
+
+// StalePolicies lists every policy, the default first.
+var StalePolicies = []StalePolicy{StaleBlock, StaleServe, StaleServeAndRefresh}
+
+// Valid reports whether p is one of StalePolicies.
+func (p StalePolicy) Valid() bool {
+	switch p {
+	case StaleBlock, StaleServe, StaleServeAndRefresh:
+		return true
+	default:
+		return false
+	}
+}
+
+// stalePolicyFor returns the city's stale policy.
+func (s *MovieService) stalePolicyFor(ctx context.Context, city string) StalePolicy {
+	if policy := s.citySettings(ctx, city).StalePolicy; policy != "" {
+		return policy
+	}
+
+	return s.stale
+}
+
+// StaleNote records whether a request was served movies older than the cache
+// TTL.
+type StaleNote struct {
+	Stale bool
+}
+
+type staleNoteKey struct{}
+
+// WithStaleNote returns a context in which loading a city's movies records in
+// note whether they were stale.
+func WithStaleNote(ctx context.Context) (context.Context, *StaleNote) {
+	note := &StaleNote{}
+	return context.WithValue(ctx, staleNoteKey{}, note), note
+}
+
+// NoteStale records in the context's note, if it has one, that the movies
+// loaded were stale.
+func NoteStale(ctx context.Context) {
+	if note, ok := ctx.Value(staleNoteKey{}).(*StaleNote); ok {
+		note.Stale = true
+	}
+}
+
+// serveStale returns the city's saved movies, whatever their age, for a
+// policy that serves them instead of waiting for a scrape. It reports false
+// when there are none to serve, so the city is scraped as usual.
+func (s *MovieService) serveStale(ctx context.Context, city string, policy StalePolicy) ([]Movie, bool, error) {
+	staleMovies, err := s.repo.ListFresh(ctx, city, time.Time{})
+	if err != nil {
+		return nil, false, fmt.Errorf("query cached movies: %w", err)
+	}
+
+	if len(staleMovies) == 0 {
+		return nil, false, nil
+	}
+
+	if policy == StaleServeAndRefresh {
+		s.refreshInBackground(ctx, city)
+	}
+
+	NoteStale(ctx)
+
+	return staleMovies, true, nil
+}
+
+// refreshInBackground scrapes the city without holding up the request that
+// found it stale. Nothing is started while another load of the city holds
+// its lock, since that load scrapes it already.
+func (s *MovieService) refreshInBackground(ctx context.Context, city string) {
+	lock := s.cityLock(city)
+	if !lock.TryLock() {
+		return
+	}
+
+	// The refresh outlives the request, keeping only its logging values.
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		defer lock.Unlock()
+
+		if _, _, err := s.loadLocked(ctx, city); err != nil {
+			s.logger.WarnContext(ctx, "failed to refresh stale movies", "city", city, "error", err)
+		}
+	}()
+}
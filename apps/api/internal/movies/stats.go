@@ -0,0 +1,125 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+type cacheCounters struct {
+	mu     sync.Mutex
+	hits   map[string]int64
+	misses map[string]int64
+}
+
+func newCacheCounters() *cacheCounters {
+	return &cacheCounters{
+		hits:   make(map[string]int64),
+		misses: make(map[string]int64),
+	}
+}
+
+func (c *cacheCounters) recordHit(city string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.hits[city]++
+}
+
+func (c *cacheCounters) recordMiss(city string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.misses[city]++
+}
+
+func (c *cacheCounters) snapshot() (map[string]int64, map[string]int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hits := make(map[string]int64, len(c.hits))
+	for city, count := range c.hits {
+		hits[city] = count
+	}
+
+	misses := make(map[string]int64, len(c.misses))
+	for city, count := range c.misses {
+		misses[city] = count
+	}
+
+	return hits, misses
+}
+
+func (s *MovieService) Stats(ctx context.Context) (CacheStats, error) {
+	scrapes, err := s.repo.ListScrapes(ctx)
+	if err != nil {
+		return CacheStats{}, fmt.Errorf("query city scrapes: %w", err)
+	}
+
+	hits, misses := s.counters.snapshot()
+	now := time.Now()
+
+	byCity := make(map[string]*CityCacheStats)
+	cityStats := func(city string) *CityCacheStats {
+		if entry, ok := byCity[city]; ok {
+			return entry
+		}
+
+		entry := &CityCacheStats{City: city}
+		byCity[city] = entry
+
+		return entry
+	}
+
+	for _, scrape := range scrapes {
+		scrapedAt := scrape.ScrapedAt
+		age := now.Sub(scrapedAt)
+
+		entry := cityStats(scrape.City)
+		entry.MovieCount = scrape.MovieCount
+		entry.ScrapedAt = &scrapedAt
+		entry.AgeSeconds = age.Seconds()
+		entry.Fresh = age < s.cacheTTLFor(ctx, scrape.City)
+	}
+
+	var stats CacheStats
+	for city, count := range hits {
+		cityStats(city).Hits = count
+		stats.Hits += count
+	}
+
+	for city, count := range misses {
+		cityStats(city).Misses = count
+		stats.Misses += count
+	}
+
+	for city, streak := range s.scrapeHistory.failureStreaks() {
+		entry := cityStats(city)
+		entry.FailureStreak = streak.count
+		entry.LastError = streak.lastError
+	}
+
+	stats.Cities = make([]CityCacheStats, 0, len(byCity))
+	for _, entry := range byCity {
+		stats.Cities = append(stats.Cities, *entry)
+	}
+
+	sort.Slice(stats.Cities, func(i, j int) bool {
+		return stats.Cities[i].City < stats.Cities[j].City
+	})
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats.Memory = MemoryStats{
+		AllocBytes:     mem.Alloc,
+		HeapInuseBytes: mem.HeapInuse,
+		SysBytes:       mem.Sys,
+		NumGC:          mem.NumGC,
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,32 @@
+package movies
+
+import (
+	"context"
+	"time"
+)
+
+// defaultStreamingTTL is how long streaming availability is reused before it
+// is looked up again. Titles move to streaming within weeks of release, so it
+// is kept much shorter than the ratings TTL.
+const defaultStreamingTTL = 24 * time.Hour
+
+// addStreaming sets the movie's streaming availability, reusing a lookup made
+// within the streaming TTL. Lookup failures are logged and leave it unset.
+func (s *MovieService) addStreaming(ctx context.Context, movie *Movie) {
+	key := lookupKey(*movie)
+	expiredBefore := time.Now().Add(-s.streamingTTL)
+
+	if streaming, ok := s.streamingCache.get(key, expiredBefore); ok {
+		movie.Streaming = streaming
+		return
+	}
+
+	streaming, err := s.streaming.LookupStreaming(ctx, movie.Title, movie.Year)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to look up streaming availability", "title", movie.Title, "error", err)
+		return
+	}
+
+	s.streamingCache.set(key, streaming, time.Now(), expiredBefore)
+	movie.Streaming = streaming
+}
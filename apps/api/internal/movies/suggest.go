@@ -0,0 +1,162 @@
+package movies
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type prefixEntry struct {
+	key   string
+	movie int
+}
+
+// prefixIndex holds every word-aligned suffix of each folded, lowercased title
+// in sorted order, so completions for a prefix are one binary search away and
+// "drag" completes "How to Train Your Dragon" as well as "Dragon Ball".
+type prefixIndex struct {
+	snapshot uint64
+	movies   []Movie
+	entries  []prefixEntry
+}
+
+func buildPrefixIndex(list []Movie) *prefixIndex {
+	index := &prefixIndex{
+		snapshot: snapshotKey(list),
+		movies:   list,
+	}
+
+	for i, movie := range list {
+		words := strings.Fields(strings.ToLower(foldForMatch(movie.Title)))
+		for start := range words {
+			index.entries = append(index.entries, prefixEntry{
+				key:   strings.Join(words[start:], " "),
+				movie: i,
+			})
+		}
+	}
+
+	sort.Slice(index.entries, func(i, j int) bool {
+		return index.entries[i].key < index.entries[j].key
+	})
+
+	return index
+}
+
+// lookup returns the indexes of movies with a title word starting with prefix,
+// in title order of the matching suffix.
+func (idx *prefixIndex) lookup(prefix string) []int {
+	first := sort.Search(len(idx.entries), func(i int) bool {
+		return idx.entries[i].key >= prefix
+	})
+
+	seen := make(map[int]bool)
+	var result []int
+	for i := first; i < len(idx.entries) && strings.HasPrefix(idx.entries[i].key, prefix); i++ {
+		movie := idx.entries[i].movie
+		if seen[movie] {
+			continue
+		}
+
+		seen[movie] = true
+		result = append(result, movie)
+	}
+
+	return result
+}
+
+type prefixIndexes struct {
+	mu     sync.Mutex
+	cities map[string]*prefixIndex
+}
+
+func newPrefixIndexes() *prefixIndexes {
+	return &prefixIndexes{cities: make(map[string]*prefixIndex)}
+}
+
+func (p *prefixIndexes) forCity(city string, list []Movie) *prefixIndex {
+	snapshot := snapshotKey(list)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if index, ok := p.cities[city]; ok && index.snapshot == snapshot {
+		return index
+	}
+
+	index := buildPrefixIndex(list)
+	p.cities[city] = index
+
+	return index
+}
+
+// popularity counts how often each title was the best match for a search, per
+// city, and is used to rank suggestions.
+type popularity struct {
+	mu     sync.Mutex
+	cities map[string]map[string]int64
+}
+
+func newPopularity() *popularity {
+	return &popularity{cities: make(map[string]map[string]int64)}
+}
+
+func (p *popularity) record(city, title string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	counts, ok := p.cities[city]
+	if !ok {
+		counts = make(map[string]int64)
+		p.cities[city] = counts
+	}
+
+	counts[title]++
+}
+
+func (p *popularity) count(city, title string) int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.cities[city][title]
+}
+
+func (s *MovieService) Suggest(ctx context.Context, city, prefix string, limit int) ([]Suggestion, error) {
+	loadedMovies, _, err := s.Load(ctx, city)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix = strings.ToLower(foldForMatch(prefix))
+	if prefix == "" {
+		return []Suggestion{}, nil
+	}
+
+	index := s.prefixes.forCity(city, loadedMovies)
+
+	matches := index.lookup(prefix)
+	suggestions := make([]Suggestion, 0, len(matches))
+	for _, i := range matches {
+		movie := index.movies[i]
+		suggestions = append(suggestions, Suggestion{
+			Title:      movie.Title,
+			Href:       movie.Href,
+			Popularity: s.popularity.count(city, movie.Title),
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Popularity != suggestions[j].Popularity {
+			return suggestions[i].Popularity > suggestions[j].Popularity
+		}
+
+		return suggestions[i].Title < suggestions[j].Title
+	})
+
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	return suggestions, nil
+}
@@ -0,0 +1,38 @@
+package movies
+
+import (
+	"context"
+	"time"
+)
+
+// RecordRequest writes the request event in the background, like searches,
+// so the traffic table never delays a response.
+func (s *MovieService) RecordRequest(ctx context.Context, event RequestEvent) {
+	if s.requestLog == nil {
+		return
+	}
+
+	go func() {
+		recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), searchLogTimeout)
+		defer cancel()
+
+		if err := s.requestLog.RecordRequest(recordCtx, event); err != nil {
+			s.logger.ErrorContext(recordCtx, "failed to record request", "endpoint", event.Endpoint, "error", err)
+		}
+	}()
+}
+
+// TrafficSummary returns the requests recorded since the given time, per city
+// and UTC day.
+func (s *MovieService) TrafficSummary(ctx context.Context, since time.Time) (TrafficSummary, error) {
+	if s.requestLog == nil {
+		return TrafficSummary{}, ErrTrafficLogDisabled
+	}
+
+	days, err := s.requestLog.SummarizeTraffic(ctx, since)
+	if err != nil {
+		return TrafficSummary{}, err
+	}
+
+	return TrafficSummary{Since: since, Days: days}, nil
+}
@@ -0,0 +1,43 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// trailerTTL is how long a trailer lookup is reused. Trailers rarely change
+// once a movie is showing, including when none was found.
+const trailerTTL = 7 * 24 * time.Hour
+
+// Trailer returns the trailer of the city's movie with the given ID, reusing
+// a lookup made within the trailer TTL.
+func (s *MovieService) Trailer(ctx context.Context, city, id string) (Trailer, error) {
+	if s.trailers == nil {
+		return Trailer{}, ErrTrailersDisabled
+	}
+
+	movie, err := s.FindMovie(ctx, city, id)
+	if err != nil {
+		return Trailer{}, err
+	}
+
+	key := lookupKey(movie)
+	expiredBefore := time.Now().Add(-trailerTTL)
+
+	trailer, ok := s.trailerCache.get(key, expiredBefore)
+	if !ok {
+		trailer, err = s.trailers.LookupTrailer(ctx, movie.Title, movie.Year)
+		if err != nil {
+			return Trailer{}, fmt.Errorf("look up trailer: %w", err)
+		}
+
+		s.trailerCache.set(key, trailer, time.Now(), expiredBefore)
+	}
+
+	if trailer == nil {
+		return Trailer{}, ErrTrailerNotFound
+	}
+
+	return *trailer, nil
+}
@@ -1,12 +1,403 @@
 package movies
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
 type Movie struct {
-	Title string `json:"title"`
-	Href  string `json:"href"`
+	Title     string   `json:"title"`
+	Href      string   `json:"href"`
+	Year      int      `json:"year,omitempty"`
+	Genres    []string `json:"genres,omitempty"`
+	Languages []string `json:"languages,omitempty"`
+	Cast      []string `json:"cast,omitempty"`
+	Score     int      `json:"score,omitempty"`
+
+	// Buzz is the number of people interested in the movie or who voted on
+	// it, as shown on its listing card.
+	Buzz int `json:"buzz,omitempty"`
+
+	// Rerelease reports whether the movie was released more than a year
+	// before it was scraped.
+	Rerelease bool `json:"rerelease,omitempty"`
+
+	// OriginalTitle is the scraped title when Title was replaced by a
+	// variant in the reader's language.
+	OriginalTitle string `json:"original_title,omitempty"`
+
+	// Highlights lists the characters of Title that matched the search query
+	// as [Start, End) character offsets.
+	Highlights []Highlight `json:"highlights,omitempty"`
+
+	// Ratings holds critic scores for search matches when ratings lookups
+	// are enabled and the title was found.
+	Ratings *Ratings `json:"ratings,omitempty"`
+
+	// LetterboxdURL and IMDbURL link to the movie's reviews once its IMDb ID
+	// is known from the ratings lookup.
+	LetterboxdURL string `json:"letterboxd_url,omitempty"`
+	IMDbURL       string `json:"imdb_url,omitempty"`
+
+	// Streaming reports whether a search match can already be streamed, when
+	// streaming lookups are enabled and the title was found.
+	Streaming *Streaming `json:"streaming,omitempty"`
+
+	// Editions links to each language's listing when GroupEditions merged
+	// the movie's separate cards per language.
+	Editions []Edition `json:"editions,omitempty"`
+}
+
+// Edition is one language's listing of a movie.
+type Edition struct {
+	Language string `json:"language"`
+	Href     string `json:"href"`
+}
+
+// eventCode matches the BookMyShow event code that ends a movie's link, as in
+// /movies/bhubaneswar/f1-the-movie/ET00403839.
+var eventCode = regexp.MustCompile(`/(ET\d+)/?(?:[?#]|$)`)
+
+// ID identifies the movie by the event code in its link, which stays the same
+// when BookMyShow changes the title slug and across cities. Links without one
+// are identified by a hash of the link.
+func (m Movie) ID() string {
+	if match := eventCode.FindStringSubmatch(m.Href); match != nil {
+		return match[1]
+	}
+
+	sum := sha256.Sum256([]byte(m.Href))
+	return hex.EncodeToString(sum[:8])
+}
+
+// movieIDPattern matches the IDs Movie.ID returns: an event code or 16
+// lowercase hex digits.
+var movieIDPattern = regexp.MustCompile(`^(?:ET\d+|[0-9a-f]{16})$`)
+
+// ParseID normalizes a movie ID given by a client, ignoring case and
+// surrounding space, and reports whether it has the form of one.
+func ParseID(id string) (string, bool) {
+	id = strings.TrimSpace(id)
+	if upper := strings.ToUpper(id); strings.HasPrefix(upper, "ET") {
+		id = upper
+	} else {
+		id = strings.ToLower(id)
+	}
+
+	return id, movieIDPattern.MatchString(id)
+}
+
+// MarshalJSON adds the movie's ID, so clients can build short links to it.
+func (m Movie) MarshalJSON() ([]byte, error) {
+	type movie Movie
+
+	return json.Marshal(struct {
+		ID string `json:"id"`
+		movie
+	}{m.ID(), movie(m)})
+}
+
+// Streaming is a title's availability on subscription streaming services in
+// the configured region.
+type Streaming struct {
+	Available bool     `json:"available"`
+	Providers []string `json:"providers,omitempty"`
+
+	// Link is a page listing where the title can be watched.
+	Link string `json:"link,omitempty"`
+}
+
+// Trailer is a movie's trailer on a video site, with what clients need to
+// embed it.
+type Trailer struct {
+	Title    string `json:"title"`
+	Provider string `json:"provider"`
+	URL      string `json:"url"`
+
+	// EmbedURL is the player page to show in an iframe.
+	EmbedURL string `json:"embed_url"`
+
+	ThumbnailURL    string `json:"thumbnail_url,omitempty"`
+	ThumbnailWidth  int    `json:"thumbnail_width,omitempty"`
+	ThumbnailHeight int    `json:"thumbnail_height,omitempty"`
+}
+
+// Ratings are critic scores for a title. Zero values mean the source has no
+// score for it.
+type Ratings struct {
+	IMDbID         string  `json:"imdb_id,omitempty"`
+	IMDb           float64 `json:"imdb,omitempty"`
+	RottenTomatoes int     `json:"rotten_tomatoes,omitempty"`
+	Metacritic     int     `json:"metacritic,omitempty"`
+}
+
+type Highlight struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
 }
 
 type Response struct {
-	City   string  `json:"city"`
-	Movies []Movie `json:"movies"`
-	Count  int     `json:"count"`
+	City        string  `json:"city"`
+	DisplayName string  `json:"display_name,omitempty"`
+	Movies      []Movie `json:"movies"`
+	Count       int     `json:"count"`
+	DidYouMean  string  `json:"did_you_mean,omitempty"`
+
+	// Stale reports movies older than the city's cache TTL, served under a
+	// stale policy instead of scraping the city first.
+	Stale bool `json:"stale,omitempty"`
+
+	// Facets counts genres and languages across the matches, keyed by facet
+	// name. Only the full-text index backend computes them.
+	Facets map[string]map[string]int `json:"facets,omitempty"`
+}
+
+type SearchResult struct {
+	Movies    []Movie
+	FromCache bool
+
+	// DidYouMean is the title closest to the query by edit distance, set only
+	// when the search found nothing.
+	DidYouMean string
+
+	Facets map[string]map[string]int
+}
+
+type Suggestion struct {
+	Title      string `json:"title"`
+	Href       string `json:"href"`
+	Popularity int64  `json:"popularity"`
+}
+
+type SuggestResponse struct {
+	City        string       `json:"city"`
+	Prefix      string       `json:"prefix"`
+	Suggestions []Suggestion `json:"suggestions"`
+	Count       int          `json:"count"`
+}
+
+type SearchRequest struct {
+	Query     string
+	Fuzziness int
+	Fields    []string
+}
+
+type SearchEvent struct {
+	City            string    `json:"city"`
+	RawQuery        string    `json:"raw_query"`
+	NormalizedQuery string    `json:"normalized_query"`
+	ResultCount     int       `json:"result_count"`
+	SearchedAt      time.Time `json:"searched_at"`
+
+	// TopTitle is the title of the best match, if there was one.
+	TopTitle string `json:"top_title,omitempty"`
+}
+
+// SearchEventColumns names the values of SearchEvent.Record, in order.
+var SearchEventColumns = []string{"searched_at", "city", "raw_query", "normalized_query", "result_count", "top_title"}
+
+// Record returns the event as a row of text for CSV exports.
+func (e SearchEvent) Record() []string {
+	return []string{
+		e.SearchedAt.UTC().Format(time.RFC3339),
+		e.City,
+		e.RawQuery,
+		e.NormalizedQuery,
+		strconv.Itoa(e.ResultCount),
+		e.TopTitle,
+	}
+}
+
+// ClickEvent records a user following a movie's link.
+type ClickEvent struct {
+	City      string
+	Title     string
+	Href      string
+	ClickedAt time.Time
+}
+
+type TitleCount struct {
+	Title string `json:"title"`
+	Count int64  `json:"count"`
+}
+
+// Trending lists a city's most searched and most clicked titles, most popular
+// first. A search counts toward the title of its best match.
+type Trending struct {
+	City         string       `json:"city"`
+	Since        time.Time    `json:"since"`
+	MostSearched []TitleCount `json:"most_searched"`
+	MostClicked  []TitleCount `json:"most_clicked"`
+}
+
+type QueryCount struct {
+	Query      string  `json:"query"`
+	Count      int64   `json:"count"`
+	AvgResults float64 `json:"avg_results"`
+}
+
+type SearchSummary struct {
+	City                 string       `json:"city,omitempty"`
+	Since                time.Time    `json:"since"`
+	TopQueries           []QueryCount `json:"top_queries"`
+	TopZeroResultQueries []QueryCount `json:"top_zero_result_queries"`
+}
+
+// Cache results of a request, for RequestEvent.
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+// RequestEvent is one API request, recorded without the client's address,
+// user, or query so traffic can be analyzed anonymously.
+type RequestEvent struct {
+	// Endpoint is the route that served the request, such as "GET /movies".
+	Endpoint string
+	City     string
+
+	// Cache is CacheHit or CacheMiss for requests that loaded a city's
+	// movies, and empty otherwise.
+	Cache      string
+	Status     int
+	Latency    time.Duration
+	OccurredAt time.Time
+}
+
+// TrafficDay summarizes one city's requests on one UTC day. Requests that
+// named no city are counted under an empty city.
+type TrafficDay struct {
+	Date         string  `json:"date"`
+	City         string  `json:"city"`
+	Requests     int64   `json:"requests"`
+	CacheHits    int64   `json:"cache_hits"`
+	CacheMisses  int64   `json:"cache_misses"`
+	Errors       int64   `json:"errors"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	P95LatencyMs float64 `json:"p95_latency_ms"`
+}
+
+type TrafficSummary struct {
+	Since time.Time    `json:"since"`
+	Days  []TrafficDay `json:"days"`
+}
+
+// CitySettings are an admin's overrides for one city. Disabled cities are
+// neither scraped nor served, and a zero CacheTTL or empty StalePolicy uses
+// the service's.
+type CitySettings struct {
+	City        string
+	Disabled    bool
+	CacheTTL    time.Duration
+	StalePolicy StalePolicy
+	UpdatedAt   time.Time
+}
+
+// TitleVariant is a movie's title in another language, keyed by the movie's
+// ID and a base language subtag such as "hi".
+type TitleVariant struct {
+	MovieID   string    `json:"movie_id"`
+	Language  string    `json:"language"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Alias struct {
+	Alias     string    `json:"alias"`
+	Canonical string    `json:"canonical"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Sighting records when a movie first appeared in a city's listings and
+// when a scrape last found it there.
+type Sighting struct {
+	City        string
+	Movie       Movie
+	FirstSeenAt time.Time
+	LastSeenAt  time.Time
+}
+
+// Changes lists how a city's listings differ between a point in time and its
+// latest scrape. Movies that appeared and disappeared in between are in
+// neither list.
+type Changes struct {
+	// Added holds the movies listed now that were first seen since then,
+	// newest first.
+	Added []Sighting
+
+	// Removed holds the movies listed since then that the latest scrape no
+	// longer found, most recently seen first.
+	Removed []Sighting
+}
+
+// ID identifies the sighting stably across scrapes, for clients that
+// deduplicate on it.
+func (s Sighting) ID() string {
+	sum := sha256.Sum256([]byte(s.City + "\x00" + s.Movie.Href))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Listing is a movie in a city's saved listings, as of the scrape that found
+// it.
+type Listing struct {
+	City      string
+	Movie     Movie
+	ScrapedAt time.Time
+}
+
+// Availability lists the cities showing a title, in city order.
+type Availability struct {
+	ID     string
+	Title  string
+	Cities []Listing
+}
+
+type CityScrape struct {
+	City       string
+	ScrapedAt  time.Time
+	MovieCount int
+}
+
+type CacheStats struct {
+	Hits   int64            `json:"hits"`
+	Misses int64            `json:"misses"`
+	Cities []CityCacheStats `json:"cities"`
+	Memory MemoryStats      `json:"memory"`
+}
+
+type CityCacheStats struct {
+	City       string     `json:"city"`
+	Hits       int64      `json:"hits"`
+	Misses     int64      `json:"misses"`
+	MovieCount int        `json:"movie_count"`
+	ScrapedAt  *time.Time `json:"scraped_at,omitempty"`
+	AgeSeconds float64    `json:"age_seconds"`
+	Fresh      bool       `json:"fresh"`
+
+	// FailureStreak counts consecutive failed scrapes since the last
+	// successful one, and LastError is the most recent failure's error.
+	FailureStreak int    `json:"failure_streak"`
+	LastError     string `json:"last_error,omitempty"`
+}
+
+type MemoryStats struct {
+	AllocBytes     uint64 `json:"alloc_bytes"`
+	HeapInuseBytes uint64 `json:"heap_inuse_bytes"`
+	SysBytes       uint64 `json:"sys_bytes"`
+	NumGC          uint32 `json:"num_gc"`
+}
+
+// ScrapeRun records the outcome of one scrape of a city.
+type ScrapeRun struct {
+	ID         string    `json:"id"`
+	City       string    `json:"city"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	MovieCount int       `json:"movie_count"`
+	Error      string    `json:"error,omitempty"`
 }
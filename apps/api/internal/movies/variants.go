@@ -0,0 +1,172 @@
+package movies
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// defaultTitleLanguage is the language scraped titles are taken to be in.
+// BookMyShow lists titles in English or romanized, so readers who prefer
+// English before any language with a variant see them unchanged.
+const defaultTitleLanguage = "en"
+
+// TitleLanguage returns the base language of a BCP 47 tag, such as "hi" for
+// "hi-IN", which is what title variants are stored and matched under.
+func TitleLanguage(tag string) (string, bool) {
+	parsed, err := language.Parse(tag)
+	if err != nil {
+		return "", false
+	}
+
+	base, confidence := parsed.Base()
+	if confidence == language.No {
+		return "", false
+	}
+
+	return base.String(), true
+}
+
+type variantCache struct {
+	mu       sync.Mutex
+	loadedAt time.Time
+	byMovie  map[string]map[string]string
+}
+
+// LocalizeTitles returns the movies with each title replaced by its variant
+// in the first of languages that has one, keeping the scraped title in
+// OriginalTitle. Languages are tried in order up to English, the language of
+// scraped titles. Replaced titles lose their highlights, which point into the
+// scraped title.
+func (s *MovieService) LocalizeTitles(ctx context.Context, list []Movie, languages []string) []Movie {
+	if s.variants == nil || len(languages) == 0 || len(list) == 0 {
+		return list
+	}
+
+	byMovie, err := s.variantMap(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to load title variants", "error", err)
+	}
+
+	if len(byMovie) == 0 {
+		return list
+	}
+
+	localized := slices.Clone(list)
+	for i, movie := range localized {
+		variants := byMovie[movie.ID()]
+		if len(variants) == 0 {
+			continue
+		}
+
+		for _, language := range languages {
+			if language == defaultTitleLanguage {
+				break
+			}
+
+			if title, ok := variants[language]; ok {
+				localized[i].OriginalTitle = movie.Title
+				localized[i].Title = title
+				localized[i].Highlights = nil
+				break
+			}
+		}
+	}
+
+	return localized
+}
+
+// variantMap returns the cached variants by movie ID and language, reloading
+// them like aliasMap.
+func (s *MovieService) variantMap(ctx context.Context) (map[string]map[string]string, error) {
+	s.variantCache.mu.Lock()
+	defer s.variantCache.mu.Unlock()
+
+	if s.variantCache.byMovie != nil && time.Since(s.variantCache.loadedAt) < aliasRefreshInterval {
+		return s.variantCache.byMovie, nil
+	}
+
+	variants, err := s.variants.ListTitleVariants(ctx)
+	if err != nil {
+		return s.variantCache.byMovie, err
+	}
+
+	byMovie := make(map[string]map[string]string)
+	for _, variant := range variants {
+		if byMovie[variant.MovieID] == nil {
+			byMovie[variant.MovieID] = make(map[string]string)
+		}
+		byMovie[variant.MovieID][variant.Language] = variant.Title
+	}
+
+	s.variantCache.byMovie = byMovie
+	s.variantCache.loadedAt = time.Now()
+
+	return byMovie, nil
+}
+
+func (s *MovieService) invalidateVariants() {
+	s.variantCache.mu.Lock()
+	defer s.variantCache.mu.Unlock()
+
+	s.variantCache.byMovie = nil
+}
+
+func (s *MovieService) ListTitleVariants(ctx context.Context) ([]TitleVariant, error) {
+	if s.variants == nil {
+		return nil, ErrTitleVariantsDisabled
+	}
+
+	return s.variants.ListTitleVariants(ctx)
+}
+
+// AddTitleVariant sets the movie's title in the language, given as a BCP 47
+// tag of which only the base language is kept.
+func (s *MovieService) AddTitleVariant(ctx context.Context, movieID, tag, title string) (TitleVariant, error) {
+	if s.variants == nil {
+		return TitleVariant{}, ErrTitleVariantsDisabled
+	}
+
+	base, ok := TitleLanguage(tag)
+	if !ok {
+		return TitleVariant{}, ErrLanguageUnknown
+	}
+
+	variant := TitleVariant{
+		MovieID:   movieID,
+		Language:  base,
+		Title:     NormalizeQuery(title),
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.variants.UpsertTitleVariant(ctx, variant); err != nil {
+		return TitleVariant{}, err
+	}
+
+	s.invalidateVariants()
+
+	return variant, nil
+}
+
+func (s *MovieService) RemoveTitleVariant(ctx context.Context, movieID, tag string) (bool, error) {
+	if s.variants == nil {
+		return false, ErrTitleVariantsDisabled
+	}
+
+	base, ok := TitleLanguage(tag)
+	if !ok {
+		return false, ErrLanguageUnknown
+	}
+
+	removed, err := s.variants.DeleteTitleVariant(ctx, movieID, base)
+	if err != nil {
+		return false, err
+	}
+
+	s.invalidateVariants()
+
+	return removed, nil
+}
@@ -0,0 +1,94 @@
+package movies
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeTitleVariantStore struct {
+	mu       sync.Mutex
+	variants []TitleVariant
+}
+
+func (f *fakeTitleVariantStore) ListTitleVariants(_ context.Context) ([]TitleVariant, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]TitleVariant(nil), f.variants...), nil
+}
+
+func (f *fakeTitleVariantStore) UpsertTitleVariant(_ context.Context, variant TitleVariant) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.variants = append(f.variants, variant)
+	return nil
+}
+
+func (f *fakeTitleVariantStore) DeleteTitleVariant(_ context.Context, _, _ string) (bool, error) {
+	return false, nil
+}
+
+func TestMovieServiceLocalizeTitles(t *testing.T) {
+	t.Parallel()
+
+	service := NewMovieService(&fakeRepository{}, &fakeScraper{}, ServiceOptions{
+		CacheTTL:      24 * time.Hour,
+		TitleVariants: &fakeTitleVariantStore{},
+	}, testLogger())
+
+	ctx := context.Background()
+	if _, err := service.AddTitleVariant(ctx, "ET00395817", "hi-IN", " सुपरमैन "); err != nil {
+		t.Fatalf("AddTitleVariant() error = %v", err)
+	}
+
+	list := []Movie{
+		{Title: "Superman", Href: "/movies/cuttack/superman/ET00395817", Highlights: []Highlight{{Start: 0, End: 5}}},
+		{Title: "Ballerina", Href: "/movies/cuttack/ballerina/ET00412345"},
+	}
+
+	for _, tc := range []struct {
+		name      string
+		languages []string
+		want      string
+	}{
+		{name: "variant", languages: []string{"hi", "en"}, want: "सुपरमैन"},
+		{name: "later variant", languages: []string{"or", "hi"}, want: "सुपरमैन"},
+		{name: "english first", languages: []string{"en", "hi"}, want: "Superman"},
+		{name: "no variant", languages: []string{"ta"}, want: "Superman"},
+	} {
+		localized := service.LocalizeTitles(ctx, list, tc.languages)
+
+		if localized[0].Title != tc.want {
+			t.Fatalf("%s: Title = %q, want %q", tc.name, localized[0].Title, tc.want)
+		}
+
+		replaced := tc.want != "Superman"
+		if replaced != (localized[0].OriginalTitle == "Superman") || replaced != (localized[0].Highlights == nil) {
+			t.Fatalf("%s: OriginalTitle = %q, Highlights = %v", tc.name, localized[0].OriginalTitle, localized[0].Highlights)
+		}
+
+		if localized[1].Title != "Ballerina" {
+			t.Fatalf("%s: Title = %q, want Ballerina unchanged", tc.name, localized[1].Title)
+		}
+	}
+
+	if list[0].Title != "Superman" {
+		t.Fatalf("LocalizeTitles() modified its input: %q", list[0].Title)
+	}
+}
+
+func TestMovieServiceAddTitleVariantRejectsUnknownLanguage(t *testing.T) {
+	t.Parallel()
+
+	service := NewMovieService(&fakeRepository{}, &fakeScraper{}, ServiceOptions{
+		TitleVariants: &fakeTitleVariantStore{},
+	}, testLogger())
+
+	if _, err := service.AddTitleVariant(context.Background(), "ET00395817", "!!", "Superman"); !errors.Is(err, ErrLanguageUnknown) {
+		t.Fatalf("AddTitleVariant() error = %v, want %v", err, ErrLanguageUnknown)
+	}
+}
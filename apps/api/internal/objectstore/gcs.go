@@ -0,0 +1,358 @@
+package objectstore
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	gcsEndpoint = "https://storage.googleapis.com"
+	gcsScope    = "https://www.googleapis.com/auth/devstorage.read_write"
+
+	// metadataTokenURL hands out tokens for the service account of the
+	// Compute Engine, GKE, or Cloud Run instance the server runs on.
+	metadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+	// tokenEarlyExpiry renews tokens this long before they expire, so a
+	// token never expires in flight.
+	tokenEarlyExpiry = time.Minute
+)
+
+// GCS stores objects in a Google Cloud Storage bucket through its JSON API,
+// authenticating as a service account.
+type GCS struct {
+	endpoint string
+	bucket   string
+	key      *serviceAccountKey
+	client   *http.Client
+	now      func() time.Time
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+var _ Store = (*GCS)(nil)
+
+// serviceAccountKey is the part of a service account's JSON key that is
+// needed to sign token requests.
+type serviceAccountKey struct {
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+
+	signer *rsa.PrivateKey
+}
+
+// NewGCS stores objects in bucket at endpoint, or at Google when endpoint is
+// empty. It signs in with the service account key in credentialsFile, or
+// with the instance's service account when credentialsFile is empty.
+func NewGCS(endpoint, bucket, credentialsFile string) (*GCS, error) {
+	if endpoint == "" {
+		endpoint = gcsEndpoint
+	}
+
+	store := &GCS{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		bucket:   bucket,
+		client:   &http.Client{},
+		now:      time.Now,
+	}
+
+	if credentialsFile != "" {
+		key, err := loadServiceAccountKey(credentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		store.key = key
+	}
+
+	return store, nil
+}
+
+func loadServiceAccountKey(path string) (*serviceAccountKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read GCS credentials: %w", err)
+	}
+
+	var key serviceAccountKey
+	if err := json.Unmarshal(content, &key); err != nil {
+		return nil, fmt.Errorf("parse GCS credentials %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("parse GCS credentials %s: private_key is not PEM", path)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse GCS credentials %s: %w", path, err)
+	}
+
+	signer, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("parse GCS credentials %s: private_key is not an RSA key", path)
+	}
+	key.signer = signer
+
+	if key.TokenURI == "" {
+		key.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	return &key, nil
+}
+
+func (g *GCS) Put(ctx context.Context, key string, body io.Reader, size int64) error {
+	query := url.Values{"uploadType": {"media"}, "name": {key}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.endpoint+"/upload/storage/v1/b/"+url.PathEscape(g.bucket)+"/o?"+query.Encode(), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := g.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (g *GCS) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.objectURL(key)+"?alt=media", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := g.do(req)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("get %s: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+type gcsObjectList struct {
+	Items []struct {
+		Name    string    `json:"name"`
+		Updated time.Time `json:"updated"`
+		Size    string    `json:"size"`
+	} `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+func (g *GCS) List(ctx context.Context, prefix string) ([]Object, error) {
+	var (
+		objects []Object
+		token   string
+	)
+
+	for {
+		query := url.Values{"prefix": {prefix}, "fields": {"items(name,updated,size),nextPageToken"}}
+		if token != "" {
+			query.Set("pageToken", token)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.endpoint+"/storage/v1/b/"+url.PathEscape(g.bucket)+"/o?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := g.do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var result gcsObjectList
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode object list: %w", err)
+		}
+
+		for _, item := range result.Items {
+			// The JSON API reports sizes as strings, since they may not fit
+			// in a JavaScript number.
+			size, err := strconv.ParseInt(item.Size, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("decode size of %s: %w", item.Name, err)
+			}
+			objects = append(objects, Object{Key: item.Name, LastModified: item.Updated, Size: size})
+		}
+
+		if result.NextPageToken == "" {
+			return objects, nil
+		}
+		token = result.NextPageToken
+	}
+}
+
+func (g *GCS) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, g.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := g.do(req)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// objectURL returns the URL of key, whose slashes are escaped since the JSON
+// API takes the whole name as one path segment.
+func (g *GCS) objectURL(key string) string {
+	return g.endpoint + "/storage/v1/b/" + url.PathEscape(g.bucket) + "/o/" + escape(key, false)
+}
+
+// do authorizes and sends req, returning ErrNotFound for a 404 and an error
+// for any other response that is not 2xx.
+func (g *GCS) do(req *http.Request) (*http.Response, error) {
+	token, err := g.accessToken(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("get GCS access token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+
+	if err := responseError(req, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// accessToken returns a cached OAuth token, fetching a new one when it is
+// about to expire.
+func (g *GCS) accessToken(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	if g.token != "" && now.Before(g.tokenExpiry) {
+		return g.token, nil
+	}
+
+	var (
+		req *http.Request
+		err error
+	)
+	if g.key != nil {
+		req, err = g.key.tokenRequest(ctx, now)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, metadataTokenURL, nil)
+		if err == nil {
+			req.Header.Set("Metadata-Flavor", "Google")
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	if err := responseError(req, resp); err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("decode token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("token response has no access_token")
+	}
+
+	g.token = token.AccessToken
+	g.tokenExpiry = now.Add(time.Duration(token.ExpiresIn)*time.Second - tokenEarlyExpiry)
+
+	return g.token, nil
+}
+
+// tokenRequest exchanges a JWT signed with the key for an access token, as
+// in RFC 7523.
+func (k *serviceAccountKey) tokenRequest(ctx context.Context, now time.Time) (*http.Request, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": k.PrivateKeyID})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := json.Marshal(map[string]any{
+		"iss":   k.ClientEmail,
+		"scope": gcsScope,
+		"aud":   k.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, k.signer, crypto.SHA256, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("sign token request: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return req, nil
+}
@@ -0,0 +1,147 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// writeCredentials writes a service account key whose tokens come from
+// tokenURI.
+func writeCredentials(t *testing.T, tokenURI string) string {
+	t.Helper()
+
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	content, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "screening@example.iam.gserviceaccount.com",
+		"private_key_id": "key-1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":      tokenURI,
+	})
+	if err != nil {
+		t.Fatalf("marshal credentials: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("write credentials: %v", err)
+	}
+
+	return path
+}
+
+func TestGCSPutGetListAndDelete(t *testing.T) {
+	t.Parallel()
+
+	var (
+		tokens   atomic.Int32
+		uploaded string
+		deleted  string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			tokens.Add(1)
+			if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || strings.Count(r.FormValue("assertion"), ".") != 2 {
+				http.Error(w, "bad token request", http.StatusBadRequest)
+				return
+			}
+			io.WriteString(w, `{"access_token":"token-1","expires_in":3600}`)
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer token-1" {
+			t.Errorf("Authorization = %q, want the access token", r.Header.Get("Authorization"))
+		}
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/screening/o":
+			body, _ := io.ReadAll(r.Body)
+			uploaded = r.URL.Query().Get("name") + "=" + string(body)
+		case r.Method == http.MethodGet && r.URL.EscapedPath() == "/storage/v1/b/screening/o/previews%2Fa.png":
+			io.WriteString(w, "PNG")
+		case r.Method == http.MethodGet && r.URL.Path == "/storage/v1/b/screening/o":
+			if r.URL.Query().Get("pageToken") == "" {
+				io.WriteString(w, `{"items":[{"name":"backups/a.dump","updated":"2025-06-01T03:00:00.000Z","size":"10"}],"nextPageToken":"next"}`)
+				return
+			}
+			io.WriteString(w, `{"items":[{"name":"backups/b.dump","updated":"2025-06-02T03:00:00.000Z","size":"20"}]}`)
+		case r.Method == http.MethodDelete:
+			deleted = r.URL.EscapedPath()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, `{"error":{"code":404}}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	store, err := NewGCS(server.URL, "screening", writeCredentials(t, server.URL+"/token"))
+	if err != nil {
+		t.Fatalf("NewGCS() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if err := PutBytes(ctx, store, "backups/c.dump", []byte("PGDMP")); err != nil {
+		t.Fatalf("PutBytes() error = %v", err)
+	}
+	if uploaded != "backups/c.dump=PGDMP" {
+		t.Fatalf("uploaded = %q, want backups/c.dump=PGDMP", uploaded)
+	}
+
+	data, err := GetBytes(ctx, store, "previews/a.png")
+	if err != nil || string(data) != "PNG" {
+		t.Fatalf("GetBytes() = %q, %v, want PNG", data, err)
+	}
+	if _, err := store.Get(ctx, "previews/b.png"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() error = %v, want ErrNotFound", err)
+	}
+
+	objects, err := store.List(ctx, "backups/")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(objects) != 2 || objects[0].Key != "backups/a.dump" || objects[1].Size != 20 {
+		t.Fatalf("List() = %+v, want both pages", objects)
+	}
+
+	if err := store.Delete(ctx, "backups/a.dump"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if deleted != "/storage/v1/b/screening/o/backups%2Fa.dump" {
+		t.Fatalf("deleted = %q, want the escaped object path", deleted)
+	}
+
+	if got := tokens.Load(); got != 1 {
+		t.Fatalf("token requests = %d, want the token reused", got)
+	}
+}
+
+func TestNewRejectsUnknownBackends(t *testing.T) {
+	t.Parallel()
+
+	if _, err := New(Config{Backend: "ftp"}); err == nil {
+		t.Fatal("New() error = nil, want an unknown backend")
+	}
+}
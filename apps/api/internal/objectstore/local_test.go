@@ -0,0 +1,74 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLocalPutGetListAndDelete(t *testing.T) {
+	t.Parallel()
+
+	store := NewLocal(t.TempDir())
+	ctx := context.Background()
+
+	for _, key := range []string{"scrapes/cuttack/a.html", "scrapes/mumbai/b.html", "backups/c.dump"} {
+		if err := PutBytes(ctx, store, key, []byte(key)); err != nil {
+			t.Fatalf("PutBytes(%s) error = %v", key, err)
+		}
+	}
+
+	data, err := GetBytes(ctx, store, "scrapes/mumbai/b.html")
+	if err != nil || string(data) != "scrapes/mumbai/b.html" {
+		t.Fatalf("GetBytes() = %q, %v, want the stored object", data, err)
+	}
+
+	objects, err := store.List(ctx, "scrapes/")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	var keys []string
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	if want := []string{"scrapes/cuttack/a.html", "scrapes/mumbai/b.html"}; !slices.Equal(keys, want) {
+		t.Fatalf("List() keys = %v, want %v", keys, want)
+	}
+	if objects[0].Size != int64(len("scrapes/cuttack/a.html")) {
+		t.Fatalf("List() size = %d, want the object's length", objects[0].Size)
+	}
+
+	if err := store.Delete(ctx, "backups/c.dump"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := store.Delete(ctx, "backups/c.dump"); err != nil {
+		t.Fatalf("Delete() of a missing object error = %v, want nil", err)
+	}
+	if _, err := store.Get(ctx, "backups/c.dump"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLocalRejectsKeysOutsideItsDirectory(t *testing.T) {
+	t.Parallel()
+
+	store := NewLocal(t.TempDir())
+
+	for _, key := range []string{"../escape", "/etc/passwd", "a/../../b", ""} {
+		if err := store.Put(context.Background(), key, strings.NewReader(""), 0); err == nil {
+			t.Errorf("Put(%q) error = nil, want an invalid key", key)
+		}
+	}
+}
+
+func TestLocalListsNothingBeforeTheFirstPut(t *testing.T) {
+	t.Parallel()
+
+	objects, err := NewLocal(t.TempDir()+"/missing").List(context.Background(), "")
+	if err != nil || len(objects) != 0 {
+		t.Fatalf("List() = %v, %v, want no objects", objects, err)
+	}
+}
@@ -0,0 +1,94 @@
+// Package objectstore keeps files such as database backups, scrape archives,
+// and rendered images in a bucket on S3 or an S3-compatible service, in
+// Google Cloud Storage, or in a local directory.
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned by Get for a key that has no object.
+var ErrNotFound = errors.New("object not found")
+
+// Store keeps objects by key. Keys are slash-separated paths, such as
+// backups/20250610T030000Z.dump.
+type Store interface {
+	// Put stores size bytes read from body under key, replacing any object
+	// already there.
+	Put(ctx context.Context, key string, body io.Reader, size int64) error
+
+	// Get returns the object under key, which the caller must close.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// List returns the objects whose keys start with prefix.
+	List(ctx context.Context, prefix string) ([]Object, error)
+
+	// Delete removes the object under key. Deleting a missing object is not
+	// an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Object is an object in a store.
+type Object struct {
+	Key          string
+	LastModified time.Time
+	Size         int64
+}
+
+// Config selects and configures a store.
+type Config struct {
+	// Backend is "s3", "gcs", or "local".
+	Backend string
+
+	// Bucket is the S3 or GCS bucket.
+	Bucket string
+
+	// Endpoint is the S3-compatible service, or AWS in Region when empty.
+	// For GCS it replaces https://storage.googleapis.com, for emulators.
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// CredentialsFile is a GCS service account key. Without one, tokens
+	// come from the metadata server of the machine the server runs on.
+	CredentialsFile string
+
+	// Dir is the directory of the local backend.
+	Dir string
+}
+
+// New returns the store cfg selects.
+func New(cfg Config) (Store, error) {
+	switch cfg.Backend {
+	case "s3":
+		return NewS3(cfg.Endpoint, cfg.Region, cfg.Bucket, cfg.AccessKeyID, cfg.SecretAccessKey), nil
+	case "gcs":
+		return NewGCS(cfg.Endpoint, cfg.Bucket, cfg.CredentialsFile)
+	case "local":
+		return NewLocal(cfg.Dir), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// PutBytes stores data under key.
+func PutBytes(ctx context.Context, store Store, key string, data []byte) error {
+	return store.Put(ctx, key, bytes.NewReader(data), int64(len(data)))
+}
+
+// GetBytes reads the object under key.
+func GetBytes(ctx context.Context, store Store, key string) ([]byte, error) {
+	body, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return io.ReadAll(body)
+}
@@ -0,0 +1,102 @@
+// Package omdb looks up IMDb, Rotten Tomatoes, and Metacritic scores for
+// movies from the OMDb API (https://www.omdbapi.com).
+package omdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-scraping/internal/movies"
+)
+
+const (
+	defaultBaseURL = "https://www.omdbapi.com/"
+	requestTimeout = 5 * time.Second
+)
+
+type Client struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+var _ movies.RatingsProvider = (*Client)(nil)
+
+func NewClient(apiKey string) *Client {
+	return &Client{
+		apiKey:  apiKey,
+		baseURL: defaultBaseURL,
+		client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+type titleResponse struct {
+	Response   string `json:"Response"`
+	Error      string `json:"Error"`
+	IMDbID     string `json:"imdbID"`
+	IMDbRating string `json:"imdbRating"`
+	Metascore  string `json:"Metascore"`
+	Ratings    []struct {
+		Source string `json:"Source"`
+		Value  string `json:"Value"`
+	} `json:"Ratings"`
+}
+
+// LookupRatings fetches the scores for the movie with the given title,
+// narrowed to year when it is known. It returns nil if OMDb has no such movie.
+func (c *Client) LookupRatings(ctx context.Context, title string, year int) (*movies.Ratings, error) {
+	query := url.Values{
+		"apikey": {c.apiKey},
+		"t":      {title},
+		"type":   {"movie"},
+	}
+	if year > 0 {
+		query.Set("y", strconv.Itoa(year))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("build omdb request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("query omdb: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("query omdb: unexpected status %d", resp.StatusCode)
+	}
+
+	var payload titleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("decode omdb response: %w", err)
+	}
+
+	if payload.Response != "True" {
+		if payload.Error == "Movie not found!" {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("query omdb: %s", payload.Error)
+	}
+
+	ratings := &movies.Ratings{IMDbID: payload.IMDbID}
+	ratings.IMDb, _ = strconv.ParseFloat(payload.IMDbRating, 64)
+	ratings.Metacritic, _ = strconv.Atoi(payload.Metascore)
+
+	for _, rating := range payload.Ratings {
+		if rating.Source == "Rotten Tomatoes" {
+			ratings.RottenTomatoes, _ = strconv.Atoi(strings.TrimSuffix(rating.Value, "%"))
+		}
+	}
+
+	return ratings, nil
+}
@@ -0,0 +1,74 @@
+package omdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-scraping/internal/movies"
+)
+
+func testClient(t *testing.T, body string) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("apikey") != "key" || r.URL.Query().Get("t") != "Ballerina" || r.URL.Query().Get("y") != "2025" {
+			t.Errorf("query = %q, want apikey, title, and year", r.URL.RawQuery)
+		}
+
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("key")
+	client.baseURL = server.URL
+
+	return client
+}
+
+func TestLookupRatingsParsesScores(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t, `{
+		"Response": "True",
+		"imdbID": "tt7181546",
+		"imdbRating": "6.9",
+		"Metascore": "N/A",
+		"Ratings": [
+			{"Source": "Internet Movie Database", "Value": "6.9/10"},
+			{"Source": "Rotten Tomatoes", "Value": "76%"}
+		]
+	}`)
+
+	ratings, err := client.LookupRatings(context.Background(), "Ballerina", 2025)
+	if err != nil {
+		t.Fatalf("LookupRatings() error = %v", err)
+	}
+
+	want := movies.Ratings{IMDbID: "tt7181546", IMDb: 6.9, RottenTomatoes: 76}
+	if ratings == nil || *ratings != want {
+		t.Fatalf("LookupRatings() = %+v, want %+v", ratings, want)
+	}
+}
+
+func TestLookupRatingsReturnsNilWhenNotFound(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t, `{"Response": "False", "Error": "Movie not found!"}`)
+
+	ratings, err := client.LookupRatings(context.Background(), "Ballerina", 2025)
+	if err != nil || ratings != nil {
+		t.Fatalf("LookupRatings() = %+v, %v, want nil, nil", ratings, err)
+	}
+}
+
+func TestLookupRatingsReportsAPIErrors(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t, `{"Response": "False", "Error": "Request limit reached!"}`)
+
+	if _, err := client.LookupRatings(context.Background(), "Ballerina", 2025); err == nil {
+		t.Fatal("LookupRatings() error = nil, want API error")
+	}
+}
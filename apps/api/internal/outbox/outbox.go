@@ -0,0 +1,197 @@
+// Package outbox delivers messages that were written to the database in the
+// same transaction as the change they describe, so a crash before a message
+// is delivered delays it instead of losing it. A destination that only queues
+// the message in memory can still lose it in a crash after delivery.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+const (
+	// pollInterval is how often the outbox is checked for due messages.
+	pollInterval = 5 * time.Second
+
+	// batchSize is how many messages are claimed at a time.
+	batchSize = 20
+
+	// lease is how long a claimed message is hidden from other replicas. A
+	// replica that crashes while delivering leaves the message to be claimed
+	// again once the lease runs out.
+	lease = 2 * time.Minute
+
+	// maxAttempts is how many times a message is tried before it is given up
+	// on and kept as failed.
+	maxAttempts = 10
+
+	baseBackoff = 30 * time.Second
+	maxBackoff  = time.Hour
+)
+
+// Message is a notification waiting to be delivered.
+type Message struct {
+	ID    int64
+	Topic string
+
+	// Key is what the message is about, such as a city, so that destinations
+	// can subscribe to only some messages of a topic.
+	Key     string
+	Payload []byte
+
+	// Attempts counts the deliveries tried, including the current one.
+	Attempts int
+
+	// Delivered names the destinations that already received the message on
+	// an earlier attempt.
+	Delivered []string
+}
+
+// Destination is where a topic's messages are delivered, such as a webhook.
+type Destination struct {
+	// Name identifies the destination across restarts, to record that it
+	// received a message.
+	Name string
+
+	// Key limits the destination to messages with the same key. Empty
+	// receives every message of the topic.
+	Key     string
+	Deliver func(ctx context.Context, payload []byte) error
+}
+
+// Store keeps the messages waiting to be delivered.
+type Store interface {
+	// Claim returns up to limit messages due at now, hiding them from other
+	// claims until the lease ends, and counts the attempt.
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Message, error)
+
+	// MarkDelivered records that destination received the message.
+	MarkDelivered(ctx context.Context, id int64, destination string) error
+
+	// Complete deletes a message every destination received.
+	Complete(ctx context.Context, id int64) error
+
+	// Retry makes the message due again at the given time.
+	Retry(ctx context.Context, id int64, at time.Time, lastError string) error
+
+	// Fail stops retrying the message, keeping it for inspection.
+	Fail(ctx context.Context, id int64, lastError string) error
+}
+
+// Dispatcher delivers the outbox's messages to the destinations subscribed to
+// their topics. Several replicas can run one against the same store; each
+// message is claimed by one of them at a time.
+type Dispatcher struct {
+	store        Store
+	destinations map[string][]Destination
+	logger       *slog.Logger
+	now          func() time.Time
+}
+
+func NewDispatcher(store Store, logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		store:        store,
+		destinations: make(map[string][]Destination),
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// Subscribe delivers the topic's messages to destinations. It must be called
+// before Run.
+func (d *Dispatcher) Subscribe(topic string, destinations ...Destination) {
+	d.destinations[topic] = append(d.destinations[topic], destinations...)
+}
+
+// Run delivers due messages until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.Dispatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch delivers the messages that are due now.
+func (d *Dispatcher) Dispatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		messages, err := d.store.Claim(ctx, d.now(), lease, batchSize)
+		if err != nil {
+			d.logger.ErrorContext(ctx, "failed to claim outbox messages", "error", err)
+			return
+		}
+
+		for _, message := range messages {
+			d.deliver(ctx, message)
+		}
+
+		if len(messages) < batchSize {
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, message Message) {
+	var errs []error
+	for _, destination := range d.destinations[message.Topic] {
+		if destination.Key != "" && destination.Key != message.Key {
+			continue
+		}
+		if slices.Contains(message.Delivered, destination.Name) {
+			continue
+		}
+
+		if err := destination.Deliver(ctx, message.Payload); err != nil {
+			d.logger.WarnContext(ctx, "failed to deliver outbox message", "id", message.ID, "topic", message.Topic, "destination", destination.Name, "attempt", message.Attempts, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+
+		if err := d.store.MarkDelivered(ctx, message.ID, destination.Name); err != nil {
+			d.logger.ErrorContext(ctx, "failed to record outbox delivery", "id", message.ID, "destination", destination.Name, "error", err)
+			errs = append(errs, err)
+		}
+	}
+
+	// The outcome is saved even when shutdown cut the deliveries short, so
+	// that the message is retried soon rather than when its lease ends.
+	ctx = context.WithoutCancel(ctx)
+
+	var err error
+	switch {
+	case len(errs) == 0:
+		err = d.store.Complete(ctx, message.ID)
+	case message.Attempts >= maxAttempts:
+		d.logger.ErrorContext(ctx, "giving up on outbox message", "id", message.ID, "topic", message.Topic, "attempts", message.Attempts, "error", errors.Join(errs...))
+		err = d.store.Fail(ctx, message.ID, errors.Join(errs...).Error())
+	default:
+		err = d.store.Retry(ctx, message.ID, d.now().Add(backoff(message.Attempts)), errors.Join(errs...).Error())
+	}
+	if err != nil {
+		d.logger.ErrorContext(ctx, "failed to update outbox message", "id", message.ID, "error", err)
+	}
+}
+
+// backoff is how long to wait before the attempt after the given one,
+// doubling from baseBackoff up to maxBackoff.
+func backoff(attempt int) time.Duration {
+	delay := baseBackoff
+	for range attempt - 1 {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	return delay
+}
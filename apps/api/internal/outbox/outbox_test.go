@@ -0,0 +1,147 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeStore struct {
+	messages  []Message
+	delivered map[int64][]string
+	completed []int64
+	retried   map[int64]time.Time
+	failed    map[int64]string
+}
+
+func newFakeStore(messages ...Message) *fakeStore {
+	return &fakeStore{
+		messages:  messages,
+		delivered: make(map[int64][]string),
+		retried:   make(map[int64]time.Time),
+		failed:    make(map[int64]string),
+	}
+}
+
+func (f *fakeStore) Claim(_ context.Context, _ time.Time, _ time.Duration, limit int) ([]Message, error) {
+	claimed := f.messages[:min(limit, len(f.messages))]
+	f.messages = f.messages[len(claimed):]
+
+	return claimed, nil
+}
+
+func (f *fakeStore) MarkDelivered(_ context.Context, id int64, destination string) error {
+	f.delivered[id] = append(f.delivered[id], destination)
+	return nil
+}
+
+func (f *fakeStore) Complete(_ context.Context, id int64) error {
+	f.completed = append(f.completed, id)
+	return nil
+}
+
+func (f *fakeStore) Retry(_ context.Context, id int64, at time.Time, _ string) error {
+	f.retried[id] = at
+	return nil
+}
+
+func (f *fakeStore) Fail(_ context.Context, id int64, lastError string) error {
+	f.failed[id] = lastError
+	return nil
+}
+
+type recordingDestination struct {
+	payloads []string
+	err      error
+}
+
+func (r *recordingDestination) destination(name, key string) Destination {
+	return Destination{Name: name, Key: key, Deliver: func(_ context.Context, payload []byte) error {
+		if r.err != nil {
+			return r.err
+		}
+
+		r.payloads = append(r.payloads, string(payload))
+		return nil
+	}}
+}
+
+func testDispatcher(store Store) *Dispatcher {
+	dispatcher := NewDispatcher(store, slog.New(slog.DiscardHandler))
+	dispatcher.now = func() time.Time { return time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC) }
+
+	return dispatcher
+}
+
+func TestDispatchDeliversToDestinationsWithMatchingKey(t *testing.T) {
+	t.Parallel()
+
+	store := newFakeStore(
+		Message{ID: 1, Topic: "movies_added", Key: "cuttack", Payload: []byte("a"), Attempts: 1},
+		Message{ID: 2, Topic: "movies_added", Key: "puri", Payload: []byte("b"), Attempts: 1},
+	)
+	cuttack, every := &recordingDestination{}, &recordingDestination{}
+
+	dispatcher := testDispatcher(store)
+	dispatcher.Subscribe("movies_added", cuttack.destination("slack:cuttack", "cuttack"), every.destination("audit", ""))
+	dispatcher.Dispatch(context.Background())
+
+	if !reflect.DeepEqual(cuttack.payloads, []string{"a"}) || !reflect.DeepEqual(every.payloads, []string{"a", "b"}) {
+		t.Fatalf("delivered cuttack = %v, every = %v, want a to both and b to every", cuttack.payloads, every.payloads)
+	}
+
+	if !reflect.DeepEqual(store.completed, []int64{1, 2}) {
+		t.Fatalf("completed = %v, want both messages", store.completed)
+	}
+}
+
+func TestDispatchRetriesOnlyFailedDestinations(t *testing.T) {
+	t.Parallel()
+
+	store := newFakeStore(Message{ID: 7, Topic: "movies_added", Payload: []byte("a"), Attempts: 3, Delivered: []string{"discord"}})
+	discord, slack := &recordingDestination{}, &recordingDestination{err: errors.New("503 Service Unavailable")}
+
+	dispatcher := testDispatcher(store)
+	dispatcher.Subscribe("movies_added", discord.destination("discord", ""), slack.destination("slack", ""))
+	dispatcher.Dispatch(context.Background())
+
+	if len(discord.payloads) != 0 {
+		t.Fatalf("discord payloads = %v, want none for a destination that already received the message", discord.payloads)
+	}
+
+	if want := dispatcher.now().Add(2 * time.Minute); !store.retried[7].Equal(want) {
+		t.Fatalf("retried at %s, want %s after the third attempt", store.retried[7], want)
+	}
+
+	if len(store.completed) != 0 {
+		t.Fatalf("completed = %v, want none while a destination fails", store.completed)
+	}
+}
+
+func TestDispatchGivesUpAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	store := newFakeStore(Message{ID: 9, Topic: "movies_added", Attempts: maxAttempts})
+	failing := &recordingDestination{err: errors.New("404 Not Found")}
+
+	dispatcher := testDispatcher(store)
+	dispatcher.Subscribe("movies_added", failing.destination("slack", ""))
+	dispatcher.Dispatch(context.Background())
+
+	if store.failed[9] != "404 Not Found" {
+		t.Fatalf("failed = %v, want the message failed with the last error", store.failed)
+	}
+}
+
+func TestBackoffDoublesUpToMax(t *testing.T) {
+	t.Parallel()
+
+	for attempt, want := range map[int]time.Duration{1: 30 * time.Second, 2: time.Minute, 4: 4 * time.Minute, 9: time.Hour} {
+		if got := backoff(attempt); got != want {
+			t.Fatalf("backoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+
+	"go-scraping/internal/movies"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AliasStore struct {
+	pool *pgxpool.Pool
+}
+
+var _ movies.AliasStore = (*AliasStore)(nil)
+
+func NewAliasStore(pool *pgxpool.Pool) *AliasStore {
+	return &AliasStore{pool: pool}
+}
+
+func (s *AliasStore) ListAliases(ctx context.Context) ([]movies.Alias, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT alias, canonical, created_at FROM title_aliases
+		ORDER BY alias
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []movies.Alias{}
+	for rows.Next() {
+		var alias movies.Alias
+		if err := rows.Scan(&alias.Alias, &alias.Canonical, &alias.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		result = append(result, alias)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *AliasStore) UpsertAlias(ctx context.Context, alias movies.Alias) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO title_aliases (alias, canonical, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (alias) DO UPDATE SET canonical = EXCLUDED.canonical
+	`, alias.Alias, alias.Canonical, alias.CreatedAt)
+
+	return err
+}
+
+func (s *AliasStore) DeleteAlias(ctx context.Context, alias string) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM title_aliases WHERE alias = $1`, alias)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"go-scraping/internal/cities"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CityStore struct {
+	pool *pgxpool.Pool
+}
+
+var _ cities.Store = (*CityStore)(nil)
+
+func NewCityStore(pool *pgxpool.Pool) *CityStore {
+	return &CityStore{pool: pool}
+}
+
+func (s *CityStore) ListCities(ctx context.Context) ([]cities.City, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT name, display_name, timezone, aliases FROM onboarded_cities
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []cities.City{}
+	for rows.Next() {
+		var city cities.City
+		if err := rows.Scan(&city.Name, &city.DisplayName, &city.Timezone, &city.Aliases); err != nil {
+			return nil, err
+		}
+
+		result = append(result, city)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *CityStore) AddCity(ctx context.Context, city cities.City) error {
+	tag, err := s.pool.Exec(ctx, `
+		INSERT INTO onboarded_cities (name, display_name, timezone, aliases)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO NOTHING
+	`, city.Name, city.DisplayName, city.Timezone, nonNil(city.Aliases))
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("city %s: name is %w", city.Name, cities.ErrNameUsed)
+	}
+
+	return nil
+}
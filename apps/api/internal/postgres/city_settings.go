@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"go-scraping/internal/movies"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CitySettingsStore struct {
+	pool *pgxpool.Pool
+}
+
+var _ movies.CitySettingsStore = (*CitySettingsStore)(nil)
+
+func NewCitySettingsStore(pool *pgxpool.Pool) *CitySettingsStore {
+	return &CitySettingsStore{pool: pool}
+}
+
+func (s *CitySettingsStore) ListCitySettings(ctx context.Context) ([]movies.CitySettings, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT city, disabled, cache_ttl_seconds, stale_policy, updated_at FROM city_settings
+		ORDER BY city
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []movies.CitySettings{}
+	for rows.Next() {
+		var (
+			settings   movies.CitySettings
+			ttlSeconds int64
+		)
+		if err := rows.Scan(&settings.City, &settings.Disabled, &ttlSeconds, &settings.StalePolicy, &settings.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		settings.CacheTTL = time.Duration(ttlSeconds) * time.Second
+		result = append(result, settings)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *CitySettingsStore) UpsertCitySettings(ctx context.Context, settings movies.CitySettings) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO city_settings (city, disabled, cache_ttl_seconds, stale_policy, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (city) DO UPDATE SET
+			disabled = EXCLUDED.disabled,
+			cache_ttl_seconds = EXCLUDED.cache_ttl_seconds,
+			stale_policy = EXCLUDED.stale_policy,
+			updated_at = EXCLUDED.updated_at
+	`, settings.City, settings.Disabled, int64(settings.CacheTTL/time.Second), settings.StalePolicy, settings.UpdatedAt)
+
+	return err
+}
@@ -2,12 +2,21 @@ package postgres
 
 import (
 	"context"
+	_ "embed"
+	"fmt"
+	"time"
 
 	"go-scraping/internal/config"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// migrateLockKey identifies the session-level advisory lock held while a
+// replica migrates, so replicas starting together migrate one at a time.
+const migrateLockKey int64 = 7_264_811_002
+
+const migrateUnlockTimeout = 5 * time.Second
+
 func NewPool(ctx context.Context, cfg config.Config) (*pgxpool.Pool, error) {
 	pool, err := pgxpool.New(ctx, cfg.ConnectionString())
 	if err != nil {
@@ -19,30 +28,137 @@ func NewPool(ctx context.Context, cfg config.Config) (*pgxpool.Pool, error) {
 		return nil, err
 	}
 
-	if err := ensureSchema(ctx, pool); err != nil {
-		pool.Close()
-		return nil, err
-	}
-
 	return pool, nil
 }
 
-func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
+// schema creates every table the API uses. Each statement is idempotent, so
+// it is safe to apply to a database that already has some or all of them.
+//
+//go:embed schema.sql
+var schema string
+
+// Migrate applies the schema and then the upgrades that databases created by
+// earlier versions need. It holds an advisory lock throughout, since
+// replicas starting together would otherwise race to alter the same tables.
+func Migrate(ctx context.Context, pool *pgxpool.Pool) error {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrateLockKey); err != nil {
+		return fmt.Errorf("lock migrations: %w", err)
+	}
+	defer func() {
+		unlockCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), migrateUnlockTimeout)
+		defer cancel()
+
+		// Closing the connection ends the session and so releases the lock
+		// when unlocking fails.
+		if _, err := conn.Exec(unlockCtx, `SELECT pg_advisory_unlock($1)`, migrateLockKey); err != nil {
+			_ = conn.Conn().Close(unlockCtx)
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, schema); err != nil {
+		return fmt.Errorf("apply schema: %w", err)
+	}
+
 	queries := []string{
 		`
 			CREATE TABLE IF NOT EXISTS city_scrapes (
 				city VARCHAR(100) PRIMARY KEY,
-				scraped_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+				scraped_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
 			)
 		`,
 		`CREATE INDEX IF NOT EXISTS idx_city_scrapes_scraped_at ON city_scrapes(scraped_at)`,
+		`ALTER TABLE movies ADD COLUMN IF NOT EXISTS release_year INTEGER`,
+		`ALTER TABLE movies ADD COLUMN IF NOT EXISTS genres TEXT[] NOT NULL DEFAULT '{}'`,
+		`ALTER TABLE movies ADD COLUMN IF NOT EXISTS languages TEXT[] NOT NULL DEFAULT '{}'`,
+		`ALTER TABLE movies ADD COLUMN IF NOT EXISTS cast_members TEXT[] NOT NULL DEFAULT '{}'`,
+		`ALTER TABLE movies ADD COLUMN IF NOT EXISTS buzz INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE movies ADD COLUMN IF NOT EXISTS rerelease BOOLEAN NOT NULL DEFAULT FALSE`,
+		`
+			CREATE TABLE IF NOT EXISTS search_log (
+				id BIGSERIAL PRIMARY KEY,
+				city VARCHAR(100) NOT NULL,
+				raw_query VARCHAR(500) NOT NULL,
+				normalized_query VARCHAR(500) NOT NULL,
+				result_count INTEGER NOT NULL,
+				zero_result BOOLEAN NOT NULL,
+				searched_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)
+		`,
+		`CREATE INDEX IF NOT EXISTS idx_search_log_searched_at ON search_log(searched_at)`,
+		`ALTER TABLE search_log ADD COLUMN IF NOT EXISTS top_title VARCHAR(500)`,
+		`
+			CREATE TABLE IF NOT EXISTS title_aliases (
+				alias VARCHAR(500) PRIMARY KEY,
+				canonical VARCHAR(500) NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)
+		`,
+		`ALTER TABLE city_settings ADD COLUMN IF NOT EXISTS stale_policy VARCHAR(32) NOT NULL DEFAULT ''`,
+		// Popularity is counted from movie_clicks, which already has every
+		// click these daily counts were kept for.
+		`DROP TABLE IF EXISTS movie_click_counts`,
+		// Databases from before timestamps carried their zone keep them as
+		// TIMESTAMP. Their values are wall-clock times in the session's
+		// timezone, which is how the conversion reads them.
+		`
+			DO $$
+			DECLARE
+				col RECORD;
+			BEGIN
+				FOR col IN
+					SELECT table_name, column_name
+					FROM information_schema.columns
+					WHERE table_schema = current_schema() AND data_type = 'timestamp without time zone'
+				LOOP
+					EXECUTE format('ALTER TABLE %I ALTER COLUMN %I TYPE TIMESTAMPTZ', col.table_name, col.column_name);
+				END LOOP;
+			END
+			$$
+		`,
 	}
 
 	for _, query := range queries {
-		if _, err := pool.Exec(ctx, query); err != nil {
-			return err
+		if _, err := conn.Exec(ctx, query); err != nil {
+			return fmt.Errorf("upgrade schema: %w", err)
 		}
 	}
 
-	return nil
+	return backfillListingEvents(ctx, conn)
+}
+
+// backfillListingEvents starts the event log of a database from before it
+// with the movies it already lists, so replaying the log gives their current
+// listings. It takes the lock that saving a scrape takes, so a replica still
+// running an earlier version cannot append events between the check for an
+// empty log and the backfill.
+func backfillListingEvents(ctx context.Context, conn *pgxpool.Conn) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("backfill listing events: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('listing_events'))`); err != nil {
+		return fmt.Errorf("lock listing events: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO listing_events (city, event_type, href, title, release_year, genres, languages, cast_members, occurred_at)
+		SELECT city, 'movie_added', href, title, release_year, genres, languages, cast_members, COALESCE(scraped_at, CURRENT_TIMESTAMP)
+		FROM movies
+		WHERE NOT EXISTS (SELECT 1 FROM listing_events)
+		ORDER BY city, id
+	`); err != nil {
+		return fmt.Errorf("backfill listing events: %w", err)
+	}
+
+	return tx.Commit(ctx)
 }
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"go-scraping/internal/digest"
+	"go-scraping/internal/movies"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DigestStore struct {
+	pool *pgxpool.Pool
+}
+
+var _ digest.Store = (*DigestStore)(nil)
+
+func NewDigestStore(pool *pgxpool.Pool) *DigestStore {
+	return &DigestStore{pool: pool}
+}
+
+func (s *DigestStore) Subscribe(ctx context.Context, subscription digest.Subscription) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO digest_subscriptions (email, city, token)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (email, city) DO NOTHING
+	`, subscription.Email, subscription.City, subscription.Token)
+
+	return err
+}
+
+func (s *DigestStore) Unsubscribe(ctx context.Context, token string) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM digest_subscriptions WHERE token = $1`, token)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *DigestStore) Subscriptions(ctx context.Context) ([]digest.Subscription, error) {
+	rows, err := s.pool.Query(ctx, `SELECT email, city, token FROM digest_subscriptions ORDER BY email, city`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []digest.Subscription
+	for rows.Next() {
+		var subscription digest.Subscription
+		if err := rows.Scan(&subscription.Email, &subscription.City, &subscription.Token); err != nil {
+			return nil, err
+		}
+
+		result = append(result, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *DigestStore) RecordAdditions(ctx context.Context, city string, added []movies.Movie, addedAt time.Time) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	for _, movie := range added {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO movie_additions (city, title, href, added_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (city, href) DO UPDATE SET title = EXCLUDED.title, added_at = EXCLUDED.added_at
+		`, city, movie.Title, movie.Href, addedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *DigestStore) Additions(ctx context.Context, city string, since time.Time) ([]movies.Movie, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT title, href FROM movie_additions
+		WHERE city = $1 AND added_at > $2
+		ORDER BY added_at, title
+	`, city, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []movies.Movie
+	for rows.Next() {
+		var movie movies.Movie
+		if err := rows.Scan(&movie.Title, &movie.Href); err != nil {
+			return nil, err
+		}
+
+		result = append(result, movie)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *DigestStore) DeleteAdditionsBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM movie_additions WHERE added_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
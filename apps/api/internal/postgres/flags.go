@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+
+	"go-scraping/internal/flags"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type FlagStore struct {
+	pool *pgxpool.Pool
+}
+
+var _ flags.Store = (*FlagStore)(nil)
+
+func NewFlagStore(pool *pgxpool.Pool) *FlagStore {
+	return &FlagStore{pool: pool}
+}
+
+func (s *FlagStore) ListFlags(ctx context.Context) ([]flags.Flag, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT name, enabled, cities, percent, updated_at FROM feature_flags
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []flags.Flag{}
+	for rows.Next() {
+		var flag flags.Flag
+		if err := rows.Scan(&flag.Name, &flag.Enabled, &flag.Cities, &flag.Percent, &flag.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		result = append(result, flag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *FlagStore) UpsertFlag(ctx context.Context, flag flags.Flag) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO feature_flags (name, enabled, cities, percent, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (name) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			cities = EXCLUDED.cities,
+			percent = EXCLUDED.percent,
+			updated_at = EXCLUDED.updated_at
+	`, flag.Name, flag.Enabled, nonNil(flag.Cities), flag.Percent, flag.UpdatedAt)
+
+	return err
+}
+
+func (s *FlagStore) DeleteFlag(ctx context.Context, name string) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM feature_flags WHERE name = $1`, name)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"go-scraping/internal/idempotency"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// IdempotencyKeys keeps the responses to admin requests in the database, so
+// that a retry is replayed whichever replica it reaches.
+type IdempotencyKeys struct {
+	pool *pgxpool.Pool
+	ttl  time.Duration
+}
+
+var _ idempotency.Store = (*IdempotencyKeys)(nil)
+
+func NewIdempotencyKeys(pool *pgxpool.Pool, ttl time.Duration) *IdempotencyKeys {
+	return &IdempotencyKeys{pool: pool, ttl: ttl}
+}
+
+// Begin deletes expired keys before claiming, since admin requests are too
+// few for them to pile up between claims.
+func (s *IdempotencyKeys) Begin(ctx context.Context, key, fingerprint string) (*idempotency.Response, error) {
+	now := time.Now().UTC()
+
+	if _, err := s.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at < $1`, now); err != nil {
+		return nil, err
+	}
+
+	tag, err := s.pool.Exec(ctx, `
+		INSERT INTO idempotency_keys (idempotency_key, fingerprint, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (idempotency_key) DO NOTHING
+	`, key, fingerprint, now.Add(s.ttl))
+	if err != nil {
+		return nil, err
+	}
+
+	if tag.RowsAffected() == 1 {
+		return nil, nil
+	}
+
+	var (
+		saved  string
+		status *int
+		header http.Header
+		body   []byte
+	)
+	err = s.pool.QueryRow(ctx, `
+		SELECT fingerprint, status, header, body FROM idempotency_keys WHERE idempotency_key = $1
+	`, key).Scan(&saved, &status, &header, &body)
+	switch {
+	// The request that held the key was abandoned since the insert.
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, idempotency.ErrInProgress
+	case err != nil:
+		return nil, err
+	case saved != fingerprint:
+		return nil, idempotency.ErrKeyReused
+	case status == nil:
+		return nil, idempotency.ErrInProgress
+	}
+
+	return &idempotency.Response{Status: *status, Header: header, Body: body}, nil
+}
+
+func (s *IdempotencyKeys) Complete(ctx context.Context, key string, response idempotency.Response) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE idempotency_keys SET status = $2, header = $3, body = $4, expires_at = $5
+		WHERE idempotency_key = $1
+	`, key, response.Status, response.Header, response.Body, time.Now().Add(s.ttl).UTC())
+
+	return err
+}
+
+func (s *IdempotencyKeys) Abandon(ctx context.Context, key string) error {
+	_, err := s.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE idempotency_key = $1 AND status IS NULL`, key)
+
+	return err
+}
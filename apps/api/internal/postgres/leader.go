@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go-scraping/internal/jobs"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// schedulerLockKey identifies the session-level advisory lock held by the
+// replica running background jobs.
+const schedulerLockKey int64 = 7_264_811_001
+
+const (
+	leaderRetryInterval = 10 * time.Second
+	leaderCheckInterval = 5 * time.Second
+	leaderUnlockTimeout = 5 * time.Second
+)
+
+// LeaderElector elects a single scheduler leader across replicas with a
+// Postgres advisory lock. The lock lives as long as the connection that took
+// it, so a replica that dies releases leadership with its session.
+type LeaderElector struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+var _ jobs.Elector = (*LeaderElector)(nil)
+
+func NewLeaderElector(pool *pgxpool.Pool, logger *slog.Logger) *LeaderElector {
+	return &LeaderElector{pool: pool, logger: logger}
+}
+
+func (e *LeaderElector) Campaign(ctx context.Context) (context.Context, error) {
+	for {
+		conn, acquired, err := e.tryLock(ctx)
+		if err != nil && ctx.Err() == nil {
+			e.logger.ErrorContext(ctx, "failed to campaign for scheduler leadership", "error", err)
+		}
+
+		if acquired {
+			leaderCtx, cancel := context.WithCancel(ctx)
+			go e.hold(leaderCtx, cancel, conn)
+
+			return leaderCtx, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(leaderRetryInterval):
+		}
+	}
+}
+
+func (e *LeaderElector) tryLock(ctx context.Context) (*pgxpool.Conn, bool, error) {
+	conn, err := e.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, schedulerLockKey).Scan(&acquired); err != nil {
+		conn.Release()
+		return nil, false, err
+	}
+
+	if !acquired {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	return conn, true, nil
+}
+
+// hold keeps the locked connection checked out, cancelling leadership as soon
+// as the connection stops responding, and unlocks it once leadership ends.
+func (e *LeaderElector) hold(ctx context.Context, cancel context.CancelFunc, conn *pgxpool.Conn) {
+	defer cancel()
+
+	ticker := time.NewTicker(leaderCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			unlockCtx, cancelUnlock := context.WithTimeout(context.Background(), leaderUnlockTimeout)
+			defer cancelUnlock()
+
+			if _, err := conn.Exec(unlockCtx, `SELECT pg_advisory_unlock($1)`, schedulerLockKey); err != nil {
+				_ = conn.Conn().Close(unlockCtx)
+			}
+			conn.Release()
+
+			return
+		case <-ticker.C:
+			if err := conn.Ping(ctx); err != nil && ctx.Err() == nil {
+				e.logger.ErrorContext(ctx, "lost scheduler leadership connection", "error", err)
+
+				_ = conn.Conn().Close(context.Background())
+				conn.Release()
+
+				return
+			}
+		}
+	}
+}
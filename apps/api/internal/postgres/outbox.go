@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"go-scraping/internal/outbox"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OutboxStore struct {
+	pool *pgxpool.Pool
+}
+
+var _ outbox.Store = (*OutboxStore)(nil)
+
+func NewOutboxStore(pool *pgxpool.Pool) *OutboxStore {
+	return &OutboxStore{pool: pool}
+}
+
+// enqueue adds a message to the outbox in tx, so that it is delivered exactly
+// when tx commits. Times are stored in UTC.
+func enqueue(ctx context.Context, tx pgx.Tx, topic, key string, payload any, at time.Time) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode %s message: %w", topic, err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO outbox (topic, message_key, payload, created_at, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $4)
+	`, topic, key, body, at.UTC())
+
+	return err
+}
+
+// Claim skips messages locked by a concurrent claim, so replicas claiming at
+// the same time get different messages.
+func (s *OutboxStore) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]outbox.Message, error) {
+	rows, err := s.pool.Query(ctx, `
+		UPDATE outbox SET attempts = attempts + 1, next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE failed_at IS NULL AND next_attempt_at <= $1
+			ORDER BY id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, topic, message_key, payload, attempts,
+			ARRAY(SELECT destination FROM outbox_deliveries WHERE message_id = outbox.id)
+	`, now.UTC(), now.Add(lease).UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []outbox.Message
+	for rows.Next() {
+		var message outbox.Message
+		if err := rows.Scan(&message.ID, &message.Topic, &message.Key, &message.Payload, &message.Attempts, &message.Delivered); err != nil {
+			return nil, err
+		}
+
+		result = append(result, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(result, func(a, b outbox.Message) int { return cmp.Compare(a.ID, b.ID) })
+
+	return result, nil
+}
+
+func (s *OutboxStore) MarkDelivered(ctx context.Context, id int64, destination string) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO outbox_deliveries (message_id, destination, delivered_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`, id, destination, time.Now().UTC())
+
+	return err
+}
+
+// Complete also deletes the message's deliveries, which cascade.
+func (s *OutboxStore) Complete(ctx context.Context, id int64) error {
+	_, err := s.pool.Exec(ctx, `DELETE FROM outbox WHERE id = $1`, id)
+
+	return err
+}
+
+func (s *OutboxStore) Retry(ctx context.Context, id int64, at time.Time, lastError string) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE outbox SET next_attempt_at = $2, last_error = $3 WHERE id = $1
+	`, id, at.UTC(), lastError)
+
+	return err
+}
+
+func (s *OutboxStore) Fail(ctx context.Context, id int64, lastError string) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE outbox SET failed_at = $2, last_error = $3 WHERE id = $1
+	`, id, time.Now().UTC(), lastError)
+
+	return err
+}
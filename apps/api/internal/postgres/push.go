@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+
+	"go-scraping/internal/webpush"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PushSubscriptions struct {
+	pool *pgxpool.Pool
+}
+
+var _ webpush.Store = (*PushSubscriptions)(nil)
+
+func NewPushSubscriptions(pool *pgxpool.Pool) *PushSubscriptions {
+	return &PushSubscriptions{pool: pool}
+}
+
+func (s *PushSubscriptions) Save(ctx context.Context, subscription webpush.Subscription) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO push_subscriptions (endpoint, p256dh, auth, city, titles)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (endpoint) DO UPDATE SET
+			p256dh = EXCLUDED.p256dh,
+			auth = EXCLUDED.auth,
+			city = EXCLUDED.city,
+			titles = EXCLUDED.titles
+	`, subscription.Endpoint, subscription.Keys.P256DH, subscription.Keys.Auth, subscription.City, subscription.Titles)
+
+	return err
+}
+
+func (s *PushSubscriptions) Delete(ctx context.Context, endpoint string) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM push_subscriptions WHERE endpoint = $1`, endpoint)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *PushSubscriptions) Subscriptions(ctx context.Context, city string) ([]webpush.Subscription, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT endpoint, p256dh, auth, city, titles FROM push_subscriptions
+		WHERE city = $1
+	`, city)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []webpush.Subscription
+	for rows.Next() {
+		var subscription webpush.Subscription
+		if err := rows.Scan(&subscription.Endpoint, &subscription.Keys.P256DH, &subscription.Keys.Auth, &subscription.City, &subscription.Titles); err != nil {
+			return nil, err
+		}
+
+		result = append(result, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go-scraping/internal/movies"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type QuarantineStore struct {
+	pool *pgxpool.Pool
+}
+
+var _ movies.QuarantineStore = (*QuarantineStore)(nil)
+
+func NewQuarantineStore(pool *pgxpool.Pool) *QuarantineStore {
+	return &QuarantineStore{pool: pool}
+}
+
+func (s *QuarantineStore) SaveQuarantined(ctx context.Context, scrape movies.QuarantinedScrape) error {
+	body, err := json.Marshal(scrape.Movies)
+	if err != nil {
+		return fmt.Errorf("encode quarantined movies: %w", err)
+	}
+
+	_, err = s.pool.Exec(ctx, `
+		INSERT INTO quarantined_scrapes (city, movies, previous_count, problems, scraped_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (city) DO UPDATE SET
+			movies = EXCLUDED.movies,
+			previous_count = EXCLUDED.previous_count,
+			problems = EXCLUDED.problems,
+			scraped_at = EXCLUDED.scraped_at
+	`, scrape.City, body, scrape.PreviousCount, scrape.Problems, scrape.ScrapedAt)
+
+	return err
+}
+
+func (s *QuarantineStore) ListQuarantined(ctx context.Context) ([]movies.QuarantinedScrape, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT city, movies, previous_count, problems, scraped_at FROM quarantined_scrapes
+		ORDER BY scraped_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []movies.QuarantinedScrape{}
+	for rows.Next() {
+		scrape, err := scanQuarantined(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, scrape)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *QuarantineStore) GetQuarantined(ctx context.Context, city string) (movies.QuarantinedScrape, bool, error) {
+	row := s.pool.QueryRow(ctx, `
+		SELECT city, movies, previous_count, problems, scraped_at FROM quarantined_scrapes
+		WHERE city = $1
+	`, city)
+
+	scrape, err := scanQuarantined(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return movies.QuarantinedScrape{}, false, nil
+	}
+	if err != nil {
+		return movies.QuarantinedScrape{}, false, err
+	}
+
+	return scrape, true, nil
+}
+
+func (s *QuarantineStore) DeleteQuarantined(ctx context.Context, city string) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM quarantined_scrapes WHERE city = $1`, city)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func scanQuarantined(row pgx.Row) (movies.QuarantinedScrape, error) {
+	var (
+		scrape movies.QuarantinedScrape
+		body   []byte
+	)
+	if err := row.Scan(&scrape.City, &body, &scrape.PreviousCount, &scrape.Problems, &scrape.ScrapedAt); err != nil {
+		return movies.QuarantinedScrape{}, err
+	}
+
+	if err := json.Unmarshal(body, &scrape.Movies); err != nil {
+		return movies.QuarantinedScrape{}, fmt.Errorf("decode quarantined movies: %w", err)
+	}
+
+	return scrape, nil
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-scraping/internal/movies"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RatingsStore struct {
+	pool *pgxpool.Pool
+}
+
+var _ movies.RatingsStore = (*RatingsStore)(nil)
+
+func NewRatingsStore(pool *pgxpool.Pool) *RatingsStore {
+	return &RatingsStore{pool: pool}
+}
+
+func (s *RatingsStore) GetRatings(ctx context.Context, title string, year int, since time.Time) (*movies.Ratings, bool, error) {
+	var (
+		found   bool
+		ratings movies.Ratings
+	)
+
+	err := s.pool.QueryRow(ctx, `
+		SELECT found, imdb_id, imdb_rating, rotten_tomatoes, metacritic FROM movie_ratings
+		WHERE title = $1 AND release_year = $2 AND fetched_at > $3
+	`, title, year, since).Scan(&found, &ratings.IMDbID, &ratings.IMDb, &ratings.RottenTomatoes, &ratings.Metacritic)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !found {
+		return nil, true, nil
+	}
+
+	return &ratings, true, nil
+}
+
+func (s *RatingsStore) SaveRatings(ctx context.Context, title string, year int, ratings *movies.Ratings, fetchedAt time.Time) error {
+	var saved movies.Ratings
+	if ratings != nil {
+		saved = *ratings
+	}
+
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO movie_ratings (title, release_year, found, imdb_id, imdb_rating, rotten_tomatoes, metacritic, fetched_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (title, release_year) DO UPDATE SET
+			found = EXCLUDED.found,
+			imdb_id = EXCLUDED.imdb_id,
+			imdb_rating = EXCLUDED.imdb_rating,
+			rotten_tomatoes = EXCLUDED.rotten_tomatoes,
+			metacritic = EXCLUDED.metacritic,
+			fetched_at = EXCLUDED.fetched_at
+	`, title, year, ratings != nil, saved.IMDbID, saved.IMDb, saved.RottenTomatoes, saved.Metacritic, fetchedAt)
+
+	return err
+}
@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-scraping/internal/reminders"
+	"go-scraping/internal/webpush"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReminderStore struct {
+	pool *pgxpool.Pool
+}
+
+var _ reminders.Store = (*ReminderStore)(nil)
+
+func NewReminderStore(pool *pgxpool.Pool) *ReminderStore {
+	return &ReminderStore{pool: pool}
+}
+
+func (s *ReminderStore) Create(ctx context.Context, reminder reminders.Reminder) (reminders.Reminder, error) {
+	var endpoint *string
+	var p256dh, auth []byte
+	if reminder.Push != nil {
+		endpoint = &reminder.Push.Endpoint
+		p256dh = reminder.Push.Keys.P256DH
+		auth = reminder.Push.Keys.Auth
+	}
+
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO reminders (user_id, title, city, channel, push_endpoint, push_p256dh, push_auth)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, reminder.UserID, reminder.Title, reminder.City, reminder.Channel, endpoint, p256dh, auth).Scan(&reminder.ID, &reminder.CreatedAt)
+	if err != nil {
+		return reminders.Reminder{}, err
+	}
+
+	return reminder, nil
+}
+
+func (s *ReminderStore) List(ctx context.Context, userID int64) ([]reminders.Reminder, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, user_id, title, city, channel, created_at, fulfilled_at
+		FROM reminders
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []reminders.Reminder{}
+	for rows.Next() {
+		var reminder reminders.Reminder
+		if err := rows.Scan(&reminder.ID, &reminder.UserID, &reminder.Title, &reminder.City, &reminder.Channel, &reminder.CreatedAt, &reminder.FulfilledAt); err != nil {
+			return nil, err
+		}
+
+		result = append(result, reminder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *ReminderStore) CountActive(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := s.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM reminders WHERE user_id = $1 AND fulfilled_at IS NULL
+	`, userID).Scan(&count)
+
+	return count, err
+}
+
+func (s *ReminderStore) Delete(ctx context.Context, userID, id int64) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `
+		DELETE FROM reminders WHERE user_id = $1 AND id = $2
+	`, userID, id)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *ReminderStore) Pending(ctx context.Context, city string) ([]reminders.Reminder, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT r.id, r.user_id, r.title, r.city, r.channel, r.created_at,
+			r.push_endpoint, r.push_p256dh, r.push_auth, u.email, COALESCE(t.chat_id, 0)
+		FROM reminders r
+		JOIN users u ON u.id = r.user_id
+		LEFT JOIN telegram_chats t ON t.user_id = r.user_id
+		WHERE r.city = $1 AND r.fulfilled_at IS NULL
+	`, city)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []reminders.Reminder
+	for rows.Next() {
+		var (
+			reminder     reminders.Reminder
+			endpoint     *string
+			p256dh, auth []byte
+		)
+		if err := rows.Scan(&reminder.ID, &reminder.UserID, &reminder.Title, &reminder.City, &reminder.Channel, &reminder.CreatedAt,
+			&endpoint, &p256dh, &auth, &reminder.Email, &reminder.TelegramChatID); err != nil {
+			return nil, err
+		}
+
+		if endpoint != nil {
+			reminder.Push = &reminders.PushTarget{
+				Endpoint: *endpoint,
+				Keys:     webpush.Keys{P256DH: p256dh, Auth: auth},
+			}
+		}
+
+		result = append(result, reminder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *ReminderStore) MarkFulfilled(ctx context.Context, id int64, at time.Time) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE reminders SET fulfilled_at = $2 WHERE id = $1
+	`, id, at)
+
+	return err
+}
+
+func (s *ReminderStore) LinkTelegramChat(ctx context.Context, userID, chatID int64) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO telegram_chats (user_id, chat_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET chat_id = EXCLUDED.chat_id, linked_at = CURRENT_TIMESTAMP
+	`, userID, chatID)
+
+	return err
+}
+
+func (s *ReminderStore) TelegramChat(ctx context.Context, userID int64) (int64, bool, error) {
+	var chatID int64
+	err := s.pool.QueryRow(ctx, `
+		SELECT chat_id FROM telegram_chats WHERE user_id = $1
+	`, userID).Scan(&chatID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	return chatID, true, nil
+}
@@ -2,10 +2,13 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"go-scraping/internal/movies"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -21,7 +24,7 @@ func NewMovieRepository(pool *pgxpool.Pool) *MovieRepository {
 
 func (r *MovieRepository) ListFresh(ctx context.Context, city string, since time.Time) ([]movies.Movie, error) {
 	rows, err := r.pool.Query(ctx, `
-		SELECT title, href FROM movies
+		SELECT title, href, COALESCE(release_year, 0), genres, languages, cast_members, buzz, rerelease FROM movies
 		WHERE city = $1 AND scraped_at > $2
 		ORDER BY scraped_at DESC
 	`, city, since)
@@ -33,7 +36,7 @@ func (r *MovieRepository) ListFresh(ctx context.Context, city string, since time
 	var result []movies.Movie
 	for rows.Next() {
 		var movie movies.Movie
-		if err := rows.Scan(&movie.Title, &movie.Href); err != nil {
+		if err := rows.Scan(&movie.Title, &movie.Href, &movie.Year, &movie.Genres, &movie.Languages, &movie.Cast, &movie.Buzz, &movie.Rerelease); err != nil {
 			return nil, err
 		}
 
@@ -72,15 +75,34 @@ func (r *MovieRepository) ReplaceCity(ctx context.Context, city string, list []m
 		_ = tx.Rollback(ctx)
 	}()
 
+	events, hadPrevious, err := appendListingEvents(ctx, tx, city, list, scrapedAt)
+	if err != nil {
+		return err
+	}
+
+	if hadPrevious {
+		if err := enqueueAddedMovies(ctx, tx, city, events, scrapedAt); err != nil {
+			return err
+		}
+	}
+
 	if _, err := tx.Exec(ctx, `DELETE FROM movies WHERE city = $1`, city); err != nil {
 		return err
 	}
 
 	for _, movie := range list {
 		if _, err := tx.Exec(ctx, `
-			INSERT INTO movies (city, title, href, scraped_at)
-			VALUES ($1, $2, $3, $4)
-		`, city, movie.Title, movie.Href, scrapedAt); err != nil {
+			INSERT INTO movies (city, title, href, release_year, genres, languages, cast_members, buzz, rerelease, scraped_at)
+			VALUES ($1, $2, $3, NULLIF($4, 0), $5, $6, $7, $8, $9, $10)
+		`, city, movie.Title, movie.Href, movie.Year, nonNil(movie.Genres), nonNil(movie.Languages), nonNil(movie.Cast), movie.Buzz, movie.Rerelease, scrapedAt); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO movie_sightings (city, href, title, first_seen_at, last_seen_at)
+			VALUES ($1, $2, $3, $4, $4)
+			ON CONFLICT (city, href) DO UPDATE SET title = EXCLUDED.title, last_seen_at = EXCLUDED.last_seen_at
+		`, city, movie.Href, movie.Title, scrapedAt); err != nil {
 			return err
 		}
 	}
@@ -95,3 +117,360 @@ func (r *MovieRepository) ReplaceCity(ctx context.Context, city string, list []m
 
 	return tx.Commit(ctx)
 }
+
+// appendListingEvents records how list changes the city's saved movies, in the
+// same transaction that saves it, so the event log and the movies projected
+// from it cannot disagree. It returns the events and whether the city had
+// saved movies before.
+func appendListingEvents(ctx context.Context, tx pgx.Tx, city string, list []movies.Movie, scrapedAt time.Time) ([]movies.ListingEvent, bool, error) {
+	// Holding the lock until commit makes events commit in ID order, so a
+	// reader paging by ID never skips an event committed late.
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('listing_events'))`); err != nil {
+		return nil, false, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT title, href, COALESCE(release_year, 0), genres, languages, cast_members FROM movies
+		WHERE city = $1
+		ORDER BY id
+		FOR UPDATE
+	`, city)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var previous []movies.Movie
+	for rows.Next() {
+		var movie movies.Movie
+		if err := rows.Scan(&movie.Title, &movie.Href, &movie.Year, &movie.Genres, &movie.Languages, &movie.Cast); err != nil {
+			rows.Close()
+			return nil, false, err
+		}
+
+		previous = append(previous, movie)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	events := movies.DiffListings(city, previous, list, scrapedAt)
+	for _, event := range events {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO listing_events (city, event_type, href, title, release_year, genres, languages, cast_members, changed_fields, occurred_at)
+			VALUES ($1, $2, $3, $4, NULLIF($5, 0), $6, $7, $8, $9, $10)
+		`, event.City, event.Type, event.Movie.Href, event.Movie.Title, event.Movie.Year, nonNil(event.Movie.Genres), nonNil(event.Movie.Languages), nonNil(event.Movie.Cast), nonNil(event.Changed), event.OccurredAt); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return events, len(previous) > 0, nil
+}
+
+// enqueueAddedMovies queues the newly listed movies for announcement, in the
+// transaction that saves them.
+func enqueueAddedMovies(ctx context.Context, tx pgx.Tx, city string, events []movies.ListingEvent, scrapedAt time.Time) error {
+	var added []movies.Movie
+	for _, event := range events {
+		if event.Type == movies.EventMovieAdded {
+			added = append(added, event.Movie)
+		}
+	}
+
+	if len(added) == 0 {
+		return nil
+	}
+
+	return enqueue(ctx, tx, movies.TopicMoviesAdded, city, movies.AddedMovies{City: city, Movies: added}, scrapedAt)
+}
+
+func (r *MovieRepository) ListEvents(ctx context.Context, city string, after int64, limit int) ([]movies.ListingEvent, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, city, event_type, href, title, COALESCE(release_year, 0), genres, languages, cast_members, changed_fields, occurred_at
+		FROM listing_events
+		WHERE ($1 = '' OR city = $1) AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`, city, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+func scanEvents(rows pgx.Rows) ([]movies.ListingEvent, error) {
+	var result []movies.ListingEvent
+	for rows.Next() {
+		var event movies.ListingEvent
+		if err := rows.Scan(&event.ID, &event.City, &event.Type, &event.Movie.Href, &event.Movie.Title, &event.Movie.Year, &event.Movie.Genres, &event.Movie.Languages, &event.Movie.Cast, &event.Changed, &event.OccurredAt); err != nil {
+			return nil, err
+		}
+
+		result = append(result, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *MovieRepository) ListEventsBefore(ctx context.Context, city string, before time.Time) ([]movies.ListingEvent, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, city, event_type, href, title, COALESCE(release_year, 0), genres, languages, cast_members, changed_fields, occurred_at
+		FROM listing_events
+		WHERE city = $1 AND occurred_at < $2
+		ORDER BY id
+	`, city, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+func (r *MovieRepository) LastScrape(ctx context.Context, city string) (time.Time, bool, error) {
+	var scrapedAt time.Time
+
+	err := r.pool.QueryRow(ctx, `
+		SELECT scraped_at FROM city_scrapes WHERE city = $1
+	`, city).Scan(&scrapedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return scrapedAt, true, nil
+}
+
+func (r *MovieRepository) ListScrapes(ctx context.Context) ([]movies.CityScrape, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT cs.city, cs.scraped_at, COUNT(m.id)
+		FROM city_scrapes cs
+		LEFT JOIN movies m ON m.city = cs.city
+		GROUP BY cs.city, cs.scraped_at
+		ORDER BY cs.city
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []movies.CityScrape
+	for rows.Next() {
+		var scrape movies.CityScrape
+		if err := rows.Scan(&scrape.City, &scrape.ScrapedAt, &scrape.MovieCount); err != nil {
+			return nil, err
+		}
+
+		result = append(result, scrape)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteScrapedBefore removes the movies and scrape records of every city last
+// scraped before the cutoff, along with sightings of movies not listed since,
+// returning the number of movies deleted.
+func (r *MovieRepository) DeleteScrapedBefore(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// The expired movies are removed from the event log too, so that it
+	// still replays to the saved listings.
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('listing_events'))`); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO listing_events (city, event_type, href, title, release_year, genres, languages, cast_members, occurred_at)
+		SELECT city, 'movie_removed', href, title, release_year, genres, languages, cast_members, $2::timestamptz
+		FROM movies
+		WHERE scraped_at < $1
+		ORDER BY city, id
+	`, before, time.Now()); err != nil {
+		return 0, err
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM movies WHERE scraped_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM city_scrapes WHERE scraped_at < $1`, before); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM movie_sightings WHERE last_seen_at < $1`, before); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (r *MovieRepository) ListSightings(ctx context.Context, city string, since time.Time, limit int) ([]movies.Sighting, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT s.title, s.href, COALESCE(m.release_year, 0), COALESCE(m.genres, '{}'), COALESCE(m.languages, '{}'), s.first_seen_at, s.last_seen_at
+		FROM movie_sightings s
+		LEFT JOIN movies m ON m.city = s.city AND m.href = s.href
+		WHERE s.city = $1 AND s.first_seen_at > $2
+		ORDER BY s.first_seen_at DESC, s.title
+		LIMIT NULLIF($3, 0)
+	`, city, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanSightings(rows, city)
+}
+
+// ListChanges reads both lists from movie_sightings: a movie is listed now if
+// the latest scrape saved it to movies, and was listed at since if a scrape
+// found it then or later and it was first seen before.
+func (r *MovieRepository) ListChanges(ctx context.Context, city string, since time.Time) (movies.Changes, error) {
+	var changes movies.Changes
+
+	added, err := r.pool.Query(ctx, `
+		SELECT s.title, s.href, COALESCE(m.release_year, 0), m.genres, m.languages, s.first_seen_at, s.last_seen_at
+		FROM movie_sightings s
+		JOIN movies m ON m.city = s.city AND m.href = s.href
+		WHERE s.city = $1 AND s.first_seen_at > $2
+		ORDER BY s.first_seen_at DESC, s.title
+	`, city, since)
+	if err != nil {
+		return movies.Changes{}, err
+	}
+
+	changes.Added, err = scanSightings(added, city)
+	if err != nil {
+		return movies.Changes{}, err
+	}
+
+	removed, err := r.pool.Query(ctx, `
+		SELECT s.title, s.href, 0, '{}'::TEXT[], '{}'::TEXT[], s.first_seen_at, s.last_seen_at
+		FROM movie_sightings s
+		WHERE s.city = $1 AND s.first_seen_at <= $2 AND s.last_seen_at >= $2
+			AND NOT EXISTS (SELECT 1 FROM movies m WHERE m.city = s.city AND m.href = s.href)
+		ORDER BY s.last_seen_at DESC, s.title
+	`, city, since)
+	if err != nil {
+		return movies.Changes{}, err
+	}
+
+	changes.Removed, err = scanSightings(removed, city)
+	if err != nil {
+		return movies.Changes{}, err
+	}
+
+	return changes, nil
+}
+
+func (r *MovieRepository) ListListings(ctx context.Context) ([]movies.Listing, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT city, title, href, COALESCE(release_year, 0), genres, languages, cast_members, buzz, rerelease, scraped_at FROM movies
+		ORDER BY city, title
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []movies.Listing
+	for rows.Next() {
+		var listing movies.Listing
+		if err := rows.Scan(&listing.City, &listing.Movie.Title, &listing.Movie.Href, &listing.Movie.Year, &listing.Movie.Genres, &listing.Movie.Languages, &listing.Movie.Cast, &listing.Movie.Buzz, &listing.Movie.Rerelease, &listing.ScrapedAt); err != nil {
+			return nil, err
+		}
+
+		result = append(result, listing)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RandomMovie matches languages and genres case-insensitively, keeping movies
+// whose arrays are empty as MovieFilter.Matches does.
+func (r *MovieRepository) RandomMovie(ctx context.Context, city string, filter movies.MovieFilter) (movies.Movie, bool, error) {
+	var movie movies.Movie
+
+	err := r.pool.QueryRow(ctx, `
+		SELECT title, href, COALESCE(release_year, 0), genres, languages, cast_members, buzz, rerelease FROM movies
+		WHERE city = $1
+			AND (cardinality($2::TEXT[]) = 0 OR cardinality(languages) = 0
+				OR EXISTS (SELECT 1 FROM unnest(languages) l WHERE lower(l) = ANY($2)))
+			AND (cardinality($3::TEXT[]) = 0 OR cardinality(genres) = 0
+				OR EXISTS (SELECT 1 FROM unnest(genres) g WHERE lower(g) = ANY($3)))
+		ORDER BY random()
+		LIMIT 1
+	`, city, lowered(filter.Languages), lowered(filter.Genres)).Scan(&movie.Title, &movie.Href, &movie.Year, &movie.Genres, &movie.Languages, &movie.Cast, &movie.Buzz, &movie.Rerelease)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return movies.Movie{}, false, nil
+	}
+	if err != nil {
+		return movies.Movie{}, false, err
+	}
+
+	return movie, true, nil
+}
+
+func scanSightings(rows pgx.Rows, city string) ([]movies.Sighting, error) {
+	defer rows.Close()
+
+	var result []movies.Sighting
+	for rows.Next() {
+		sighting := movies.Sighting{City: city}
+		if err := rows.Scan(&sighting.Movie.Title, &sighting.Movie.Href, &sighting.Movie.Year, &sighting.Movie.Genres, &sighting.Movie.Languages, &sighting.FirstSeenAt, &sighting.LastSeenAt); err != nil {
+			return nil, err
+		}
+
+		result = append(result, sighting)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// nonNil keeps nil slices from being written as NULL into NOT NULL array
+// columns.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+
+	return values
+}
+
+func lowered(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, strings.ToLower(value))
+	}
+
+	return result
+}
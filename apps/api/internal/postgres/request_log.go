@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"go-scraping/internal/movies"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RequestLog struct {
+	pool *pgxpool.Pool
+}
+
+var _ movies.RequestLog = (*RequestLog)(nil)
+
+func NewRequestLog(pool *pgxpool.Pool) *RequestLog {
+	return &RequestLog{pool: pool}
+}
+
+// RecordRequest stores times in UTC, so that days in reports are UTC days.
+func (l *RequestLog) RecordRequest(ctx context.Context, event movies.RequestEvent) error {
+	_, err := l.pool.Exec(ctx, `
+		INSERT INTO request_log (endpoint, city, cache, status, latency_ms, occurred_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6)
+	`, event.Endpoint, event.City, event.Cache, event.Status, float64(event.Latency)/float64(time.Millisecond), event.OccurredAt.UTC())
+
+	return err
+}
+
+func (l *RequestLog) SummarizeTraffic(ctx context.Context, since time.Time) ([]movies.TrafficDay, error) {
+	rows, err := l.pool.Query(ctx, `
+		SELECT to_char((occurred_at AT TIME ZONE 'UTC')::DATE, 'YYYY-MM-DD'), city,
+			count(*),
+			count(*) FILTER (WHERE cache = 'hit'),
+			count(*) FILTER (WHERE cache = 'miss'),
+			count(*) FILTER (WHERE status >= 500),
+			avg(latency_ms),
+			percentile_cont(0.95) WITHIN GROUP (ORDER BY latency_ms)
+		FROM request_log
+		WHERE occurred_at >= $1
+		GROUP BY (occurred_at AT TIME ZONE 'UTC')::DATE, city
+		ORDER BY (occurred_at AT TIME ZONE 'UTC')::DATE DESC, count(*) DESC, city
+	`, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []movies.TrafficDay{}
+	for rows.Next() {
+		var day movies.TrafficDay
+		if err := rows.Scan(&day.Date, &day.City, &day.Requests, &day.CacheHits, &day.CacheMisses, &day.Errors, &day.AvgLatencyMs, &day.P95LatencyMs); err != nil {
+			return nil, err
+		}
+
+		result = append(result, day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (l *RequestLog) DeleteRequestsBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := l.pool.Exec(ctx, `DELETE FROM request_log WHERE occurred_at < $1`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
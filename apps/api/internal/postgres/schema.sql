@@ -0,0 +1,301 @@
+CREATE TABLE IF NOT EXISTS movies (
+    id SERIAL PRIMARY KEY,
+    city VARCHAR(100) NOT NULL,
+    title VARCHAR(500) NOT NULL,
+    href VARCHAR(1000) NOT NULL,
+    release_year INTEGER,
+    genres TEXT[] NOT NULL DEFAULT '{}',
+    languages TEXT[] NOT NULL DEFAULT '{}',
+    cast_members TEXT[] NOT NULL DEFAULT '{}',
+    buzz INTEGER NOT NULL DEFAULT 0,
+    rerelease BOOLEAN NOT NULL DEFAULT FALSE,
+    scraped_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(city, href)
+);
+
+CREATE INDEX IF NOT EXISTS idx_movies_city ON movies(city);
+CREATE INDEX IF NOT EXISTS idx_movies_scraped_at ON movies(scraped_at);
+
+CREATE TABLE IF NOT EXISTS city_scrapes (
+    city VARCHAR(100) PRIMARY KEY,
+    scraped_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_city_scrapes_scraped_at ON city_scrapes(scraped_at);
+
+CREATE TABLE IF NOT EXISTS search_log (
+    id BIGSERIAL PRIMARY KEY,
+    city VARCHAR(100) NOT NULL,
+    raw_query VARCHAR(500) NOT NULL,
+    normalized_query VARCHAR(500) NOT NULL,
+    result_count INTEGER NOT NULL,
+    zero_result BOOLEAN NOT NULL,
+    top_title VARCHAR(500),
+    searched_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_log_searched_at ON search_log(searched_at);
+
+CREATE TABLE IF NOT EXISTS movie_clicks (
+    id BIGSERIAL PRIMARY KEY,
+    city VARCHAR(100) NOT NULL,
+    title VARCHAR(500) NOT NULL,
+    href VARCHAR(1000) NOT NULL,
+    clicked_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_movie_clicks_clicked_at ON movie_clicks(clicked_at);
+CREATE INDEX IF NOT EXISTS idx_movie_clicks_city_clicked_at ON movie_clicks(city, clicked_at);
+
+CREATE TABLE IF NOT EXISTS title_aliases (
+    alias VARCHAR(500) PRIMARY KEY,
+    canonical VARCHAR(500) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS title_variants (
+    movie_id VARCHAR(64) NOT NULL,
+    language VARCHAR(16) NOT NULL,
+    title VARCHAR(500) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (movie_id, language)
+);
+
+CREATE TABLE IF NOT EXISTS city_settings (
+    city VARCHAR(100) PRIMARY KEY,
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    cache_ttl_seconds BIGINT NOT NULL DEFAULT 0,
+    stale_policy VARCHAR(32) NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Cities onboarded at runtime; the rest come from the config file.
+CREATE TABLE IF NOT EXISTS onboarded_cities (
+    name VARCHAR(100) PRIMARY KEY,
+    display_name VARCHAR(200) NOT NULL,
+    timezone VARCHAR(100) NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Feature flags overridden at runtime; the rest come from the config file.
+CREATE TABLE IF NOT EXISTS feature_flags (
+    name VARCHAR(64) PRIMARY KEY,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    cities TEXT[] NOT NULL DEFAULT '{}',
+    percent INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS movie_ratings (
+    title VARCHAR(500) NOT NULL,
+    release_year INTEGER NOT NULL DEFAULT 0,
+    found BOOLEAN NOT NULL,
+    imdb_id VARCHAR(20) NOT NULL DEFAULT '',
+    imdb_rating DOUBLE PRECISION NOT NULL DEFAULT 0,
+    rotten_tomatoes INTEGER NOT NULL DEFAULT 0,
+    metacritic INTEGER NOT NULL DEFAULT 0,
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (title, release_year)
+);
+
+CREATE TABLE IF NOT EXISTS telegram_subscriptions (
+    chat_id BIGINT NOT NULL,
+    city VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (chat_id, city)
+);
+
+CREATE INDEX IF NOT EXISTS idx_telegram_subscriptions_city ON telegram_subscriptions(city);
+
+CREATE TABLE IF NOT EXISTS digest_subscriptions (
+    email VARCHAR(320) NOT NULL,
+    city VARCHAR(100) NOT NULL,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (email, city)
+);
+
+CREATE TABLE IF NOT EXISTS movie_additions (
+    city VARCHAR(100) NOT NULL,
+    title VARCHAR(500) NOT NULL,
+    href VARCHAR(1000) NOT NULL,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (city, href)
+);
+
+CREATE INDEX IF NOT EXISTS idx_movie_additions_added_at ON movie_additions(added_at);
+
+CREATE TABLE IF NOT EXISTS push_subscriptions (
+    endpoint VARCHAR(2000) PRIMARY KEY,
+    p256dh BYTEA NOT NULL,
+    auth BYTEA NOT NULL,
+    city VARCHAR(100) NOT NULL,
+    titles TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_subscriptions_city ON push_subscriptions(city);
+
+CREATE TABLE IF NOT EXISTS movie_sightings (
+    city VARCHAR(100) NOT NULL,
+    href VARCHAR(1000) NOT NULL,
+    title VARCHAR(500) NOT NULL,
+    first_seen_at TIMESTAMPTZ NOT NULL,
+    last_seen_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (city, href)
+);
+
+CREATE INDEX IF NOT EXISTS idx_movie_sightings_first_seen_at ON movie_sightings(city, first_seen_at);
+
+-- Append-only: rows are never updated or deleted, so movies is a projection
+-- of these events.
+CREATE TABLE IF NOT EXISTS listing_events (
+    id BIGSERIAL PRIMARY KEY,
+    city VARCHAR(100) NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    href VARCHAR(1000) NOT NULL,
+    title VARCHAR(500) NOT NULL,
+    release_year INTEGER,
+    genres TEXT[] NOT NULL DEFAULT '{}',
+    languages TEXT[] NOT NULL DEFAULT '{}',
+    cast_members TEXT[] NOT NULL DEFAULT '{}',
+    changed_fields TEXT[] NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_listing_events_city ON listing_events(city, id);
+
+-- Notifications written in the same transaction as the change they announce,
+-- deleted once every destination has received them.
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    topic VARCHAR(64) NOT NULL,
+    message_key VARCHAR(100) NOT NULL DEFAULT '',
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    -- Set once the message is given up on; failed messages are kept.
+    failed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_due ON outbox(next_attempt_at) WHERE failed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS outbox_deliveries (
+    message_id BIGINT NOT NULL REFERENCES outbox(id) ON DELETE CASCADE,
+    destination VARCHAR(200) NOT NULL,
+    delivered_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (message_id, destination)
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id BIGSERIAL PRIMARY KEY,
+    email VARCHAR(320) NOT NULL UNIQUE,
+    -- NULL for accounts that only sign in through an OAuth provider.
+    password_hash VARCHAR(100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    provider VARCHAR(32) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    home_city VARCHAR(100),
+    languages TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+
+CREATE TABLE IF NOT EXISTS request_log (
+    id BIGSERIAL PRIMARY KEY,
+    endpoint VARCHAR(200) NOT NULL,
+    city VARCHAR(100) NOT NULL DEFAULT '',
+    -- 'hit' or 'miss' for requests that loaded a city's movies.
+    cache VARCHAR(8),
+    status INTEGER NOT NULL,
+    latency_ms DOUBLE PRECISION NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_request_log_occurred_at ON request_log(occurred_at);
+
+CREATE TABLE IF NOT EXISTS api_usage (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_usage_day ON api_usage(day);
+
+CREATE TABLE IF NOT EXISTS watchlist_items (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- The normalized title or external ID, so each movie is listed once.
+    item_key VARCHAR(255) NOT NULL,
+    title VARCHAR(255),
+    external_id VARCHAR(64),
+    added_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, item_key)
+);
+
+CREATE TABLE IF NOT EXISTS reminders (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    city VARCHAR(100) NOT NULL,
+    channel VARCHAR(16) NOT NULL,
+    push_endpoint TEXT,
+    push_p256dh BYTEA,
+    push_auth BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    fulfilled_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminders_pending ON reminders(city) WHERE fulfilled_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_reminders_user_id ON reminders(user_id);
+
+CREATE TABLE IF NOT EXISTS telegram_chats (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL,
+    linked_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Scrapes that failed the quality checks, held for an admin to publish or
+-- discard. Each city keeps only its latest.
+CREATE TABLE IF NOT EXISTS quarantined_scrapes (
+    city VARCHAR(100) PRIMARY KEY,
+    movies JSONB NOT NULL,
+    previous_count INTEGER NOT NULL,
+    problems TEXT[] NOT NULL DEFAULT '{}',
+    scraped_at TIMESTAMPTZ NOT NULL
+);
+
+-- Idempotency keys of admin POSTs and the responses to replay to retries. A
+-- key without a status is still being served.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key VARCHAR(255) PRIMARY KEY,
+    fingerprint VARCHAR(64) NOT NULL,
+    status INTEGER,
+    header JSONB,
+    body BYTEA,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
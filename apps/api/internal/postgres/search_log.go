@@ -0,0 +1,242 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"go-scraping/internal/movies"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxLoggedQueryLength keeps pathological queries from failing the insert
+// against the VARCHAR(500) columns.
+const maxLoggedQueryLength = 500
+
+type SearchLog struct {
+	pool *pgxpool.Pool
+}
+
+var _ movies.SearchLog = (*SearchLog)(nil)
+
+func NewSearchLog(pool *pgxpool.Pool) *SearchLog {
+	return &SearchLog{pool: pool}
+}
+
+func (l *SearchLog) RecordSearch(ctx context.Context, event movies.SearchEvent) error {
+	_, err := l.pool.Exec(ctx, `
+		INSERT INTO search_log (city, raw_query, normalized_query, result_count, zero_result, top_title, searched_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
+	`, event.City, truncate(event.RawQuery), truncate(event.NormalizedQuery), event.ResultCount, event.ResultCount == 0, truncate(event.TopTitle), event.SearchedAt)
+
+	return err
+}
+
+func (l *SearchLog) SummarizeSearches(ctx context.Context, city string, since time.Time, limit int) (movies.SearchSummary, error) {
+	summary := movies.SearchSummary{
+		City:  city,
+		Since: since,
+	}
+
+	topQueries, err := l.queryCounts(ctx, city, since, limit, false)
+	if err != nil {
+		return movies.SearchSummary{}, err
+	}
+
+	zeroResultQueries, err := l.queryCounts(ctx, city, since, limit, true)
+	if err != nil {
+		return movies.SearchSummary{}, err
+	}
+
+	summary.TopQueries = topQueries
+	summary.TopZeroResultQueries = zeroResultQueries
+
+	return summary, nil
+}
+
+// ExportSearches streams the matching rows to fn as they are read, so large
+// exports are never held in memory.
+func (l *SearchLog) ExportSearches(ctx context.Context, city string, since time.Time, fn func(movies.SearchEvent) error) error {
+	rows, err := l.pool.Query(ctx, `
+		SELECT city, raw_query, normalized_query, result_count, COALESCE(top_title, ''), searched_at
+		FROM search_log
+		WHERE searched_at > $1
+			AND ($2 = '' OR city = $2)
+		ORDER BY searched_at, id
+	`, since, city)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event movies.SearchEvent
+		if err := rows.Scan(&event.City, &event.RawQuery, &event.NormalizedQuery, &event.ResultCount, &event.TopTitle, &event.SearchedAt); err != nil {
+			return err
+		}
+
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (l *SearchLog) RecordClick(ctx context.Context, event movies.ClickEvent) error {
+	_, err := l.pool.Exec(ctx, `
+		INSERT INTO movie_clicks (city, title, href, clicked_at)
+		VALUES ($1, $2, $3, $4)
+	`, event.City, truncate(event.Title), event.Href, event.ClickedAt)
+
+	return err
+}
+
+// ListDailyClicks counts the clicks on each link per UTC day. Within a day,
+// the links clicked most recently come first, with their latest title.
+func (l *SearchLog) ListDailyClicks(ctx context.Context, city string, since time.Time) ([]movies.DailyClicks, error) {
+	rows, err := l.pool.Query(ctx, `
+		SELECT (array_agg(title ORDER BY clicked_at DESC))[1], href, to_char(day, 'YYYY-MM-DD'), COUNT(*)
+		FROM (
+			SELECT title, href, clicked_at, (clicked_at AT TIME ZONE 'UTC')::DATE AS day
+			FROM movie_clicks
+			WHERE city = $1 AND clicked_at >= $2
+		) clicks
+		GROUP BY day, href
+		ORDER BY day DESC, MAX(clicked_at) DESC
+	`, city, since.UTC().Truncate(24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []movies.DailyClicks{}
+	for rows.Next() {
+		var day movies.DailyClicks
+		if err := rows.Scan(&day.Movie.Title, &day.Movie.Href, &day.Date, &day.Clicks); err != nil {
+			return nil, err
+		}
+
+		result = append(result, day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (l *SearchLog) Trending(ctx context.Context, city string, since time.Time, limit int) (movies.Trending, error) {
+	trending := movies.Trending{
+		City:  city,
+		Since: since,
+	}
+
+	var err error
+
+	trending.MostSearched, err = l.titleCounts(ctx, `
+		SELECT top_title, COUNT(*)
+		FROM search_log
+		WHERE searched_at > $1 AND city = $2 AND top_title IS NOT NULL
+		GROUP BY top_title
+		ORDER BY COUNT(*) DESC, top_title
+		LIMIT $3
+	`, since, city, limit)
+	if err != nil {
+		return movies.Trending{}, err
+	}
+
+	trending.MostClicked, err = l.titleCounts(ctx, `
+		SELECT title, COUNT(*)
+		FROM movie_clicks
+		WHERE clicked_at > $1 AND city = $2
+		GROUP BY title
+		ORDER BY COUNT(*) DESC, title
+		LIMIT $3
+	`, since, city, limit)
+	if err != nil {
+		return movies.Trending{}, err
+	}
+
+	return trending, nil
+}
+
+func (l *SearchLog) DeleteSearchesBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := l.pool.Exec(ctx, `DELETE FROM search_log WHERE searched_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := l.pool.Exec(ctx, `DELETE FROM movie_clicks WHERE clicked_at < $1`, before); err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (l *SearchLog) titleCounts(ctx context.Context, query string, args ...any) ([]movies.TitleCount, error) {
+	rows, err := l.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []movies.TitleCount{}
+	for rows.Next() {
+		var count movies.TitleCount
+		if err := rows.Scan(&count.Title, &count.Count); err != nil {
+			return nil, err
+		}
+
+		result = append(result, count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (l *SearchLog) queryCounts(ctx context.Context, city string, since time.Time, limit int, zeroResultOnly bool) ([]movies.QueryCount, error) {
+	rows, err := l.pool.Query(ctx, `
+		SELECT normalized_query, COUNT(*), AVG(result_count)::float8
+		FROM search_log
+		WHERE searched_at > $1
+			AND ($2 = '' OR city = $2)
+			AND (NOT $3 OR zero_result)
+		GROUP BY normalized_query
+		ORDER BY COUNT(*) DESC, normalized_query
+		LIMIT $4
+	`, since, city, zeroResultOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []movies.QueryCount{}
+	for rows.Next() {
+		var count movies.QueryCount
+		if err := rows.Scan(&count.Query, &count.Count, &count.AvgResults); err != nil {
+			return nil, err
+		}
+
+		result = append(result, count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func truncate(value string) string {
+	runes := []rune(value)
+	if len(runes) <= maxLoggedQueryLength {
+		return value
+	}
+
+	return string(runes[:maxLoggedQueryLength])
+}
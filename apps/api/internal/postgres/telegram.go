@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+
+	"go-scraping/internal/telegram"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TelegramSubscriptions struct {
+	pool *pgxpool.Pool
+}
+
+var _ telegram.SubscriptionStore = (*TelegramSubscriptions)(nil)
+
+func NewTelegramSubscriptions(pool *pgxpool.Pool) *TelegramSubscriptions {
+	return &TelegramSubscriptions{pool: pool}
+}
+
+func (s *TelegramSubscriptions) Subscribe(ctx context.Context, chatID int64, city string) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO telegram_subscriptions (chat_id, city)
+		VALUES ($1, $2)
+		ON CONFLICT (chat_id, city) DO NOTHING
+	`, chatID, city)
+
+	return err
+}
+
+func (s *TelegramSubscriptions) Unsubscribe(ctx context.Context, chatID int64, city string) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM telegram_subscriptions WHERE chat_id = $1 AND city = $2`, chatID, city)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *TelegramSubscriptions) Subscribers(ctx context.Context, city string) ([]int64, error) {
+	rows, err := s.pool.Query(ctx, `SELECT chat_id FROM telegram_subscriptions WHERE city = $1 ORDER BY chat_id`, city)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chatIDs []int64
+	for rows.Next() {
+		var chatID int64
+		if err := rows.Scan(&chatID); err != nil {
+			return nil, err
+		}
+
+		chatIDs = append(chatIDs, chatID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return chatIDs, nil
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+
+	"go-scraping/internal/movies"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TitleVariantStore struct {
+	pool *pgxpool.Pool
+}
+
+var _ movies.TitleVariantStore = (*TitleVariantStore)(nil)
+
+func NewTitleVariantStore(pool *pgxpool.Pool) *TitleVariantStore {
+	return &TitleVariantStore{pool: pool}
+}
+
+func (s *TitleVariantStore) ListTitleVariants(ctx context.Context) ([]movies.TitleVariant, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT movie_id, language, title, created_at FROM title_variants
+		ORDER BY movie_id, language
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []movies.TitleVariant{}
+	for rows.Next() {
+		var variant movies.TitleVariant
+		if err := rows.Scan(&variant.MovieID, &variant.Language, &variant.Title, &variant.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		result = append(result, variant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *TitleVariantStore) UpsertTitleVariant(ctx context.Context, variant movies.TitleVariant) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO title_variants (movie_id, language, title, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (movie_id, language) DO UPDATE SET title = EXCLUDED.title
+	`, variant.MovieID, variant.Language, variant.Title, variant.CreatedAt)
+
+	return err
+}
+
+func (s *TitleVariantStore) DeleteTitleVariant(ctx context.Context, movieID, language string) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM title_variants WHERE movie_id = $1 AND language = $2`, movieID, language)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
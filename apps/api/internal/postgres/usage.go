@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+
+	"go-scraping/internal/usage"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UsageStore struct {
+	pool *pgxpool.Pool
+}
+
+var _ usage.Store = (*UsageStore)(nil)
+
+func NewUsageStore(pool *pgxpool.Pool) *UsageStore {
+	return &UsageStore{pool: pool}
+}
+
+func (s *UsageStore) Increment(ctx context.Context, userID int64, date string) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO api_usage (user_id, day, requests)
+		VALUES ($1, $2::DATE, 1)
+		ON CONFLICT (user_id, day) DO UPDATE SET requests = api_usage.requests + 1
+	`, userID, date)
+
+	return err
+}
+
+func (s *UsageStore) List(ctx context.Context, userID int64, since string) ([]usage.Day, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT to_char(day, 'YYYY-MM-DD'), requests
+		FROM api_usage
+		WHERE user_id = $1 AND day >= $2::DATE
+		ORDER BY day DESC
+	`, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []usage.Day{}
+	for rows.Next() {
+		var day usage.Day
+		if err := rows.Scan(&day.Date, &day.Requests); err != nil {
+			return nil, err
+		}
+
+		result = append(result, day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *UsageStore) Report(ctx context.Context, since string) ([]usage.UserDay, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT a.user_id, u.email, to_char(a.day, 'YYYY-MM-DD'), a.requests
+		FROM api_usage a
+		JOIN users u ON u.id = a.user_id
+		WHERE a.day >= $1::DATE
+		ORDER BY a.day DESC, a.requests DESC, a.user_id
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []usage.UserDay{}
+	for rows.Next() {
+		var day usage.UserDay
+		if err := rows.Scan(&day.UserID, &day.Email, &day.Date, &day.Requests); err != nil {
+			return nil, err
+		}
+
+		result = append(result, day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
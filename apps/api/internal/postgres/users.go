@@ -0,0 +1,190 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-scraping/internal/users"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UserStore struct {
+	pool *pgxpool.Pool
+}
+
+var _ users.Store = (*UserStore)(nil)
+
+func NewUserStore(pool *pgxpool.Pool) *UserStore {
+	return &UserStore{pool: pool}
+}
+
+func (s *UserStore) CreateUser(ctx context.Context, email, passwordHash string) (users.User, error) {
+	var user users.User
+
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO users (email, password_hash)
+		VALUES ($1, NULLIF($2, ''))
+		ON CONFLICT (email) DO NOTHING
+		RETURNING id, email, created_at
+	`, email, passwordHash).Scan(&user.ID, &user.Email, &user.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return users.User{}, users.ErrEmailTaken
+	}
+
+	if err != nil {
+		return users.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s *UserStore) CreateIdentityUser(ctx context.Context, identity users.Identity, email string) (users.User, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return users.User{}, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var user users.User
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO users (email)
+		VALUES ($1)
+		ON CONFLICT (email) DO NOTHING
+		RETURNING id, email, created_at
+	`, email).Scan(&user.ID, &user.Email, &user.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return users.User{}, users.ErrEmailTaken
+	}
+
+	if err != nil {
+		return users.User{}, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO user_identities (provider, subject, user_id)
+		VALUES ($1, $2, $3)
+	`, identity.Provider, identity.Subject, user.ID)
+	if err != nil {
+		return users.User{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return users.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s *UserStore) UserByIdentity(ctx context.Context, identity users.Identity) (users.User, error) {
+	var user users.User
+
+	err := s.pool.QueryRow(ctx, `
+		SELECT u.id, u.email, u.created_at
+		FROM user_identities i
+		JOIN users u ON u.id = i.user_id
+		WHERE i.provider = $1 AND i.subject = $2
+	`, identity.Provider, identity.Subject).Scan(&user.ID, &user.Email, &user.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return users.User{}, users.ErrNotFound
+	}
+
+	if err != nil {
+		return users.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s *UserStore) UserByEmail(ctx context.Context, email string) (users.User, string, error) {
+	var (
+		user users.User
+		hash string
+	)
+
+	err := s.pool.QueryRow(ctx, `
+		SELECT id, email, created_at, COALESCE(password_hash, '') FROM users WHERE email = $1
+	`, email).Scan(&user.ID, &user.Email, &user.CreatedAt, &hash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return users.User{}, "", users.ErrNotFound
+	}
+
+	if err != nil {
+		return users.User{}, "", err
+	}
+
+	return user, hash, nil
+}
+
+func (s *UserStore) UserByID(ctx context.Context, id int64) (users.User, error) {
+	var user users.User
+
+	err := s.pool.QueryRow(ctx, `
+		SELECT id, email, created_at FROM users WHERE id = $1
+	`, id).Scan(&user.ID, &user.Email, &user.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return users.User{}, users.ErrNotFound
+	}
+
+	if err != nil {
+		return users.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s *UserStore) Preferences(ctx context.Context, userID int64) (users.Preferences, error) {
+	prefs := users.Preferences{Languages: []string{}}
+
+	err := s.pool.QueryRow(ctx, `
+		SELECT COALESCE(home_city, ''), languages FROM user_preferences WHERE user_id = $1
+	`, userID).Scan(&prefs.HomeCity, &prefs.Languages)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return prefs, nil
+	}
+
+	if err != nil {
+		return users.Preferences{}, err
+	}
+
+	return prefs, nil
+}
+
+func (s *UserStore) SavePreferences(ctx context.Context, userID int64, prefs users.Preferences) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO user_preferences (user_id, home_city, languages, updated_at)
+		VALUES ($1, NULLIF($2, ''), $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO UPDATE SET
+			home_city = EXCLUDED.home_city,
+			languages = EXCLUDED.languages,
+			updated_at = EXCLUDED.updated_at
+	`, userID, prefs.HomeCity, prefs.Languages)
+
+	return err
+}
+
+func (s *UserStore) SaveRefreshToken(ctx context.Context, id string, userID int64, expiresAt time.Time) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO refresh_tokens (id, user_id, expires_at)
+		VALUES ($1, $2, $3)
+	`, id, userID, expiresAt)
+
+	return err
+}
+
+func (s *UserStore) RevokeRefreshToken(ctx context.Context, id string) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
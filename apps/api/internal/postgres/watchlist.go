@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+
+	"go-scraping/internal/watchlist"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WatchlistStore struct {
+	pool *pgxpool.Pool
+}
+
+var _ watchlist.Store = (*WatchlistStore)(nil)
+
+func NewWatchlistStore(pool *pgxpool.Pool) *WatchlistStore {
+	return &WatchlistStore{pool: pool}
+}
+
+func (s *WatchlistStore) Add(ctx context.Context, userID int64, item watchlist.Item) (watchlist.Item, error) {
+	// The no-op update makes RETURNING yield the existing row on conflict.
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO watchlist_items (user_id, item_key, title, external_id)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''))
+		ON CONFLICT (user_id, item_key) DO UPDATE SET item_key = EXCLUDED.item_key
+		RETURNING id, COALESCE(title, ''), COALESCE(external_id, ''), added_at
+	`, userID, item.Key(), item.Title, item.ExternalID).Scan(&item.ID, &item.Title, &item.ExternalID, &item.AddedAt)
+	if err != nil {
+		return watchlist.Item{}, err
+	}
+
+	return item, nil
+}
+
+func (s *WatchlistStore) List(ctx context.Context, userID int64) ([]watchlist.Item, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, COALESCE(title, ''), COALESCE(external_id, ''), added_at
+		FROM watchlist_items
+		WHERE user_id = $1
+		ORDER BY added_at DESC, id DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []watchlist.Item{}
+	for rows.Next() {
+		var item watchlist.Item
+		if err := rows.Scan(&item.ID, &item.Title, &item.ExternalID, &item.AddedAt); err != nil {
+			return nil, err
+		}
+
+		result = append(result, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *WatchlistStore) Count(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := s.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM watchlist_items WHERE user_id = $1
+	`, userID).Scan(&count)
+
+	return count, err
+}
+
+func (s *WatchlistStore) Remove(ctx context.Context, userID, itemID int64) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `
+		DELETE FROM watchlist_items WHERE user_id = $1 AND id = $2
+	`, userID, itemID)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type counters struct {
+	index    int64
+	previous int64
+	current  int64
+}
+
+// MemoryStore keeps counts in this process, so each replica limits clients
+// on its own.
+type MemoryStore struct {
+	mu       sync.Mutex
+	counters map[string]*counters
+	sweptAt  int64
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: make(map[string]*counters)}
+}
+
+func (s *MemoryStore) Hit(_ context.Context, key string, index int64, _ time.Duration) (int64, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Clients that have been quiet for two windows count as new, so they
+	// are dropped once per window to keep the map from growing forever.
+	if index > s.sweptAt {
+		for k, c := range s.counters {
+			if c.index < index-1 {
+				delete(s.counters, k)
+			}
+		}
+		s.sweptAt = index
+	}
+
+	c, ok := s.counters[key]
+	if !ok {
+		c = &counters{index: index}
+		s.counters[key] = c
+	}
+
+	switch {
+	case c.index == index-1:
+		c.previous, c.current = c.current, 0
+	case c.index < index-1:
+		c.previous, c.current = 0, 0
+	}
+	c.index = index
+	c.current++
+
+	return c.previous, c.current, nil
+}
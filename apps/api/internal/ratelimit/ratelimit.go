@@ -0,0 +1,125 @@
+// Package ratelimit limits how many requests each client makes in a sliding
+// window. The counts live in a Store, which replicas can share so that a
+// client cannot get around its limit by spreading requests across them.
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// Store counts hits in fixed windows. Hit adds a hit for key to the window
+// with the given index and returns the counts of that window and of the one
+// before it. Windows are only needed until the end of the window after them.
+type Store interface {
+	Hit(ctx context.Context, key string, index int64, window time.Duration) (previous, current int64, err error)
+}
+
+// FallbackStore counts in a shared store, and in a local one while the shared
+// store fails, so an outage of Redis loosens limits to each replica's instead
+// of lifting them.
+type FallbackStore struct {
+	primary  Store
+	fallback Store
+	logger   *slog.Logger
+}
+
+var _ Store = (*FallbackStore)(nil)
+
+func NewFallbackStore(primary, fallback Store, logger *slog.Logger) *FallbackStore {
+	return &FallbackStore{primary: primary, fallback: fallback, logger: logger}
+}
+
+func (s *FallbackStore) Hit(ctx context.Context, key string, index int64, window time.Duration) (int64, int64, error) {
+	previous, current, err := s.primary.Hit(ctx, key, index, window)
+	if err == nil {
+		return previous, current, nil
+	}
+
+	s.logger.WarnContext(ctx, "rate limit store failed, counting locally", "error", err)
+
+	return s.fallback.Hit(ctx, key, index, window)
+}
+
+// Options sets how many requests a client may make in each window.
+type Options struct {
+	Requests int
+	Window   time.Duration
+}
+
+// Decision is whether a request is allowed, with what the client may be told
+// about its limit.
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Limiter estimates each client's requests in the last window by weighting
+// the previous fixed window by how much of it still overlaps the sliding one,
+// which needs two counters per client instead of a timestamp per request.
+type Limiter struct {
+	store  Store
+	opts   Options
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+func New(store Store, opts Options, logger *slog.Logger) *Limiter {
+	return &Limiter{
+		store:  store,
+		opts:   opts,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Allow counts a request by the client identified by key and reports whether
+// it is within the limit. Rejected requests count too, so a client has to
+// slow down to get through. When the store fails, the request is allowed,
+// since an outage of the limiter should not take the API down with it.
+func (l *Limiter) Allow(ctx context.Context, key string) Decision {
+	window := l.opts.Window
+	now := l.now()
+	index := now.UnixNano() / int64(window)
+	elapsed := time.Duration(now.UnixNano() % int64(window))
+
+	previous, current, err := l.store.Hit(ctx, key, index, window)
+	if err != nil {
+		l.logger.WarnContext(ctx, "rate limit store failed, allowing request", "error", err)
+		return Decision{Allowed: true, Limit: l.opts.Requests, Remaining: l.opts.Requests}
+	}
+
+	limit := float64(l.opts.Requests)
+	overlap := 1 - float64(elapsed)/float64(window)
+	count := float64(previous)*overlap + float64(current)
+
+	decision := Decision{
+		Allowed:   count <= limit,
+		Limit:     l.opts.Requests,
+		Remaining: max(0, int(math.Floor(limit-count))),
+	}
+	if !decision.Allowed {
+		decision.RetryAfter = retryAfter(previous, current, limit, elapsed, window)
+	}
+
+	return decision
+}
+
+// retryAfter returns how long until the estimate leaves room for another
+// request, assuming the client waits without retrying.
+func retryAfter(previous, current int64, limit float64, elapsed, window time.Duration) time.Duration {
+	room := limit - 1
+	if float64(current) <= room && previous > 0 {
+		// The previous window's share shrinks enough before this one ends.
+		at := float64(window) * (1 - (room-float64(current))/float64(previous))
+		return max(time.Duration(at)-elapsed, time.Second)
+	}
+
+	// Otherwise this window's count has to fade during the next one.
+	at := float64(window) * (1 - room/float64(current))
+	return max(window-elapsed+time.Duration(at), time.Second)
+}
@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func testLimiter(store Store, requests int, now *time.Time) *Limiter {
+	limiter := New(store, Options{Requests: requests, Window: time.Minute}, slog.New(slog.DiscardHandler))
+	limiter.now = func() time.Time { return *now }
+
+	return limiter
+}
+
+func TestLimiterAllowsUpToTheLimit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	limiter := testLimiter(NewMemoryStore(), 3, &now)
+
+	for i, wantRemaining := range []int{2, 1, 0} {
+		decision := limiter.Allow(context.Background(), "ip:203.0.113.7")
+		if !decision.Allowed || decision.Remaining != wantRemaining || decision.Limit != 3 {
+			t.Fatalf("request %d = %+v, want allowed with %d remaining", i+1, decision, wantRemaining)
+		}
+	}
+
+	decision := limiter.Allow(context.Background(), "ip:203.0.113.7")
+	if decision.Allowed || decision.RetryAfter <= 0 {
+		t.Fatalf("request 4 = %+v, want rejected with a retry delay", decision)
+	}
+
+	if other := limiter.Allow(context.Background(), "ip:198.51.100.2"); !other.Allowed {
+		t.Fatalf("other client = %+v, want allowed", other)
+	}
+}
+
+func TestLimiterSlidesThePreviousWindow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 12, 0, 30, 0, time.UTC)
+	limiter := testLimiter(NewMemoryStore(), 4, &now)
+
+	for range 4 {
+		limiter.Allow(context.Background(), "user:1")
+	}
+
+	// A quarter into the next window, three quarters of the previous
+	// window's four requests still count.
+	now = time.Date(2026, 10, 16, 12, 1, 15, 0, time.UTC)
+	if decision := limiter.Allow(context.Background(), "user:1"); !decision.Allowed || decision.Remaining != 0 {
+		t.Fatalf("first request = %+v, want allowed with none remaining", decision)
+	}
+
+	decision := limiter.Allow(context.Background(), "user:1")
+	if decision.Allowed {
+		t.Fatalf("second request = %+v, want rejected", decision)
+	}
+
+	// 4*(1-t/60s) + 2 <= 3 first holds at t = 45s, 30s from now.
+	if decision.RetryAfter != 30*time.Second {
+		t.Fatalf("RetryAfter = %s, want 30s", decision.RetryAfter)
+	}
+
+	now = time.Date(2026, 10, 16, 12, 3, 0, 0, time.UTC)
+	if decision := limiter.Allow(context.Background(), "user:1"); !decision.Allowed || decision.Remaining != 3 {
+		t.Fatalf("after a quiet window = %+v, want allowed with 3 remaining", decision)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Hit(context.Context, string, int64, time.Duration) (int64, int64, error) {
+	return 0, 0, errors.New("connection refused")
+}
+
+func TestLimiterAllowsWhenTheStoreFails(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	if decision := testLimiter(failingStore{}, 1, &now).Allow(context.Background(), "ip:203.0.113.7"); !decision.Allowed {
+		t.Fatalf("Allow() = %+v, want allowed", decision)
+	}
+}
+
+func TestFallbackStoreCountsLocallyWhenThePrimaryFails(t *testing.T) {
+	t.Parallel()
+
+	store := NewFallbackStore(failingStore{}, NewMemoryStore(), slog.New(slog.DiscardHandler))
+	now := time.Now()
+	limiter := testLimiter(store, 1, &now)
+
+	if decision := limiter.Allow(context.Background(), "ip:203.0.113.7"); !decision.Allowed {
+		t.Fatalf("first request = %+v, want allowed", decision)
+	}
+
+	if decision := limiter.Allow(context.Background(), "ip:203.0.113.7"); decision.Allowed {
+		t.Fatalf("second request = %+v, want rejected by the local count", decision)
+	}
+}
+
+func TestMemoryStoreDropsQuietClients(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryStore()
+	store.Hit(context.Background(), "a", 10, time.Minute)
+	store.Hit(context.Background(), "b", 11, time.Minute)
+	store.Hit(context.Background(), "b", 13, time.Minute)
+
+	if _, ok := store.counters["a"]; ok {
+		t.Fatal("counters kept a client quiet for two windows")
+	}
+}
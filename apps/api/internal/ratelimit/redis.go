@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTimeout bounds each round trip to Redis, so a slow server delays
+// requests by at most this long before they are allowed without a count.
+const redisTimeout = time.Second
+
+// hitScript increments the current window's counter, sets it to expire, and
+// reads the previous window's counter in one atomic step, so a counter is
+// never left without an expiry.
+var hitScript = redis.NewScript(`
+local current = redis.call("INCR", KEYS[1])
+redis.call("PEXPIRE", KEYS[1], ARGV[1])
+local previous = redis.call("GET", KEYS[2])
+return {tonumber(previous) or 0, current}
+`)
+
+// RedisStore keeps counts in Redis, so that every replica pointed at the same
+// server shares them.
+type RedisStore struct {
+	client *redis.Client
+}
+
+var _ Store = (*RedisStore)(nil)
+
+// NewRedisStore connects to the Redis server at rawURL, such as
+// redis://:password@localhost:6379/0, or rediss:// for TLS. Connections are
+// opened as needed.
+func NewRedisStore(rawURL string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("redis URL must look like redis://host:port/db: %w", err)
+	}
+
+	opts.DialTimeout = redisTimeout
+	opts.ReadTimeout = redisTimeout
+	opts.WriteTimeout = redisTimeout
+
+	return &RedisStore{client: redis.NewClient(opts)}, nil
+}
+
+// Hit counts the hit and reads the previous window in a single round trip.
+// Each counter expires once the window after it has ended. Both keys share a
+// hash tag, so they live on the same node of a cluster.
+func (s *RedisStore) Hit(ctx context.Context, key string, index int64, window time.Duration) (int64, int64, error) {
+	tag := "ratelimit:{" + key + "}:"
+	keys := []string{tag + strconv.FormatInt(index, 10), tag + strconv.FormatInt(index-1, 10)}
+
+	counts, err := hitScript.Run(ctx, s.client, keys, (2 * window).Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, 0, fmt.Errorf("count hit in redis: %w", err)
+	}
+	if len(counts) != 2 {
+		return 0, 0, fmt.Errorf("count hit in redis: got %d counts, want 2", len(counts))
+	}
+
+	return counts[0], counts[1], nil
+}
+
+// Close closes the connections to Redis.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedisStoreCountsWindows(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.RunT(t)
+	server.RequireAuth("secret")
+
+	store, err := NewRedisStore("redis://:secret@" + server.Addr() + "/2")
+	if err != nil {
+		t.Fatalf("NewRedisStore() error = %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	store.Hit(ctx, "ip:203.0.113.7", 41, time.Minute)
+	store.Hit(ctx, "ip:203.0.113.7", 41, time.Minute)
+
+	previous, current, err := store.Hit(ctx, "ip:203.0.113.7", 42, time.Minute)
+	if err != nil {
+		t.Fatalf("Hit() error = %v", err)
+	}
+	if previous != 2 || current != 1 {
+		t.Fatalf("Hit() = %d, %d, want 2, 1", previous, current)
+	}
+
+	server.Select(2)
+	if ttl := server.TTL("ratelimit:{ip:203.0.113.7}:42"); ttl != 2*time.Minute {
+		t.Fatalf("TTL = %v, want two windows", ttl)
+	}
+
+	// Counters expire on their own once the window after them ends.
+	server.FastForward(2 * time.Minute)
+	if previous, current, err := store.Hit(ctx, "ip:203.0.113.7", 43, time.Minute); err != nil || previous != 0 || current != 1 {
+		t.Fatalf("Hit() after expiry = %d, %d, %v, want 0, 1", previous, current, err)
+	}
+}
+
+func TestRedisStoreReportsAuthFailures(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.RunT(t)
+	server.RequireAuth("secret")
+
+	store, err := NewRedisStore("redis://:wrong@" + server.Addr())
+	if err != nil {
+		t.Fatalf("NewRedisStore() error = %v", err)
+	}
+	defer store.Close()
+
+	if _, _, err := store.Hit(context.Background(), "user:1", 1, time.Minute); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Fatalf("Hit() error = %v, want the AUTH error", err)
+	}
+}
+
+func TestNewRedisStoreRejectsBadURLs(t *testing.T) {
+	t.Parallel()
+
+	for _, rawURL := range []string{"localhost:6379", "http://localhost:6379", "redis://localhost:6379/primary"} {
+		if _, err := NewRedisStore(rawURL); err == nil {
+			t.Errorf("NewRedisStore(%q) error = nil, want an error", rawURL)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-scraping/internal/telegram"
+	"go-scraping/internal/users"
+	"go-scraping/internal/webpush"
+)
+
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+type pusher interface {
+	Push(ctx context.Context, endpoint string, keys webpush.Keys, msg webpush.Message) error
+}
+
+type messenger interface {
+	SendMessage(ctx context.Context, chatID int64, text string) error
+}
+
+// EmailDeliverer emails reminders to the user's account address.
+type EmailDeliverer struct {
+	mailer Mailer
+}
+
+func NewEmailDeliverer(mailer Mailer) *EmailDeliverer {
+	return &EmailDeliverer{mailer: mailer}
+}
+
+func (d *EmailDeliverer) Deliver(ctx context.Context, notice Notice) error {
+	subject := fmt.Sprintf("%s is now showing in %s", notice.Movie.Title, notice.City.DisplayName)
+	body := fmt.Sprintf("%s is now showing in %s.\n\nBook tickets: %s\n\nYou asked to be told when %q was released. This is the only reminder you will get for it.\n",
+		notice.Movie.Title, notice.City.DisplayName, notice.Movie.Href, notice.Reminder.Title)
+
+	return d.mailer.Send(ctx, notice.Reminder.Email, subject, body)
+}
+
+// PushDeliverer sends reminders to the browser subscription they were created
+// with.
+type PushDeliverer struct {
+	pusher pusher
+}
+
+func NewPushDeliverer(pusher pusher) *PushDeliverer {
+	return &PushDeliverer{pusher: pusher}
+}
+
+func (d *PushDeliverer) Deliver(ctx context.Context, notice Notice) error {
+	if notice.Reminder.Push == nil {
+		return errors.New("push reminder has no subscription")
+	}
+
+	return d.pusher.Push(ctx, notice.Reminder.Push.Endpoint, notice.Reminder.Push.Keys, webpush.Message{
+		Title: notice.Movie.Title + " is now showing",
+		Body:  "Tickets are open in " + notice.City.DisplayName + ".",
+		URL:   notice.Movie.Href,
+	})
+}
+
+// TelegramDeliverer messages reminders to the user's linked chat.
+type TelegramDeliverer struct {
+	messenger messenger
+}
+
+func NewTelegramDeliverer(messenger messenger) *TelegramDeliverer {
+	return &TelegramDeliverer{messenger: messenger}
+}
+
+func (d *TelegramDeliverer) Deliver(ctx context.Context, notice Notice) error {
+	if notice.Reminder.TelegramChatID == 0 {
+		return errors.New("telegram chat is no longer linked")
+	}
+
+	text := fmt.Sprintf("%s is now showing in %s: %s", notice.Movie.Title, notice.City.DisplayName, notice.Movie.Href)
+
+	return d.messenger.SendMessage(ctx, notice.Reminder.TelegramChatID, text)
+}
+
+type linkTokenVerifier interface {
+	VerifyLinkToken(token string) (int64, error)
+}
+
+// TelegramLinker links the chat that sends a /link command to the account
+// that requested its code.
+type TelegramLinker struct {
+	store  Store
+	tokens linkTokenVerifier
+}
+
+var _ telegram.ChatLinker = (*TelegramLinker)(nil)
+
+func NewTelegramLinker(store Store, tokens linkTokenVerifier) *TelegramLinker {
+	return &TelegramLinker{store: store, tokens: tokens}
+}
+
+func (l *TelegramLinker) LinkChat(ctx context.Context, code string, chatID int64) error {
+	userID, err := l.tokens.VerifyLinkToken(code)
+	if errors.Is(err, users.ErrInvalidToken) {
+		return telegram.ErrInvalidLinkCode
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return l.store.LinkTelegramChat(ctx, userID, chatID)
+}
@@ -0,0 +1,304 @@
+// Package reminders tells users, once, when a title they asked about starts
+// showing in their city, over the channel they chose.
+package reminders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+	"go-scraping/internal/webpush"
+)
+
+const (
+	maxTitleLength = 200
+
+	// MaxActive caps the unfulfilled reminders a user can have.
+	MaxActive = 100
+
+	// maxPendingRefreshes bounds the scrapes waiting to be checked against
+	// reminders; further ones are dropped rather than blocking scrapes.
+	maxPendingRefreshes = 16
+)
+
+type Channel string
+
+const (
+	ChannelEmail    Channel = "email"
+	ChannelPush     Channel = "push"
+	ChannelTelegram Channel = "telegram"
+)
+
+var (
+	ErrInvalidReminder    = errors.New("invalid reminder")
+	ErrChannelUnavailable = errors.New("this notification channel is not enabled")
+	ErrTelegramNotLinked  = errors.New("link a Telegram chat with the bot's /link command first")
+	ErrTooMany            = fmt.Errorf("a user can have at most %d active reminders", MaxActive)
+)
+
+// PushTarget is the browser subscription a push reminder is sent to.
+type PushTarget struct {
+	Endpoint string
+	Keys     webpush.Keys
+}
+
+// Reminder asks for one notification when Title starts showing in City.
+type Reminder struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"-"`
+	Title       string     `json:"title"`
+	City        string     `json:"city"`
+	Channel     Channel    `json:"channel"`
+	CreatedAt   time.Time  `json:"created_at"`
+	FulfilledAt *time.Time `json:"fulfilled_at,omitempty"`
+
+	// Push is set for push reminders.
+	Push *PushTarget `json:"-"`
+
+	// Email and TelegramChatID are the user's addresses, filled in by
+	// Store.Pending. TelegramChatID is zero if no chat is linked.
+	Email          string `json:"-"`
+	TelegramChatID int64  `json:"-"`
+}
+
+// PushRegistration is a browser push subscription as the browser reports it,
+// with keys in base64url.
+type PushRegistration struct {
+	Endpoint string
+	P256DH   string
+	Auth     string
+}
+
+type Request struct {
+	Title   string
+	City    string
+	Channel Channel
+
+	// Push is required for push reminders.
+	Push *PushRegistration
+}
+
+type Store interface {
+	Create(ctx context.Context, reminder Reminder) (Reminder, error)
+
+	// List returns the user's reminders, newest first.
+	List(ctx context.Context, userID int64) ([]Reminder, error)
+	CountActive(ctx context.Context, userID int64) (int, error)
+	Delete(ctx context.Context, userID, id int64) (bool, error)
+
+	// Pending returns the unfulfilled reminders for a city.
+	Pending(ctx context.Context, city string) ([]Reminder, error)
+	MarkFulfilled(ctx context.Context, id int64, at time.Time) error
+
+	LinkTelegramChat(ctx context.Context, userID, chatID int64) error
+	TelegramChat(ctx context.Context, userID int64) (int64, bool, error)
+}
+
+// Notice is a reminder whose title was found in a scrape.
+type Notice struct {
+	Reminder Reminder
+	Movie    movies.Movie
+	City     cities.City
+}
+
+// Deliverer sends notices over one channel.
+type Deliverer interface {
+	Deliver(ctx context.Context, notice Notice) error
+}
+
+type cityResolver interface {
+	Resolve(name string) (cities.City, bool)
+}
+
+type Reminders struct {
+	store      Store
+	deliverers map[Channel]Deliverer
+	cities     cityResolver
+	logger     *slog.Logger
+
+	refreshed chan refreshedCity
+}
+
+type refreshedCity struct {
+	city   string
+	movies []movies.Movie
+}
+
+var _ movies.RefreshListener = (*Reminders)(nil)
+
+// New accepts reminders for the channels that have a deliverer.
+func New(store Store, deliverers map[Channel]Deliverer, cities cityResolver, logger *slog.Logger) *Reminders {
+	return &Reminders{
+		store:      store,
+		deliverers: deliverers,
+		cities:     cities,
+		logger:     logger,
+		refreshed:  make(chan refreshedCity, maxPendingRefreshes),
+	}
+}
+
+// Channels returns the enabled channels, sorted.
+func (r *Reminders) Channels() []Channel {
+	channels := make([]Channel, 0, len(r.deliverers))
+	for channel := range r.deliverers {
+		channels = append(channels, channel)
+	}
+	slices.Sort(channels)
+
+	return channels
+}
+
+func (r *Reminders) Enabled(channel Channel) bool {
+	_, ok := r.deliverers[channel]
+	return ok
+}
+
+// Create saves a reminder for a title, which need not be showing anywhere yet.
+func (r *Reminders) Create(ctx context.Context, userID int64, req Request) (Reminder, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" || utf8.RuneCountInString(title) > maxTitleLength {
+		return Reminder{}, fmt.Errorf("%w: title must be 1 to %d characters", ErrInvalidReminder, maxTitleLength)
+	}
+
+	if req.City == "" {
+		return Reminder{}, fmt.Errorf("%w: city is required", ErrInvalidReminder)
+	}
+
+	if !r.Enabled(req.Channel) {
+		return Reminder{}, ErrChannelUnavailable
+	}
+
+	city, _ := r.cities.Resolve(req.City)
+	reminder := Reminder{
+		UserID:  userID,
+		Title:   title,
+		City:    city.Name,
+		Channel: req.Channel,
+	}
+
+	switch req.Channel {
+	case ChannelPush:
+		target, err := pushTarget(req.Push)
+		if err != nil {
+			return Reminder{}, err
+		}
+
+		reminder.Push = target
+	case ChannelTelegram:
+		_, linked, err := r.store.TelegramChat(ctx, userID)
+		if err != nil {
+			return Reminder{}, fmt.Errorf("load telegram chat: %w", err)
+		}
+
+		if !linked {
+			return Reminder{}, ErrTelegramNotLinked
+		}
+	}
+
+	active, err := r.store.CountActive(ctx, userID)
+	if err != nil {
+		return Reminder{}, fmt.Errorf("count reminders: %w", err)
+	}
+
+	if active >= MaxActive {
+		return Reminder{}, ErrTooMany
+	}
+
+	return r.store.Create(ctx, reminder)
+}
+
+func pushTarget(registration *PushRegistration) (*PushTarget, error) {
+	if registration == nil {
+		return nil, fmt.Errorf("%w: push reminders need a push subscription", ErrInvalidReminder)
+	}
+
+	if !strings.HasPrefix(registration.Endpoint, "https://") {
+		return nil, fmt.Errorf("%w: push endpoint must be an https URL", ErrInvalidReminder)
+	}
+
+	keys, err := webpush.ParseKeys(registration.P256DH, registration.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidReminder, err)
+	}
+
+	return &PushTarget{Endpoint: registration.Endpoint, Keys: keys}, nil
+}
+
+func (r *Reminders) List(ctx context.Context, userID int64) ([]Reminder, error) {
+	return r.store.List(ctx, userID)
+}
+
+func (r *Reminders) Delete(ctx context.Context, userID, id int64) (bool, error) {
+	return r.store.Delete(ctx, userID, id)
+}
+
+// Run delivers reminders for refreshed cities until ctx is done.
+func (r *Reminders) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-r.refreshed:
+			r.deliver(ctx, event)
+		}
+	}
+}
+
+// MoviesRefreshed queues a check of the city's reminders against its
+// listings. Checking every scrape rather than only newly added movies also
+// covers titles that were already showing when the reminder was created, and
+// retries deliveries that failed.
+func (r *Reminders) MoviesRefreshed(ctx context.Context, city string, current []movies.Movie) {
+	select {
+	case r.refreshed <- refreshedCity{city: city, movies: current}:
+	default:
+		r.logger.WarnContext(ctx, "reminder queue full, dropping refresh", "city", city)
+	}
+}
+
+func (r *Reminders) deliver(ctx context.Context, event refreshedCity) {
+	pending, err := r.store.Pending(ctx, event.city)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to load pending reminders", "city", event.city, "error", err)
+		return
+	}
+
+	if len(pending) == 0 {
+		return
+	}
+
+	showing := make(map[string]movies.Movie, len(event.movies))
+	for _, movie := range event.movies {
+		showing[movies.AliasKey(movie.Title)] = movie
+	}
+
+	city, _ := r.cities.Resolve(event.city)
+
+	for _, reminder := range pending {
+		movie, found := showing[movies.AliasKey(reminder.Title)]
+		if !found {
+			continue
+		}
+
+		deliverer, enabled := r.deliverers[reminder.Channel]
+		if !enabled {
+			continue
+		}
+
+		if err := deliverer.Deliver(ctx, Notice{Reminder: reminder, Movie: movie, City: city}); err != nil {
+			r.logger.ErrorContext(ctx, "failed to deliver reminder", "reminder_id", reminder.ID, "channel", reminder.Channel, "error", err)
+			continue
+		}
+
+		if err := r.store.MarkFulfilled(ctx, reminder.ID, time.Now()); err != nil {
+			r.logger.ErrorContext(ctx, "failed to mark reminder fulfilled", "reminder_id", reminder.ID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeStore struct {
+	mu        sync.Mutex
+	reminders []Reminder
+	chats     map[int64]int64
+}
+
+func (s *fakeStore) Create(_ context.Context, reminder Reminder) (Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reminder.ID = int64(len(s.reminders) + 1)
+	reminder.CreatedAt = time.Now()
+	s.reminders = append(s.reminders, reminder)
+
+	return reminder, nil
+}
+
+func (s *fakeStore) List(_ context.Context, userID int64) ([]Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []Reminder
+	for _, reminder := range s.reminders {
+		if reminder.UserID == userID {
+			result = append(result, reminder)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *fakeStore) CountActive(_ context.Context, userID int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, reminder := range s.reminders {
+		if reminder.UserID == userID && reminder.FulfilledAt == nil {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (s *fakeStore) Delete(_ context.Context, _, _ int64) (bool, error) {
+	return false, nil
+}
+
+func (s *fakeStore) Pending(_ context.Context, city string) ([]Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []Reminder
+	for _, reminder := range s.reminders {
+		if reminder.City == city && reminder.FulfilledAt == nil {
+			reminder.Email = "ana@example.com"
+			reminder.TelegramChatID = s.chats[reminder.UserID]
+			result = append(result, reminder)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *fakeStore) MarkFulfilled(_ context.Context, id int64, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reminders[id-1].FulfilledAt = &at
+
+	return nil
+}
+
+func (s *fakeStore) LinkTelegramChat(_ context.Context, userID, chatID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chats[userID] = chatID
+
+	return nil
+}
+
+func (s *fakeStore) TelegramChat(_ context.Context, userID int64) (int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chatID, linked := s.chats[userID]
+
+	return chatID, linked, nil
+}
+
+type fakeDeliverer struct {
+	mu      sync.Mutex
+	notices []Notice
+	err     error
+}
+
+func (d *fakeDeliverer) Deliver(_ context.Context, notice Notice) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err != nil {
+		return d.err
+	}
+
+	d.notices = append(d.notices, notice)
+
+	return nil
+}
+
+func newTestReminders(t *testing.T, deliverers map[Channel]Deliverer) (*Reminders, *fakeStore) {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	store := &fakeStore{chats: make(map[int64]int64)}
+
+	return New(store, deliverers, registry, slog.New(slog.DiscardHandler)), store
+}
+
+func TestCreateValidatesRequest(t *testing.T) {
+	t.Parallel()
+
+	email := &fakeDeliverer{}
+	reminders, _ := newTestReminders(t, map[Channel]Deliverer{ChannelEmail: email, ChannelTelegram: &fakeDeliverer{}, ChannelPush: &fakeDeliverer{}})
+
+	tests := []struct {
+		name string
+		req  Request
+		want error
+	}{
+		{name: "missing title", req: Request{City: "bbsr", Channel: ChannelEmail}, want: ErrInvalidReminder},
+		{name: "missing city", req: Request{Title: "Dune", Channel: ChannelEmail}, want: ErrInvalidReminder},
+		{name: "unknown channel", req: Request{Title: "Dune", City: "bbsr", Channel: "sms"}, want: ErrChannelUnavailable},
+		{name: "push without subscription", req: Request{Title: "Dune", City: "bbsr", Channel: ChannelPush}, want: ErrInvalidReminder},
+		{name: "telegram without chat", req: Request{Title: "Dune", City: "bbsr", Channel: ChannelTelegram}, want: ErrTelegramNotLinked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := reminders.Create(context.Background(), 1, tt.req); !errors.Is(err, tt.want) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	reminder, err := reminders.Create(context.Background(), 1, Request{Title: " Dune: Part Three ", City: "bbsr", Channel: ChannelEmail})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if reminder.Title != "Dune: Part Three" || reminder.City != "bhubaneswar" {
+		t.Fatalf("Create() = %+v, want trimmed title in bhubaneswar", reminder)
+	}
+}
+
+func TestDeliverFulfillsMatchingReminders(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	telegram := &fakeDeliverer{}
+	reminders, store := newTestReminders(t, map[Channel]Deliverer{ChannelTelegram: telegram})
+
+	if err := store.LinkTelegramChat(ctx, 1, 42); err != nil {
+		t.Fatalf("LinkTelegramChat() error = %v", err)
+	}
+
+	for _, title := range []string{"superman", "Dune: Part Three"} {
+		if _, err := reminders.Create(ctx, 1, Request{Title: title, City: "bhubaneswar", Channel: ChannelTelegram}); err != nil {
+			t.Fatalf("Create(%q) error = %v", title, err)
+		}
+	}
+
+	listings := []movies.Movie{{Title: "Superman", Href: "https://in.bookmyshow.com/superman"}, {Title: "F1"}}
+	reminders.deliver(ctx, refreshedCity{city: "bhubaneswar", movies: listings})
+	reminders.deliver(ctx, refreshedCity{city: "bhubaneswar", movies: listings})
+
+	if len(telegram.notices) != 1 {
+		t.Fatalf("delivered %d notices, want 1", len(telegram.notices))
+	}
+
+	notice := telegram.notices[0]
+	if notice.Movie.Title != "Superman" || notice.Reminder.TelegramChatID != 42 || notice.City.DisplayName != "Bhubaneswar" {
+		t.Fatalf("notice = %+v, want Superman for chat 42 in Bhubaneswar", notice)
+	}
+
+	list, _ := store.List(ctx, 1)
+	if list[0].FulfilledAt == nil || list[1].FulfilledAt != nil {
+		t.Fatalf("reminders = %+v, want only the Superman reminder fulfilled", list)
+	}
+}
+
+func TestDeliverRetriesFailedDeliveries(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	email := &fakeDeliverer{err: errors.New("smtp unavailable")}
+	reminders, store := newTestReminders(t, map[Channel]Deliverer{ChannelEmail: email})
+
+	if _, err := reminders.Create(ctx, 1, Request{Title: "Superman", City: "bhubaneswar", Channel: ChannelEmail}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	listings := []movies.Movie{{Title: "Superman"}}
+	reminders.deliver(ctx, refreshedCity{city: "bhubaneswar", movies: listings})
+
+	if active, _ := store.CountActive(ctx, 1); active != 1 {
+		t.Fatalf("active reminders after failed delivery = %d, want 1", active)
+	}
+
+	email.err = nil
+	reminders.deliver(ctx, refreshedCity{city: "bhubaneswar", movies: listings})
+
+	if active, _ := store.CountActive(ctx, 1); active != 0 || len(email.notices) != 1 {
+		t.Fatalf("after retry: active = %d, delivered = %d, want 0 and 1", active, len(email.notices))
+	}
+}
@@ -0,0 +1,63 @@
+package social
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const postTimeout = 10 * time.Second
+
+// mastodonMaxLength is the default status limit of Mastodon servers.
+const mastodonMaxLength = 500
+
+// Mastodon posts statuses to an account on a Mastodon server.
+type Mastodon struct {
+	server      string
+	accessToken string
+	client      *http.Client
+}
+
+// NewMastodon posts with an access token that has the write:statuses scope,
+// created under Preferences > Development on server.
+func NewMastodon(server, accessToken string) *Mastodon {
+	return &Mastodon{
+		server:      strings.TrimRight(server, "/"),
+		accessToken: accessToken,
+		client:      &http.Client{Timeout: postTimeout},
+	}
+}
+
+func (m *Mastodon) Name() string {
+	return "mastodon"
+}
+
+func (m *Mastodon) MaxLength() int {
+	return mastodonMaxLength
+}
+
+func (m *Mastodon) Post(ctx context.Context, text string) error {
+	form := url.Values{"status": {text}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.server+"/api/v1/statuses", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("build mastodon request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+m.accessToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post mastodon status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("post mastodon status: unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
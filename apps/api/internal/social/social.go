@@ -0,0 +1,179 @@
+// Package social posts each city's current listings to social network
+// accounts, such as a local city bot on Mastodon or X.
+package social
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+// DefaultTemplate is used for cities without their own template.
+const DefaultTemplate = "Now showing in {{.City}}: {{.Titles}}"
+
+// maxPendingPosts bounds the posts waiting to be published; further ones are
+// dropped rather than blocking scrapes.
+const maxPendingPosts = 16
+
+// Account is a social network account that can publish a text post.
+type Account interface {
+	Name() string
+	Post(ctx context.Context, text string) error
+
+	// MaxLength is the longest post the network accepts, in characters.
+	MaxLength() int
+}
+
+// TemplateData is available to post templates.
+type TemplateData struct {
+	// City is the city's display name.
+	City string
+
+	// Titles lists as many titles as fit in the post, separated by commas,
+	// ending with how many were left out.
+	Titles string
+
+	// Count is the number of movies showing.
+	Count int
+}
+
+type cityResolver interface {
+	Resolve(name string) (cities.City, bool)
+}
+
+// Publisher posts a city's listings after each of its scrapes.
+type Publisher struct {
+	accounts  []Account
+	templates map[string]*template.Template
+	cities    cityResolver
+	logger    *slog.Logger
+
+	pending chan refresh
+}
+
+type refresh struct {
+	city   string
+	movies []movies.Movie
+}
+
+var _ movies.RefreshListener = (*Publisher)(nil)
+
+// ParseTemplates parses a post template for each enabled city, keyed by city
+// name.
+func ParseTemplates(texts map[string]string) (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template, len(texts))
+	for city, text := range texts {
+		parsed, err := template.New(city).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parse template for %s: %w", city, err)
+		}
+
+		if _, err := render(parsed, TemplateData{}); err != nil {
+			return nil, fmt.Errorf("parse template for %s: %w", city, err)
+		}
+
+		templates[city] = parsed
+	}
+
+	return templates, nil
+}
+
+// NewPublisher posts to every account for the cities in templates.
+func NewPublisher(accounts []Account, templates map[string]*template.Template, cities cityResolver, logger *slog.Logger) *Publisher {
+	return &Publisher{
+		accounts:  accounts,
+		templates: templates,
+		cities:    cities,
+		logger:    logger,
+		pending:   make(chan refresh, maxPendingPosts),
+	}
+}
+
+// Run publishes queued posts until ctx is done.
+func (p *Publisher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-p.pending:
+			p.publish(ctx, event)
+		}
+	}
+}
+
+// MoviesRefreshed queues a post for the city if posting is enabled for it.
+func (p *Publisher) MoviesRefreshed(ctx context.Context, city string, current []movies.Movie) {
+	if _, enabled := p.templates[city]; !enabled || len(current) == 0 {
+		return
+	}
+
+	select {
+	case p.pending <- refresh{city: city, movies: current}:
+	default:
+		p.logger.WarnContext(ctx, "social post queue full, dropping post", "city", city)
+	}
+}
+
+func (p *Publisher) publish(ctx context.Context, event refresh) {
+	city, _ := p.cities.Resolve(event.city)
+
+	for _, account := range p.accounts {
+		text, err := compose(p.templates[event.city], city.DisplayName, event.movies, account.MaxLength())
+		if err != nil {
+			p.logger.ErrorContext(ctx, "failed to compose social post", "city", event.city, "account", account.Name(), "error", err)
+			continue
+		}
+
+		if err := account.Post(ctx, text); err != nil {
+			p.logger.ErrorContext(ctx, "failed to publish social post", "city", event.city, "account", account.Name(), "error", err)
+			continue
+		}
+
+		p.logger.InfoContext(ctx, "published social post", "city", event.city, "account", account.Name())
+	}
+}
+
+// compose renders the template with as many titles as fit in maxLength
+// characters.
+func compose(tmpl *template.Template, city string, list []movies.Movie, maxLength int) (string, error) {
+	for shown := len(list); shown >= 0; shown-- {
+		titles := make([]string, 0, shown)
+		for _, movie := range list[:shown] {
+			titles = append(titles, movie.Title)
+		}
+
+		joined := strings.Join(titles, ", ")
+		if omitted := len(list) - shown; omitted > 0 {
+			if shown == 0 {
+				joined = fmt.Sprintf("%d movies", omitted)
+			} else {
+				joined += fmt.Sprintf(" and %d more", omitted)
+			}
+		}
+
+		text, err := render(tmpl, TemplateData{City: city, Titles: joined, Count: len(list)})
+		if err != nil {
+			return "", err
+		}
+
+		if len([]rune(text)) <= maxLength {
+			return text, nil
+		}
+	}
+
+	return "", fmt.Errorf("template does not fit in %d characters", maxLength)
+}
+
+func render(tmpl *template.Template, data TemplateData) (string, error) {
+	var text strings.Builder
+	if err := tmpl.Execute(&text, data); err != nil {
+		return "", fmt.Errorf("render template: %w", err)
+	}
+
+	return strings.TrimSpace(text.String()), nil
+}
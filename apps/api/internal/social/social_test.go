@@ -0,0 +1,160 @@
+package social
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeAccount struct {
+	mu        sync.Mutex
+	maxLength int
+	posts     []string
+}
+
+func (f *fakeAccount) Name() string {
+	return "fake"
+}
+
+func (f *fakeAccount) MaxLength() int {
+	return f.maxLength
+}
+
+func (f *fakeAccount) Post(_ context.Context, text string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.posts = append(f.posts, text)
+	return nil
+}
+
+func TestPublisherPostsOnlyForEnabledCities(t *testing.T) {
+	t.Parallel()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar"}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	templates, err := ParseTemplates(map[string]string{"bhubaneswar": DefaultTemplate})
+	if err != nil {
+		t.Fatalf("ParseTemplates() error = %v", err)
+	}
+
+	account := &fakeAccount{maxLength: 500}
+	publisher := NewPublisher([]Account{account}, templates, registry, slog.New(slog.DiscardHandler))
+	listing := []movies.Movie{{Title: "F1"}, {Title: "Ballerina"}}
+
+	publisher.MoviesRefreshed(context.Background(), "cuttack", listing)
+	publisher.MoviesRefreshed(context.Background(), "bhubaneswar", listing)
+
+	if len(publisher.pending) != 1 {
+		t.Fatalf("pending posts = %d, want 1", len(publisher.pending))
+	}
+
+	publisher.publish(context.Background(), <-publisher.pending)
+
+	if want := []string{"Now showing in Bhubaneswar: F1, Ballerina"}; len(account.posts) != 1 || account.posts[0] != want[0] {
+		t.Fatalf("posts = %q, want %q", account.posts, want)
+	}
+}
+
+func TestComposeDropsTitlesToFit(t *testing.T) {
+	t.Parallel()
+
+	templates, err := ParseTemplates(map[string]string{"cuttack": "{{.Count}} films in {{.City}}: {{.Titles}} #cuttack"})
+	if err != nil {
+		t.Fatalf("ParseTemplates() error = %v", err)
+	}
+
+	list := []movies.Movie{{Title: "Jurassic World Rebirth"}, {Title: "Superman"}, {Title: "F1"}}
+
+	text, err := compose(templates["cuttack"], "Cuttack", list, 62)
+	if err != nil {
+		t.Fatalf("compose() error = %v", err)
+	}
+
+	if want := "3 films in Cuttack: Jurassic World Rebirth and 2 more #cuttack"; text != want {
+		t.Fatalf("compose() = %q, want %q", text, want)
+	}
+}
+
+func TestParseTemplatesRejectsUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ParseTemplates(map[string]string{"cuttack": "{{.Town}}"}); err == nil {
+		t.Fatal("ParseTemplates() error = nil, want unknown field error")
+	}
+}
+
+func TestMastodonPostsStatus(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+
+		if r.URL.Path != "/api/v1/statuses" || r.Header.Get("Authorization") != "Bearer token" || form.Get("status") != "Now showing" {
+			t.Errorf("request = %s %s %q, want status post with bearer token", r.Method, r.URL.Path, body)
+		}
+	}))
+	defer server.Close()
+
+	if err := NewMastodon(server.URL+"/", "token").Post(context.Background(), "Now showing"); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+}
+
+func TestOAuthSignatureMatchesReference(t *testing.T) {
+	t.Parallel()
+
+	// The example from X's "Creating a signature" guide.
+	params := map[string]string{
+		"status":                 "Hello Ladies + Gentlemen, a signed OAuth request!",
+		"include_entities":       "true",
+		"oauth_consumer_key":     "xvz1evFS4wEEPTGEFPHBog",
+		"oauth_nonce":            "kYjzVBB8Y0ZFabxSWbWovY3uYSQ2pTgmZeNu2VS4cg",
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        "1318622958",
+		"oauth_token":            "370773112-GmHxMAgYyLbNEtIKZeRNFsMKPR9EyMZeS9weJAEb",
+		"oauth_version":          "1.0",
+	}
+
+	signature := oauthSignature(http.MethodPost, "https://api.twitter.com/1.1/statuses/update.json", params,
+		"kAcSOqF21Fu85e7zjz7ZN2U4ZRhfV3WpwPAoE3Z7kBw", "LswwdoUaIvS8ltyTt5jkRh4J50vUPVVHtR2YPi5kE")
+
+	if want := "hCtSmYh+iHYCEqBWrE7C7hYmtUk="; signature != want {
+		t.Fatalf("oauthSignature() = %q, want %q", signature, want)
+	}
+}
+
+func TestXPostsSignedRequest(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		if r.URL.Path != "/2/tweets" || string(body) != `{"text":"Now showing"}` || !strings.HasPrefix(r.Header.Get("Authorization"), "OAuth ") {
+			t.Errorf("request = %s %s %q, want signed JSON post", r.Method, r.URL.Path, body)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	account := NewX(XCredentials{ConsumerKey: "key", ConsumerSecret: "secret", AccessToken: "token", AccessTokenSecret: "token-secret"})
+	account.baseURL = server.URL
+
+	if err := account.Post(context.Background(), "Now showing"); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package social
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultXBaseURL = "https://api.x.com"
+
+	// xMaxLength is X's post limit for accounts without a subscription.
+	xMaxLength = 280
+)
+
+// XCredentials are an app's consumer keys and the bot account's access
+// tokens, all from the X developer portal.
+type XCredentials struct {
+	ConsumerKey       string
+	ConsumerSecret    string
+	AccessToken       string
+	AccessTokenSecret string
+}
+
+// X posts to an X account, signing requests with OAuth 1.0a.
+type X struct {
+	credentials XCredentials
+	baseURL     string
+	client      *http.Client
+}
+
+func NewX(credentials XCredentials) *X {
+	return &X{
+		credentials: credentials,
+		baseURL:     defaultXBaseURL,
+		client:      &http.Client{Timeout: postTimeout},
+	}
+}
+
+func (x *X) Name() string {
+	return "x"
+}
+
+func (x *X) MaxLength() int {
+	return xMaxLength
+}
+
+func (x *X) Post(ctx context.Context, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("encode x post: %w", err)
+	}
+
+	endpoint := x.baseURL + "/2/tweets"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build x request: %w", err)
+	}
+
+	authorization, err := x.authorization(http.MethodPost, endpoint, time.Now())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := x.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post to x: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("post to x: unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// authorization returns an OAuth 1.0a HMAC-SHA1 Authorization header. A JSON
+// body is not part of the signature, and the endpoint has no query string.
+func (x *X) authorization(method, endpoint string, now time.Time) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate oauth nonce: %w", err)
+	}
+
+	params := map[string]string{
+		"oauth_consumer_key":     x.credentials.ConsumerKey,
+		"oauth_nonce":            hex.EncodeToString(nonce),
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        strconv.FormatInt(now.Unix(), 10),
+		"oauth_token":            x.credentials.AccessToken,
+		"oauth_version":          "1.0",
+	}
+
+	params["oauth_signature"] = oauthSignature(method, endpoint, params, x.credentials.ConsumerSecret, x.credentials.AccessTokenSecret)
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, oauthEscape(key), oauthEscape(params[key])))
+	}
+
+	return "OAuth " + strings.Join(pairs, ", "), nil
+}
+
+func oauthSignature(method, endpoint string, params map[string]string, consumerSecret, tokenSecret string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, oauthEscape(key)+"="+oauthEscape(params[key]))
+	}
+
+	base := method + "&" + oauthEscape(endpoint) + "&" + oauthEscape(strings.Join(pairs, "&"))
+	mac := hmac.New(sha1.New, []byte(oauthEscape(consumerSecret)+"&"+oauthEscape(tokenSecret)))
+	mac.Write([]byte(base))
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// oauthEscape percent-encodes everything except unreserved characters, as
+// OAuth 1.0a requires.
+func oauthEscape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
@@ -0,0 +1,367 @@
+// Package telegram serves movie listings through a Telegram bot and messages
+// subscribed chats when new movies appear in a city.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+const (
+	defaultBaseURL = "https://api.telegram.org"
+
+	// pollTimeout is how long Telegram holds a getUpdates request open while
+	// waiting for messages.
+	pollTimeout  = 30 * time.Second
+	retryDelay   = 5 * time.Second
+	followerWait = 10 * time.Second
+
+	// maxMessageLength is Telegram's limit on the length of a message.
+	maxMessageLength = 4096
+)
+
+type movieLoader interface {
+	Load(ctx context.Context, city string) ([]movies.Movie, bool, error)
+}
+
+type cityResolver interface {
+	Resolve(name string) (cities.City, bool)
+}
+
+// SubscriptionStore records which chats want new-movie messages for a city.
+type SubscriptionStore interface {
+	Subscribe(ctx context.Context, chatID int64, city string) error
+	Unsubscribe(ctx context.Context, chatID int64, city string) (bool, error)
+	Subscribers(ctx context.Context, city string) ([]int64, error)
+}
+
+// ChatLinker ties a chat to the user account that issued a link code, so the
+// user's notifications can be sent there.
+type ChatLinker interface {
+	// LinkChat returns ErrInvalidLinkCode for codes that are malformed,
+	// expired, or were not issued by the API.
+	LinkChat(ctx context.Context, code string, chatID int64) error
+}
+
+// ErrInvalidLinkCode is returned by a ChatLinker for codes it does not accept.
+var ErrInvalidLinkCode = errors.New("invalid or expired link code")
+
+type Options struct {
+	// Leader reports whether this replica should poll for messages. Telegram
+	// allows a single poller per bot, so only the scheduler leader polls. A
+	// nil Leader always polls.
+	Leader func() bool
+
+	// Linker enables the /link command. It is nil when user accounts are
+	// disabled.
+	Linker ChatLinker
+}
+
+type Bot struct {
+	token   string
+	baseURL string
+	client  *http.Client
+	loader  movieLoader
+	cities  cityResolver
+	store   SubscriptionStore
+	opts    Options
+	logger  *slog.Logger
+}
+
+var _ movies.ChangeListener = (*Bot)(nil)
+
+func NewBot(token string, loader movieLoader, cities cityResolver, store SubscriptionStore, opts Options, logger *slog.Logger) *Bot {
+	return &Bot{
+		token:   token,
+		baseURL: defaultBaseURL,
+		client:  &http.Client{Timeout: pollTimeout + 10*time.Second},
+		loader:  loader,
+		cities:  cities,
+		store:   store,
+		opts:    opts,
+		logger:  logger,
+	}
+}
+
+// Run answers commands until ctx is done.
+func (b *Bot) Run(ctx context.Context) {
+	b.poll(ctx)
+}
+
+// MoviesAdded messages the city's subscribers. A failed message is returned,
+// so the outbox retries it and chats that were already messaged may be
+// messaged again.
+func (b *Bot) MoviesAdded(ctx context.Context, cityName string, added []movies.Movie) error {
+	chatIDs, err := b.store.Subscribers(ctx, cityName)
+	if err != nil {
+		return fmt.Errorf("load telegram subscribers: %w", err)
+	}
+
+	city, _ := b.cities.Resolve(cityName)
+	text := movieList(fmt.Sprintf("New in %s:", city.DisplayName), added)
+
+	var errs []error
+	for _, chatID := range chatIDs {
+		if err := b.SendMessage(ctx, chatID, text); err != nil {
+			errs = append(errs, fmt.Errorf("message chat %d: %w", chatID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+type update struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+func (b *Bot) poll(ctx context.Context) {
+	var offset int64
+
+	for ctx.Err() == nil {
+		if b.opts.Leader != nil && !b.opts.Leader() {
+			sleep(ctx, followerWait)
+			continue
+		}
+
+		updates, err := b.getUpdates(ctx, offset)
+		if err != nil {
+			if ctx.Err() == nil {
+				b.logger.ErrorContext(ctx, "failed to poll telegram", "error", err)
+				sleep(ctx, retryDelay)
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+
+			if update.Message != nil {
+				b.handle(ctx, update.Message.Chat.ID, update.Message.Text)
+			}
+		}
+	}
+}
+
+// handle answers a single command.
+func (b *Bot) handle(ctx context.Context, chatID int64, text string) {
+	command, arg, _ := strings.Cut(strings.TrimSpace(text), " ")
+	// Commands in group chats may be addressed as /movies@SomeBot.
+	command, _, _ = strings.Cut(command, "@")
+	arg = strings.TrimSpace(arg)
+
+	var reply string
+	switch command {
+	case "/movies", "/subscribe", "/unsubscribe":
+		if arg == "" {
+			reply = fmt.Sprintf("Usage: %s <city>", command)
+			break
+		}
+
+		city, _ := b.cities.Resolve(arg)
+		reply = b.runCityCommand(ctx, command, chatID, city)
+	case "/link":
+		reply = b.link(ctx, chatID, arg)
+	default:
+		reply = "Commands:\n" +
+			"/movies <city> - list the movies showing in a city\n" +
+			"/subscribe <city> - get a message when new movies appear\n" +
+			"/unsubscribe <city> - stop those messages"
+		if b.opts.Linker != nil {
+			reply += "\n/link <code> - get your account's reminders in this chat"
+		}
+	}
+
+	if err := b.SendMessage(ctx, chatID, reply); err != nil {
+		b.logger.ErrorContext(ctx, "failed to send telegram reply", "chat_id", chatID, "error", err)
+	}
+}
+
+func (b *Bot) link(ctx context.Context, chatID int64, code string) string {
+	if b.opts.Linker == nil {
+		return "Linking accounts is not enabled."
+	}
+
+	if code == "" {
+		return "Usage: /link <code>"
+	}
+
+	err := b.opts.Linker.LinkChat(ctx, code, chatID)
+	if errors.Is(err, ErrInvalidLinkCode) {
+		return "That code is invalid or has expired. Request a new one and try again."
+	}
+
+	if err != nil {
+		b.logger.ErrorContext(ctx, "failed to link telegram chat", "chat_id", chatID, "error", err)
+		return "Couldn't link your account right now."
+	}
+
+	return "Linked. Your reminders will be sent to this chat."
+}
+
+func (b *Bot) runCityCommand(ctx context.Context, command string, chatID int64, city cities.City) string {
+	switch command {
+	case "/movies":
+		list, _, err := b.loader.Load(ctx, city.Name)
+		if err != nil {
+			b.logger.ErrorContext(ctx, "failed to load movies for telegram", "city", city.Name, "error", err)
+			return fmt.Sprintf("Couldn't load movies for %s right now.", city.DisplayName)
+		}
+
+		return movieList(fmt.Sprintf("Now showing in %s:", city.DisplayName), list)
+	case "/subscribe":
+		if err := b.store.Subscribe(ctx, chatID, city.Name); err != nil {
+			b.logger.ErrorContext(ctx, "failed to save telegram subscription", "city", city.Name, "error", err)
+			return "Couldn't subscribe right now."
+		}
+
+		return fmt.Sprintf("Subscribed to new movies in %s.", city.DisplayName)
+	default:
+		removed, err := b.store.Unsubscribe(ctx, chatID, city.Name)
+		if err != nil {
+			b.logger.ErrorContext(ctx, "failed to remove telegram subscription", "city", city.Name, "error", err)
+			return "Couldn't unsubscribe right now."
+		}
+
+		if !removed {
+			return fmt.Sprintf("You weren't subscribed to %s.", city.DisplayName)
+		}
+
+		return fmt.Sprintf("Unsubscribed from %s.", city.DisplayName)
+	}
+}
+
+// movieList formats one movie per line under header, leaving out movies that
+// would push the message past Telegram's length limit.
+func movieList(header string, list []movies.Movie) string {
+	var message strings.Builder
+	message.WriteString(header)
+
+	for i, movie := range list {
+		line := "\n- " + movie.Title
+		if movie.Href != "" {
+			line += " " + movie.Href
+		}
+
+		more := fmt.Sprintf("\n\u2026and %d more", len(list)-i)
+		if message.Len()+len(line)+len(more) > maxMessageLength {
+			message.WriteString(more)
+			break
+		}
+
+		message.WriteString(line)
+	}
+
+	return message.String()
+}
+
+func (b *Bot) getUpdates(ctx context.Context, offset int64) ([]update, error) {
+	query := url.Values{
+		"offset":          {strconv.FormatInt(offset, 10)},
+		"timeout":         {strconv.Itoa(int(pollTimeout.Seconds()))},
+		"allowed_updates": {`["message"]`},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.methodURL("getUpdates")+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []update
+	if err := b.do(req, &updates); err != nil {
+		return nil, fmt.Errorf("get updates: %w", err)
+	}
+
+	return updates, nil
+}
+
+// SendMessage sends text to a chat, which must have started a conversation
+// with the bot.
+func (b *Bot) SendMessage(ctx context.Context, chatID int64, text string) error {
+	body, err := json.Marshal(map[string]any{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.methodURL("sendMessage"), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := b.do(req, nil); err != nil {
+		return fmt.Errorf("send message: %w", err)
+	}
+
+	return nil
+}
+
+func (b *Bot) methodURL(method string) string {
+	return b.baseURL + "/bot" + b.token + "/" + method
+}
+
+// do sends a Bot API request and decodes its result into dst, if set.
+func (b *Bot) do(req *http.Request, dst any) error {
+	resp, err := b.client.Do(req)
+	if err != nil {
+		// The request URL contains the bot token, so it is kept out of
+		// errors that end up in logs.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		return err
+	}
+	defer resp.Body.Close()
+
+	var payload struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	if !payload.OK {
+		return fmt.Errorf("telegram error %d: %s", resp.StatusCode, payload.Description)
+	}
+
+	if dst == nil {
+		return nil
+	}
+
+	return json.Unmarshal(payload.Result, dst)
+}
+
+func sleep(ctx context.Context, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,182 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeLoader struct {
+	movies []movies.Movie
+	city   string
+}
+
+func (f *fakeLoader) Load(_ context.Context, city string) ([]movies.Movie, bool, error) {
+	f.city = city
+	return f.movies, true, nil
+}
+
+type fakeStore struct {
+	mu   sync.Mutex
+	subs map[string][]int64
+}
+
+func (f *fakeStore) Subscribe(_ context.Context, chatID int64, city string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.subs[city] = append(f.subs[city], chatID)
+	return nil
+}
+
+func (f *fakeStore) Unsubscribe(_ context.Context, _ int64, _ string) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeStore) Subscribers(_ context.Context, city string) ([]int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.subs[city], nil
+}
+
+type sentMessage struct {
+	ChatID int64  `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+func testBot(t *testing.T, loader movieLoader, store SubscriptionStore) (*Bot, func() []sentMessage) {
+	t.Helper()
+
+	var (
+		mu   sync.Mutex
+		sent []sentMessage
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bottoken/sendMessage" {
+			t.Errorf("path = %q, want sendMessage", r.URL.Path)
+		}
+
+		var message sentMessage
+		_ = json.NewDecoder(r.Body).Decode(&message)
+
+		mu.Lock()
+		sent = append(sent, message)
+		mu.Unlock()
+
+		_, _ = w.Write([]byte(`{"ok": true, "result": {}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	registry, err := cities.NewRegistry([]cities.City{
+		{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Timezone: "Asia/Kolkata", Aliases: []string{"bbsr"}},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	bot := NewBot("token", loader, registry, store, Options{}, slog.New(slog.DiscardHandler))
+	bot.baseURL = server.URL
+
+	return bot, func() []sentMessage {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]sentMessage(nil), sent...)
+	}
+}
+
+func TestBotListsMoviesForCityAlias(t *testing.T) {
+	t.Parallel()
+
+	loader := &fakeLoader{movies: []movies.Movie{{Title: "Ballerina", Href: "https://in.bookmyshow.com/ballerina"}}}
+	bot, sent := testBot(t, loader, &fakeStore{subs: map[string][]int64{}})
+
+	bot.handle(context.Background(), 42, "/movies@NowScreeningBot BBSR")
+
+	if loader.city != "bhubaneswar" {
+		t.Fatalf("loaded city = %q, want bhubaneswar", loader.city)
+	}
+
+	messages := sent()
+	if len(messages) != 1 || messages[0].ChatID != 42 || messages[0].Text != "Now showing in Bhubaneswar:\n- Ballerina https://in.bookmyshow.com/ballerina" {
+		t.Fatalf("sent = %+v, want Bhubaneswar listing", messages)
+	}
+}
+
+func TestBotNotifiesSubscribersOfNewMovies(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeStore{subs: map[string][]int64{}}
+	bot, sent := testBot(t, &fakeLoader{}, store)
+
+	bot.handle(context.Background(), 42, "/subscribe bbsr")
+	if err := bot.MoviesAdded(context.Background(), "bhubaneswar", []movies.Movie{{Title: "F1"}}); err != nil {
+		t.Fatalf("MoviesAdded() error = %v", err)
+	}
+
+	messages := sent()
+	if len(messages) != 2 || messages[0].Text != "Subscribed to new movies in Bhubaneswar." {
+		t.Fatalf("sent = %+v, want subscription confirmation and notification", messages)
+	}
+
+	if messages[1].ChatID != 42 || messages[1].Text != "New in Bhubaneswar:\n- F1" {
+		t.Fatalf("notification = %+v, want F1 for chat 42", messages[1])
+	}
+}
+
+func TestMovieListStaysWithinMessageLimit(t *testing.T) {
+	t.Parallel()
+
+	list := make([]movies.Movie, 500)
+	for i := range list {
+		list[i] = movies.Movie{Title: strings.Repeat("x", 40)}
+	}
+
+	message := movieList("Now showing in Cuttack:", list)
+	if len(message) > maxMessageLength || !strings.Contains(message, "more") {
+		t.Fatalf("len(message) = %d, want truncated within %d", len(message), maxMessageLength)
+	}
+}
+
+type fakeLinker struct {
+	chats map[string]int64
+}
+
+func (l *fakeLinker) LinkChat(_ context.Context, code string, chatID int64) error {
+	if code != "valid" {
+		return ErrInvalidLinkCode
+	}
+
+	l.chats[code] = chatID
+
+	return nil
+}
+
+func TestBotLinksChats(t *testing.T) {
+	t.Parallel()
+
+	bot, sent := testBot(t, &fakeLoader{}, &fakeStore{subs: map[string][]int64{}})
+	linker := &fakeLinker{chats: map[string]int64{}}
+	bot.opts.Linker = linker
+
+	bot.handle(context.Background(), 42, "/link expired")
+	bot.handle(context.Background(), 42, "/link valid")
+
+	messages := sent()
+	if len(messages) != 2 || messages[1].Text != "Linked. Your reminders will be sent to this chat." {
+		t.Fatalf("sent = %+v, want rejection then confirmation", messages)
+	}
+
+	if linker.chats["valid"] != 42 {
+		t.Fatalf("linked chats = %v, want chat 42", linker.chats)
+	}
+}
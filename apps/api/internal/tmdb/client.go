@@ -0,0 +1,176 @@
+// Package tmdb looks up where movies can be streamed from TMDB's watch
+// provider data, which is sourced from JustWatch, and finds their trailers.
+package tmdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go-scraping/internal/movies"
+)
+
+const (
+	defaultBaseURL = "https://api.themoviedb.org/3"
+	requestTimeout = 5 * time.Second
+)
+
+type Client struct {
+	apiKey  string
+	region  string
+	baseURL string
+	client  *http.Client
+}
+
+var (
+	_ movies.StreamingProvider = (*Client)(nil)
+	_ movies.TrailerProvider   = (*Client)(nil)
+)
+
+// NewClient reports availability in region, an ISO 3166-1 country code such
+// as "IN".
+func NewClient(apiKey, region string) *Client {
+	return &Client{
+		apiKey:  apiKey,
+		region:  region,
+		baseURL: defaultBaseURL,
+		client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+type searchResponse struct {
+	Results []struct {
+		ID int `json:"id"`
+	} `json:"results"`
+}
+
+type providersResponse struct {
+	Results map[string]struct {
+		Link     string `json:"link"`
+		Flatrate []struct {
+			ProviderName string `json:"provider_name"`
+		} `json:"flatrate"`
+	} `json:"results"`
+}
+
+type videosResponse struct {
+	Results []struct {
+		Name     string `json:"name"`
+		Key      string `json:"key"`
+		Site     string `json:"site"`
+		Type     string `json:"type"`
+		Official bool   `json:"official"`
+	} `json:"results"`
+}
+
+// LookupStreaming finds the movie by title, narrowed to year when it is known,
+// and reports the subscription services streaming it in the client's region.
+// It returns nil if TMDB has no such movie.
+func (c *Client) LookupStreaming(ctx context.Context, title string, year int) (*movies.Streaming, error) {
+	id, found, err := c.findMovie(ctx, title, year)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var providers providersResponse
+	if err := c.get(ctx, fmt.Sprintf("/movie/%d/watch/providers", id), url.Values{}, &providers); err != nil {
+		return nil, fmt.Errorf("query tmdb watch providers: %w", err)
+	}
+
+	region := providers.Results[c.region]
+	streaming := &movies.Streaming{Link: region.Link}
+	for _, provider := range region.Flatrate {
+		streaming.Providers = append(streaming.Providers, provider.ProviderName)
+	}
+	streaming.Available = len(streaming.Providers) > 0
+
+	return streaming, nil
+}
+
+// LookupTrailer finds the movie like LookupStreaming and returns its YouTube
+// trailer, preferring official ones. It returns nil if TMDB has no such movie
+// or no trailer for it.
+func (c *Client) LookupTrailer(ctx context.Context, title string, year int) (*movies.Trailer, error) {
+	id, found, err := c.findMovie(ctx, title, year)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var videos videosResponse
+	if err := c.get(ctx, fmt.Sprintf("/movie/%d/videos", id), url.Values{}, &videos); err != nil {
+		return nil, fmt.Errorf("query tmdb videos: %w", err)
+	}
+
+	best := -1
+	for i, video := range videos.Results {
+		if video.Site != "YouTube" || video.Type != "Trailer" || video.Key == "" {
+			continue
+		}
+
+		if best < 0 || (video.Official && !videos.Results[best].Official) {
+			best = i
+		}
+	}
+
+	if best < 0 {
+		return nil, nil
+	}
+
+	video := videos.Results[best]
+	key := url.PathEscape(video.Key)
+
+	return &movies.Trailer{
+		Title:           video.Name,
+		Provider:        "YouTube",
+		URL:             "https://www.youtube.com/watch?v=" + url.QueryEscape(video.Key),
+		EmbedURL:        "https://www.youtube-nocookie.com/embed/" + key,
+		ThumbnailURL:    "https://i.ytimg.com/vi/" + key + "/hqdefault.jpg",
+		ThumbnailWidth:  480,
+		ThumbnailHeight: 360,
+	}, nil
+}
+
+// findMovie returns the TMDB ID of the best match for title, narrowed to year
+// when it is known, and whether there was any match.
+func (c *Client) findMovie(ctx context.Context, title string, year int) (int, bool, error) {
+	query := url.Values{"query": {title}}
+	if year > 0 {
+		query.Set("year", strconv.Itoa(year))
+	}
+
+	var search searchResponse
+	if err := c.get(ctx, "/search/movie", query, &search); err != nil {
+		return 0, false, fmt.Errorf("search tmdb: %w", err)
+	}
+
+	if len(search.Results) == 0 {
+		return 0, false, nil
+	}
+
+	return search.Results[0].ID, true, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, dst any) error {
+	query.Set("api_key", c.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
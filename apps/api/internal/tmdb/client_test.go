@@ -0,0 +1,112 @@
+package tmdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"go-scraping/internal/movies"
+)
+
+func testClient(t *testing.T, searchBody string) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /search/movie", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_key") != "key" || r.URL.Query().Get("query") != "Ballerina" || r.URL.Query().Get("year") != "2025" {
+			t.Errorf("query = %q, want api_key, title, and year", r.URL.RawQuery)
+		}
+
+		_, _ = w.Write([]byte(searchBody))
+	})
+	mux.HandleFunc("GET /movie/541671/watch/providers", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"results": {
+			"IN": {"link": "https://www.themoviedb.org/movie/541671/watch?locale=IN", "flatrate": [{"provider_name": "Amazon Prime Video"}]},
+			"US": {"flatrate": [{"provider_name": "Netflix"}]}
+		}}`))
+	})
+	mux.HandleFunc("GET /movie/541671/videos", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"results": [
+			{"name": "Behind the Scenes", "key": "bts", "site": "YouTube", "type": "Featurette", "official": true},
+			{"name": "Fan Trailer", "key": "fan", "site": "YouTube", "type": "Trailer", "official": false},
+			{"name": "Vimeo Trailer", "key": "123", "site": "Vimeo", "type": "Trailer", "official": true},
+			{"name": "Official Trailer", "key": "abc123", "site": "YouTube", "type": "Trailer", "official": true}
+		]}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := NewClient("key", "IN")
+	client.baseURL = server.URL
+
+	return client
+}
+
+func TestLookupStreamingReportsRegionProviders(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t, `{"results": [{"id": 541671}]}`)
+
+	streaming, err := client.LookupStreaming(context.Background(), "Ballerina", 2025)
+	if err != nil {
+		t.Fatalf("LookupStreaming() error = %v", err)
+	}
+
+	want := &movies.Streaming{
+		Available: true,
+		Providers: []string{"Amazon Prime Video"},
+		Link:      "https://www.themoviedb.org/movie/541671/watch?locale=IN",
+	}
+	if !reflect.DeepEqual(streaming, want) {
+		t.Fatalf("LookupStreaming() = %+v, want %+v", streaming, want)
+	}
+}
+
+func TestLookupStreamingReturnsNilWhenNotFound(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t, `{"results": []}`)
+
+	streaming, err := client.LookupStreaming(context.Background(), "Ballerina", 2025)
+	if err != nil || streaming != nil {
+		t.Fatalf("LookupStreaming() = %+v, %v, want nil, nil", streaming, err)
+	}
+}
+
+func TestLookupTrailerPrefersOfficialYouTubeTrailer(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t, `{"results": [{"id": 541671}]}`)
+
+	trailer, err := client.LookupTrailer(context.Background(), "Ballerina", 2025)
+	if err != nil {
+		t.Fatalf("LookupTrailer() error = %v", err)
+	}
+
+	want := &movies.Trailer{
+		Title:           "Official Trailer",
+		Provider:        "YouTube",
+		URL:             "https://www.youtube.com/watch?v=abc123",
+		EmbedURL:        "https://www.youtube-nocookie.com/embed/abc123",
+		ThumbnailURL:    "https://i.ytimg.com/vi/abc123/hqdefault.jpg",
+		ThumbnailWidth:  480,
+		ThumbnailHeight: 360,
+	}
+	if !reflect.DeepEqual(trailer, want) {
+		t.Fatalf("LookupTrailer() = %+v, want %+v", trailer, want)
+	}
+}
+
+func TestLookupTrailerReturnsNilWhenNotFound(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t, `{"results": []}`)
+
+	trailer, err := client.LookupTrailer(context.Background(), "Ballerina", 2025)
+	if err != nil || trailer != nil {
+		t.Fatalf("LookupTrailer() = %+v, %v, want nil, nil", trailer, err)
+	}
+}
@@ -0,0 +1,81 @@
+// Package usage counts the API requests each user makes per day, so a shared
+// deployment can spot accounts that go beyond fair use.
+package usage
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// dateLayout is how days are written, in UTC.
+const dateLayout = time.DateOnly
+
+// recordTimeout bounds how long counting a request may take. Counting runs
+// after the request's context may have been cancelled.
+const recordTimeout = 5 * time.Second
+
+// Day is a user's request count for one UTC day.
+type Day struct {
+	Date     string `json:"date"`
+	Requests int64  `json:"requests"`
+}
+
+// UserDay is one user's request count for one UTC day, for admin reports.
+type UserDay struct {
+	UserID   int64  `json:"user_id"`
+	Email    string `json:"email"`
+	Date     string `json:"date"`
+	Requests int64  `json:"requests"`
+}
+
+type Store interface {
+	// Increment adds one request to the user's count for date.
+	Increment(ctx context.Context, userID int64, date string) error
+
+	// List returns the user's counts from since onwards, newest first.
+	List(ctx context.Context, userID int64, since string) ([]Day, error)
+
+	// Report returns every user's counts from since onwards, newest first
+	// and then busiest first.
+	Report(ctx context.Context, since string) ([]UserDay, error)
+}
+
+type Tracker struct {
+	store  Store
+	logger *slog.Logger
+}
+
+func New(store Store, logger *slog.Logger) *Tracker {
+	return &Tracker{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Record counts a request by the user in the background, so it never delays
+// the response. Failures are logged and the request goes uncounted.
+func (t *Tracker) Record(ctx context.Context, userID int64) {
+	date := time.Now().UTC().Format(dateLayout)
+
+	go func() {
+		recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+		defer cancel()
+
+		if err := t.store.Increment(recordCtx, userID, date); err != nil {
+			t.logger.ErrorContext(recordCtx, "failed to record usage", "user_id", userID, "error", err)
+		}
+	}()
+}
+
+// Usage returns the user's daily counts from the UTC day of since onwards,
+// newest first.
+func (t *Tracker) Usage(ctx context.Context, userID int64, since time.Time) ([]Day, error) {
+	return t.store.List(ctx, userID, since.UTC().Format(dateLayout))
+}
+
+// Report returns every user's daily counts from the UTC day of since
+// onwards.
+func (t *Tracker) Report(ctx context.Context, since time.Time) ([]UserDay, error) {
+	return t.store.Report(ctx, since.UTC().Format(dateLayout))
+}
@@ -0,0 +1,64 @@
+package usage
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type fakeStore struct {
+	incremented chan string
+	since       string
+}
+
+func (f *fakeStore) Increment(_ context.Context, _ int64, date string) error {
+	f.incremented <- date
+	return nil
+}
+
+func (f *fakeStore) List(_ context.Context, _ int64, since string) ([]Day, error) {
+	f.since = since
+	return nil, nil
+}
+
+func (f *fakeStore) Report(_ context.Context, since string) ([]UserDay, error) {
+	f.since = since
+	return nil, nil
+}
+
+func TestRecordCountsTodayInUTC(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeStore{incremented: make(chan string, 1)}
+	tracker := New(store, slog.New(slog.DiscardHandler))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tracker.Record(ctx, 1)
+	cancel()
+
+	select {
+	case date := <-store.incremented:
+		if want := time.Now().UTC().Format(time.DateOnly); date != want {
+			t.Fatalf("Increment() date = %q, want %q", date, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Record() did not increment the count")
+	}
+}
+
+func TestUsageFormatsSinceAsUTCDate(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeStore{}
+	tracker := New(store, slog.New(slog.DiscardHandler))
+
+	since := time.Date(2025, 7, 14, 2, 0, 0, 0, time.FixedZone("IST", 5*60*60+30*60))
+	if _, err := tracker.Usage(context.Background(), 1, since); err != nil {
+		t.Fatalf("Usage() error = %v", err)
+	}
+
+	if store.since != "2025-07-13" {
+		t.Fatalf("List() since = %q, want %q", store.since, "2025-07-13")
+	}
+}
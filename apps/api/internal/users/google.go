@@ -0,0 +1,152 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	googleAuthURL     = "https://accounts.google.com/o/oauth2/v2/auth"
+	googleTokenURL    = "https://oauth2.googleapis.com/token"
+	googleUserInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
+
+	googleRequestTimeout = 10 * time.Second
+)
+
+// Identity is an account at an OAuth provider, identified by Subject.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// Google signs users in with Google's OAuth 2.0 authorization code flow.
+type Google struct {
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	client       *http.Client
+
+	authURL     string
+	tokenURL    string
+	userInfoURL string
+}
+
+// NewGoogle uses an OAuth client created in the Google Cloud console, whose
+// authorized redirect URIs include redirectURL.
+func NewGoogle(clientID, clientSecret, redirectURL string) *Google {
+	return &Google{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		client:       &http.Client{Timeout: googleRequestTimeout},
+		authURL:      googleAuthURL,
+		tokenURL:     googleTokenURL,
+		userInfoURL:  googleUserInfoURL,
+	}
+}
+
+// AuthCodeURL returns the consent page users are sent to. Google redirects
+// back with state unchanged.
+func (g *Google) AuthCodeURL(state string) string {
+	params := url.Values{
+		"client_id":     {g.clientID},
+		"redirect_uri":  {g.redirectURL},
+		"response_type": {"code"},
+		"scope":         {"openid email"},
+		"state":         {state},
+	}
+
+	return g.authURL + "?" + params.Encode()
+}
+
+// Exchange redeems the authorization code Google redirected back with and
+// returns the identity of the user who granted it.
+func (g *Google) Exchange(ctx context.Context, code string) (Identity, error) {
+	accessToken, err := g.token(ctx, code)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.userInfoURL, nil)
+	if err != nil {
+		return Identity{}, fmt.Errorf("build google userinfo request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return Identity{}, fmt.Errorf("query google userinfo: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Identity{}, fmt.Errorf("query google userinfo: unexpected status %s", resp.Status)
+	}
+
+	var info struct {
+		Subject       string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return Identity{}, fmt.Errorf("decode google userinfo: %w", err)
+	}
+
+	if info.Subject == "" {
+		return Identity{}, fmt.Errorf("query google userinfo: response has no subject")
+	}
+
+	return Identity{
+		Provider:      "google",
+		Subject:       info.Subject,
+		Email:         info.Email,
+		EmailVerified: info.EmailVerified,
+	}, nil
+}
+
+func (g *Google) token(ctx context.Context, code string) (string, error) {
+	form := url.Values{
+		"code":          {code},
+		"client_id":     {g.clientID},
+		"client_secret": {g.clientSecret},
+		"redirect_uri":  {g.redirectURL},
+		"grant_type":    {"authorization_code"},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("build google token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("exchange google code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Google answers an invalid or reused code with 400 invalid_grant.
+	if resp.StatusCode == http.StatusBadRequest {
+		return "", ErrInvalidCredentials
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("exchange google code: unexpected status %s", resp.Status)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("decode google token: %w", err)
+	}
+
+	return token.AccessToken, nil
+}
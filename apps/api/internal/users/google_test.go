@@ -0,0 +1,78 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func newTestGoogle(t *testing.T, handler http.Handler) *Google {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	google := NewGoogle("client-id", "client-secret", "http://localhost:8080/auth/google/callback")
+	google.tokenURL = server.URL + "/token"
+	google.userInfoURL = server.URL + "/userinfo"
+
+	return google
+}
+
+func TestGoogleAuthCodeURL(t *testing.T) {
+	t.Parallel()
+
+	google := NewGoogle("client-id", "client-secret", "http://localhost:8080/auth/google/callback")
+
+	parsed, err := url.Parse(google.AuthCodeURL("xyz"))
+	if err != nil {
+		t.Fatalf("AuthCodeURL() is not a URL: %v", err)
+	}
+
+	query := parsed.Query()
+	if query.Get("client_id") != "client-id" || query.Get("state") != "xyz" || query.Get("scope") != "openid email" {
+		t.Fatalf("AuthCodeURL() query = %v, want client_id, state, and scope", query)
+	}
+}
+
+func TestGoogleExchange(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "good-code" || r.FormValue("client_secret") != "client-secret" {
+			http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "google-token"})
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer google-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"sub": "1234", "email": "ana@example.com", "email_verified": true})
+	})
+
+	google := newTestGoogle(t, mux)
+
+	identity, err := google.Exchange(context.Background(), "good-code")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+
+	want := Identity{Provider: "google", Subject: "1234", Email: "ana@example.com", EmailVerified: true}
+	if identity != want {
+		t.Fatalf("Exchange() = %+v, want %+v", identity, want)
+	}
+
+	if _, err := google.Exchange(context.Background(), "bad-code"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Exchange() bad code error = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
@@ -0,0 +1,103 @@
+package users
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	accessTokenType  = "access"
+	refreshTokenType = "refresh"
+	linkTokenType    = "link"
+)
+
+// jwtHeader is the fixed header of every token, which is also the only one
+// accepted, so tokens signed with another algorithm are rejected.
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+type claims struct {
+	Subject   string `json:"sub"`
+	Type      string `json:"typ"`
+	ID        string `json:"jti,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// signer issues and verifies HS256 JSON Web Tokens.
+type signer struct {
+	secret []byte
+}
+
+func (s signer) sign(c claims) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("encode token claims: %w", err)
+	}
+
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	return unsigned + "." + s.signature(unsigned), nil
+}
+
+// verify returns the claims of a validly signed, unexpired token of the
+// given type.
+func (s signer) verify(token, tokenType string, now time.Time) (claims, error) {
+	header, rest, _ := strings.Cut(token, ".")
+	payload, signature, found := strings.Cut(rest, ".")
+	if !found || header != jwtHeader {
+		return claims{}, ErrInvalidToken
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(s.signature(header+"."+payload))) {
+		return claims{}, ErrInvalidToken
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return claims{}, ErrInvalidToken
+	}
+
+	var c claims
+	if err := json.Unmarshal(decoded, &c); err != nil {
+		return claims{}, ErrInvalidToken
+	}
+
+	if c.Type != tokenType || now.Unix() >= c.ExpiresAt {
+		return claims{}, ErrInvalidToken
+	}
+
+	return c, nil
+}
+
+func (s signer) signature(unsigned string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(unsigned))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func subjectID(c claims) (int64, error) {
+	id, err := strconv.ParseInt(c.Subject, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+
+	return id, nil
+}
+
+func newTokenID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
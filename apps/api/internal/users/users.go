@@ -0,0 +1,402 @@
+// Package users registers accounts and authenticates them with short-lived
+// JWT access tokens and longer-lived, rotating refresh tokens.
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minPasswordLength = 8
+
+	// maxPasswordLength is bcrypt's input limit in bytes.
+	maxPasswordLength = 72
+
+	defaultAccessTTL  = 15 * time.Minute
+	defaultRefreshTTL = 30 * 24 * time.Hour
+
+	linkTokenTTL = 10 * time.Minute
+
+	maxLanguages      = 10
+	maxLanguageLength = 40
+)
+
+var (
+	ErrEmailTaken         = errors.New("an account with this email already exists")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+	ErrNotFound           = errors.New("user not found")
+	ErrUnverifiedEmail    = errors.New("the provider has not verified this email")
+)
+
+// InvalidInputError describes a registration or preferences that were
+// rejected, and the field at fault.
+type InvalidInputError struct {
+	Field  string
+	Reason string
+}
+
+func (e *InvalidInputError) Error() string {
+	return e.Reason
+}
+
+type User struct {
+	ID        int64     `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TokenPair is returned on login and refresh. ExpiresIn is the access token's
+// lifetime in seconds.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// Preferences are a user's defaults for movie listings. HomeCity is a
+// registered city name, or empty if unset.
+type Preferences struct {
+	HomeCity  string   `json:"home_city"`
+	Languages []string `json:"languages"`
+}
+
+// Store keeps accounts and the refresh tokens issued to them.
+type Store interface {
+	// CreateUser returns ErrEmailTaken if the email is already registered.
+	CreateUser(ctx context.Context, email, passwordHash string) (User, error)
+
+	// CreateIdentityUser creates a user without a password who signs in
+	// through the identity, returning ErrEmailTaken if the email is already
+	// registered.
+	CreateIdentityUser(ctx context.Context, identity Identity, email string) (User, error)
+
+	// UserByIdentity returns the user who signs in through the identity, or
+	// ErrNotFound.
+	UserByIdentity(ctx context.Context, identity Identity) (User, error)
+
+	// UserByEmail returns the user and their password hash, or ErrNotFound.
+	UserByEmail(ctx context.Context, email string) (User, string, error)
+	UserByID(ctx context.Context, id int64) (User, error)
+
+	// Preferences returns the user's preferences, which are empty if they
+	// were never saved.
+	Preferences(ctx context.Context, userID int64) (Preferences, error)
+	SavePreferences(ctx context.Context, userID int64, prefs Preferences) error
+
+	SaveRefreshToken(ctx context.Context, id string, userID int64, expiresAt time.Time) error
+
+	// RevokeRefreshToken marks the token as used, reporting false if it was
+	// unknown or already revoked.
+	RevokeRefreshToken(ctx context.Context, id string) (bool, error)
+}
+
+type Options struct {
+	// Secret signs tokens. It must be at least 32 bytes.
+	Secret string
+
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+type Service struct {
+	store      Store
+	signer     signer
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	logger     *slog.Logger
+
+	// dummyHash is compared against when a login names an unknown email,
+	// so that response times do not reveal which emails are registered.
+	dummyHash []byte
+}
+
+func NewService(store Store, opts Options, logger *slog.Logger) (*Service, error) {
+	if len(opts.Secret) < 32 {
+		return nil, errors.New("token secret must be at least 32 bytes")
+	}
+
+	accessTTL := opts.AccessTTL
+	if accessTTL <= 0 {
+		accessTTL = defaultAccessTTL
+	}
+
+	refreshTTL := opts.RefreshTTL
+	if refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTTL
+	}
+
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("hash dummy password: %w", err)
+	}
+
+	return &Service{
+		store:      store,
+		signer:     signer{secret: []byte(opts.Secret)},
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		logger:     logger,
+		dummyHash:  dummyHash,
+	}, nil
+}
+
+func (s *Service) Register(ctx context.Context, email, password string) (User, error) {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Name != "" {
+		return User{}, &InvalidInputError{Field: "email", Reason: "email must be a valid address"}
+	}
+
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return User{}, &InvalidInputError{Field: "password", Reason: fmt.Sprintf("password must be %d to %d bytes", minPasswordLength, maxPasswordLength)}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return User{}, fmt.Errorf("hash password: %w", err)
+	}
+
+	user, err := s.store.CreateUser(ctx, strings.ToLower(address.Address), string(hash))
+	if err != nil {
+		return User{}, err
+	}
+
+	s.logger.InfoContext(ctx, "user registered", "user_id", user.ID)
+
+	return user, nil
+}
+
+func (s *Service) Login(ctx context.Context, email, password string) (TokenPair, error) {
+	user, hash, err := s.store.UserByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if errors.Is(err, ErrNotFound) {
+		_ = bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+		return TokenPair{}, ErrInvalidCredentials
+	}
+
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("load user: %w", err)
+	}
+
+	// Accounts registered through an OAuth provider have no password.
+	if hash == "" {
+		_ = bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+		return TokenPair{}, ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return TokenPair{}, ErrInvalidCredentials
+	}
+
+	return s.issue(ctx, user.ID)
+}
+
+// LoginWithIdentity signs in the user linked to an identity from an OAuth
+// provider, registering one on first sign-in. Identities are not linked to
+// existing accounts by email, so that an account registered with someone
+// else's email cannot later be taken over through it; signing in with the
+// email of a password account returns ErrEmailTaken.
+func (s *Service) LoginWithIdentity(ctx context.Context, identity Identity) (TokenPair, error) {
+	user, err := s.store.UserByIdentity(ctx, identity)
+	if errors.Is(err, ErrNotFound) {
+		user, err = s.registerIdentity(ctx, identity)
+	}
+
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return s.issue(ctx, user.ID)
+}
+
+func (s *Service) registerIdentity(ctx context.Context, identity Identity) (User, error) {
+	if !identity.EmailVerified {
+		return User{}, ErrUnverifiedEmail
+	}
+
+	address, err := mail.ParseAddress(identity.Email)
+	if err != nil {
+		return User{}, fmt.Errorf("parse %s email: %w", identity.Provider, err)
+	}
+
+	user, err := s.store.CreateIdentityUser(ctx, identity, strings.ToLower(address.Address))
+	if err != nil {
+		return User{}, err
+	}
+
+	s.logger.InfoContext(ctx, "user registered", "user_id", user.ID, "provider", identity.Provider)
+
+	return user, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. Each refresh token
+// can be used once.
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	c, err := s.signer.verify(refreshToken, refreshTokenType, time.Now())
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	userID, err := subjectID(c)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	revoked, err := s.store.RevokeRefreshToken(ctx, c.ID)
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("revoke refresh token: %w", err)
+	}
+
+	if !revoked {
+		return TokenPair{}, ErrInvalidToken
+	}
+
+	return s.issue(ctx, userID)
+}
+
+// Logout revokes a refresh token. Access tokens stay valid until they expire.
+func (s *Service) Logout(ctx context.Context, refreshToken string) error {
+	c, err := s.signer.verify(refreshToken, refreshTokenType, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.store.RevokeRefreshToken(ctx, c.ID); err != nil {
+		return fmt.Errorf("revoke refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// Authenticate returns the ID of the user an access token was issued to.
+func (s *Service) Authenticate(accessToken string) (int64, error) {
+	c, err := s.signer.verify(accessToken, accessTokenType, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return subjectID(c)
+}
+
+// LinkToken issues a short-lived token that proves its holder is signed in as
+// the user, for linking another channel, such as a Telegram chat, to the
+// account.
+func (s *Service) LinkToken(userID int64) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(linkTokenTTL)
+
+	token, err := s.signer.sign(claims{
+		Subject:   strconv.FormatInt(userID, 10),
+		Type:      linkTokenType,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// VerifyLinkToken returns the ID of the user a link token was issued to.
+func (s *Service) VerifyLinkToken(token string) (int64, error) {
+	c, err := s.signer.verify(token, linkTokenType, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return subjectID(c)
+}
+
+func (s *Service) User(ctx context.Context, id int64) (User, error) {
+	return s.store.UserByID(ctx, id)
+}
+
+func (s *Service) Preferences(ctx context.Context, userID int64) (Preferences, error) {
+	return s.store.Preferences(ctx, userID)
+}
+
+// SavePreferences replaces the user's preferences. The home city must already
+// be resolved to a registered city by the caller. Languages are deduplicated
+// ignoring case.
+func (s *Service) SavePreferences(ctx context.Context, userID int64, prefs Preferences) (Preferences, error) {
+	languages := []string{}
+	for _, language := range prefs.Languages {
+		language = strings.TrimSpace(language)
+		if language == "" {
+			continue
+		}
+
+		if len(language) > maxLanguageLength {
+			return Preferences{}, &InvalidInputError{Field: "languages", Reason: fmt.Sprintf("languages must be at most %d bytes each", maxLanguageLength)}
+		}
+
+		if !slices.ContainsFunc(languages, func(existing string) bool { return strings.EqualFold(existing, language) }) {
+			languages = append(languages, language)
+		}
+	}
+
+	if len(languages) > maxLanguages {
+		return Preferences{}, &InvalidInputError{Field: "languages", Reason: fmt.Sprintf("at most %d languages can be preferred", maxLanguages)}
+	}
+
+	prefs.Languages = languages
+	if err := s.store.SavePreferences(ctx, userID, prefs); err != nil {
+		return Preferences{}, fmt.Errorf("save preferences: %w", err)
+	}
+
+	return prefs, nil
+}
+
+func (s *Service) issue(ctx context.Context, userID int64) (TokenPair, error) {
+	now := time.Now()
+	subject := strconv.FormatInt(userID, 10)
+
+	accessToken, err := s.signer.sign(claims{
+		Subject:   subject,
+		Type:      accessTokenType,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.accessTTL).Unix(),
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	tokenID, err := newTokenID()
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("generate refresh token id: %w", err)
+	}
+
+	refreshExpiresAt := now.Add(s.refreshTTL)
+	refreshToken, err := s.signer.sign(claims{
+		Subject:   subject,
+		Type:      refreshTokenType,
+		ID:        tokenID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: refreshExpiresAt.Unix(),
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	if err := s.store.SaveRefreshToken(ctx, tokenID, userID, refreshExpiresAt); err != nil {
+		return TokenPair{}, fmt.Errorf("save refresh token: %w", err)
+	}
+
+	return TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.accessTTL.Seconds()),
+	}, nil
+}
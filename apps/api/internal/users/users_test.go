@@ -0,0 +1,415 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+type fakeStore struct {
+	mu         sync.Mutex
+	users      map[string]User
+	hashes     map[string]string
+	identities map[Identity]string
+	tokens     map[string]bool
+	prefs      map[int64]Preferences
+	nextUser   int64
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		users:      make(map[string]User),
+		hashes:     make(map[string]string),
+		identities: make(map[Identity]string),
+		tokens:     make(map[string]bool),
+		prefs:      make(map[int64]Preferences),
+	}
+}
+
+func (s *fakeStore) CreateIdentityUser(ctx context.Context, identity Identity, email string) (User, error) {
+	user, err := s.CreateUser(ctx, email, "")
+	if err != nil {
+		return User{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.identities[Identity{Provider: identity.Provider, Subject: identity.Subject}] = email
+
+	return user, nil
+}
+
+func (s *fakeStore) UserByIdentity(_ context.Context, identity Identity) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	email, exists := s.identities[Identity{Provider: identity.Provider, Subject: identity.Subject}]
+	if !exists {
+		return User{}, ErrNotFound
+	}
+
+	return s.users[email], nil
+}
+
+func (s *fakeStore) CreateUser(_ context.Context, email, passwordHash string) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.users[email]; exists {
+		return User{}, ErrEmailTaken
+	}
+
+	s.nextUser++
+	user := User{ID: s.nextUser, Email: email, CreatedAt: time.Now()}
+	s.users[email] = user
+	s.hashes[email] = passwordHash
+
+	return user, nil
+}
+
+func (s *fakeStore) UserByEmail(_ context.Context, email string) (User, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, exists := s.users[email]
+	if !exists {
+		return User{}, "", ErrNotFound
+	}
+
+	return user, s.hashes[email], nil
+}
+
+func (s *fakeStore) UserByID(_ context.Context, id int64) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+
+	return User{}, ErrNotFound
+}
+
+func (s *fakeStore) Preferences(_ context.Context, userID int64) (Preferences, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.prefs[userID], nil
+}
+
+func (s *fakeStore) SavePreferences(_ context.Context, userID int64, prefs Preferences) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prefs[userID] = prefs
+
+	return nil
+}
+
+func (s *fakeStore) SaveRefreshToken(_ context.Context, id string, _ int64, _ time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[id] = true
+
+	return nil
+}
+
+func (s *fakeStore) RevokeRefreshToken(_ context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	active := s.tokens[id]
+	s.tokens[id] = false
+
+	return active, nil
+}
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	service, err := NewService(newFakeStore(), Options{Secret: testSecret}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	return service
+}
+
+func TestNewServiceRejectsShortSecret(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewService(newFakeStore(), Options{Secret: "short"}, slog.New(slog.DiscardHandler)); err == nil {
+		t.Fatal("NewService() error = nil, want error")
+	}
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	service := newTestService(t)
+
+	user, err := service.Register(ctx, "Ana@Example.com", "correct horse")
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if user.Email != "ana@example.com" {
+		t.Fatalf("Register() email = %q, want %q", user.Email, "ana@example.com")
+	}
+
+	if _, err := service.Register(ctx, "ana@example.com", "another password"); !errors.Is(err, ErrEmailTaken) {
+		t.Fatalf("Register() duplicate error = %v, want %v", err, ErrEmailTaken)
+	}
+
+	tokens, err := service.Login(ctx, "ana@example.com", "correct horse")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	userID, err := service.Authenticate(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+
+	if userID != user.ID {
+		t.Fatalf("Authenticate() = %d, want %d", userID, user.ID)
+	}
+
+	if _, err := service.Authenticate(tokens.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Authenticate(refresh token) error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestRegisterRejectsInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	service := newTestService(t)
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+	}{
+		{name: "invalid email", email: "not-an-email", password: "correct horse"},
+		{name: "short password", email: "ana@example.com", password: "short"},
+		{name: "long password", email: "ana@example.com", password: strings.Repeat("a", 73)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var invalid *InvalidInputError
+			if _, err := service.Register(context.Background(), tt.email, tt.password); !errors.As(err, &invalid) {
+				t.Fatalf("Register() error = %v, want InvalidInputError", err)
+			}
+		})
+	}
+}
+
+func TestLoginRejectsWrongCredentials(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	service := newTestService(t)
+
+	if _, err := service.Register(ctx, "ana@example.com", "correct horse"); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if _, err := service.Login(ctx, "ana@example.com", "wrong horse"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Login() wrong password error = %v, want %v", err, ErrInvalidCredentials)
+	}
+
+	if _, err := service.Login(ctx, "bo@example.com", "correct horse"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Login() unknown email error = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	service := newTestService(t)
+
+	if _, err := service.Register(ctx, "ana@example.com", "correct horse"); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	tokens, err := service.Login(ctx, "ana@example.com", "correct horse")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	refreshed, err := service.Refresh(ctx, tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	if _, err := service.Refresh(ctx, tokens.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Refresh() reused token error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	if err := service.Logout(ctx, refreshed.RefreshToken); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+
+	if _, err := service.Refresh(ctx, refreshed.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Refresh() after logout error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	t.Parallel()
+
+	s := signer{secret: []byte(testSecret)}
+	now := time.Now()
+
+	token, err := s.sign(claims{Subject: "1", Type: accessTokenType, IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Minute).Unix()})
+	if err != nil {
+		t.Fatalf("sign() error = %v", err)
+	}
+
+	if _, err := s.verify(token, accessTokenType, now); err != nil {
+		t.Fatalf("verify() error = %v", err)
+	}
+
+	header, rest, _ := strings.Cut(token, ".")
+	_, signature, _ := strings.Cut(rest, ".")
+	forged, _ := (signer{secret: []byte("another secret that is long enough")}).sign(claims{Subject: "2", Type: accessTokenType, ExpiresAt: now.Add(time.Minute).Unix()})
+	_, forgedRest, _ := strings.Cut(forged, ".")
+	forgedPayload, _, _ := strings.Cut(forgedRest, ".")
+
+	tests := []struct {
+		name  string
+		token string
+		typ   string
+		now   time.Time
+	}{
+		{name: "expired", token: token, typ: accessTokenType, now: now.Add(time.Minute)},
+		{name: "wrong type", token: token, typ: refreshTokenType, now: now},
+		{name: "tampered payload", token: header + "." + forgedPayload + "." + signature, typ: accessTokenType, now: now},
+		{name: "wrong secret", token: forged, typ: accessTokenType, now: now},
+		{name: "unsigned", token: `eyJhbGciOiJub25lIn0.` + forgedPayload + ".", typ: accessTokenType, now: now},
+		{name: "malformed", token: "not a token", typ: accessTokenType, now: now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.verify(tt.token, tt.typ, tt.now); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("verify() error = %v, want %v", err, ErrInvalidToken)
+			}
+		})
+	}
+}
+
+func TestLoginWithIdentity(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	service := newTestService(t)
+
+	if _, err := service.Register(ctx, "ana@example.com", "correct horse"); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	identity := Identity{Provider: "google", Subject: "42", Email: "Bo@Example.com", EmailVerified: true}
+
+	first, err := service.LoginWithIdentity(ctx, identity)
+	if err != nil {
+		t.Fatalf("LoginWithIdentity() error = %v", err)
+	}
+
+	second, err := service.LoginWithIdentity(ctx, identity)
+	if err != nil {
+		t.Fatalf("LoginWithIdentity() again error = %v", err)
+	}
+
+	firstID, _ := service.Authenticate(first.AccessToken)
+	secondID, _ := service.Authenticate(second.AccessToken)
+	if firstID == 0 || firstID != secondID {
+		t.Fatalf("LoginWithIdentity() users = %d and %d, want the same user", firstID, secondID)
+	}
+
+	if _, err := service.Login(ctx, "bo@example.com", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Login() without password error = %v, want %v", err, ErrInvalidCredentials)
+	}
+
+	taken := Identity{Provider: "google", Subject: "43", Email: "ana@example.com", EmailVerified: true}
+	if _, err := service.LoginWithIdentity(ctx, taken); !errors.Is(err, ErrEmailTaken) {
+		t.Fatalf("LoginWithIdentity() with registered email error = %v, want %v", err, ErrEmailTaken)
+	}
+
+	unverified := Identity{Provider: "google", Subject: "44", Email: "cy@example.com"}
+	if _, err := service.LoginWithIdentity(ctx, unverified); !errors.Is(err, ErrUnverifiedEmail) {
+		t.Fatalf("LoginWithIdentity() with unverified email error = %v, want %v", err, ErrUnverifiedEmail)
+	}
+}
+
+func TestLinkToken(t *testing.T) {
+	t.Parallel()
+
+	service := newTestService(t)
+
+	token, _, err := service.LinkToken(7)
+	if err != nil {
+		t.Fatalf("LinkToken() error = %v", err)
+	}
+
+	if userID, err := service.VerifyLinkToken(token); err != nil || userID != 7 {
+		t.Fatalf("VerifyLinkToken() = %d, %v, want 7", userID, err)
+	}
+
+	if _, err := service.Authenticate(token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Authenticate(link token) error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestSavePreferences(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	service := newTestService(t)
+
+	saved, err := service.SavePreferences(ctx, 1, Preferences{
+		HomeCity:  "mumbai",
+		Languages: []string{" Hindi ", "", "hindi", "English"},
+	})
+	if err != nil {
+		t.Fatalf("SavePreferences() error = %v", err)
+	}
+
+	if want := []string{"Hindi", "English"}; !slices.Equal(saved.Languages, want) {
+		t.Fatalf("SavePreferences() languages = %v, want %v", saved.Languages, want)
+	}
+
+	prefs, err := service.Preferences(ctx, 1)
+	if err != nil || prefs.HomeCity != "mumbai" || !slices.Equal(prefs.Languages, saved.Languages) {
+		t.Fatalf("Preferences() = %+v, %v, want %+v", prefs, err, saved)
+	}
+
+	tooLong := Preferences{Languages: []string{strings.Repeat("a", maxLanguageLength+1)}}
+	var invalid *InvalidInputError
+	if _, err := service.SavePreferences(ctx, 1, tooLong); !errors.As(err, &invalid) {
+		t.Fatalf("SavePreferences() with long language error = %v, want InvalidInputError", err)
+	}
+
+	tooMany := Preferences{}
+	for i := range maxLanguages + 1 {
+		tooMany.Languages = append(tooMany.Languages, fmt.Sprintf("language-%d", i))
+	}
+	if _, err := service.SavePreferences(ctx, 1, tooMany); !errors.As(err, &invalid) {
+		t.Fatalf("SavePreferences() with too many languages error = %v, want InvalidInputError", err)
+	}
+}
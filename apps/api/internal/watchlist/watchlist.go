@@ -0,0 +1,149 @@
+// Package watchlist keeps the movies each user wants to see and reports which
+// of them are showing in a city.
+package watchlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-scraping/internal/movies"
+)
+
+const (
+	maxTitleLength = 200
+
+	// MaxItems caps the size of a user's watchlist.
+	MaxItems = 500
+)
+
+var (
+	ErrInvalidItem = errors.New("set either a title or a movie ID such as ET00403839 as external_id")
+	ErrFull        = fmt.Errorf("a watchlist can hold at most %d movies", MaxItems)
+)
+
+// Item is a movie on a watchlist, identified by either its title or its movie
+// ID, usually its BookMyShow event code.
+type Item struct {
+	ID         int64     `json:"id"`
+	Title      string    `json:"title,omitempty"`
+	ExternalID string    `json:"external_id,omitempty"`
+	AddedAt    time.Time `json:"added_at"`
+}
+
+// Key identifies the movie an item refers to, so that adding the same movie
+// twice keeps one item.
+func (i Item) Key() string {
+	if i.ExternalID != "" {
+		return "id:" + i.ExternalID
+	}
+
+	return "title:" + movies.AliasKey(i.Title)
+}
+
+// Entry is an item with its showing status in a city.
+type Entry struct {
+	Item
+
+	InTheatersNearYou bool   `json:"in_theaters_near_you"`
+	URL               string `json:"url,omitempty"`
+}
+
+type Store interface {
+	// Add saves the item unless the user already has one with the same key,
+	// returning the saved item.
+	Add(ctx context.Context, userID int64, item Item) (Item, error)
+	List(ctx context.Context, userID int64) ([]Item, error)
+	Count(ctx context.Context, userID int64) (int, error)
+	Remove(ctx context.Context, userID, itemID int64) (bool, error)
+}
+
+type movieLoader interface {
+	Load(ctx context.Context, city string) ([]movies.Movie, bool, error)
+}
+
+type Watchlist struct {
+	store  Store
+	movies movieLoader
+	logger *slog.Logger
+}
+
+func New(store Store, movies movieLoader, logger *slog.Logger) *Watchlist {
+	return &Watchlist{
+		store:  store,
+		movies: movies,
+		logger: logger,
+	}
+}
+
+func (w *Watchlist) Add(ctx context.Context, userID int64, title, externalID string) (Item, error) {
+	item := Item{Title: strings.TrimSpace(title)}
+	externalID, validID := movies.ParseID(externalID)
+	item.ExternalID = externalID
+
+	switch {
+	case (item.Title == "") == (item.ExternalID == ""):
+		return Item{}, ErrInvalidItem
+	case item.ExternalID != "" && !validID:
+		return Item{}, ErrInvalidItem
+	case utf8.RuneCountInString(item.Title) > maxTitleLength:
+		return Item{}, ErrInvalidItem
+	}
+
+	count, err := w.store.Count(ctx, userID)
+	if err != nil {
+		return Item{}, fmt.Errorf("count watchlist items: %w", err)
+	}
+
+	if count >= MaxItems {
+		return Item{}, ErrFull
+	}
+
+	return w.store.Add(ctx, userID, item)
+}
+
+func (w *Watchlist) Remove(ctx context.Context, userID, itemID int64) (bool, error) {
+	return w.store.Remove(ctx, userID, itemID)
+}
+
+// List returns the user's watchlist, newest first, marking the movies that are
+// showing in the city.
+func (w *Watchlist) List(ctx context.Context, userID int64, city string) ([]Entry, error) {
+	items, err := w.store.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list watchlist items: %w", err)
+	}
+
+	entries := make([]Entry, len(items))
+	for i, item := range items {
+		entries[i] = Entry{Item: item}
+	}
+
+	if len(items) == 0 {
+		return entries, nil
+	}
+
+	showing, _, err := w.movies.Load(ctx, city)
+	if err != nil {
+		return nil, fmt.Errorf("load movies: %w", err)
+	}
+
+	byKey := make(map[string]movies.Movie, 2*len(showing))
+	for _, movie := range showing {
+		byKey[Item{Title: movie.Title}.Key()] = movie
+		byKey[Item{ExternalID: movie.ID()}.Key()] = movie
+	}
+
+	for i := range entries {
+		if movie, ok := byKey[entries[i].Key()]; ok {
+			entries[i].InTheatersNearYou = true
+			entries[i].URL = movie.Href
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,171 @@
+package watchlist
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"go-scraping/internal/movies"
+)
+
+type fakeStore struct {
+	mu     sync.Mutex
+	items  map[int64][]Item
+	nextID int64
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{items: make(map[int64][]Item)}
+}
+
+func (s *fakeStore) Add(_ context.Context, userID int64, item Item) (Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.items[userID] {
+		if existing.Key() == item.Key() {
+			return existing, nil
+		}
+	}
+
+	s.nextID++
+	item.ID = s.nextID
+	item.AddedAt = time.Now()
+	s.items[userID] = slices.Insert(s.items[userID], 0, item)
+
+	return item, nil
+}
+
+func (s *fakeStore) List(_ context.Context, userID int64) ([]Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.items[userID]), nil
+}
+
+func (s *fakeStore) Count(_ context.Context, userID int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.items[userID]), nil
+}
+
+func (s *fakeStore) Remove(_ context.Context, userID, itemID int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.items[userID])
+	s.items[userID] = slices.DeleteFunc(s.items[userID], func(item Item) bool { return item.ID == itemID })
+
+	return len(s.items[userID]) < before, nil
+}
+
+type fakeLoader map[string][]movies.Movie
+
+func (f fakeLoader) Load(_ context.Context, city string) ([]movies.Movie, bool, error) {
+	return f[city], true, nil
+}
+
+func TestListMarksMoviesShowingInCity(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	loader := fakeLoader{
+		"bhubaneswar": {
+			{Title: "F1: The Movie", Href: "https://in.bookmyshow.com/movies/bhubaneswar/f1-the-movie/ET00403839"},
+			{Title: "Superman", Href: "https://in.bookmyshow.com/movies/bhubaneswar/superman/ET00414210"},
+			{Title: "Daskalia", Href: "https://in.bookmyshow.com/movies/bhubaneswar/daskalia"},
+		},
+	}
+	list := New(newFakeStore(), loader, slog.New(slog.DiscardHandler))
+
+	// Movies without an event code are identified by a hash of their link.
+	daskalia := loader["bhubaneswar"][2].ID()
+
+	for _, add := range []struct{ title, externalID string }{
+		{title: "superman"},
+		{externalID: "et00403839"},
+		{title: "Jurassic World Rebirth"},
+		{externalID: daskalia},
+	} {
+		if _, err := list.Add(ctx, 1, add.title, add.externalID); err != nil {
+			t.Fatalf("Add(%q, %q) error = %v", add.title, add.externalID, err)
+		}
+	}
+
+	entries, err := list.List(ctx, 1, "bhubaneswar")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	got := make(map[string]bool)
+	for _, entry := range entries {
+		got[entry.Title+entry.ExternalID] = entry.InTheatersNearYou
+	}
+
+	want := map[string]bool{"superman": true, "ET00403839": true, "Jurassic World Rebirth": false, daskalia: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("List() showing = %v, want %v", got, want)
+	}
+
+	entries, err = list.List(ctx, 1, "cuttack")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	for _, entry := range entries {
+		if entry.InTheatersNearYou {
+			t.Fatalf("List() in another city marked %+v as showing", entry)
+		}
+	}
+}
+
+func TestAddKeepsOneItemPerMovie(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	list := New(newFakeStore(), fakeLoader{}, slog.New(slog.DiscardHandler))
+
+	first, err := list.Add(ctx, 1, "Superman", "")
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	second, err := list.Add(ctx, 1, "  SUPERMAN ", "")
+	if err != nil {
+		t.Fatalf("Add() again error = %v", err)
+	}
+
+	if first.ID != second.ID {
+		t.Fatalf("Add() again = item %d, want existing item %d", second.ID, first.ID)
+	}
+}
+
+func TestAddRejectsInvalidItems(t *testing.T) {
+	t.Parallel()
+
+	list := New(newFakeStore(), fakeLoader{}, slog.New(slog.DiscardHandler))
+
+	tests := []struct {
+		name       string
+		title      string
+		externalID string
+	}{
+		{name: "empty"},
+		{name: "both", title: "Superman", externalID: "ET00414210"},
+		{name: "unknown id format", externalID: "tt0111161"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := list.Add(context.Background(), 1, tt.title, tt.externalID); !errors.Is(err, ErrInvalidItem) {
+				t.Fatalf("Add(%q, %q) error = %v, want %v", tt.title, tt.externalID, err, ErrInvalidItem)
+			}
+		})
+	}
+}
@@ -0,0 +1,439 @@
+package web
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-scraping/internal/movies"
+)
+
+type adminService interface {
+	Stats(ctx context.Context) (movies.CacheStats, error)
+	RecentScrapes() []movies.ScrapeRun
+	SearchSummary(ctx context.Context, city string, since time.Time, limit int) (movies.SearchSummary, error)
+	ExportSearches(ctx context.Context, city string, since time.Time, fn func(movies.SearchEvent) error) error
+	TrafficSummary(ctx context.Context, since time.Time) (movies.TrafficSummary, error)
+	ListAliases(ctx context.Context) ([]movies.Alias, error)
+	AddAlias(ctx context.Context, alias, canonical string) (movies.Alias, error)
+	RemoveAlias(ctx context.Context, alias string) (bool, error)
+	ListTitleVariants(ctx context.Context) ([]movies.TitleVariant, error)
+	AddTitleVariant(ctx context.Context, movieID, language, title string) (movies.TitleVariant, error)
+	RemoveTitleVariant(ctx context.Context, movieID, language string) (bool, error)
+	PauseScraping()
+	ResumeScraping()
+	ScrapingPaused() bool
+	ListQuarantined(ctx context.Context) ([]movies.QuarantinedScrape, error)
+	PublishQuarantined(ctx context.Context, city string) (bool, error)
+	DiscardQuarantined(ctx context.Context, city string) (bool, error)
+}
+
+type searchStatsParams struct {
+	City  string `query:"city"`
+	Days  int    `query:"days" default:"7" validate:"min=1,max=365"`
+	Limit int    `query:"limit" default:"20" validate:"min=1,max=100"`
+}
+
+type trafficParams struct {
+	Days int `query:"days" default:"7" validate:"min=1,max=365"`
+}
+
+type searchExportParams struct {
+	City   string `query:"city"`
+	Format string `query:"format" default:"csv" validate:"oneof=csv ndjson"`
+	Days   int    `query:"days" default:"7" validate:"min=1,max=365"`
+}
+
+type aliasRequest struct {
+	Alias     string `json:"alias" validate:"required,text"`
+	Canonical string `json:"canonical" validate:"required,text"`
+}
+
+type titleVariantRequest struct {
+	MovieID  string `json:"movie_id" validate:"required,text"`
+	Language string `json:"language" validate:"required,text"`
+	Title    string `json:"title" validate:"required,text"`
+}
+
+// exportFlushRows is how many exported rows are buffered before they are
+// flushed to the client.
+const exportFlushRows = 500
+
+type AdminHandler struct {
+	service adminService
+	logger  *slog.Logger
+}
+
+func RegisterAdminRoutes(mux *http.ServeMux, service adminService, logger *slog.Logger) {
+	handler := &AdminHandler{
+		service: service,
+		logger:  logger,
+	}
+
+	mux.Handle("GET /admin/cache/stats", http.HandlerFunc(handler.GetCacheStats))
+	mux.Handle("GET /admin/scrapes", http.HandlerFunc(handler.ListScrapes))
+	mux.Handle("GET /admin/search/stats", http.HandlerFunc(handler.GetSearchStats))
+	mux.Handle("GET /admin/search/export", http.HandlerFunc(handler.ExportSearches))
+	mux.Handle("GET /admin/traffic", http.HandlerFunc(handler.GetTraffic))
+	mux.Handle("GET /admin/aliases", http.HandlerFunc(handler.ListAliases))
+	mux.Handle("POST /admin/aliases", http.HandlerFunc(handler.AddAlias))
+	mux.Handle("DELETE /admin/aliases/{alias}", http.HandlerFunc(handler.RemoveAlias))
+	mux.Handle("GET /admin/title-variants", http.HandlerFunc(handler.ListTitleVariants))
+	mux.Handle("POST /admin/title-variants", http.HandlerFunc(handler.AddTitleVariant))
+	mux.Handle("DELETE /admin/title-variants/{movieID}/{language}", http.HandlerFunc(handler.RemoveTitleVariant))
+	mux.Handle("GET /admin/scraping", http.HandlerFunc(handler.GetScrapingState))
+	mux.Handle("POST /admin/scraping/pause", http.HandlerFunc(handler.PauseScraping))
+	mux.Handle("POST /admin/scraping/resume", http.HandlerFunc(handler.ResumeScraping))
+	mux.Handle("GET /admin/quarantine", http.HandlerFunc(handler.ListQuarantined))
+	mux.Handle("POST /admin/quarantine/{city}/publish", http.HandlerFunc(handler.PublishQuarantined))
+	mux.Handle("DELETE /admin/quarantine/{city}", http.HandlerFunc(handler.DiscardQuarantined))
+}
+
+func (h *AdminHandler) GetCacheStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.service.Stats(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to load cache stats", "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to load cache stats")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, stats)
+}
+
+func (h *AdminHandler) ListScrapes(w http.ResponseWriter, _ *http.Request) {
+	runs := h.service.RecentScrapes()
+
+	WriteJSON(w, http.StatusOK, map[string]any{
+		"scrapes": runs,
+		"count":   len(runs),
+	})
+}
+
+func (h *AdminHandler) GetSearchStats(w http.ResponseWriter, r *http.Request) {
+	var params searchStatsParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -params.Days)
+
+	summary, err := h.service.SearchSummary(r.Context(), params.City, since, params.Limit)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to load search stats", "error", err)
+		WriteServiceError(w, err, "Failed to load search stats")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, summary)
+}
+
+// GetTraffic summarizes the requests of the last days UTC days, including
+// today, per city and day, to show which cities deserve scheduled refreshes.
+func (h *AdminHandler) GetTraffic(w http.ResponseWriter, r *http.Request) {
+	var params trafficParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-params.Days)
+
+	summary, err := h.service.TrafficSummary(r.Context(), since)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to load traffic summary", "error", err)
+		WriteServiceError(w, err, "Failed to load traffic")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, summary)
+}
+
+// ExportSearches streams the search log as CSV or newline-delimited JSON,
+// writing rows as they are read from the database. Errors before the first row
+// get a normal error response; later ones abort the response so the client
+// sees a truncated download as a failure.
+func (h *AdminHandler) ExportSearches(w http.ResponseWriter, r *http.Request) {
+	var params searchExportParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -params.Days)
+
+	var (
+		csvWriter *csv.Writer
+		encoder   *json.Encoder
+		started   bool
+		rows      int
+	)
+	controller := http.NewResponseController(w)
+
+	start := func() error {
+		started = true
+
+		if params.Format == "ndjson" {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			encoder = json.NewEncoder(w)
+			encoder.SetEscapeHTML(false)
+			return nil
+		}
+
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="searches.csv"`)
+		csvWriter = csv.NewWriter(w)
+
+		return csvWriter.Write(movies.SearchEventColumns)
+	}
+
+	flush := func() error {
+		if csvWriter != nil {
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return err
+			}
+		}
+
+		if err := controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+
+		return nil
+	}
+
+	err := h.service.ExportSearches(r.Context(), params.City, since, func(event movies.SearchEvent) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		if encoder != nil {
+			if err := encoder.Encode(event); err != nil {
+				return err
+			}
+		} else if err := csvWriter.Write(event.Record()); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%exportFlushRows == 0 {
+			return flush()
+		}
+
+		return nil
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = flush()
+	}
+
+	switch {
+	case err == nil:
+	case !started:
+		h.logger.ErrorContext(r.Context(), "failed to export searches", "error", err)
+		WriteServiceError(w, err, "Failed to export searches")
+	case r.Context().Err() != nil:
+		h.logger.InfoContext(r.Context(), "search export cancelled", "rows", rows)
+	default:
+		h.logger.ErrorContext(r.Context(), "failed to export searches", "rows", rows, "error", err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func (h *AdminHandler) ListAliases(w http.ResponseWriter, r *http.Request) {
+	aliases, err := h.service.ListAliases(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list aliases", "error", err)
+		WriteServiceError(w, err, "Failed to list aliases")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]any{
+		"aliases": aliases,
+		"count":   len(aliases),
+	})
+}
+
+func (h *AdminHandler) AddAlias(w http.ResponseWriter, r *http.Request) {
+	var req aliasRequest
+	if err := bindJSON(w, r, &req); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	if movies.AliasKey(req.Alias) == "" {
+		writeBindError(w, invalidField("alias", "alias must contain letters or digits"))
+		return
+	}
+
+	if movies.NormalizeQuery(req.Canonical) == "" {
+		writeBindError(w, invalidField("canonical", "canonical must contain letters or digits"))
+		return
+	}
+
+	alias, err := h.service.AddAlias(r.Context(), req.Alias, req.Canonical)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to add alias", "alias", req.Alias, "error", err)
+		WriteServiceError(w, err, "Failed to add alias")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, alias)
+}
+
+func (h *AdminHandler) RemoveAlias(w http.ResponseWriter, r *http.Request) {
+	alias := r.PathValue("alias")
+
+	removed, err := h.service.RemoveAlias(r.Context(), alias)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to remove alias", "alias", alias, "error", err)
+		WriteServiceError(w, err, "Failed to remove alias")
+		return
+	}
+
+	if !removed {
+		WriteError(w, http.StatusNotFound, "Alias not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandler) ListTitleVariants(w http.ResponseWriter, r *http.Request) {
+	variants, err := h.service.ListTitleVariants(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list title variants", "error", err)
+		WriteServiceError(w, err, "Failed to list title variants")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]any{
+		"title_variants": variants,
+		"count":          len(variants),
+	})
+}
+
+// AddTitleVariant sets a movie's title in a language, by the movie's id from
+// movie responses.
+func (h *AdminHandler) AddTitleVariant(w http.ResponseWriter, r *http.Request) {
+	var req titleVariantRequest
+	if err := bindJSON(w, r, &req); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	if movies.NormalizeQuery(req.Title) == "" {
+		writeBindError(w, invalidField("title", "title must contain letters or digits"))
+		return
+	}
+
+	variant, err := h.service.AddTitleVariant(r.Context(), req.MovieID, req.Language, req.Title)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to add title variant", "movie_id", req.MovieID, "error", err)
+		WriteServiceError(w, err, "Failed to add title variant")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, variant)
+}
+
+func (h *AdminHandler) RemoveTitleVariant(w http.ResponseWriter, r *http.Request) {
+	movieID := r.PathValue("movieID")
+
+	removed, err := h.service.RemoveTitleVariant(r.Context(), movieID, r.PathValue("language"))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to remove title variant", "movie_id", movieID, "error", err)
+		WriteServiceError(w, err, "Failed to remove title variant")
+		return
+	}
+
+	if !removed {
+		WriteError(w, http.StatusNotFound, "Title variant not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandler) GetScrapingState(w http.ResponseWriter, _ *http.Request) {
+	h.writeScrapingState(w)
+}
+
+func (h *AdminHandler) PauseScraping(w http.ResponseWriter, _ *http.Request) {
+	h.service.PauseScraping()
+	h.writeScrapingState(w)
+}
+
+func (h *AdminHandler) ResumeScraping(w http.ResponseWriter, _ *http.Request) {
+	h.service.ResumeScraping()
+	h.writeScrapingState(w)
+}
+
+func (h *AdminHandler) writeScrapingState(w http.ResponseWriter) {
+	WriteJSON(w, http.StatusOK, map[string]bool{"paused": h.service.ScrapingPaused()})
+}
+
+// ListQuarantined lists the scrapes held back by the quality checks, newest
+// first, with what was wrong with each.
+func (h *AdminHandler) ListQuarantined(w http.ResponseWriter, r *http.Request) {
+	scrapes, err := h.service.ListQuarantined(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list quarantined scrapes", "error", err)
+		WriteServiceError(w, err, "Failed to list quarantined scrapes")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]any{
+		"scrapes": scrapes,
+		"count":   len(scrapes),
+	})
+}
+
+// PublishQuarantined saves a city's quarantined scrape as its listings, for
+// scrapes an admin judged to be right after all.
+func (h *AdminHandler) PublishQuarantined(w http.ResponseWriter, r *http.Request) {
+	city := strings.ToLower(r.PathValue("city"))
+
+	published, err := h.service.PublishQuarantined(r.Context(), city)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to publish quarantined scrape", "city", city, "error", err)
+		WriteServiceError(w, err, "Failed to publish quarantined scrape")
+		return
+	}
+
+	if !published {
+		WriteError(w, http.StatusNotFound, "No quarantined scrape for this city")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DiscardQuarantined drops a city's quarantined scrape, so that the city is
+// scraped again on its next load.
+func (h *AdminHandler) DiscardQuarantined(w http.ResponseWriter, r *http.Request) {
+	city := strings.ToLower(r.PathValue("city"))
+
+	discarded, err := h.service.DiscardQuarantined(r.Context(), city)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to discard quarantined scrape", "city", city, "error", err)
+		WriteServiceError(w, err, "Failed to discard quarantined scrape")
+		return
+	}
+
+	if !discarded {
+		WriteError(w, http.StatusNotFound, "No quarantined scrape for this city")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
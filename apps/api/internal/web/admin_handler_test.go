@@ -0,0 +1,499 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-scraping/internal/movies"
+)
+
+type fakeAdminService struct {
+	stats movies.CacheStats
+	err   error
+
+	searchSummary movies.SearchSummary
+	summaryCity   string
+	summarySince  time.Time
+	summaryLimit  int
+
+	searchEvents []movies.SearchEvent
+	exportCity   string
+
+	aliases      []movies.Alias
+	removeResult bool
+
+	paused  bool
+	scrapes []movies.ScrapeRun
+
+	variants []movies.TitleVariant
+
+	citySettings []movies.CitySettings
+
+	traffic      movies.TrafficSummary
+	trafficSince time.Time
+
+	quarantined []movies.QuarantinedScrape
+	published   string
+}
+
+func (f *fakeAdminService) Stats(_ context.Context) (movies.CacheStats, error) {
+	return f.stats, f.err
+}
+
+func (f *fakeAdminService) SearchSummary(_ context.Context, city string, since time.Time, limit int) (movies.SearchSummary, error) {
+	f.summaryCity = city
+	f.summarySince = since
+	f.summaryLimit = limit
+
+	return f.searchSummary, f.err
+}
+
+func (f *fakeAdminService) TrafficSummary(_ context.Context, since time.Time) (movies.TrafficSummary, error) {
+	f.trafficSince = since
+	f.traffic.Since = since
+
+	return f.traffic, f.err
+}
+
+func (f *fakeAdminService) ExportSearches(_ context.Context, city string, _ time.Time, fn func(movies.SearchEvent) error) error {
+	f.exportCity = city
+
+	if f.err != nil {
+		return f.err
+	}
+
+	for _, event := range f.searchEvents {
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *fakeAdminService) ListAliases(_ context.Context) ([]movies.Alias, error) {
+	return f.aliases, f.err
+}
+
+func (f *fakeAdminService) AddAlias(_ context.Context, alias, canonical string) (movies.Alias, error) {
+	entry := movies.Alias{Alias: movies.AliasKey(alias), Canonical: canonical}
+	f.aliases = append(f.aliases, entry)
+
+	return entry, f.err
+}
+
+func (f *fakeAdminService) RemoveAlias(_ context.Context, _ string) (bool, error) {
+	return f.removeResult, f.err
+}
+
+func (f *fakeAdminService) ListTitleVariants(_ context.Context) ([]movies.TitleVariant, error) {
+	return f.variants, f.err
+}
+
+func (f *fakeAdminService) AddTitleVariant(_ context.Context, movieID, language, title string) (movies.TitleVariant, error) {
+	if f.err != nil {
+		return movies.TitleVariant{}, f.err
+	}
+
+	variant := movies.TitleVariant{MovieID: movieID, Language: language, Title: title}
+	f.variants = append(f.variants, variant)
+
+	return variant, nil
+}
+
+func (f *fakeAdminService) RemoveTitleVariant(_ context.Context, _, _ string) (bool, error) {
+	return f.removeResult, f.err
+}
+
+func (f *fakeAdminService) ListCitySettings(_ context.Context) ([]movies.CitySettings, error) {
+	return f.citySettings, f.err
+}
+
+func (f *fakeAdminService) UpdateCitySettings(_ context.Context, settings movies.CitySettings) (movies.CitySettings, error) {
+	if f.err != nil {
+		return movies.CitySettings{}, f.err
+	}
+
+	f.citySettings = append(f.citySettings, settings)
+
+	return settings, nil
+}
+
+func (f *fakeAdminService) RecentScrapes() []movies.ScrapeRun {
+	return f.scrapes
+}
+
+func (f *fakeAdminService) PauseScraping() {
+	f.paused = true
+}
+
+func (f *fakeAdminService) ResumeScraping() {
+	f.paused = false
+}
+
+func (f *fakeAdminService) ScrapingPaused() bool {
+	return f.paused
+}
+
+func (f *fakeAdminService) ListQuarantined(_ context.Context) ([]movies.QuarantinedScrape, error) {
+	return f.quarantined, f.err
+}
+
+func (f *fakeAdminService) PublishQuarantined(_ context.Context, city string) (bool, error) {
+	for _, scrape := range f.quarantined {
+		if scrape.City == city {
+			f.published = city
+			return true, nil
+		}
+	}
+
+	return false, f.err
+}
+
+func (f *fakeAdminService) DiscardQuarantined(_ context.Context, _ string) (bool, error) {
+	return false, f.err
+}
+
+func testAdminHandler(t *testing.T, service adminService) http.Handler {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	RegisterAdminRoutes(mux, service, slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestGetCacheStatsReturnsStats(t *testing.T) {
+	t.Parallel()
+
+	provider := &fakeAdminService{
+		stats: movies.CacheStats{
+			Hits:   4,
+			Misses: 1,
+			Cities: []movies.CityCacheStats{{City: "cuttack", Hits: 4, Misses: 1, MovieCount: 12}},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/cache/stats", nil)
+	recorder := httptest.NewRecorder()
+
+	testAdminHandler(t, provider).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	var payload movies.CacheStats
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if payload.Hits != 4 || payload.Misses != 1 {
+		t.Fatalf("hits/misses = %d/%d, want 4/1", payload.Hits, payload.Misses)
+	}
+
+	if len(payload.Cities) != 1 || payload.Cities[0].MovieCount != 12 {
+		t.Fatalf("cities = %+v, want cuttack with 12 movies", payload.Cities)
+	}
+}
+
+func TestGetCacheStatsReturnsErrorPayload(t *testing.T) {
+	t.Parallel()
+
+	provider := &fakeAdminService{err: errors.New("db down")}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/cache/stats", nil)
+	recorder := httptest.NewRecorder()
+
+	testAdminHandler(t, provider).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestGetSearchStatsReturnsSummary(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeAdminService{
+		searchSummary: movies.SearchSummary{
+			City:                 "cuttack",
+			TopQueries:           []movies.QueryCount{{Query: "pushpa", Count: 12, AvgResults: 1}},
+			TopZeroResultQueries: []movies.QueryCount{{Query: "httyd", Count: 4}},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/search/stats?city=cuttack&days=3&limit=5", nil)
+	recorder := httptest.NewRecorder()
+
+	testAdminHandler(t, service).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	if service.summaryCity != "cuttack" || service.summaryLimit != 5 {
+		t.Fatalf("SearchSummary() args = %q, %d, want cuttack, 5", service.summaryCity, service.summaryLimit)
+	}
+
+	if age := time.Since(service.summarySince); age < 71*time.Hour || age > 73*time.Hour {
+		t.Fatalf("SearchSummary() since = %s ago, want about 3 days", age)
+	}
+
+	var payload movies.SearchSummary
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if len(payload.TopZeroResultQueries) != 1 || payload.TopZeroResultQueries[0].Query != "httyd" {
+		t.Fatalf("zero-result queries = %+v, want httyd", payload.TopZeroResultQueries)
+	}
+}
+
+func TestGetSearchStatsRejectsInvalidDays(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/search/stats?days=0", nil)
+	recorder := httptest.NewRecorder()
+
+	testAdminHandler(t, &fakeAdminService{}).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetTrafficSummarizesWholeDays(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeAdminService{
+		traffic: movies.TrafficSummary{
+			Days: []movies.TrafficDay{{Date: "2025-07-14", City: "cuttack", Requests: 120, CacheHits: 110, CacheMisses: 2}},
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	testAdminHandler(t, service).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/traffic?days=3", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	wantSince := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -2)
+	if !service.trafficSince.Equal(wantSince) {
+		t.Fatalf("TrafficSummary() since = %s, want %s", service.trafficSince, wantSince)
+	}
+
+	var payload movies.TrafficSummary
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if len(payload.Days) != 1 || payload.Days[0].City != "cuttack" || payload.Days[0].CacheHits != 110 {
+		t.Fatalf("days = %+v, want cuttack's traffic", payload.Days)
+	}
+}
+
+func TestAddAliasStoresAlias(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeAdminService{}
+	body := strings.NewReader(`{"alias": "HTTYD", "canonical": "How to Train Your Dragon"}`)
+	req := httptest.NewRequest(http.MethodPost, "/admin/aliases", body)
+	recorder := httptest.NewRecorder()
+
+	testAdminHandler(t, service).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusCreated)
+	}
+
+	if len(service.aliases) != 1 || service.aliases[0].Alias != "httyd" {
+		t.Fatalf("aliases = %+v, want httyd alias", service.aliases)
+	}
+}
+
+func TestAddAliasRejectsMissingFields(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/aliases", strings.NewReader(`{"alias": "HTTYD"}`))
+	recorder := httptest.NewRecorder()
+
+	testAdminHandler(t, &fakeAdminService{}).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestRemoveAliasReturnsNotFound(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodDelete, "/admin/aliases/httyd", nil)
+	recorder := httptest.NewRecorder()
+
+	testAdminHandler(t, &fakeAdminService{}).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestPauseAndResumeScraping(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeAdminService{}
+	handler := testAdminHandler(t, service)
+
+	for _, tc := range []struct {
+		path       string
+		wantPaused bool
+	}{
+		{path: "/admin/scraping/pause", wantPaused: true},
+		{path: "/admin/scraping/resume", wantPaused: false},
+	} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, tc.path, nil))
+
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("POST %s status = %d, want %d", tc.path, recorder.Code, http.StatusOK)
+		}
+
+		var payload map[string]bool
+		if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+
+		if payload["paused"] != tc.wantPaused || service.paused != tc.wantPaused {
+			t.Fatalf("POST %s paused = %v, want %v", tc.path, payload["paused"], tc.wantPaused)
+		}
+	}
+}
+
+func TestQuarantinedScrapesAreListedAndPublished(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeAdminService{quarantined: []movies.QuarantinedScrape{{
+		City:          "cuttack",
+		Movies:        []movies.Movie{{Title: "Superman"}},
+		PreviousCount: 12,
+		Problems:      []string{"movie count dropped by 92%, from 12 to 1"},
+	}}}
+	handler := testAdminHandler(t, service)
+
+	list := httptest.NewRecorder()
+	handler.ServeHTTP(list, httptest.NewRequest(http.MethodGet, "/admin/quarantine", nil))
+	if list.Code != http.StatusOK || !strings.Contains(list.Body.String(), `"previous_count":12`) {
+		t.Fatalf("GET /admin/quarantine = %d %s, want the quarantined scrape", list.Code, list.Body)
+	}
+
+	publish := httptest.NewRecorder()
+	handler.ServeHTTP(publish, httptest.NewRequest(http.MethodPost, "/admin/quarantine/Cuttack/publish", nil))
+	if publish.Code != http.StatusNoContent || service.published != "cuttack" {
+		t.Fatalf("publish = %d for %q, want 204 for cuttack", publish.Code, service.published)
+	}
+
+	discard := httptest.NewRecorder()
+	handler.ServeHTTP(discard, httptest.NewRequest(http.MethodDelete, "/admin/quarantine/puri", nil))
+	if discard.Code != http.StatusNotFound {
+		t.Fatalf("discard = %d, want 404 without a quarantined scrape", discard.Code)
+	}
+}
+
+func TestExportSearchesStreamsRows(t *testing.T) {
+	t.Parallel()
+
+	searchedAt := time.Date(2025, 7, 14, 10, 0, 0, 0, time.UTC)
+	service := &fakeAdminService{
+		searchEvents: []movies.SearchEvent{
+			{City: "cuttack", RawQuery: "Superman, 2025", NormalizedQuery: "superman 2025", ResultCount: 1, SearchedAt: searchedAt, TopTitle: "Superman"},
+			{City: "cuttack", RawQuery: "kalky", NormalizedQuery: "kalky", SearchedAt: searchedAt},
+		},
+	}
+	handler := testAdminHandler(t, service)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/search/export?city=cuttack", nil))
+
+	wantCSV := "searched_at,city,raw_query,normalized_query,result_count,top_title\n" +
+		"2025-07-14T10:00:00Z,cuttack,\"Superman, 2025\",superman 2025,1,Superman\n" +
+		"2025-07-14T10:00:00Z,cuttack,kalky,kalky,0,\n"
+	if recorder.Code != http.StatusOK || recorder.Body.String() != wantCSV {
+		t.Fatalf("CSV export = %d %q, want %q", recorder.Code, recorder.Body.String(), wantCSV)
+	}
+
+	if service.exportCity != "cuttack" {
+		t.Fatalf("ExportSearches() city = %q, want cuttack", service.exportCity)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/search/export?format=ndjson", nil))
+
+	lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+	if recorder.Header().Get("Content-Type") != "application/x-ndjson" || len(lines) != 2 {
+		t.Fatalf("NDJSON export = %q, want 2 lines", recorder.Body.String())
+	}
+
+	var event movies.SearchEvent
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil || event.RawQuery != "kalky" {
+		t.Fatalf("NDJSON line = %q, %v, want kalky event", lines[1], err)
+	}
+}
+
+func TestExportSearchesReportsErrorsBeforeStreaming(t *testing.T) {
+	t.Parallel()
+
+	handler := testAdminHandler(t, &fakeAdminService{err: movies.ErrSearchLogDisabled})
+
+	for target, wantStatus := range map[string]int{
+		"/admin/search/export":             http.StatusNotFound,
+		"/admin/search/export?format=xlsx": http.StatusBadRequest,
+	} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+
+		if recorder.Code != wantStatus {
+			t.Fatalf("GET %s status = %d, want %d", target, recorder.Code, wantStatus)
+		}
+	}
+}
+
+func TestAddTitleVariant(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeAdminService{}
+	handler := testAdminHandler(t, service)
+
+	recorder := httptest.NewRecorder()
+	body := strings.NewReader(`{"movie_id":"ET00395817","language":"hi","title":"सुपरमैन"}`)
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/admin/title-variants", body))
+
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusCreated, recorder.Body.String())
+	}
+
+	if len(service.variants) != 1 || service.variants[0].MovieID != "ET00395817" || service.variants[0].Language != "hi" {
+		t.Fatalf("variants = %+v, want the hi variant of ET00395817", service.variants)
+	}
+}
+
+func TestAddTitleVariantRejectsUnknownLanguage(t *testing.T) {
+	t.Parallel()
+
+	handler := testAdminHandler(t, &fakeAdminService{err: movies.ErrLanguageUnknown})
+
+	recorder := httptest.NewRecorder()
+	body := strings.NewReader(`{"movie_id":"ET00395817","language":"xx-!!","title":"Superman"}`)
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/admin/title-variants", body))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
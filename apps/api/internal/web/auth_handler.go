@@ -0,0 +1,178 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"go-scraping/internal/users"
+)
+
+type accountService interface {
+	Register(ctx context.Context, email, password string) (users.User, error)
+	Login(ctx context.Context, email, password string) (users.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (users.TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+	User(ctx context.Context, id int64) (users.User, error)
+	authenticator
+}
+
+type authenticator interface {
+	Authenticate(accessToken string) (int64, error)
+}
+
+type credentialsRequest struct {
+	Email    string `json:"email" validate:"required,text"`
+	Password string `json:"password" validate:"required"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type userIDKey struct{}
+
+// UserID returns the ID of the user authenticated by RequireAuth.
+func UserID(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(userIDKey{}).(int64)
+	return id, ok
+}
+
+// RequireAuth rejects requests without a valid "Authorization: Bearer" access
+// token, and otherwise makes the user's ID available through UserID.
+func RequireAuth(auth authenticator) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !found {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				WriteError(w, http.StatusUnauthorized, "Authentication required")
+				return
+			}
+
+			userID, err := auth.Authenticate(token)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				WriteError(w, http.StatusUnauthorized, "Invalid or expired access token")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userIDKey{}, userID)))
+		})
+	}
+}
+
+type AuthHandler struct {
+	accounts accountService
+	logger   *slog.Logger
+}
+
+func RegisterAuthRoutes(mux *http.ServeMux, accounts accountService, logger *slog.Logger) {
+	handler := &AuthHandler{
+		accounts: accounts,
+		logger:   logger,
+	}
+
+	mux.Handle("POST /auth/register", http.HandlerFunc(handler.Register))
+	mux.Handle("POST /auth/login", http.HandlerFunc(handler.Login))
+	mux.Handle("POST /auth/refresh", http.HandlerFunc(handler.Refresh))
+	mux.Handle("POST /auth/logout", http.HandlerFunc(handler.Logout))
+	mux.Handle("GET /auth/me", RequireAuth(accounts)(http.HandlerFunc(handler.Me)))
+}
+
+func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var req credentialsRequest
+	if err := bindJSON(w, r, &req); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	user, err := h.accounts.Register(r.Context(), req.Email, req.Password)
+
+	var invalid *users.InvalidInputError
+	switch {
+	case errors.As(err, &invalid):
+		writeBindError(w, invalidField(invalid.Field, "%s", invalid.Reason))
+	case errors.Is(err, users.ErrEmailTaken):
+		WriteError(w, http.StatusConflict, "An account with this email already exists")
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "failed to register user", "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to register")
+	default:
+		WriteJSON(w, http.StatusCreated, user)
+	}
+}
+
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req credentialsRequest
+	if err := bindJSON(w, r, &req); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	tokens, err := h.accounts.Login(r.Context(), req.Email, req.Password)
+	switch {
+	case errors.Is(err, users.ErrInvalidCredentials):
+		WriteError(w, http.StatusUnauthorized, "Invalid email or password")
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "failed to log in", "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to log in")
+	default:
+		WriteJSON(w, http.StatusOK, tokens)
+	}
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if err := bindJSON(w, r, &req); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	tokens, err := h.accounts.Refresh(r.Context(), req.RefreshToken)
+	switch {
+	case errors.Is(err, users.ErrInvalidToken):
+		WriteError(w, http.StatusUnauthorized, "Invalid or expired refresh token")
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "failed to refresh tokens", "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to refresh tokens")
+	default:
+		WriteJSON(w, http.StatusOK, tokens)
+	}
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if err := bindJSON(w, r, &req); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	err := h.accounts.Logout(r.Context(), req.RefreshToken)
+	switch {
+	case errors.Is(err, users.ErrInvalidToken):
+		WriteError(w, http.StatusUnauthorized, "Invalid or expired refresh token")
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "failed to log out", "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to log out")
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	userID, _ := UserID(r.Context())
+
+	user, err := h.accounts.User(r.Context(), userID)
+	switch {
+	case errors.Is(err, users.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "User not found")
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "failed to load user", "user_id", userID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to load user")
+	default:
+		WriteJSON(w, http.StatusOK, user)
+	}
+}
@@ -0,0 +1,135 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-scraping/internal/users"
+)
+
+type fakeAccounts struct{}
+
+func (fakeAccounts) Register(_ context.Context, email, _ string) (users.User, error) {
+	if email == "taken@example.com" {
+		return users.User{}, users.ErrEmailTaken
+	}
+
+	if !strings.Contains(email, "@") {
+		return users.User{}, &users.InvalidInputError{Field: "email", Reason: "email must be a valid address"}
+	}
+
+	return users.User{ID: 1, Email: email}, nil
+}
+
+func (fakeAccounts) Login(_ context.Context, _, password string) (users.TokenPair, error) {
+	if password != "correct horse" {
+		return users.TokenPair{}, users.ErrInvalidCredentials
+	}
+
+	return users.TokenPair{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer"}, nil
+}
+
+func (fakeAccounts) Refresh(_ context.Context, refreshToken string) (users.TokenPair, error) {
+	if refreshToken != "refresh" {
+		return users.TokenPair{}, users.ErrInvalidToken
+	}
+
+	return users.TokenPair{AccessToken: "access", RefreshToken: "refresh-2", TokenType: "Bearer"}, nil
+}
+
+func (fakeAccounts) Logout(_ context.Context, refreshToken string) error {
+	if refreshToken != "refresh" {
+		return users.ErrInvalidToken
+	}
+
+	return nil
+}
+
+func (fakeAccounts) Authenticate(accessToken string) (int64, error) {
+	if accessToken != "access" {
+		return 0, users.ErrInvalidToken
+	}
+
+	return 1, nil
+}
+
+func (fakeAccounts) User(_ context.Context, id int64) (users.User, error) {
+	return users.User{ID: id, Email: "ana@example.com"}, nil
+}
+
+func (fakeAccounts) Preferences(context.Context, int64) (users.Preferences, error) {
+	return users.Preferences{HomeCity: "bhubaneswar", Languages: []string{"Odia"}}, nil
+}
+
+func TestAuthRoutes(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	RegisterAuthRoutes(mux, fakeAccounts{}, slog.New(slog.DiscardHandler))
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		authHeader string
+		wantStatus int
+	}{
+		{name: "register", method: http.MethodPost, target: "/auth/register", body: `{"email": "ana@example.com", "password": "correct horse"}`, wantStatus: http.StatusCreated},
+		{name: "register taken", method: http.MethodPost, target: "/auth/register", body: `{"email": "taken@example.com", "password": "correct horse"}`, wantStatus: http.StatusConflict},
+		{name: "register invalid", method: http.MethodPost, target: "/auth/register", body: `{"email": "ana", "password": "correct horse"}`, wantStatus: http.StatusBadRequest},
+		{name: "login", method: http.MethodPost, target: "/auth/login", body: `{"email": "ana@example.com", "password": "correct horse"}`, wantStatus: http.StatusOK},
+		{name: "login wrong password", method: http.MethodPost, target: "/auth/login", body: `{"email": "ana@example.com", "password": "wrong"}`, wantStatus: http.StatusUnauthorized},
+		{name: "refresh", method: http.MethodPost, target: "/auth/refresh", body: `{"refresh_token": "refresh"}`, wantStatus: http.StatusOK},
+		{name: "refresh invalid", method: http.MethodPost, target: "/auth/refresh", body: `{"refresh_token": "forged"}`, wantStatus: http.StatusUnauthorized},
+		{name: "logout", method: http.MethodPost, target: "/auth/logout", body: `{"refresh_token": "refresh"}`, wantStatus: http.StatusNoContent},
+		{name: "me", method: http.MethodGet, target: "/auth/me", authHeader: "Bearer access", wantStatus: http.StatusOK},
+		{name: "me without token", method: http.MethodGet, target: "/auth/me", wantStatus: http.StatusUnauthorized},
+		{name: "me with invalid token", method: http.MethodGet, target: "/auth/me", authHeader: "Bearer forged", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("%s %s status = %d, want %d", tt.method, tt.target, recorder.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAuthMeReturnsAuthenticatedUser(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	RegisterAuthRoutes(mux, fakeAccounts{}, slog.New(slog.DiscardHandler))
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/me", nil)
+	req.Header.Set("Authorization", "Bearer access")
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+
+	var user users.User
+	if err := json.NewDecoder(recorder.Body).Decode(&user); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if user.ID != 1 || user.Email != "ana@example.com" {
+		t.Fatalf("GET /auth/me = %+v, want user 1", user)
+	}
+}
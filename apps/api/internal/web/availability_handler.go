@@ -0,0 +1,109 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-scraping/internal/movies"
+)
+
+type availabilityFinder interface {
+	Availability(ctx context.Context, title string) (movies.Availability, error)
+	MovieAvailability(ctx context.Context, id string) (movies.Availability, error)
+}
+
+// availabilityParams names the movie by either a title to search for or its
+// ID.
+type availabilityParams struct {
+	Title string `query:"title" validate:"text,maxlen=100"`
+	ID    string `query:"id" validate:"text,maxlen=100"`
+}
+
+type cityAvailability struct {
+	ID          string    `json:"id"`
+	City        string    `json:"city"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Title       string    `json:"title"`
+	Href        string    `json:"href"`
+	ScrapedAt   time.Time `json:"scraped_at"`
+}
+
+type availabilityResponse struct {
+	ID     string             `json:"id"`
+	Title  string             `json:"title"`
+	Cities []cityAvailability `json:"cities"`
+	Count  int                `json:"count"`
+}
+
+type AvailabilityHandler struct {
+	finder availabilityFinder
+	cities cityRegistry
+	logger *slog.Logger
+}
+
+func RegisterAvailabilityRoutes(mux *http.ServeMux, finder availabilityFinder, registry cityRegistry, logger *slog.Logger) {
+	handler := &AvailabilityHandler{
+		finder: finder,
+		cities: registry,
+		logger: logger,
+	}
+
+	mux.Handle("GET /movies/availability", http.HandlerFunc(handler.Availability))
+}
+
+// Availability lists every city showing the movie with the given ID, or the
+// one that best matches the title, with each city's booking link, for users
+// deciding where to watch something that hasn't opened locally.
+func (h *AvailabilityHandler) Availability(w http.ResponseWriter, r *http.Request) {
+	var params availabilityParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	if (params.Title == "") == (params.ID == "") {
+		writeBindError(w, invalidField("title", "set either title or id"))
+		return
+	}
+
+	var (
+		availability movies.Availability
+		err          error
+	)
+	if params.ID != "" {
+		availability, err = h.finder.MovieAvailability(r.Context(), params.ID)
+	} else {
+		availability, err = h.finder.Availability(r.Context(), params.Title)
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to look up availability", "title", params.Title, "id", params.ID, "error", err)
+		WriteServiceError(w, err, "Failed to look up availability")
+		return
+	}
+
+	response := availabilityResponse{
+		ID:     availability.ID,
+		Title:  availability.Title,
+		Cities: make([]cityAvailability, 0, len(availability.Cities)),
+	}
+	for _, listing := range availability.Cities {
+		entry := cityAvailability{
+			ID:    listing.Movie.ID(),
+			City:  listing.City,
+			Title: listing.Movie.Title,
+			Href:  listing.Movie.Href,
+		}
+		city, ok := h.cities.Resolve(listing.City)
+		if ok {
+			entry.DisplayName = city.DisplayName
+		}
+		entry.ScrapedAt = listing.ScrapedAt.In(city.Location())
+
+		response.Cities = append(response.Cities, entry)
+	}
+	response.Count = len(response.Cities)
+
+	WriteJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,134 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeAvailabilityFinder struct {
+	title string
+	id    string
+}
+
+func (f *fakeAvailabilityFinder) Availability(_ context.Context, title string) (movies.Availability, error) {
+	f.title = title
+	if title == "Kantara" {
+		return movies.Availability{}, movies.ErrTitleNotShowing
+	}
+
+	return f1Availability, nil
+}
+
+func (f *fakeAvailabilityFinder) MovieAvailability(_ context.Context, id string) (movies.Availability, error) {
+	f.id = id
+	if id != "ET00403839" {
+		return movies.Availability{}, movies.ErrTitleNotShowing
+	}
+
+	return f1Availability, nil
+}
+
+var f1Availability = movies.Availability{
+	ID:    "ET00403839",
+	Title: "F1",
+	Cities: []movies.Listing{
+		{City: "bhubaneswar", Movie: movies.Movie{Title: "F1", Href: "/bhubaneswar/f1/ET00403839"}},
+		{City: "goa", Movie: movies.Movie{Title: "F1", Href: "/goa/f1/ET00403839"}},
+	},
+}
+
+func testAvailabilityHandler(t *testing.T, finder availabilityFinder) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterAvailabilityRoutes(mux, finder, registry, slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestAvailabilityListsCities(t *testing.T) {
+	t.Parallel()
+
+	finder := &fakeAvailabilityFinder{}
+	recorder := httptest.NewRecorder()
+	testAvailabilityHandler(t, finder).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies/availability?title=%20f1%20", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	if finder.title != "f1" {
+		t.Fatalf("Availability() title = %q, want %q", finder.title, "f1")
+	}
+
+	var response availabilityResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if response.Count != 2 || response.Cities[0].DisplayName != "Bhubaneswar" || response.Cities[1].Href != "/goa/f1/ET00403839" {
+		t.Fatalf("response = %+v, want bhubaneswar with its display name and goa", response)
+	}
+}
+
+func TestAvailabilityLooksUpMovieIDs(t *testing.T) {
+	t.Parallel()
+
+	finder := &fakeAvailabilityFinder{}
+	recorder := httptest.NewRecorder()
+	testAvailabilityHandler(t, finder).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies/availability?id=ET00403839", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	if finder.id != "ET00403839" || finder.title != "" {
+		t.Fatalf("looked up id %q and title %q, want only the id", finder.id, finder.title)
+	}
+
+	var response availabilityResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if response.ID != "ET00403839" || response.Count != 2 || response.Cities[1].ID != "ET00403839" {
+		t.Fatalf("response = %+v, want F1 in two cities with its ID", response)
+	}
+}
+
+func TestAvailabilityRejectsMissingTitlesAndReportsUnknownOnes(t *testing.T) {
+	t.Parallel()
+
+	handler := testAvailabilityHandler(t, &fakeAvailabilityFinder{})
+
+	tests := []struct {
+		target     string
+		wantStatus int
+	}{
+		{target: "/movies/availability", wantStatus: http.StatusBadRequest},
+		{target: "/movies/availability?title=Kantara", wantStatus: http.StatusNotFound},
+		{target: "/movies/availability?title=F1&id=ET00403839", wantStatus: http.StatusBadRequest},
+		{target: "/movies/availability?id=ET00376540", wantStatus: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.target, nil))
+
+		if recorder.Code != test.wantStatus {
+			t.Errorf("GET %s status = %d, want %d", test.target, recorder.Code, test.wantStatus)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package web
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Handlers read their parameters by binding them to a struct whose fields
+// name the parameter and its rules:
+//
+//	Limit int    `query:"limit" default:"20" validate:"min=1,max=50"`
+//	Title string `json:"title" validate:"required,text,maxlen=100"`
+//
+// Query parameters are bound from the query tag and body fields from the json
+// tag. Fields may be strings, integers, booleans, comma-separated string
+// lists, or types implementing encoding.TextUnmarshaler, whose errors are
+// reported after the parameter's name. A query parameter that is missing or
+// empty takes its default, if any. The rules are:
+//
+//	required  the parameter must be set
+//	text      a string is trimmed and must not contain control characters
+//	maxlen=N  a string has at most N characters
+//	oneof=A B a string is one of the space-separated values
+//	min=N     an integer is at least N
+//	max=N     an integer is at most N
+//
+// Rules other than required apply only to parameters that are set. Every
+// invalid parameter is reported, not just the first.
+
+// FieldError is why a request parameter is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists the invalid parameters of a request.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// invalidField reports a single invalid parameter, for checks that struct
+// tags cannot express.
+func invalidField(field, format string, args ...any) *ValidationError {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: fmt.Sprintf(format, args...)}}}
+}
+
+type validationResponse struct {
+	Error  string       `json:"error"`
+	Code   string       `json:"code"`
+	Fields []FieldError `json:"fields"`
+}
+
+// writeBindError responds with 400 to a request whose parameters could not be
+// bound. Validation errors list each invalid field; anything else is a body
+// that is not valid JSON.
+func writeBindError(w http.ResponseWriter, err error) {
+	var invalid *ValidationError
+	if errors.As(err, &invalid) {
+		WriteJSON(w, http.StatusBadRequest, validationResponse{Error: invalid.Error(), Code: "invalid_request", Fields: invalid.Fields})
+		return
+	}
+
+	writeErrorCode(w, http.StatusBadRequest, "invalid_body", "Invalid request body")
+}
+
+// bindQuery binds the request's query parameters to the struct dst points to
+// and validates them.
+func bindQuery(r *http.Request, dst any) error {
+	query := r.URL.Query()
+
+	return bindFields(dst, "query", func(name string) string {
+		return query.Get(name)
+	})
+}
+
+// bindJSON decodes the request's JSON body into the struct dst points to and
+// validates it.
+func bindJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	if err := DecodeJSON(w, r, dst); err != nil {
+		return err
+	}
+
+	return bindFields(dst, "json", nil)
+}
+
+// bindFields sets the fields named in tag from lookup, unless it is nil, and
+// then checks their rules.
+func bindFields(dst any, tag string, lookup func(name string) string) error {
+	value := reflect.ValueOf(dst).Elem()
+
+	var invalid ValidationError
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		set := !value.Field(i).IsZero()
+		if lookup != nil {
+			raw := lookup(name)
+			if raw == "" {
+				raw = field.Tag.Get("default")
+			}
+
+			set = raw != ""
+			if set {
+				if message := setField(value.Field(i), name, raw); message != "" {
+					invalid.Fields = append(invalid.Fields, FieldError{Field: name, Message: message})
+					continue
+				}
+			}
+		}
+
+		if message := checkRules(value.Field(i), name, field.Tag.Get("validate"), set); message != "" {
+			invalid.Fields = append(invalid.Fields, FieldError{Field: name, Message: message})
+		}
+	}
+
+	if len(invalid.Fields) > 0 {
+		return &invalid
+	}
+
+	return nil
+}
+
+// setField parses raw into field, returning why it is invalid.
+func setField(field reflect.Value, name, raw string) string {
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := unmarshaler.UnmarshalText([]byte(raw)); err != nil {
+			return name + " " + err.Error()
+		}
+
+		return ""
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return name + " must be an integer"
+		}
+		field.SetInt(parsed)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return name + " must be true or false"
+		}
+		field.SetBool(parsed)
+	case reflect.Slice:
+		field.Set(reflect.ValueOf(parseList(raw)))
+	default:
+		panic(fmt.Sprintf("web: cannot bind %s to a %s", name, field.Type()))
+	}
+
+	return ""
+}
+
+// checkRules returns why field breaks one of rules, or "" when it doesn't.
+// Set reports whether the parameter was given.
+func checkRules(field reflect.Value, name, rules string, set bool) string {
+	if rules == "" {
+		return ""
+	}
+
+	parsed := make(map[string]string)
+	for _, rule := range strings.Split(rules, ",") {
+		key, arg, _ := strings.Cut(rule, "=")
+		parsed[key] = arg
+	}
+
+	if _, ok := parsed["text"]; ok && field.Kind() == reflect.String {
+		text := strings.TrimSpace(field.String())
+		if !utf8.ValidString(text) || strings.ContainsFunc(text, unicode.IsControl) {
+			return name + " must not contain control characters"
+		}
+		field.SetString(text)
+		set = set && text != ""
+	}
+
+	if !set {
+		if _, ok := parsed["required"]; ok {
+			return name + " is required"
+		}
+
+		return ""
+	}
+
+	if arg, ok := parsed["maxlen"]; ok && utf8.RuneCountInString(field.String()) > ruleInt(arg) {
+		return fmt.Sprintf("%s must be at most %s characters", name, arg)
+	}
+
+	if arg, ok := parsed["oneof"]; ok && !slices.Contains(strings.Fields(arg), field.String()) {
+		return name + " must be " + quoteChoices(strings.Fields(arg))
+	}
+
+	minArg, hasMin := parsed["min"]
+	maxArg, hasMax := parsed["max"]
+	if (hasMin || hasMax) && field.CanInt() {
+		value := field.Int()
+		switch {
+		case hasMin && hasMax && (value < int64(ruleInt(minArg)) || value > int64(ruleInt(maxArg))):
+			return fmt.Sprintf("%s must be an integer between %s and %s", name, minArg, maxArg)
+		case hasMin && !hasMax && value < int64(ruleInt(minArg)):
+			return fmt.Sprintf("%s must be at least %s", name, minArg)
+		case hasMax && !hasMin && value > int64(ruleInt(maxArg)):
+			return fmt.Sprintf("%s must be at most %s", name, maxArg)
+		}
+	}
+
+	return ""
+}
+
+func ruleInt(arg string) int {
+	value, err := strconv.Atoi(arg)
+	if err != nil {
+		panic(fmt.Sprintf("web: rule argument %q is not an integer", arg))
+	}
+
+	return value
+}
+
+// quoteChoices formats choices as "a", "a" or "b", or "a", "b", or "c".
+func quoteChoices(choices []string) string {
+	quoted := make([]string, 0, len(choices))
+	for _, choice := range choices {
+		quoted = append(quoted, strconv.Quote(choice))
+	}
+
+	switch len(quoted) {
+	case 1:
+		return quoted[0]
+	case 2:
+		return quoted[0] + " or " + quoted[1]
+	default:
+		return strings.Join(quoted[:len(quoted)-1], ", ") + ", or " + quoted[len(quoted)-1]
+	}
+}
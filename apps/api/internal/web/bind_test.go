@@ -0,0 +1,116 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testParams struct {
+	Query  string            `query:"q" validate:"required,text,maxlen=5"`
+	Limit  int               `query:"limit" default:"10" validate:"min=1,max=50"`
+	Format string            `query:"format" default:"csv" validate:"oneof=csv ndjson"`
+	Tags   []string          `query:"tags"`
+	In     searchFieldsParam `query:"in"`
+}
+
+func TestBindQueryAppliesDefaultsAndRules(t *testing.T) {
+	t.Parallel()
+
+	var params testParams
+	request := httptest.NewRequest(http.MethodGet, "/?q=+dune+&tags=a,+b,,c&in=cast,title,cast", nil)
+	if err := bindQuery(request, &params); err != nil {
+		t.Fatalf("bindQuery() error = %v", err)
+	}
+
+	want := testParams{Query: "dune", Limit: 10, Format: "csv", Tags: []string{"a", "b", "c"}, In: searchFieldsParam{"cast", "title"}}
+	if !reflect.DeepEqual(params, want) {
+		t.Fatalf("params = %+v, want %+v", params, want)
+	}
+}
+
+func TestBindQueryReportsEveryInvalidField(t *testing.T) {
+	t.Parallel()
+
+	var params testParams
+	request := httptest.NewRequest(http.MethodGet, "/?limit=0&format=xlsx&in=plot", nil)
+	err := bindQuery(request, &params)
+
+	want := []FieldError{
+		{Field: "q", Message: "q is required"},
+		{Field: "limit", Message: "limit must be an integer between 1 and 50"},
+		{Field: "format", Message: `format must be "csv" or "ndjson"`},
+		{Field: "in", Message: `in has unknown search field "plot", expected any of title, cast, genres, languages`},
+	}
+
+	invalid, ok := err.(*ValidationError)
+	if !ok || !reflect.DeepEqual(invalid.Fields, want) {
+		t.Fatalf("bindQuery() error = %v, want fields %+v", err, want)
+	}
+}
+
+func TestBindQueryRejectsControlCharactersAndLongText(t *testing.T) {
+	t.Parallel()
+
+	for target, want := range map[string]string{
+		"/?q=du%00ne":   "q must not contain control characters",
+		"/?q=dunesss":   "q must be at most 5 characters",
+		"/?q=a&limit=":  "",
+		"/?q=a&limit=x": "limit must be an integer",
+	} {
+		var params testParams
+		err := bindQuery(httptest.NewRequest(http.MethodGet, target, nil), &params)
+		if got := errorText(err); got != want {
+			t.Errorf("bindQuery(%s) error = %q, want %q", target, got, want)
+		}
+	}
+}
+
+func TestWriteBindErrorListsFields(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"city": "  "}`))
+
+	var req subscribeRequest
+	writeBindError(recorder, bindJSON(recorder, request, &req))
+
+	var payload validationResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	want := validationResponse{
+		Error:  "email is required; city is required",
+		Code:   "invalid_request",
+		Fields: []FieldError{{Field: "email", Message: "email is required"}, {Field: "city", Message: "city is required"}},
+	}
+	if recorder.Code != http.StatusBadRequest || !reflect.DeepEqual(payload, want) {
+		t.Fatalf("response = %d %+v, want 400 %+v", recorder.Code, payload, want)
+	}
+}
+
+func TestWriteBindErrorReportsMalformedBodies(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"email": 42}`))
+
+	var req subscribeRequest
+	writeBindError(recorder, bindJSON(recorder, request, &req))
+
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), `"invalid_body"`) {
+		t.Fatalf("response = %d %s, want 400 with code invalid_body", recorder.Code, recorder.Body)
+	}
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
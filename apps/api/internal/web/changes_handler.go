@@ -0,0 +1,121 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-scraping/internal/movies"
+)
+
+type changeLister interface {
+	ListChanges(ctx context.Context, city string, since time.Time) (movies.Changes, error)
+}
+
+const defaultChangesDays = 7
+
+type changesParams struct {
+	// Since is parsed after the city is known, since a bare date is
+	// midnight in the city's timezone.
+	Since string `query:"since"`
+}
+
+type changedMovie struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Href        string    `json:"href"`
+	Year        int       `json:"year,omitempty"`
+	Genres      []string  `json:"genres,omitempty"`
+	Languages   []string  `json:"languages,omitempty"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+type changesResponse struct {
+	City    string         `json:"city"`
+	Since   time.Time      `json:"since"`
+	Added   []changedMovie `json:"added"`
+	Removed []changedMovie `json:"removed"`
+}
+
+type ChangesHandler struct {
+	lister      changeLister
+	cities      cityRegistry
+	defaultCity string
+	logger      *slog.Logger
+}
+
+func RegisterChangeRoutes(mux *http.ServeMux, lister changeLister, registry cityRegistry, defaultCity string, logger *slog.Logger) {
+	handler := &ChangesHandler{
+		lister:      lister,
+		cities:      registry,
+		defaultCity: defaultCity,
+		logger:      logger,
+	}
+
+	mux.Handle("GET /movies/changes", http.HandlerFunc(handler.Changes))
+}
+
+// Changes returns the titles added to and removed from a city's listings
+// since a date or timestamp, so integrations need not diff full listings.
+// A bare date means midnight in the city's timezone.
+func (h *ChangesHandler) Changes(w http.ResponseWriter, r *http.Request) {
+	city, err := resolveCity(r, h.cities, h.defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+
+	var params changesParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -defaultChangesDays)
+	if value := params.Since; value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, city.Location())
+		if err != nil {
+			parsed, err = time.Parse(time.RFC3339, value)
+		}
+		if err != nil {
+			writeBindError(w, invalidField("since", "since must be a date such as 2025-07-01 or an RFC 3339 timestamp"))
+			return
+		}
+
+		since = parsed
+	}
+
+	changes, err := h.lister.ListChanges(r.Context(), city.Name, since)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list movie changes", "city", city.Name, "error", err)
+		WriteServiceError(w, err, "Failed to list movie changes")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, changesResponse{
+		City:    city.Name,
+		Since:   since.In(city.Location()),
+		Added:   changedMovies(changes.Added, city.Location()),
+		Removed: changedMovies(changes.Removed, city.Location()),
+	})
+}
+
+func changedMovies(sightings []movies.Sighting, location *time.Location) []changedMovie {
+	result := make([]changedMovie, 0, len(sightings))
+	for _, sighting := range sightings {
+		result = append(result, changedMovie{
+			ID:          sighting.Movie.ID(),
+			Title:       sighting.Movie.Title,
+			Href:        sighting.Movie.Href,
+			Year:        sighting.Movie.Year,
+			Genres:      sighting.Movie.Genres,
+			Languages:   sighting.Movie.Languages,
+			FirstSeenAt: sighting.FirstSeenAt.In(location),
+			LastSeenAt:  sighting.LastSeenAt.In(location),
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,88 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeChangeLister struct {
+	city  string
+	since time.Time
+}
+
+func (f *fakeChangeLister) ListChanges(_ context.Context, city string, since time.Time) (movies.Changes, error) {
+	f.city, f.since = city, since
+
+	return movies.Changes{
+		Added:   []movies.Sighting{{City: city, Movie: movies.Movie{Title: "F1", Href: "/f1"}}},
+		Removed: []movies.Sighting{{City: city, Movie: movies.Movie{Title: "Sitaare Zameen Par", Href: "/sitaare"}}},
+	}, nil
+}
+
+func testChangesHandler(t *testing.T, lister changeLister) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Timezone: "Asia/Kolkata", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterChangeRoutes(mux, lister, registry, "cuttack", slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestChangesParsesDatesInCityTimezone(t *testing.T) {
+	t.Parallel()
+
+	lister := &fakeChangeLister{}
+	recorder := httptest.NewRecorder()
+	testChangesHandler(t, lister).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies/changes?city=bbsr&since=2025-07-01", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	if want := time.Date(2025, 6, 30, 18, 30, 0, 0, time.UTC); lister.city != "bhubaneswar" || !lister.since.Equal(want) {
+		t.Fatalf("ListChanges() city = %q, since = %s, want bhubaneswar since %s", lister.city, lister.since, want)
+	}
+
+	var response changesResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if len(response.Added) != 1 || response.Added[0].Title != "F1" || len(response.Removed) != 1 || response.Removed[0].Title != "Sitaare Zameen Par" {
+		t.Fatalf("response = %+v, want F1 added and Sitaare Zameen Par removed", response)
+	}
+}
+
+func TestChangesAcceptsTimestampsAndRejectsOtherSince(t *testing.T) {
+	t.Parallel()
+
+	lister := &fakeChangeLister{}
+	handler := testChangesHandler(t, lister)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies/changes?since=2025-07-01T06:00:00Z", nil))
+
+	if recorder.Code != http.StatusOK || !lister.since.Equal(time.Date(2025, 7, 1, 6, 0, 0, 0, time.UTC)) {
+		t.Fatalf("status = %d, since = %s, want 200 since the timestamp", recorder.Code, lister.since)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies/changes?since=last-week", nil))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,88 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-scraping/internal/cities"
+)
+
+type cityOnboarder interface {
+	Onboard(ctx context.Context, city cities.City) (cities.Onboarded, error)
+}
+
+type onboardRequest struct {
+	Name        string   `json:"name" validate:"required,text"`
+	DisplayName string   `json:"display_name" validate:"text,maxlen=100"`
+	Timezone    string   `json:"timezone" validate:"text,maxlen=100"`
+	Aliases     []string `json:"aliases"`
+}
+
+type onboardResponse struct {
+	City   cities.City `json:"city"`
+	Movies int         `json:"movies"`
+	Queued bool        `json:"queued"`
+}
+
+type CitiesHandler struct {
+	onboarder cityOnboarder
+	logger    *slog.Logger
+}
+
+func RegisterCityRoutes(mux *http.ServeMux, onboarder cityOnboarder, logger *slog.Logger) {
+	handler := &CitiesHandler{
+		onboarder: onboarder,
+		logger:    logger,
+	}
+
+	mux.Handle("POST /admin/cities", http.HandlerFunc(handler.OnboardCity))
+}
+
+// OnboardCity adds a city without a config change or redeploy. The city is
+// scraped once first, and rejected unless BookMyShow lists movies for it.
+func (h *CitiesHandler) OnboardCity(w http.ResponseWriter, r *http.Request) {
+	var req onboardRequest
+	if err := bindJSON(w, r, &req); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	city := cities.City{
+		Name:        strings.ToLower(req.Name),
+		DisplayName: req.DisplayName,
+		Timezone:    req.Timezone,
+	}
+	if len(city.Name) > maxCityLength || !citySlug.MatchString(city.Name) {
+		writeBindError(w, invalidField("name", "name must be a city slug of at most %d lowercase letters, digits and hyphens", maxCityLength))
+		return
+	}
+
+	if city.Timezone != "" {
+		if _, err := time.LoadLocation(city.Timezone); err != nil {
+			writeBindError(w, invalidField("timezone", "timezone must be an IANA timezone such as Asia/Kolkata"))
+			return
+		}
+	}
+
+	for _, alias := range req.Aliases {
+		alias = strings.ToLower(strings.TrimSpace(alias))
+		if len(alias) > maxCityLength || !citySlug.MatchString(alias) {
+			writeBindError(w, invalidField("aliases", "aliases must be slugs of at most %d lowercase letters, digits and hyphens", maxCityLength))
+			return
+		}
+
+		city.Aliases = append(city.Aliases, alias)
+	}
+
+	onboarded, err := h.onboarder.Onboard(r.Context(), city)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to onboard city", "city", city.Name, "error", err)
+		WriteServiceError(w, err, "Failed to onboard the city")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, onboardResponse{City: onboarded.City, Movies: onboarded.Movies, Queued: onboarded.Queued})
+}
@@ -0,0 +1,95 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-scraping/internal/cities"
+)
+
+type fakeOnboarder struct {
+	onboarded cities.City
+	err       error
+}
+
+func (f *fakeOnboarder) Onboard(_ context.Context, city cities.City) (cities.Onboarded, error) {
+	if f.err != nil {
+		return cities.Onboarded{}, f.err
+	}
+
+	f.onboarded = city
+	return cities.Onboarded{City: city, Movies: 12, Queued: true}, nil
+}
+
+func testCitiesHandler(onboarder cityOnboarder) http.Handler {
+	mux := http.NewServeMux()
+	RegisterCityRoutes(mux, onboarder, slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestOnboardCity(t *testing.T) {
+	t.Parallel()
+
+	onboarder := &fakeOnboarder{}
+	recorder := httptest.NewRecorder()
+	body := `{"name": " Puri ", "display_name": "Puri", "timezone": "Asia/Kolkata", "aliases": ["Jagannath-Puri"]}`
+	testCitiesHandler(onboarder).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/admin/cities", strings.NewReader(body)))
+
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusCreated, recorder.Body)
+	}
+
+	want := cities.City{Name: "puri", DisplayName: "Puri", Timezone: "Asia/Kolkata", Aliases: []string{"jagannath-puri"}}
+	if !reflect.DeepEqual(onboarder.onboarded, want) {
+		t.Fatalf("Onboard() city = %+v, want %+v", onboarder.onboarded, want)
+	}
+
+	var response onboardResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil || response.Movies != 12 || !response.Queued {
+		t.Fatalf("response = %+v, %v, want 12 movies, queued", response, err)
+	}
+}
+
+func TestOnboardCityRejectsInvalidRequests(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		status int
+		code   string
+	}{
+		{"missing name", `{}`, nil, http.StatusBadRequest, "invalid_request"},
+		{"name not a slug", `{"name": "new delhi"}`, nil, http.StatusBadRequest, "invalid_request"},
+		{"unknown timezone", `{"name": "puri", "timezone": "Asia/Puri"}`, nil, http.StatusBadRequest, "invalid_request"},
+		{"alias not a slug", `{"name": "puri", "aliases": ["pu ri"]}`, nil, http.StatusBadRequest, "invalid_request"},
+		{"name used", `{"name": "bbsr"}`, cities.ErrNameUsed, http.StatusConflict, "city_exists"},
+		{"not listed", `{"name": "purii"}`, cities.ErrNotListed, http.StatusUnprocessableEntity, "city_not_listed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := httptest.NewRecorder()
+			testCitiesHandler(&fakeOnboarder{err: tt.err}).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/admin/cities", strings.NewReader(tt.body)))
+
+			if recorder.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.status, recorder.Body)
+			}
+
+			var response map[string]any
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil || response["code"] != tt.code {
+				t.Fatalf("response = %v, %v, want code %s", response, err, tt.code)
+			}
+		})
+	}
+}
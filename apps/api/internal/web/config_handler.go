@@ -0,0 +1,37 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+type configReloader interface {
+	Reload(ctx context.Context) error
+}
+
+type ConfigHandler struct {
+	reloader configReloader
+	logger   *slog.Logger
+}
+
+func RegisterConfigRoutes(mux *http.ServeMux, reloader configReloader, logger *slog.Logger) {
+	handler := &ConfigHandler{
+		reloader: reloader,
+		logger:   logger,
+	}
+
+	mux.Handle("POST /admin/config/reload", http.HandlerFunc(handler.Reload))
+}
+
+// Reload re-reads the configuration and applies its reloadable settings. An
+// invalid configuration is rejected and the running settings are kept.
+func (h *ConfigHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	if err := h.reloader.Reload(r.Context()); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to reload config", "error", err)
+		WriteError(w, http.StatusUnprocessableEntity, "Failed to reload config: "+err.Error())
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{"status": "reloaded"})
+}
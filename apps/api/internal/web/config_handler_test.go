@@ -0,0 +1,71 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeConfigReloader struct {
+	reloads int
+	err     error
+}
+
+func (f *fakeConfigReloader) Reload(_ context.Context) error {
+	f.reloads++
+	return f.err
+}
+
+func testConfigHandler(t *testing.T, reloader configReloader) http.Handler {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	RegisterConfigRoutes(mux, reloader, slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestReloadConfigAppliesConfig(t *testing.T) {
+	t.Parallel()
+
+	reloader := &fakeConfigReloader{}
+	req := httptest.NewRequest(http.MethodPost, "/admin/config/reload", nil)
+	recorder := httptest.NewRecorder()
+
+	testConfigHandler(t, reloader).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	if reloader.reloads != 1 {
+		t.Fatalf("reloads = %d, want 1", reloader.reloads)
+	}
+}
+
+func TestReloadConfigRejectsInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	reloader := &fakeConfigReloader{err: errors.New("invalid config: cache_ttl must be positive, got 0s")}
+	req := httptest.NewRequest(http.MethodPost, "/admin/config/reload", nil)
+	recorder := httptest.NewRecorder()
+
+	testConfigHandler(t, reloader).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusUnprocessableEntity)
+	}
+
+	var payload map[string]string
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if want := "Failed to reload config: invalid config: cache_ttl must be positive, got 0s"; payload["error"] != want {
+		t.Fatalf("error = %q, want %q", payload["error"], want)
+	}
+}
@@ -0,0 +1,281 @@
+package web
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/jobs"
+	"go-scraping/internal/movies"
+)
+
+//go:embed templates/dashboard.html
+var dashboardFS embed.FS
+
+var dashboardTemplate = template.Must(template.New("dashboard.html").Funcs(template.FuncMap{
+	"age":      formatAge,
+	"duration": formatDuration,
+}).ParseFS(dashboardFS, "templates/dashboard.html"))
+
+type dashboardService interface {
+	Stats(ctx context.Context) (movies.CacheStats, error)
+	RecentScrapes() []movies.ScrapeRun
+	ScrapingPaused() bool
+	ListAliases(ctx context.Context) ([]movies.Alias, error)
+	AddAlias(ctx context.Context, alias, canonical string) (movies.Alias, error)
+	RemoveAlias(ctx context.Context, alias string) (bool, error)
+	ListCitySettings(ctx context.Context) ([]movies.CitySettings, error)
+	UpdateCitySettings(ctx context.Context, settings movies.CitySettings) (movies.CitySettings, error)
+}
+
+// minCityCacheTTL keeps a per-city cache TTL from making every request
+// scrape.
+const minCityCacheTTL = time.Minute
+
+type dashboardScheduler interface {
+	jobScheduler
+	Leader() bool
+}
+
+type dashboardView struct {
+	GeneratedAt time.Time
+	Message     string
+	Paused      bool
+	Leader      bool
+	Stats       movies.CacheStats
+	Jobs        []jobs.Status
+	Scrapes     []movies.ScrapeRun
+	Settings    []movies.CitySettings
+	Aliases     []movies.Alias
+
+	// StalePolicies are the options of each city's stale policy.
+	StalePolicies []movies.StalePolicy
+
+	// AliasesEnabled shows the aliases section, which has a form to add the
+	// first alias even while Aliases is empty.
+	AliasesEnabled bool
+}
+
+type DashboardHandler struct {
+	service   dashboardService
+	scheduler dashboardScheduler
+	cities    cityRegistry
+	logger    *slog.Logger
+}
+
+func RegisterDashboardRoutes(mux *http.ServeMux, service dashboardService, scheduler dashboardScheduler, registry cityRegistry, logger *slog.Logger) {
+	handler := &DashboardHandler{
+		service:   service,
+		scheduler: scheduler,
+		cities:    registry,
+		logger:    logger,
+	}
+
+	mux.Handle("GET /admin", http.HandlerFunc(handler.GetDashboard))
+	mux.Handle("POST /admin/dashboard/jobs/{name}/run", http.HandlerFunc(handler.RunJob))
+	mux.Handle("POST /admin/dashboard/cities/{city}", http.HandlerFunc(handler.UpdateCity))
+	mux.Handle("POST /admin/dashboard/aliases", http.HandlerFunc(handler.AddAlias))
+	mux.Handle("POST /admin/dashboard/aliases/{alias}/delete", http.HandlerFunc(handler.RemoveAlias))
+}
+
+func (h *DashboardHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.service.Stats(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to load cache stats", "error", err)
+		http.Error(w, "Failed to load cache stats", http.StatusInternalServerError)
+		return
+	}
+
+	view := dashboardView{
+		GeneratedAt: time.Now(),
+		Message:     r.URL.Query().Get("message"),
+		Paused:      h.service.ScrapingPaused(),
+		Leader:      h.scheduler.Leader(),
+		Stats:       stats,
+		Jobs:        h.scheduler.Statuses(),
+		Scrapes:     h.service.RecentScrapes(),
+	}
+
+	// The settings and alias forms are left out when their stores are not
+	// configured, without failing the rest of the page.
+	saved, err := h.service.ListCitySettings(r.Context())
+	if err != nil && !errors.Is(err, movies.ErrCitySettingsDisabled) {
+		h.logger.ErrorContext(r.Context(), "failed to load city settings", "error", err)
+	}
+	if err == nil {
+		view.Settings = dashboardCities(h.cities.List(), saved)
+		view.StalePolicies = movies.StalePolicies
+	}
+
+	view.Aliases, err = h.service.ListAliases(r.Context())
+	if err != nil && !errors.Is(err, movies.ErrAliasesDisabled) {
+		h.logger.ErrorContext(r.Context(), "failed to load aliases", "error", err)
+	}
+	view.AliasesEnabled = err == nil
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := dashboardTemplate.Execute(w, view); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to render dashboard", "error", err)
+	}
+}
+
+// RunJob triggers a job from the dashboard's form and redirects back to the
+// dashboard with the outcome.
+func (h *DashboardHandler) RunJob(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	message := "Queued " + name + "."
+	if err := h.scheduler.Trigger(name); err != nil {
+		switch {
+		case errors.Is(err, jobs.ErrUnknownJob):
+			message = "Unknown job " + name + "."
+		case errors.Is(err, jobs.ErrNotLeader):
+			message = "Jobs run on another replica."
+		default:
+			h.logger.ErrorContext(r.Context(), "failed to trigger job", "job", name, "error", err)
+			message = "Failed to trigger " + name + "."
+		}
+	}
+
+	redirectToDashboard(w, r, message)
+}
+
+// UpdateCity saves the city's enabled checkbox and cache TTL, an empty TTL
+// meaning the default.
+func (h *DashboardHandler) UpdateCity(w http.ResponseWriter, r *http.Request) {
+	city, err := resolveCityName(h.cities, r.PathValue("city"))
+	if err != nil {
+		redirectToDashboard(w, r, "Unknown city "+r.PathValue("city")+".")
+		return
+	}
+
+	settings := movies.CitySettings{
+		City:     city.Name,
+		Disabled: r.PostFormValue("enabled") != "true",
+	}
+
+	if value := strings.TrimSpace(r.PostFormValue("cache_ttl")); value != "" {
+		settings.CacheTTL, err = time.ParseDuration(value)
+		if err != nil || settings.CacheTTL < minCityCacheTTL {
+			redirectToDashboard(w, r, fmt.Sprintf("Cache TTL must be a duration of at least %s, such as 6h.", formatDuration(minCityCacheTTL)))
+			return
+		}
+	}
+
+	settings.StalePolicy = movies.StalePolicy(r.PostFormValue("stale_policy"))
+	if settings.StalePolicy != "" && !settings.StalePolicy.Valid() {
+		redirectToDashboard(w, r, fmt.Sprintf("Unknown stale policy %q.", settings.StalePolicy))
+		return
+	}
+
+	if _, err := h.service.UpdateCitySettings(r.Context(), settings); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to update city settings", "city", city.Name, "error", err)
+		redirectToDashboard(w, r, lookupServiceError(err, "Failed to save "+city.Name+".").message)
+		return
+	}
+
+	redirectToDashboard(w, r, "Saved "+city.Name+".")
+}
+
+func (h *DashboardHandler) AddAlias(w http.ResponseWriter, r *http.Request) {
+	alias, canonical := r.PostFormValue("alias"), r.PostFormValue("canonical")
+	if movies.AliasKey(alias) == "" || movies.NormalizeQuery(canonical) == "" {
+		redirectToDashboard(w, r, "Alias and canonical title are required.")
+		return
+	}
+
+	entry, err := h.service.AddAlias(r.Context(), alias, canonical)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to add alias", "alias", alias, "error", err)
+		redirectToDashboard(w, r, lookupServiceError(err, "Failed to add alias.").message)
+		return
+	}
+
+	redirectToDashboard(w, r, fmt.Sprintf("Added alias %q for %q.", entry.Alias, entry.Canonical))
+}
+
+func (h *DashboardHandler) RemoveAlias(w http.ResponseWriter, r *http.Request) {
+	alias := r.PathValue("alias")
+
+	removed, err := h.service.RemoveAlias(r.Context(), alias)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to remove alias", "alias", alias, "error", err)
+		redirectToDashboard(w, r, lookupServiceError(err, "Failed to remove alias.").message)
+		return
+	}
+
+	message := fmt.Sprintf("Removed alias %q.", alias)
+	if !removed {
+		message = fmt.Sprintf("Alias %q was already removed.", alias)
+	}
+
+	redirectToDashboard(w, r, message)
+}
+
+// redirectToDashboard sends a form submission back to the dashboard, which
+// shows message in a banner.
+func redirectToDashboard(w http.ResponseWriter, r *http.Request, message string) {
+	http.Redirect(w, r, "/admin?message="+url.QueryEscape(message), http.StatusSeeOther)
+}
+
+// dashboardCities lists the settings of every registered city, defaults
+// included, followed by those saved for unregistered cities.
+func dashboardCities(registered []cities.City, saved []movies.CitySettings) []movies.CitySettings {
+	byCity := make(map[string]movies.CitySettings, len(saved))
+	for _, settings := range saved {
+		byCity[settings.City] = settings
+	}
+
+	result := make([]movies.CitySettings, 0, len(registered))
+	for _, city := range registered {
+		settings, ok := byCity[city.Name]
+		if !ok {
+			settings.City = city.Name
+		}
+
+		result = append(result, settings)
+		delete(byCity, city.Name)
+	}
+
+	for _, settings := range saved {
+		if _, ok := byCity[settings.City]; ok {
+			result = append(result, settings)
+		}
+	}
+
+	return result
+}
+
+// formatDuration formats d the way it is typed into forms, such as "6h" rather
+// than "6h0m0s", and zero as empty.
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+
+	formatted := d.String()
+	if strings.HasSuffix(formatted, "m0s") {
+		formatted = strings.TrimSuffix(formatted, "0s")
+	}
+	if strings.HasSuffix(formatted, "h0m") {
+		formatted = strings.TrimSuffix(formatted, "0m")
+	}
+
+	return formatted
+}
+
+func formatAge(seconds float64) string {
+	if seconds <= 0 {
+		return "-"
+	}
+
+	return (time.Duration(seconds) * time.Second).Round(time.Minute).String()
+}
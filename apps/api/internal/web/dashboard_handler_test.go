@@ -0,0 +1,223 @@
+package web
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/jobs"
+	"go-scraping/internal/movies"
+)
+
+type fakeDashboardScheduler struct {
+	fakeJobScheduler
+	leader bool
+}
+
+func (f *fakeDashboardScheduler) Leader() bool {
+	return f.leader
+}
+
+func testDashboardHandler(t *testing.T, service dashboardService, scheduler dashboardScheduler) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{
+		{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Aliases: []string{"bbsr"}},
+		{Name: "cuttack", DisplayName: "Cuttack"},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterDashboardRoutes(mux, service, scheduler, registry, slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestGetDashboardRendersCitiesJobsAndScrapes(t *testing.T) {
+	t.Parallel()
+
+	scrapedAt := time.Now().Add(-2 * time.Hour)
+	service := &fakeAdminService{
+		stats: movies.CacheStats{
+			Cities: []movies.CityCacheStats{{City: "cuttack", MovieCount: 12, ScrapedAt: &scrapedAt, AgeSeconds: 7200, Fresh: true}},
+		},
+		scrapes: []movies.ScrapeRun{{City: "bhubaneswar", StartedAt: time.Now(), Error: "navigation <timeout>"}},
+		paused:  true,
+	}
+	scheduler := &fakeDashboardScheduler{
+		fakeJobScheduler: fakeJobScheduler{statuses: []jobs.Status{{Name: "refresh:cuttack", Runs: 3, Failures: 1}}},
+		leader:           true,
+	}
+
+	recorder := httptest.NewRecorder()
+	testDashboardHandler(t, service, scheduler).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	body := recorder.Body.String()
+	for _, want := range []string{
+		"<td>cuttack</td>",
+		"<td>12</td>",
+		"2h0m0s",
+		"refresh:cuttack",
+		`action="/admin/dashboard/jobs/refresh:cuttack/run"`,
+		"navigation &lt;timeout&gt;",
+		"Scraping is paused",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("dashboard is missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestDashboardRunJobRedirectsWithMessage(t *testing.T) {
+	t.Parallel()
+
+	scheduler := &fakeDashboardScheduler{
+		fakeJobScheduler: fakeJobScheduler{statuses: []jobs.Status{{Name: "cleanup"}}},
+		leader:           true,
+	}
+	handler := testDashboardHandler(t, &fakeAdminService{}, scheduler)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/admin/dashboard/jobs/cleanup/run", nil))
+
+	if recorder.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusSeeOther)
+	}
+
+	if location := recorder.Header().Get("Location"); location != "/admin?message=Queued+cleanup." {
+		t.Fatalf("Location = %q, want dashboard with queued message", location)
+	}
+
+	if len(scheduler.triggered) != 1 || scheduler.triggered[0] != "cleanup" {
+		t.Fatalf("triggered = %v, want [cleanup]", scheduler.triggered)
+	}
+}
+
+func TestGetDashboardRendersSettingsAndAliases(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeAdminService{
+		citySettings: []movies.CitySettings{
+			{City: "cuttack", Disabled: true},
+			{City: "puri", CacheTTL: 6 * time.Hour, StalePolicy: movies.StaleServe},
+		},
+		aliases: []movies.Alias{{Alias: "httyd", Canonical: "How to Train Your Dragon"}},
+	}
+
+	recorder := httptest.NewRecorder()
+	testDashboardHandler(t, service, &fakeDashboardScheduler{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+	body := recorder.Body.String()
+	for _, want := range []string{
+		`action="/admin/dashboard/cities/bhubaneswar"`,
+		`action="/admin/dashboard/cities/cuttack"`,
+		`action="/admin/dashboard/cities/puri"`,
+		`name="cache_ttl" value="6h"`,
+		`<option selected>serve_stale</option>`,
+		`<span class="stale">disabled</span>`,
+		"How to Train Your Dragon",
+		`action="/admin/dashboard/aliases/httyd/delete"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("dashboard is missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestDashboardUpdateCity(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name        string
+		path        string
+		form        string
+		want        []movies.CitySettings
+		wantMessage string
+	}{
+		{
+			name:        "disable",
+			path:        "/admin/dashboard/cities/bbsr",
+			form:        "cache_ttl=",
+			want:        []movies.CitySettings{{City: "bhubaneswar", Disabled: true}},
+			wantMessage: "Saved bhubaneswar.",
+		},
+		{
+			name:        "ttl",
+			path:        "/admin/dashboard/cities/cuttack",
+			form:        "enabled=true&cache_ttl=6h",
+			want:        []movies.CitySettings{{City: "cuttack", CacheTTL: 6 * time.Hour}},
+			wantMessage: "Saved cuttack.",
+		},
+		{
+			name:        "ttl too short",
+			path:        "/admin/dashboard/cities/cuttack",
+			form:        "enabled=true&cache_ttl=5s",
+			wantMessage: "Cache TTL must be a duration of at least 1m, such as 6h.",
+		},
+		{
+			name:        "stale policy",
+			path:        "/admin/dashboard/cities/puri",
+			form:        "enabled=true&stale_policy=serve_stale_and_refresh",
+			want:        []movies.CitySettings{{City: "puri", StalePolicy: movies.StaleServeAndRefresh}},
+			wantMessage: "Saved puri.",
+		},
+		{
+			name:        "unknown stale policy",
+			path:        "/admin/dashboard/cities/puri",
+			form:        "enabled=true&stale_policy=never",
+			wantMessage: `Unknown stale policy "never".`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			service := &fakeAdminService{}
+
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.form))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			recorder := httptest.NewRecorder()
+
+			testDashboardHandler(t, service, &fakeDashboardScheduler{}).ServeHTTP(recorder, req)
+
+			if recorder.Code != http.StatusSeeOther {
+				t.Fatalf("status = %d, want %d", recorder.Code, http.StatusSeeOther)
+			}
+
+			if location := recorder.Header().Get("Location"); location != "/admin?message="+url.QueryEscape(tc.wantMessage) {
+				t.Fatalf("Location = %q, want message %q", location, tc.wantMessage)
+			}
+
+			if !slices.Equal(service.citySettings, tc.want) {
+				t.Fatalf("saved settings = %+v, want %+v", service.citySettings, tc.want)
+			}
+		})
+	}
+}
+
+func TestDashboardAddAlias(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeAdminService{}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/dashboard/aliases", strings.NewReader("alias=HTTYD&canonical=How+to+Train+Your+Dragon"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+
+	testDashboardHandler(t, service, &fakeDashboardScheduler{}).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusSeeOther || len(service.aliases) != 1 || service.aliases[0].Canonical != "How to Train Your Dragon" {
+		t.Fatalf("status = %d, aliases = %+v, want the alias added", recorder.Code, service.aliases)
+	}
+}
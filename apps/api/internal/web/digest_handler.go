@@ -0,0 +1,85 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"go-scraping/internal/digest"
+)
+
+type digestSubscriptions interface {
+	Subscribe(ctx context.Context, email, city string) error
+	Unsubscribe(ctx context.Context, token string) (bool, error)
+}
+
+type subscribeRequest struct {
+	Email string `json:"email" validate:"required,text"`
+	City  string `json:"city" validate:"required,text"`
+}
+
+type unsubscribeParams struct {
+	Token string `query:"token" validate:"required"`
+}
+
+type DigestHandler struct {
+	subscriptions digestSubscriptions
+	logger        *slog.Logger
+}
+
+func RegisterDigestRoutes(mux *http.ServeMux, subscriptions digestSubscriptions, logger *slog.Logger) {
+	handler := &DigestHandler{
+		subscriptions: subscriptions,
+		logger:        logger,
+	}
+
+	mux.Handle("POST /digest/subscriptions", http.HandlerFunc(handler.Subscribe))
+	// The link in each digest is opened with GET; mail clients that support
+	// one-click unsubscribe POST to the same URL.
+	mux.Handle("GET /digest/unsubscribe", http.HandlerFunc(handler.Unsubscribe))
+	mux.Handle("POST /digest/unsubscribe", http.HandlerFunc(handler.Unsubscribe))
+}
+
+func (h *DigestHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	var req subscribeRequest
+	if err := bindJSON(w, r, &req); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	if err := h.subscriptions.Subscribe(r.Context(), req.Email, req.City); err != nil {
+		if errors.Is(err, digest.ErrInvalidEmail) {
+			writeBindError(w, invalidField("email", "email must be a valid address"))
+			return
+		}
+
+		h.logger.ErrorContext(r.Context(), "failed to subscribe to digest", "city", req.City, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to subscribe")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, map[string]string{"status": "subscribed"})
+}
+
+func (h *DigestHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	var params unsubscribeParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	removed, err := h.subscriptions.Unsubscribe(r.Context(), params.Token)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to unsubscribe from digest", "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to unsubscribe")
+		return
+	}
+
+	if !removed {
+		WriteError(w, http.StatusNotFound, "Subscription not found")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{"status": "unsubscribed"})
+}
@@ -0,0 +1,90 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-scraping/internal/digest"
+)
+
+type fakeDigestSubscriptions struct {
+	subscribed []string
+	tokens     map[string]bool
+}
+
+func (f *fakeDigestSubscriptions) Subscribe(_ context.Context, email, city string) error {
+	if !strings.Contains(email, "@") {
+		return fmt.Errorf("%w: %q", digest.ErrInvalidEmail, email)
+	}
+
+	f.subscribed = append(f.subscribed, email+":"+city)
+	return nil
+}
+
+func (f *fakeDigestSubscriptions) Unsubscribe(_ context.Context, token string) (bool, error) {
+	removed := f.tokens[token]
+	delete(f.tokens, token)
+
+	return removed, nil
+}
+
+func testDigestHandler(t *testing.T, subscriptions digestSubscriptions) http.Handler {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	RegisterDigestRoutes(mux, subscriptions, slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestSubscribeToDigest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "valid", body: `{"email": "reader@example.com", "city": "bbsr"}`, wantStatus: http.StatusCreated},
+		{name: "invalid email", body: `{"email": "reader", "city": "bbsr"}`, wantStatus: http.StatusBadRequest},
+		{name: "missing city", body: `{"email": "reader@example.com"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			subscriptions := &fakeDigestSubscriptions{}
+			req := httptest.NewRequest(http.MethodPost, "/digest/subscriptions", strings.NewReader(tt.body))
+			recorder := httptest.NewRecorder()
+
+			testDigestHandler(t, subscriptions).ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestUnsubscribeFromDigestByToken(t *testing.T) {
+	t.Parallel()
+
+	handler := testDigestHandler(t, &fakeDigestSubscriptions{tokens: map[string]bool{"abc": true}})
+
+	for _, want := range []int{http.StatusOK, http.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodGet, "/digest/unsubscribe?token=abc", nil)
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, req)
+
+		if recorder.Code != want {
+			t.Fatalf("status = %d, want %d", recorder.Code, want)
+		}
+	}
+}
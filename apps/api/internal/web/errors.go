@@ -0,0 +1,74 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/flags"
+	"go-scraping/internal/movies"
+)
+
+// serviceError is how an error reported by a service is shown to clients.
+type serviceError struct {
+	err     error
+	status  int
+	code    string
+	message string
+}
+
+// serviceErrors maps the errors clients can act on to their responses. Any
+// other error is reported as an internal error without its details.
+var serviceErrors = []serviceError{
+	{movies.ErrCityDisabled, http.StatusNotFound, "city_disabled", "This city is not available right now"},
+	{movies.ErrCityUnknown, http.StatusBadRequest, "city_unknown", fmt.Sprintf("city must be a registered city or alias, or a city slug of at most %d lowercase letters, digits and hyphens", maxCityLength)},
+	{movies.ErrScrapingPaused, http.StatusServiceUnavailable, "scraping_paused", "Scraping is paused and no cached movies are available"},
+	{movies.ErrScrapingDisabled, http.StatusServiceUnavailable, "scraping_disabled", "This server only serves cached movies and has none for this city yet"},
+	{movies.ErrScrapeBlocked, http.StatusServiceUnavailable, "scrape_blocked", "BookMyShow is refusing requests, try again later"},
+	{movies.ErrScrapeQueueFull, http.StatusServiceUnavailable, "scrape_queue_full", "Too many cities are being scraped right now, try again shortly"},
+	{movies.ErrScrapeEmpty, http.StatusBadGateway, "scrape_empty", "BookMyShow returned no movies for this city"},
+	{movies.ErrScrapeQuarantined, http.StatusServiceUnavailable, "scrape_quarantined", "The latest listings for this city look wrong and are awaiting review"},
+	{movies.ErrShuttingDown, http.StatusServiceUnavailable, "shutting_down", "The server is shutting down"},
+	{movies.ErrSearchLogDisabled, http.StatusNotFound, "search_analytics_disabled", "Search analytics are disabled"},
+	{movies.ErrTrafficLogDisabled, http.StatusNotFound, "traffic_analytics_disabled", "Traffic analytics are disabled"},
+	{movies.ErrMovieNotListed, http.StatusNotFound, "movie_not_listed", "The movie is not listed in this city"},
+	{movies.ErrMovieNeverListed, http.StatusNotFound, "movie_never_listed", "The movie was never listed in this city"},
+	{movies.ErrTitleNotShowing, http.StatusNotFound, "title_not_showing", "The title is not showing in any city"},
+	{movies.ErrTrailerNotFound, http.StatusNotFound, "trailer_not_found", "No trailer was found for this movie"},
+	{movies.ErrTrailersDisabled, http.StatusNotFound, "trailers_disabled", "Trailer lookups are disabled"},
+	{movies.ErrNoMatchingMovies, http.StatusNotFound, "no_matching_movies", "No movies showing in this city match the filters"},
+	{movies.ErrAliasesDisabled, http.StatusNotFound, "aliases_disabled", "Title aliases are disabled"},
+	{movies.ErrTitleVariantsDisabled, http.StatusNotFound, "title_variants_disabled", "Title variants are disabled"},
+	{movies.ErrCitySettingsDisabled, http.StatusNotFound, "city_settings_disabled", "City settings are disabled"},
+	{movies.ErrQuarantineDisabled, http.StatusNotFound, "quarantine_disabled", "Scrape quality checks are disabled"},
+	{cities.ErrNameUsed, http.StatusConflict, "city_exists", "A registered city already uses this name or alias"},
+	{cities.ErrNotListed, http.StatusUnprocessableEntity, "city_not_listed", "BookMyShow lists no movies for this city, check the slug"},
+	{flags.ErrOverridesDisabled, http.StatusNotFound, "flag_overrides_disabled", "Flag overrides are disabled"},
+	{movies.ErrLanguageUnknown, http.StatusBadRequest, "language_unknown", "language must be a language tag such as hi or or-IN"},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, "timeout", "Timed out waiting for BookMyShow"},
+}
+
+// WriteServiceError writes the response for err, returned by a service call.
+// Unmapped errors respond with 500 and fallback as the message.
+func WriteServiceError(w http.ResponseWriter, err error, fallback string) {
+	mapped := lookupServiceError(err, fallback)
+	writeErrorCode(w, mapped.status, mapped.code, mapped.message)
+}
+
+// lookupServiceError returns how err is shown to clients, for responses that
+// are not JSON.
+func lookupServiceError(err error, fallback string) serviceError {
+	for _, mapped := range serviceErrors {
+		if errors.Is(err, mapped.err) {
+			return mapped
+		}
+	}
+
+	return serviceError{err: err, status: http.StatusInternalServerError, code: "internal_error", message: fallback}
+}
+
+func writeErrorCode(w http.ResponseWriter, status int, code, message string) {
+	WriteJSON(w, status, map[string]string{"error": message, "code": code})
+}
@@ -0,0 +1,109 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-scraping/internal/movies"
+)
+
+type eventLister interface {
+	ListEvents(ctx context.Context, city string, after int64, limit int) ([]movies.ListingEvent, error)
+}
+
+type eventsParams struct {
+	City  string `query:"city"`
+	After int64  `query:"after" validate:"min=0"`
+	Limit int    `query:"limit" default:"100" validate:"min=1,max=500"`
+}
+
+type listingEvent struct {
+	ID         int64     `json:"id"`
+	City       string    `json:"city"`
+	Type       string    `json:"type"`
+	MovieID    string    `json:"movie_id"`
+	Title      string    `json:"title"`
+	Href       string    `json:"href"`
+	Year       int       `json:"year,omitempty"`
+	Genres     []string  `json:"genres,omitempty"`
+	Languages  []string  `json:"languages,omitempty"`
+	Cast       []string  `json:"cast,omitempty"`
+	Changed    []string  `json:"changed,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+type eventsResponse struct {
+	Events []listingEvent `json:"events"`
+
+	// After is the cursor for the next page: the ID of the last event
+	// returned, or the request's cursor when there were none.
+	After int64 `json:"after"`
+}
+
+type EventsHandler struct {
+	lister eventLister
+	cities cityRegistry
+	logger *slog.Logger
+}
+
+func RegisterEventRoutes(mux *http.ServeMux, lister eventLister, registry cityRegistry, logger *slog.Logger) {
+	handler := &EventsHandler{
+		lister: lister,
+		cities: registry,
+		logger: logger,
+	}
+
+	mux.Handle("GET /movies/events", http.HandlerFunc(handler.Events))
+}
+
+// Events pages through the append-only log of listing changes, oldest first,
+// for consumers that keep their own copy of the listings up to date. Without
+// a city, every city's events are listed.
+func (h *EventsHandler) Events(w http.ResponseWriter, r *http.Request) {
+	var params eventsParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	var city string
+	if params.City != "" {
+		resolved, err := resolveCityName(h.cities, params.City)
+		if err != nil {
+			WriteServiceError(w, err, "Invalid city")
+			return
+		}
+		city = resolved.Name
+	}
+
+	events, err := h.lister.ListEvents(r.Context(), city, params.After, params.Limit)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list listing events", "city", city, "error", err)
+		WriteServiceError(w, err, "Failed to list listing events")
+		return
+	}
+
+	response := eventsResponse{Events: make([]listingEvent, 0, len(events)), After: params.After}
+	for _, event := range events {
+		eventCity, _ := h.cities.Resolve(event.City)
+		response.Events = append(response.Events, listingEvent{
+			ID:         event.ID,
+			City:       event.City,
+			Type:       event.Type,
+			MovieID:    event.Movie.ID(),
+			Title:      event.Movie.Title,
+			Href:       event.Movie.Href,
+			Year:       event.Movie.Year,
+			Genres:     event.Movie.Genres,
+			Languages:  event.Movie.Languages,
+			Cast:       event.Movie.Cast,
+			Changed:    event.Changed,
+			OccurredAt: event.OccurredAt.In(eventCity.Location()),
+		})
+		response.After = event.ID
+	}
+
+	WriteJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,98 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeEventLister struct {
+	city  string
+	after int64
+	limit int
+}
+
+func (f *fakeEventLister) ListEvents(_ context.Context, city string, after int64, limit int) ([]movies.ListingEvent, error) {
+	f.city, f.after, f.limit = city, after, limit
+
+	return []movies.ListingEvent{
+		{ID: after + 1, City: "bhubaneswar", Type: movies.EventMovieAdded, Movie: movies.Movie{Title: "F1", Href: "/f1"}},
+		{ID: after + 2, City: "bhubaneswar", Type: movies.EventMetadataChanged, Movie: movies.Movie{Title: "F1: The Movie", Href: "/f1"}, Changed: []string{"title"}},
+	}, nil
+}
+
+func testEventsHandler(t *testing.T, lister eventLister) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Timezone: "Asia/Kolkata", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterEventRoutes(mux, lister, registry, slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestEventsPagesFromCursor(t *testing.T) {
+	t.Parallel()
+
+	lister := &fakeEventLister{}
+	recorder := httptest.NewRecorder()
+	testEventsHandler(t, lister).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies/events?city=bbsr&after=40&limit=2", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	if lister.city != "bhubaneswar" || lister.after != 40 || lister.limit != 2 {
+		t.Fatalf("ListEvents() city = %q, after = %d, limit = %d, want bhubaneswar after 40 limit 2", lister.city, lister.after, lister.limit)
+	}
+
+	var response eventsResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if len(response.Events) != 2 || response.Events[1].Type != "metadata_changed" || response.Events[1].Changed[0] != "title" {
+		t.Fatalf("events = %+v, want the added and changed events", response.Events)
+	}
+
+	if response.After != 42 {
+		t.Fatalf("after = %d, want the last event's ID 42", response.After)
+	}
+}
+
+func TestEventsListsEveryCityWithoutCity(t *testing.T) {
+	t.Parallel()
+
+	lister := &fakeEventLister{city: "unset"}
+	recorder := httptest.NewRecorder()
+	testEventsHandler(t, lister).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies/events", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	if lister.city != "" || lister.limit != 100 {
+		t.Fatalf("ListEvents() city = %q, limit = %d, want every city and the default limit", lister.city, lister.limit)
+	}
+}
+
+func TestEventsRejectsInvalidCursor(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	testEventsHandler(t, &fakeEventLister{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies/events?after=latest", nil))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,98 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"go-scraping/internal/flags"
+)
+
+type flagSet interface {
+	List(ctx context.Context) []flags.Flag
+	Override(ctx context.Context, flag flags.Flag) (flags.Flag, error)
+	Reset(ctx context.Context, name string) (bool, error)
+}
+
+type flagRequest struct {
+	Enabled bool     `json:"enabled"`
+	Cities  []string `json:"cities"`
+	Percent int      `json:"percent" validate:"min=0,max=100"`
+}
+
+type FlagsHandler struct {
+	flags  flagSet
+	cities cityRegistry
+	logger *slog.Logger
+}
+
+func RegisterFlagRoutes(mux *http.ServeMux, set flagSet, registry cityRegistry, logger *slog.Logger) {
+	handler := &FlagsHandler{
+		flags:  set,
+		cities: registry,
+		logger: logger,
+	}
+
+	mux.Handle("GET /admin/flags", http.HandlerFunc(handler.ListFlags))
+	mux.Handle("PUT /admin/flags/{name}", http.HandlerFunc(handler.OverrideFlag))
+	mux.Handle("DELETE /admin/flags/{name}", http.HandlerFunc(handler.ResetFlag))
+}
+
+// ListFlags returns the flags in effect, configured or overridden.
+func (h *FlagsHandler) ListFlags(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, h.flags.List(r.Context()))
+}
+
+// OverrideFlag replaces the flag on every replica until it is reset. Cities
+// may be given by alias.
+func (h *FlagsHandler) OverrideFlag(w http.ResponseWriter, r *http.Request) {
+	var req flagRequest
+	if err := bindJSON(w, r, &req); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	flag := flags.Flag{Name: r.PathValue("name"), Enabled: req.Enabled, Percent: req.Percent}
+	for _, name := range req.Cities {
+		city, err := resolveCityName(h.cities, strings.TrimSpace(name))
+		if err != nil {
+			WriteServiceError(w, err, "Invalid city")
+			return
+		}
+		flag.Cities = append(flag.Cities, city.Name)
+	}
+
+	flag, err := h.flags.Override(r.Context(), flag)
+	if errors.Is(err, flags.ErrInvalidFlag) {
+		writeBindError(w, invalidField("name", "%s", err))
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to override flag", "flag", flag.Name, "error", err)
+		WriteServiceError(w, err, "Failed to override flag")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, flag)
+}
+
+// ResetFlag removes the flag's override, so its configuration applies again.
+func (h *FlagsHandler) ResetFlag(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	removed, err := h.flags.Reset(r.Context(), name)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to reset flag", "flag", name, "error", err)
+		WriteServiceError(w, err, "Failed to reset flag")
+		return
+	}
+
+	if !removed {
+		WriteError(w, http.StatusNotFound, "Flag is not overridden")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,104 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/flags"
+)
+
+type fakeFlagSet struct {
+	overridden flags.Flag
+	reset      string
+}
+
+func (f *fakeFlagSet) List(_ context.Context) []flags.Flag {
+	return []flags.Flag{{Name: "search_index", Enabled: true, Percent: 10}}
+}
+
+func (f *fakeFlagSet) Override(_ context.Context, flag flags.Flag) (flags.Flag, error) {
+	if err := flag.Validate(); err != nil {
+		return flags.Flag{}, err
+	}
+
+	f.overridden = flag
+	flag.Overridden = true
+	return flag, nil
+}
+
+func (f *fakeFlagSet) Reset(_ context.Context, name string) (bool, error) {
+	f.reset = name
+	return name == "search_index", nil
+}
+
+func testFlagsHandler(t *testing.T, set flagSet) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterFlagRoutes(mux, set, registry, slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestOverrideFlagResolvesCityAliases(t *testing.T) {
+	t.Parallel()
+
+	set := &fakeFlagSet{}
+	recorder := httptest.NewRecorder()
+	testFlagsHandler(t, set).ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/admin/flags/live_scraping", strings.NewReader(`{"enabled": true, "cities": ["bbsr"], "percent": 50}`)))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+	}
+
+	want := flags.Flag{Name: "live_scraping", Enabled: true, Cities: []string{"bhubaneswar"}, Percent: 50}
+	if !reflect.DeepEqual(set.overridden, want) {
+		t.Fatalf("Override() flag = %+v, want %+v", set.overridden, want)
+	}
+
+	var response flags.Flag
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil || !response.Overridden {
+		t.Fatalf("response = %+v, %v, want the overridden flag", response, err)
+	}
+}
+
+func TestOverrideFlagRejectsInvalidPercent(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	testFlagsHandler(t, &fakeFlagSet{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/admin/flags/search_index", strings.NewReader(`{"enabled": true, "percent": 150}`)))
+
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "percent") {
+		t.Fatalf("status = %d, body = %s, want 400 naming percent", recorder.Code, recorder.Body)
+	}
+}
+
+func TestResetFlagReportsFlagsWithoutOverride(t *testing.T) {
+	t.Parallel()
+
+	handler := testFlagsHandler(t, &fakeFlagSet{})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/admin/flags/search_index", nil))
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusNoContent)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/admin/flags/live_scraping", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+}
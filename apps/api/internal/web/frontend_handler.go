@@ -0,0 +1,74 @@
+package web
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+type FrontendHandler struct {
+	files  fs.FS
+	server http.Handler
+}
+
+// RegisterFrontendRoutes serves the single-page frontend in files from /.
+// Every route registered elsewhere takes precedence, and paths that are not
+// files get index.html, so the frontend's client-side routes can be reloaded
+// and linked to.
+func RegisterFrontendRoutes(mux *http.ServeMux, files fs.FS) {
+	handler := &FrontendHandler{
+		files:  files,
+		server: http.FileServerFS(files),
+	}
+
+	mux.Handle("GET /", handler)
+}
+
+func (h *FrontendHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	if name == "" || name == "index.html" {
+		h.serveIndex(w, r)
+		return
+	}
+
+	info, err := fs.Stat(h.files, name)
+	if err == nil && !info.IsDir() {
+		// Bundlers put a content hash in asset names, so a changed asset
+		// has a new name and the old one can be cached indefinitely.
+		if strings.HasPrefix(name, "assets/") {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		}
+
+		h.server.ServeHTTP(w, r)
+		return
+	}
+
+	// A missing script or image should fail rather than load the page in
+	// its place.
+	if path.Ext(name) != "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	h.serveIndex(w, r)
+}
+
+// serveIndex serves index.html, which browsers must revalidate so that a
+// deploy reaches them on their next visit.
+func (h *FrontendHandler) serveIndex(w http.ResponseWriter, r *http.Request) {
+	index, err := fs.ReadFile(h.files, "index.html")
+	if errors.Is(err, fs.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load the frontend", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(index)
+}
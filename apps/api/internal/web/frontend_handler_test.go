@@ -0,0 +1,66 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func testFrontendHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	RegisterFrontendRoutes(mux, fstest.MapFS{
+		"index.html":         {Data: []byte("<div id=app></div>")},
+		"assets/app-4f2a.js": {Data: []byte("console.log(1)")},
+		"favicon.ico":        {Data: []byte("icon")},
+	})
+
+	return mux
+}
+
+func TestFrontendServesFilesAndFallsBackToIndex(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path         string
+		status       int
+		body         string
+		cacheControl string
+	}{
+		{"/", http.StatusOK, "<div id=app></div>", "no-cache"},
+		{"/index.html", http.StatusOK, "<div id=app></div>", "no-cache"},
+		{"/watchlist/cuttack", http.StatusOK, "<div id=app></div>", "no-cache"},
+		{"/assets/", http.StatusOK, "<div id=app></div>", "no-cache"},
+		{"/assets/app-4f2a.js", http.StatusOK, "console.log(1)", "public, max-age=31536000, immutable"},
+		{"/favicon.ico", http.StatusOK, "icon", ""},
+		{"/assets/missing.js", http.StatusNotFound, "404 page not found\n", ""},
+		{"/health", http.StatusOK, "ok", ""},
+	}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		testFrontendHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+		if recorder.Code != test.status || recorder.Body.String() != test.body {
+			t.Errorf("GET %s = %d %q, want %d %q", test.path, recorder.Code, recorder.Body.String(), test.status, test.body)
+		}
+		if got := recorder.Header().Get("Cache-Control"); got != test.cacheControl {
+			t.Errorf("GET %s Cache-Control = %q, want %q", test.path, got, test.cacheControl)
+		}
+	}
+}
+
+func TestFrontendServesIndexAsHTML(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	testFrontendHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/settings", nil))
+
+	if got := recorder.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+		t.Fatalf("Content-Type = %q, want text/html", got)
+	}
+}
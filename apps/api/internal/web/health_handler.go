@@ -0,0 +1,12 @@
+package web
+
+import "net/http"
+
+// RegisterHealthRoutes registers a liveness check. It answers as soon as the
+// server is listening, without waiting for the database or for preloading,
+// whose progress is reported by the refresh jobs under /admin/jobs.
+func RegisterHealthRoutes(mux *http.ServeMux) {
+	mux.Handle("GET /healthz", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		WriteJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	}))
+}
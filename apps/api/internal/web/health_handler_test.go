@@ -0,0 +1,43 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-scraping/internal/buildinfo"
+)
+
+func TestHealthz(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	RegisterHealthRoutes(mux)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	RegisterVersionRoutes(mux)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	var info buildinfo.Info
+	if err := json.NewDecoder(recorder.Body).Decode(&info); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if recorder.Code != http.StatusOK || info.Version != buildinfo.Version || info.GoVersion == "" {
+		t.Fatalf("GET /version = %d %+v, want version %q and a Go version", recorder.Code, info, buildinfo.Version)
+	}
+}
@@ -0,0 +1,140 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-scraping/internal/movies"
+)
+
+type historyLister interface {
+	History(ctx context.Context, city string, start, end time.Time) ([]movies.Movie, error)
+	MovieRun(ctx context.Context, city, id string) (movies.Run, error)
+}
+
+type historyParams struct {
+	// Date is parsed after the city is known, since the day runs from
+	// midnight to midnight in the city's timezone.
+	Date string `query:"date" validate:"required"`
+}
+
+type historyResponse struct {
+	City   string         `json:"city"`
+	Date   string         `json:"date"`
+	Movies []movies.Movie `json:"movies"`
+}
+
+type runResponse struct {
+	City    string       `json:"city"`
+	Movie   movies.Movie `json:"movie"`
+	From    time.Time    `json:"from"`
+	To      *time.Time   `json:"to,omitempty"`
+	Showing bool         `json:"showing"`
+
+	// Days counts the calendar days in the city's timezone from the first
+	// listing to the last, including both.
+	Days int `json:"days"`
+}
+
+type HistoryHandler struct {
+	lister      historyLister
+	cities      cityRegistry
+	defaultCity string
+	logger      *slog.Logger
+}
+
+func RegisterHistoryRoutes(mux *http.ServeMux, lister historyLister, registry cityRegistry, defaultCity string, logger *slog.Logger) {
+	handler := &HistoryHandler{
+		lister:      lister,
+		cities:      registry,
+		defaultCity: defaultCity,
+		logger:      logger,
+	}
+
+	mux.Handle("GET /history", http.HandlerFunc(handler.History))
+	mux.Handle("GET /movies/{id}/run", http.HandlerFunc(handler.Run))
+}
+
+// History returns the movies a city listed at any time on a past date,
+// replayed from its listing events.
+func (h *HistoryHandler) History(w http.ResponseWriter, r *http.Request) {
+	city, err := resolveCity(r, h.cities, h.defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+
+	var params historyParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	start, err := time.ParseInLocation(time.DateOnly, params.Date, city.Location())
+	if err != nil {
+		writeBindError(w, invalidField("date", "date must be a date such as 2025-07-01"))
+		return
+	}
+	if start.After(time.Now()) {
+		writeBindError(w, invalidField("date", "date must not be in the future"))
+		return
+	}
+
+	list, err := h.lister.History(r.Context(), city.Name, start, start.AddDate(0, 0, 1))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to load listing history", "city", city.Name, "error", err)
+		WriteServiceError(w, err, "Failed to load the listing history")
+		return
+	}
+
+	if list == nil {
+		list = []movies.Movie{}
+	}
+
+	WriteJSON(w, http.StatusOK, historyResponse{City: city.Name, Date: params.Date, Movies: list})
+}
+
+// Run returns when a movie was listed in a city, including movies that are
+// no longer showing.
+func (h *HistoryHandler) Run(w http.ResponseWriter, r *http.Request) {
+	city, err := resolveCity(r, h.cities, h.defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+
+	run, err := h.lister.MovieRun(r.Context(), city.Name, r.PathValue("id"))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to load movie run", "city", city.Name, "error", err)
+		WriteServiceError(w, err, "Failed to load the movie's run")
+		return
+	}
+
+	location := city.Location()
+	response := runResponse{
+		City:    city.Name,
+		Movie:   run.Movie,
+		From:    run.From.In(location),
+		Showing: run.Showing(),
+	}
+
+	last := time.Now()
+	if !run.Showing() {
+		to := run.To.In(location)
+		response.To = &to
+		last = run.To
+	}
+	response.Days = calendarDays(run.From.In(location), last.In(location))
+
+	WriteJSON(w, http.StatusOK, response)
+}
+
+// calendarDays counts the dates from from to to, including both.
+func calendarDays(from, to time.Time) int {
+	first := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int(last.Sub(first).Hours()/24) + 1
+}
@@ -0,0 +1,126 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeHistoryLister struct {
+	city       string
+	start, end time.Time
+	run        movies.Run
+}
+
+func (f *fakeHistoryLister) History(_ context.Context, city string, start, end time.Time) ([]movies.Movie, error) {
+	f.city, f.start, f.end = city, start, end
+
+	return []movies.Movie{{Title: "F1", Href: "/f1"}}, nil
+}
+
+func (f *fakeHistoryLister) MovieRun(_ context.Context, city, id string) (movies.Run, error) {
+	f.city = city
+	if id != f.run.Movie.ID() {
+		return movies.Run{}, movies.ErrMovieNeverListed
+	}
+
+	return f.run, nil
+}
+
+func testHistoryHandler(t *testing.T, lister historyLister) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Timezone: "Asia/Kolkata", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterHistoryRoutes(mux, lister, registry, "bhubaneswar", slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestHistoryCoversTheDateInCityTimezone(t *testing.T) {
+	t.Parallel()
+
+	lister := &fakeHistoryLister{}
+	recorder := httptest.NewRecorder()
+	testHistoryHandler(t, lister).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/history?city=bbsr&date=2025-07-01", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	start := time.Date(2025, 6, 30, 18, 30, 0, 0, time.UTC)
+	if lister.city != "bhubaneswar" || !lister.start.Equal(start) || !lister.end.Equal(start.Add(24*time.Hour)) {
+		t.Fatalf("History() city = %q, from %s to %s, want bhubaneswar's 1 July", lister.city, lister.start, lister.end)
+	}
+
+	var response historyResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.Date != "2025-07-01" || len(response.Movies) != 1 {
+		t.Fatalf("response = %+v, want the date and its movies", response)
+	}
+}
+
+func TestHistoryRejectsMissingAndFutureDates(t *testing.T) {
+	t.Parallel()
+
+	tomorrow := time.Now().AddDate(0, 0, 2).Format(time.DateOnly)
+	for _, target := range []string{"/history", "/history?date=01-07-2025", "/history?date=" + tomorrow} {
+		recorder := httptest.NewRecorder()
+		testHistoryHandler(t, &fakeHistoryLister{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", target, recorder.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestRunReportsDatesListed(t *testing.T) {
+	t.Parallel()
+
+	lister := &fakeHistoryLister{run: movies.Run{
+		Movie: movies.Movie{Title: "Jaws", Href: "https://in.bookmyshow.com/movies/bhubaneswar/jaws/ET00000042"},
+		From:  time.Date(2025, 7, 1, 20, 0, 0, 0, time.UTC),
+		To:    time.Date(2025, 7, 10, 3, 0, 0, 0, time.UTC),
+	}}
+	recorder := httptest.NewRecorder()
+	testHistoryHandler(t, lister).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies/ET00000042/run", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	var response struct {
+		From    string `json:"from"`
+		To      string `json:"to"`
+		Showing bool   `json:"showing"`
+		Days    int    `json:"days"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	// 20:00 UTC on 1 July is 2 July in Bhubaneswar.
+	if response.From != "2025-07-02T01:30:00+05:30" || response.To != "2025-07-10T08:30:00+05:30" || response.Showing || response.Days != 9 {
+		t.Fatalf("response = %+v, want 2 to 10 July, nine days", response)
+	}
+
+	recorder = httptest.NewRecorder()
+	testHistoryHandler(t, lister).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies/ET00000001/run", nil))
+
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+}
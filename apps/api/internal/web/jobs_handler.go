@@ -0,0 +1,56 @@
+package web
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"go-scraping/internal/jobs"
+)
+
+type jobScheduler interface {
+	Statuses() []jobs.Status
+	Trigger(name string) error
+}
+
+type JobsHandler struct {
+	scheduler jobScheduler
+	logger    *slog.Logger
+}
+
+func RegisterJobRoutes(mux *http.ServeMux, scheduler jobScheduler, logger *slog.Logger) {
+	handler := &JobsHandler{
+		scheduler: scheduler,
+		logger:    logger,
+	}
+
+	mux.Handle("GET /admin/jobs", http.HandlerFunc(handler.ListJobs))
+	mux.Handle("POST /admin/jobs/{name}/run", http.HandlerFunc(handler.RunJob))
+}
+
+func (h *JobsHandler) ListJobs(w http.ResponseWriter, _ *http.Request) {
+	WriteJSON(w, http.StatusOK, h.scheduler.Statuses())
+}
+
+func (h *JobsHandler) RunJob(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	if err := h.scheduler.Trigger(name); err != nil {
+		if errors.Is(err, jobs.ErrUnknownJob) {
+			WriteError(w, http.StatusNotFound, "Job not found")
+			return
+		}
+
+		if errors.Is(err, jobs.ErrNotLeader) {
+			WriteError(w, http.StatusConflict, "Jobs run on another replica")
+			return
+		}
+
+		h.logger.ErrorContext(r.Context(), "failed to trigger job", "job", name, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to trigger job")
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "triggered job", "job", name)
+	WriteJSON(w, http.StatusAccepted, map[string]string{"job": name, "status": "queued"})
+}
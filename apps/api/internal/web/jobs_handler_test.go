@@ -0,0 +1,91 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-scraping/internal/jobs"
+)
+
+type fakeJobScheduler struct {
+	statuses  []jobs.Status
+	triggered []string
+}
+
+func (f *fakeJobScheduler) Statuses() []jobs.Status {
+	return f.statuses
+}
+
+func (f *fakeJobScheduler) Trigger(name string) error {
+	for _, status := range f.statuses {
+		if status.Name == name {
+			f.triggered = append(f.triggered, name)
+			return nil
+		}
+	}
+
+	return jobs.ErrUnknownJob
+}
+
+func testJobsHandler(t *testing.T, scheduler jobScheduler) http.Handler {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	RegisterJobRoutes(mux, scheduler, slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestListJobsReturnsStatuses(t *testing.T) {
+	t.Parallel()
+
+	scheduler := &fakeJobScheduler{
+		statuses: []jobs.Status{{Name: "cleanup", Runs: 2, LastError: "db down"}},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/jobs", nil)
+	recorder := httptest.NewRecorder()
+
+	testJobsHandler(t, scheduler).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	var payload []jobs.Status
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if len(payload) != 1 || payload[0].Runs != 2 || payload[0].LastError != "db down" {
+		t.Fatalf("payload = %+v, want cleanup status", payload)
+	}
+}
+
+func TestRunJobTriggersKnownJobs(t *testing.T) {
+	t.Parallel()
+
+	scheduler := &fakeJobScheduler{statuses: []jobs.Status{{Name: "refresh"}}}
+	handler := testJobsHandler(t, scheduler)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/admin/jobs/refresh/run", nil))
+
+	if recorder.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusAccepted)
+	}
+
+	if len(scheduler.triggered) != 1 || scheduler.triggered[0] != "refresh" {
+		t.Fatalf("triggered = %v, want [refresh]", scheduler.triggered)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/admin/jobs/missing/run", nil))
+
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+}
@@ -17,3 +17,13 @@ func WriteJSON(w http.ResponseWriter, status int, payload any) {
 func WriteError(w http.ResponseWriter, status int, message string) {
 	WriteJSON(w, status, map[string]string{"error": message})
 }
+
+// maxRequestBodyBytes caps JSON request bodies accepted by admin endpoints.
+const maxRequestBodyBytes = 1 << 20
+
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(dst)
+}
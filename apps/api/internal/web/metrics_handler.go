@@ -0,0 +1,115 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"go-scraping/internal/backup"
+	"go-scraping/internal/movies"
+)
+
+type statsProvider interface {
+	Stats(ctx context.Context) (movies.CacheStats, error)
+}
+
+// BackupReporter reports the outcome of the most recent database backups.
+type BackupReporter interface {
+	Status() backup.Status
+}
+
+type MetricsHandler struct {
+	stats   statsProvider
+	backups BackupReporter
+	logger  *slog.Logger
+}
+
+// RegisterMetricsRoutes serves the metrics, with backup gauges when backups
+// is not nil.
+func RegisterMetricsRoutes(mux *http.ServeMux, stats statsProvider, backups BackupReporter, logger *slog.Logger) {
+	handler := &MetricsHandler{
+		stats:   stats,
+		backups: backups,
+		logger:  logger,
+	}
+
+	mux.Handle("GET /metrics", http.HandlerFunc(handler.GetMetrics))
+}
+
+// GetMetrics serves per-city data freshness gauges in the Prometheus text
+// exposition format, so staleness can be alerted on even while the process
+// itself looks healthy.
+func (h *MetricsHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.stats.Stats(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to load cache stats", "error", err)
+		http.Error(w, "Failed to load metrics", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetrics(w, stats)
+
+	if h.backups != nil {
+		writeBackupMetrics(w, h.backups.Status())
+	}
+}
+
+func writeMetrics(w io.Writer, stats movies.CacheStats) {
+	fmt.Fprintln(w, "# HELP seconds_since_last_successful_scrape Seconds since the city's movies were last scraped and saved.")
+	fmt.Fprintln(w, "# TYPE seconds_since_last_successful_scrape gauge")
+	for _, city := range stats.Cities {
+		if city.ScrapedAt == nil {
+			continue
+		}
+
+		fmt.Fprintf(w, "seconds_since_last_successful_scrape{city=\"%s\"} %g\n", escapeLabel(city.City), city.AgeSeconds)
+	}
+
+	fmt.Fprintln(w, "# HELP movie_count Movies currently saved for the city.")
+	fmt.Fprintln(w, "# TYPE movie_count gauge")
+	for _, city := range stats.Cities {
+		if city.ScrapedAt == nil {
+			continue
+		}
+
+		fmt.Fprintf(w, "movie_count{city=\"%s\"} %d\n", escapeLabel(city.City), city.MovieCount)
+	}
+}
+
+// writeBackupMetrics writes the backup gauges. Only the replica that ran the
+// backup job has a status to report, so the others leave them out.
+func writeBackupMetrics(w io.Writer, status backup.Status) {
+	if status.LastAttemptAt.IsZero() {
+		return
+	}
+
+	fmt.Fprintln(w, "# HELP backup_last_run_success Whether the most recent database backup succeeded.")
+	fmt.Fprintln(w, "# TYPE backup_last_run_success gauge")
+	success := 0
+	if status.LastError == "" {
+		success = 1
+	}
+	fmt.Fprintf(w, "backup_last_run_success %d\n", success)
+
+	if status.LastSuccessAt.IsZero() {
+		return
+	}
+
+	fmt.Fprintln(w, "# HELP last_successful_backup_timestamp_seconds Unix time of the most recent successful database backup.")
+	fmt.Fprintln(w, "# TYPE last_successful_backup_timestamp_seconds gauge")
+	fmt.Fprintf(w, "last_successful_backup_timestamp_seconds %d\n", status.LastSuccessAt.Unix())
+
+	fmt.Fprintln(w, "# HELP last_backup_size_bytes Size of the most recent successful database backup.")
+	fmt.Fprintln(w, "# TYPE last_backup_size_bytes gauge")
+	fmt.Fprintf(w, "last_backup_size_bytes %d\n", status.LastSizeBytes)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
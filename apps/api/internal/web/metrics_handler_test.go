@@ -0,0 +1,94 @@
+package web
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-scraping/internal/backup"
+	"go-scraping/internal/movies"
+)
+
+func TestGetMetricsExportsFreshnessGauges(t *testing.T) {
+	t.Parallel()
+
+	scrapedAt := time.Now().Add(-90 * time.Second)
+	service := &fakeAdminService{
+		stats: movies.CacheStats{
+			Cities: []movies.CityCacheStats{
+				{City: "cuttack", MovieCount: 12, ScrapedAt: &scrapedAt, AgeSeconds: 90},
+				{City: "puri", Misses: 2},
+			},
+		},
+	}
+
+	mux := http.NewServeMux()
+	RegisterMetricsRoutes(mux, service, nil, slog.New(slog.DiscardHandler))
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	body := recorder.Body.String()
+	for _, want := range []string{
+		"# TYPE seconds_since_last_successful_scrape gauge\n",
+		`seconds_since_last_successful_scrape{city="cuttack"} 90` + "\n",
+		"# TYPE movie_count gauge\n",
+		`movie_count{city="cuttack"} 12` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("metrics are missing %q:\n%s", want, body)
+		}
+	}
+
+	if strings.Contains(body, `city="puri"`) {
+		t.Fatalf("metrics = %q, want no gauges for a city never scraped", body)
+	}
+
+	if strings.Contains(body, "backup") {
+		t.Fatalf("metrics = %q, want no backup gauges with backups disabled", body)
+	}
+}
+
+type fakeBackupReporter struct {
+	status backup.Status
+}
+
+func (f fakeBackupReporter) Status() backup.Status {
+	return f.status
+}
+
+func TestGetMetricsExportsBackupGauges(t *testing.T) {
+	t.Parallel()
+
+	succeededAt := time.Date(2025, 6, 10, 3, 0, 0, 0, time.UTC)
+	backups := fakeBackupReporter{status: backup.Status{
+		LastAttemptAt: succeededAt.Add(24 * time.Hour),
+		LastSuccessAt: succeededAt,
+		LastError:     "pg_dump: connection refused",
+		LastSizeBytes: 2048,
+	}}
+
+	mux := http.NewServeMux()
+	RegisterMetricsRoutes(mux, &fakeAdminService{}, backups, slog.New(slog.DiscardHandler))
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := recorder.Body.String()
+	for _, want := range []string{
+		"backup_last_run_success 0\n",
+		"last_successful_backup_timestamp_seconds 1749524400\n",
+		"last_backup_size_bytes 2048\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("metrics are missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -1,11 +1,39 @@
 package web
 
 import (
-	"log"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"math"
+	"net"
 	"net/http"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
+
+	"go-scraping/internal/alerts"
+	"go-scraping/internal/idempotency"
+	"go-scraping/internal/logging"
+	"go-scraping/internal/movies"
+	"go-scraping/internal/ratelimit"
 )
 
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied request IDs before they are
+// echoed back and written to every log line of the request.
+const maxRequestIDLength = 128
+
 type Middleware func(http.Handler) http.Handler
 
 func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
@@ -16,7 +44,24 @@ func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
 	return handler
 }
 
-func LoggingMiddleware(logger *log.Logger) Middleware {
+// RequestIDMiddleware tags the request context with the client's X-Request-ID,
+// or a generated one, and echoes it in the response.
+func RequestIDMiddleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(requestIDHeader)
+			if requestID == "" || len(requestID) > maxRequestIDLength {
+				requestID = logging.NewID()
+			}
+
+			w.Header().Set(requestIDHeader, requestID)
+
+			next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), requestID)))
+		})
+	}
+}
+
+func LoggingMiddleware(logger *slog.Logger) Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			recorder := &statusRecorder{
@@ -27,19 +72,58 @@ func LoggingMiddleware(logger *log.Logger) Middleware {
 
 			next.ServeHTTP(recorder, r)
 
-			logger.Printf("%s %s %d %s", r.Method, r.URL.RequestURI(), recorder.status, time.Since(startedAt))
+			logger.InfoContext(r.Context(), "request",
+				"method", r.Method,
+				"path", r.URL.RequestURI(),
+				"status", recorder.status,
+				"duration", time.Since(startedAt),
+			)
 		})
 	}
 }
 
-func RecoverMiddleware(logger *log.Logger) Middleware {
+// PanicReporter is told about panics recovered while serving requests.
+type PanicReporter interface {
+	Report(ctx context.Context, p alerts.Panic)
+}
+
+// RecoverMiddleware turns panics into 500 responses in the standard error
+// envelope. Each panic is logged with its stack and, when reporter is
+// non-nil, reported without delaying the response. http.ErrAbortHandler is
+// re-raised so the server aborts the response as intended.
+func RecoverMiddleware(logger *slog.Logger, reporter PanicReporter) Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
-				if recovered := recover(); recovered != nil {
-					logger.Printf("panic while serving %s %s: %v", r.Method, r.URL.RequestURI(), recovered)
-					WriteError(w, http.StatusInternalServerError, "Internal server error")
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				report := alerts.Panic{
+					RequestID: logging.RequestID(r.Context()),
+					Method:    r.Method,
+					Path:      r.URL.Path,
+					Value:     fmt.Sprint(recovered),
+					Stack:     string(debug.Stack()),
+				}
+
+				logger.ErrorContext(r.Context(), "panic while serving request",
+					"method", r.Method,
+					"path", r.URL.RequestURI(),
+					"panic", recovered,
+					"stack", report.Stack,
+				)
+
+				if reporter != nil {
+					go reporter.Report(context.WithoutCancel(r.Context()), report)
 				}
+
+				writeErrorCode(w, http.StatusInternalServerError, "internal_error", "Internal server error")
 			}()
 
 			next.ServeHTTP(w, r)
@@ -47,18 +131,371 @@ func RecoverMiddleware(logger *log.Logger) Middleware {
 	}
 }
 
-func CORSMiddleware() Middleware {
+// CORSOrigins is the set of origins allowed to make cross-origin requests. It
+// can be replaced while the server is running.
+type CORSOrigins struct {
+	origins atomic.Pointer[[]string]
+}
+
+// NewCORSOrigins allows the given origins; "*" allows any origin.
+func NewCORSOrigins(origins []string) *CORSOrigins {
+	allowed := &CORSOrigins{}
+	allowed.Set(origins)
+
+	return allowed
+}
+
+func (o *CORSOrigins) Set(origins []string) {
+	origins = append([]string(nil), origins...)
+	o.origins.Store(&origins)
+}
+
+// allowOrigin returns the Access-Control-Allow-Origin value for a request
+// from origin, or "" if the origin is not allowed.
+func (o *CORSOrigins) allowOrigin(origin string) string {
+	for _, allowed := range *o.origins.Load() {
+		if allowed == "*" {
+			return "*"
+		}
+
+		if origin != "" && allowed == origin {
+			return origin
+		}
+	}
+
+	return ""
+}
+
+func CORSMiddleware(origins *CORSOrigins) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch allowed := origins.allowOrigin(r.Header.Get("Origin")); allowed {
+			case "":
+			case "*":
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			default:
+				w.Header().Set("Access-Control-Allow-Origin", allowed)
+				w.Header().Add("Vary", "Origin")
+			}
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type rateLimiter interface {
+	Allow(ctx context.Context, key string) ratelimit.Decision
+}
+
+// RateLimitMiddleware rejects clients that make more requests than the
+// limiter allows with 429. Requests with a valid access token are limited per
+// user, and the rest per client IP. When trustForwardedFor is set, the IP is
+// the last one in X-Forwarded-For, as added by the reverse proxy in front of
+// the server. Auth may be nil when there are no user accounts. Health checks,
+// metrics scrapes, and CORS preflights are never limited.
+func RateLimitMiddleware(limiter rateLimiter, auth authenticator, trustForwardedFor bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions || r.URL.Path == "/healthz" || r.URL.Path == "/metrics" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			decision := limiter.Allow(r.Context(), rateLimitKey(r, auth, trustForwardedFor))
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+
+			if !decision.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(decision.RetryAfter.Seconds()))))
+				writeErrorCode(w, http.StatusTooManyRequests, "rate_limited", "Too many requests, try again later")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey identifies the client a request counts toward.
+func rateLimitKey(r *http.Request, auth authenticator, trustForwardedFor bool) string {
+	if auth != nil {
+		if token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+			if userID, err := auth.Authenticate(token); err == nil {
+				return "user:" + strconv.FormatInt(userID, 10)
+			}
+		}
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); trustForwardedFor && forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		return "ip:" + strings.TrimSpace(hops[len(hops)-1])
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+// isAdminPath reports whether the path is under the /admin namespace.
+func isAdminPath(path string) bool {
+	return path == "/admin" || strings.HasPrefix(path, "/admin/")
+}
+
+// AdminMiddleware rejects requests to /admin and everything under it unless
+// they carry the admin token, either as "Authorization: Bearer <token>" or as
+// the password of HTTP basic auth, so browsers can open the dashboard. Without
+// a token every admin request is rejected. Browsers resend basic auth
+// credentials on their own, so cross-origin requests that change state are
+// rejected too, keeping other sites from submitting the dashboard's forms.
+// Other paths, and CORS preflights, which carry no credentials, pass through.
+func AdminMiddleware(token string) Middleware {
+	crossOrigin := http.NewCrossOriginProtection()
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type")
+			if !isAdminPath(r.URL.Path) || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if err := crossOrigin.Check(r); err != nil {
+				WriteError(w, http.StatusForbidden, "Cross-origin admin requests are not allowed")
+				return
+			}
+
+			if token == "" {
+				WriteError(w, http.StatusForbidden, "Admin access is disabled; set ADMIN_TOKEN to enable it")
+				return
+			}
+
+			presented, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !found {
+				_, presented, found = r.BasicAuth()
+			}
+
+			if !found || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+				w.Header().Add("WWW-Authenticate", "Bearer")
+				w.Header().Add("WWW-Authenticate", `Basic realm="admin", charset="UTF-8"`)
+				WriteError(w, http.StatusUnauthorized, "Admin token required")
+				return
+			}
 
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength bounds client-supplied idempotency keys, which are
+// stored with their responses.
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyMiddleware answers admin POSTs that are retried with the same
+// Idempotency-Key header with the first response, marked with an
+// Idempotent-Replayed header, instead of running them again. A retry while
+// the first request is still being served gets 409, and reusing a key for a
+// different request gets 422. Server errors are not kept, so retrying after
+// one runs the request again. Failing to save or release a key is logged, since
+// the response has already been written.
+func IdempotencyMiddleware(store idempotency.Store, logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if key == "" || r.Method != http.MethodPost || !strings.HasPrefix(r.URL.Path, "/admin/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > maxIdempotencyKeyLength {
+				writeBindError(w, invalidField(idempotencyKeyHeader, "%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength))
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes))
+			if err != nil {
+				writeBindError(w, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			saved, err := store.Begin(r.Context(), key, idempotencyFingerprint(r, body))
+			switch {
+			case errors.Is(err, idempotency.ErrInProgress):
+				writeErrorCode(w, http.StatusConflict, "idempotency_key_in_use", "A request with this idempotency key is still in progress")
+				return
+			case errors.Is(err, idempotency.ErrKeyReused):
+				writeErrorCode(w, http.StatusUnprocessableEntity, "idempotency_key_reused", "This idempotency key was used for a different request")
+				return
+			case err != nil:
+				logger.ErrorContext(r.Context(), "failed to claim idempotency key", "error", err)
+				WriteError(w, http.StatusInternalServerError, "Failed to check the idempotency key")
+				return
+			case saved != nil:
+				maps.Copy(w.Header(), saved.Header)
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(saved.Status)
+				_, _ = w.Write(saved.Body)
+				return
+			}
+
+			recorder := &replayRecorder{
+				ResponseWriter: w,
+				status:         http.StatusOK,
+				before:         w.Header().Clone(),
+			}
+
+			// The key is released if the handler panics, since its
+			// response is unknown.
+			served := false
+			defer func() {
+				// The client may be gone, but the key should still be
+				// settled for its retries.
+				ctx := context.WithoutCancel(r.Context())
+
+				if !served || recorder.status >= http.StatusInternalServerError {
+					if err := store.Abandon(ctx, key); err != nil {
+						logger.ErrorContext(ctx, "failed to release idempotency key", "error", err)
+					}
+					return
+				}
+
+				err := store.Complete(ctx, key, idempotency.Response{
+					Status: recorder.status,
+					Header: recorder.header,
+					Body:   recorder.body.Bytes(),
+				})
+				if err != nil {
+					logger.ErrorContext(ctx, "failed to save idempotent response", "error", err)
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+			served = true
+		})
+	}
+}
+
+// idempotencyFingerprint identifies what a request asks for, so that a key
+// reused for another request is told apart from a retry.
+func idempotencyFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", r.Method, r.URL.RequestURI())
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replayRecorder keeps a copy of the response a handler writes, with only the
+// headers the handler set, so that it can be replayed.
+type replayRecorder struct {
+	http.ResponseWriter
+	before      http.Header
+	wroteHeader bool
+	status      int
+	header      http.Header
+	body        bytes.Buffer
+}
+
+func (r *replayRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.status = status
+		r.header = make(http.Header)
+		for name, values := range r.ResponseWriter.Header() {
+			if !slices.Equal(r.before[name], values) {
+				r.header[name] = slices.Clone(values)
+			}
+		}
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *replayRecorder) Write(p []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(p)
+
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *replayRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+type requestRecorder interface {
+	RecordRequest(ctx context.Context, event movies.RequestEvent)
+}
+
+type trafficNoteKey struct{}
+
+// trafficNote collects what handlers learn about a request for its traffic
+// event.
+type trafficNote struct {
+	city  string
+	cache string
+}
+
+// TrafficMiddleware records each request served by a route with its status
+// and latency, and the city and cache result its handler noted, but nothing
+// that identifies the client. Requests that match no route are not recorded.
+// It must come last in the chain, since the route is only known from the
+// request that reaches the mux.
+func TrafficMiddleware(recorder requestRecorder) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			note := &trafficNote{}
+			r = r.WithContext(context.WithValue(r.Context(), trafficNoteKey{}, note))
+
+			status := &statusRecorder{
+				ResponseWriter: w,
+				status:         http.StatusOK,
+			}
+			startedAt := time.Now()
+
+			next.ServeHTTP(status, r)
+
+			if r.Pattern == "" {
+				return
+			}
+
+			recorder.RecordRequest(r.Context(), movies.RequestEvent{
+				Endpoint:   r.Pattern,
+				City:       note.city,
+				Cache:      note.cache,
+				Status:     status.status,
+				Latency:    time.Since(startedAt),
+				OccurredAt: startedAt,
+			})
+		})
+	}
+}
+
+// noteCity records the city a request was for in its traffic event.
+func noteCity(ctx context.Context, city string) {
+	if note, ok := ctx.Value(trafficNoteKey{}).(*trafficNote); ok {
+		note.city = city
+	}
+}
+
+// noteCache records whether a request's movies came from the cache in its
+// traffic event.
+func noteCache(ctx context.Context, fromCache bool) {
+	if note, ok := ctx.Value(trafficNoteKey{}).(*trafficNote); ok {
+		note.cache = movies.CacheMiss
+		if fromCache {
+			note.cache = movies.CacheHit
+		}
+	}
+}
+
 type statusRecorder struct {
 	http.ResponseWriter
 	status int
@@ -68,3 +505,9 @@ func (r *statusRecorder) WriteHeader(status int) {
 	r.status = status
 	r.ResponseWriter.WriteHeader(status)
 }
+
+// Unwrap lets http.ResponseController flush streamed responses through the
+// recorder.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -2,26 +2,35 @@ package web
 
 import (
 	"bytes"
-	"log"
+	"context"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
+
+	"go-scraping/internal/alerts"
+	"go-scraping/internal/cities"
+	"go-scraping/internal/idempotency"
+	"go-scraping/internal/logging"
+	"go-scraping/internal/movies"
+	"go-scraping/internal/ratelimit"
 )
 
 func TestLoggingMiddlewareLogsRecoveredPanics(t *testing.T) {
 	t.Parallel()
 
 	var logs bytes.Buffer
-	logger := log.New(&logs, "", 0)
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
 
 	handler := Chain(
 		http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
 			panic("boom")
 		}),
-		CORSMiddleware(),
+		CORSMiddleware(NewCORSOrigins([]string{"*"})),
 		LoggingMiddleware(logger),
-		RecoverMiddleware(logger),
+		RecoverMiddleware(logger, nil),
 	)
 
 	req := httptest.NewRequest(http.MethodGet, "/movies?city=cuttack", nil)
@@ -34,11 +43,382 @@ func TestLoggingMiddlewareLogsRecoveredPanics(t *testing.T) {
 	}
 
 	logOutput := logs.String()
-	if !strings.Contains(logOutput, "panic while serving GET /movies?city=cuttack: boom") {
+	if !strings.Contains(logOutput, `msg="panic while serving request" method=GET path="/movies?city=cuttack" panic=boom`) {
 		t.Fatalf("logs = %q, want panic log", logOutput)
 	}
 
-	if !strings.Contains(logOutput, "GET /movies?city=cuttack 500 ") {
+	if !strings.Contains(logOutput, `msg=request method=GET path="/movies?city=cuttack" status=500 `) {
 		t.Fatalf("logs = %q, want access log with 500", logOutput)
 	}
 }
+
+type fakePanicReporter struct {
+	reported chan alerts.Panic
+}
+
+func (f *fakePanicReporter) Report(_ context.Context, p alerts.Panic) {
+	f.reported <- p
+}
+
+func TestRecoverMiddlewareReportsPanics(t *testing.T) {
+	t.Parallel()
+
+	reporter := &fakePanicReporter{reported: make(chan alerts.Panic, 1)}
+	handler := Chain(
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic("boom")
+		}),
+		RequestIDMiddleware(),
+		RecoverMiddleware(slog.New(slog.DiscardHandler), reporter),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/movies", nil)
+	req.Header.Set("X-Request-ID", "abc123")
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusInternalServerError || !strings.Contains(recorder.Body.String(), `"code":"internal_error"`) {
+		t.Fatalf("response = %d %s, want 500 error envelope", recorder.Code, recorder.Body.String())
+	}
+
+	report := <-reporter.reported
+	if report.RequestID != "abc123" || report.Value != "boom" || !strings.Contains(report.Stack, "goroutine") {
+		t.Fatalf("report = %+v, want request ID, value and stack", report)
+	}
+}
+
+func TestRequestIDMiddlewareTagsRequests(t *testing.T) {
+	t.Parallel()
+
+	var seen string
+	handler := Chain(
+		http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			seen = logging.RequestID(r.Context())
+		}),
+		RequestIDMiddleware(),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/movies", nil)
+	req.Header.Set("X-Request-ID", "abc123")
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, req)
+
+	if seen != "abc123" || recorder.Header().Get("X-Request-ID") != "abc123" {
+		t.Fatalf("request ID = %q, header = %q, want abc123", seen, recorder.Header().Get("X-Request-ID"))
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies", nil))
+
+	if seen == "" || recorder.Header().Get("X-Request-ID") != seen {
+		t.Fatalf("generated request ID = %q, header = %q, want matching non-empty IDs", seen, recorder.Header().Get("X-Request-ID"))
+	}
+}
+
+func TestCORSMiddlewareAllowsConfiguredOrigins(t *testing.T) {
+	t.Parallel()
+
+	origins := NewCORSOrigins([]string{"https://letterboxd.com"})
+	handler := Chain(http.NotFoundHandler(), CORSMiddleware(origins))
+
+	allowOrigin := func(origin string) string {
+		req := httptest.NewRequest(http.MethodOptions, "/movies", nil)
+		req.Header.Set("Origin", origin)
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, req)
+
+		return recorder.Header().Get("Access-Control-Allow-Origin")
+	}
+
+	if got := allowOrigin("https://letterboxd.com"); got != "https://letterboxd.com" {
+		t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, "https://letterboxd.com")
+	}
+
+	if got := allowOrigin("https://example.com"); got != "" {
+		t.Fatalf("Access-Control-Allow-Origin = %q, want none", got)
+	}
+
+	origins.Set([]string{"https://example.com"})
+
+	if got := allowOrigin("https://example.com"); got != "https://example.com" {
+		t.Fatalf("Access-Control-Allow-Origin after Set = %q, want %q", got, "https://example.com")
+	}
+}
+
+type fakeRequestRecorder struct {
+	events []movies.RequestEvent
+}
+
+func (f *fakeRequestRecorder) RecordRequest(_ context.Context, event movies.RequestEvent) {
+	f.events = append(f.events, event)
+}
+
+func TestTrafficMiddlewareRecordsRoutedRequests(t *testing.T) {
+	t.Parallel()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /movies", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := resolveCity(r, registry, "cuttack"); err != nil {
+			WriteServiceError(w, err, "Invalid city")
+			return
+		}
+
+		noteCache(r.Context(), true)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	recorder := &fakeRequestRecorder{}
+	handler := Chain(mux, RequestIDMiddleware(), TrafficMiddleware(recorder))
+
+	for _, target := range []string{"/movies?city=bbsr", "/movies?city=!!", "/missing"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	if len(recorder.events) != 2 {
+		t.Fatalf("events = %+v, want the two routed requests", recorder.events)
+	}
+
+	if got := recorder.events[0]; got.Endpoint != "GET /movies" || got.City != "bhubaneswar" || got.Cache != movies.CacheHit || got.Status != http.StatusNoContent || got.OccurredAt.IsZero() {
+		t.Fatalf("first event = %+v, want a cache hit for bhubaneswar", got)
+	}
+
+	if got := recorder.events[1]; got.City != "" || got.Cache != "" || got.Status != http.StatusBadRequest {
+		t.Fatalf("second event = %+v, want a 400 without city or cache", got)
+	}
+}
+
+type fakeRateLimiter struct {
+	keys []string
+}
+
+func (f *fakeRateLimiter) Allow(_ context.Context, key string) ratelimit.Decision {
+	f.keys = append(f.keys, key)
+	if len(f.keys) > 2 {
+		return ratelimit.Decision{Limit: 2, RetryAfter: 1500 * time.Millisecond}
+	}
+
+	return ratelimit.Decision{Allowed: true, Limit: 2, Remaining: 2 - len(f.keys)}
+}
+
+func TestRateLimitMiddlewareLimitsClients(t *testing.T) {
+	t.Parallel()
+
+	limiter := &fakeRateLimiter{}
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), RateLimitMiddleware(limiter, fakeAccounts{}, true))
+
+	requests := []func(*http.Request){
+		func(r *http.Request) { r.Header.Set("Authorization", "Bearer access") },
+		func(r *http.Request) { r.Header.Set("X-Forwarded-For", "198.51.100.9, 203.0.113.7") },
+		func(r *http.Request) { r.Header.Set("Authorization", "Bearer expired") },
+	}
+
+	var recorder *httptest.ResponseRecorder
+	for _, prepare := range requests {
+		request := httptest.NewRequest(http.MethodGet, "/movies", nil)
+		prepare(request)
+
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+	}
+
+	if want := []string{"user:1", "ip:203.0.113.7", "ip:192.0.2.1"}; strings.Join(limiter.keys, " ") != strings.Join(want, " ") {
+		t.Fatalf("keys = %v, want %v", limiter.keys, want)
+	}
+
+	if recorder.Code != http.StatusTooManyRequests || recorder.Header().Get("Retry-After") != "2" || !strings.Contains(recorder.Body.String(), `"rate_limited"`) {
+		t.Fatalf("third request = %d %v %s, want 429 with Retry-After 2", recorder.Code, recorder.Header(), recorder.Body)
+	}
+
+	health := httptest.NewRecorder()
+	handler.ServeHTTP(health, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if health.Code != http.StatusNoContent || len(limiter.keys) != 3 {
+		t.Fatalf("health check = %d after %d limiter calls, want it served without one", health.Code, len(limiter.keys))
+	}
+}
+
+func TestAdminMiddlewareRequiresTheAdminToken(t *testing.T) {
+	t.Parallel()
+
+	const token = "admin-token-that-is-long-enough-to-use"
+
+	tests := []struct {
+		name    string
+		token   string
+		path    string
+		prepare func(*http.Request)
+		want    int
+	}{
+		{name: "public route", token: token, path: "/movies", want: http.StatusNoContent},
+		{name: "anonymous dashboard", token: token, path: "/admin", want: http.StatusUnauthorized},
+		{name: "anonymous admin route", token: token, path: "/admin/usage", want: http.StatusUnauthorized},
+		{
+			name:    "wrong token",
+			token:   token,
+			path:    "/admin/usage",
+			prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") },
+			want:    http.StatusUnauthorized,
+		},
+		{
+			name:    "bearer token",
+			token:   token,
+			path:    "/admin/usage",
+			prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) },
+			want:    http.StatusNoContent,
+		},
+		{
+			name:    "basic auth password",
+			token:   token,
+			path:    "/admin",
+			prepare: func(r *http.Request) { r.SetBasicAuth("admin", token) },
+			want:    http.StatusNoContent,
+		},
+		{
+			name:    "no token configured",
+			path:    "/admin/usage",
+			prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer ") },
+			want:    http.StatusForbidden,
+		},
+		{name: "lookalike path", token: token, path: "/administrators", want: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}), AdminMiddleware(tt.token))
+
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.prepare != nil {
+				tt.prepare(request)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.want, recorder.Body)
+			}
+
+			if tt.want == http.StatusUnauthorized && len(recorder.Header().Values("WWW-Authenticate")) != 2 {
+				t.Fatalf("WWW-Authenticate = %v, want Bearer and Basic challenges", recorder.Header().Values("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestAdminMiddlewareRejectsCrossSitePosts(t *testing.T) {
+	t.Parallel()
+
+	const token = "admin-token-that-is-long-enough-to-use"
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{name: "cross-site form", headers: map[string]string{"Sec-Fetch-Site": "cross-site"}, want: http.StatusForbidden},
+		{name: "foreign origin", headers: map[string]string{"Origin": "https://evil.example"}, want: http.StatusForbidden},
+		{name: "same origin", headers: map[string]string{"Sec-Fetch-Site": "same-origin"}, want: http.StatusNoContent},
+		{name: "non-browser client", want: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ran := false
+			handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				ran = true
+				w.WriteHeader(http.StatusNoContent)
+			}), AdminMiddleware(token))
+
+			request := httptest.NewRequest(http.MethodPost, "/admin/dashboard/aliases", strings.NewReader("alias=bbsr&city=bhubaneswar"))
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			request.SetBasicAuth("admin", token)
+			for key, value := range tt.headers {
+				request.Header.Set(key, value)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.want, recorder.Body)
+			}
+
+			if ran != (tt.want == http.StatusNoContent) {
+				t.Fatalf("handler ran = %v, want %v", ran, !ran)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareReplaysRetriedAdminPosts(t *testing.T) {
+	t.Parallel()
+
+	runs := 0
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		runs++
+		WriteJSON(w, http.StatusAccepted, map[string]int{"run": runs})
+	}), IdempotencyMiddleware(idempotency.New(time.Hour), slog.New(slog.DiscardHandler)))
+
+	post := func(target, key, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		if key != "" {
+			request.Header.Set("Idempotency-Key", key)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	first := post("/admin/jobs/cleanup/run", "retry-1", "")
+	retry := post("/admin/jobs/cleanup/run", "retry-1", "")
+	if runs != 1 || retry.Code != http.StatusAccepted || retry.Body.String() != first.Body.String() || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("retry = %d %v %s after %d runs, want the first response replayed", retry.Code, retry.Header(), retry.Body, runs)
+	}
+
+	if reused := post("/admin/aliases", "retry-1", `{"alias":"kgf"}`); reused.Code != http.StatusUnprocessableEntity || !strings.Contains(reused.Body.String(), `"idempotency_key_reused"`) {
+		t.Fatalf("reused key = %d %s, want 422 idempotency_key_reused", reused.Code, reused.Body)
+	}
+
+	post("/admin/jobs/cleanup/run", "", "")
+	post("/admin/jobs/cleanup/run", "retry-2", "")
+	if runs != 3 {
+		t.Fatalf("runs = %d, want requests without the key or with a new one to run", runs)
+	}
+}
+
+func TestIdempotencyMiddlewareReleasesKeysAfterServerErrors(t *testing.T) {
+	t.Parallel()
+
+	status := http.StatusServiceUnavailable
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}), IdempotencyMiddleware(idempotency.New(time.Hour), slog.New(slog.DiscardHandler)))
+
+	for _, want := range []int{http.StatusServiceUnavailable, http.StatusNoContent} {
+		request := httptest.NewRequest(http.MethodPost, "/admin/config/reload", nil)
+		request.Header.Set("Idempotency-Key", "reload-1")
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != want || recorder.Header().Get("Idempotent-Replayed") != "" {
+			t.Fatalf("response = %d %v, want %d served by the handler", recorder.Code, recorder.Header(), want)
+		}
+
+		status = http.StatusNoContent
+	}
+}
@@ -3,61 +3,315 @@ package web
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"go-scraping/internal/cities"
 	"go-scraping/internal/movies"
+
+	"golang.org/x/text/language"
 )
 
 type movieLoader interface {
 	Load(ctx context.Context, city string) ([]movies.Movie, bool, error)
+	Search(ctx context.Context, city string, req movies.SearchRequest) (movies.SearchResult, error)
+	Suggest(ctx context.Context, city, prefix string, limit int) ([]movies.Suggestion, error)
+	LocalizeTitles(ctx context.Context, list []movies.Movie, languages []string) []movies.Movie
+	LastModified(ctx context.Context, city string) (time.Time, bool, error)
+}
+
+type cityRegistry interface {
+	Resolve(name string) (cities.City, bool)
+	List() []cities.City
+}
+
+type moviesParams struct {
+	Query     string            `query:"query" validate:"text,maxlen=100"`
+	Fuzziness fuzzinessParam    `query:"fuzziness" default:"auto"`
+	In        searchFieldsParam `query:"in"`
+	Sort      string            `query:"sort" validate:"oneof=buzz"`
+
+	// Group merges a film's separate cards per language into one movie.
+	Group bool `query:"group" default:"true"`
+}
+
+type suggestParams struct {
+	Prefix string `query:"prefix" validate:"required,text,maxlen=100"`
+	Limit  int    `query:"limit" default:"10" validate:"min=1,max=50"`
 }
 
 type MoviesHandler struct {
 	loader      movieLoader
+	cities      cityRegistry
+	preferences PreferenceSource
 	defaultCity string
-	logger      *log.Logger
+	logger      *slog.Logger
 }
 
-func RegisterMovieRoutes(mux *http.ServeMux, loader movieLoader, defaultCity string, logger *log.Logger) {
+// RegisterMovieRoutes registers the movie listing routes. When preferences is
+// non-nil, authenticated /movies requests default to the user's home city and
+// languages.
+func RegisterMovieRoutes(mux *http.ServeMux, loader movieLoader, registry cityRegistry, preferences PreferenceSource, defaultCity string, logger *slog.Logger) {
 	handler := &MoviesHandler{
 		loader:      loader,
+		cities:      registry,
+		preferences: preferences,
 		defaultCity: defaultCity,
 		logger:      logger,
 	}
 
-	mux.Handle("GET /movies", http.HandlerFunc(handler.GetMovies))
+	var getMovies http.Handler = http.HandlerFunc(handler.GetMovies)
+	if preferences != nil {
+		getMovies = OptionalAuth(preferences)(getMovies)
+	}
+
+	mux.Handle("GET /movies", getMovies)
 	mux.Handle("OPTIONS /movies", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusNoContent)
 	}))
+	mux.Handle("GET /suggest", http.HandlerFunc(handler.GetSuggestions))
+	mux.Handle("GET /cities", http.HandlerFunc(handler.ListCities))
 }
 
 func (h *MoviesHandler) GetMovies(w http.ResponseWriter, r *http.Request) {
-	city := r.URL.Query().Get("city")
-	if city == "" {
-		city = h.defaultCity
+	prefs := userPreferences(r, h.preferences, h.logger)
+
+	defaultCity := h.defaultCity
+	if prefs.HomeCity != "" {
+		defaultCity = prefs.HomeCity
 	}
 
-	query := r.URL.Query().Get("query")
+	resolved, err := resolveCity(r, h.cities, defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+	city := resolved.Name
 
-	loadedMovies, fromCache, err := h.loader.Load(r.Context(), city)
+	// An explicit but empty languages parameter disables the preferred
+	// languages.
+	languages := prefs.Languages
+	if r.URL.Query().Has("languages") {
+		languages = parseList(r.URL.Query().Get("languages"))
+	}
+
+	var params moviesParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	titleLanguages, err := parseTitleLanguages(r)
 	if err != nil {
-		h.logger.Printf("Error loading movies for %s: %v", city, err)
-		WriteError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to load movies: %v", err))
+		WriteServiceError(w, err, "Invalid lang")
 		return
 	}
 
-	if fromCache {
-		h.logger.Printf("Returning %d cached movies for city: %s", len(loadedMovies), city)
+	w.Header().Add("Vary", "Accept-Language")
+
+	lastModified, known := h.lastModified(r.Context(), city)
+	if known && notModifiedSince(r, lastModified) {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusNotModified)
+		return
 	}
 
-	if query != "" {
-		loadedMovies = movies.FuzzySearch(loadedMovies, movies.NormalizeQuery(query))
+	ctx, stale := movies.WithStaleNote(r.Context())
+
+	var result movies.SearchResult
+	if params.Query != "" {
+		result, err = h.loader.Search(ctx, city, movies.SearchRequest{
+			Query:     params.Query,
+			Fuzziness: int(params.Fuzziness),
+			Fields:    params.In,
+		})
+	} else {
+		result.Movies, result.FromCache, err = h.loader.Load(ctx, city)
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to load movies", "city", city, "error", err)
+		WriteServiceError(w, err, "Failed to load movies")
+		return
+	}
+
+	noteCache(r.Context(), result.FromCache)
+	if result.FromCache {
+		h.logger.DebugContext(r.Context(), "returning cached movies", "city", city, "movies", len(result.Movies))
+	}
+
+	result.Movies = movies.FilterLanguages(result.Movies, languages)
+	if params.Group {
+		result.Movies = movies.GroupEditions(result.Movies)
+	}
+	result.Movies = h.loader.LocalizeTitles(r.Context(), result.Movies, titleLanguages)
+	if params.Sort == "buzz" {
+		movies.SortByBuzz(result.Movies)
+	}
+
+	// Movies scraped by this request have only now been saved.
+	if !known {
+		lastModified, known = h.lastModified(r.Context(), city)
+	}
+	if known {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
 	}
 
 	WriteJSON(w, http.StatusOK, movies.Response{
-		City:   city,
-		Movies: loadedMovies,
-		Count:  len(loadedMovies),
+		City:        city,
+		DisplayName: resolved.DisplayName,
+		Movies:      result.Movies,
+		Count:       len(result.Movies),
+		DidYouMean:  result.DidYouMean,
+		Stale:       stale.Stale,
+		Facets:      result.Facets,
+	})
+}
+
+// lastModified returns when the city's served movies were scraped. Failures
+// only cost conditional responses, so they are logged rather than returned.
+func (h *MoviesHandler) lastModified(ctx context.Context, city string) (time.Time, bool) {
+	lastModified, known, err := h.loader.LastModified(ctx, city)
+	if err != nil {
+		h.logger.WarnContext(ctx, "failed to look up last scrape", "city", city, "error", err)
+		return time.Time{}, false
+	}
+
+	return lastModified, known
+}
+
+// notModifiedSince reports whether the request's If-Modified-Since covers
+// lastModified, at the header's one-second precision.
+func notModifiedSince(r *http.Request, lastModified time.Time) bool {
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+func (h *MoviesHandler) GetSuggestions(w http.ResponseWriter, r *http.Request) {
+	resolved, err := resolveCity(r, h.cities, h.defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+	city := resolved.Name
+
+	var params suggestParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	suggestions, err := h.loader.Suggest(r.Context(), city, params.Prefix, params.Limit)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to load suggestions", "city", city, "error", err)
+		WriteServiceError(w, err, "Failed to load suggestions")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, movies.SuggestResponse{
+		City:        city,
+		Prefix:      params.Prefix,
+		Suggestions: suggestions,
+		Count:       len(suggestions),
 	})
 }
+
+func (h *MoviesHandler) ListCities(w http.ResponseWriter, _ *http.Request) {
+	registered := h.cities.List()
+
+	WriteJSON(w, http.StatusOK, map[string]any{
+		"cities": registered,
+		"count":  len(registered),
+	})
+}
+
+// fuzzinessParam is the fuzziness parameter: "auto" or a number of typos.
+type fuzzinessParam int
+
+func (p *fuzzinessParam) UnmarshalText(text []byte) error {
+	if string(text) == "auto" {
+		*p = movies.FuzzinessAuto
+		return nil
+	}
+
+	fuzziness, err := strconv.Atoi(string(text))
+	if err != nil || fuzziness < 0 || fuzziness > movies.MaxFuzziness {
+		return fmt.Errorf("must be \"auto\" or an integer between 0 and %d", movies.MaxFuzziness)
+	}
+
+	*p = fuzzinessParam(fuzziness)
+	return nil
+}
+
+// parseTitleLanguages returns the base languages to show titles in, most
+// preferred first, from the lang parameter or else the Accept-Language
+// header. Unparseable headers are ignored, but not parameters.
+func parseTitleLanguages(r *http.Request) ([]string, error) {
+	var tags []string
+	if r.URL.Query().Has("lang") {
+		tags = parseList(r.URL.Query().Get("lang"))
+	} else if header := r.Header.Get("Accept-Language"); header != "" {
+		parsed, _, err := language.ParseAcceptLanguage(header)
+		if err != nil {
+			return nil, nil
+		}
+
+		for _, tag := range parsed {
+			tags = append(tags, tag.String())
+		}
+	}
+
+	var languages []string
+	for _, tag := range tags {
+		base, ok := movies.TitleLanguage(tag)
+		if !ok {
+			return nil, movies.ErrLanguageUnknown
+		}
+
+		if !slices.Contains(languages, base) {
+			languages = append(languages, base)
+		}
+	}
+
+	return languages, nil
+}
+
+// parseList splits a comma-separated parameter, dropping empty values.
+func parseList(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+
+	return values
+}
+
+// searchFieldsParam is the in parameter: the fields to search, without
+// duplicates.
+type searchFieldsParam []string
+
+func (p *searchFieldsParam) UnmarshalText(text []byte) error {
+	var fields searchFieldsParam
+	for _, field := range strings.Split(string(text), ",") {
+		field = strings.TrimSpace(field)
+		if !movies.IsSearchField(field) {
+			return fmt.Errorf("has unknown search field %q, expected any of title, cast, genres, languages", field)
+		}
+
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+
+	*p = fields
+	return nil
+}
@@ -4,24 +4,41 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"io"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"go-scraping/internal/cities"
+	"go-scraping/internal/config"
 	"go-scraping/internal/movies"
 )
 
 type fakeMoviesService struct {
 	loadMovies []movies.Movie
 	fromCache  bool
+	stale      bool
 	err        error
 	loadCity   string
 	loadCalls  int
+	searchReq  movies.SearchRequest
+	minScore   int
+	didYouMean string
+
+	suggestions   []movies.Suggestion
+	suggestPrefix string
+	suggestLimit  int
+
+	titleLanguages []string
+	lastModified   time.Time
 }
 
-func (f *fakeMoviesService) Load(_ context.Context, city string) ([]movies.Movie, bool, error) {
+func (f *fakeMoviesService) Load(ctx context.Context, city string) ([]movies.Movie, bool, error) {
 	f.loadCalls++
 	f.loadCity = city
 
@@ -29,17 +46,66 @@ func (f *fakeMoviesService) Load(_ context.Context, city string) ([]movies.Movie
 		return nil, false, f.err
 	}
 
+	if f.stale {
+		movies.NoteStale(ctx)
+	}
+
 	return append([]movies.Movie(nil), f.loadMovies...), f.fromCache, nil
 }
 
+func (f *fakeMoviesService) Search(ctx context.Context, city string, req movies.SearchRequest) (movies.SearchResult, error) {
+	f.searchReq = req
+
+	loadedMovies, fromCache, err := f.Load(ctx, city)
+	if err != nil {
+		return movies.SearchResult{}, err
+	}
+
+	return movies.SearchResult{
+		Movies:     movies.FuzzySearch(loadedMovies, movies.NormalizeQuery(req.Query), movies.SearchOptions{MinScore: f.minScore, Fuzziness: req.Fuzziness, Fields: req.Fields}),
+		FromCache:  fromCache,
+		DidYouMean: f.didYouMean,
+	}, nil
+}
+
+func (f *fakeMoviesService) Suggest(_ context.Context, city, prefix string, limit int) ([]movies.Suggestion, error) {
+	f.loadCity = city
+	f.suggestPrefix = prefix
+	f.suggestLimit = limit
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return append([]movies.Suggestion(nil), f.suggestions...), nil
+}
+
+func (f *fakeMoviesService) LocalizeTitles(_ context.Context, list []movies.Movie, languages []string) []movies.Movie {
+	f.titleLanguages = languages
+
+	return list
+}
+
+func (f *fakeMoviesService) LastModified(_ context.Context, _ string) (time.Time, bool, error) {
+	return f.lastModified, !f.lastModified.IsZero(), nil
+}
+
 func testHandler(t *testing.T, service movieLoader) http.Handler {
 	t.Helper()
 
 	mux := http.NewServeMux()
-	logger := log.New(io.Discard, "", 0)
-	RegisterMovieRoutes(mux, service, "cuttack", logger)
+	logger := slog.New(slog.DiscardHandler)
+	registry, err := cities.NewRegistry([]cities.City{
+		{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Timezone: "Asia/Kolkata", Aliases: []string{"bbsr"}},
+		{Name: "cuttack", DisplayName: "Cuttack", Timezone: "Asia/Kolkata"},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
 
-	return Chain(mux, CORSMiddleware())
+	RegisterMovieRoutes(mux, service, registry, nil, "cuttack", logger)
+
+	return Chain(mux, CORSMiddleware(NewCORSOrigins([]string{"*"})))
 }
 
 func decodeResponse(t *testing.T, recorder *httptest.ResponseRecorder) movies.Response {
@@ -53,6 +119,28 @@ func decodeResponse(t *testing.T, recorder *httptest.ResponseRecorder) movies.Re
 	return payload
 }
 
+func TestGetMoviesFlagsStaleMovies(t *testing.T) {
+	t.Parallel()
+
+	for _, stale := range []bool{false, true} {
+		service := &fakeMoviesService{
+			loadMovies: []movies.Movie{{Title: "Cached", Href: "/cached"}},
+			stale:      stale,
+		}
+
+		recorder := httptest.NewRecorder()
+		testHandler(t, service).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies?city=cuttack", nil))
+
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+		}
+
+		if got := decodeResponse(t, recorder).Stale; got != stale {
+			t.Fatalf("stale = %v, want %v", got, stale)
+		}
+	}
+}
+
 func TestGetMoviesUsesDefaultCity(t *testing.T) {
 	t.Parallel()
 
@@ -103,6 +191,36 @@ func TestGetMoviesFiltersResultsForQuery(t *testing.T) {
 	}
 }
 
+func TestGetMoviesDropsLooseMatchesForOneLetterQueries(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeMoviesService{
+		minScore: config.Defaults().SearchMinScore,
+		loadMovies: []movies.Movie{
+			{Title: "Elio", Href: "/elio"},
+			{Title: "Superman", Href: "/superman"},
+			{Title: "Jaws", Href: "/jaws"},
+			{Title: "F1: The Movie", Href: "/f1"},
+			{Title: "Ballerina", Href: "/ballerina"},
+			{Title: "How to Train Your Dragon", Href: "/httyd"},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/movies?query=e&fuzziness=2", nil)
+	recorder := httptest.NewRecorder()
+
+	testHandler(t, service).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	payload := decodeResponse(t, recorder)
+	if len(payload.Movies) != 1 || payload.Movies[0].Title != "Elio" {
+		t.Fatalf("movies = %+v, want only Elio, the one title with a word starting with e", payload.Movies)
+	}
+}
+
 func TestGetMoviesReturnsSuccessPayloadShape(t *testing.T) {
 	t.Parallel()
 
@@ -147,8 +265,41 @@ func TestGetMoviesReturnsErrorPayload(t *testing.T) {
 		t.Fatalf("json.Unmarshal() error = %v", err)
 	}
 
-	if payload["error"] != "Failed to load movies: boom" {
-		t.Fatalf("error payload = %q, want %q", payload["error"], "Failed to load movies: boom")
+	if payload["error"] != "Failed to load movies" || payload["code"] != "internal_error" {
+		t.Fatalf("error payload = %v, want internal_error without details", payload)
+	}
+}
+
+func TestGetMoviesMapsServiceErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{err: fmt.Errorf("scrape movies: %w", movies.ErrScrapeBlocked), wantStatus: http.StatusServiceUnavailable, wantCode: "scrape_blocked"},
+		{err: fmt.Errorf("scrape movies: %w", movies.ErrScrapeEmpty), wantStatus: http.StatusBadGateway, wantCode: "scrape_empty"},
+		{err: movies.ErrScrapingPaused, wantStatus: http.StatusServiceUnavailable, wantCode: "scraping_paused"},
+		{err: fmt.Errorf("scrape movies: %w", context.DeadlineExceeded), wantStatus: http.StatusGatewayTimeout, wantCode: "timeout"},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		testHandler(t, &fakeMoviesService{err: tt.err}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies", nil))
+
+		var payload map[string]string
+		if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+
+		if recorder.Code != tt.wantStatus || payload["code"] != tt.wantCode {
+			t.Fatalf("%v: status = %d, code = %q, want %d and %q", tt.err, recorder.Code, payload["code"], tt.wantStatus, tt.wantCode)
+		}
+
+		if strings.Contains(payload["error"], "scrape movies") {
+			t.Fatalf("%v: error message %q leaks the underlying error", tt.err, payload["error"])
+		}
 	}
 }
 
@@ -169,11 +320,498 @@ func TestOptionsMoviesReturnsCORSHeaders(t *testing.T) {
 		t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, "*")
 	}
 
-	if got := recorder.Header().Get("Access-Control-Allow-Methods"); got != "GET, OPTIONS" {
-		t.Fatalf("Access-Control-Allow-Methods = %q, want %q", got, "GET, OPTIONS")
+	if got := recorder.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST, PUT, DELETE, OPTIONS" {
+		t.Fatalf("Access-Control-Allow-Methods = %q, want %q", got, "GET, POST, PUT, DELETE, OPTIONS")
+	}
+
+	if got := recorder.Header().Get("Access-Control-Allow-Headers"); got != "Origin, Content-Type, Authorization" {
+		t.Fatalf("Access-Control-Allow-Headers = %q, want %q", got, "Origin, Content-Type, Authorization")
+	}
+}
+
+func TestGetMoviesPassesFuzzinessToSearch(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeMoviesService{
+		loadMovies: []movies.Movie{{Title: "Oppenheimer", Href: "/oppenheimer"}},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/movies?query=Oppenhiemer&fuzziness=2", nil)
+	recorder := httptest.NewRecorder()
+
+	testHandler(t, service).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	if service.searchReq.Fuzziness != 2 {
+		t.Fatalf("Search() fuzziness = %d, want 2", service.searchReq.Fuzziness)
+	}
+
+	payload := decodeResponse(t, recorder)
+	if len(payload.Movies) != 1 || payload.Movies[0].Title != "Oppenheimer" {
+		t.Fatalf("movies = %+v, want Oppenheimer", payload.Movies)
+	}
+}
+
+func TestGetMoviesRejectsInvalidFuzziness(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeMoviesService{}
+	req := httptest.NewRequest(http.MethodGet, "/movies?query=Oppenheimer&fuzziness=9", nil)
+	recorder := httptest.NewRecorder()
+
+	testHandler(t, service).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+
+	if service.loadCalls != 0 {
+		t.Fatalf("Load() calls = %d, want 0", service.loadCalls)
+	}
+}
+
+func TestGetSuggestionsReturnsCompletions(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeMoviesService{
+		suggestions: []movies.Suggestion{{Title: "Pushpa 2: The Rule", Href: "/pushpa", Popularity: 7}},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/suggest?city=bhubaneswar&prefix=pus&limit=5", nil)
+	recorder := httptest.NewRecorder()
+
+	testHandler(t, service).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	if service.loadCity != "bhubaneswar" || service.suggestPrefix != "pus" || service.suggestLimit != 5 {
+		t.Fatalf("Suggest() args = %q, %q, %d, want bhubaneswar, pus, 5", service.loadCity, service.suggestPrefix, service.suggestLimit)
 	}
 
-	if got := recorder.Header().Get("Access-Control-Allow-Headers"); got != "Origin, Content-Type" {
-		t.Fatalf("Access-Control-Allow-Headers = %q, want %q", got, "Origin, Content-Type")
+	var payload movies.SuggestResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if payload.Count != 1 || payload.Suggestions[0].Popularity != 7 {
+		t.Fatalf("payload = %+v, want one suggestion with popularity 7", payload)
+	}
+}
+
+func TestGetSuggestionsRequiresPrefix(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/suggest?city=cuttack", nil)
+	recorder := httptest.NewRecorder()
+
+	testHandler(t, &fakeMoviesService{}).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetMoviesIncludesDidYouMean(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeMoviesService{
+		loadMovies: []movies.Movie{{Title: "Interstellar", Href: "/interstellar"}},
+		didYouMean: "Interstellar",
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/movies?query=Zzzz", nil)
+	recorder := httptest.NewRecorder()
+
+	testHandler(t, service).ServeHTTP(recorder, req)
+
+	payload := decodeResponse(t, recorder)
+	if payload.Count != 0 || payload.DidYouMean != "Interstellar" {
+		t.Fatalf("payload = %+v, want no movies and did_you_mean Interstellar", payload)
+	}
+}
+
+func TestGetMoviesSearchesRequestedFields(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeMoviesService{
+		loadMovies: []movies.Movie{
+			{Title: "Kalki 2898 AD", Href: "/kalki", Cast: []string{"Prabhas", "Deepika Padukone"}},
+			{Title: "Ballerina", Href: "/ballerina", Cast: []string{"Ana de Armas"}},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/movies?query=prabhas&in=cast,title", nil)
+	recorder := httptest.NewRecorder()
+
+	testHandler(t, service).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	if got := service.searchReq.Fields; len(got) != 2 || got[0] != "cast" || got[1] != "title" {
+		t.Fatalf("Search() fields = %v, want [cast title]", got)
+	}
+
+	payload := decodeResponse(t, recorder)
+	if len(payload.Movies) != 1 || payload.Movies[0].Href != "/kalki" {
+		t.Fatalf("movies = %+v, want Kalki 2898 AD", payload.Movies)
+	}
+}
+
+func TestGetMoviesRejectsUnknownSearchField(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/movies?query=prabhas&in=director", nil)
+	recorder := httptest.NewRecorder()
+
+	testHandler(t, &fakeMoviesService{}).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetMoviesResolvesCityAliases(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeMoviesService{}
+	req := httptest.NewRequest(http.MethodGet, "/movies?city=BBSR", nil)
+	recorder := httptest.NewRecorder()
+
+	testHandler(t, service).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	if service.loadCity != "bhubaneswar" {
+		t.Fatalf("loaded city = %q, want %q", service.loadCity, "bhubaneswar")
+	}
+
+	if payload := decodeResponse(t, recorder); payload.City != "bhubaneswar" || payload.DisplayName != "Bhubaneswar" {
+		t.Fatalf("city = %q, display name = %q, want bhubaneswar and Bhubaneswar", payload.City, payload.DisplayName)
+	}
+}
+
+func TestGetMoviesValidatesParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		target     string
+		wantStatus int
+	}{
+		{target: "/movies?city=puri", wantStatus: http.StatusOK},
+		{target: "/movies?city=navi-mumbai", wantStatus: http.StatusOK},
+		{target: "/movies?city=" + url.QueryEscape(`puri"];alert(1)//`), wantStatus: http.StatusBadRequest},
+		{target: "/movies?city=new+delhi", wantStatus: http.StatusBadRequest},
+		{target: "/movies?city=" + strings.Repeat("a", maxCityLength+1), wantStatus: http.StatusBadRequest},
+		{target: "/movies?query=" + url.QueryEscape("Super\x00man"), wantStatus: http.StatusBadRequest},
+		{target: "/movies?query=" + strings.Repeat("a", 101), wantStatus: http.StatusBadRequest},
+		{target: "/suggest?prefix=" + url.QueryEscape("Su\nper"), wantStatus: http.StatusBadRequest},
+		{target: "/suggest?city=%3Cscript%3E&prefix=Su", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		testHandler(t, &fakeMoviesService{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+		if recorder.Code != tt.wantStatus {
+			t.Fatalf("GET %s status = %d, want %d", tt.target, recorder.Code, tt.wantStatus)
+		}
+	}
+}
+
+func TestGetMoviesDefaultsToUserPreferences(t *testing.T) {
+	t.Parallel()
+
+	registry, err := cities.NewRegistry([]cities.City{
+		{Name: "bhubaneswar", DisplayName: "Bhubaneswar"},
+		{Name: "cuttack", DisplayName: "Cuttack"},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	service := &fakeMoviesService{
+		loadMovies: []movies.Movie{
+			{Title: "Daman", Languages: []string{"Odia"}},
+			{Title: "Thug Life", Languages: []string{"Tamil"}},
+		},
+	}
+
+	mux := http.NewServeMux()
+	RegisterMovieRoutes(mux, service, registry, fakeAccounts{}, "cuttack", slog.New(slog.DiscardHandler))
+
+	tests := []struct {
+		name       string
+		target     string
+		token      string
+		wantStatus int
+		wantCity   string
+		wantCount  int
+	}{
+		{name: "anonymous", target: "/movies", wantStatus: http.StatusOK, wantCity: "cuttack", wantCount: 2},
+		{name: "preferences", target: "/movies", token: "access", wantStatus: http.StatusOK, wantCity: "bhubaneswar", wantCount: 1},
+		{name: "explicit params", target: "/movies?city=cuttack&languages=tamil", token: "access", wantStatus: http.StatusOK, wantCity: "cuttack", wantCount: 1},
+		{name: "languages disabled", target: "/movies?languages=", token: "access", wantStatus: http.StatusOK, wantCity: "bhubaneswar", wantCount: 2},
+		{name: "invalid token", target: "/movies", token: "expired", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, req)
+
+		if recorder.Code != tt.wantStatus {
+			t.Fatalf("%s: status = %d, want %d", tt.name, recorder.Code, tt.wantStatus)
+		}
+
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+
+		if payload := decodeResponse(t, recorder); payload.City != tt.wantCity || payload.Count != tt.wantCount {
+			t.Fatalf("%s: city = %q with %d movies, want %q with %d", tt.name, payload.City, payload.Count, tt.wantCity, tt.wantCount)
+		}
+	}
+}
+
+func TestListCitiesReturnsRegisteredCities(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/cities", nil)
+	recorder := httptest.NewRecorder()
+
+	testHandler(t, &fakeMoviesService{}).ServeHTTP(recorder, req)
+
+	var payload struct {
+		Cities []cities.City `json:"cities"`
+		Count  int           `json:"count"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if payload.Count != 2 || payload.Cities[0].Name != "bhubaneswar" || payload.Cities[0].Timezone != "Asia/Kolkata" {
+		t.Fatalf("payload = %+v, want bhubaneswar and cuttack", payload)
+	}
+}
+
+func TestGetMoviesLocalizesTitles(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name           string
+		target         string
+		acceptLanguage string
+		want           []string
+	}{
+		{name: "accept-language", target: "/movies", acceptLanguage: "hi-IN,hi;q=0.9,en;q=0.8", want: []string{"hi", "en"}},
+		{name: "lang parameter wins", target: "/movies?lang=or,en", acceptLanguage: "hi", want: []string{"or", "en"}},
+		{name: "unparseable header", target: "/movies", acceptLanguage: "!!", want: nil},
+		{name: "none", target: "/movies", want: nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			service := &fakeMoviesService{loadMovies: []movies.Movie{{Title: "Superman", Href: "/superman"}}}
+
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			if tc.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tc.acceptLanguage)
+			}
+			recorder := httptest.NewRecorder()
+
+			testHandler(t, service).ServeHTTP(recorder, req)
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+			}
+
+			if !slices.Equal(service.titleLanguages, tc.want) {
+				t.Fatalf("LocalizeTitles() languages = %v, want %v", service.titleLanguages, tc.want)
+			}
+
+			if !slices.Contains(recorder.Header().Values("Vary"), "Accept-Language") {
+				t.Fatalf("Vary = %v, want Accept-Language", recorder.Header().Values("Vary"))
+			}
+		})
+	}
+}
+
+func TestGetMoviesRejectsUnknownLang(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	testHandler(t, &fakeMoviesService{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies?lang=!!", nil))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetMoviesSortsByBuzz(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeMoviesService{
+		loadMovies: []movies.Movie{
+			{Title: "Quiet", Href: "/quiet"},
+			{Title: "Hyped", Href: "/hyped", Buzz: 450000},
+			{Title: "Known", Href: "/known", Buzz: 1200},
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	testHandler(t, service).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies?sort=buzz", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	var response movies.Response
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	var titles []string
+	for _, movie := range response.Movies {
+		titles = append(titles, movie.Title)
+	}
+
+	if want := []string{"Hyped", "Known", "Quiet"}; !slices.Equal(titles, want) {
+		t.Fatalf("titles = %v, want %v", titles, want)
+	}
+}
+
+func TestGetMoviesGroupsLanguageEditions(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeMoviesService{
+		loadMovies: []movies.Movie{
+			{Title: "Pushpa 2 (Hindi)", Href: "/pushpa-2-hindi"},
+			{Title: "Pushpa 2 (Telugu)", Href: "/pushpa-2-telugu"},
+			{Title: "Stree 2", Href: "/stree-2"},
+		},
+	}
+
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{target: "/movies", want: []string{"Pushpa 2", "Stree 2"}},
+		{target: "/movies?group=false", want: []string{"Pushpa 2 (Hindi)", "Pushpa 2 (Telugu)", "Stree 2"}},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		testHandler(t, service).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d", tt.target, recorder.Code, http.StatusOK)
+		}
+
+		var response movies.Response
+		if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+			t.Fatalf("%s: decode response: %v", tt.target, err)
+		}
+
+		var titles []string
+		for _, movie := range response.Movies {
+			titles = append(titles, movie.Title)
+		}
+
+		if !slices.Equal(titles, tt.want) {
+			t.Fatalf("%s: titles = %v, want %v", tt.target, titles, tt.want)
+		}
+	}
+}
+
+func TestGetMoviesRejectsUnknownSort(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	testHandler(t, &fakeMoviesService{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies?sort=title", nil))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetMoviesHonorsIfModifiedSince(t *testing.T) {
+	t.Parallel()
+
+	scrapedAt := time.Date(2025, 7, 14, 10, 0, 0, 500, time.UTC)
+	lastModified := "Mon, 14 Jul 2025 10:00:00 GMT"
+
+	for _, tc := range []struct {
+		name            string
+		ifModifiedSince string
+		wantStatus      int
+	}{
+		{name: "no header", wantStatus: http.StatusOK},
+		{name: "unchanged", ifModifiedSince: lastModified, wantStatus: http.StatusNotModified},
+		{name: "later", ifModifiedSince: "Tue, 15 Jul 2025 10:00:00 GMT", wantStatus: http.StatusNotModified},
+		{name: "modified", ifModifiedSince: "Mon, 14 Jul 2025 09:59:59 GMT", wantStatus: http.StatusOK},
+		{name: "unparseable", ifModifiedSince: "yesterday", wantStatus: http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			service := &fakeMoviesService{
+				loadMovies:   []movies.Movie{{Title: "Superman", Href: "/superman"}},
+				lastModified: scrapedAt,
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/movies", nil)
+			if tc.ifModifiedSince != "" {
+				req.Header.Set("If-Modified-Since", tc.ifModifiedSince)
+			}
+			recorder := httptest.NewRecorder()
+
+			testHandler(t, service).ServeHTTP(recorder, req)
+
+			if recorder.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tc.wantStatus)
+			}
+
+			if got := recorder.Header().Get("Last-Modified"); got != lastModified {
+				t.Fatalf("Last-Modified = %q, want %q", got, lastModified)
+			}
+
+			wantLoads := 1
+			if tc.wantStatus == http.StatusNotModified {
+				wantLoads = 0
+			}
+
+			if service.loadCalls != wantLoads {
+				t.Fatalf("Load() calls = %d, want %d", service.loadCalls, wantLoads)
+			}
+		})
+	}
+}
+
+func TestGetMoviesOmitsLastModifiedWithoutScrape(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeMoviesService{loadMovies: []movies.Movie{{Title: "Superman", Href: "/superman"}}}
+
+	req := httptest.NewRequest(http.MethodGet, "/movies", nil)
+	req.Header.Set("If-Modified-Since", "Mon, 14 Jul 2025 10:00:00 GMT")
+	recorder := httptest.NewRecorder()
+
+	testHandler(t, service).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Last-Modified") != "" {
+		t.Fatalf("response = %d with Last-Modified %q, want 200 without it", recorder.Code, recorder.Header().Get("Last-Modified"))
 	}
 }
@@ -0,0 +1,142 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"go-scraping/internal/users"
+)
+
+const (
+	oauthStateCookie = "oauth_state"
+	oauthStateMaxAge = 10 * 60
+)
+
+type oauthProvider interface {
+	AuthCodeURL(state string) string
+	Exchange(ctx context.Context, code string) (users.Identity, error)
+}
+
+type identityLogin interface {
+	LoginWithIdentity(ctx context.Context, identity users.Identity) (users.TokenPair, error)
+}
+
+// OAuthHandler signs users in through an OAuth provider. The state parameter
+// is kept in a short-lived cookie so that the callback only accepts codes from
+// sign-ins this browser started.
+type OAuthHandler struct {
+	provider    oauthProvider
+	accounts    identityLogin
+	frontendURL string
+	logger      *slog.Logger
+}
+
+// RegisterGoogleAuthRoutes serves the Google sign-in flow. The callback
+// redirects to frontendURL with the token pair, or an error code, in the
+// fragment; without a frontendURL it responds with the token pair as JSON.
+func RegisterGoogleAuthRoutes(mux *http.ServeMux, google oauthProvider, accounts identityLogin, frontendURL string, logger *slog.Logger) {
+	handler := &OAuthHandler{
+		provider:    google,
+		accounts:    accounts,
+		frontendURL: frontendURL,
+		logger:      logger,
+	}
+
+	mux.Handle("GET /auth/google", http.HandlerFunc(handler.Start))
+	mux.Handle("GET /auth/google/callback", http.HandlerFunc(handler.Callback))
+}
+
+func (h *OAuthHandler) Start(w http.ResponseWriter, r *http.Request) {
+	state := rand.Text()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state,
+		Path:     "/auth/google",
+		MaxAge:   oauthStateMaxAge,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, h.provider.AuthCodeURL(state), http.StatusFound)
+}
+
+func (h *OAuthHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	cookie, err := r.Cookie(oauthStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(query.Get("state"))) != 1 {
+		h.fail(w, r, http.StatusBadRequest, "invalid_state", "Sign-in expired or was not started here")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Path:     "/auth/google",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+
+	// The user declined on the consent page.
+	if query.Get("error") != "" {
+		h.fail(w, r, http.StatusUnauthorized, "access_denied", "Sign-in was cancelled")
+		return
+	}
+
+	identity, err := h.provider.Exchange(r.Context(), query.Get("code"))
+	if errors.Is(err, users.ErrInvalidCredentials) {
+		h.fail(w, r, http.StatusUnauthorized, "invalid_code", "Sign-in code is invalid or expired")
+		return
+	}
+
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to exchange oauth code", "error", err)
+		h.fail(w, r, http.StatusBadGateway, "server_error", "Failed to sign in")
+		return
+	}
+
+	tokens, err := h.accounts.LoginWithIdentity(r.Context(), identity)
+	switch {
+	case errors.Is(err, users.ErrEmailTaken):
+		h.fail(w, r, http.StatusConflict, "account_exists", "An account with this email already exists; log in with its password")
+	case errors.Is(err, users.ErrUnverifiedEmail):
+		h.fail(w, r, http.StatusForbidden, "unverified_email", "The email of this account is not verified")
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "failed to sign in with identity", "provider", identity.Provider, "error", err)
+		h.fail(w, r, http.StatusInternalServerError, "server_error", "Failed to sign in")
+	default:
+		h.succeed(w, r, tokens)
+	}
+}
+
+func (h *OAuthHandler) succeed(w http.ResponseWriter, r *http.Request, tokens users.TokenPair) {
+	if h.frontendURL == "" {
+		WriteJSON(w, http.StatusOK, tokens)
+		return
+	}
+
+	fragment := url.Values{
+		"access_token":  {tokens.AccessToken},
+		"refresh_token": {tokens.RefreshToken},
+		"token_type":    {tokens.TokenType},
+		"expires_in":    {strconv.FormatInt(tokens.ExpiresIn, 10)},
+	}
+
+	http.Redirect(w, r, h.frontendURL+"#"+fragment.Encode(), http.StatusSeeOther)
+}
+
+func (h *OAuthHandler) fail(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	if h.frontendURL == "" {
+		WriteError(w, status, message)
+		return
+	}
+
+	http.Redirect(w, r, h.frontendURL+"#"+url.Values{"error": {code}}.Encode(), http.StatusSeeOther)
+}
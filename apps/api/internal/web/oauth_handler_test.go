@@ -0,0 +1,145 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"go-scraping/internal/users"
+)
+
+type fakeOAuthProvider struct{}
+
+func (fakeOAuthProvider) AuthCodeURL(state string) string {
+	return "https://accounts.example.com/auth?state=" + url.QueryEscape(state)
+}
+
+func (fakeOAuthProvider) Exchange(_ context.Context, code string) (users.Identity, error) {
+	if code != "good-code" {
+		return users.Identity{}, users.ErrInvalidCredentials
+	}
+
+	return users.Identity{Provider: "google", Subject: "1", Email: "ana@example.com", EmailVerified: true}, nil
+}
+
+type fakeIdentityLogin struct{}
+
+func (fakeIdentityLogin) LoginWithIdentity(_ context.Context, _ users.Identity) (users.TokenPair, error) {
+	return users.TokenPair{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer", ExpiresIn: 900}, nil
+}
+
+// startGoogleSignIn follows GET /auth/google and returns its state cookie.
+func startGoogleSignIn(t *testing.T, mux *http.ServeMux) *http.Cookie {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/auth/google", nil))
+
+	if recorder.Code != http.StatusFound {
+		t.Fatalf("GET /auth/google status = %d, want %d", recorder.Code, http.StatusFound)
+	}
+
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != oauthStateCookie {
+		t.Fatalf("GET /auth/google cookies = %v, want the state cookie", cookies)
+	}
+
+	location, _ := url.Parse(recorder.Header().Get("Location"))
+	if location.Query().Get("state") != cookies[0].Value {
+		t.Fatalf("GET /auth/google redirect state = %q, want %q", location.Query().Get("state"), cookies[0].Value)
+	}
+
+	return cookies[0]
+}
+
+func TestGoogleSignInRedirectsToFrontend(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	RegisterGoogleAuthRoutes(mux, fakeOAuthProvider{}, fakeIdentityLogin{}, "https://app.example.com/login", slog.New(slog.DiscardHandler))
+
+	state := startGoogleSignIn(t, mux)
+
+	tests := []struct {
+		name         string
+		query        string
+		cookie       *http.Cookie
+		wantFragment url.Values
+	}{
+		{
+			name:         "success",
+			query:        "code=good-code&state=" + url.QueryEscape(state.Value),
+			cookie:       state,
+			wantFragment: url.Values{"access_token": {"access"}, "refresh_token": {"refresh"}, "token_type": {"Bearer"}, "expires_in": {"900"}},
+		},
+		{
+			name:         "invalid code",
+			query:        "code=bad-code&state=" + url.QueryEscape(state.Value),
+			cookie:       state,
+			wantFragment: url.Values{"error": {"invalid_code"}},
+		},
+		{
+			name:         "denied",
+			query:        "error=access_denied&state=" + url.QueryEscape(state.Value),
+			cookie:       state,
+			wantFragment: url.Values{"error": {"access_denied"}},
+		},
+		{
+			name:         "state mismatch",
+			query:        "code=good-code&state=forged",
+			cookie:       state,
+			wantFragment: url.Values{"error": {"invalid_state"}},
+		},
+		{
+			name:         "missing cookie",
+			query:        "code=good-code&state=" + url.QueryEscape(state.Value),
+			wantFragment: url.Values{"error": {"invalid_state"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/auth/google/callback?"+tt.query, nil)
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, req)
+
+			if recorder.Code != http.StatusSeeOther {
+				t.Fatalf("callback status = %d, want %d", recorder.Code, http.StatusSeeOther)
+			}
+
+			location, _ := url.Parse(recorder.Header().Get("Location"))
+			fragment, _ := url.ParseQuery(location.Fragment)
+			if location.Host != "app.example.com" || fragment.Encode() != tt.wantFragment.Encode() {
+				t.Fatalf("callback redirect = %s, want fragment %s", location, tt.wantFragment.Encode())
+			}
+		})
+	}
+}
+
+func TestGoogleSignInWithoutFrontendRespondsWithJSON(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	RegisterGoogleAuthRoutes(mux, fakeOAuthProvider{}, fakeIdentityLogin{}, "", slog.New(slog.DiscardHandler))
+
+	state := startGoogleSignIn(t, mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/google/callback?code=good-code&state="+url.QueryEscape(state.Value), nil)
+	req.AddCookie(state)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("callback status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+}
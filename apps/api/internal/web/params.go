@@ -0,0 +1,45 @@
+package web
+
+import (
+	"net/http"
+	"regexp"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+const maxCityLength = 64
+
+// citySlug matches BookMyShow city names, which are interpolated into the
+// scraped URL and page script.
+var citySlug = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// resolveCity returns the city named by the request, or defaultCity, with
+// aliases resolved to the city they belong to. Cities outside the registry
+// must be BookMyShow city slugs of lowercase letters, digits and hyphens.
+func resolveCity(r *http.Request, registry cityRegistry, defaultCity string) (cities.City, error) {
+	name := r.URL.Query().Get("city")
+	if name == "" {
+		name = defaultCity
+	}
+
+	city, err := resolveCityName(registry, name)
+	if err != nil {
+		return cities.City{}, err
+	}
+
+	noteCity(r.Context(), city.Name)
+
+	return city, nil
+}
+
+// resolveCityName resolves a city named in a request body the way
+// resolveCity resolves the city parameter.
+func resolveCityName(registry cityRegistry, name string) (cities.City, error) {
+	city, ok := registry.Resolve(name)
+	if !ok && (len(city.Name) > maxCityLength || !citySlug.MatchString(city.Name)) {
+		return cities.City{}, movies.ErrCityUnknown
+	}
+
+	return city, nil
+}
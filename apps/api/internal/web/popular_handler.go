@@ -0,0 +1,104 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-scraping/internal/movies"
+)
+
+type popularLister interface {
+	PopularMovies(ctx context.Context, city string, since time.Time, limit int) ([]movies.PopularMovie, error)
+}
+
+type popularParams struct {
+	Days  int `query:"days" default:"7" validate:"min=1,max=90"`
+	Limit int `query:"limit" default:"10" validate:"min=1,max=50"`
+}
+
+type dayClicks struct {
+	Date   string `json:"date"`
+	Clicks int64  `json:"clicks"`
+}
+
+type popularMovie struct {
+	ID     string      `json:"id"`
+	Title  string      `json:"title"`
+	Href   string      `json:"href"`
+	Clicks int64       `json:"clicks"`
+	Days   []dayClicks `json:"days"`
+}
+
+type popularResponse struct {
+	City   string         `json:"city"`
+	Since  string         `json:"since"`
+	Movies []popularMovie `json:"movies"`
+}
+
+type PopularHandler struct {
+	lister      popularLister
+	cities      cityRegistry
+	defaultCity string
+	logger      *slog.Logger
+}
+
+func RegisterPopularRoutes(mux *http.ServeMux, lister popularLister, registry cityRegistry, defaultCity string, logger *slog.Logger) {
+	handler := &PopularHandler{
+		lister:      lister,
+		cities:      registry,
+		defaultCity: defaultCity,
+		logger:      logger,
+	}
+
+	mux.Handle("GET /analytics/popular", http.HandlerFunc(handler.Popular))
+}
+
+// Popular ranks the city's movies by how often users clicked through their
+// short links to book, over the last days including today, in UTC.
+func (h *PopularHandler) Popular(w http.ResponseWriter, r *http.Request) {
+	city, err := resolveCity(r, h.cities, h.defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+
+	var params popularParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, 1-params.Days)
+
+	popular, err := h.lister.PopularMovies(r.Context(), city.Name, since, params.Limit)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list popular movies", "city", city.Name, "error", err)
+		WriteServiceError(w, err, "Failed to list popular movies")
+		return
+	}
+
+	response := popularResponse{
+		City:   city.Name,
+		Since:  since.Format(time.DateOnly),
+		Movies: make([]popularMovie, 0, len(popular)),
+	}
+
+	for _, movie := range popular {
+		days := make([]dayClicks, 0, len(movie.Days))
+		for _, day := range movie.Days {
+			days = append(days, dayClicks{Date: day.Date, Clicks: day.Clicks})
+		}
+
+		response.Movies = append(response.Movies, popularMovie{
+			ID:     movie.Movie.ID(),
+			Title:  movie.Movie.Title,
+			Href:   movie.Movie.Href,
+			Clicks: movie.Clicks,
+			Days:   days,
+		})
+	}
+
+	WriteJSON(w, http.StatusOK, response)
+}
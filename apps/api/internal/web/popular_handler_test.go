@@ -0,0 +1,101 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakePopularLister struct {
+	city  string
+	since time.Time
+	limit int
+	err   error
+}
+
+func (f *fakePopularLister) PopularMovies(_ context.Context, city string, since time.Time, limit int) ([]movies.PopularMovie, error) {
+	f.city, f.since, f.limit = city, since, limit
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return []movies.PopularMovie{{
+		Movie:  movies.Movie{Title: "F1", Href: "/movies/bhubaneswar/f1/ET00403839"},
+		Clicks: 5,
+		Days:   []movies.DayClicks{{Date: "2025-07-02", Clicks: 3}, {Date: "2025-07-01", Clicks: 2}},
+	}}, nil
+}
+
+func testPopularHandler(t *testing.T, lister popularLister) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterPopularRoutes(mux, lister, registry, "bhubaneswar", slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestPopularRanksClickedMovies(t *testing.T) {
+	t.Parallel()
+
+	lister := &fakePopularLister{}
+	recorder := httptest.NewRecorder()
+	testPopularHandler(t, lister).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/analytics/popular?city=bbsr&days=2", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly)
+	if lister.city != "bhubaneswar" || lister.since.Format(time.DateOnly) != yesterday || lister.limit != 10 {
+		t.Fatalf("PopularMovies() city = %q, since = %s, limit = %d, want bhubaneswar since %s, 10 movies", lister.city, lister.since, lister.limit, yesterday)
+	}
+
+	var response popularResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if response.Since != yesterday || len(response.Movies) != 1 {
+		t.Fatalf("response = %+v, want one movie since %s", response, yesterday)
+	}
+
+	if movie := response.Movies[0]; movie.ID != "ET00403839" || movie.Clicks != 5 || len(movie.Days) != 2 || movie.Days[0].Date != "2025-07-02" {
+		t.Fatalf("movie = %+v, want F1 with 5 clicks over two days", movie)
+	}
+}
+
+func TestPopularReportsErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		target     string
+		err        error
+		wantStatus int
+	}{
+		{target: "/analytics/popular?days=91", wantStatus: http.StatusBadRequest},
+		{target: "/analytics/popular?city=Not%20A%20City!", wantStatus: http.StatusBadRequest},
+		{target: "/analytics/popular", err: movies.ErrSearchLogDisabled, wantStatus: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		testPopularHandler(t, &fakePopularLister{err: test.err}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.target, nil))
+
+		if recorder.Code != test.wantStatus {
+			t.Errorf("GET %s status = %d, want %d", test.target, recorder.Code, test.wantStatus)
+		}
+	}
+}
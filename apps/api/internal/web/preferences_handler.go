@@ -0,0 +1,136 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"go-scraping/internal/users"
+)
+
+// PreferenceSource authenticates users and looks up their preferences, which
+// listings fall back to when the request doesn't say otherwise.
+type PreferenceSource interface {
+	Preferences(ctx context.Context, userID int64) (users.Preferences, error)
+	authenticator
+}
+
+type preferenceService interface {
+	SavePreferences(ctx context.Context, userID int64, prefs users.Preferences) (users.Preferences, error)
+	PreferenceSource
+}
+
+type preferencesResponse struct {
+	HomeCity  string   `json:"home_city" validate:"text"`
+	Languages []string `json:"languages"`
+}
+
+// OptionalAuth makes the user's ID available through UserID when the request
+// carries a valid "Authorization: Bearer" access token. Requests without one
+// pass through anonymously, while invalid tokens are rejected so clients
+// notice an expired session instead of silently losing their preferences.
+func OptionalAuth(auth authenticator) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			RequireAuth(auth)(next).ServeHTTP(w, r)
+		})
+	}
+}
+
+// userPreferences returns the preferences of the authenticated user, or empty
+// preferences for anonymous requests and lookup failures.
+func userPreferences(r *http.Request, source PreferenceSource, logger *slog.Logger) users.Preferences {
+	userID, ok := UserID(r.Context())
+	if !ok || source == nil {
+		return users.Preferences{}
+	}
+
+	prefs, err := source.Preferences(r.Context(), userID)
+	if err != nil {
+		logger.WarnContext(r.Context(), "failed to load preferences", "user_id", userID, "error", err)
+		return users.Preferences{}
+	}
+
+	return prefs
+}
+
+type PreferencesHandler struct {
+	preferences preferenceService
+	cities      cityRegistry
+	logger      *slog.Logger
+}
+
+func RegisterPreferenceRoutes(mux *http.ServeMux, preferences preferenceService, registry cityRegistry, logger *slog.Logger) {
+	handler := &PreferencesHandler{
+		preferences: preferences,
+		cities:      registry,
+		logger:      logger,
+	}
+
+	requireAuth := RequireAuth(preferences)
+
+	mux.Handle("GET /me/preferences", requireAuth(http.HandlerFunc(handler.Get)))
+	mux.Handle("PUT /me/preferences", requireAuth(http.HandlerFunc(handler.Put)))
+}
+
+func (h *PreferencesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, _ := UserID(r.Context())
+
+	prefs, err := h.preferences.Preferences(r.Context(), userID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to load preferences", "user_id", userID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to load preferences")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, newPreferencesResponse(prefs))
+}
+
+func (h *PreferencesHandler) Put(w http.ResponseWriter, r *http.Request) {
+	userID, _ := UserID(r.Context())
+
+	var req preferencesResponse
+	if err := bindJSON(w, r, &req); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	prefs := users.Preferences{Languages: req.Languages}
+	if req.HomeCity != "" {
+		city, ok := h.cities.Resolve(req.HomeCity)
+		if !ok {
+			writeBindError(w, invalidField("home_city", "home_city must be a registered city or alias"))
+			return
+		}
+
+		prefs.HomeCity = city.Name
+	}
+
+	saved, err := h.preferences.SavePreferences(r.Context(), userID, prefs)
+
+	var invalid *users.InvalidInputError
+	switch {
+	case errors.As(err, &invalid):
+		writeBindError(w, invalidField(invalid.Field, "%s", invalid.Reason))
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "failed to save preferences", "user_id", userID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to save preferences")
+	default:
+		WriteJSON(w, http.StatusOK, newPreferencesResponse(saved))
+	}
+}
+
+func newPreferencesResponse(prefs users.Preferences) preferencesResponse {
+	languages := prefs.Languages
+	if languages == nil {
+		languages = []string{}
+	}
+
+	return preferencesResponse{HomeCity: prefs.HomeCity, Languages: languages}
+}
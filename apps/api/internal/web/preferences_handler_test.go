@@ -0,0 +1,93 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/users"
+)
+
+type fakePreferences struct {
+	fakeAccounts
+
+	mu    sync.Mutex
+	prefs users.Preferences
+}
+
+func (f *fakePreferences) Preferences(context.Context, int64) (users.Preferences, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.prefs, nil
+}
+
+func (f *fakePreferences) SavePreferences(_ context.Context, _ int64, prefs users.Preferences) (users.Preferences, error) {
+	if len(prefs.Languages) > 2 {
+		return users.Preferences{}, &users.InvalidInputError{Field: "languages", Reason: "at most 2 languages can be preferred"}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.prefs = prefs
+
+	return prefs, nil
+}
+
+func TestPreferenceRoutes(t *testing.T) {
+	t.Parallel()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterPreferenceRoutes(mux, &fakePreferences{}, registry, slog.New(slog.DiscardHandler))
+
+	serve := func(method, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/me/preferences", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	if recorder := serve(http.MethodGet, "", ""); recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("GET /me/preferences without token status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+
+	if recorder := serve(http.MethodGet, "", "access"); recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"languages":[]`) {
+		t.Fatalf("GET /me/preferences = %d %s, want empty preferences", recorder.Code, recorder.Body.String())
+	}
+
+	for body, wantStatus := range map[string]int{
+		`{"home_city": "atlantis"}`:                    http.StatusBadRequest,
+		`{"languages": ["Odia", "Hindi", "English"]}`:  http.StatusBadRequest,
+		`{"home_city": "bbsr", "languages": ["Odia"]}`: http.StatusOK,
+	} {
+		if recorder := serve(http.MethodPut, body, "access"); recorder.Code != wantStatus {
+			t.Fatalf("PUT /me/preferences %s status = %d, want %d", body, recorder.Code, wantStatus)
+		}
+	}
+
+	var prefs users.Preferences
+	if err := json.NewDecoder(serve(http.MethodGet, "", "access").Body).Decode(&prefs); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if prefs.HomeCity != "bhubaneswar" || len(prefs.Languages) != 1 || prefs.Languages[0] != "Odia" {
+		t.Fatalf("GET /me/preferences = %+v, want bhubaneswar with Odia", prefs)
+	}
+}
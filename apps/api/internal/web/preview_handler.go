@@ -0,0 +1,271 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-scraping/internal/movies"
+	"go-scraping/internal/objectstore"
+)
+
+//go:embed templates/preview.html
+var previewFS embed.FS
+
+var previewTemplate = template.Must(template.ParseFS(previewFS, "templates/preview.html"))
+
+type previewService interface {
+	Load(ctx context.Context, city string) ([]movies.Movie, bool, error)
+	FindMovie(ctx context.Context, city, id string) (movies.Movie, error)
+}
+
+type pageRenderer interface {
+	RenderPNG(ctx context.Context, document string, width, height int) ([]byte, error)
+}
+
+// imageArchive keeps rendered images beyond the in-memory cache, so replicas
+// and restarts reuse them.
+type imageArchive interface {
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Put(ctx context.Context, key string, body io.Reader, size int64) error
+}
+
+// Preview images use the 1.91:1 size Open Graph and Twitter cards display.
+const (
+	previewWidth     = 1200
+	previewHeight    = 630
+	previewMaxMovies = 12
+	previewCacheTTL  = time.Hour
+	previewCacheSize = 100
+)
+
+type previewView struct {
+	CityName string
+	Date     string
+	Movie    *movies.Movie
+	Details  string
+	Movies   []movies.Movie
+	More     int
+}
+
+type PreviewHandler struct {
+	service     previewService
+	renderer    pageRenderer
+	cities      cityRegistry
+	defaultCity string
+	cache       *imageCache
+	archive     imageArchive
+	logger      *slog.Logger
+}
+
+// RegisterPreviewRoutes registers the share image routes. Rendered images are
+// also kept in archive, unless it is nil.
+func RegisterPreviewRoutes(mux *http.ServeMux, service previewService, renderer pageRenderer, registry cityRegistry, defaultCity string, archive imageArchive, logger *slog.Logger) {
+	handler := &PreviewHandler{
+		service:     service,
+		renderer:    renderer,
+		cities:      registry,
+		defaultCity: defaultCity,
+		cache:       newImageCache(previewCacheSize, previewCacheTTL),
+		archive:     archive,
+		logger:      logger,
+	}
+
+	mux.Handle("GET /preview.png", http.HandlerFunc(handler.CityPreview))
+	mux.Handle("GET /movies/{id}/preview.png", http.HandlerFunc(handler.MoviePreview))
+}
+
+// CityPreview renders a social share image of the city's current lineup.
+func (h *PreviewHandler) CityPreview(w http.ResponseWriter, r *http.Request) {
+	city, err := resolveCity(r, h.cities, h.defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+
+	list, fromCache, err := h.service.Load(r.Context(), city.Name)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to load movies for preview", "city", city.Name, "error", err)
+		WriteServiceError(w, err, "Failed to load movies")
+		return
+	}
+	noteCache(r.Context(), fromCache)
+
+	view := previewView{CityName: city.DisplayName, Movies: list}
+	if len(list) > previewMaxMovies {
+		// The last line says how many more there are.
+		view.Movies, view.More = list[:previewMaxMovies-1], len(list)-previewMaxMovies+1
+	}
+
+	h.render(w, r, city.Timezone, view)
+}
+
+// MoviePreview renders a social share image of one movie showing in the
+// city.
+func (h *PreviewHandler) MoviePreview(w http.ResponseWriter, r *http.Request) {
+	city, err := resolveCity(r, h.cities, h.defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+
+	movie, err := h.service.FindMovie(r.Context(), city.Name, r.PathValue("id"))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to find movie for preview", "city", city.Name, "error", err)
+		WriteServiceError(w, err, "Failed to find the movie")
+		return
+	}
+
+	var details []string
+	if movie.Year > 0 {
+		details = append(details, strconv.Itoa(movie.Year))
+	}
+	details = append(details, movie.Genres...)
+	if len(movie.Languages) > 0 {
+		details = append(details, strings.Join(movie.Languages, ", "))
+	}
+
+	h.render(w, r, city.Timezone, previewView{
+		CityName: city.DisplayName,
+		Movie:    &movie,
+		Details:  strings.Join(details, " · "),
+	})
+}
+
+// render draws the view, reusing the image rendered for an identical page
+// within the cache TTL or kept in the archive, since each render starts
+// Chrome.
+func (h *PreviewHandler) render(w http.ResponseWriter, r *http.Request, timezone string, view previewView) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	view.Date = time.Now().In(location).Format("Monday, 2 January 2006")
+
+	var document bytes.Buffer
+	if err := previewTemplate.Execute(&document, view); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to build preview page", "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to render the preview")
+		return
+	}
+
+	key := sha256.Sum256(document.Bytes())
+	image, ok := h.cache.get(key)
+	if !ok {
+		image, ok = h.archived(r.Context(), key)
+	}
+	if !ok {
+		image, err = h.renderer.RenderPNG(r.Context(), document.String(), previewWidth, previewHeight)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "failed to render preview", "error", err)
+			WriteError(w, http.StatusInternalServerError, "Failed to render the preview")
+			return
+		}
+
+		h.archiveImage(r.Context(), key, image)
+	}
+	h.cache.put(key, image)
+
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(image)
+}
+
+// archiveKey returns the archive key of the image rendered from the page with
+// the given hash.
+func archiveKey(key [sha256.Size]byte) string {
+	return "previews/" + hex.EncodeToString(key[:]) + ".png"
+}
+
+// archived returns the image kept in the archive for the page with the given
+// hash. Failing to read the archive only costs a render, so it is logged.
+func (h *PreviewHandler) archived(ctx context.Context, key [sha256.Size]byte) ([]byte, bool) {
+	if h.archive == nil {
+		return nil, false
+	}
+
+	body, err := h.archive.Get(ctx, archiveKey(key))
+	if err != nil {
+		if !errors.Is(err, objectstore.ErrNotFound) {
+			h.logger.WarnContext(ctx, "failed to read archived preview", "error", err)
+		}
+		return nil, false
+	}
+	defer body.Close()
+
+	image, err := io.ReadAll(body)
+	if err != nil {
+		h.logger.WarnContext(ctx, "failed to read archived preview", "error", err)
+		return nil, false
+	}
+
+	return image, true
+}
+
+func (h *PreviewHandler) archiveImage(ctx context.Context, key [sha256.Size]byte, image []byte) {
+	if h.archive == nil {
+		return
+	}
+
+	if err := h.archive.Put(ctx, archiveKey(key), bytes.NewReader(image), int64(len(image))); err != nil {
+		h.logger.WarnContext(ctx, "failed to archive preview", "error", err)
+	}
+}
+
+// imageCache keeps rendered images by the hash of their page for a TTL,
+// dropping the oldest entry once it holds size images.
+type imageCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[[sha256.Size]byte]cachedImage
+}
+
+type cachedImage struct {
+	image      []byte
+	renderedAt time.Time
+}
+
+func newImageCache(size int, ttl time.Duration) *imageCache {
+	return &imageCache{size: size, ttl: ttl, entries: make(map[[sha256.Size]byte]cachedImage)}
+}
+
+func (c *imageCache) get(key [sha256.Size]byte) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Since(entry.renderedAt) > c.ttl {
+		return nil, false
+	}
+
+	return entry.image, true
+}
+
+func (c *imageCache) put(key [sha256.Size]byte, image []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= c.size {
+		var oldest [sha256.Size]byte
+		var oldestAt time.Time
+		for k, entry := range c.entries {
+			if oldestAt.IsZero() || entry.renderedAt.Before(oldestAt) {
+				oldest, oldestAt = k, entry.renderedAt
+			}
+		}
+		delete(c.entries, oldest)
+	}
+
+	c.entries[key] = cachedImage{image: image, renderedAt: time.Now()}
+}
@@ -0,0 +1,152 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+	"go-scraping/internal/objectstore"
+)
+
+type fakePreviewService struct {
+	movies []movies.Movie
+}
+
+func (f *fakePreviewService) Load(_ context.Context, _ string) ([]movies.Movie, bool, error) {
+	return f.movies, true, nil
+}
+
+func (f *fakePreviewService) FindMovie(_ context.Context, _ string, id string) (movies.Movie, error) {
+	for _, movie := range f.movies {
+		if movie.ID() == id {
+			return movie, nil
+		}
+	}
+
+	return movies.Movie{}, movies.ErrMovieNotListed
+}
+
+type fakeRenderer struct {
+	mu        sync.Mutex
+	documents []string
+}
+
+func (f *fakeRenderer) RenderPNG(_ context.Context, document string, width, height int) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.documents = append(f.documents, document)
+	return []byte("png"), nil
+}
+
+func testPreviewHandler(t *testing.T, service previewService, renderer pageRenderer) http.Handler {
+	t.Helper()
+
+	return testArchivingPreviewHandler(t, service, renderer, nil)
+}
+
+func testArchivingPreviewHandler(t *testing.T, service previewService, renderer pageRenderer, archive imageArchive) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "cuttack", DisplayName: "Cuttack", Timezone: "Asia/Kolkata", Aliases: []string{"ctc"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterPreviewRoutes(mux, service, renderer, registry, "cuttack", archive, slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestCityPreviewRendersLineupOnce(t *testing.T) {
+	t.Parallel()
+
+	service := &fakePreviewService{movies: []movies.Movie{{Title: "Daskalia", Href: "/daskalia"}, {Title: "F1: The Movie", Href: "/f1"}}}
+	renderer := &fakeRenderer{}
+	handler := testPreviewHandler(t, service, renderer)
+
+	for range 2 {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/preview.png?city=ctc", nil))
+
+		if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "image/png" || recorder.Body.String() != "png" {
+			t.Fatalf("status = %d, content type = %q, body = %q, want the rendered PNG", recorder.Code, recorder.Header().Get("Content-Type"), recorder.Body.String())
+		}
+	}
+
+	if len(renderer.documents) != 1 {
+		t.Fatalf("renders = %d, want the second request served from the cache", len(renderer.documents))
+	}
+
+	for _, want := range []string{"Cuttack", "Daskalia", "F1: The Movie"} {
+		if !strings.Contains(renderer.documents[0], want) {
+			t.Errorf("rendered page does not contain %q", want)
+		}
+	}
+}
+
+func TestCityPreviewReusesArchivedImages(t *testing.T) {
+	t.Parallel()
+
+	service := &fakePreviewService{movies: []movies.Movie{{Title: "Daskalia", Href: "/daskalia"}}}
+	renderer := &fakeRenderer{}
+	archive := objectstore.NewLocal(t.TempDir())
+
+	// Each handler has its own memory cache, as separate replicas would.
+	for range 2 {
+		recorder := httptest.NewRecorder()
+		testArchivingPreviewHandler(t, service, renderer, archive).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/preview.png", nil))
+
+		if recorder.Code != http.StatusOK || recorder.Body.String() != "png" {
+			t.Fatalf("status = %d, body = %q, want the rendered PNG", recorder.Code, recorder.Body.String())
+		}
+	}
+
+	if len(renderer.documents) != 1 {
+		t.Fatalf("renders = %d, want the second handler served from the archive", len(renderer.documents))
+	}
+
+	objects, err := archive.List(context.Background(), "previews/")
+	if err != nil || len(objects) != 1 {
+		t.Fatalf("archived previews = %v, %v, want one", objects, err)
+	}
+}
+
+func TestMoviePreviewRendersDetails(t *testing.T) {
+	t.Parallel()
+
+	service := &fakePreviewService{movies: []movies.Movie{{
+		Title:     "F1: The Movie",
+		Href:      "https://in.bookmyshow.com/movies/cuttack/f1-the-movie/ET00403839",
+		Year:      2025,
+		Genres:    []string{"Action", "Sports"},
+		Languages: []string{"English", "Hindi"},
+	}}}
+	renderer := &fakeRenderer{}
+	handler := testPreviewHandler(t, service, renderer)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies/ET00403839/preview.png", nil))
+
+	if recorder.Code != http.StatusOK || len(renderer.documents) != 1 {
+		t.Fatalf("status = %d after %d renders, want 200 after one", recorder.Code, len(renderer.documents))
+	}
+
+	if want := "2025 · Action · Sports · English, Hindi"; !strings.Contains(renderer.documents[0], want) {
+		t.Errorf("rendered page does not contain %q", want)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies/ET00000001/preview.png", nil))
+
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+}
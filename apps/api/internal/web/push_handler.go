@@ -0,0 +1,108 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"go-scraping/internal/webpush"
+)
+
+type pushSubscriptions interface {
+	PublicKey() string
+	Subscribe(ctx context.Context, registration webpush.Registration) error
+	Unsubscribe(ctx context.Context, endpoint string) (bool, error)
+}
+
+// pushSubscriptionPayload is a PushSubscription as serialized by the
+// browser's toJSON.
+type pushSubscriptionPayload struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256DH string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// pushSubscribeRequest carries the browser's PushSubscription JSON unchanged,
+// with the city and titles to watch.
+type pushSubscribeRequest struct {
+	Subscription pushSubscriptionPayload `json:"subscription"`
+	City         string                  `json:"city" validate:"required,text"`
+	Titles       []string                `json:"titles"`
+}
+
+type pushUnsubscribeParams struct {
+	Endpoint string `query:"endpoint" validate:"required"`
+}
+
+type PushHandler struct {
+	subscriptions pushSubscriptions
+	logger        *slog.Logger
+}
+
+func RegisterPushRoutes(mux *http.ServeMux, subscriptions pushSubscriptions, logger *slog.Logger) {
+	handler := &PushHandler{
+		subscriptions: subscriptions,
+		logger:        logger,
+	}
+
+	mux.Handle("GET /push/vapid-public-key", http.HandlerFunc(handler.GetPublicKey))
+	mux.Handle("POST /push/subscriptions", http.HandlerFunc(handler.Subscribe))
+	mux.Handle("DELETE /push/subscriptions", http.HandlerFunc(handler.Unsubscribe))
+}
+
+func (h *PushHandler) GetPublicKey(w http.ResponseWriter, _ *http.Request) {
+	WriteJSON(w, http.StatusOK, map[string]string{"public_key": h.subscriptions.PublicKey()})
+}
+
+func (h *PushHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	var req pushSubscribeRequest
+	if err := bindJSON(w, r, &req); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	err := h.subscriptions.Subscribe(r.Context(), webpush.Registration{
+		Endpoint: req.Subscription.Endpoint,
+		P256DH:   req.Subscription.Keys.P256DH,
+		Auth:     req.Subscription.Keys.Auth,
+		City:     req.City,
+		Titles:   req.Titles,
+	})
+	if errors.Is(err, webpush.ErrInvalidSubscription) {
+		writeBindError(w, invalidField("subscription", "%s", err))
+		return
+	}
+
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to save push subscription", "city", req.City, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to subscribe")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, map[string]string{"status": "subscribed"})
+}
+
+func (h *PushHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	var params pushUnsubscribeParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	removed, err := h.subscriptions.Unsubscribe(r.Context(), params.Endpoint)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to delete push subscription", "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to unsubscribe")
+		return
+	}
+
+	if !removed {
+		WriteError(w, http.StatusNotFound, "Subscription not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,78 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-scraping/internal/webpush"
+)
+
+type fakePushSubscriptions struct {
+	registrations []webpush.Registration
+}
+
+func (f *fakePushSubscriptions) PublicKey() string {
+	return "BPublicKey"
+}
+
+func (f *fakePushSubscriptions) Subscribe(_ context.Context, registration webpush.Registration) error {
+	f.registrations = append(f.registrations, registration)
+	return nil
+}
+
+func (f *fakePushSubscriptions) Unsubscribe(_ context.Context, endpoint string) (bool, error) {
+	for i, registration := range f.registrations {
+		if registration.Endpoint == endpoint {
+			f.registrations = append(f.registrations[:i], f.registrations[i+1:]...)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func TestPushSubscriptionLifecycle(t *testing.T) {
+	t.Parallel()
+
+	subscriptions := &fakePushSubscriptions{}
+	mux := http.NewServeMux()
+	RegisterPushRoutes(mux, subscriptions, slog.New(slog.DiscardHandler))
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/push/vapid-public-key", nil))
+
+	var key map[string]string
+	if err := json.NewDecoder(recorder.Body).Decode(&key); err != nil || key["public_key"] != "BPublicKey" {
+		t.Fatalf("public key response = %v (%v), want BPublicKey", key, err)
+	}
+
+	body := `{"subscription": {"endpoint": "https://push.example.com/abc", "keys": {"p256dh": "key", "auth": "secret"}}, "city": "bbsr", "titles": ["F1"]}`
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/push/subscriptions", strings.NewReader(body)))
+
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("subscribe status = %d, want %d", recorder.Code, http.StatusCreated)
+	}
+
+	want := []webpush.Registration{{Endpoint: "https://push.example.com/abc", P256DH: "key", Auth: "secret", City: "bbsr", Titles: []string{"F1"}}}
+	if !reflect.DeepEqual(subscriptions.registrations, want) {
+		t.Fatalf("registrations = %+v, want %+v", subscriptions.registrations, want)
+	}
+
+	for _, wantStatus := range []int{http.StatusNoContent, http.StatusNotFound} {
+		recorder = httptest.NewRecorder()
+		target := "/push/subscriptions?endpoint=" + url.QueryEscape(want[0].Endpoint)
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, target, nil))
+
+		if recorder.Code != wantStatus {
+			t.Fatalf("unsubscribe status = %d, want %d", recorder.Code, wantStatus)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go-scraping/internal/movies"
+
+	"github.com/skip2/go-qrcode"
+)
+
+type movieFinder interface {
+	FindMovie(ctx context.Context, city, id string) (movies.Movie, error)
+}
+
+type qrParams struct {
+	Size int `query:"size" default:"256" validate:"min=64,max=1024"`
+}
+
+type QRHandler struct {
+	finder      movieFinder
+	cities      cityRegistry
+	defaultCity string
+	publicURL   string
+	logger      *slog.Logger
+}
+
+// RegisterQRRoutes registers the QR code route. Codes link to publicURL, or to
+// the requested host when it is empty.
+func RegisterQRRoutes(mux *http.ServeMux, finder movieFinder, registry cityRegistry, defaultCity, publicURL string, logger *slog.Logger) {
+	handler := &QRHandler{
+		finder:      finder,
+		cities:      registry,
+		defaultCity: defaultCity,
+		publicURL:   strings.TrimSuffix(publicURL, "/"),
+		logger:      logger,
+	}
+
+	mux.Handle("GET /movies/{id}/qr.png", http.HandlerFunc(handler.QRCode))
+}
+
+// QRCode returns a PNG QR code of the movie's short link, for signage that
+// shows current listings. Scanning it goes through the short link, so it
+// keeps working after a re-scrape and is counted as a click.
+func (h *QRHandler) QRCode(w http.ResponseWriter, r *http.Request) {
+	city, err := resolveCity(r, h.cities, h.defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+
+	var params qrParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	movie, err := h.finder.FindMovie(r.Context(), city.Name, r.PathValue("id"))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to find movie for QR code", "city", city.Name, "error", err)
+		WriteServiceError(w, err, "Failed to find the movie")
+		return
+	}
+
+	link := h.baseURL(r) + "/go/" + url.PathEscape(movie.ID()) + "?" + url.Values{"city": {city.Name}}.Encode()
+
+	png, err := qrcode.Encode(link, qrcode.Medium, params.Size)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to encode QR code", "link", link, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to generate the QR code")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(png)
+}
+
+func (h *QRHandler) baseURL(r *http.Request) string {
+	if h.publicURL != "" {
+		return h.publicURL
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host
+}
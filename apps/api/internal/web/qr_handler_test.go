@@ -0,0 +1,106 @@
+package web
+
+import (
+	"context"
+	"image/png"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeMovieFinder struct {
+	city string
+	id   string
+}
+
+func (f *fakeMovieFinder) FindMovie(_ context.Context, city, id string) (movies.Movie, error) {
+	f.city, f.id = city, id
+	if id != "ET00403839" {
+		return movies.Movie{}, movies.ErrMovieNotListed
+	}
+
+	return movies.Movie{Title: "F1: The Movie", Href: "https://in.bookmyshow.com/movies/bhubaneswar/f1-the-movie/ET00403839"}, nil
+}
+
+func testQRHandler(t *testing.T, finder movieFinder) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterQRRoutes(mux, finder, registry, "cuttack", "https://api.example.com/", slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestQRCodeRendersPNG(t *testing.T) {
+	t.Parallel()
+
+	finder := &fakeMovieFinder{}
+	recorder := httptest.NewRecorder()
+	testQRHandler(t, finder).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies/ET00403839/qr.png?city=bbsr&size=128", nil))
+
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("status = %d, content type = %q, want a 200 PNG", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+
+	if finder.city != "bhubaneswar" || finder.id != "ET00403839" {
+		t.Fatalf("FindMovie() city = %q, id = %q, want bhubaneswar and ET00403839", finder.city, finder.id)
+	}
+
+	image, err := png.Decode(recorder.Body)
+	if err != nil {
+		t.Fatalf("decode PNG: %v", err)
+	}
+
+	if bounds := image.Bounds(); bounds.Dx() != 128 || bounds.Dy() != 128 {
+		t.Fatalf("image size = %dx%d, want 128x128", bounds.Dx(), bounds.Dy())
+	}
+}
+
+func TestQRCodeRejectsBadRequests(t *testing.T) {
+	t.Parallel()
+
+	handler := testQRHandler(t, &fakeMovieFinder{})
+
+	tests := []struct {
+		target     string
+		wantStatus int
+	}{
+		{target: "/movies/ET00403839/qr.png?size=5000", wantStatus: http.StatusBadRequest},
+		{target: "/movies/ET00000001/qr.png", wantStatus: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.target, nil))
+
+		if recorder.Code != test.wantStatus {
+			t.Errorf("GET %s status = %d, want %d", test.target, recorder.Code, test.wantStatus)
+		}
+	}
+}
+
+func TestQRCodeLinksToShortLink(t *testing.T) {
+	t.Parallel()
+
+	handler := &QRHandler{publicURL: ""}
+	request := httptest.NewRequest(http.MethodGet, "/movies/ET00403839/qr.png", nil)
+	request.Host = "now-screening.example.com"
+
+	if got := handler.baseURL(request); got != "http://now-screening.example.com" {
+		t.Fatalf("baseURL() = %q, want the request host", got)
+	}
+
+	handler.publicURL = "https://api.example.com"
+	if got := handler.baseURL(request); got != "https://api.example.com" {
+		t.Fatalf("baseURL() = %q, want the public URL", got)
+	}
+}
@@ -0,0 +1,69 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"go-scraping/internal/movies"
+)
+
+type randomPicker interface {
+	RandomMovie(ctx context.Context, city string, filter movies.MovieFilter) (movies.Movie, error)
+}
+
+type randomParams struct {
+	Languages []string `query:"language"`
+	Genres    []string `query:"genre"`
+}
+
+type randomResponse struct {
+	City  string       `json:"city"`
+	Movie movies.Movie `json:"movie"`
+}
+
+type RandomHandler struct {
+	picker      randomPicker
+	cities      cityRegistry
+	defaultCity string
+	logger      *slog.Logger
+}
+
+func RegisterRandomRoutes(mux *http.ServeMux, picker randomPicker, registry cityRegistry, defaultCity string, logger *slog.Logger) {
+	handler := &RandomHandler{
+		picker:      picker,
+		cities:      registry,
+		defaultCity: defaultCity,
+		logger:      logger,
+	}
+
+	mux.Handle("GET /movies/random", http.HandlerFunc(handler.Random))
+}
+
+// Random returns one movie showing in the city, for clients that can't decide
+// what to watch. The language and genre parameters take comma-separated
+// values, and a movie matches if it has any of them.
+func (h *RandomHandler) Random(w http.ResponseWriter, r *http.Request) {
+	city, err := resolveCity(r, h.cities, h.defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+
+	var params randomParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	filter := movies.MovieFilter{Languages: params.Languages, Genres: params.Genres}
+
+	movie, err := h.picker.RandomMovie(r.Context(), city.Name, filter)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to pick a random movie", "city", city.Name, "error", err)
+		WriteServiceError(w, err, "Failed to pick a movie")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, randomResponse{City: city.Name, Movie: movie})
+}
@@ -0,0 +1,88 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeRandomPicker struct {
+	city   string
+	filter movies.MovieFilter
+	err    error
+}
+
+func (f *fakeRandomPicker) RandomMovie(_ context.Context, city string, filter movies.MovieFilter) (movies.Movie, error) {
+	f.city, f.filter = city, filter
+	if f.err != nil {
+		return movies.Movie{}, f.err
+	}
+
+	return movies.Movie{Title: "Daskalia", Href: "/daskalia"}, nil
+}
+
+func testRandomHandler(t *testing.T, picker randomPicker) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterRandomRoutes(mux, picker, registry, "cuttack", slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestRandomPassesFilters(t *testing.T) {
+	t.Parallel()
+
+	picker := &fakeRandomPicker{}
+	recorder := httptest.NewRecorder()
+	testRandomHandler(t, picker).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies/random?city=bbsr&language=Odia,%20Hindi&genre=drama", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	if picker.city != "bhubaneswar" || !slices.Equal(picker.filter.Languages, []string{"Odia", "Hindi"}) || !slices.Equal(picker.filter.Genres, []string{"drama"}) {
+		t.Fatalf("RandomMovie() city = %q, filter = %+v, want bhubaneswar with Odia, Hindi and drama", picker.city, picker.filter)
+	}
+
+	var response randomResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if response.City != "bhubaneswar" || response.Movie.Title != "Daskalia" {
+		t.Fatalf("response = %+v, want Daskalia in bhubaneswar", response)
+	}
+}
+
+func TestRandomReportsNoMatches(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	testRandomHandler(t, &fakeRandomPicker{err: movies.ErrNoMatchingMovies}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies/random?language=Tamil", nil))
+
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+
+	var response map[string]string
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if response["code"] != "no_matching_movies" {
+		t.Fatalf("code = %q, want no_matching_movies", response["code"])
+	}
+}
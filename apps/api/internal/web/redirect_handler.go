@@ -0,0 +1,56 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"go-scraping/internal/movies"
+)
+
+type linkFollower interface {
+	FollowLink(ctx context.Context, city, id string) (movies.Movie, error)
+}
+
+type RedirectHandler struct {
+	follower    linkFollower
+	cities      cityRegistry
+	defaultCity string
+	logger      *slog.Logger
+}
+
+func RegisterRedirectRoutes(mux *http.ServeMux, follower linkFollower, registry cityRegistry, defaultCity string, logger *slog.Logger) {
+	handler := &RedirectHandler{
+		follower:    follower,
+		cities:      registry,
+		defaultCity: defaultCity,
+		logger:      logger,
+	}
+
+	mux.Handle("GET /go/{movieID}", http.HandlerFunc(handler.Follow))
+}
+
+// Follow redirects to the booking page of the movie with the id listed in
+// movie responses, counting the redirect as a click. The id comes from the
+// BookMyShow event code, so short links keep working when a re-scrape finds
+// the movie under a new URL.
+func (h *RedirectHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	city, err := resolveCity(r, h.cities, h.defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+
+	movie, err := h.follower.FollowLink(r.Context(), city.Name, r.PathValue("movieID"))
+	if err != nil {
+		// Unknown ids are the client's mistake, so only failures that
+		// respond with a server error are logged.
+		if lookupServiceError(err, "").status >= http.StatusInternalServerError {
+			h.logger.ErrorContext(r.Context(), "failed to follow movie link", "city", city.Name, "error", err)
+		}
+		WriteServiceError(w, err, "Failed to find the movie")
+		return
+	}
+
+	http.Redirect(w, r, movie.Href, http.StatusFound)
+}
@@ -0,0 +1,82 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeLinkFollower struct {
+	city string
+	id   string
+}
+
+func (f *fakeLinkFollower) FollowLink(_ context.Context, city, id string) (movies.Movie, error) {
+	f.city, f.id = city, id
+	if id != "ET00403839" {
+		return movies.Movie{}, movies.ErrMovieNotListed
+	}
+
+	return movies.Movie{Title: "F1: The Movie", Href: "https://in.bookmyshow.com/movies/bhubaneswar/f1-the-movie/ET00403839"}, nil
+}
+
+func testRedirectHandler(t *testing.T, follower linkFollower, logger *slog.Logger) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterRedirectRoutes(mux, follower, registry, "cuttack", logger)
+
+	return mux
+}
+
+func TestFollowRedirectsToBookingPage(t *testing.T) {
+	t.Parallel()
+
+	follower := &fakeLinkFollower{}
+	recorder := httptest.NewRecorder()
+	testRedirectHandler(t, follower, slog.New(slog.DiscardHandler)).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/go/ET00403839?city=bbsr", nil))
+
+	if recorder.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusFound)
+	}
+
+	if want := "https://in.bookmyshow.com/movies/bhubaneswar/f1-the-movie/ET00403839"; recorder.Header().Get("Location") != want {
+		t.Fatalf("Location = %q, want %q", recorder.Header().Get("Location"), want)
+	}
+
+	if follower.city != "bhubaneswar" || follower.id != "ET00403839" {
+		t.Fatalf("FollowLink() city = %q, id = %q, want bhubaneswar and ET00403839", follower.city, follower.id)
+	}
+}
+
+func TestFollowReportsUnlistedMovies(t *testing.T) {
+	t.Parallel()
+
+	var logs bytes.Buffer
+	follower := &fakeLinkFollower{}
+	recorder := httptest.NewRecorder()
+	testRedirectHandler(t, follower, slog.New(slog.NewTextHandler(&logs, nil))).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/go/ET00000001", nil))
+
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+
+	if follower.city != "cuttack" {
+		t.Fatalf("FollowLink() city = %q, want the default city", follower.city)
+	}
+
+	if logs.Len() != 0 {
+		t.Fatalf("logs = %q, want unknown ids not logged as errors", logs.String())
+	}
+}
@@ -0,0 +1,154 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-scraping/internal/reminders"
+)
+
+type reminderService interface {
+	Create(ctx context.Context, userID int64, req reminders.Request) (reminders.Reminder, error)
+	List(ctx context.Context, userID int64) ([]reminders.Reminder, error)
+	Delete(ctx context.Context, userID, id int64) (bool, error)
+	Enabled(channel reminders.Channel) bool
+}
+
+type linkTokenIssuer interface {
+	authenticator
+	LinkToken(userID int64) (string, time.Time, error)
+}
+
+type reminderRequest struct {
+	Title            string                   `json:"title" validate:"required,text,maxlen=200"`
+	City             string                   `json:"city" validate:"required,text"`
+	Channel          reminders.Channel        `json:"channel" validate:"required,oneof=email push telegram"`
+	PushSubscription *pushSubscriptionPayload `json:"push_subscription"`
+}
+
+type telegramLinkResponse struct {
+	Code      string    `json:"code"`
+	Command   string    `json:"command"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type RemindersHandler struct {
+	reminders reminderService
+	tokens    linkTokenIssuer
+	logger    *slog.Logger
+}
+
+func RegisterReminderRoutes(mux *http.ServeMux, service reminderService, tokens linkTokenIssuer, logger *slog.Logger) {
+	handler := &RemindersHandler{
+		reminders: service,
+		tokens:    tokens,
+		logger:    logger,
+	}
+
+	requireAuth := RequireAuth(tokens)
+
+	mux.Handle("GET /me/reminders", requireAuth(http.HandlerFunc(handler.List)))
+	mux.Handle("POST /me/reminders", requireAuth(http.HandlerFunc(handler.Create)))
+	mux.Handle("DELETE /me/reminders/{id}", requireAuth(http.HandlerFunc(handler.Delete)))
+
+	if service.Enabled(reminders.ChannelTelegram) {
+		mux.Handle("POST /me/telegram/link", requireAuth(http.HandlerFunc(handler.LinkTelegram)))
+	}
+}
+
+func (h *RemindersHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, _ := UserID(r.Context())
+
+	list, err := h.reminders.List(r.Context(), userID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list reminders", "user_id", userID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to load reminders")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, list)
+}
+
+func (h *RemindersHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, _ := UserID(r.Context())
+
+	var req reminderRequest
+	if err := bindJSON(w, r, &req); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	create := reminders.Request{
+		Title:   req.Title,
+		City:    req.City,
+		Channel: req.Channel,
+	}
+	if push := req.PushSubscription; push != nil {
+		create.Push = &reminders.PushRegistration{
+			Endpoint: push.Endpoint,
+			P256DH:   push.Keys.P256DH,
+			Auth:     push.Keys.Auth,
+		}
+	}
+
+	reminder, err := h.reminders.Create(r.Context(), userID, create)
+	switch {
+	case errors.Is(err, reminders.ErrInvalidReminder),
+		errors.Is(err, reminders.ErrChannelUnavailable),
+		errors.Is(err, reminders.ErrTelegramNotLinked),
+		errors.Is(err, reminders.ErrTooMany):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "failed to create reminder", "user_id", userID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to create reminder")
+	default:
+		WriteJSON(w, http.StatusCreated, reminder)
+	}
+}
+
+func (h *RemindersHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, _ := UserID(r.Context())
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid reminder id")
+		return
+	}
+
+	deleted, err := h.reminders.Delete(r.Context(), userID, id)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to delete reminder", "user_id", userID, "reminder_id", id, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to delete reminder")
+		return
+	}
+
+	if !deleted {
+		WriteError(w, http.StatusNotFound, "Reminder not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LinkTelegram issues a code for the bot's /link command, which links the
+// chat it is sent from to the account.
+func (h *RemindersHandler) LinkTelegram(w http.ResponseWriter, r *http.Request) {
+	userID, _ := UserID(r.Context())
+
+	code, expiresAt, err := h.tokens.LinkToken(userID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to issue telegram link code", "user_id", userID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to issue link code")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, telegramLinkResponse{
+		Code:      code,
+		Command:   "/link " + code,
+		ExpiresAt: expiresAt,
+	})
+}
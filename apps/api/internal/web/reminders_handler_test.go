@@ -0,0 +1,97 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-scraping/internal/reminders"
+)
+
+type fakeReminders struct {
+	created []reminders.Request
+}
+
+func (f *fakeReminders) Create(_ context.Context, _ int64, req reminders.Request) (reminders.Reminder, error) {
+	if req.Channel != reminders.ChannelPush {
+		return reminders.Reminder{}, reminders.ErrChannelUnavailable
+	}
+
+	f.created = append(f.created, req)
+
+	return reminders.Reminder{ID: 1, Title: req.Title, City: req.City, Channel: req.Channel}, nil
+}
+
+func (f *fakeReminders) List(_ context.Context, _ int64) ([]reminders.Reminder, error) {
+	return []reminders.Reminder{}, nil
+}
+
+func (f *fakeReminders) Delete(_ context.Context, _, id int64) (bool, error) {
+	return id == 1, nil
+}
+
+func (f *fakeReminders) Enabled(channel reminders.Channel) bool {
+	return channel == reminders.ChannelTelegram
+}
+
+type fakeLinkTokens struct {
+	fakeAccounts
+}
+
+func (fakeLinkTokens) LinkToken(_ int64) (string, time.Time, error) {
+	return "code", time.Now().Add(10 * time.Minute), nil
+}
+
+func TestReminderRoutes(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeReminders{}
+	mux := http.NewServeMux()
+	RegisterReminderRoutes(mux, service, fakeLinkTokens{}, slog.New(slog.DiscardHandler))
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer access")
+
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	body := `{"title": "Dune", "city": "bbsr", "channel": "push", "push_subscription": {"endpoint": "https://push.example.com/abc", "keys": {"p256dh": "key", "auth": "secret"}}}`
+	if recorder := serve(http.MethodPost, "/me/reminders", body); recorder.Code != http.StatusCreated {
+		t.Fatalf("POST /me/reminders status = %d, want %d", recorder.Code, http.StatusCreated)
+	}
+
+	if push := service.created[0].Push; push == nil || push.Endpoint != "https://push.example.com/abc" || push.P256DH != "key" || push.Auth != "secret" {
+		t.Fatalf("created push subscription = %+v, want the posted one", push)
+	}
+
+	if recorder := serve(http.MethodPost, "/me/reminders", `{"title": "Dune", "city": "bbsr", "channel": "sms"}`); recorder.Code != http.StatusBadRequest {
+		t.Fatalf("POST /me/reminders unknown channel status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+
+	for _, wantStatus := range []int{http.StatusNoContent, http.StatusNotFound} {
+		target := "/me/reminders/1"
+		if wantStatus == http.StatusNotFound {
+			target = "/me/reminders/2"
+		}
+
+		if recorder := serve(http.MethodDelete, target, ""); recorder.Code != wantStatus {
+			t.Fatalf("DELETE %s status = %d, want %d", target, recorder.Code, wantStatus)
+		}
+	}
+
+	recorder := serve(http.MethodPost, "/me/telegram/link", "")
+
+	var link telegramLinkResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&link); err != nil || link.Command != "/link code" {
+		t.Fatalf("POST /me/telegram/link = %+v (%v), want /link code", link, err)
+	}
+}
@@ -0,0 +1,109 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-scraping/internal/movies"
+)
+
+type runSummarizer interface {
+	RunSummary(ctx context.Context, city string, weeks int) (movies.RunSummary, error)
+}
+
+type runsParams struct {
+	Weeks int `query:"weeks" default:"8" validate:"min=1,max=52"`
+}
+
+type titleRun struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Href        string    `json:"href"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	Days        int       `json:"days"`
+	Week        int       `json:"week"`
+}
+
+type weekChurn struct {
+	Start   time.Time `json:"start"`
+	Added   int       `json:"added"`
+	Removed int       `json:"removed"`
+}
+
+type runsResponse struct {
+	City           string      `json:"city"`
+	Showing        []titleRun  `json:"showing"`
+	CompletedRuns  int         `json:"completed_runs"`
+	AverageRunDays float64     `json:"average_run_days"`
+	Weeks          []weekChurn `json:"weeks"`
+}
+
+type RunsHandler struct {
+	summarizer  runSummarizer
+	cities      cityRegistry
+	defaultCity string
+	logger      *slog.Logger
+}
+
+func RegisterRunsRoutes(mux *http.ServeMux, summarizer runSummarizer, registry cityRegistry, defaultCity string, logger *slog.Logger) {
+	handler := &RunsHandler{
+		summarizer:  summarizer,
+		cities:      registry,
+		defaultCity: defaultCity,
+		logger:      logger,
+	}
+
+	mux.Handle("GET /analytics/runs", http.HandlerFunc(handler.Runs))
+}
+
+// Runs reports how long movies stay listed in a city: how long each current
+// movie has been showing, the average length of finished runs, and how many
+// movies were added and dropped each week.
+func (h *RunsHandler) Runs(w http.ResponseWriter, r *http.Request) {
+	city, err := resolveCity(r, h.cities, h.defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+
+	var params runsParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	summary, err := h.summarizer.RunSummary(r.Context(), city.Name, params.Weeks)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to summarize runs", "city", city.Name, "error", err)
+		WriteServiceError(w, err, "Failed to summarize runs")
+		return
+	}
+
+	location := city.Location()
+	response := runsResponse{
+		City:           city.Name,
+		Showing:        make([]titleRun, 0, len(summary.Showing)),
+		CompletedRuns:  summary.CompletedRuns,
+		AverageRunDays: summary.AverageRunDays,
+		Weeks:          make([]weekChurn, 0, len(summary.Weeks)),
+	}
+
+	for _, run := range summary.Showing {
+		response.Showing = append(response.Showing, titleRun{
+			ID:          run.Movie.ID(),
+			Title:       run.Movie.Title,
+			Href:        run.Movie.Href,
+			FirstSeenAt: run.FirstSeenAt.In(location),
+			Days:        run.Days,
+			Week:        run.Week,
+		})
+	}
+
+	for _, week := range summary.Weeks {
+		response.Weeks = append(response.Weeks, weekChurn{Start: week.Start.In(location), Added: week.Added, Removed: week.Removed})
+	}
+
+	WriteJSON(w, http.StatusOK, response)
+}
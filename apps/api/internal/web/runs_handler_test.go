@@ -0,0 +1,91 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeRunSummarizer struct {
+	city  string
+	weeks int
+}
+
+func (f *fakeRunSummarizer) RunSummary(_ context.Context, city string, weeks int) (movies.RunSummary, error) {
+	f.city, f.weeks = city, weeks
+
+	return movies.RunSummary{
+		Showing:        []movies.TitleRun{{Movie: movies.Movie{Title: "F1", Href: "/f1"}, FirstSeenAt: time.Date(2025, 7, 1, 20, 0, 0, 0, time.UTC), Days: 18, Week: 3}},
+		CompletedRuns:  2,
+		AverageRunDays: 18.5,
+		Weeks:          []movies.WeekChurn{{Start: time.Date(2025, 7, 22, 12, 0, 0, 0, time.UTC), Added: 1}},
+	}, nil
+}
+
+func testRunsHandler(t *testing.T, summarizer runSummarizer) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Timezone: "Asia/Kolkata", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterRunsRoutes(mux, summarizer, registry, "bhubaneswar", slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestRunsSummarizesCityRuns(t *testing.T) {
+	t.Parallel()
+
+	summarizer := &fakeRunSummarizer{}
+	recorder := httptest.NewRecorder()
+	testRunsHandler(t, summarizer).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/analytics/runs?city=bbsr", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+	if summarizer.city != "bhubaneswar" || summarizer.weeks != 8 {
+		t.Fatalf("RunSummary() city = %q, weeks = %d, want bhubaneswar over 8 weeks", summarizer.city, summarizer.weeks)
+	}
+
+	var response struct {
+		Showing []struct {
+			FirstSeenAt string `json:"first_seen_at"`
+			Week        int    `json:"week"`
+		} `json:"showing"`
+		AverageRunDays float64 `json:"average_run_days"`
+		Weeks          []struct {
+			Added int `json:"added"`
+		} `json:"weeks"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if len(response.Showing) != 1 || response.Showing[0].FirstSeenAt != "2025-07-02T01:30:00+05:30" || response.Showing[0].Week != 3 {
+		t.Fatalf("showing = %+v, want F1 in its third week, in the city's timezone", response.Showing)
+	}
+	if response.AverageRunDays != 18.5 || len(response.Weeks) != 1 || response.Weeks[0].Added != 1 {
+		t.Fatalf("response = %+v, want the average run and weekly churn", response)
+	}
+}
+
+func TestRunsRejectsWeeksOutOfRange(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	testRunsHandler(t, &fakeRunSummarizer{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/analytics/runs?weeks=53", nil))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
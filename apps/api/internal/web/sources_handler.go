@@ -0,0 +1,24 @@
+package web
+
+import (
+	"net/http"
+
+	"go-scraping/internal/bookmyshow"
+)
+
+type sourceReporter interface {
+	Sources() []bookmyshow.SourceStatus
+}
+
+// RegisterSourceRoutes registers an endpoint reporting the health of each
+// site the scraper loads listings from, mirrors included.
+func RegisterSourceRoutes(mux *http.ServeMux, sources sourceReporter) {
+	mux.Handle("GET /admin/sources", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		statuses := sources.Sources()
+
+		WriteJSON(w, http.StatusOK, map[string]any{
+			"sources": statuses,
+			"count":   len(statuses),
+		})
+	}))
+}
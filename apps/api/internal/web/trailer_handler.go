@@ -0,0 +1,95 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+
+	"go-scraping/internal/movies"
+)
+
+type trailerFinder interface {
+	Trailer(ctx context.Context, city, id string) (movies.Trailer, error)
+}
+
+type trailerParams struct {
+	MaxWidth int `query:"maxwidth" default:"560" validate:"min=200,max=1920"`
+}
+
+// trailerResponse is an oEmbed video response, so clients that already
+// render oEmbed can show the trailer as is.
+type trailerResponse struct {
+	Type            string `json:"type"`
+	Version         string `json:"version"`
+	Title           string `json:"title"`
+	ProviderName    string `json:"provider_name"`
+	URL             string `json:"url"`
+	HTML            string `json:"html"`
+	Width           int    `json:"width"`
+	Height          int    `json:"height"`
+	ThumbnailURL    string `json:"thumbnail_url,omitempty"`
+	ThumbnailWidth  int    `json:"thumbnail_width,omitempty"`
+	ThumbnailHeight int    `json:"thumbnail_height,omitempty"`
+}
+
+type TrailerHandler struct {
+	finder      trailerFinder
+	cities      cityRegistry
+	defaultCity string
+	logger      *slog.Logger
+}
+
+func RegisterTrailerRoutes(mux *http.ServeMux, finder trailerFinder, registry cityRegistry, defaultCity string, logger *slog.Logger) {
+	handler := &TrailerHandler{
+		finder:      finder,
+		cities:      registry,
+		defaultCity: defaultCity,
+		logger:      logger,
+	}
+
+	mux.Handle("GET /movies/{id}/trailer", http.HandlerFunc(handler.Trailer))
+}
+
+// Trailer returns the movie's trailer with embed HTML and a thumbnail, so
+// clients can show a play button without calling YouTube themselves. The
+// player is maxwidth pixels wide, at a 16:9 aspect ratio.
+func (h *TrailerHandler) Trailer(w http.ResponseWriter, r *http.Request) {
+	city, err := resolveCity(r, h.cities, h.defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+
+	var params trailerParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+	width, height := params.MaxWidth, params.MaxWidth*9/16
+
+	trailer, err := h.finder.Trailer(r.Context(), city.Name, r.PathValue("id"))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to find trailer", "city", city.Name, "error", err)
+		WriteServiceError(w, err, "Failed to find the trailer")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, trailerResponse{
+		Type:         "video",
+		Version:      "1.0",
+		Title:        trailer.Title,
+		ProviderName: trailer.Provider,
+		URL:          trailer.URL,
+		HTML: fmt.Sprintf(
+			`<iframe width="%d" height="%d" src="%s" title="%s" frameborder="0" allow="autoplay; encrypted-media; picture-in-picture" allowfullscreen></iframe>`,
+			width, height, html.EscapeString(trailer.EmbedURL), html.EscapeString(trailer.Title),
+		),
+		Width:           width,
+		Height:          height,
+		ThumbnailURL:    trailer.ThumbnailURL,
+		ThumbnailWidth:  trailer.ThumbnailWidth,
+		ThumbnailHeight: trailer.ThumbnailHeight,
+	})
+}
@@ -0,0 +1,119 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeTrailerFinder struct {
+	city string
+	err  error
+}
+
+func (f *fakeTrailerFinder) Trailer(_ context.Context, city, id string) (movies.Trailer, error) {
+	f.city = city
+	if f.err != nil {
+		return movies.Trailer{}, f.err
+	}
+	if id != "ET00403839" {
+		return movies.Trailer{}, movies.ErrMovieNotListed
+	}
+
+	return movies.Trailer{
+		Title:           "F1 \"Official\" Trailer",
+		Provider:        "YouTube",
+		URL:             "https://www.youtube.com/watch?v=abc123",
+		EmbedURL:        "https://www.youtube-nocookie.com/embed/abc123",
+		ThumbnailURL:    "https://i.ytimg.com/vi/abc123/hqdefault.jpg",
+		ThumbnailWidth:  480,
+		ThumbnailHeight: 360,
+	}, nil
+}
+
+func testTrailerHandler(t *testing.T, finder trailerFinder) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterTrailerRoutes(mux, finder, registry, "cuttack", slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestTrailerReturnsOEmbed(t *testing.T) {
+	t.Parallel()
+
+	finder := &fakeTrailerFinder{}
+	recorder := httptest.NewRecorder()
+	testTrailerHandler(t, finder).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/movies/ET00403839/trailer?city=bbsr&maxwidth=640", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	if finder.city != "bhubaneswar" {
+		t.Fatalf("Trailer() city = %q, want %q", finder.city, "bhubaneswar")
+	}
+
+	var got trailerResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if got.Type != "video" || got.Width != 640 || got.Height != 360 || got.ThumbnailURL == "" {
+		t.Fatalf("response = %+v, want a 640x360 video with a thumbnail", got)
+	}
+
+	for _, want := range []string{`width="640"`, `height="360"`, `src="https://www.youtube-nocookie.com/embed/abc123"`, `title="F1 &#34;Official&#34; Trailer"`} {
+		if !strings.Contains(got.HTML, want) {
+			t.Errorf("html = %q, want it to contain %q", got.HTML, want)
+		}
+	}
+}
+
+func TestTrailerMapsServiceErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		target   string
+		err      error
+		wantCode string
+	}{
+		{name: "not listed", target: "/movies/ET00000000/trailer", wantCode: "movie_not_listed"},
+		{name: "no trailer", target: "/movies/ET00403839/trailer", err: movies.ErrTrailerNotFound, wantCode: "trailer_not_found"},
+		{name: "disabled", target: "/movies/ET00403839/trailer", err: movies.ErrTrailersDisabled, wantCode: "trailers_disabled"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := httptest.NewRecorder()
+			testTrailerHandler(t, &fakeTrailerFinder{err: tc.err}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+			if recorder.Code != http.StatusNotFound {
+				t.Fatalf("status = %d, want %d", recorder.Code, http.StatusNotFound)
+			}
+
+			var body map[string]string
+			if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+
+			if body["code"] != tc.wantCode {
+				t.Fatalf("code = %q, want %q", body["code"], tc.wantCode)
+			}
+		})
+	}
+}
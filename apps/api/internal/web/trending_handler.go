@@ -0,0 +1,115 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-scraping/internal/movies"
+)
+
+type trendingService interface {
+	RecordClick(ctx context.Context, city, id string) error
+	Trending(ctx context.Context, city string, since time.Time, limit int) (movies.Trending, error)
+}
+
+type trendingParams struct {
+	// Hours is at most 30 days.
+	Hours int `query:"hours" default:"24" validate:"min=1,max=720"`
+	Limit int `query:"limit" default:"10" validate:"min=1,max=50"`
+}
+
+// clickRequest names the clicked movie by its ID, or by its link for clients
+// that predate movie IDs.
+type clickRequest struct {
+	City string `json:"city" validate:"text"`
+	ID   string `json:"id" validate:"text"`
+	Href string `json:"href" validate:"text"`
+}
+
+type TrendingHandler struct {
+	service     trendingService
+	cities      cityRegistry
+	defaultCity string
+	logger      *slog.Logger
+}
+
+func RegisterTrendingRoutes(mux *http.ServeMux, service trendingService, registry cityRegistry, defaultCity string, logger *slog.Logger) {
+	handler := &TrendingHandler{
+		service:     service,
+		cities:      registry,
+		defaultCity: defaultCity,
+		logger:      logger,
+	}
+
+	mux.Handle("GET /trending", http.HandlerFunc(handler.Trending))
+	mux.Handle("POST /movies/clicks", http.HandlerFunc(handler.RecordClick))
+}
+
+// Trending returns the city's most searched and most clicked titles over the
+// last hours, for "popular right now" rails.
+func (h *TrendingHandler) Trending(w http.ResponseWriter, r *http.Request) {
+	city, err := resolveCity(r, h.cities, h.defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+
+	var params trendingParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	since := time.Now().Add(-time.Duration(params.Hours) * time.Hour)
+
+	trending, err := h.service.Trending(r.Context(), city.Name, since, params.Limit)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list trending titles", "city", city.Name, "error", err)
+		WriteServiceError(w, err, "Failed to list trending titles")
+		return
+	}
+
+	trending.Since = trending.Since.In(city.Location())
+	WriteJSON(w, http.StatusOK, trending)
+}
+
+// RecordClick counts a click on one of the city's listed movies toward its
+// trending rank.
+func (h *TrendingHandler) RecordClick(w http.ResponseWriter, r *http.Request) {
+	var req clickRequest
+	if err := bindJSON(w, r, &req); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	if (req.ID == "") == (req.Href == "") {
+		writeBindError(w, invalidField("id", "set either id or href"))
+		return
+	}
+
+	id := req.ID
+	if id == "" {
+		id = movies.Movie{Href: req.Href}.ID()
+	}
+
+	cityName := req.City
+	if cityName == "" {
+		cityName = h.defaultCity
+	}
+
+	city, err := resolveCityName(h.cities, cityName)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+
+	if err := h.service.RecordClick(r.Context(), city.Name, id); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to record click", "city", city.Name, "error", err)
+		WriteServiceError(w, err, "Failed to record click")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,117 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeTrendingService struct {
+	mu     sync.Mutex
+	since  time.Time
+	limit  int
+	clicks []string
+}
+
+func (f *fakeTrendingService) RecordClick(_ context.Context, city, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if id != "ET00403839" {
+		return movies.ErrMovieNotListed
+	}
+
+	f.clicks = append(f.clicks, city+" "+id)
+	return nil
+}
+
+func (f *fakeTrendingService) Trending(_ context.Context, city string, since time.Time, limit int) (movies.Trending, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.since, f.limit = since, limit
+
+	return movies.Trending{
+		City:         city,
+		Since:        since,
+		MostSearched: []movies.TitleCount{{Title: "F1", Count: 12}},
+		MostClicked:  []movies.TitleCount{{Title: "Superman", Count: 4}},
+	}, nil
+}
+
+func testTrendingHandler(t *testing.T, service trendingService) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterTrendingRoutes(mux, service, registry, "cuttack", slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestTrendingUsesRollingWindow(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeTrendingService{}
+	recorder := httptest.NewRecorder()
+	testTrendingHandler(t, service).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/trending?city=bbsr&hours=6", nil))
+
+	var trending movies.Trending
+	if err := json.NewDecoder(recorder.Body).Decode(&trending); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if recorder.Code != http.StatusOK || trending.City != "bhubaneswar" || len(trending.MostSearched) != 1 || len(trending.MostClicked) != 1 {
+		t.Fatalf("GET /trending = %d %+v, want both rails for bhubaneswar", recorder.Code, trending)
+	}
+
+	if age := time.Since(service.since); age < 6*time.Hour-time.Minute || age > 6*time.Hour+time.Minute || service.limit != 10 {
+		t.Fatalf("Trending() since %s ago, limit %d, want 6h and 10", age, service.limit)
+	}
+}
+
+func TestRecordClick(t *testing.T) {
+	t.Parallel()
+
+	service := &fakeTrendingService{}
+	handler := testTrendingHandler(t, service)
+
+	tests := []struct {
+		body string
+		want int
+	}{
+		{`{"city": "bbsr", "id": "ET00403839"}`, http.StatusNoContent},
+		{`{"city": "bbsr", "href": "/movies/bhubaneswar/f1/ET00403839"}`, http.StatusNoContent},
+		{`{"city": "bbsr", "href": "/movies/bhubaneswar/kalki/ET00376540"}`, http.StatusNotFound},
+		{`{"city": "bbsr"}`, http.StatusBadRequest},
+		{`{"city": "bbsr", "id": "ET00403839", "href": "/movies/bhubaneswar/f1/ET00403839"}`, http.StatusBadRequest},
+		{`{"city": "Not A City!", "id": "ET00403839"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/movies/clicks", strings.NewReader(tt.body)))
+
+		if recorder.Code != tt.want {
+			t.Fatalf("POST /movies/clicks %s status = %d, want %d", tt.body, recorder.Code, tt.want)
+		}
+	}
+
+	if !slices.Equal(service.clicks, []string{"bhubaneswar ET00403839", "bhubaneswar ET00403839"}) {
+		t.Fatalf("clicks = %v, want two clicks on ET00403839 in bhubaneswar", service.clicks)
+	}
+}
@@ -0,0 +1,107 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-scraping/internal/movies"
+)
+
+type newMovieLister interface {
+	ListNewMovies(ctx context.Context, city string, since time.Time, limit int) ([]movies.Sighting, error)
+}
+
+type triggerParams struct {
+	Since timestampParam `query:"since"`
+	Limit int            `query:"limit" default:"50" validate:"min=1,max=100"`
+}
+
+// timestampParam is an RFC 3339 timestamp parameter.
+type timestampParam time.Time
+
+func (p *timestampParam) UnmarshalText(text []byte) error {
+	parsed, err := time.Parse(time.RFC3339, string(text))
+	if err != nil {
+		return errors.New("must be an RFC 3339 timestamp such as 2025-07-01T00:00:00Z")
+	}
+
+	*p = timestampParam(parsed)
+	return nil
+}
+
+// newMovieItem is one result of a polling trigger. Automation platforms such
+// as Zapier deduplicate results on id, so it must not change between polls.
+type newMovieItem struct {
+	ID          string    `json:"id"`
+	City        string    `json:"city"`
+	DisplayName string    `json:"display_name"`
+	MovieID     string    `json:"movie_id"`
+	Title       string    `json:"title"`
+	Href        string    `json:"href"`
+	Year        int       `json:"year,omitempty"`
+	Genres      []string  `json:"genres,omitempty"`
+	Languages   []string  `json:"languages,omitempty"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+}
+
+type TriggersHandler struct {
+	lister      newMovieLister
+	cities      cityRegistry
+	defaultCity string
+	logger      *slog.Logger
+}
+
+func RegisterTriggerRoutes(mux *http.ServeMux, lister newMovieLister, registry cityRegistry, defaultCity string, logger *slog.Logger) {
+	handler := &TriggersHandler{
+		lister:      lister,
+		cities:      registry,
+		defaultCity: defaultCity,
+		logger:      logger,
+	}
+
+	mux.Handle("GET /triggers/new_movies", http.HandlerFunc(handler.NewMovies))
+}
+
+// NewMovies returns the movies first seen in a city as a bare JSON array,
+// newest first, which is the shape Zapier and IFTTT polling triggers expect.
+func (h *TriggersHandler) NewMovies(w http.ResponseWriter, r *http.Request) {
+	city, err := resolveCity(r, h.cities, h.defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+
+	var params triggerParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	sightings, err := h.lister.ListNewMovies(r.Context(), city.Name, time.Time(params.Since), params.Limit)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list new movies", "city", city.Name, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to list new movies")
+		return
+	}
+
+	items := make([]newMovieItem, 0, len(sightings))
+	for _, sighting := range sightings {
+		items = append(items, newMovieItem{
+			ID:          sighting.ID(),
+			City:        city.Name,
+			DisplayName: city.DisplayName,
+			MovieID:     sighting.Movie.ID(),
+			Title:       sighting.Movie.Title,
+			Href:        sighting.Movie.Href,
+			Year:        sighting.Movie.Year,
+			Genres:      sighting.Movie.Genres,
+			Languages:   sighting.Movie.Languages,
+			FirstSeenAt: sighting.FirstSeenAt.In(city.Location()),
+		})
+	}
+
+	WriteJSON(w, http.StatusOK, items)
+}
@@ -0,0 +1,83 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeNewMovieLister struct {
+	city  string
+	since time.Time
+	limit int
+}
+
+func (f *fakeNewMovieLister) ListNewMovies(_ context.Context, city string, since time.Time, limit int) ([]movies.Sighting, error) {
+	f.city, f.since, f.limit = city, since, limit
+
+	return []movies.Sighting{
+		{City: city, Movie: movies.Movie{Title: "F1", Href: "/f1"}, FirstSeenAt: time.Date(2025, 6, 27, 6, 0, 0, 0, time.UTC)},
+	}, nil
+}
+
+func testTriggersHandler(t *testing.T, lister newMovieLister) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterTriggerRoutes(mux, lister, registry, "cuttack", slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestNewMoviesTriggerReturnsArrayWithStableIDs(t *testing.T) {
+	t.Parallel()
+
+	lister := &fakeNewMovieLister{}
+	req := httptest.NewRequest(http.MethodGet, "/triggers/new_movies?city=bbsr&since=2025-06-01T00:00:00Z", nil)
+	recorder := httptest.NewRecorder()
+
+	testTriggersHandler(t, lister).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	if lister.city != "bhubaneswar" || !lister.since.Equal(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)) || lister.limit != 50 {
+		t.Fatalf("lister = %+v, want bhubaneswar since June 1 with the default limit", lister)
+	}
+
+	var items []newMovieItem
+	if err := json.NewDecoder(recorder.Body).Decode(&items); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	want := movies.Sighting{City: "bhubaneswar", Movie: movies.Movie{Href: "/f1"}}.ID()
+	if len(items) != 1 || items[0].ID != want || items[0].DisplayName != "Bhubaneswar" {
+		t.Fatalf("items = %+v, want F1 with id %s", items, want)
+	}
+}
+
+func TestNewMoviesTriggerRejectsMalformedSince(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/triggers/new_movies?since=yesterday", nil)
+	recorder := httptest.NewRecorder()
+
+	testTriggersHandler(t, &fakeNewMovieLister{}).ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,124 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-scraping/internal/usage"
+)
+
+type usageRecorder interface {
+	Record(ctx context.Context, userID int64)
+}
+
+type usageService interface {
+	usageRecorder
+	Usage(ctx context.Context, userID int64, since time.Time) ([]usage.Day, error)
+	Report(ctx context.Context, since time.Time) ([]usage.UserDay, error)
+}
+
+type usageParams struct {
+	Days int `query:"days" default:"30" validate:"min=1,max=365"`
+}
+
+type usageResponse struct {
+	Since string      `json:"since"`
+	Total int64       `json:"total"`
+	Days  []usage.Day `json:"days"`
+}
+
+type usageReportResponse struct {
+	Since string          `json:"since"`
+	Usage []usage.UserDay `json:"usage"`
+}
+
+// UsageMiddleware counts every request made with a valid access token toward
+// its user's daily usage. Requests without one are served as usual and not
+// counted.
+func UsageMiddleware(auth authenticator, recorder usageRecorder) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+				if userID, err := auth.Authenticate(token); err == nil {
+					recorder.Record(r.Context(), userID)
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type UsageHandler struct {
+	usage  usageService
+	logger *slog.Logger
+}
+
+func RegisterUsageRoutes(mux *http.ServeMux, tracker usageService, auth authenticator, logger *slog.Logger) {
+	handler := &UsageHandler{
+		usage:  tracker,
+		logger: logger,
+	}
+
+	mux.Handle("GET /admin/usage", http.HandlerFunc(handler.Report))
+	mux.Handle("GET /me/usage", RequireAuth(auth)(http.HandlerFunc(handler.Me)))
+}
+
+// Me returns the authenticated user's request counts for each of the last
+// days UTC days they used the API, newest first.
+func (h *UsageHandler) Me(w http.ResponseWriter, r *http.Request) {
+	userID, _ := UserID(r.Context())
+
+	since, err := usageSince(r)
+	if err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	days, err := h.usage.Usage(r.Context(), userID, since)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to load usage", "user_id", userID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to load usage")
+		return
+	}
+
+	response := usageResponse{Since: since.Format(time.DateOnly), Days: days}
+	for _, day := range days {
+		response.Total += day.Requests
+	}
+
+	WriteJSON(w, http.StatusOK, response)
+}
+
+// Report returns every user's daily request counts for the last days UTC
+// days, for spotting accounts that go beyond fair use.
+func (h *UsageHandler) Report(w http.ResponseWriter, r *http.Request) {
+	since, err := usageSince(r)
+	if err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	report, err := h.usage.Report(r.Context(), since)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to load usage report", "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to load usage")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, usageReportResponse{Since: since.Format(time.DateOnly), Usage: report})
+}
+
+// usageSince returns the first UTC day covered by the days parameter, where
+// one day means today only.
+func usageSince(r *http.Request) (time.Time, error) {
+	var params usageParams
+	if err := bindQuery(r, &params); err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Now().UTC().AddDate(0, 0, 1-params.Days), nil
+}
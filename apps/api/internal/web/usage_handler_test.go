@@ -0,0 +1,128 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"go-scraping/internal/usage"
+)
+
+type fakeUsage struct {
+	recorded []int64
+	userID   int64
+	since    time.Time
+}
+
+func (f *fakeUsage) Record(_ context.Context, userID int64) {
+	f.recorded = append(f.recorded, userID)
+}
+
+func (f *fakeUsage) Usage(_ context.Context, userID int64, since time.Time) ([]usage.Day, error) {
+	f.userID, f.since = userID, since
+	return []usage.Day{{Date: "2025-07-14", Requests: 40}, {Date: "2025-07-13", Requests: 2}}, nil
+}
+
+func (f *fakeUsage) Report(_ context.Context, since time.Time) ([]usage.UserDay, error) {
+	f.since = since
+	return []usage.UserDay{{UserID: 1, Email: "ana@example.com", Date: "2025-07-14", Requests: 40}}, nil
+}
+
+func TestUsageMiddlewareCountsAuthenticatedRequests(t *testing.T) {
+	t.Parallel()
+
+	tracker := &fakeUsage{}
+	handler := UsageMiddleware(fakeAccounts{}, tracker)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for _, token := range []string{"access", "", "expired", "access"} {
+		req := httptest.NewRequest(http.MethodGet, "/movies", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		if recorder.Code != http.StatusNoContent {
+			t.Fatalf("status with token %q = %d, want %d", token, recorder.Code, http.StatusNoContent)
+		}
+	}
+
+	if want := []int64{1, 1}; !slices.Equal(tracker.recorded, want) {
+		t.Fatalf("recorded = %v, want %v", tracker.recorded, want)
+	}
+}
+
+func TestUsageRoutes(t *testing.T) {
+	t.Parallel()
+
+	tracker := &fakeUsage{}
+	mux := http.NewServeMux()
+	RegisterUsageRoutes(mux, tracker, fakeAccounts{}, slog.New(slog.DiscardHandler))
+
+	const adminToken = "admin-token-that-is-long-enough-to-use"
+	handler := Chain(mux, AdminMiddleware(adminToken))
+
+	serve := func(target, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	if recorder := serve("/me/usage", ""); recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("GET /me/usage without token status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+
+	recorder := serve("/me/usage?days=7", "access")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /me/usage status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	var mine usageResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&mine); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	wantSince := time.Now().UTC().AddDate(0, 0, -6).Format(time.DateOnly)
+	if tracker.userID != 1 || mine.Since != wantSince || mine.Total != 42 || len(mine.Days) != 2 {
+		t.Fatalf("user = %d, response = %+v, want user 1's 42 requests since %s", tracker.userID, mine, wantSince)
+	}
+
+	if recorder := serve("/me/usage?days=0", "access"); recorder.Code != http.StatusBadRequest {
+		t.Fatalf("GET /me/usage?days=0 status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+
+	// Every user's usage is for admins only, not for any signed-in user.
+	for _, token := range []string{"", "access"} {
+		if recorder := serve("/admin/usage", token); recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("GET /admin/usage with token %q status = %d, want %d", token, recorder.Code, http.StatusUnauthorized)
+		}
+	}
+
+	recorder = serve("/admin/usage", adminToken)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /admin/usage status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	var report usageReportResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&report); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if len(report.Usage) != 1 || report.Usage[0].Email != "ana@example.com" {
+		t.Fatalf("report = %+v, want ana's usage", report)
+	}
+}
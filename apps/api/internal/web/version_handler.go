@@ -0,0 +1,15 @@
+package web
+
+import (
+	"net/http"
+
+	"go-scraping/internal/buildinfo"
+)
+
+// RegisterVersionRoutes registers an endpoint reporting which build is
+// running, so operators can tell which scraper revision an instance has.
+func RegisterVersionRoutes(mux *http.ServeMux) {
+	mux.Handle("GET /version", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		WriteJSON(w, http.StatusOK, buildinfo.Get())
+	}))
+}
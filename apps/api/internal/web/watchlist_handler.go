@@ -0,0 +1,125 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"go-scraping/internal/watchlist"
+)
+
+type watchlistService interface {
+	Add(ctx context.Context, userID int64, title, externalID string) (watchlist.Item, error)
+	List(ctx context.Context, userID int64, city string) ([]watchlist.Entry, error)
+	Remove(ctx context.Context, userID, itemID int64) (bool, error)
+}
+
+type watchlistRequest struct {
+	Title      string `json:"title" validate:"text"`
+	ExternalID string `json:"external_id" validate:"text"`
+}
+
+type watchlistResponse struct {
+	City        string            `json:"city"`
+	DisplayName string            `json:"display_name"`
+	Items       []watchlist.Entry `json:"items"`
+}
+
+type WatchlistHandler struct {
+	watchlist   watchlistService
+	cities      cityRegistry
+	preferences PreferenceSource
+	defaultCity string
+	logger      *slog.Logger
+}
+
+func RegisterWatchlistRoutes(mux *http.ServeMux, list watchlistService, preferences PreferenceSource, registry cityRegistry, defaultCity string, logger *slog.Logger) {
+	handler := &WatchlistHandler{
+		watchlist:   list,
+		cities:      registry,
+		preferences: preferences,
+		defaultCity: defaultCity,
+		logger:      logger,
+	}
+
+	requireAuth := RequireAuth(preferences)
+
+	mux.Handle("GET /me/watchlist", requireAuth(http.HandlerFunc(handler.List)))
+	mux.Handle("POST /me/watchlist", requireAuth(http.HandlerFunc(handler.Add)))
+	mux.Handle("DELETE /me/watchlist/{id}", requireAuth(http.HandlerFunc(handler.Remove)))
+}
+
+func (h *WatchlistHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, _ := UserID(r.Context())
+
+	defaultCity := h.defaultCity
+	if prefs := userPreferences(r, h.preferences, h.logger); prefs.HomeCity != "" {
+		defaultCity = prefs.HomeCity
+	}
+
+	city, err := resolveCity(r, h.cities, defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+
+	entries, err := h.watchlist.List(r.Context(), userID, city.Name)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list watchlist", "user_id", userID, "city", city.Name, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to load watchlist")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, watchlistResponse{
+		City:        city.Name,
+		DisplayName: city.DisplayName,
+		Items:       entries,
+	})
+}
+
+func (h *WatchlistHandler) Add(w http.ResponseWriter, r *http.Request) {
+	userID, _ := UserID(r.Context())
+
+	var req watchlistRequest
+	if err := bindJSON(w, r, &req); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	item, err := h.watchlist.Add(r.Context(), userID, req.Title, req.ExternalID)
+	switch {
+	case errors.Is(err, watchlist.ErrInvalidItem), errors.Is(err, watchlist.ErrFull):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "failed to add watchlist item", "user_id", userID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to add to watchlist")
+	default:
+		WriteJSON(w, http.StatusCreated, item)
+	}
+}
+
+func (h *WatchlistHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	userID, _ := UserID(r.Context())
+
+	itemID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid watchlist item id")
+		return
+	}
+
+	removed, err := h.watchlist.Remove(r.Context(), userID, itemID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to remove watchlist item", "user_id", userID, "item_id", itemID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to remove from watchlist")
+		return
+	}
+
+	if !removed {
+		WriteError(w, http.StatusNotFound, "Watchlist item not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,116 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/watchlist"
+)
+
+type fakeWatchlist struct {
+	items map[int64][]watchlist.Item
+	city  string
+}
+
+func (f *fakeWatchlist) Add(_ context.Context, userID int64, title, externalID string) (watchlist.Item, error) {
+	if title == "" && externalID == "" {
+		return watchlist.Item{}, watchlist.ErrInvalidItem
+	}
+
+	item := watchlist.Item{ID: int64(len(f.items[userID]) + 1), Title: title, ExternalID: externalID}
+	f.items[userID] = append(f.items[userID], item)
+
+	return item, nil
+}
+
+func (f *fakeWatchlist) List(_ context.Context, userID int64, city string) ([]watchlist.Entry, error) {
+	f.city = city
+
+	entries := []watchlist.Entry{}
+	for _, item := range f.items[userID] {
+		entries = append(entries, watchlist.Entry{Item: item, InTheatersNearYou: item.Title == "Superman"})
+	}
+
+	return entries, nil
+}
+
+func (f *fakeWatchlist) Remove(_ context.Context, userID, itemID int64) (bool, error) {
+	for i, item := range f.items[userID] {
+		if item.ID == itemID {
+			f.items[userID] = append(f.items[userID][:i], f.items[userID][i+1:]...)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func TestWatchlistRoutes(t *testing.T) {
+	t.Parallel()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	list := &fakeWatchlist{items: make(map[int64][]watchlist.Item)}
+	mux := http.NewServeMux()
+	RegisterWatchlistRoutes(mux, list, fakeAccounts{}, registry, "cuttack", slog.New(slog.DiscardHandler))
+
+	serve := func(method, target, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	if recorder := serve(http.MethodGet, "/me/watchlist", "", ""); recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("GET /me/watchlist without token status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+
+	if recorder := serve(http.MethodPost, "/me/watchlist", `{}`, "access"); recorder.Code != http.StatusBadRequest {
+		t.Fatalf("POST /me/watchlist empty status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+
+	if recorder := serve(http.MethodPost, "/me/watchlist", `{"title": "Superman"}`, "access"); recorder.Code != http.StatusCreated {
+		t.Fatalf("POST /me/watchlist status = %d, want %d", recorder.Code, http.StatusCreated)
+	}
+
+	recorder := serve(http.MethodGet, "/me/watchlist?city=bbsr", "", "access")
+
+	var response struct {
+		City  string `json:"city"`
+		Items []struct {
+			Title             string `json:"title"`
+			InTheatersNearYou bool   `json:"in_theaters_near_you"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if response.City != "bhubaneswar" || len(response.Items) != 1 || !response.Items[0].InTheatersNearYou {
+		t.Fatalf("GET /me/watchlist = %+v, want Superman showing in bhubaneswar", response)
+	}
+
+	if serve(http.MethodGet, "/me/watchlist", "", "access"); list.city != "bhubaneswar" {
+		t.Fatalf("GET /me/watchlist city = %q, want the home city %q", list.city, "bhubaneswar")
+	}
+
+	for _, wantStatus := range []int{http.StatusNoContent, http.StatusNotFound} {
+		if recorder := serve(http.MethodDelete, "/me/watchlist/1", "", "access"); recorder.Code != wantStatus {
+			t.Fatalf("DELETE /me/watchlist/1 status = %d, want %d", recorder.Code, wantStatus)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package web
+
+import (
+	"context"
+	"embed"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+//go:embed templates/web.html
+var webUIFS embed.FS
+
+//go:embed static
+var webUIStatic embed.FS
+
+var webUITemplate = template.Must(template.New("web.html").Funcs(template.FuncMap{
+	"join": strings.Join,
+}).ParseFS(webUIFS, "templates/web.html"))
+
+type webUILoader interface {
+	Load(ctx context.Context, city string) ([]movies.Movie, bool, error)
+	Search(ctx context.Context, city string, req movies.SearchRequest) (movies.SearchResult, error)
+}
+
+type webUIParams struct {
+	Q string `query:"q" validate:"text,maxlen=100"`
+}
+
+type webUIView struct {
+	City       cities.City
+	Cities     []cities.City
+	Query      string
+	Movies     []movies.Movie
+	DidYouMean string
+	Error      string
+}
+
+type WebUIHandler struct {
+	loader      webUILoader
+	cities      cityRegistry
+	defaultCity string
+	logger      *slog.Logger
+}
+
+// RegisterWebUIRoutes registers the server-rendered listings pages, for using
+// the API without deploying the frontend.
+func RegisterWebUIRoutes(mux *http.ServeMux, loader webUILoader, registry cityRegistry, defaultCity string, logger *slog.Logger) {
+	handler := &WebUIHandler{
+		loader:      loader,
+		cities:      registry,
+		defaultCity: defaultCity,
+		logger:      logger,
+	}
+
+	static, err := fs.Sub(webUIStatic, "static")
+	if err != nil {
+		panic(err)
+	}
+
+	mux.Handle("GET /web", http.RedirectHandler("/web/"+url.PathEscape(defaultCity), http.StatusFound))
+	mux.Handle("GET /web/{city}", http.HandlerFunc(handler.Page))
+	mux.Handle("GET /web/static/", http.StripPrefix("/web/static/", http.FileServerFS(static)))
+}
+
+// Page renders a city's current movies, or those matching the q parameter,
+// with links to their short links and to the other registered cities.
+func (h *WebUIHandler) Page(w http.ResponseWriter, r *http.Request) {
+	view := webUIView{Cities: h.cities.List()}
+
+	city, err := resolveCityName(h.cities, r.PathValue("city"))
+	if err != nil {
+		h.renderError(w, r, view, err)
+		return
+	}
+
+	// Aliases and differently cased names get one canonical address.
+	if city.Name != r.PathValue("city") {
+		target := url.URL{Path: "/web/" + city.Name, RawQuery: r.URL.RawQuery}
+		http.Redirect(w, r, target.String(), http.StatusMovedPermanently)
+		return
+	}
+	view.City = city
+	noteCity(r.Context(), city.Name)
+
+	var params webUIParams
+	if err := bindQuery(r, &params); err != nil {
+		view.Error = err.Error()
+		h.render(w, r, http.StatusBadRequest, view)
+		return
+	}
+	view.Query = params.Q
+
+	var result movies.SearchResult
+	if view.Query != "" {
+		result, err = h.loader.Search(r.Context(), city.Name, movies.SearchRequest{
+			Query:     view.Query,
+			Fuzziness: movies.FuzzinessAuto,
+		})
+	} else {
+		result.Movies, result.FromCache, err = h.loader.Load(r.Context(), city.Name)
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to load movies for web page", "city", city.Name, "error", err)
+		h.renderError(w, r, view, err)
+		return
+	}
+	noteCache(r.Context(), result.FromCache)
+
+	view.Movies = result.Movies
+	view.DidYouMean = result.DidYouMean
+
+	h.render(w, r, http.StatusOK, view)
+}
+
+func (h *WebUIHandler) renderError(w http.ResponseWriter, r *http.Request, view webUIView, err error) {
+	mapped := lookupServiceError(err, "Failed to load movies")
+	view.Error = mapped.message
+
+	h.render(w, r, mapped.status, view)
+}
+
+func (h *WebUIHandler) render(w http.ResponseWriter, r *http.Request, status int, view webUIView) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+
+	if err := webUITemplate.Execute(w, view); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to render web page", "city", view.City.Name, "error", err)
+	}
+}
@@ -0,0 +1,154 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeWebUILoader struct {
+	movies      []movies.Movie
+	err         error
+	searchQuery string
+}
+
+func (f *fakeWebUILoader) Load(_ context.Context, _ string) ([]movies.Movie, bool, error) {
+	return f.movies, true, f.err
+}
+
+func (f *fakeWebUILoader) Search(_ context.Context, _ string, req movies.SearchRequest) (movies.SearchResult, error) {
+	f.searchQuery = req.Query
+
+	return movies.SearchResult{
+		Movies:     movies.FuzzySearch(f.movies, movies.NormalizeQuery(req.Query), movies.SearchOptions{Fuzziness: req.Fuzziness}),
+		DidYouMean: "Daskalia",
+	}, f.err
+}
+
+func testWebUIHandler(t *testing.T, loader webUILoader) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{
+		{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Aliases: []string{"bbsr"}},
+		{Name: "cuttack", DisplayName: "Cuttack"},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterWebUIRoutes(mux, loader, registry, "cuttack", slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestWebUIRendersListings(t *testing.T) {
+	t.Parallel()
+
+	loader := &fakeWebUILoader{movies: []movies.Movie{
+		{Title: "Daskalia", Href: "https://in.bookmyshow.com/movies/cuttack/daskalia/ET00438001", Year: 2025, Languages: []string{"Odia"}},
+		{Title: "<script>alert(1)</script>", Href: "/script"},
+	}}
+
+	recorder := httptest.NewRecorder()
+	testWebUIHandler(t, loader).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/web/cuttack", nil))
+
+	if recorder.Code != http.StatusOK || !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("status = %d, content type = %q, want a 200 HTML page", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+
+	body := recorder.Body.String()
+	for _, want := range []string{"Now Screening in Cuttack", `href="/go/ET00438001?city=cuttack"`, "(2025)", "Odia", "&lt;script&gt;", `href="/web/bhubaneswar"`, `action="/web/cuttack"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body does not contain %q", want)
+		}
+	}
+
+	if strings.Contains(body, "<script>alert") {
+		t.Fatalf("body contains unescaped title:\n%s", body)
+	}
+}
+
+func TestWebUISearches(t *testing.T) {
+	t.Parallel()
+
+	loader := &fakeWebUILoader{movies: []movies.Movie{
+		{Title: "Daskalia", Href: "/daskalia"},
+		{Title: "Superman", Href: "/superman"},
+	}}
+
+	recorder := httptest.NewRecorder()
+	testWebUIHandler(t, loader).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/web/cuttack?q=superman", nil))
+
+	body := recorder.Body.String()
+	if recorder.Code != http.StatusOK || loader.searchQuery != "superman" {
+		t.Fatalf("status = %d, search query = %q, want 200 and superman", recorder.Code, loader.searchQuery)
+	}
+
+	if strings.Count(body, `href="/go/`) != 1 || !strings.Contains(body, ">Superman</a>") || !strings.Contains(body, `value="superman"`) {
+		t.Fatalf("body does not show only the search results:\n%s", body)
+	}
+
+	if !strings.Contains(body, `href="/web/cuttack?q=Daskalia"`) {
+		t.Fatalf("body does not suggest the did-you-mean query:\n%s", body)
+	}
+}
+
+func TestWebUIRedirects(t *testing.T) {
+	t.Parallel()
+
+	handler := testWebUIHandler(t, &fakeWebUILoader{})
+
+	for _, tc := range []struct {
+		target   string
+		status   int
+		location string
+	}{
+		{target: "/web", status: http.StatusFound, location: "/web/cuttack"},
+		{target: "/web/bbsr?q=kalki", status: http.StatusMovedPermanently, location: "/web/bhubaneswar?q=kalki"},
+	} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+		if recorder.Code != tc.status || recorder.Header().Get("Location") != tc.location {
+			t.Fatalf("GET %s = %d to %q, want %d to %q", tc.target, recorder.Code, recorder.Header().Get("Location"), tc.status, tc.location)
+		}
+	}
+}
+
+func TestWebUIRendersErrors(t *testing.T) {
+	t.Parallel()
+
+	handler := testWebUIHandler(t, &fakeWebUILoader{err: movies.ErrScrapeBlocked})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/web/cuttack", nil))
+
+	if recorder.Code != http.StatusServiceUnavailable || !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("status = %d, content type = %q, want a 503 HTML page", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/web/not%20a%20city", nil))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("unknown city status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestWebUIServesStylesheet(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	testWebUIHandler(t, &fakeWebUILoader{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/web/static/web.css", nil))
+
+	if recorder.Code != http.StatusOK || !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/css") {
+		t.Fatalf("status = %d, content type = %q, want a 200 stylesheet", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+}
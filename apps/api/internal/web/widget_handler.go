@@ -0,0 +1,94 @@
+package web
+
+import (
+	"context"
+	"embed"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"go-scraping/internal/movies"
+)
+
+//go:embed templates/widget.html
+var widgetFS embed.FS
+
+var widgetTemplate = template.Must(template.New("widget.html").Funcs(template.FuncMap{
+	"join": strings.Join,
+}).ParseFS(widgetFS, "templates/widget.html"))
+
+type widgetLoader interface {
+	Load(ctx context.Context, city string) ([]movies.Movie, bool, error)
+}
+
+type widgetParams struct {
+	Theme string `query:"theme" default:"light" validate:"oneof=light dark"`
+	Limit int    `query:"limit" default:"10" validate:"min=1,max=50"`
+}
+
+type widgetView struct {
+	City     string
+	CityName string
+	Theme    string
+	Movies   []movies.Movie
+	More     int
+}
+
+type WidgetHandler struct {
+	loader      widgetLoader
+	cities      cityRegistry
+	defaultCity string
+	logger      *slog.Logger
+}
+
+func RegisterWidgetRoutes(mux *http.ServeMux, loader widgetLoader, registry cityRegistry, defaultCity string, logger *slog.Logger) {
+	handler := &WidgetHandler{
+		loader:      loader,
+		cities:      registry,
+		defaultCity: defaultCity,
+		logger:      logger,
+	}
+
+	mux.Handle("GET /widget", http.HandlerFunc(handler.Widget))
+}
+
+// Widget renders a self-contained HTML page of a city's current movies for
+// sites to embed in an iframe. Movies link to their short links, so clicks
+// from embeds count toward trending.
+func (h *WidgetHandler) Widget(w http.ResponseWriter, r *http.Request) {
+	city, err := resolveCity(r, h.cities, h.defaultCity)
+	if err != nil {
+		WriteServiceError(w, err, "Invalid city")
+		return
+	}
+
+	var params widgetParams
+	if err := bindQuery(r, &params); err != nil {
+		writeBindError(w, err)
+		return
+	}
+
+	list, fromCache, err := h.loader.Load(r.Context(), city.Name)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to load movies for widget", "city", city.Name, "error", err)
+		WriteServiceError(w, err, "Failed to load movies")
+		return
+	}
+	noteCache(r.Context(), fromCache)
+
+	view := widgetView{
+		City:     city.Name,
+		CityName: city.DisplayName,
+		Theme:    params.Theme,
+		Movies:   list,
+	}
+	if len(list) > params.Limit {
+		view.Movies, view.More = list[:params.Limit], len(list)-params.Limit
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := widgetTemplate.Execute(w, view); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to render widget", "city", city.Name, "error", err)
+	}
+}
@@ -0,0 +1,74 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeWidgetLoader struct {
+	movies []movies.Movie
+}
+
+func (f *fakeWidgetLoader) Load(_ context.Context, _ string) ([]movies.Movie, bool, error) {
+	return f.movies, true, nil
+}
+
+func testWidgetHandler(t *testing.T, loader widgetLoader) http.Handler {
+	t.Helper()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "cuttack", DisplayName: "Cuttack", Aliases: []string{"ctc"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	RegisterWidgetRoutes(mux, loader, registry, "cuttack", slog.New(slog.DiscardHandler))
+
+	return mux
+}
+
+func TestWidgetRendersListings(t *testing.T) {
+	t.Parallel()
+
+	loader := &fakeWidgetLoader{movies: []movies.Movie{
+		{Title: "Daskalia", Href: "https://in.bookmyshow.com/movies/cuttack/daskalia/ET00438001", Languages: []string{"Odia"}},
+		{Title: "<script>alert(1)</script>", Href: "/script"},
+		{Title: "F1: The Movie", Href: "https://in.bookmyshow.com/movies/cuttack/f1-the-movie/ET00403839"},
+	}}
+
+	recorder := httptest.NewRecorder()
+	testWidgetHandler(t, loader).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/widget?city=ctc&theme=dark&limit=2", nil))
+
+	if recorder.Code != http.StatusOK || !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("status = %d, content type = %q, want a 200 HTML page", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+
+	body := recorder.Body.String()
+	for _, want := range []string{"Now Screening in Cuttack", `class="dark"`, `href="/go/ET00438001?city=cuttack"`, "Odia", "&lt;script&gt;", "and 1 more"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body does not contain %q", want)
+		}
+	}
+
+	if strings.Contains(body, "<script>alert") || strings.Contains(body, "F1: The Movie") {
+		t.Errorf("body = %s, want the title escaped and the list cut at the limit", body)
+	}
+}
+
+func TestWidgetRejectsUnknownThemes(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	testWidgetHandler(t, &fakeWidgetLoader{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/widget?theme=neon", nil))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
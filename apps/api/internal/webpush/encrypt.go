@@ -0,0 +1,110 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	saltLength   = 16
+	recordSize   = 4096
+	authLength   = 16
+	p256KeyBytes = 65
+)
+
+// encrypt encrypts payload for a subscription with the aes128gcm content
+// encoding from RFC 8291, as a single record with no padding.
+func encrypt(payload []byte, keys Keys) ([]byte, error) {
+	uaPublic, err := ecdh.P256().NewPublicKey(keys.P256DH)
+	if err != nil {
+		return nil, fmt.Errorf("parse subscription key: %w", err)
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate ephemeral key: %w", err)
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+
+	cek, nonce, err := deriveKeys(asPrivate, uaPublic, asPrivate.PublicKey().Bytes(), keys, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return nil, err
+	}
+
+	// 0x02 marks the last (and only) record.
+	plaintext := append(append([]byte(nil), payload...), 0x02)
+	if len(plaintext)+gcm.Overhead() > recordSize {
+		return nil, fmt.Errorf("payload of %d bytes does not fit in one record", len(payload))
+	}
+
+	header := make([]byte, 0, saltLength+4+1+p256KeyBytes)
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, p256KeyBytes)
+	header = append(header, asPrivate.PublicKey().Bytes()...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// deriveKeys derives the content encryption key and nonce shared by the
+// application server key pair and the subscription's key pair. asPublic is
+// the application server's public key, which is also sent in the header.
+func deriveKeys(private *ecdh.PrivateKey, peer *ecdh.PublicKey, asPublic []byte, keys Keys, salt []byte) ([]byte, []byte, error) {
+	secret, err := private.ECDH(peer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("derive shared secret: %w", err)
+	}
+
+	keyInfo := "WebPush: info\x00" + string(keys.P256DH) + string(asPublic)
+
+	prkKey, err := hkdf.Extract(sha256.New, secret, keys.Auth)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ikm, err := hkdf.Expand(sha256.New, prkKey, keyInfo, 32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cek, nonce, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
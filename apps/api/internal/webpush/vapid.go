@@ -0,0 +1,110 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"time"
+)
+
+// vapidTokenLifetime is how long each VAPID token is valid. Push services
+// reject tokens that expire more than 24 hours ahead.
+const vapidTokenLifetime = 12 * time.Hour
+
+// VAPID identifies this server to push services (RFC 8292), so that only it
+// can push to the subscriptions created with its public key.
+type VAPID struct {
+	publicKey string
+	key       *ecdsa.PrivateKey
+	subject   string
+}
+
+// ParseVAPID parses an application server key pair in the base64url form
+// printed by common VAPID key generators: the uncompressed P-256 public point
+// and the raw private scalar. Subject is a mailto: or https: contact URL.
+func ParseVAPID(publicKey, privateKey, subject string) (*VAPID, error) {
+	rawPrivate, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode VAPID private key: %w", err)
+	}
+
+	private, err := ecdh.P256().NewPrivateKey(rawPrivate)
+	if err != nil {
+		return nil, fmt.Errorf("parse VAPID private key: %w", err)
+	}
+
+	rawPublic := private.PublicKey().Bytes()
+	if publicKey != base64.RawURLEncoding.EncodeToString(rawPublic) {
+		return nil, errors.New("VAPID public key does not match the private key")
+	}
+
+	key := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(rawPublic[1:33]),
+			Y:     new(big.Int).SetBytes(rawPublic[33:]),
+		},
+		D: new(big.Int).SetBytes(rawPrivate),
+	}
+
+	return &VAPID{publicKey: publicKey, key: key, subject: subject}, nil
+}
+
+// PublicKey is the application server key browsers pass to
+// pushManager.subscribe.
+func (v *VAPID) PublicKey() string {
+	return v.publicKey
+}
+
+// authorization returns the Authorization header for a push to endpoint.
+func (v *VAPID) authorization(endpoint string, now time.Time) (string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("parse endpoint: %w", err)
+	}
+
+	claims, err := json.Marshal(map[string]any{
+		"aud": parsed.Scheme + "://" + parsed.Host,
+		"exp": now.Add(vapidTokenLifetime).Unix(),
+		"sub": v.subject,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`)) +
+		"." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	r, s, err := ecdsa.Sign(rand.Reader, v.key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("sign VAPID token: %w", err)
+	}
+
+	// ES256 signatures are the two 32-byte integers concatenated.
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	token := unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)
+
+	return "vapid t=" + token + ", k=" + v.publicKey, nil
+}
+
+// decodeBase64URL accepts base64url with or without padding, as browsers and
+// key generators differ.
+func decodeBase64URL(value string) ([]byte, error) {
+	for len(value)%4 != 0 {
+		value += "="
+	}
+
+	return base64.URLEncoding.DecodeString(value)
+}
@@ -0,0 +1,251 @@
+// Package webpush sends browser push notifications when a title someone is
+// watching becomes bookable in their city.
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+const (
+	sendTimeout = 10 * time.Second
+
+	// messageTTL is how long a push service keeps a notification for a
+	// browser that is offline.
+	messageTTL = 24 * time.Hour
+)
+
+var (
+	ErrInvalidSubscription = errors.New("invalid push subscription")
+
+	// errSubscriptionGone means the push service no longer accepts pushes
+	// for the subscription, usually because the user revoked permission.
+	errSubscriptionGone = errors.New("push subscription is gone")
+)
+
+// Keys are a subscription's public key and authentication secret, which
+// payloads are encrypted with.
+type Keys struct {
+	P256DH []byte
+	Auth   []byte
+}
+
+// Subscription is a browser push subscription and the titles it watches in
+// a city.
+type Subscription struct {
+	Endpoint string
+	Keys     Keys
+	City     string
+	Titles   []string
+}
+
+// Registration is a subscription as the browser reports it, with keys in
+// base64url.
+type Registration struct {
+	Endpoint string
+	P256DH   string
+	Auth     string
+	City     string
+	Titles   []string
+}
+
+// Store keeps push subscriptions, one per endpoint.
+type Store interface {
+	Save(ctx context.Context, subscription Subscription) error
+	Delete(ctx context.Context, endpoint string) (bool, error)
+	Subscriptions(ctx context.Context, city string) ([]Subscription, error)
+}
+
+type cityResolver interface {
+	Resolve(name string) (cities.City, bool)
+}
+
+type Notifier struct {
+	vapid  *VAPID
+	store  Store
+	cities cityResolver
+	client *http.Client
+	logger *slog.Logger
+}
+
+// Message is the JSON payload of a notification, which the site's service
+// worker shows.
+type Message struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url,omitempty"`
+}
+
+var _ movies.ChangeListener = (*Notifier)(nil)
+
+func NewNotifier(vapid *VAPID, store Store, cities cityResolver, logger *slog.Logger) *Notifier {
+	return &Notifier{
+		vapid:  vapid,
+		store:  store,
+		cities: cities,
+		client: &http.Client{Timeout: sendTimeout},
+		logger: logger,
+	}
+}
+
+func (n *Notifier) PublicKey() string {
+	return n.vapid.PublicKey()
+}
+
+// Subscribe saves a subscription, replacing the city and titles of an
+// existing subscription with the same endpoint.
+func (n *Notifier) Subscribe(ctx context.Context, registration Registration) error {
+	endpoint, err := url.Parse(registration.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return fmt.Errorf("%w: endpoint must be an https URL", ErrInvalidSubscription)
+	}
+
+	keys, err := ParseKeys(registration.P256DH, registration.Auth)
+	if err != nil {
+		return err
+	}
+
+	var titles []string
+	for _, title := range registration.Titles {
+		if title = strings.TrimSpace(title); title != "" {
+			titles = append(titles, title)
+		}
+	}
+
+	if len(titles) == 0 {
+		return fmt.Errorf("%w: at least one title is required", ErrInvalidSubscription)
+	}
+
+	city, _ := n.cities.Resolve(registration.City)
+
+	return n.store.Save(ctx, Subscription{
+		Endpoint: registration.Endpoint,
+		Keys:     keys,
+		City:     city.Name,
+		Titles:   titles,
+	})
+}
+
+// ParseKeys decodes the base64url keys of a subscription as the browser
+// reports them.
+func ParseKeys(p256dh, auth string) (Keys, error) {
+	publicKey, err := decodeBase64URL(p256dh)
+	if err != nil || len(publicKey) != p256KeyBytes {
+		return Keys{}, fmt.Errorf("%w: p256dh must be a base64url P-256 public key", ErrInvalidSubscription)
+	}
+
+	secret, err := decodeBase64URL(auth)
+	if err != nil || len(secret) != authLength {
+		return Keys{}, fmt.Errorf("%w: auth must be a base64url 16-byte secret", ErrInvalidSubscription)
+	}
+
+	return Keys{P256DH: publicKey, Auth: secret}, nil
+}
+
+func (n *Notifier) Unsubscribe(ctx context.Context, endpoint string) (bool, error) {
+	return n.store.Delete(ctx, endpoint)
+}
+
+// MoviesAdded pushes to subscriptions watching any of the movies. Expired
+// subscriptions are deleted; any other failed push is returned, so the
+// outbox retries the message and subscriptions that were already pushed to
+// may be notified again.
+func (n *Notifier) MoviesAdded(ctx context.Context, cityName string, added []movies.Movie) error {
+	subscriptions, err := n.store.Subscriptions(ctx, cityName)
+	if err != nil {
+		return fmt.Errorf("load push subscriptions: %w", err)
+	}
+
+	byKey := make(map[string]movies.Movie, len(added))
+	for _, movie := range added {
+		byKey[movies.AliasKey(movie.Title)] = movie
+	}
+
+	city, _ := n.cities.Resolve(cityName)
+
+	var errs []error
+	for _, subscription := range subscriptions {
+		for _, title := range subscription.Titles {
+			movie, watched := byKey[movies.AliasKey(title)]
+			if !watched {
+				continue
+			}
+
+			err := n.send(ctx, subscription, Message{
+				Title: movie.Title + " is now bookable",
+				Body:  "Tickets are open in " + city.DisplayName + ".",
+				URL:   movie.Href,
+			})
+			if errors.Is(err, errSubscriptionGone) {
+				if _, err := n.store.Delete(ctx, subscription.Endpoint); err != nil {
+					n.logger.ErrorContext(ctx, "failed to delete expired push subscription", "error", err)
+				}
+				break
+			}
+
+			if err != nil {
+				errs = append(errs, fmt.Errorf("push %q: %w", movie.Title, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Push sends a single notification to a subscription, for callers that keep
+// their own subscriptions.
+func (n *Notifier) Push(ctx context.Context, endpoint string, keys Keys, msg Message) error {
+	return n.send(ctx, Subscription{Endpoint: endpoint, Keys: keys}, msg)
+}
+
+func (n *Notifier) send(ctx context.Context, subscription Subscription, msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encode push message: %w", err)
+	}
+
+	body, err := encrypt(payload, subscription.Keys)
+	if err != nil {
+		return fmt.Errorf("encrypt push message: %w", err)
+	}
+
+	authorization, err := n.vapid.authorization(subscription.Endpoint, time.Now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build push request: %w", err)
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", fmt.Sprint(int(messageTTL.Seconds())))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send push message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return errSubscriptionGone
+	case resp.StatusCode >= http.StatusMultipleChoices:
+		return fmt.Errorf("send push message: unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,287 @@
+package webpush
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-scraping/internal/cities"
+	"go-scraping/internal/movies"
+)
+
+type fakeStore struct {
+	mu            sync.Mutex
+	subscriptions map[string]Subscription
+}
+
+func (f *fakeStore) Save(_ context.Context, subscription Subscription) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.subscriptions[subscription.Endpoint] = subscription
+	return nil
+}
+
+func (f *fakeStore) Delete(_ context.Context, endpoint string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, exists := f.subscriptions[endpoint]
+	delete(f.subscriptions, endpoint)
+
+	return exists, nil
+}
+
+func (f *fakeStore) Subscriptions(_ context.Context, city string) ([]Subscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []Subscription
+	for _, subscription := range f.subscriptions {
+		if subscription.City == city {
+			result = append(result, subscription)
+		}
+	}
+
+	return result, nil
+}
+
+func generateKey(t *testing.T) *ecdh.PrivateKey {
+	t.Helper()
+
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	return key
+}
+
+func encode(raw []byte) string {
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decrypt reverses encrypt as a browser would, with the subscription's
+// private key.
+func decrypt(t *testing.T, body []byte, uaPrivate *ecdh.PrivateKey, keys Keys) []byte {
+	t.Helper()
+
+	salt := body[:saltLength]
+	idLength := int(body[saltLength+4])
+	asPublic := body[saltLength+5 : saltLength+5+idLength]
+
+	if rs := binary.BigEndian.Uint32(body[saltLength:]); rs != recordSize {
+		t.Fatalf("record size = %d, want %d", rs, recordSize)
+	}
+
+	peer, err := ecdh.P256().NewPublicKey(asPublic)
+	if err != nil {
+		t.Fatalf("NewPublicKey() error = %v", err)
+	}
+
+	cek, nonce, err := deriveKeys(uaPrivate, peer, asPublic, keys, salt)
+	if err != nil {
+		t.Fatalf("deriveKeys() error = %v", err)
+	}
+
+	gcm, err := newGCM(cek)
+	if err != nil {
+		t.Fatalf("newGCM() error = %v", err)
+	}
+
+	plaintext, err := gcm.Open(nil, nonce, body[saltLength+5+idLength:], nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	if plaintext[len(plaintext)-1] != 0x02 {
+		t.Fatalf("record delimiter = %#x, want 0x02", plaintext[len(plaintext)-1])
+	}
+
+	return plaintext[:len(plaintext)-1]
+}
+
+func verifyVAPID(t *testing.T, header string, vapid *VAPID, wantAudience string) {
+	t.Helper()
+
+	token, publicKey, found := strings.Cut(strings.TrimPrefix(header, "vapid t="), ", k=")
+	if !found || publicKey != vapid.PublicKey() {
+		t.Fatalf("Authorization = %q, want vapid token and public key", header)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token = %q, want three parts", token)
+	}
+
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+
+	if !ecdsa.Verify(&vapid.key.PublicKey, digest[:], r, s) {
+		t.Fatal("VAPID signature does not verify")
+	}
+
+	claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var decoded map[string]any
+	if err := json.Unmarshal(claims, &decoded); err != nil || decoded["aud"] != wantAudience {
+		t.Fatalf("claims = %s, want aud %s", claims, wantAudience)
+	}
+}
+
+func TestNotifierPushesWatchedTitles(t *testing.T) {
+	t.Parallel()
+
+	serverKey := generateKey(t)
+	vapid, err := ParseVAPID(encode(serverKey.PublicKey().Bytes()), encode(serverKey.Bytes()), "mailto:ops@example.com")
+	if err != nil {
+		t.Fatalf("ParseVAPID() error = %v", err)
+	}
+
+	var (
+		mu     sync.Mutex
+		pushes []*http.Request
+		bodies [][]byte
+	)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		pushes = append(pushes, r)
+		bodies = append(bodies, body)
+		mu.Unlock()
+
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+
+		if r.URL.Path == "/failing" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	registry, err := cities.NewRegistry([]cities.City{{Name: "bhubaneswar", DisplayName: "Bhubaneswar", Aliases: []string{"bbsr"}}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	store := &fakeStore{subscriptions: map[string]Subscription{}}
+	notifier := NewNotifier(vapid, store, registry, slog.New(slog.DiscardHandler))
+	notifier.client = server.Client()
+
+	browserKey := generateKey(t)
+	auth := make([]byte, authLength)
+	_, _ = rand.Read(auth)
+
+	for _, registration := range []Registration{
+		{Endpoint: server.URL + "/watching", Titles: []string{"f1"}},
+		{Endpoint: server.URL + "/other", Titles: []string{"Jaws"}},
+		{Endpoint: server.URL + "/gone", Titles: []string{"F1"}},
+		{Endpoint: server.URL + "/failing", Titles: []string{"F1"}},
+	} {
+		registration.P256DH = encode(browserKey.PublicKey().Bytes())
+		registration.Auth = encode(auth)
+		registration.City = "BBSR"
+
+		if err := notifier.Subscribe(context.Background(), registration); err != nil {
+			t.Fatalf("Subscribe() error = %v", err)
+		}
+	}
+
+	err = notifier.MoviesAdded(context.Background(), "bhubaneswar", []movies.Movie{{Title: "F1", Href: "https://in.bookmyshow.com/f1"}})
+	if err == nil {
+		t.Fatal("MoviesAdded() error = nil, want the failed push returned so the outbox retries")
+	}
+
+	if len(pushes) != 3 {
+		t.Fatalf("pushes = %d, want 3 to the subscriptions watching F1", len(pushes))
+	}
+
+	for i, push := range pushes {
+		if push.URL.Path != "/watching" {
+			continue
+		}
+
+		if push.Header.Get("Content-Encoding") != "aes128gcm" {
+			t.Fatalf("Content-Encoding = %q, want aes128gcm", push.Header.Get("Content-Encoding"))
+		}
+
+		verifyVAPID(t, push.Header.Get("Authorization"), vapid, server.URL)
+
+		payload := decrypt(t, bodies[i], browserKey, Keys{P256DH: browserKey.PublicKey().Bytes(), Auth: auth})
+		want := `{"title":"F1 is now bookable","body":"Tickets are open in Bhubaneswar.","url":"https://in.bookmyshow.com/f1"}`
+		if string(payload) != want {
+			t.Fatalf("payload = %s, want %s", payload, want)
+		}
+	}
+
+	if _, exists := store.subscriptions[server.URL+"/gone"]; exists {
+		t.Fatal("gone subscription still stored, want it deleted")
+	}
+}
+
+func TestSubscribeRejectsInvalidRegistrations(t *testing.T) {
+	t.Parallel()
+
+	registry, err := cities.NewRegistry(nil)
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	notifier := NewNotifier(nil, &fakeStore{subscriptions: map[string]Subscription{}}, registry, slog.New(slog.DiscardHandler))
+	valid := Registration{
+		Endpoint: "https://push.example.com/abc",
+		P256DH:   encode(generateKey(t).PublicKey().Bytes()),
+		Auth:     encode(make([]byte, authLength)),
+		City:     "cuttack",
+		Titles:   []string{"F1"},
+	}
+
+	tests := map[string]func(*Registration){
+		"http endpoint": func(r *Registration) { r.Endpoint = "http://push.example.com/abc" },
+		"short key":     func(r *Registration) { r.P256DH = encode([]byte("short")) },
+		"short auth":    func(r *Registration) { r.Auth = encode([]byte("short")) },
+		"no titles":     func(r *Registration) { r.Titles = []string{" "} },
+	}
+
+	for name, mutate := range tests {
+		registration := valid
+		mutate(&registration)
+
+		if err := notifier.Subscribe(context.Background(), registration); err == nil {
+			t.Fatalf("%s: Subscribe() error = nil, want ErrInvalidSubscription", name)
+		}
+	}
+
+	if err := notifier.Subscribe(context.Background(), valid); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+}
+
+func TestParseVAPIDRejectsMismatchedKeys(t *testing.T) {
+	t.Parallel()
+
+	key, other := generateKey(t), generateKey(t)
+
+	if _, err := ParseVAPID(encode(other.PublicKey().Bytes()), encode(key.Bytes()), "mailto:ops@example.com"); err == nil {
+		t.Fatal("ParseVAPID() error = nil, want mismatch error")
+	}
+}